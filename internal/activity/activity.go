@@ -3,20 +3,17 @@ package activity
 
 import (
 	"time"
-)
 
-// Color class constants for activity status.
-const (
-	ColorGreen   = "green"   // Active: <2 minutes
-	ColorYellow  = "yellow"  // Stale: 2-5 minutes
-	ColorRed     = "red"     // Stuck: >5 minutes
-	ColorUnknown = "unknown" // No activity data
+	"github.com/steveyegge/gastown/internal/keepalive"
 )
 
-// Thresholds for activity color coding.
+// Color class constants for activity status. The boundaries are the
+// keepalive thresholds (2 and 5 minutes unless a rig configures its own).
 const (
-	ThresholdActive = 2 * time.Minute  // Green threshold
-	ThresholdStale  = 5 * time.Minute  // Yellow threshold (beyond this is red)
+	ColorGreen   = "green"   // Active: younger than the fresh threshold
+	ColorYellow  = "yellow"  // Stale: between the fresh and stale thresholds
+	ColorRed     = "red"     // Stuck: older than the stale threshold
+	ColorUnknown = "unknown" // No activity data
 )
 
 // Info holds activity information for display.
@@ -27,13 +24,20 @@ type Info struct {
 	ColorClass   string    // CSS class for coloring (green, yellow, red, unknown)
 }
 
-// Calculate computes activity info from a last-activity timestamp.
-// Returns color-coded info based on thresholds:
-//   - Green:   <2 minutes (active)
-//   - Yellow:  2-5 minutes (stale)
-//   - Red:     >5 minutes (stuck)
-//   - Unknown: zero time value
+// Calculate computes activity info from a last-activity timestamp using the
+// default keepalive thresholds. Use CalculateFor when the rig is known.
 func Calculate(lastActivity time.Time) Info {
+	return CalculateFor(lastActivity, keepalive.DefaultThresholds)
+}
+
+// CalculateFor computes activity info from a last-activity timestamp.
+// Returns color-coded info based on a rig's keepalive thresholds (see
+// keepalive.RigThresholds):
+//   - Green:   younger than th.Fresh (active)
+//   - Yellow:  between th.Fresh and th.Stale (stale)
+//   - Red:     th.Stale or older (stuck)
+//   - Unknown: zero time value
+func CalculateFor(lastActivity time.Time, th keepalive.Thresholds) Info {
 	info := Info{
 		LastActivity: lastActivity,
 	}
@@ -57,7 +61,7 @@ func Calculate(lastActivity time.Time) Info {
 	info.FormattedAge = formatAge(info.Duration)
 
 	// Determine color class
-	info.ColorClass = colorForDuration(info.Duration)
+	info.ColorClass = colorForDuration(info.Duration, th)
 
 	return info
 }
@@ -110,11 +114,11 @@ func formatInt(n int) string {
 }
 
 // colorForDuration returns the color class for a given duration.
-func colorForDuration(d time.Duration) string {
-	switch {
-	case d < ThresholdActive:
+func colorForDuration(d time.Duration, th keepalive.Thresholds) string {
+	switch th.Classify(d) {
+	case keepalive.Fresh:
 		return ColorGreen
-	case d < ThresholdStale:
+	case keepalive.Stale:
 		return ColorYellow
 	default:
 		return ColorRed
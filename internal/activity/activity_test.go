@@ -3,6 +3,8 @@ package activity
 import (
 	"testing"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/keepalive"
 )
 
 func TestCalculateActivity_Green(t *testing.T) {
@@ -114,6 +116,25 @@ func TestCalculateActivity_FutureTime(t *testing.T) {
 	}
 }
 
+func TestCalculateFor_RigThresholds(t *testing.T) {
+	// A rig with long-running builds raises its thresholds
+	th := keepalive.Thresholds{Fresh: 10 * time.Minute, Stale: 30 * time.Minute}
+	tests := []struct {
+		age       time.Duration
+		wantColor string
+	}{
+		{3 * time.Minute, ColorGreen},
+		{15 * time.Minute, ColorYellow},
+		{45 * time.Minute, ColorRed},
+	}
+	for _, tt := range tests {
+		info := CalculateFor(time.Now().Add(-tt.age), th)
+		if info.ColorClass != tt.wantColor {
+			t.Errorf("CalculateFor(%v ago) ColorClass = %q, want %q", tt.age, info.ColorClass, tt.wantColor)
+		}
+	}
+}
+
 func TestInfo_IsActive(t *testing.T) {
 	tests := []struct {
 		color    string
@@ -3,11 +3,7 @@
 package agent
 
 import (
-	"encoding/json"
-	"os"
-	"path/filepath"
-
-	"github.com/steveyegge/gastown/internal/util"
+	"github.com/steveyegge/gastown/internal/runstate"
 )
 
 // State represents an agent's running state.
@@ -25,10 +21,10 @@ const (
 )
 
 // StateManager handles loading and saving agent state to disk.
-// It uses generics to work with any state type.
+// It uses generics to work with any state type. State lives in the rig's
+// runtime state store, so concurrent readers and writers are safe.
 type StateManager[T any] struct {
-	stateFilePath  string
-	defaultFactory func() *T
+	doc *runstate.Doc[T]
 }
 
 // NewStateManager creates a new StateManager for the given state file path.
@@ -36,41 +32,28 @@ type StateManager[T any] struct {
 // to create a new state with default values.
 func NewStateManager[T any](rigPath, stateFileName string, defaultFactory func() *T) *StateManager[T] {
 	return &StateManager[T]{
-		stateFilePath:  filepath.Join(rigPath, ".runtime", stateFileName),
-		defaultFactory: defaultFactory,
+		doc: runstate.NewDoc(runstate.Open(rigPath), stateFileName, defaultFactory),
 	}
 }
 
 // StateFile returns the path to the state file.
 func (m *StateManager[T]) StateFile() string {
-	return m.stateFilePath
+	return m.doc.Path()
 }
 
 // Load loads agent state from disk.
 // If the file doesn't exist, returns a new state created by the default factory.
 func (m *StateManager[T]) Load() (*T, error) {
-	data, err := os.ReadFile(m.stateFilePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return m.defaultFactory(), nil
-		}
-		return nil, err
-	}
-
-	var state T
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, err
-	}
-
-	return &state, nil
+	return m.doc.Load()
 }
 
 // Save persists agent state to disk using atomic write.
 func (m *StateManager[T]) Save(state *T) error {
-	dir := filepath.Dir(m.stateFilePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
+	return m.doc.Save(state)
+}
 
-	return util.AtomicWriteJSON(m.stateFilePath, state)
+// Update applies fn to the current state and saves it, without losing
+// concurrent updates from other processes.
+func (m *StateManager[T]) Update(fn func(state *T) error) (*T, error) {
+	return m.doc.Update(fn)
 }
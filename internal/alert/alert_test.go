@@ -0,0 +1,255 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/metrics"
+	"github.com/steveyegge/gastown/internal/slo"
+)
+
+func float(v float64) *float64 { return &v }
+
+func TestCompile(t *testing.T) {
+	mail := []Action{{Type: ActionMail}}
+	for _, tt := range []struct {
+		rule Rule
+		err  string
+	}{
+		{Rule{Name: "rate", Event: "merge_failed", Above: float(3), Window: "1h", Actions: mail}, ""},
+		{Rule{Name: "absent", Event: "patrol_complete", AbsentFor: "2h", Actions: mail}, ""},
+		{Rule{Name: "metric", Metric: "gastown_mail_unread", Below: float(1), Actions: mail}, ""},
+		{Rule{Event: "merge_failed", Above: float(3), Actions: mail}, "no name"},
+		{Rule{Name: "x", Event: "merge_failed", Actions: mail}, "needs above or below"},
+		{Rule{Name: "x", Event: "e", Metric: "m", Above: float(1), Actions: mail}, "not both"},
+		{Rule{Name: "x", Event: "e", AbsentFor: "1h", Above: float(1), Actions: mail}, "cannot be combined"},
+		{Rule{Name: "x", Metric: "m", AbsentFor: "1h", Above: float(1), Actions: mail}, "only to event rules"},
+		{Rule{Name: "x", Event: "e", Above: float(1), Window: "soon", Actions: mail}, "window"},
+		{Rule{Name: "x", Event: "e", Above: float(1)}, "no actions"},
+		{Rule{Name: "x", Actions: mail}, "needs an event, metric, or slo"},
+		{Rule{Name: "x", Event: "e", Above: float(1), Actions: []Action{{Type: ActionSlack}}}, "needs a url"},
+		{Rule{Name: "x", Event: "e", Above: float(1), Actions: []Action{{Type: ActionPauseQueue}}}, "needs a rig"},
+		{Rule{Name: "x", Event: "e", Above: float(1), Actions: []Action{{Type: "page"}}}, "unknown action"},
+		{Rule{Name: "budget", SLO: "merge-2h", Actions: mail}, ""},
+		{Rule{Name: "x", SLO: "merge-2h", Above: float(1), Actions: mail}, "slo cannot be combined"},
+	} {
+		_, err := compile(tt.rule)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.rule.Name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%+v: error = %v, want %q", tt.rule, err, tt.err)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	ev := func(ago time.Duration, typ, actor, rig string) events.Event {
+		return events.Event{Timestamp: now.Add(-ago).Format(time.RFC3339), Type: typ, Actor: actor, Rig: rig}
+	}
+	evts := []events.Event{
+		ev(3*time.Hour, events.TypePatrolComplete, "gastown/witness", "gastown"),
+		ev(90*time.Minute, events.TypeMergeFailed, "gastown/refinery", "gastown"),
+		ev(50*time.Minute, events.TypeMergeFailed, "gastown/refinery", "gastown"),
+		ev(20*time.Minute, events.TypeMergeFailed, "gastown/refinery", "gastown"),
+		ev(10*time.Minute, events.TypeMergeFailed, "beads/refinery", "beads"),
+		ev(5*time.Minute, events.TypePatrolComplete, "beads/witness", "beads"),
+	}
+	families := []*metrics.Family{{Name: "gastown_merge_queue_depth", Samples: []metrics.Sample{
+		{Labels: metrics.Labels{"rig": "gastown", "state": "pending"}, Value: 12},
+		{Labels: metrics.Labels{"rig": "gastown", "state": "blocked"}, Value: 4},
+		{Labels: metrics.Labels{"rig": "beads", "state": "pending"}, Value: 30},
+	}}}
+	armed := now.Add(-24 * time.Hour)
+
+	for _, tt := range []struct {
+		rule   Rule
+		firing bool
+		value  float64
+	}{
+		{Rule{Event: "merge_failed", Rig: "gastown", Above: float(1)}, true, 2},
+		{Rule{Event: "merge_failed", Rig: "gastown", Above: float(2)}, false, 2},
+		{Rule{Event: "merge_failed", Above: float(3), Window: "2h"}, true, 4},
+		{Rule{Event: "merge_failed", Actor: "*/refinery", Below: float(5), Window: "2h"}, true, 4},
+		{Rule{Event: "patrol_complete", Actor: "gastown/witness", AbsentFor: "2h"}, true, 3},
+		{Rule{Event: "patrol_complete", Actor: "beads/witness", AbsentFor: "2h"}, false, 5.0 / 60},
+		{Rule{Metric: "gastown_merge_queue_depth", Rig: "gastown", Above: float(15)}, true, 16},
+		{Rule{Metric: "gastown_merge_queue_depth", Labels: map[string]string{"state": "pending"}, Above: float(50)}, false, 42},
+		{Rule{Metric: "gastown_polecats", Below: float(1)}, false, 0}, // no samples: no alert
+	} {
+		tt.rule.Name = "r"
+		tt.rule.Actions = []Action{{Type: ActionMail}}
+		r, err := compile(tt.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := r.evaluate(evts, families, now, armed)
+		if res.firing != tt.firing || res.value != tt.value {
+			t.Errorf("%s: firing=%v value=%v, want %v %v", Describe(tt.rule), res.firing, res.value, tt.firing, tt.value)
+		}
+		if res.firing && res.summary == "" {
+			t.Errorf("%s: firing without a summary", Describe(tt.rule))
+		}
+	}
+
+	// An absence rule armed recently waits out its duration before firing
+	r, _ := compile(Rule{Name: "new", Event: "patrol_complete", Actor: "greenplace/witness", AbsentFor: "2h", Actions: []Action{{Type: ActionMail}}})
+	if res := r.evaluate(evts, nil, now, now.Add(-time.Hour)); res.firing {
+		t.Errorf("absence rule fired an hour after arming: %+v", res)
+	}
+	if res := r.evaluate(evts, nil, now, armed); !res.firing {
+		t.Error("absence rule did not fire a day after arming")
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	for _, tt := range []struct {
+		rule Rule
+		want string
+	}{
+		{Rule{Event: "merge_failed", Rig: "gastown", Above: float(3)}, "merge_failed in gastown above 3 per 1h"},
+		{Rule{Event: "patrol_complete", Actor: "gastown/witness", AbsentFor: "2h"}, "no patrol_complete from gastown/witness for 2h"},
+		{Rule{Metric: "gastown_mail_unread", Labels: map[string]string{"address": "mayor/"}, Above: float(50)}, "gastown_mail_unread{address=mayor/} above 50"},
+	} {
+		if got := Describe(tt.rule); got != tt.want {
+			t.Errorf("Describe = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestSLORules(t *testing.T) {
+	objectives := []slo.Objective{
+		{Name: "merge-2h", Rig: "gastown", Target: 0.9, Within: "2h"},
+		{Name: "custom", Target: 0.5, Within: "1h"},
+		{Name: "off", Target: 0.5, Within: "1h", Disabled: true},
+	}
+	cfg := Config{Rules: []Rule{{Name: "custom-budget", SLO: "custom", Actions: []Action{{Type: ActionSlack, URL: "http://x"}}}}}
+	var names []string
+	for _, r := range EffectiveRules(cfg, objectives) {
+		names = append(names, r.Name)
+	}
+	if got := strings.Join(names, " "); got != "custom-budget slo-merge-2h" {
+		t.Errorf("effective rules = %s", got)
+	}
+
+	if _, err := NewEngine(t.TempDir(), Config{Rules: []Rule{{Name: "x", SLO: "nope", Actions: []Action{{Type: ActionMail}}}}}, objectives, nil, t.Logf); err == nil {
+		t.Error("NewEngine accepted a rule for an unknown slo")
+	}
+
+	e, err := NewEngine(t.TempDir(), Config{}, objectives[:1], nil, t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := e.rules[0]
+	if r.Rig != "gastown" || r.lookback() != slo.DefaultWindow {
+		t.Errorf("default rule rig=%q lookback=%v", r.Rig, r.lookback())
+	}
+
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	evts := []events.Event{{
+		Timestamp: now.Add(-3 * time.Hour).Format(time.RFC3339), Type: events.TypeDone, Rig: "gastown",
+		Payload: events.DonePayload("gt-a", "polecat/toast", "COMPLETED"),
+	}}
+	res := r.evaluate(evts, nil, now, now)
+	if !res.firing || res.value >= 0 || !strings.Contains(res.summary, "merge-2h") {
+		t.Errorf("overdue MR against a 90%% SLO: %+v, want firing with the budget overspent", res)
+	}
+}
+
+func TestEngineEvaluate(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "daemon"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	emit := func(at time.Time) {
+		t.Helper()
+		err := events.EmitTo(townRoot, events.Event{Timestamp: at.Format(time.RFC3339), Type: events.TypeMergeFailed, Actor: "gastown/refinery", Rig: "gastown"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var posted []Alert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a Alert
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			t.Errorf("decoding webhook body: %v", err)
+		}
+		posted = append(posted, a)
+	}))
+	defer srv.Close()
+
+	cfg := Config{Rules: []Rule{{
+		Name: "merge-failures", Event: events.TypeMergeFailed, Rig: "gastown", Above: float(1), Window: "10m", Repeat: "30m",
+		Actions: []Action{{Type: ActionWebhook, URL: srv.URL}, {Type: ActionPauseQueue}},
+	}}}
+	e, err := NewEngine(townRoot, cfg, nil, nil, t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paused []string
+	e.pauseQueue = func(_, rigName, reason string) error {
+		paused = append(paused, rigName+": "+reason)
+		return nil
+	}
+	ctx := context.Background()
+
+	emit(start)
+	e.Evaluate(ctx, start.Add(time.Minute))
+	if len(posted) != 0 {
+		t.Fatalf("fired below threshold: %+v", posted)
+	}
+
+	emit(start.Add(2 * time.Minute))
+	e.Evaluate(ctx, start.Add(3*time.Minute))
+	e.Evaluate(ctx, start.Add(4*time.Minute)) // Still firing: no repeat yet
+	if len(posted) != 1 || posted[0].Rule != "merge-failures" || posted[0].Value != 2 {
+		t.Fatalf("posted = %+v, want one alert with value 2", posted)
+	}
+	if len(paused) != 1 || !strings.HasPrefix(paused[0], "gastown: alert merge-failures") {
+		t.Errorf("paused = %v", paused)
+	}
+
+	// State survives a restart of the engine
+	if e, err = NewEngine(townRoot, cfg, nil, nil, t.Logf); err != nil {
+		t.Fatal(err)
+	}
+	e.pauseQueue = func(string, string, string) error { return nil }
+	e.Evaluate(ctx, start.Add(5*time.Minute))
+	if len(posted) != 1 {
+		t.Errorf("restarted engine re-fired: %d alerts", len(posted))
+	}
+
+	// Window passes: resolved
+	e.Evaluate(ctx, start.Add(15*time.Minute))
+	state, err := LoadState(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := state["merge-failures"]; st == nil || st.Firing || st.ResolvedAt.IsZero() {
+		t.Errorf("state after window = %+v, want resolved", st)
+	}
+
+	var types []string
+	evts, err := readEvents(filepath.Join(townRoot, events.EventsFile), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ev := range evts {
+		types = append(types, ev.Type)
+	}
+	want := "merge_failed merge_failed alert_fired queue_paused alert_resolved"
+	if got := strings.Join(types, " "); got != want {
+		t.Errorf("events = %s, want %s", got, want)
+	}
+}
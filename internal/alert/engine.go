@@ -0,0 +1,446 @@
+package alert
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/logrotate"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/metrics"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/runstate"
+	"github.com/steveyegge/gastown/internal/slo"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// RuleState is the saved state of a rule between evaluations.
+type RuleState struct {
+	// ArmedAt is when the rule was first evaluated.
+	ArmedAt time.Time `json:"armed_at"`
+
+	// Firing is whether the rule's condition held at the last evaluation.
+	Firing bool `json:"firing"`
+
+	// Value and Summary describe the last evaluation.
+	Value   float64 `json:"value"`
+	Summary string  `json:"summary,omitempty"`
+
+	// FiredAt is when the rule last started firing.
+	FiredAt time.Time `json:"fired_at,omitempty"`
+
+	// NotifiedAt is when the rule's actions last ran.
+	NotifiedAt time.Time `json:"notified_at,omitempty"`
+
+	// ResolvedAt is when the rule last stopped firing.
+	ResolvedAt time.Time `json:"resolved_at,omitempty"`
+}
+
+// StateFile returns the path of the saved rule states.
+func StateFile(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "alerts.json")
+}
+
+// stateDoc returns the document holding the rule states.
+func stateDoc(townRoot string) *runstate.Doc[map[string]*RuleState] {
+	return runstate.NewDocAt(StateFile(townRoot), func() *map[string]*RuleState {
+		return &map[string]*RuleState{}
+	})
+}
+
+// LoadState reads the saved rule states, keyed by rule name.
+func LoadState(townRoot string) (map[string]*RuleState, error) {
+	state, err := stateDoc(townRoot).Load()
+	if err != nil {
+		return nil, err
+	}
+	if *state == nil {
+		return map[string]*RuleState{}, nil
+	}
+	return *state, nil
+}
+
+// Engine evaluates alert rules periodically and runs their actions.
+type Engine struct {
+	townRoot string
+	town     string
+	rules    []*rule
+	interval time.Duration
+	metrics  func() []*metrics.Family
+	client   *http.Client
+	logger   func(format string, args ...interface{})
+
+	// pauseQueue pauses a rig's merge queue (refinery.PauseQueue; replaced in tests).
+	pauseQueue func(townRoot, rigName, reason string) error
+
+	state map[string]*RuleState // only accessed from the run goroutine
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewEngine validates the configured rules and creates an engine for them
+// and the town's merge queue objectives (see EffectiveRules). Disabled
+// rules are skipped. metricsFn collects the town's metrics for metric
+// rules; it may be nil if no rule watches a metric.
+func NewEngine(townRoot string, cfg Config, objectives []slo.Objective, metricsFn func() []*metrics.Family, logger func(format string, args ...interface{})) (*Engine, error) {
+	if err := slo.Validate(objectives); err != nil {
+		return nil, err
+	}
+	interval, err := logrotate.ParseDuration(cfg.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("alerts interval: %w", err)
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	town, _ := workspace.GetTownName(townRoot)
+	e := &Engine{
+		townRoot:   townRoot,
+		town:       town,
+		interval:   interval,
+		metrics:    metricsFn,
+		client:     &http.Client{Timeout: 30 * time.Second},
+		logger:     logger,
+		pauseQueue: refinery.PauseQueue,
+	}
+	seen := make(map[string]bool)
+	for _, r := range EffectiveRules(cfg, objectives) {
+		if r.Disabled {
+			continue
+		}
+		c, err := compile(r)
+		if err != nil {
+			return nil, err
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("duplicate alert rule %q", r.Name)
+		}
+		seen[r.Name] = true
+		if r.Metric != "" && metricsFn == nil {
+			return nil, fmt.Errorf("rule %s: metric rules need the metrics collector", r.Name)
+		}
+		if r.SLO != "" {
+			o, ok := slo.Find(objectives, r.SLO)
+			if !ok {
+				return nil, fmt.Errorf("rule %s: unknown or disabled slo %q", r.Name, r.SLO)
+			}
+			c.objective = &o
+			if c.Rig == "" {
+				c.Rig = o.Rig
+			}
+		}
+		e.rules = append(e.rules, c)
+	}
+
+	if e.state, err = LoadState(townRoot); err != nil {
+		logger("alerts: %v; starting with fresh state", err)
+		e.state = map[string]*RuleState{}
+	}
+	return e, nil
+}
+
+// Rules returns the number of active rules.
+func (e *Engine) Rules() int {
+	return len(e.rules)
+}
+
+// EffectiveRules returns the configured rules plus a default rule for every
+// enabled objective no rule refers to. The default rule, named
+// "slo-<objective>", mails the overseer when the error budget is exhausted.
+// Refer to an objective from a rule of your own (or a disabled one) to
+// change or silence its alerting.
+func EffectiveRules(cfg Config, objectives []slo.Objective) []Rule {
+	rules := append([]Rule(nil), cfg.Rules...)
+	watched := make(map[string]bool)
+	for _, r := range cfg.Rules {
+		if r.SLO != "" {
+			watched[r.SLO] = true
+		}
+	}
+	for _, o := range objectives {
+		if o.Disabled || watched[o.Name] {
+			continue
+		}
+		rules = append(rules, Rule{
+			Name:    "slo-" + o.Name,
+			SLO:     o.Name,
+			Rig:     o.Rig,
+			Actions: []Action{{Type: ActionMail}},
+		})
+	}
+	return rules
+}
+
+// UsesMetrics reports whether any rule in cfg watches a metric.
+func UsesMetrics(cfg Config) bool {
+	for _, r := range cfg.Rules {
+		if !r.Disabled && r.Metric != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Start begins evaluating rules in the background.
+func (e *Engine) Start() error {
+	e.ctx, e.cancel = context.WithCancel(context.Background())
+	e.wg.Add(1)
+	go e.run()
+	return nil
+}
+
+// Stop stops evaluation and waits for any running actions to finish.
+func (e *Engine) Stop() {
+	if e.cancel == nil {
+		return
+	}
+	e.cancel()
+	e.wg.Wait()
+}
+
+func (e *Engine) run() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		e.Evaluate(e.ctx, time.Now())
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Evaluate checks every rule once, runs the actions of rules that started
+// firing (or are due to repeat), and saves the rule states.
+func (e *Engine) Evaluate(ctx context.Context, now time.Time) {
+	var lookback time.Duration
+	needMetrics := false
+	for _, r := range e.rules {
+		if lb := r.lookback(); lb > lookback {
+			lookback = lb
+		}
+		needMetrics = needMetrics || r.Metric != ""
+	}
+
+	var evts []events.Event
+	if lookback > 0 {
+		var err error
+		evts, err = readEvents(filepath.Join(e.townRoot, events.EventsFile), now.Add(-lookback))
+		if err != nil {
+			e.logger("alerts: reading events: %v", err)
+			return
+		}
+	}
+	var families []*metrics.Family
+	if needMetrics {
+		families = e.metrics()
+	}
+
+	for _, r := range e.rules {
+		st := e.state[r.Name]
+		if st == nil {
+			st = &RuleState{ArmedAt: now}
+			e.state[r.Name] = st
+		}
+
+		res := r.evaluate(evts, families, now, st.ArmedAt)
+		st.Value = res.value
+		switch {
+		case res.firing && !st.Firing:
+			st.Firing, st.FiredAt, st.Summary = true, now, res.summary
+			e.fire(ctx, r, st, now)
+		case res.firing:
+			st.Summary = res.summary
+			if r.repeat > 0 && now.Sub(st.NotifiedAt) >= r.repeat {
+				e.fire(ctx, r, st, now)
+			}
+		case st.Firing:
+			st.Firing, st.ResolvedAt = false, now
+			e.logger("alerts: %s resolved", r.Name)
+			_ = events.EmitTo(e.townRoot, events.Event{
+				Type:       events.TypeAlertResolved,
+				Actor:      "daemon",
+				Rig:        r.Rig,
+				Subject:    r.Name,
+				Payload:    events.AlertPayload(r.Name, res.value, st.Summary),
+				Visibility: events.VisibilityBoth,
+			})
+		}
+	}
+
+	// Forget rules that were removed from the config
+	for name := range e.state {
+		if !e.hasRule(name) {
+			delete(e.state, name)
+		}
+	}
+	if err := stateDoc(e.townRoot).Save(&e.state); err != nil {
+		e.logger("alerts: saving state: %v", err)
+	}
+}
+
+func (e *Engine) hasRule(name string) bool {
+	for _, r := range e.rules {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fire records a firing rule on the event feed and runs its actions.
+func (e *Engine) fire(ctx context.Context, r *rule, st *RuleState, now time.Time) {
+	st.NotifiedAt = now
+	a := Alert{Town: e.town, Rule: r.Name, Rig: r.Rig, Value: st.Value, Summary: st.Summary, FiredAt: st.FiredAt}
+	e.logger("alerts: %s firing: %s", r.Name, a.Summary)
+	payload := events.AlertPayload(r.Name, a.Value, a.Summary)
+	if r.SLO != "" {
+		payload["slo"] = r.SLO
+	}
+	_ = events.EmitTo(e.townRoot, events.Event{
+		Type:       events.TypeAlertFired,
+		Actor:      "daemon",
+		Rig:        r.Rig,
+		Subject:    r.Name,
+		Payload:    payload,
+		Visibility: events.VisibilityBoth,
+	})
+
+	for _, action := range r.Actions {
+		if err := e.runAction(ctx, r, action, a); err != nil {
+			e.logger("Warning: alerts: %s: %s action failed: %v", r.Name, action.Type, err)
+		}
+	}
+}
+
+func (e *Engine) runAction(ctx context.Context, r *rule, action Action, a Alert) error {
+	switch action.Type {
+	case ActionMail:
+		to := action.To
+		if to == "" {
+			to = "overseer"
+		}
+		msg := mail.NewMessage("daemon", to, "ALERT: "+a.Rule, alertBody(a))
+		msg.Priority = mail.PriorityHigh
+		return mail.NewRouterWithTownRoot(e.townRoot, e.townRoot).Send(msg)
+
+	case ActionSlack:
+		text := fmt.Sprintf(":rotating_light: *%s*: %s", a.Rule, a.Summary)
+		if a.Town != "" {
+			text = fmt.Sprintf(":rotating_light: *%s* (%s): %s", a.Rule, a.Town, a.Summary)
+		}
+		return e.post(ctx, action.URL, map[string]string{"text": text})
+
+	case ActionWebhook:
+		return e.post(ctx, action.URL, a)
+
+	case ActionPauseQueue:
+		rigName := action.Rig
+		if rigName == "" {
+			rigName = r.Rig
+		}
+		reason := fmt.Sprintf("alert %s: %s", a.Rule, a.Summary)
+		if err := e.pauseQueue(e.townRoot, rigName, reason); err != nil {
+			return err
+		}
+		e.logger("alerts: paused merge queue for %s", rigName)
+		return events.EmitTo(e.townRoot, events.Event{
+			Type:       events.TypeQueuePaused,
+			Actor:      "daemon",
+			Rig:        rigName,
+			Payload:    events.QueuePausePayload(rigName, reason),
+			Visibility: events.VisibilityFeed,
+		})
+	}
+	return fmt.Errorf("unknown action type %q", action.Type)
+}
+
+// post sends body as JSON to a webhook URL.
+func (e *Engine) post(ctx context.Context, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, os.ExpandEnv(url), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func alertBody(a Alert) string {
+	body := fmt.Sprintf("Alert rule %s is firing.\n\n%s\n\nfired_at: %s\nvalue: %g\n",
+		a.Rule, a.Summary, a.FiredAt.Format(time.RFC3339), a.Value)
+	if a.Rig != "" {
+		body += "rig: " + a.Rig + "\n"
+	}
+	return body + "\nSee gt alerts for the state of all rules."
+}
+
+// readEvents reads the events logged since cutoff, oldest first, including
+// rotated segments that may hold them.
+func readEvents(path string, cutoff time.Time) ([]events.Event, error) {
+	segments, err := logrotate.Segments(path)
+	if err != nil {
+		return nil, err
+	}
+	var readers []func() (io.ReadCloser, error)
+	for _, s := range segments {
+		if s.RotatedAt.Before(cutoff) {
+			continue // Everything in it predates the cutoff
+		}
+		readers = append(readers, s.Open)
+	}
+	readers = append(readers, func() (io.ReadCloser, error) { return os.Open(path) })
+
+	var result []events.Event
+	for _, open := range readers {
+		rc, err := open()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		scanner := bufio.NewScanner(rc)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			e, err := events.Parse(scanner.Bytes())
+			if err != nil || e.Time().Before(cutoff) {
+				continue
+			}
+			result = append(result, e)
+		}
+		err = scanner.Err()
+		_ = rc.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
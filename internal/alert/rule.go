@@ -0,0 +1,446 @@
+// Package alert evaluates alert rules over the town's event and metric
+// streams and runs the rules' actions when they fire.
+//
+// A rule watches one of four conditions:
+//   - event rate:    more (or fewer) events of a type than a threshold
+//     within a window, e.g. merge_failed above 3 per 1h
+//   - event absence: no event of a type for a duration, e.g. no
+//     patrol_complete from gastown/witness in 2h
+//   - metric value:  a town metric (as served at the daemon's /metrics)
+//     above or below a threshold, e.g. gastown_merge_queue_depth above 20
+//   - SLO budget:    a merge queue objective (see package slo) has
+//     exhausted its error budget
+//
+// When a rule starts firing its actions run: mail an address (the overseer
+// by default), post to a Slack or generic webhook, or pause a rig's merge
+// queue. Rules are configured under "alerts" in mayor/daemon.json and
+// evaluated by the daemon. Every objective under "slos" that no rule
+// refers to gets a default rule mailing the overseer (see EffectiveRules).
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/logrotate"
+	"github.com/steveyegge/gastown/internal/metrics"
+	"github.com/steveyegge/gastown/internal/slo"
+)
+
+// Action types.
+const (
+	ActionMail       = "mail"
+	ActionSlack      = "slack"
+	ActionWebhook    = "webhook"
+	ActionPauseQueue = "pause_queue"
+)
+
+// DefaultWindow is the window of an event rate rule that sets none.
+const DefaultWindow = time.Hour
+
+// DefaultInterval is how often the daemon evaluates alert rules.
+const DefaultInterval = time.Minute
+
+// Config is the "alerts" section of mayor/daemon.json.
+type Config struct {
+	// Interval is how often rules are evaluated, e.g. "30s" (default "1m").
+	Interval string `json:"interval,omitempty"`
+
+	// Rules are the alert rules.
+	Rules []Rule `json:"rules"`
+}
+
+// Rule is one alert rule. Set Event, Metric, or SLO to choose the condition.
+type Rule struct {
+	// Name identifies the rule in alerts, logs, and saved state.
+	Name string `json:"name"`
+
+	// Disabled stops evaluating the rule without removing it.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Event is the event type to watch, e.g. "merge_failed".
+	Event string `json:"event,omitempty"`
+
+	// Metric is the metric to watch, e.g. "gastown_merge_queue_depth".
+	// Samples matching Labels (and Rig) are summed.
+	Metric string `json:"metric,omitempty"`
+
+	// SLO is the name of a merge queue objective (under "slos"). The rule
+	// fires while that objective's error budget is exhausted.
+	SLO string `json:"slo,omitempty"`
+
+	// Labels restrict a metric rule to samples with these label values.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Rig restricts the rule to one rig's events or metric samples.
+	Rig string `json:"rig,omitempty"`
+
+	// Actor restricts an event rule to events from matching actors, as a
+	// glob, e.g. "gastown/witness" or "gastown/polecats/*".
+	Actor string `json:"actor,omitempty"`
+
+	// Above fires when the event count or metric value exceeds it.
+	Above *float64 `json:"above,omitempty"`
+
+	// Below fires when the event count or metric value falls under it.
+	Below *float64 `json:"below,omitempty"`
+
+	// Window is the span events are counted over, e.g. "1h" (default "1h").
+	Window string `json:"window,omitempty"`
+
+	// AbsentFor fires when no matching event has occurred for this long,
+	// e.g. "2h". Use instead of Above/Below.
+	AbsentFor string `json:"absent_for,omitempty"`
+
+	// Repeat re-runs the actions at this interval while the rule keeps
+	// firing, e.g. "4h" (default: only when it starts firing).
+	Repeat string `json:"repeat,omitempty"`
+
+	// Actions run when the rule fires.
+	Actions []Action `json:"actions"`
+}
+
+// Action is something done when a rule fires.
+type Action struct {
+	// Type is "mail", "slack", "webhook", or "pause_queue".
+	Type string `json:"type"`
+
+	// To is the mail address to notify (default "overseer").
+	To string `json:"to,omitempty"`
+
+	// URL is the Slack incoming webhook or generic webhook URL. It may
+	// reference environment variables, e.g. "${GT_SLACK_WEBHOOK}".
+	URL string `json:"url,omitempty"`
+
+	// Rig is the rig whose merge queue to pause (default: the rule's rig).
+	Rig string `json:"rig,omitempty"`
+}
+
+// Alert describes a rule that fired. It is the body of webhook actions.
+type Alert struct {
+	Town    string    `json:"town,omitempty"`
+	Rule    string    `json:"rule"`
+	Rig     string    `json:"rig,omitempty"`
+	Value   float64   `json:"value"`
+	Summary string    `json:"summary"`
+	FiredAt time.Time `json:"fired_at"`
+}
+
+// rule is a validated Rule with its durations parsed.
+type rule struct {
+	Rule
+	window    time.Duration
+	absentFor time.Duration
+	repeat    time.Duration
+
+	// objective is the SLO an SLO rule watches (set by the engine).
+	objective *slo.Objective
+}
+
+// compile validates a rule and parses its durations.
+func compile(r Rule) (*rule, error) {
+	if r.Name == "" {
+		return nil, errors.New("rule has no name")
+	}
+	c := &rule{Rule: r}
+	var err error
+	if c.window, err = logrotate.ParseDuration(r.Window); err != nil {
+		return nil, fmt.Errorf("rule %s: window: %w", r.Name, err)
+	}
+	if c.window == 0 {
+		c.window = DefaultWindow
+	}
+	if c.absentFor, err = logrotate.ParseDuration(r.AbsentFor); err != nil {
+		return nil, fmt.Errorf("rule %s: absent_for: %w", r.Name, err)
+	}
+	if c.repeat, err = logrotate.ParseDuration(r.Repeat); err != nil {
+		return nil, fmt.Errorf("rule %s: repeat: %w", r.Name, err)
+	}
+
+	threshold := r.Above != nil || r.Below != nil
+	switch {
+	case r.SLO != "":
+		if r.Event != "" || r.Metric != "" || threshold || r.AbsentFor != "" {
+			return nil, fmt.Errorf("rule %s: slo cannot be combined with event, metric, or thresholds", r.Name)
+		}
+	case r.Event != "" && r.Metric != "":
+		return nil, fmt.Errorf("rule %s: set event or metric, not both", r.Name)
+	case r.Event != "" && r.AbsentFor != "":
+		if threshold {
+			return nil, fmt.Errorf("rule %s: absent_for cannot be combined with above/below", r.Name)
+		}
+	case r.Event != "" || r.Metric != "":
+		if !threshold {
+			return nil, fmt.Errorf("rule %s: needs above or below", r.Name)
+		}
+		if r.AbsentFor != "" {
+			return nil, fmt.Errorf("rule %s: absent_for applies only to event rules", r.Name)
+		}
+	default:
+		return nil, fmt.Errorf("rule %s: needs an event, metric, or slo", r.Name)
+	}
+	if r.Actor != "" {
+		if _, err := path.Match(r.Actor, ""); err != nil {
+			return nil, fmt.Errorf("rule %s: actor: %w", r.Name, err)
+		}
+	}
+
+	if len(r.Actions) == 0 {
+		return nil, fmt.Errorf("rule %s: no actions", r.Name)
+	}
+	for _, a := range r.Actions {
+		switch a.Type {
+		case ActionMail:
+		case ActionSlack, ActionWebhook:
+			if a.URL == "" {
+				return nil, fmt.Errorf("rule %s: %s action needs a url", r.Name, a.Type)
+			}
+		case ActionPauseQueue:
+			if a.Rig == "" && r.Rig == "" {
+				return nil, fmt.Errorf("rule %s: pause_queue action needs a rig", r.Name)
+			}
+		default:
+			return nil, fmt.Errorf("rule %s: unknown action type %q", r.Name, a.Type)
+		}
+	}
+	return c, nil
+}
+
+// Describe summarizes a rule's condition, e.g. "merge_failed in gastown
+// above 3 per 1h". It does not validate the rule.
+func Describe(r Rule) string {
+	c := &rule{Rule: r}
+	c.window, _ = logrotate.ParseDuration(r.Window)
+	if c.window == 0 {
+		c.window = DefaultWindow
+	}
+	c.absentFor, _ = logrotate.ParseDuration(r.AbsentFor)
+
+	var desc string
+	switch {
+	case r.SLO != "":
+		return "error budget of SLO " + r.SLO + " exhausted"
+	case r.Metric != "":
+		labels := make(map[string]string, len(r.Labels)+1)
+		for k, v := range r.Labels {
+			labels[k] = v
+		}
+		if r.Rig != "" {
+			labels["rig"] = r.Rig
+		}
+		desc = r.Metric + formatLabels(labels)
+	case c.absentFor > 0:
+		return fmt.Sprintf("no %s%s for %s", r.Event, c.scope(), formatWindow(c.absentFor))
+	default:
+		desc = r.Event + c.scope()
+	}
+	if r.Above != nil {
+		desc += fmt.Sprintf(" above %g", *r.Above)
+	}
+	if r.Below != nil {
+		desc += fmt.Sprintf(" below %g", *r.Below)
+	}
+	if r.Event != "" {
+		desc += " per " + formatWindow(c.window)
+	}
+	return desc
+}
+
+// lookback is how far back in the events log the rule needs to see.
+func (r *rule) lookback() time.Duration {
+	switch {
+	case r.objective != nil:
+		_, window, _ := r.objective.Durations()
+		return window
+	case r.Event == "":
+		return 0
+	case r.absentFor > 0:
+		return r.absentFor
+	default:
+		return r.window
+	}
+}
+
+// matches reports whether an event counts toward the rule.
+func (r *rule) matches(e events.Event) bool {
+	if e.Type != r.Event {
+		return false
+	}
+	if r.Rig != "" && e.Rig != r.Rig {
+		return false
+	}
+	if r.Actor != "" {
+		if ok, _ := path.Match(r.Actor, e.Actor); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// result is the outcome of evaluating a rule.
+type result struct {
+	firing  bool
+	value   float64
+	summary string
+}
+
+// evaluate checks the rule against events (oldest first, covering at least
+// the rule's lookback) and metric families. armedAt is when the rule was
+// first evaluated: an absence rule cannot fire before it has watched for
+// its full duration, so a new rule or town does not alert immediately.
+func (r *rule) evaluate(evts []events.Event, families []*metrics.Family, now, armedAt time.Time) result {
+	if r.Metric != "" {
+		return r.evaluateMetric(families)
+	}
+	if r.objective != nil {
+		return r.evaluateSLO(evts, now)
+	}
+
+	if r.absentFor > 0 {
+		var last time.Time
+		for _, e := range evts {
+			if r.matches(e) {
+				if t := e.Time(); t.After(last) {
+					last = t
+				}
+			}
+		}
+		since := now.Sub(last)
+		if last.IsZero() {
+			since = now.Sub(armedAt)
+		}
+		res := result{value: since.Hours()}
+		if since >= r.absentFor {
+			res.firing = true
+			res.summary = fmt.Sprintf("no %s%s for %s", r.Event, r.scope(), formatWindow(r.absentFor))
+		}
+		return res
+	}
+
+	cutoff := now.Add(-r.window)
+	count := 0
+	for _, e := range evts {
+		if r.matches(e) && !e.Time().Before(cutoff) {
+			count++
+		}
+	}
+	res := result{value: float64(count)}
+	if cmp, ok := r.crossed(res.value); ok {
+		res.firing = true
+		res.summary = fmt.Sprintf("%d %s event(s)%s in the last %s (%s)", count, r.Event, r.scope(), formatWindow(r.window), cmp)
+	}
+	return res
+}
+
+// evaluateMetric sums the samples of the rule's metric that match its labels.
+func (r *rule) evaluateMetric(families []*metrics.Family) result {
+	labels := make(map[string]string, len(r.Labels)+1)
+	for k, v := range r.Labels {
+		labels[k] = v
+	}
+	if r.Rig != "" {
+		labels["rig"] = r.Rig
+	}
+
+	var res result
+	found := false
+	for _, f := range families {
+		if f.Name != r.Metric {
+			continue
+		}
+		for _, s := range f.Samples {
+			if labelsMatch(s.Labels, labels) {
+				res.value += s.Value
+				found = true
+			}
+		}
+	}
+	if !found {
+		return res // No data is not an alert
+	}
+	if cmp, ok := r.crossed(res.value); ok {
+		res.firing = true
+		res.summary = fmt.Sprintf("%s%s = %g (%s)", r.Metric, formatLabels(labels), res.value, cmp)
+	}
+	return res
+}
+
+// evaluateSLO measures the rule's objective. The value is the share of the
+// error budget remaining.
+func (r *rule) evaluateSLO(evts []events.Event, now time.Time) result {
+	st, err := slo.Measure(*r.objective, evts, now)
+	if err != nil {
+		return result{}
+	}
+	res := result{value: st.BudgetRemaining}
+	if st.Exhausted {
+		res.firing = true
+		res.summary = fmt.Sprintf("SLO %s: %s", st.Name, st.Summary())
+	}
+	return res
+}
+
+// crossed reports whether value is past the rule's threshold, and how.
+func (r *rule) crossed(value float64) (string, bool) {
+	if r.Above != nil && value > *r.Above {
+		return fmt.Sprintf("above %g", *r.Above), true
+	}
+	if r.Below != nil && value < *r.Below {
+		return fmt.Sprintf("below %g", *r.Below), true
+	}
+	return "", false
+}
+
+// scope describes the rig and actor filters of an event rule.
+func (r *rule) scope() string {
+	switch {
+	case r.Actor != "":
+		return " from " + r.Actor
+	case r.Rig != "":
+		return " in " + r.Rig
+	}
+	return ""
+}
+
+func labelsMatch(sample metrics.Labels, want map[string]string) bool {
+	for k, v := range want {
+		if sample[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + labels[k]
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// formatWindow formats a duration compactly: 90s, 30m, 2h, 1d.
+func formatWindow(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d >= time.Minute && d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return d.String()
+}
@@ -0,0 +1,127 @@
+// Package approval tracks the steps that wait on the human overseer.
+//
+// Anything that needs a person's say-so - a merge touching protected paths,
+// an override of an exceeded budget, nuking a polecat whose work would be
+// lost - files an approval bead instead of sending ordinary mail, where such
+// requests get lost. gt approvals lists every pending request across the
+// town, and gt approve / gt deny settle one in a single command, carrying
+// out whatever the request was holding back.
+//
+// Approval beads live in the beads database of the thing they gate: a merge
+// approval sits next to its MR, which depends on it, so the Refinery leaves
+// the MR alone until the approval closes. Town-wide requests (budgets) live
+// in the town's beads.
+package approval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Label marks approval beads.
+const Label = "gt:approval"
+
+// Kinds of approval.
+const (
+	KindMerge  = "merge"  // an MR touching protected paths; subject is the MR
+	KindBudget = "budget" // overriding an exceeded budget; subject is the budget scope
+	KindNuke   = "nuke"   // nuking a polecat with work at risk; subject is <rig>/<polecat>
+)
+
+// Approval states, recorded in the approval bead's status field.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusDenied   = "denied"
+)
+
+// Fields holds the structured fields of an approval bead.
+// They are stored as key: value lines at the top of its description.
+type Fields struct {
+	Kind        string
+	Subject     string // what is held back (see the Kind constants)
+	Rig         string
+	RequestedBy string // agent that asked
+	Notify      string // mail address told of the decision
+	Status      string // pending, approved, or denied
+	DecidedBy   string
+	Note        string // overseer's note with the decision
+}
+
+// ParseFields extracts approval fields from an issue's description.
+// Returns nil if the issue has no approval fields.
+func ParseFields(issue *beads.Issue) *Fields {
+	if issue == nil {
+		return nil
+	}
+	kv := beads.ParseFieldHeader(issue.Description)
+	f := &Fields{
+		Kind:        kv["approval_kind"],
+		Subject:     kv["subject"],
+		Rig:         kv["rig"],
+		RequestedBy: kv["requested_by"],
+		Notify:      kv["notify"],
+		Status:      kv["approval_status"],
+		DecidedBy:   kv["decided_by"],
+		Note:        kv["decision_note"],
+	}
+	if *f == (Fields{}) {
+		return nil
+	}
+	return f
+}
+
+// Format renders the fields as description lines. Empty fields are omitted.
+func (f *Fields) Format() string {
+	return beads.FormatFieldHeader(
+		beads.Field{Key: "approval_kind", Value: f.Kind},
+		beads.Field{Key: "subject", Value: f.Subject},
+		beads.Field{Key: "rig", Value: f.Rig},
+		beads.Field{Key: "requested_by", Value: f.RequestedBy},
+		beads.Field{Key: "notify", Value: f.Notify},
+		beads.Field{Key: "approval_status", Value: f.Status},
+		beads.Field{Key: "decided_by", Value: f.DecidedBy},
+		beads.Field{Key: "decision_note", Value: f.Note},
+	)
+}
+
+// Describe builds an approval bead description: the fields, the details of
+// the request, and the commands that settle it.
+func Describe(id string, f *Fields, details string) string {
+	var b strings.Builder
+	b.WriteString(f.Format())
+	b.WriteString("\n\n")
+	if details = strings.TrimSpace(details); details != "" {
+		b.WriteString(details)
+		b.WriteString("\n\n")
+	}
+	b.WriteString(Effects(f.Kind))
+	b.WriteString("\n")
+	if id != "" {
+		fmt.Fprintf(&b, "\nApprove:  gt approve %s\n", id)
+		fmt.Fprintf(&b, "Deny:     gt deny %s -m \"<why>\"\n", id)
+	}
+	return b.String()
+}
+
+// Effects describes what approving and denying a request of kind do.
+func Effects(kind string) string {
+	switch kind {
+	case KindMerge:
+		return "Approving releases the MR into the merge queue. Denying rejects it."
+	case KindBudget:
+		return "Approving lets work continue past the budget. Denying tells the reporter to stop."
+	case KindNuke:
+		return "Approving nukes the polecat, discarding its unsaved work. Denying leaves it alone."
+	default:
+		return "Approving or denying notifies the requester."
+	}
+}
+
+// withFields replaces the field lines of an approval description, keeping
+// the text that follows them.
+func withFields(description string, f *Fields) string {
+	return beads.SetFieldHeader(description, f.Format())
+}
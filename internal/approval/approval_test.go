@@ -0,0 +1,78 @@
+package approval
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestFieldsRoundTrip(t *testing.T) {
+	f := &Fields{
+		Kind:        KindMerge,
+		Subject:     "gt-mr1",
+		Rig:         "gastown",
+		RequestedBy: "gastown/polecats/Toast",
+		Notify:      "gastown/Toast",
+		Status:      StatusPending,
+		DecidedBy:   "overseer",
+		Note:        "ship\nit",
+	}
+	got := ParseFields(&beads.Issue{Description: Describe("gt-ap1", f, "Touches migrations/001.sql")})
+	if got == nil {
+		t.Fatal("ParseFields returned nil")
+	}
+	want := *f
+	want.Note = "ship it" // newlines are folded so the note parses back
+	if *got != want {
+		t.Errorf("round trip = %+v, want %+v", *got, want)
+	}
+	if f := ParseFields(&beads.Issue{Description: "just prose"}); f != nil {
+		t.Errorf("ParseFields(prose) = %+v, want nil", f)
+	}
+}
+
+func TestParseFieldsIgnoresDetails(t *testing.T) {
+	f := &Fields{Kind: KindMerge, Subject: "gt-mr1", Rig: "gastown"}
+	got := ParseFields(&beads.Issue{Description: Describe("gt-ap1", f, "rig: other\nsubject: gt-mr2")})
+	if got == nil || got.Rig != "gastown" || got.Subject != "gt-mr1" {
+		t.Errorf("ParseFields = %+v, want fields from the header only", got)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	desc := Describe("gt-ap1", &Fields{Kind: KindNuke, Subject: "gastown/Toast"}, "Uncommitted changes: 3 files")
+	for _, want := range []string{
+		"approval_kind: nuke",
+		"Uncommitted changes: 3 files",
+		Effects(KindNuke),
+		"gt approve gt-ap1",
+		"gt deny gt-ap1",
+	} {
+		if !strings.Contains(desc, want) {
+			t.Errorf("description missing %q:\n%s", want, desc)
+		}
+	}
+	if strings.Contains(Describe("", &Fields{Kind: KindBudget}, ""), "gt approve") {
+		t.Error("description without an ID names commands")
+	}
+}
+
+func TestWithFieldsKeepsDetails(t *testing.T) {
+	f := &Fields{Kind: KindBudget, Subject: "town", Status: StatusPending}
+	desc := Describe("hq-ap1", f, "Spent $120 of $100 this week")
+
+	f.Status = StatusApproved
+	f.DecidedBy = "overseer"
+	updated := withFields(desc, f)
+
+	got := ParseFields(&beads.Issue{Description: updated})
+	if got.Status != StatusApproved || got.DecidedBy != "overseer" {
+		t.Errorf("fields not updated: %+v", got)
+	}
+	_, wantBody, _ := strings.Cut(desc, "\n\n")
+	_, gotBody, _ := strings.Cut(updated, "\n\n")
+	if gotBody != wantBody {
+		t.Errorf("details changed:\n%s\nwant:\n%s", gotBody, wantBody)
+	}
+}
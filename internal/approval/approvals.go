@@ -0,0 +1,118 @@
+package approval
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// ErrNotFound is returned when there is no pending approval for an ID.
+var ErrNotFound = errors.New("no pending approval")
+
+// Approvals manages approval beads in one beads database.
+type Approvals struct {
+	bd *beads.Beads
+}
+
+// New returns an Approvals backed by bd.
+func New(bd *beads.Beads) *Approvals {
+	return &Approvals{bd: bd}
+}
+
+// Request files a pending approval. A merge approval also blocks its MR,
+// which must be in the same database.
+func (a *Approvals) Request(f *Fields, title, details string) (*beads.Issue, error) {
+	f.Status = StatusPending
+	issue, err := a.bd.Create(beads.CreateOptions{
+		Title:       "Approval: " + title,
+		Type:        "approval",
+		Priority:    1,
+		Description: Describe("", f, details),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating approval bead: %w", err)
+	}
+	// Now that the bead has an ID, spell out the commands that settle it.
+	description := Describe(issue.ID, f, details)
+	_ = a.bd.Update(issue.ID, beads.UpdateOptions{Description: &description})
+	issue.Description = description
+
+	if f.Kind == KindMerge {
+		if err := a.bd.AddDependency(f.Subject, issue.ID); err != nil {
+			// An unlinked approval wouldn't gate anything; don't leave it around.
+			_ = a.bd.CloseWithReason("failed to block MR on approval", issue.ID)
+			return nil, fmt.Errorf("blocking %s on approval %s: %w", f.Subject, issue.ID, err)
+		}
+	}
+	return issue, nil
+}
+
+// Pending returns the approvals awaiting a decision.
+func (a *Approvals) Pending() ([]*beads.Issue, error) {
+	issues, err := a.bd.List(beads.ListOptions{
+		Status:   "all",
+		Label:    Label,
+		Priority: -1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var pending []*beads.Issue
+	for _, issue := range issues {
+		if issue.Status != "closed" {
+			pending = append(pending, issue)
+		}
+	}
+	return pending, nil
+}
+
+// For returns the pending approval of kind for subject, or ErrNotFound.
+func (a *Approvals) For(kind, subject string) (*beads.Issue, *Fields, error) {
+	issues, err := a.Pending()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, issue := range issues {
+		if f := ParseFields(issue); f != nil && f.Kind == kind && f.Subject == subject {
+			return issue, f, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w for %s %s", ErrNotFound, kind, subject)
+}
+
+// Get returns the pending approval with the given bead ID.
+func (a *Approvals) Get(id string) (*beads.Issue, *Fields, error) {
+	issue, err := a.bd.Show(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	f := ParseFields(issue)
+	if !beads.HasLabel(issue, Label) || f == nil {
+		return nil, nil, fmt.Errorf("%s is not an approval", id)
+	}
+	if issue.Status == "closed" {
+		return nil, nil, fmt.Errorf("%w: %s was already %s", ErrNotFound, id, f.Status)
+	}
+	return issue, f, nil
+}
+
+// Decide records the overseer's decision and closes the approval. Closing
+// a merge approval unblocks its MR; the caller rejects the MR on denial.
+func (a *Approvals) Decide(issue *beads.Issue, f *Fields, approved bool, by, note string) error {
+	f.Status = StatusDenied
+	if approved {
+		f.Status = StatusApproved
+	}
+	f.DecidedBy = by
+	f.Note = note
+	description := withFields(issue.Description, f)
+	if err := a.bd.Update(issue.ID, beads.UpdateOptions{Description: &description}); err != nil {
+		return err
+	}
+	reason := f.Status
+	if note != "" {
+		reason += ": " + note
+	}
+	return a.bd.CloseWithReason(reason, issue.ID)
+}
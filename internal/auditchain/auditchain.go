@@ -0,0 +1,309 @@
+// Package auditchain maintains a tamper-evident, append-only audit log for
+// regulated environments.
+//
+// Each entry records a sensitive action (a merge to a protected branch, a
+// configuration change, a forced removal) together with the SHA-256 hash of
+// the previous entry, and its own hash over its contents. Editing, deleting,
+// inserting, or reordering entries breaks the chain, which Verify detects.
+//
+// The chain alone cannot reveal entries cut from its end. Record the head
+// (seq and hash, see Head) somewhere outside the town and pass it to Verify
+// to detect truncation as well.
+//
+// The log lives at <town>/logs/audit.jsonl. Unlike the town and events logs
+// it is never rotated.
+package auditchain
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// File is the audit log's name within the town's logs directory.
+const File = "audit.jsonl"
+
+// Audited actions.
+const (
+	ActionMerge        = "merge"         // Merge to a protected or default branch
+	ActionConfigChange = "config_change" // Town or rig configuration changed
+	ActionForceRemove  = "force_remove"  // Agent or worktree removed bypassing safety checks
+)
+
+// Entry is one link in the audit chain.
+type Entry struct {
+	// Seq numbers entries from 1 without gaps.
+	Seq int64 `json:"seq"`
+
+	// Timestamp is when the action happened (RFC3339, UTC).
+	Timestamp string `json:"ts"`
+
+	// Actor is who performed the action (agent address or "overseer").
+	Actor string `json:"actor"`
+
+	// Action is one of the Action constants.
+	Action string `json:"action"`
+
+	// Subject is what the action affected (branch, config key, agent).
+	Subject string `json:"subject"`
+
+	// Details holds action-specific context (commit, old/new value, ...).
+	Details map[string]string `json:"details,omitempty"`
+
+	// Prev is the hash of the previous entry ("" for the first).
+	Prev string `json:"prev"`
+
+	// Hash is the SHA-256 over this entry with Hash empty, hex-encoded.
+	Hash string `json:"hash"`
+}
+
+// computeHash returns the hash an entry should carry.
+func (e Entry) computeHash() string {
+	e.Hash = ""
+	data, _ := json.Marshal(e) // Struct fields in order, map keys sorted
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Path returns the audit log path for a town.
+func Path(townRoot string) string {
+	return filepath.Join(townRoot, "logs", File)
+}
+
+// Record appends an action to the town's audit chain.
+func Record(townRoot, action, actor, subject string, details map[string]string) error {
+	_, err := Append(townRoot, Entry{Action: action, Actor: actor, Subject: subject, Details: details})
+	return err
+}
+
+// Append links e to the end of the town's audit chain and writes it,
+// filling in Seq, Prev, Hash, and (if empty) Timestamp. Appends from
+// concurrent processes are serialized with a file lock. Append refuses to
+// extend a chain whose last entry is unreadable.
+func Append(townRoot string, e Entry) (Entry, error) {
+	path := Path(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return Entry{}, fmt.Errorf("creating logs directory: %w", err)
+	}
+
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return Entry{}, fmt.Errorf("locking audit log: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return Entry{}, fmt.Errorf("opening audit log: %w", err)
+	}
+	defer f.Close()
+
+	last, err := lastEntry(f)
+	if err != nil {
+		return Entry{}, err
+	}
+	if last != nil {
+		e.Seq, e.Prev = last.Seq+1, last.Hash
+	} else {
+		e.Seq, e.Prev = 1, ""
+	}
+	if e.Timestamp == "" {
+		e.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	e.Hash = e.computeHash()
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return Entry{}, err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return Entry{}, fmt.Errorf("writing audit log: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return Entry{}, fmt.Errorf("syncing audit log: %w", err)
+	}
+	return e, nil
+}
+
+// lastEntry reads the final entry of the log, or nil if it is empty.
+func lastEntry(f *os.File) (*Entry, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return nil, nil
+	}
+
+	// Read backwards in growing chunks until a complete last line is found
+	for chunk := int64(4096); ; chunk *= 4 {
+		if chunk > size {
+			chunk = size
+		}
+		buf := make([]byte, chunk)
+		if _, err := f.ReadAt(buf, size-chunk); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("reading audit log: %w", err)
+		}
+		if buf[len(buf)-1] != '\n' {
+			return nil, errors.New("audit log ends with a partial entry; run gt audit verify")
+		}
+		buf = buf[:len(buf)-1]
+		start := bytes.LastIndexByte(buf, '\n')
+		if start < 0 && chunk < size {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(buf[start+1:], &e); err != nil || e.Hash == "" {
+			return nil, errors.New("audit log's last entry is unreadable; run gt audit verify")
+		}
+		return &e, nil
+	}
+}
+
+// Problem is a break in the audit chain.
+type Problem struct {
+	Line   int    `json:"line"`
+	Seq    int64  `json:"seq,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Result is the outcome of verifying an audit chain.
+type Result struct {
+	// Entries is the number of readable entries.
+	Entries int `json:"entries"`
+
+	// Head is the last readable entry, if any.
+	Head *Entry `json:"head,omitempty"`
+
+	// Problems lists every break found, in log order. Empty means intact.
+	Problems []Problem `json:"problems,omitempty"`
+}
+
+// OK reports whether the chain verified without problems.
+func (r Result) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Anchor is a previously recorded chain head. Verify checks the chain still
+// contains it unchanged, which detects entries truncated from the end.
+type Anchor struct {
+	Seq  int64
+	Hash string
+}
+
+// ParseAnchor parses an anchor written as "<seq>:<hash>" (see Head).
+func ParseAnchor(s string) (Anchor, error) {
+	var a Anchor
+	var hash string
+	if _, err := fmt.Sscanf(s, "%d:%s", &a.Seq, &hash); err != nil || a.Seq < 1 || len(hash) != sha256.Size*2 {
+		return Anchor{}, fmt.Errorf("invalid anchor %q: want <seq>:<sha256>", s)
+	}
+	a.Hash = hash
+	return a, nil
+}
+
+// String formats the anchor as "<seq>:<hash>".
+func (a Anchor) String() string {
+	return fmt.Sprintf("%d:%s", a.Seq, a.Hash)
+}
+
+// Head returns the anchor of an entry.
+func Head(e Entry) Anchor {
+	return Anchor{Seq: e.Seq, Hash: e.Hash}
+}
+
+// Verify checks every link of the chain read from r: that each entry's hash
+// matches its contents, that it names the previous entry's hash, and that
+// sequence numbers run from 1 without gaps. If anchors are given, each must
+// still be present with the same hash.
+func Verify(r io.Reader, anchors ...Anchor) (Result, error) {
+	var res Result
+	problem := func(line int, seq int64, format string, args ...interface{}) {
+		res.Problems = append(res.Problems, Problem{Line: line, Seq: seq, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	hashes := make(map[int64]string)
+	var prev *Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.Hash == "" {
+			problem(line, 0, "unreadable entry")
+			continue
+		}
+		res.Entries++
+
+		if got := e.computeHash(); got != e.Hash {
+			problem(line, e.Seq, "entry modified: hash does not match contents")
+		}
+		switch {
+		case prev == nil && e.Seq != 1:
+			problem(line, e.Seq, "chain starts at seq %d: %s missing", e.Seq, seqRange(1, e.Seq-1))
+		case prev == nil && e.Prev != "":
+			problem(line, e.Seq, "first entry links to a previous entry")
+		case prev != nil && e.Seq != prev.Seq+1:
+			if e.Seq > prev.Seq+1 {
+				problem(line, e.Seq, "gap: %s missing", seqRange(prev.Seq+1, e.Seq-1))
+			} else {
+				problem(line, e.Seq, "out of order: seq %d follows %d", e.Seq, prev.Seq)
+			}
+		}
+		if prev != nil && e.Prev != prev.Hash {
+			problem(line, e.Seq, "broken link: previous hash does not match entry %d", prev.Seq)
+		}
+
+		hashes[e.Seq] = e.Hash
+		entry := e
+		prev = &entry
+	}
+	if err := scanner.Err(); err != nil {
+		return res, err
+	}
+	res.Head = prev
+
+	for _, a := range anchors {
+		switch hash, ok := hashes[a.Seq]; {
+		case !ok:
+			problem(0, a.Seq, "anchored entry %d missing: log truncated", a.Seq)
+		case hash != a.Hash:
+			problem(0, a.Seq, "anchored entry %d changed since the anchor was recorded", a.Seq)
+		}
+	}
+	return res, nil
+}
+
+// seqRange describes the entries from..to for a problem report.
+func seqRange(from, to int64) string {
+	if from == to {
+		return fmt.Sprintf("entry %d", from)
+	}
+	return fmt.Sprintf("entries %d-%d", from, to)
+}
+
+// VerifyTown verifies the town's audit chain. A town without an audit log
+// verifies as an empty chain (unless anchors say it should have entries).
+func VerifyTown(townRoot string, anchors ...Anchor) (Result, error) {
+	f, err := os.Open(Path(townRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Verify(bytes.NewReader(nil), anchors...)
+		}
+		return Result{}, err
+	}
+	defer f.Close()
+	return Verify(f, anchors...)
+}
@@ -0,0 +1,154 @@
+package auditchain
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+// writeChain appends n config changes to a fresh town and returns the
+// town root and the log's lines.
+func writeChain(t *testing.T, n int) (string, []string) {
+	t.Helper()
+	townRoot := t.TempDir()
+	for i := 0; i < n; i++ {
+		err := Record(townRoot, ActionConfigChange, "overseer", "default-agent", map[string]string{"new": string(rune('a' + i))})
+		if err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	data, err := os.ReadFile(Path(townRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return townRoot, strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func verifyLines(t *testing.T, lines []string, anchors ...Anchor) Result {
+	t.Helper()
+	res, err := Verify(strings.NewReader(strings.Join(lines, "\n")+"\n"), anchors...)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	return res
+}
+
+func TestAppendLinksEntries(t *testing.T) {
+	townRoot, lines := writeChain(t, 3)
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+
+	res, err := VerifyTown(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.OK() || res.Entries != 3 {
+		t.Fatalf("VerifyTown = %+v, want 3 intact entries", res)
+	}
+	if res.Head.Seq != 3 || res.Head.Details["new"] != "c" {
+		t.Errorf("head = %+v, want seq 3", res.Head)
+	}
+
+	e, err := Append(townRoot, Entry{Action: ActionForceRemove, Actor: "mayor", Subject: "gastown/polecats/toast"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Seq != 4 || e.Prev != res.Head.Hash || e.Hash != e.computeHash() {
+		t.Errorf("appended %+v, want seq 4 linked to %s", e, res.Head.Hash)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	_, lines := writeChain(t, 4)
+
+	tests := []struct {
+		name   string
+		mutate func([]string) []string
+		want   string
+	}{
+		{"edit", func(l []string) []string {
+			l[1] = strings.Replace(l[1], `"new":"b"`, `"new":"z"`, 1)
+			return l
+		}, "entry modified"},
+		{"delete", func(l []string) []string {
+			return append(l[:2:2], l[3:]...)
+		}, "gap: entry 3 missing"},
+		{"reorder", func(l []string) []string {
+			l[1], l[2] = l[2], l[1]
+			return l
+		}, "out of order"},
+		{"delete first", func(l []string) []string {
+			return l[1:]
+		}, "entry 1 missing"},
+		{"garbage", func(l []string) []string {
+			l[2] = "{not json"
+			return l
+		}, "unreadable entry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := verifyLines(t, tt.mutate(append([]string(nil), lines...)))
+			if res.OK() {
+				t.Fatalf("tampering not detected: %+v", res)
+			}
+			found := false
+			for _, p := range res.Problems {
+				if strings.Contains(p.Reason, tt.want) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("problems = %+v, want one containing %q", res.Problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyAnchors(t *testing.T) {
+	townRoot, lines := writeChain(t, 3)
+	full, err := VerifyTown(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anchor, err := ParseAnchor(Head(*full.Head).String())
+	if err != nil {
+		t.Fatalf("ParseAnchor: %v", err)
+	}
+
+	if res := verifyLines(t, lines, anchor); !res.OK() {
+		t.Errorf("intact chain failed anchor check: %+v", res.Problems)
+	}
+
+	// Truncation leaves a valid chain that only the anchor exposes
+	truncated := lines[:2]
+	if res := verifyLines(t, truncated); !res.OK() {
+		t.Fatalf("truncated chain should link cleanly: %+v", res.Problems)
+	}
+	if res := verifyLines(t, truncated, anchor); res.OK() || !strings.Contains(res.Problems[0].Reason, "truncated") {
+		t.Errorf("truncation not detected: %+v", res.Problems)
+	}
+
+	if _, err := ParseAnchor("3:abc"); err == nil {
+		t.Error("ParseAnchor accepted a short hash")
+	}
+}
+
+func TestAppendRefusesPartialTail(t *testing.T) {
+	townRoot, _ := writeChain(t, 2)
+	f, err := os.OpenFile(Path(townRoot), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"seq":3,"ts":"2026-01-`)
+	f.Close()
+
+	if err := Record(townRoot, ActionMerge, "gastown/refinery", "main", nil); err == nil {
+		t.Fatal("Append extended a chain ending in a partial entry")
+	}
+	data, _ := os.ReadFile(Path(townRoot))
+	if bytes.Count(data, []byte("\n")) != 2 {
+		t.Errorf("log changed after refused append:\n%s", data)
+	}
+}
@@ -0,0 +1,590 @@
+// Package backup archives a town's state and restores it.
+//
+// A backup is a gzipped tar of everything in the town that is not a repo
+// checkout: configs, town and rig beads (and with them mail, merge
+// requests, and wisps), name pools, and other runtime metadata. Clones and
+// worktrees are left out, except for the gt state in them, such as their
+// .beads directories, which hold the rigs' canonical beads. The archive ends with a manifest giving the
+// SHA-256 of every file, so it can be verified before it is restored.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FormatVersion is the archive format version written to manifests.
+const FormatVersion = 1
+
+// ManifestName is the name of the manifest entry, the archive's last.
+const ManifestName = "MANIFEST.json"
+
+// townPrefix is the directory town files are stored under in the archive.
+const townPrefix = "town/"
+
+// readAttempts bounds how often a file that changes while it is being
+// read is reread.
+const readAttempts = 3
+
+// ErrChanged is returned by Create when beads changed during the backup,
+// so the archive may not be a consistent snapshot of the work graph.
+var ErrChanged = errors.New("town beads changed during backup")
+
+// Manifest describes a backup.
+type Manifest struct {
+	Version int       `json:"version"`
+	Town    string    `json:"town"`
+	Host    string    `json:"host,omitempty"`
+	Created time.Time `json:"created"`
+	Files   []File    `json:"files"`
+}
+
+// File is one file, directory, or symlink in a backup.
+type File struct {
+	// Path is slash-separated and relative to the town root.
+	Path   string      `json:"path"`
+	Mode   fs.FileMode `json:"mode"`
+	Size   int64       `json:"size,omitempty"`
+	SHA256 string      `json:"sha256,omitempty"`
+	Link   string      `json:"link,omitempty"`
+}
+
+// Size returns the total size of the backup's files.
+func (m *Manifest) Size() int64 {
+	var n int64
+	for _, f := range m.Files {
+		n += f.Size
+	}
+	return n
+}
+
+// Options configures Create.
+type Options struct {
+	// Town is the town's name, recorded in the manifest.
+	Town string
+
+	// Exclude lists absolute paths to leave out, e.g. the archive itself
+	// when it is written inside the town.
+	Exclude []string
+}
+
+// skipSuffixes are runtime files that must not be restored: sockets, pid
+// files, and locks belong to processes that no longer exist.
+var skipSuffixes = []string{".sock", ".pid", ".lock"}
+
+// checkoutState lists the gt state kept inside checkouts, which is
+// otherwise left out of backups: beads (or their redirect), runtime
+// metadata, and crew workers' state and mail.
+var checkoutState = []string{".beads", ".runtime", "state.json", "mail"}
+
+// Collect returns what a backup of townRoot includes, in walk order.
+// Directories holding a git checkout (a .git file or directory) are left
+// out except for the gt state in them (see checkoutState); bare repos
+// (*.git) are left out entirely.
+func Collect(townRoot string, exclude ...string) ([]File, error) {
+	skip := make(map[string]bool, len(exclude))
+	for _, p := range exclude {
+		skip[filepath.Clean(p)] = true
+	}
+	var files []File
+	if err := collect(townRoot, "", skip, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func collect(townRoot, rel string, skip map[string]bool, files *[]File) error {
+	entries, err := os.ReadDir(filepath.Join(townRoot, filepath.FromSlash(rel)))
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		p := path.Join(rel, name)
+		full := filepath.Join(townRoot, filepath.FromSlash(p))
+		if skip[full] || name == ".git" || skipped(name) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // removed since ReadDir
+			}
+			return err
+		}
+
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(full)
+			if err != nil {
+				return err
+			}
+			*files = append(*files, File{Path: p, Mode: info.Mode(), Link: link})
+		case info.IsDir():
+			if strings.HasSuffix(name, ".git") {
+				continue
+			}
+			if isCheckout(full) {
+				*files = append(*files, File{Path: p, Mode: info.Mode()})
+				for _, keep := range checkoutState {
+					fi, err := os.Lstat(filepath.Join(full, keep))
+					if err != nil {
+						continue
+					}
+					if err := collectEntry(townRoot, path.Join(p, keep), fi, skip, files); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			*files = append(*files, File{Path: p, Mode: info.Mode()})
+			if err := collect(townRoot, p, skip, files); err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			*files = append(*files, File{Path: p, Mode: info.Mode(), Size: info.Size()})
+		}
+	}
+	return nil
+}
+
+// collectEntry adds gt state kept in a checkout: a file, a directory, or
+// a symlink.
+func collectEntry(townRoot, p string, info fs.FileInfo, skip map[string]bool, files *[]File) error {
+	full := filepath.Join(townRoot, filepath.FromSlash(p))
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		link, err := os.Readlink(full)
+		if err != nil {
+			return err
+		}
+		*files = append(*files, File{Path: p, Mode: info.Mode(), Link: link})
+	case info.IsDir():
+		*files = append(*files, File{Path: p, Mode: info.Mode()})
+		return collect(townRoot, p, skip, files)
+	case info.Mode().IsRegular():
+		*files = append(*files, File{Path: p, Mode: info.Mode(), Size: info.Size()})
+	}
+	return nil
+}
+
+func skipped(name string) bool {
+	for _, s := range skipSuffixes {
+		if strings.HasSuffix(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func isCheckout(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// Create writes a backup of townRoot to w and returns its manifest.
+//
+// Agents may be working while the backup runs. A file that changes while
+// it is read is reread; if beads changed after they were archived, Create
+// returns ErrChanged, since a database and its write-ahead log may no
+// longer match, and the caller should discard the archive and retry.
+func Create(townRoot string, w io.Writer, opts Options) (*Manifest, error) {
+	files, err := Collect(townRoot, opts.Exclude...)
+	if err != nil {
+		return nil, fmt.Errorf("listing town files: %w", err)
+	}
+	host, _ := os.Hostname()
+	m := &Manifest{Version: FormatVersion, Town: opts.Town, Host: host, Created: time.Now().UTC()}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	stamps := make(map[string]fs.FileInfo)
+	for _, f := range files {
+		full := filepath.Join(townRoot, filepath.FromSlash(f.Path))
+		hdr := &tar.Header{Name: townPrefix + f.Path, Mode: int64(f.Mode.Perm()), ModTime: m.Created}
+		var data []byte
+		switch {
+		case f.Link != "":
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = f.Link
+		case f.Mode.IsDir():
+			hdr.Typeflag = tar.TypeDir
+			hdr.Name += "/"
+		default:
+			var info fs.FileInfo
+			data, info, err = readStable(full)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue // removed since Collect
+				}
+				return nil, fmt.Errorf("reading %s: %w", f.Path, err)
+			}
+			sum := sha256.Sum256(data)
+			f.Size = int64(len(data))
+			f.SHA256 = hex.EncodeToString(sum[:])
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = f.Size
+			hdr.ModTime = info.ModTime()
+			if inBeads(f.Path) {
+				stamps[full] = info
+			}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, err
+		}
+		m.Files = append(m.Files, f)
+	}
+
+	for full, before := range stamps {
+		if after, err := os.Stat(full); err != nil || changed(before, after) {
+			return nil, ErrChanged
+		}
+	}
+
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	hdr := &tar.Header{Name: ManifestName, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(manifest)), ModTime: m.Created}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(manifest); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return m, gz.Close()
+}
+
+// readStable reads a file, rereading it if it changes meanwhile.
+func readStable(path string) ([]byte, fs.FileInfo, error) {
+	for attempt := 1; ; attempt++ {
+		before, err := os.Stat(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := os.ReadFile(path) //nolint:gosec // G304: path is from the town walk
+		if err != nil {
+			return nil, nil, err
+		}
+		after, err := os.Stat(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !changed(before, after) && int64(len(data)) == after.Size() || attempt == readAttempts {
+			return data, after, nil
+		}
+	}
+}
+
+func changed(before, after fs.FileInfo) bool {
+	return before.Size() != after.Size() || !before.ModTime().Equal(after.ModTime())
+}
+
+// inBeads reports whether a path is inside a .beads directory.
+func inBeads(p string) bool {
+	return strings.HasPrefix(p, ".beads/") || strings.Contains(p, "/.beads/")
+}
+
+// CreateFile writes a backup of townRoot to the file archive, retrying if
+// beads change during the backup, then verifies what it wrote.
+func CreateFile(townRoot, archive string, opts Options) (*Manifest, error) {
+	abs, err := filepath.Abs(archive)
+	if err != nil {
+		return nil, err
+	}
+	tmp := abs + ".tmp"
+	opts.Exclude = append(opts.Exclude, abs, tmp)
+
+	for attempt := 1; ; attempt++ {
+		m, err := createFile(townRoot, tmp, opts)
+		if errors.Is(err, ErrChanged) && attempt < readAttempts {
+			continue
+		}
+		if err != nil {
+			_ = os.Remove(tmp)
+			return nil, err
+		}
+		if _, err := VerifyFile(tmp); err != nil {
+			_ = os.Remove(tmp)
+			return nil, fmt.Errorf("verifying backup: %w", err)
+		}
+		if err := os.Rename(tmp, abs); err != nil {
+			_ = os.Remove(tmp)
+			return nil, err
+		}
+		return m, nil
+	}
+}
+
+func createFile(townRoot, archive string, opts Options) (*Manifest, error) {
+	f, err := os.OpenFile(archive, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600) //nolint:gosec // G304: path is chosen by the user
+	if err != nil {
+		return nil, err
+	}
+	m, err := Create(townRoot, f, opts)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return m, err
+}
+
+// Verify reads a backup and checks every file in it against its manifest.
+func Verify(r io.Reader) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a backup archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	seen := make(map[string]File)
+	var m *Manifest
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+		if m != nil {
+			return nil, fmt.Errorf("entry %s after the manifest", hdr.Name)
+		}
+		if hdr.Name == ManifestName {
+			m = &Manifest{}
+			if err := json.NewDecoder(tr).Decode(m); err != nil {
+				return nil, fmt.Errorf("reading manifest: %w", err)
+			}
+			continue
+		}
+		p, err := entryPath(hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := seen[p]; dup {
+			return nil, fmt.Errorf("%s is in the archive more than once", p)
+		}
+		f := File{Path: p, Link: hdr.Linkname}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			f.Mode = fs.ModeDir
+		case tar.TypeSymlink:
+			f.Mode = fs.ModeSymlink
+		case tar.TypeReg:
+		default:
+			return nil, fmt.Errorf("%s has unsupported type %q", p, hdr.Typeflag)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			h := sha256.New()
+			n, err := io.Copy(h, tr)
+			if err != nil {
+				return nil, fmt.Errorf("reading %s: %w", p, err)
+			}
+			f.Size = n
+			f.SHA256 = hex.EncodeToString(h.Sum(nil))
+		}
+		seen[p] = f
+	}
+	if m == nil {
+		return nil, errors.New("archive has no manifest (truncated?)")
+	}
+	if m.Version > FormatVersion {
+		return nil, fmt.Errorf("backup format %d is newer than this gt supports (%d)", m.Version, FormatVersion)
+	}
+
+	for _, want := range m.Files {
+		got, ok := seen[want.Path]
+		if !ok {
+			return nil, fmt.Errorf("%s is in the manifest but not the archive", want.Path)
+		}
+		if got.Mode.Type() != want.Mode.Type() || got.SHA256 != want.SHA256 || got.Size != want.Size || got.Link != want.Link {
+			return nil, fmt.Errorf("%s does not match the manifest (corrupt archive)", want.Path)
+		}
+		delete(seen, want.Path)
+	}
+	for p := range seen {
+		return nil, fmt.Errorf("%s is in the archive but not the manifest", p)
+	}
+
+	// Nothing may be restored through a symlink, which could point
+	// anywhere; backups never contain entries below one.
+	links := make(map[string]bool)
+	for _, f := range m.Files {
+		if f.Link != "" {
+			links[f.Path] = true
+		}
+	}
+	for _, f := range m.Files {
+		for dir := path.Dir(f.Path); dir != "."; dir = path.Dir(dir) {
+			if links[dir] {
+				return nil, fmt.Errorf("%s is below symlink %s", f.Path, dir)
+			}
+		}
+	}
+	return m, nil
+}
+
+// VerifyFile verifies the backup at archive.
+func VerifyFile(archive string) (*Manifest, error) {
+	f, err := os.Open(archive) //nolint:gosec // G304: path is chosen by the user
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Verify(f)
+}
+
+// entryPath returns the town-relative path of an archive entry, rejecting
+// entries that would land outside the town.
+func entryPath(name string) (string, error) {
+	p, ok := strings.CutPrefix(name, townPrefix)
+	p = strings.TrimSuffix(p, "/")
+	if !ok || p == "" || path.IsAbs(p) || path.Clean(p) != p || p == ".." || strings.HasPrefix(p, "../") {
+		return "", fmt.Errorf("invalid archive entry %q", name)
+	}
+	return p, nil
+}
+
+// Restore verifies the backup at archive and extracts it into dir. Files
+// already in dir are left alone unless overwrite is set, in which case
+// they are replaced; restoring over existing files is refused otherwise.
+func Restore(archive, dir string, overwrite bool) (*Manifest, error) {
+	m, err := VerifyFile(archive)
+	if err != nil {
+		return nil, err
+	}
+	if !overwrite {
+		var conflicts []string
+		for _, f := range m.Files {
+			if f.Mode.IsDir() {
+				continue
+			}
+			if _, err := os.Lstat(filepath.Join(dir, filepath.FromSlash(f.Path))); err == nil {
+				conflicts = append(conflicts, f.Path)
+			}
+		}
+		if len(conflicts) > 0 {
+			more := ""
+			if len(conflicts) > 3 {
+				more = fmt.Sprintf(" and %d more", len(conflicts)-3)
+				conflicts = conflicts[:3]
+			}
+			return nil, fmt.Errorf("%s already has %s%s", dir, strings.Join(conflicts, ", "), more)
+		}
+	}
+
+	f, err := os.Open(archive) //nolint:gosec // G304: path is chosen by the user
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Name == ManifestName {
+			continue
+		}
+		p, err := entryPath(hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := extract(tr, hdr, dir, p); err != nil {
+			return nil, fmt.Errorf("restoring %s: %w", p, err)
+		}
+	}
+	return m, nil
+}
+
+// extract writes the archive entry for the town-relative path p below
+// dir. It never writes through a symlink: one already on disk, or
+// restored earlier from the archive, could point outside dir.
+func extract(r io.Reader, hdr *tar.Header, dir, p string) error {
+	if err := noSymlinkParents(dir, p); err != nil {
+		return err
+	}
+	dest := filepath.Join(dir, filepath.FromSlash(p))
+	fi, err := os.Lstat(dest)
+	exists := err == nil
+	if exists && fi.Mode()&fs.ModeSymlink != 0 && hdr.Typeflag == tar.TypeDir {
+		return fmt.Errorf("%s is a symlink", dest)
+	}
+
+	perm := fs.FileMode(hdr.Mode).Perm()
+	if hdr.Typeflag == tar.TypeDir {
+		return os.MkdirAll(dest, perm|0700)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	if exists && !fi.IsDir() {
+		if err := os.Remove(dest); err != nil {
+			return err
+		}
+	}
+	switch hdr.Typeflag {
+	case tar.TypeSymlink:
+		return os.Symlink(hdr.Linkname, dest)
+	case tar.TypeReg:
+		f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm) //nolint:gosec // G304: dest is checked by entryPath
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, r); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	return nil
+}
+
+// noSymlinkParents returns an error if any directory between dir and the
+// town-relative path p is a symlink. Directories that do not exist yet are
+// fine; extract creates them.
+func noSymlinkParents(dir, p string) error {
+	cur := dir
+	for _, part := range strings.Split(path.Dir(p), "/") {
+		if part == "." {
+			break
+		}
+		cur = filepath.Join(cur, part)
+		fi, err := os.Lstat(cur)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if fi.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("%s is a symlink", cur)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// makeTown builds a small town: town and rig beads, configs, a rig with a
+// bare repo, the Mayor's clone holding the rig's canonical beads, and a
+// polecat worktree.
+func makeTown(t *testing.T) string {
+	t.Helper()
+	town := t.TempDir()
+	files := map[string]string{
+		".beads/beads.db":                      "town db",
+		".beads/issues.jsonl":                  `{"id":"hq-1"}`,
+		"mayor/town.json":                      `{"name":"test"}`,
+		"mayor/rigs.json":                      `{"rigs":{}}`,
+		"daemon/daemon.pid":                    "123",
+		"daemon/schedules.json":                "{}",
+		"gastown/config.json":                  `{"name":"gastown"}`,
+		"gastown/.repo.git/HEAD":               "ref: refs/heads/main",
+		"gastown/.runtime/namepool-state.json": `{"in_use":["Toast"]}`,
+		"gastown/mayor/rig/.git/HEAD":          "ref: refs/heads/main",
+		"gastown/mayor/rig/main.go":            "package main",
+		"gastown/mayor/rig/.beads/beads.db":    "rig db",
+		"gastown/polecats/Toast/rig/.git":      "gitdir: ../../../.repo.git/worktrees/Toast",
+		"gastown/polecats/Toast/rig/work.go":   "package work",
+		"gastown/crew/max/.git/HEAD":           "ref: refs/heads/main",
+		"gastown/crew/max/state.json":          `{"name":"max"}`,
+		"gastown/crew/max/notes.txt":           "scratch",
+	}
+	for name, content := range files {
+		p := filepath.Join(town, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if runtime.GOOS != "windows" {
+		if err := os.Symlink("mayor/rig/.beads", filepath.Join(town, "gastown", ".beads")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return town
+}
+
+func TestCollect(t *testing.T) {
+	town := makeTown(t)
+	files, err := Collect(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, f := range files {
+		if !f.Mode.IsDir() {
+			paths = append(paths, f.Path)
+		}
+	}
+	sort.Strings(paths)
+	got := strings.Join(paths, "\n")
+
+	for _, want := range []string{".beads/beads.db", "mayor/town.json", "daemon/schedules.json",
+		"gastown/.runtime/namepool-state.json", "gastown/mayor/rig/.beads/beads.db", "gastown/crew/max/state.json"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %s in:\n%s", want, got)
+		}
+	}
+	for _, skip := range []string{"daemon.pid", ".repo.git", "main.go", "work.go", "notes.txt", ".git"} {
+		if strings.Contains(got, skip) {
+			t.Errorf("%s should be skipped:\n%s", skip, got)
+		}
+	}
+}
+
+func TestCreateVerifyRestore(t *testing.T) {
+	town := makeTown(t)
+	archive := filepath.Join(town, "backup.tar.gz")
+	m, err := CreateFile(town, archive, Options{Town: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Town != "test" || m.Version != FormatVersion || m.Size() == 0 {
+		t.Errorf("manifest = %+v", m)
+	}
+	for _, f := range m.Files {
+		if strings.HasPrefix(f.Path, "backup.tar.gz") {
+			t.Errorf("backup includes itself: %s", f.Path)
+		}
+	}
+
+	dest := t.TempDir()
+	if _, err := Restore(archive, dest, false); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "gastown", "mayor", "rig", ".beads", "beads.db"))
+	if err != nil || string(data) != "rig db" {
+		t.Errorf("rig beads = %q, %v", data, err)
+	}
+	if runtime.GOOS != "windows" {
+		if link, err := os.Readlink(filepath.Join(dest, "gastown", ".beads")); err != nil || link != "mayor/rig/.beads" {
+			t.Errorf("beads symlink = %q, %v", link, err)
+		}
+	}
+
+	// Restoring again would overwrite files
+	if _, err := Restore(archive, dest, false); err == nil || !strings.Contains(err.Error(), "already has") {
+		t.Errorf("restore over existing files: err = %v", err)
+	}
+	if _, err := Restore(archive, dest, true); err != nil {
+		t.Errorf("restore with overwrite: %v", err)
+	}
+}
+
+func TestVerifyDetectsCorruption(t *testing.T) {
+	town := makeTown(t)
+	var buf bytes.Buffer
+	if _, err := Create(town, &buf, Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rewrite the archive with one file's content changed
+	tampered := rewrite(t, buf.Bytes(), func(hdr *tar.Header, data []byte) []byte {
+		if strings.HasSuffix(hdr.Name, "town.json") {
+			return []byte(`{"name":"evil"}`)
+		}
+		return data
+	})
+	if _, err := Verify(bytes.NewReader(tampered)); err == nil || !strings.Contains(err.Error(), "mayor/town.json") {
+		t.Errorf("tampered file: err = %v", err)
+	}
+
+	// Drop the manifest, as in a truncated archive
+	truncated := rewrite(t, buf.Bytes(), func(hdr *tar.Header, data []byte) []byte {
+		if hdr.Name == ManifestName {
+			return nil
+		}
+		return data
+	})
+	if _, err := Verify(bytes.NewReader(truncated)); err == nil || !strings.Contains(err.Error(), "no manifest") {
+		t.Errorf("truncated: err = %v", err)
+	}
+}
+
+func TestRestoreRefusesSymlinkEscape(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on Windows")
+	}
+	outside := t.TempDir()
+	data := []byte("evil")
+	sum := sha256.Sum256(data)
+
+	// A symlink out of the town, then a directory and a file under the
+	// same name; the manifest lists only the directory and the file.
+	m := Manifest{Version: FormatVersion, Files: []File{
+		{Path: "d", Mode: fs.ModeDir | 0755},
+		{Path: "d/f", Mode: 0644, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])},
+	}}
+	manifest, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, e := range []struct {
+		hdr  tar.Header
+		data []byte
+	}{
+		{tar.Header{Name: "town/d", Typeflag: tar.TypeSymlink, Linkname: outside, Mode: 0777}, nil},
+		{tar.Header{Name: "town/d/", Typeflag: tar.TypeDir, Mode: 0755}, nil},
+		{tar.Header{Name: "town/d/f", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(data))}, data},
+		{tar.Header{Name: ManifestName, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(manifest))}, manifest},
+	} {
+		if err := tw.WriteHeader(&e.hdr); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write(e.data)
+	}
+	_ = tw.Close()
+	_ = gw.Close()
+	archive := filepath.Join(t.TempDir(), "evil.tar.gz")
+	if err := os.WriteFile(archive, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Restore(archive, t.TempDir(), true); err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Errorf("restore: err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "f")); !os.IsNotExist(err) {
+		t.Errorf("file written outside the restore directory: %v", err)
+	}
+}
+
+func TestRestoreDoesNotFollowExistingSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on Windows")
+	}
+	town := makeTown(t)
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	if _, err := CreateFile(town, archive, Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	outside := t.TempDir()
+	dest := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(dest, "mayor")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Restore(archive, dest, true); err == nil || !strings.Contains(err.Error(), "symlink") {
+		t.Errorf("restore through symlink: err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "town.json")); !os.IsNotExist(err) {
+		t.Errorf("file written through symlink: %v", err)
+	}
+}
+
+func TestEntryPath(t *testing.T) {
+	for _, name := range []string{"town/../etc/passwd", "town//etc", "other/file", "town/", "town/a/../../b"} {
+		if _, err := entryPath(name); err == nil {
+			t.Errorf("entryPath(%q) accepted", name)
+		}
+	}
+	if p, err := entryPath("town/mayor/town.json"); err != nil || p != "mayor/town.json" {
+		t.Errorf("entryPath = %q, %v", p, err)
+	}
+}
+
+// rewrite copies a backup archive, passing each entry's content through
+// edit; entries edit returns nil for are dropped.
+func rewrite(t *testing.T, archive []byte, edit func(*tar.Header, []byte) []byte) []byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gw)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		var data bytes.Buffer
+		_, _ = data.ReadFrom(tr)
+		content := edit(hdr, data.Bytes())
+		if content == nil && hdr.Typeflag == tar.TypeReg {
+			continue
+		}
+		hdr.Size = int64(len(content))
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write(content)
+	}
+	_ = tw.Close()
+	_ = gw.Close()
+	return out.Bytes()
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 // Common errors
@@ -374,9 +375,42 @@ func (b *Beads) ReadyWithType(issueType string) ([]*Issue, error) {
 	return issues, nil
 }
 
+// routed returns the wrapper to run a command about id with. Bead IDs carry
+// their rig's prefix; when the town's routes send that prefix to a different
+// database than b's, the command runs against that database instead, so a
+// rig can show or update town and sibling-rig beads by ID. Isolated (test)
+// wrappers never route.
+func (b *Beads) routed(id string) *Beads {
+	prefix := ExtractPrefix(id)
+	if b.isolated || prefix == "" {
+		return b
+	}
+	beadsDir := b.beadsDir
+	if beadsDir == "" {
+		beadsDir = ResolveBeadsDir(b.workDir)
+	}
+	beadsDir, err := filepath.Abs(beadsDir)
+	if err != nil {
+		return b
+	}
+	townRoot, err := workspace.Find(beadsDir)
+	if err != nil || townRoot == "" {
+		return b
+	}
+	rigPath := GetRigPathForPrefix(townRoot, prefix)
+	if rigPath == "" {
+		return b
+	}
+	target := ResolveBeadsDir(rigPath)
+	if filepath.Clean(target) == beadsDir {
+		return b
+	}
+	return &Beads{workDir: rigPath, beadsDir: target}
+}
+
 // Show returns detailed information about an issue.
 func (b *Beads) Show(id string) (*Issue, error) {
-	out, err := b.run("show", id, "--json")
+	out, err := b.routed(id).run("show", id, "--json")
 	if err != nil {
 		return nil, err
 	}
@@ -566,7 +600,7 @@ func (b *Beads) Update(id string, opts UpdateOptions) error {
 		}
 	}
 
-	_, err := b.run(args...)
+	_, err := b.routed(id).run(args...)
 	return err
 }
 
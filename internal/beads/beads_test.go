@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -568,6 +569,7 @@ func TestMRFieldsRoundTrip(t *testing.T) {
 		Rig:         "gastown",
 		MergeCommit: "abc123def789",
 		CloseReason: "merged",
+		Commits:     []string{"1111aaaa", "2222bbbb"},
 	}
 
 	// Format to string
@@ -581,7 +583,7 @@ func TestMRFieldsRoundTrip(t *testing.T) {
 		t.Fatal("round-trip parse returned nil")
 	}
 
-	if *parsed != *original {
+	if !reflect.DeepEqual(parsed, original) {
 		t.Errorf("round-trip mismatch:\ngot  %+v\nwant %+v", parsed, original)
 	}
 }
@@ -2380,3 +2382,39 @@ func TestCloseAndClearAgentBead_ReasonVariations(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFieldHeader(t *testing.T) {
+	description := "Review_Of: gt-mr1\nbranch:  polecat/Nux/gt-xyz \nnote:\nno colon here\n\nbranch: from-the-body\nrig: gastown"
+	got := ParseFieldHeader(description)
+	want := map[string]string{"review_of": "gt-mr1", "branch": "polecat/Nux/gt-xyz"}
+	if len(got) != len(want) {
+		t.Fatalf("ParseFieldHeader = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("field %q = %q, want %q", k, got[k], v)
+		}
+	}
+
+	if got := ParseFieldHeader("just prose\n\nrig: gastown"); got != nil {
+		t.Errorf("ParseFieldHeader(prose header) = %v, want nil", got)
+	}
+}
+
+func TestFormatAndSetFieldHeader(t *testing.T) {
+	header := FormatFieldHeader(
+		Field{Key: "review_of", Value: "gt-mr1"},
+		Field{Key: "worker", Value: ""},
+		Field{Key: "review_note", Value: "fix the\n  empty case"},
+	)
+	if want := "review_of: gt-mr1\nreview_note: fix the empty case"; header != want {
+		t.Errorf("FormatFieldHeader = %q, want %q", header, want)
+	}
+
+	if got := SetFieldHeader("review_of: old\n\nTask text\n\nMore", header); got != header+"\n\nTask text\n\nMore" {
+		t.Errorf("SetFieldHeader lost the body: %q", got)
+	}
+	if got := SetFieldHeader("review_of: old", header); got != header {
+		t.Errorf("SetFieldHeader without body = %q, want %q", got, header)
+	}
+}
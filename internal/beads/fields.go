@@ -187,6 +187,16 @@ type MRFields struct {
 	// Convoy tracking (for priority scoring - convoy starvation prevention)
 	ConvoyID        string // Parent convoy ID if part of a convoy
 	ConvoyCreatedAt string // Convoy creation time (ISO 8601) for starvation prevention
+
+	// Commits, when set, makes this a partial MR (gt done --partial): only
+	// these commits are cherry-picked onto Target, oldest first, and the
+	// worker keeps working on Branch.
+	Commits []string
+}
+
+// IsPartial reports whether the MR lands only some of its branch's commits.
+func (f *MRFields) IsPartial() bool {
+	return len(f.Commits) > 0
 }
 
 // ParseMRFields extracts structured merge-request fields from an issue's description.
@@ -261,6 +271,9 @@ func ParseMRFields(issue *Issue) *MRFields {
 		case "convoy_created_at", "convoy-created-at", "convoycreatedat":
 			fields.ConvoyCreatedAt = value
 			hasFields = true
+		case "commits":
+			fields.Commits = strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+			hasFields = true
 		}
 	}
 
@@ -325,6 +338,9 @@ func FormatMRFields(fields *MRFields) string {
 	if fields.ConvoyCreatedAt != "" {
 		lines = append(lines, "convoy_created_at: "+fields.ConvoyCreatedAt)
 	}
+	if len(fields.Commits) > 0 {
+		lines = append(lines, "commits: "+strings.Join(fields.Commits, ","))
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -371,6 +387,7 @@ func SetMRFields(issue *Issue, fields *MRFields) string {
 		"convoy_created_at":  true,
 		"convoy-created-at":  true,
 		"convoycreatedat":    true,
+		"commits":            true,
 	}
 
 	// Collect non-MR lines from existing description
@@ -421,6 +438,62 @@ func SetMRFields(issue *Issue, fields *MRFields) string {
 	return formatted + "\n\n" + strings.Join(otherLines, "\n")
 }
 
+// Field is one "key: value" line of a field header.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// ParseFieldHeader extracts the "key: value" lines that head a description,
+// keyed by lower-cased key. The header ends at the first blank line: what
+// follows is the body (a task, a checklist, plan steps) and is never parsed,
+// so a "key:" line there can't override a field. Lines without a colon and
+// empty values are skipped. Returns nil if the header has no fields.
+func ParseFieldHeader(description string) map[string]string {
+	header, _, _ := strings.Cut(description, "\n\n")
+
+	var fields map[string]string
+	for _, line := range strings.Split(header, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if key == "" || value == "" {
+			continue
+		}
+		if fields == nil {
+			fields = make(map[string]string)
+		}
+		fields[key] = value
+	}
+	return fields
+}
+
+// FormatFieldHeader formats fields as "key: value" lines, in order.
+// Fields with empty values are omitted. Whitespace within a value is
+// collapsed so free text stays on one line and parses back.
+func FormatFieldHeader(fields ...Field) string {
+	var lines []string
+	for _, f := range fields {
+		if value := strings.Join(strings.Fields(f.Value), " "); value != "" {
+			lines = append(lines, f.Key+": "+value)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetFieldHeader replaces the field header of description with header,
+// keeping the body that follows it. Returns the new description string.
+func SetFieldHeader(description, header string) string {
+	_, body, found := strings.Cut(description, "\n\n")
+	if !found {
+		return header
+	}
+	return header + "\n\n" + body
+}
+
 // SynthesisFields holds structured fields for synthesis beads.
 // These fields track the synthesis step in a convoy workflow.
 type SynthesisFields struct {
@@ -154,6 +154,54 @@ func TestGetRigPathForPrefix_NoRoutesFile(t *testing.T) {
 	}
 }
 
+func TestRoutedForID(t *testing.T) {
+	townRoot := t.TempDir()
+	for _, dir := range []string{"mayor", ".beads", "gastown/mayor/rig/.beads", "beads/mayor/rig/.beads"} {
+		if err := os.MkdirAll(filepath.Join(townRoot, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "town.json"), []byte(`{"name":"test"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	routesContent := `{"prefix": "gt-", "path": "gastown/mayor/rig"}
+{"prefix": "bd-", "path": "beads/mayor/rig"}
+{"prefix": "hq-", "path": "."}
+`
+	if err := os.WriteFile(filepath.Join(townRoot, ".beads", "routes.jsonl"), []byte(routesContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := New(filepath.Join(townRoot, "gastown", "mayor", "rig"))
+	tests := []struct {
+		id       string
+		beadsDir string // "" means b itself
+	}{
+		{"gt-abc", ""},
+		{"bd-xyz.1", filepath.Join(townRoot, "beads", "mayor", "rig", ".beads")},
+		{"hq-cv-abc", filepath.Join(townRoot, ".beads")},
+		{"zz-unrouted", ""},
+		{"noprefix", ""},
+	}
+	for _, tc := range tests {
+		got := b.routed(tc.id)
+		if tc.beadsDir == "" {
+			if got != b {
+				t.Errorf("routed(%q) = %+v, want b itself", tc.id, got)
+			}
+			continue
+		}
+		if got.beadsDir != tc.beadsDir {
+			t.Errorf("routed(%q).beadsDir = %q, want %q", tc.id, got.beadsDir, tc.beadsDir)
+		}
+	}
+
+	isolated := NewIsolated(filepath.Join(townRoot, "gastown", "mayor", "rig"))
+	if got := isolated.routed("bd-xyz"); got != isolated {
+		t.Errorf("isolated wrapper routed bd-xyz to %q", got.beadsDir)
+	}
+}
+
 func TestResolveHookDir(t *testing.T) {
 	// Create a temporary directory with routes.jsonl
 	tmpDir := t.TempDir()
@@ -0,0 +1,232 @@
+// Package branchgc prunes a rig's dead branches: branches whose merge
+// requests merged a while ago, and polecat/* branches left behind by
+// polecats that no longer exist and have no MR.
+//
+// A branch whose MR merged is deleted only if its tip is in the MR's
+// target: with gt done --partial the MR merges while work continues on the
+// branch, and those later commits must not be lost.
+//
+// Branches are never deleted on first sight. A scheduled pass (see Pass)
+// mails the rig's Refinery a report of what it found, and deletes a branch
+// only on a later pass, once the branch has been reported for the grace
+// period and still qualifies. Branches checked out in a worktree, named by
+// an open MR, or matching a keep pattern are never candidates.
+//
+// The schedule is configured under "branch_gc" in mayor/daemon.json.
+package branchgc
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/logrotate"
+)
+
+// Defaults used when mayor/daemon.json doesn't override them.
+const (
+	DefaultInterval    = "24h"
+	DefaultMergedAfter = "7d"
+	DefaultGrace       = "24h"
+)
+
+// Config is the "branch_gc" section of mayor/daemon.json.
+type Config struct {
+	// Enabled runs branch GC from the daemon.
+	Enabled bool `json:"enabled"`
+
+	// Interval is how often each rig is collected, e.g. "24h"
+	// (default DefaultInterval).
+	Interval string `json:"interval,omitempty"`
+
+	// MergedAfter is how long after its MR merged (or closed unmerged, for
+	// polecat branches) a branch is kept, e.g. "7d" (default
+	// DefaultMergedAfter).
+	MergedAfter string `json:"merged_after,omitempty"`
+
+	// Grace is how long a branch must have been reported to the Refinery
+	// before it is deleted, e.g. "24h" (default DefaultGrace).
+	Grace string `json:"grace,omitempty"`
+
+	// Keep are branch name patterns (path.Match syntax, e.g. "release/*")
+	// that are never deleted.
+	Keep []string `json:"keep,omitempty"`
+}
+
+// Policy is a Config with its durations parsed and defaults applied.
+type Policy struct {
+	Interval    time.Duration
+	MergedAfter time.Duration
+	Grace       time.Duration
+	Keep        []string
+}
+
+// Policy parses c. A nil Config yields the defaults.
+func (c *Config) Policy() (Policy, error) {
+	if c == nil {
+		c = &Config{}
+	}
+	var p Policy
+	var err error
+	if p.Interval, err = logrotate.ParseDuration(orDefault(c.Interval, DefaultInterval)); err != nil {
+		return p, fmt.Errorf("branch_gc.interval: %w", err)
+	}
+	if p.MergedAfter, err = logrotate.ParseDuration(orDefault(c.MergedAfter, DefaultMergedAfter)); err != nil {
+		return p, fmt.Errorf("branch_gc.merged_after: %w", err)
+	}
+	if p.Grace, err = logrotate.ParseDuration(orDefault(c.Grace, DefaultGrace)); err != nil {
+		return p, fmt.Errorf("branch_gc.grace: %w", err)
+	}
+	p.Keep = c.Keep
+	return p, nil
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// MR is what branch GC needs to know about a merge request.
+type MR struct {
+	ID       string
+	Branch   string
+	Target   string
+	Open     bool
+	Merged   bool      // closed with close_reason "merged"
+	ClosedAt time.Time // zero while open
+}
+
+// Inventory is the state of a rig's branches and merge requests.
+type Inventory struct {
+	Local      []string        // branches in the rig's repository
+	Remote     []string        // branches on origin
+	CheckedOut map[string]bool // branches checked out in some worktree
+	Protected  map[string]bool // the default branch and other MR targets
+	MRs        []MR
+
+	// Landed holds the branches, by candidate key ("origin/<branch>" for
+	// branches on origin), whose tips are in the target of their latest
+	// merged MR.
+	Landed map[string]bool
+}
+
+// Candidate is a branch branch GC would delete.
+type Candidate struct {
+	Branch string `json:"branch"`
+	Remote bool   `json:"remote"` // on origin rather than in the rig's repository
+	Reason string `json:"reason"`
+	MR     string `json:"mr,omitempty"`
+}
+
+// String describes the candidate for reports.
+func (c Candidate) String() string {
+	where := "local"
+	if c.Remote {
+		where = "origin"
+	}
+	return fmt.Sprintf("%s (%s): %s", c.Branch, where, c.Reason)
+}
+
+// key identifies a candidate across passes.
+func (c Candidate) key() string {
+	if c.Remote {
+		return "origin/" + c.Branch
+	}
+	return c.Branch
+}
+
+// latestMRs returns each branch's latest MR, which decides its fate; an
+// open MR always wins.
+func latestMRs(all []MR) map[string]MR {
+	mrs := make(map[string]MR)
+	for _, mr := range all {
+		if mr.Branch == "" {
+			continue
+		}
+		prev, seen := mrs[mr.Branch]
+		if !seen || (!prev.Open && (mr.Open || mr.ClosedAt.After(prev.ClosedAt))) {
+			mrs[mr.Branch] = mr
+		}
+	}
+	return mrs
+}
+
+// Plan returns the branches in inv that policy p would delete at now,
+// sorted by branch name, local before remote. Branches whose MR merged but
+// whose tips are not in its target are returned separately as unmerged:
+// they are reported, never deleted.
+func Plan(inv Inventory, p Policy, now time.Time) (plan, unmerged []Candidate) {
+	mrs := latestMRs(inv.MRs)
+
+	consider := func(branch string, remote bool) {
+		if inv.CheckedOut[branch] || inv.Protected[branch] || kept(branch, p.Keep) {
+			return
+		}
+		c := Candidate{Branch: branch, Remote: remote}
+		mr, hasMR := mrs[branch]
+		switch {
+		case hasMR && mr.Open:
+			return
+		case hasMR && now.Sub(mr.ClosedAt) < p.MergedAfter:
+			return
+		case hasMR && mr.Merged && !inv.Landed[c.key()]:
+			c.Reason = "unmerged commits after its MR merged"
+			c.MR = mr.ID
+			unmerged = append(unmerged, c)
+			return
+		case hasMR && mr.Merged:
+			c.Reason = fmt.Sprintf("merged %s ago", age(now.Sub(mr.ClosedAt)))
+			c.MR = mr.ID
+		case !strings.HasPrefix(branch, "polecat/"):
+			// Only polecat branches are ours to call orphaned.
+			return
+		case hasMR:
+			c.Reason = fmt.Sprintf("orphaned: MR closed unmerged %s ago", age(now.Sub(mr.ClosedAt)))
+			c.MR = mr.ID
+		default:
+			c.Reason = "orphaned: no polecat or MR"
+		}
+		plan = append(plan, c)
+	}
+	for _, b := range inv.Local {
+		consider(b, false)
+	}
+	for _, b := range inv.Remote {
+		consider(b, true)
+	}
+
+	sortCandidates(plan)
+	sortCandidates(unmerged)
+	return plan, unmerged
+}
+
+func sortCandidates(cs []Candidate) {
+	sort.SliceStable(cs, func(i, j int) bool {
+		if cs[i].Branch != cs[j].Branch {
+			return cs[i].Branch < cs[j].Branch
+		}
+		return !cs[i].Remote && cs[j].Remote
+	})
+}
+
+// kept reports whether branch matches a keep pattern.
+func kept(branch string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// age formats d in days, or hours under a day.
+func age(d time.Duration) string {
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+	return fmt.Sprintf("%dh", int(d/time.Hour))
+}
@@ -0,0 +1,125 @@
+package branchgc
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+func TestPlan(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	days := func(n int) time.Time { return now.Add(-time.Duration(n) * 24 * time.Hour) }
+
+	inv := Inventory{
+		Local: []string{
+			"main",
+			"polecat/Toast/gt-old",    // merged long ago
+			"polecat/Toast/gt-part",   // partial MR merged, work continued
+			"polecat/Toast/gt-recent", // merged yesterday
+			"polecat/Nux/gt-open",     // MR open
+			"polecat/Slit",            // checked out
+			"polecat/Ghost",           // no polecat, no MR
+			"polecat/Nux/gt-rejected", // closed unmerged long ago
+			"feature/human",           // not ours, no MR
+			"feature/merged",          // not ours, merged long ago
+			"release/1.0",             // kept by pattern
+		},
+		Remote:     []string{"main", "polecat/Toast/gt-old", "polecat/Slit"},
+		CheckedOut: map[string]bool{"polecat/Slit": true},
+		Protected:  map[string]bool{"main": true},
+		MRs: []MR{
+			{ID: "gt-mr1", Branch: "polecat/Toast/gt-old", Merged: true, ClosedAt: days(10)},
+			{ID: "gt-mr7", Branch: "polecat/Toast/gt-part", Merged: true, ClosedAt: days(10)},
+			{ID: "gt-mr2", Branch: "polecat/Toast/gt-recent", Merged: true, ClosedAt: days(1)},
+			{ID: "gt-mr3", Branch: "polecat/Nux/gt-open", Open: true},
+			{ID: "gt-mr0", Branch: "polecat/Nux/gt-open", Merged: true, ClosedAt: days(30)},
+			{ID: "gt-mr4", Branch: "polecat/Nux/gt-rejected", ClosedAt: days(8)},
+			{ID: "gt-mr5", Branch: "feature/merged", Merged: true, ClosedAt: days(9)},
+			{ID: "gt-mr6", Branch: "release/1.0", Merged: true, ClosedAt: days(9)},
+		},
+		Landed: map[string]bool{
+			"polecat/Toast/gt-old":        true,
+			"origin/polecat/Toast/gt-old": true,
+			"feature/merged":              true,
+			"release/1.0":                 true,
+		},
+	}
+	p := Policy{MergedAfter: 7 * 24 * time.Hour, Keep: []string{"release/*"}}
+
+	got, unmerged := Plan(inv, p, now)
+	want := []Candidate{
+		{Branch: "feature/merged", Reason: "merged 9d ago", MR: "gt-mr5"},
+		{Branch: "polecat/Ghost", Reason: "orphaned: no polecat or MR"},
+		{Branch: "polecat/Nux/gt-rejected", Reason: "orphaned: MR closed unmerged 8d ago", MR: "gt-mr4"},
+		{Branch: "polecat/Toast/gt-old", Reason: "merged 10d ago", MR: "gt-mr1"},
+		{Branch: "polecat/Toast/gt-old", Remote: true, Reason: "merged 10d ago", MR: "gt-mr1"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Plan returned %d candidates, want %d:\n%v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("candidate %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	wantUnmerged := Candidate{Branch: "polecat/Toast/gt-part", Reason: "unmerged commits after its MR merged", MR: "gt-mr7"}
+	if len(unmerged) != 1 || unmerged[0] != wantUnmerged {
+		t.Errorf("unmerged = %+v, want [%+v]", unmerged, wantUnmerged)
+	}
+}
+
+func TestConfigPolicyDefaults(t *testing.T) {
+	var c *Config
+	p, err := c.Policy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Interval != 24*time.Hour || p.MergedAfter != 7*24*time.Hour || p.Grace != 24*time.Hour {
+		t.Errorf("default policy = %+v", p)
+	}
+
+	if _, err := (&Config{MergedAfter: "soon"}).Policy(); err == nil {
+		t.Error("invalid merged_after accepted")
+	}
+}
+
+func TestLanded(t *testing.T) {
+	dir := t.TempDir()
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	run("init", "-b", "main")
+	run("config", "user.email", "test@test.com")
+	run("config", "user.name", "Test User")
+	run("commit", "--allow-empty", "-m", "base")
+	run("checkout", "-b", "polecat/Toast/gt-part")
+	run("commit", "--allow-empty", "-m", "landed")
+	merged := run("rev-parse", "HEAD")
+	run("checkout", "main")
+	run("merge", "--no-ff", "-m", "merge", "polecat/Toast/gt-part")
+	run("checkout", "polecat/Toast/gt-part")
+	run("commit", "--allow-empty", "-m", "after the MR merged")
+	tip := run("rev-parse", "HEAD")
+
+	g := git.NewGit(dir)
+	if !landed(g, merged, "main") {
+		t.Error("merged commit not reported as landed")
+	}
+	if landed(g, tip, "main") {
+		t.Error("commit made after the merge reported as landed")
+	}
+	if landed(g, "0123456789abcdef0123456789abcdef01234567", "main") {
+		t.Error("unknown commit reported as landed")
+	}
+}
@@ -0,0 +1,255 @@
+package branchgc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/runstate"
+	"github.com/steveyegge/gastown/internal/stack"
+)
+
+// Sender is the From address of branch GC reports.
+const Sender = "daemon"
+
+// repoBase returns the rig's shared repository: the bare repo polecat
+// worktrees come from, or mayor/rig in rigs without one.
+func repoBase(rigPath string) (*git.Git, error) {
+	bare := filepath.Join(rigPath, ".repo.git")
+	if info, err := os.Stat(bare); err == nil && info.IsDir() {
+		return rig.WithGitAuth(rigPath, git.NewGitWithDir(bare, "")), nil
+	}
+	mayor := filepath.Join(rigPath, "mayor", "rig")
+	if _, err := os.Stat(mayor); err != nil {
+		return nil, fmt.Errorf("no repo base found (neither .repo.git nor mayor/rig exists)")
+	}
+	return rig.WithGitAuth(rigPath, git.NewGit(mayor)), nil
+}
+
+// Collect inventories a rig's branches, worktrees, and merge requests.
+func Collect(rigPath string) (Inventory, error) {
+	inv := Inventory{CheckedOut: make(map[string]bool), Protected: map[string]bool{"HEAD": true}}
+	g, err := repoBase(rigPath)
+	if err != nil {
+		return inv, err
+	}
+
+	if inv.Local, err = g.ListBranches(""); err != nil {
+		return inv, fmt.Errorf("listing branches: %w", err)
+	}
+	remoteHeads, err := g.RemoteBranchHeads("origin")
+	if err != nil {
+		return inv, fmt.Errorf("listing origin's branches: %w", err)
+	}
+	for b := range remoteHeads {
+		inv.Remote = append(inv.Remote, b)
+	}
+	sort.Strings(inv.Remote)
+	worktrees, err := g.WorktreeList()
+	if err != nil {
+		return inv, fmt.Errorf("listing worktrees: %w", err)
+	}
+	for _, wt := range worktrees {
+		if wt.Branch != "" {
+			inv.CheckedOut[wt.Branch] = true
+		}
+	}
+
+	defaultBranch := "main"
+	if cfg, err := rig.LoadRigConfig(rigPath); err == nil && cfg.DefaultBranch != "" {
+		defaultBranch = cfg.DefaultBranch
+	}
+	inv.Protected[defaultBranch] = true
+
+	// Stacked branches stay until they land, even between polecats.
+	stacks, err := stack.Open(rigPath).List()
+	if err != nil {
+		return inv, fmt.Errorf("listing branch stacks: %w", err)
+	}
+	for _, s := range stacks {
+		for _, e := range s.Entries {
+			if !e.Landed {
+				inv.Protected[e.Branch] = true
+			}
+		}
+	}
+
+	issues, err := beads.New(rigPath).List(beads.ListOptions{Status: "all", Label: "gt:merge-request", Priority: -1})
+	if err != nil {
+		return inv, fmt.Errorf("listing merge requests: %w", err)
+	}
+	for _, issue := range issues {
+		fields := beads.ParseMRFields(issue)
+		if fields == nil {
+			continue
+		}
+		mr := MR{ID: issue.ID, Branch: fields.Branch, Target: fields.Target, Open: issue.Status != "closed"}
+		if mr.Target == "" {
+			mr.Target = defaultBranch
+		}
+		if mr.Open {
+			// Integration branches and other targets stay while MRs aim at them.
+			if fields.Target != "" {
+				inv.Protected[fields.Target] = true
+			}
+		} else {
+			mr.Merged = fields.CloseReason == "merged"
+			mr.ClosedAt, _ = time.Parse(time.RFC3339, issue.ClosedAt)
+		}
+		inv.MRs = append(inv.MRs, mr)
+	}
+
+	inv.Landed = make(map[string]bool)
+	mrs := latestMRs(inv.MRs)
+	for _, b := range inv.Local {
+		if mr, ok := mrs[b]; ok && mr.Merged {
+			if tip, err := g.Rev("refs/heads/" + b); err == nil && landed(g, tip, mr.Target) {
+				inv.Landed[b] = true
+			}
+		}
+	}
+	for b, tip := range remoteHeads {
+		if mr, ok := mrs[b]; ok && mr.Merged && landed(g, tip, mr.Target) {
+			inv.Landed["origin/"+b] = true
+		}
+	}
+	return inv, nil
+}
+
+// landed reports whether commit tip is in target, on origin or locally.
+// A tip the repository doesn't have is not known to have landed.
+func landed(g *git.Git, tip, target string) bool {
+	for _, ref := range []string{"refs/remotes/origin/" + target, "refs/heads/" + target} {
+		if ok, err := g.IsAncestor(tip, ref); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Delete deletes a candidate branch from the rig's repository or origin.
+func Delete(rigPath string, c Candidate) error {
+	g, err := repoBase(rigPath)
+	if err != nil {
+		return err
+	}
+	if c.Remote {
+		return g.DeleteRemoteBranch("origin", c.Branch)
+	}
+	return g.DeleteBranch(c.Branch, false)
+}
+
+// state is a rig's branch GC state between passes.
+type state struct {
+	LastRun  time.Time            `json:"last_run"`
+	Reported map[string]time.Time `json:"reported,omitempty"` // candidate key -> first reported
+}
+
+func stateDoc(townRoot, rigName string) *runstate.Doc[state] {
+	return runstate.NewDoc(runstate.Open(townRoot), "branch-gc/"+rigName+".json", func() *state { return &state{} })
+}
+
+func loadState(townRoot, rigName string) *state {
+	s, err := stateDoc(townRoot, rigName).Load()
+	if err != nil {
+		s = &state{}
+	}
+	if s.Reported == nil {
+		s.Reported = make(map[string]time.Time)
+	}
+	return s
+}
+
+func (s *state) save(townRoot, rigName string) error {
+	return stateDoc(townRoot, rigName).Save(s)
+}
+
+// Result is the outcome of a scheduled pass over one rig.
+type Result struct {
+	Reported []Candidate // newly found, mailed to the Refinery
+	Deleted  []Candidate
+	Pending  int         // reported earlier, still within the grace period
+	Unmerged []Candidate // MR merged, but the branch has commits that didn't land
+}
+
+// Pass runs a scheduled GC pass over a rig if one is due. Candidates first
+// seen are mailed to the rig's Refinery; candidates reported at least the
+// grace period ago that still qualify are deleted. Returns nil if no pass
+// was due.
+func Pass(townRoot, rigName string, p Policy, now time.Time) (*Result, error) {
+	st := loadState(townRoot, rigName)
+	if now.Sub(st.LastRun) < p.Interval {
+		return nil, nil
+	}
+	rigPath := filepath.Join(townRoot, rigName)
+	inv, err := Collect(rigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, unmerged := Plan(inv, p, now)
+	res := &Result{Unmerged: unmerged}
+	var errs []error
+	reported := make(map[string]time.Time)
+	for _, c := range plan {
+		first, seen := st.Reported[c.key()]
+		switch {
+		case !seen:
+			reported[c.key()] = now
+			res.Reported = append(res.Reported, c)
+		case now.Sub(first) >= p.Grace:
+			if err := Delete(rigPath, c); err != nil {
+				reported[c.key()] = first
+				errs = append(errs, fmt.Errorf("deleting %s: %w", c.key(), err))
+				continue
+			}
+			res.Deleted = append(res.Deleted, c)
+		default:
+			reported[c.key()] = first
+			res.Pending++
+		}
+	}
+
+	if len(res.Reported) > 0 {
+		if err := mailReport(townRoot, rigName, res.Reported, now.Add(p.Grace)); err != nil {
+			// Unreported candidates must not be deleted: forget them so the
+			// next pass reports them again.
+			for _, c := range res.Reported {
+				delete(reported, c.key())
+			}
+			res.Reported = nil
+			errs = append(errs, fmt.Errorf("mailing report: %w", err))
+		}
+	}
+
+	st.LastRun = now
+	st.Reported = reported
+	if err := st.save(townRoot, rigName); err != nil {
+		errs = append(errs, err)
+	}
+	return res, errors.Join(errs...)
+}
+
+// mailReport tells the rig's Refinery which branches will be deleted.
+func mailReport(townRoot, rigName string, plan []Candidate, after time.Time) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Branch GC found %d branch(es) to delete in %s after %s:\n\n",
+		len(plan), rigName, after.Format("2006-01-02 15:04 MST"))
+	for _, c := range plan {
+		fmt.Fprintf(&b, "- %s\n", c)
+	}
+	b.WriteString("\nTo keep a branch, add a matching pattern to branch_gc.keep in mayor/daemon.json.\n")
+	b.WriteString("Preview the current plan any time with: gt rig gc " + rigName + " --dry-run\n")
+
+	msg := mail.NewMessage(Sender, rigName+"/refinery",
+		fmt.Sprintf("BRANCH_GC: %d branch(es) scheduled for deletion", len(plan)), b.String())
+	return mail.NewRouterWithTownRoot(townRoot, townRoot).Send(msg)
+}
@@ -0,0 +1,209 @@
+package callback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+// DefaultPluginTimeout bounds a plugin run unless its timeout is set.
+const DefaultPluginTimeout = time.Minute
+
+// PluginConfig is the town's plugin handler config, settings/callbacks.json.
+type PluginConfig struct {
+	Handlers []Plugin `json:"handlers"`
+}
+
+// Plugin is a callback handler that runs an external executable.
+//
+// The executable gets the message as JSON on stdin, and GT_TOWN_ROOT,
+// GT_CALLBACK_TYPE, GT_CALLBACK_MATCH (the subject pattern's first
+// group), GT_MESSAGE_ID, GT_MESSAGE_FROM, and GT_MESSAGE_SUBJECT in its
+// environment. It runs in the town root. The last line it prints is the
+// action reported; a non-zero exit fails the callback, which stays in the
+// inbox.
+type Plugin struct {
+	// Type names the callback type; it must not be a built-in type.
+	Type string `json:"type"`
+
+	// Subject is a regular expression matching the callbacks' subjects.
+	Subject string `json:"subject"`
+
+	// From, if set, is a regular expression their sender must match too.
+	From string `json:"from,omitempty"`
+
+	// Command is the executable: an absolute path, a path relative to the
+	// town root, or a name looked up in PATH.
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+
+	// Summary says what the plugin does, for gt callbacks list.
+	Summary string `json:"summary,omitempty"`
+
+	// DryRun describes what the plugin would do; dry runs never run it.
+	DryRun string `json:"dry_run,omitempty"`
+
+	// Timeout bounds a run, e.g. "30s" (default 1m).
+	Timeout string `json:"timeout,omitempty"`
+
+	// Disabled skips the plugin without removing it.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// PluginConfigPath returns the path of the town's plugin handler config.
+func PluginConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, "settings", "callbacks.json")
+}
+
+// LoadPlugins reads the town's plugin handlers. A town without a plugin
+// config has none.
+func LoadPlugins(townRoot string) ([]Handler, error) {
+	path := PluginConfigPath(townRoot)
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading callback plugins: %w", err)
+	}
+	var cfg PluginConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	var handlers []Handler
+	for i, p := range cfg.Handlers {
+		if p.Disabled {
+			continue
+		}
+		h, err := p.handler()
+		if err != nil {
+			return nil, fmt.Errorf("%s: handler %d: %w", path, i+1, err)
+		}
+		h.Source = filepath.Join("settings", "callbacks.json")
+		handlers = append(handlers, h)
+	}
+	return handlers, nil
+}
+
+// RegisterPlugins adds the town's plugin handlers to r.
+func RegisterPlugins(r *Registry, townRoot string) error {
+	handlers, err := LoadPlugins(townRoot)
+	if err != nil {
+		return err
+	}
+	for _, h := range handlers {
+		if err := r.Register(h); err != nil {
+			return fmt.Errorf("%s: %w", PluginConfigPath(townRoot), err)
+		}
+	}
+	return nil
+}
+
+func (p Plugin) handler() (Handler, error) {
+	if p.Type == "" {
+		return Handler{}, errors.New("type is required")
+	}
+	if p.Command == "" {
+		return Handler{}, fmt.Errorf("%s: command is required", p.Type)
+	}
+	subject, err := regexp.Compile(p.Subject)
+	if err != nil || p.Subject == "" {
+		return Handler{}, fmt.Errorf("%s: invalid subject pattern %q", p.Type, p.Subject)
+	}
+	timeout := DefaultPluginTimeout
+	if p.Timeout != "" {
+		d, err := time.ParseDuration(p.Timeout)
+		if err != nil {
+			return Handler{}, fmt.Errorf("%s: timeout: %w", p.Type, err)
+		}
+		if d > 0 {
+			timeout = d
+		}
+	}
+
+	h := Handler{
+		Type:    Type(p.Type),
+		Pattern: subject,
+		Subject: p.Subject,
+		Summary: p.Summary,
+		DryRun:  p.DryRun,
+	}
+	if h.Summary == "" {
+		h.Summary = "Run " + p.Command
+	}
+	if h.DryRun == "" {
+		h.DryRun = "would run " + p.Command
+	}
+	if p.From != "" {
+		from, err := regexp.Compile(p.From)
+		if err != nil {
+			return Handler{}, fmt.Errorf("%s: invalid from pattern: %w", p.Type, err)
+		}
+		h.Match = func(msg *mail.Message) bool {
+			return subject.MatchString(msg.Subject) && from.MatchString(msg.From)
+		}
+	}
+	h.Handle = func(townRoot string, msg *mail.Message, _ bool) (string, error) {
+		return p.run(townRoot, subject, msg, timeout)
+	}
+	return h, nil
+}
+
+// run runs the plugin's executable on msg.
+func (p Plugin) run(townRoot string, subject *regexp.Regexp, msg *mail.Message, timeout time.Duration) (string, error) {
+	command := p.Command
+	if !filepath.IsAbs(command) && strings.ContainsRune(command, filepath.Separator) {
+		command = filepath.Join(townRoot, command)
+	}
+	input, err := json.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	match := ""
+	if m := subject.FindStringSubmatch(msg.Subject); len(m) > 1 {
+		match = m[1]
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, command, p.Args...) //nolint:gosec // G204: command is configured by the town
+	cmd.Dir = townRoot
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(os.Environ(),
+		"GT_TOWN_ROOT="+townRoot,
+		"GT_CALLBACK_TYPE="+p.Type,
+		"GT_CALLBACK_MATCH="+match,
+		"GT_MESSAGE_ID="+msg.ID,
+		"GT_MESSAGE_FROM="+msg.From,
+		"GT_MESSAGE_SUBJECT="+msg.Subject,
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("%s timed out after %s", p.Command, timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", p.Command, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", p.Command, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if action := strings.TrimSpace(lines[len(lines)-1]); action != "" {
+		return action, nil
+	}
+	return "ran " + p.Command, nil
+}
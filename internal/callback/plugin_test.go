@@ -0,0 +1,101 @@
+package callback
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+func writePlugins(t *testing.T, townRoot, config string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(townRoot, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(PluginConfigPath(townRoot), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPluginRuns(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin test uses a shell script")
+	}
+	townRoot := t.TempDir()
+	script := `#!/bin/sh
+read -r input
+case "$input" in *'"subject":"DEPLOY_DONE v1.2"'*) ;; *) echo "bad input: $input" >&2; exit 1;; esac
+[ "$GT_CALLBACK_MATCH" = v1.2 ] || { echo "match=$GT_CALLBACK_MATCH" >&2; exit 1; }
+[ "$GT_MESSAGE_FROM" = ci ] || exit 1
+[ "$(pwd -P)" = "$(cd "$GT_TOWN_ROOT" && pwd -P)" ] || exit 1
+echo "checking"
+echo "recorded deploy $GT_CALLBACK_MATCH"
+`
+	if err := os.MkdirAll(filepath.Join(townRoot, "scripts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, "scripts", "deploy.sh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writePlugins(t, townRoot, `{"handlers": [
+		{"type": "deploy_done", "subject": "^DEPLOY_DONE\\s+(\\S+)", "from": "^ci$", "command": "scripts/deploy.sh"},
+		{"type": "fails", "subject": "^FAILS", "command": "sh", "args": ["-c", "echo boom >&2; exit 3"], "dry_run": "would fail"},
+		{"type": "off", "subject": "^OFF", "command": "true", "disabled": true}
+	]}`)
+
+	r := NewRegistry()
+	if err := RegisterPlugins(r, townRoot); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(r.Handlers()); n != 2 {
+		t.Fatalf("registered %d plugins", n)
+	}
+
+	deploy := mail.NewMessage("ci", "mayor/", "DEPLOY_DONE v1.2", "")
+	h := r.Lookup(deploy)
+	if h == nil || h.Type != "deploy_done" || h.Source != filepath.Join("settings", "callbacks.json") {
+		t.Fatalf("Lookup = %+v", h)
+	}
+	if got, err := h.Run(townRoot, deploy, false); err != nil || got != "recorded deploy v1.2" {
+		t.Errorf("Run = %q, %v", got, err)
+	}
+	if got, _ := h.Run(townRoot, deploy, true); got != "would run scripts/deploy.sh" {
+		t.Errorf("dry run = %q", got)
+	}
+	if r.Lookup(mail.NewMessage("someone", "mayor/", "DEPLOY_DONE v1.2", "")) != nil {
+		t.Error("plugin matched the wrong sender")
+	}
+
+	fails := r.Lookup(mail.NewMessage("x", "mayor/", "FAILS now", ""))
+	if _, err := fails.Run(townRoot, mail.NewMessage("x", "mayor/", "FAILS now", ""), false); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("failing plugin error = %v", err)
+	}
+	if got, _ := fails.Run(townRoot, nil, true); got != "would fail" {
+		t.Errorf("dry run = %q", got)
+	}
+}
+
+func TestLoadPluginsErrors(t *testing.T) {
+	if handlers, err := LoadPlugins(t.TempDir()); err != nil || handlers != nil {
+		t.Errorf("no config = %v, %v", handlers, err)
+	}
+	for name, config := range map[string]string{
+		"bad json":        `{`,
+		"no type":         `{"handlers": [{"subject": "^X", "command": "true"}]}`,
+		"no command":      `{"handlers": [{"type": "x", "subject": "^X"}]}`,
+		"no subject":      `{"handlers": [{"type": "x", "command": "true"}]}`,
+		"bad subject":     `{"handlers": [{"type": "x", "subject": "(", "command": "true"}]}`,
+		"bad from":        `{"handlers": [{"type": "x", "subject": "^X", "from": "(", "command": "true"}]}`,
+		"bad timeout":     `{"handlers": [{"type": "x", "subject": "^X", "command": "true", "timeout": "soon"}]}`,
+		"duplicate types": `{"handlers": [{"type": "x", "subject": "^X", "command": "true"}, {"type": "x", "subject": "^Y", "command": "true"}]}`,
+	} {
+		townRoot := t.TempDir()
+		writePlugins(t, townRoot, config)
+		if err := RegisterPlugins(NewRegistry(), townRoot); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
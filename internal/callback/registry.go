@@ -0,0 +1,173 @@
+// Package callback routes callback mail in the Mayor's inbox to handlers.
+//
+// Each Handler pairs a callback type with the subject pattern (or message
+// matcher) that identifies it and the function that handles it. gt
+// callbacks process registers the built-in handlers (POLECAT_DONE, HELP:,
+// GH_CI_FAILED, ...); other packages can Register their own, and a town can
+// add handlers that run external executables (see LoadPlugins).
+package callback
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+// Type identifies a kind of callback, e.g. "polecat_done".
+type Type string
+
+// Unknown is the type of callbacks no handler matches.
+const Unknown Type = "unknown"
+
+// HandleFunc handles a callback and returns a description of what it did.
+// In a dry run it returns what it would do instead, without doing it.
+type HandleFunc func(townRoot string, msg *mail.Message, dryRun bool) (string, error)
+
+// Handler handles one type of callback.
+type Handler struct {
+	// Type names the callback type in output and logs.
+	Type Type
+
+	// Pattern matches the subjects of callbacks of this type.
+	Pattern *regexp.Regexp
+
+	// Match, if set, matches callbacks instead of Pattern, for callbacks
+	// known by something other than their subject.
+	Match func(msg *mail.Message) bool
+
+	// Subject is how the subject reads in help, e.g. "POLECAT_DONE".
+	Subject string
+
+	// Summary says briefly what the handler does, for help.
+	Summary string
+
+	// Handle handles a callback.
+	Handle HandleFunc
+
+	// DryRun, if set, describes what Handle would do; dry runs return it
+	// instead of calling Handle.
+	DryRun string
+
+	// Source is where the handler came from: "builtin", a package, or a
+	// plugin config file.
+	Source string
+}
+
+// matches reports whether the handler handles msg.
+func (h *Handler) matches(msg *mail.Message) bool {
+	if h.Match != nil {
+		return h.Match(msg)
+	}
+	return h.Pattern.MatchString(msg.Subject)
+}
+
+// Run handles msg, or describes what it would do in a dry run.
+func (h *Handler) Run(townRoot string, msg *mail.Message, dryRun bool) (string, error) {
+	if dryRun && h.DryRun != "" {
+		return h.DryRun, nil
+	}
+	return h.Handle(townRoot, msg, dryRun)
+}
+
+// Registry holds handlers, tried in the order they were registered.
+type Registry struct {
+	mu       sync.RWMutex
+	handlers []*Handler
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a handler. Its type must be new to the registry.
+func (r *Registry) Register(h Handler) error {
+	if h.Type == "" || h.Type == Unknown {
+		return fmt.Errorf("invalid callback type %q", h.Type)
+	}
+	if h.Pattern == nil && h.Match == nil {
+		return fmt.Errorf("callback %s: no pattern", h.Type)
+	}
+	if h.Handle == nil {
+		return fmt.Errorf("callback %s: no handler", h.Type)
+	}
+	if h.Subject == "" && h.Pattern != nil {
+		h.Subject = h.Pattern.String()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.handlers {
+		if existing.Type == h.Type {
+			return fmt.Errorf("callback %s is already registered", h.Type)
+		}
+	}
+	r.handlers = append(r.handlers, &h)
+	return nil
+}
+
+// Clone returns a copy of the registry that can be extended without
+// changing r.
+func (r *Registry) Clone() *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return &Registry{handlers: append([]*Handler(nil), r.handlers...)}
+}
+
+// Lookup returns the first handler for msg, or nil if none matches.
+func (r *Registry) Lookup(msg *mail.Message) *Handler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, h := range r.handlers {
+		if h.matches(msg) {
+			return h
+		}
+	}
+	return nil
+}
+
+// Classify returns the type of a callback by its subject alone, or
+// Unknown. Handlers that match by other means are not consulted.
+func (r *Registry) Classify(subject string) Type {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, h := range r.handlers {
+		if h.Match == nil && h.Pattern.MatchString(subject) {
+			return h.Type
+		}
+	}
+	return Unknown
+}
+
+// Handlers returns the registered handlers in order.
+func (r *Registry) Handlers() []Handler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Handler, len(r.handlers))
+	for i, h := range r.handlers {
+		out[i] = *h
+	}
+	return out
+}
+
+var defaultRegistry = NewRegistry()
+
+// Default returns the process-wide registry gt callbacks process uses.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Register adds a handler to the default registry.
+func Register(h Handler) error {
+	return defaultRegistry.Register(h)
+}
+
+// MustRegister adds a handler to the default registry, panicking if it is
+// invalid. It is meant for init functions.
+func MustRegister(h Handler) {
+	if err := Register(h); err != nil {
+		panic(err)
+	}
+}
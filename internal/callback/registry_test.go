@@ -0,0 +1,92 @@
+package callback
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+func echo(action string) HandleFunc {
+	return func(_ string, msg *mail.Message, dryRun bool) (string, error) {
+		if dryRun {
+			return "would " + action, nil
+		}
+		return action, nil
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	for _, h := range []Handler{
+		{Type: "by_sender", Match: func(m *mail.Message) bool { return m.From == "scheduler" }, Handle: echo("forward")},
+		{Type: "done", Pattern: regexp.MustCompile(`^DONE\s+(\S+)`), Handle: echo("log")},
+		{Type: "static", Pattern: regexp.MustCompile(`^STATIC`), Handle: echo("run"), DryRun: "would run static"},
+	} {
+		if err := r.Register(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := r.Classify("DONE nux"); got != "done" {
+		t.Errorf("Classify(DONE) = %q", got)
+	}
+	if got := r.Classify("whatever"); got != Unknown {
+		t.Errorf("Classify(whatever) = %q", got)
+	}
+
+	// Handlers are tried in order: the sender match wins over the subject
+	h := r.Lookup(&mail.Message{From: "scheduler", Subject: "DONE nux"})
+	if h == nil || h.Type != "by_sender" {
+		t.Fatalf("Lookup = %+v", h)
+	}
+	if r.Lookup(&mail.Message{From: "x", Subject: "nope"}) != nil {
+		t.Error("Lookup matched an unknown subject")
+	}
+
+	static := r.Lookup(&mail.Message{Subject: "STATIC"})
+	if got, _ := static.Run("", nil, true); got != "would run static" {
+		t.Errorf("dry run = %q", got)
+	}
+	if got, _ := static.Run("", nil, false); got != "run" {
+		t.Errorf("run = %q", got)
+	}
+	done := r.Lookup(&mail.Message{Subject: "DONE nux"})
+	if got, _ := done.Run("", &mail.Message{}, true); got != "would log" {
+		t.Errorf("handler dry run = %q", got)
+	}
+	if done.Subject != `^DONE\s+(\S+)` {
+		t.Errorf("default subject = %q", done.Subject)
+	}
+}
+
+func TestRegisterErrors(t *testing.T) {
+	r := NewRegistry()
+	pattern := regexp.MustCompile("^X")
+	if err := r.Register(Handler{Type: "x", Pattern: pattern, Handle: echo("x")}); err != nil {
+		t.Fatal(err)
+	}
+	for name, h := range map[string]Handler{
+		"duplicate":  {Type: "x", Pattern: pattern, Handle: echo("x")},
+		"no type":    {Pattern: pattern, Handle: echo("x")},
+		"unknown":    {Type: Unknown, Pattern: pattern, Handle: echo("x")},
+		"no pattern": {Type: "y", Handle: echo("x")},
+		"no handler": {Type: "y", Pattern: pattern},
+	} {
+		if err := r.Register(h); err == nil {
+			t.Errorf("%s: registered", name)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	r := NewRegistry()
+	_ = r.Register(Handler{Type: "a", Pattern: regexp.MustCompile("^A"), Handle: echo("a")})
+	c := r.Clone()
+	if err := c.Register(Handler{Type: "b", Pattern: regexp.MustCompile("^B"), Handle: echo("b")}); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Handlers()) != 1 || len(c.Handlers()) != 2 {
+		t.Errorf("handlers = %d, clone %d", len(r.Handlers()), len(c.Handlers()))
+	}
+}
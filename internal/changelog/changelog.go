@@ -0,0 +1,210 @@
+// Package changelog records what each merge shipped and compiles release
+// notes from the record.
+//
+// The Refinery appends an Entry for every merge it lands: the issue it
+// closed, who did the work, and the merge commit. Release notes for a range
+// of history (gt release notes --since <tag>) are the entries whose merge
+// commits fall in that range, grouped by issue type.
+//
+// The log lives at <town>/logs/changelog.jsonl, one JSON entry per line,
+// with entries for every rig.
+package changelog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// File is the changelog's name within the town's logs directory.
+const File = "changelog.jsonl"
+
+// Entry records one merge.
+type Entry struct {
+	Merged time.Time `json:"merged"`
+	Rig    string    `json:"rig"`
+	Issue  string    `json:"issue,omitempty"` // Source issue the merge closed
+	Title  string    `json:"title"`
+	Type   string    `json:"type,omitempty"`   // Issue type: feature, bug, task, ...
+	Worker string    `json:"worker,omitempty"` // Agent who did the work
+	MR     string    `json:"mr,omitempty"`
+	Branch string    `json:"branch,omitempty"`
+	Target string    `json:"target,omitempty"`
+	Commit string    `json:"commit"` // Merge commit on Target
+}
+
+// Path returns the changelog path for a town.
+func Path(townRoot string) string {
+	return filepath.Join(townRoot, "logs", File)
+}
+
+// Append adds an entry to the town's changelog, stamping Merged if it is
+// zero. Appends from concurrent processes are serialized with a file lock.
+func Append(townRoot string, e Entry) error {
+	if e.Merged.IsZero() {
+		e.Merged = time.Now().UTC()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	path := Path(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating logs directory: %w", err)
+	}
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking changelog: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("opening changelog: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing changelog: %w", err)
+	}
+	return nil
+}
+
+// Read returns the town's changelog entries for rig (all rigs if empty),
+// oldest first. Malformed lines, such as a torn final write, are skipped,
+// as are repeat entries for a merge commit already recorded.
+func Read(townRoot, rig string) ([]Entry, error) {
+	f, err := os.Open(Path(townRoot))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening changelog: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	seen := make(map[string]bool)
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil || e.Commit == "" {
+			continue
+		}
+		key := e.Rig + "@" + e.Commit
+		if seen[key] || (rig != "" && e.Rig != rig) {
+			continue
+		}
+		seen[key] = true
+		entries = append(entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading changelog: %w", err)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Merged.Before(entries[j].Merged) })
+	return entries, nil
+}
+
+// InCommits returns the entries whose merge commit is one of commits.
+func InCommits(entries []Entry, commits []string) []Entry {
+	set := make(map[string]bool, len(commits))
+	for _, c := range commits {
+		set[c] = true
+	}
+	var in []Entry
+	for _, e := range entries {
+		if set[e.Commit] {
+			in = append(in, e)
+		}
+	}
+	return in
+}
+
+// Section headings, in the order Render writes them.
+var sections = []struct {
+	heading string
+	types   []string // nil matches every type not claimed by another section
+}{
+	{"Features", []string{"feature", "epic"}},
+	{"Fixes", []string{"bug"}},
+	{"Other changes", nil},
+}
+
+// Render writes release notes for entries as Markdown, under title.
+func Render(w io.Writer, title string, entries []Entry) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", title)
+	if len(entries) == 0 {
+		b.WriteString("\nNo merges.\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	claimed := make(map[string]bool)
+	for _, s := range sections {
+		for _, t := range s.types {
+			claimed[t] = true
+		}
+	}
+	for _, s := range sections {
+		var in []Entry
+		for _, e := range entries {
+			if matches(s.types, claimed, e.Type) {
+				in = append(in, e)
+			}
+		}
+		if len(in) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", s.heading)
+		for _, e := range in {
+			fmt.Fprintf(&b, "- %s\n", noteLine(e))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func matches(types []string, claimed map[string]bool, t string) bool {
+	if types == nil {
+		return !claimed[t]
+	}
+	for _, want := range types {
+		if t == want {
+			return true
+		}
+	}
+	return false
+}
+
+// noteLine describes one merge: "Add widgets (gt-abc, gastown/polecats/Toast)".
+func noteLine(e Entry) string {
+	var refs []string
+	if e.Issue != "" {
+		refs = append(refs, e.Issue)
+	} else if len(e.Commit) >= 7 {
+		refs = append(refs, e.Commit[:7])
+	}
+	if e.Worker != "" {
+		refs = append(refs, e.Worker)
+	}
+	line := e.Title
+	if line == "" {
+		line = e.Branch
+	}
+	if len(refs) > 0 {
+		line += " (" + strings.Join(refs, ", ") + ")"
+	}
+	return line
+}
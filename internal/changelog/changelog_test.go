@@ -0,0 +1,114 @@
+package changelog
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendRead(t *testing.T) {
+	town := t.TempDir()
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, e := range []Entry{
+		{Merged: t0.Add(2 * time.Hour), Rig: "gastown", Title: "Second", Commit: "bbb"},
+		{Merged: t0, Rig: "gastown", Title: "First", Commit: "aaa"},
+		{Merged: t0, Rig: "beads", Title: "Other rig", Commit: "ccc"},
+		{Merged: t0.Add(3 * time.Hour), Rig: "gastown", Title: "First again", Commit: "aaa"},
+	} {
+		if err := Append(town, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A torn write at the end of the log is skipped.
+	f, err := os.OpenFile(Path(town), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"rig":"gastown","ti`)
+	f.Close()
+
+	entries, err := Read(town, "gastown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, e := range entries {
+		titles = append(titles, e.Title)
+	}
+	if got := strings.Join(titles, ","); got != "First,Second" {
+		t.Errorf("Read(gastown) titles = %s, want First,Second", got)
+	}
+
+	all, err := Read(town, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Read(all) returned %d entries, want 3", len(all))
+	}
+}
+
+func TestReadMissing(t *testing.T) {
+	entries, err := Read(t.TempDir(), "")
+	if err != nil || entries != nil {
+		t.Errorf("Read of missing changelog = %v, %v; want nil, nil", entries, err)
+	}
+}
+
+func TestAppendStampsTime(t *testing.T) {
+	town := t.TempDir()
+	if err := Append(town, Entry{Rig: "gastown", Title: "x", Commit: "aaa"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := Read(town, "")
+	if len(entries) != 1 || entries[0].Merged.IsZero() {
+		t.Errorf("entries = %+v, want one with a merge time", entries)
+	}
+}
+
+func TestInCommits(t *testing.T) {
+	entries := []Entry{{Commit: "aaa"}, {Commit: "bbb"}, {Commit: "ccc"}}
+	got := InCommits(entries, []string{"ccc", "aaa", "zzz"})
+	if len(got) != 2 || got[0].Commit != "aaa" || got[1].Commit != "ccc" {
+		t.Errorf("InCommits = %+v, want aaa and ccc in log order", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	entries := []Entry{
+		{Title: "Add widgets", Type: "feature", Issue: "gt-abc", Worker: "gastown/polecats/Toast", Commit: "1111111aaaa"},
+		{Title: "Fix crash on empty input", Type: "bug", Issue: "gt-def", Commit: "2222222bbbb"},
+		{Title: "Bump deps", Type: "chore", Commit: "3333333cccc"},
+		{Branch: "polecat/nux", Commit: "4444444dddd"},
+	}
+	var b strings.Builder
+	if err := Render(&b, "gastown release notes: v1.0..main", entries); err != nil {
+		t.Fatal(err)
+	}
+	want := `# gastown release notes: v1.0..main
+
+## Features
+
+- Add widgets (gt-abc, gastown/polecats/Toast)
+
+## Fixes
+
+- Fix crash on empty input (gt-def)
+
+## Other changes
+
+- Bump deps (3333333)
+- polecat/nux (4444444)
+`
+	if b.String() != want {
+		t.Errorf("Render =\n%s\nwant\n%s", b.String(), want)
+	}
+
+	b.Reset()
+	_ = Render(&b, "empty", nil)
+	if !strings.Contains(b.String(), "No merges.") {
+		t.Errorf("Render of no entries = %q", b.String())
+	}
+}
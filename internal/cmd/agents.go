@@ -481,7 +481,7 @@ func runAgentsFix(cmd *cobra.Command, args []string) error {
 		}
 
 		fmt.Println()
-		fmt.Printf("To fix, close duplicate sessions or remove lock files manually.\n")
+		fmt.Printf("To fix, close duplicate sessions or release locks with %s.\n", style.Dim.Render("gt locks release"))
 	}
 
 	return nil
@@ -523,13 +523,19 @@ func buildCollisionReport(townRoot string) (*CollisionReport, error) {
 			report.Issues = append(report.Issues, CollisionIssue{
 				Type:      "stale",
 				WorkerDir: workerDir,
-				Message:   fmt.Sprintf("Stale lock (dead PID %d)", lockInfo.PID),
+				Message:   fmt.Sprintf("Stale lock (%s, PID %d)", lockInfo.State(), lockInfo.PID),
 				PID:       lockInfo.PID,
 				SessionID: lockInfo.SessionID,
 			})
 			continue
 		}
 
+		// Locks taken on another host (shared filesystem) have their
+		// session in that host's tmux, not ours
+		if !lockInfo.IsLocal() {
+			continue
+		}
+
 		// Check if the locked session exists in tmux
 		expectedSession := guessSessionFromWorkerDir(workerDir, townRoot)
 		if expectedSession != "" {
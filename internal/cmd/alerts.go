@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/alert"
+	"github.com/steveyegge/gastown/internal/daemon"
+	"github.com/steveyegge/gastown/internal/slo"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var alertsJSON bool
+
+var alertsCmd = &cobra.Command{
+	Use:     "alerts",
+	GroupID: GroupDiag,
+	Short:   "Show alert rules and which are firing",
+	Long: `Show the town's alert rules and their state as last evaluated by the daemon.
+
+Alert rules are configured under "alerts" in mayor/daemon.json and evaluated
+by the daemon every minute. A rule watches an event rate, the absence of an
+event, a metric, or a merge queue SLO (see gt stats), and runs its actions
+when it starts firing:
+
+  {
+    "alerts": {
+      "rules": [
+        {"name": "merge-failures", "event": "merge_failed", "rig": "gastown",
+         "above": 3, "window": "1h",
+         "actions": [{"type": "mail"}, {"type": "pause_queue"}]},
+        {"name": "witness-silent", "event": "patrol_complete",
+         "actor": "gastown/witness", "absent_for": "2h",
+         "actions": [{"type": "slack", "url": "${GT_SLACK_WEBHOOK}"}]},
+        {"name": "queue-backlog", "metric": "gastown_merge_queue_depth",
+         "labels": {"state": "pending"}, "above": 20, "repeat": "4h",
+         "actions": [{"type": "mail", "to": "mayor/"}]},
+        {"name": "merge-slo", "slo": "merge-2h",
+         "actions": [{"type": "slack", "url": "${GT_SLACK_WEBHOOK}"}]}
+      ]
+    }
+  }
+
+Every SLO without a rule of its own gets a default rule, slo-<name>, that
+mails the overseer when its error budget is exhausted.
+
+Actions:
+  mail         Mail an address (default: overseer)
+  slack        Post to a Slack incoming webhook
+  webhook      POST the alert as JSON to a URL
+  pause_queue  Pause the rig's merge queue (resume: gt refinery resume <rig>)
+
+Firing alerts also reach the town's notification channels, batched and
+deduplicated (see gt notifications). Firing and resolved alerts appear on
+the activity feed (gt feed).`,
+	RunE: runAlerts,
+}
+
+func init() {
+	alertsCmd.Flags().BoolVar(&alertsJSON, "json", false, "Output as JSON")
+	rootCmd.AddCommand(alertsCmd)
+}
+
+// alertStatus is one rule in gt alerts output.
+type alertStatus struct {
+	Name      string           `json:"name"`
+	Condition string           `json:"condition"`
+	Actions   []string         `json:"actions"`
+	Disabled  bool             `json:"disabled,omitempty"`
+	State     *alert.RuleState `json:"state,omitempty"`
+}
+
+func runAlerts(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	var rules []alert.Rule
+	var objectives []slo.Objective
+	if cfg := daemon.LoadPatrolConfig(townRoot); cfg != nil {
+		var alerts alert.Config
+		if cfg.Alerts != nil {
+			alerts = *cfg.Alerts
+		}
+		objectives = cfg.SLOs
+		rules = alert.EffectiveRules(alerts, objectives)
+	}
+	state, err := alert.LoadState(townRoot)
+	if err != nil {
+		return fmt.Errorf("reading alert state: %w", err)
+	}
+
+	statuses := make([]alertStatus, 0, len(rules))
+	for _, r := range rules {
+		s := alertStatus{Name: r.Name, Condition: alert.Describe(r), Disabled: r.Disabled, State: state[r.Name]}
+		if o, ok := slo.Find(objectives, r.SLO); ok {
+			s.Condition += " (" + slo.Describe(o) + ")"
+		}
+		for _, a := range r.Actions {
+			s.Actions = append(s.Actions, a.Type)
+		}
+		statuses = append(statuses, s)
+	}
+
+	if alertsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statuses)
+	}
+
+	if len(statuses) == 0 {
+		fmt.Printf("%s No alert rules configured (see gt alerts --help)\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	fmt.Printf("\n%s Alert Rules\n\n", style.Bold.Render("🚨"))
+	for _, s := range statuses {
+		var status string
+		switch {
+		case s.Disabled:
+			status = style.Dim.Render("disabled")
+		case s.State == nil:
+			status = style.Dim.Render("not evaluated")
+		case s.State.Firing:
+			status = style.Error.Render("FIRING") + " since " + formatDuration(time.Since(s.State.FiredAt)) + " ago"
+		default:
+			status = style.Success.Render("ok")
+		}
+		fmt.Printf("  %s  %s\n", style.Bold.Render(s.Name), status)
+		fmt.Printf("     when: %s\n", s.Condition)
+		fmt.Printf("     then: %s\n", strings.Join(s.Actions, ", "))
+		if s.State != nil && s.State.Firing {
+			fmt.Printf("     %s\n", s.State.Summary)
+		}
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/approval"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/identity"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	approvalsJSON  bool
+	approveMessage string
+	denyMessage    string
+)
+
+var approvalsCmd = &cobra.Command{
+	Use:     "approvals",
+	GroupID: GroupWork,
+	Short:   "List everything waiting on the overseer's approval",
+	Long: `List the pending approvals across the town.
+
+Agents file an approval bead, and mail the overseer, whenever a step needs
+a human's say-so:
+
+  merge   An MR touches a rig's protected paths (settings/config.json:
+          "approval": {"protected_paths": ["migrations/**"]}). The MR is
+          held out of the merge queue until approved.
+  budget  A budget was exceeded. Approving lets work continue past it.
+  nuke    A polecat with work at risk was asked to be nuked
+          (gt polecat nuke --request-approval). Approving nukes it.
+
+Settle each with gt approve or gt deny.
+
+Examples:
+  gt approvals
+  gt approve gt-ap-abc
+  gt deny gt-ap-abc -m "Migration drops a column we still read"`,
+	Args: cobra.NoArgs,
+	RunE: runApprovals,
+}
+
+var approveCmd = &cobra.Command{
+	Use:     "approve <approval-id>",
+	GroupID: GroupWork,
+	Short:   "Approve a pending request and carry it out",
+	Long: `Approve a pending request (see gt approvals) and carry it out:
+a held MR is released into the merge queue, a budget override is passed on
+to whoever reported it, a polecat is nuked.
+
+The requester is mailed the decision and any note given with -m.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runApprove,
+}
+
+var denyCmd = &cobra.Command{
+	Use:     "deny <approval-id>",
+	GroupID: GroupWork,
+	Short:   "Deny a pending request",
+	Long: `Deny a pending request (see gt approvals). A held MR is rejected;
+anything else is left as it is. The requester is mailed the reason.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDeny,
+}
+
+func init() {
+	approvalsCmd.Flags().BoolVar(&approvalsJSON, "json", false, "Output as JSON")
+	approveCmd.Flags().StringVarP(&approveMessage, "message", "m", "", "Note for the requester")
+	denyCmd.Flags().StringVarP(&denyMessage, "message", "m", "", "Why the request is denied (required)")
+	_ = denyCmd.MarkFlagRequired("message")
+
+	rootCmd.AddCommand(approvalsCmd)
+	rootCmd.AddCommand(approveCmd)
+	rootCmd.AddCommand(denyCmd)
+}
+
+// beadStore is one of the town's beads databases.
+type beadStore struct {
+	scope string // "town" or a rig name
+	bd    *beads.Beads
+}
+
+// beadStores returns the town's beads and every rig's.
+func beadStores(townRoot string) []beadStore {
+	stores := []beadStore{{scope: "town", bd: beads.New(beads.ResolveBeadsDir(townRoot))}}
+	rigs, err := discoverAllRigs(townRoot)
+	if err != nil {
+		return stores
+	}
+	for _, r := range rigs {
+		stores = append(stores, beadStore{scope: r.Name, bd: beads.New(r.BeadsPath())})
+	}
+	return stores
+}
+
+// beadStoreFor returns the beads database holding the bead id,
+// routed by its prefix.
+func beadStoreFor(townRoot, id string) *beads.Beads {
+	dir := beads.GetRigPathForPrefix(townRoot, beads.ExtractPrefix(id))
+	if dir == "" {
+		dir = townRoot
+	}
+	return beads.New(beads.ResolveBeadsDir(dir))
+}
+
+func runApprovals(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	type approvalRow struct {
+		ID          string `json:"id"`
+		Kind        string `json:"kind"`
+		Subject     string `json:"subject"`
+		Title       string `json:"title"`
+		Scope       string `json:"scope"`
+		RequestedBy string `json:"requested_by,omitempty"`
+		CreatedAt   string `json:"created_at,omitempty"`
+	}
+	rows := []approvalRow{}
+	for _, s := range beadStores(townRoot) {
+		issues, err := approval.New(s.bd).Pending()
+		if err != nil {
+			continue // rig without beads
+		}
+		for _, issue := range issues {
+			f := approval.ParseFields(issue)
+			if f == nil {
+				continue
+			}
+			rows = append(rows, approvalRow{
+				ID: issue.ID, Kind: f.Kind, Subject: f.Subject,
+				Title: strings.TrimPrefix(issue.Title, "Approval: "),
+				Scope: s.scope, RequestedBy: f.RequestedBy, CreatedAt: issue.CreatedAt,
+			})
+		}
+	}
+
+	if approvalsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	}
+	if len(rows) == 0 {
+		fmt.Printf("%s Nothing awaiting approval\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	fmt.Printf("%s\n\n", style.Bold.Render(fmt.Sprintf("Awaiting approval (%d):", len(rows))))
+	for _, row := range rows {
+		fmt.Printf("  %s  %-6s %s\n", style.Bold.Render(row.ID), row.Kind, row.Title)
+		if row.RequestedBy != "" {
+			fmt.Printf("      %s\n", style.Dim.Render(fmt.Sprintf("%s · requested by %s", row.Scope, row.RequestedBy)))
+		}
+	}
+	fmt.Printf("\n%s\n", style.Dim.Render("gt show <id> for details · gt approve <id> · gt deny <id> -m \"<why>\""))
+	return nil
+}
+
+func runApprove(cmd *cobra.Command, args []string) error {
+	return decideApproval(args[0], true, approveMessage)
+}
+
+func runDeny(cmd *cobra.Command, args []string) error {
+	return decideApproval(args[0], false, denyMessage)
+}
+
+// decideApproval settles an approval: it carries out or rejects what the
+// approval was holding back, records the decision, and mails the requester.
+func decideApproval(id string, approved bool, note string) error {
+	if role := os.Getenv("GT_ROLE"); role != "" {
+		return fmt.Errorf("approvals are decided by the overseer, not agents (GT_ROLE=%s)", role)
+	}
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	bd := beadStoreFor(townRoot, id)
+	approvals := approval.New(bd)
+	issue, f, err := approvals.Get(id)
+	if err != nil {
+		return err
+	}
+
+	// Act before recording the decision, so a failed action leaves the
+	// approval pending to retry.
+	if approved && f.Kind == approval.KindNuke {
+		c := exec.Command("gt", "polecat", "nuke", f.Subject, "--force") //nolint:gosec // G204: subject from our own approval bead
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("nuking %s: %w", f.Subject, err)
+		}
+	}
+
+	by := detectSender()
+	if err := approvals.Decide(issue, f, approved, by, note); err != nil {
+		return fmt.Errorf("recording decision on %s: %w", id, err)
+	}
+
+	title := strings.TrimPrefix(issue.Title, "Approval: ")
+	if approved {
+		fmt.Printf("%s Approved %s: %s\n", style.SuccessPrefix, id, title)
+		if f.Kind == approval.KindMerge {
+			fmt.Printf("  %s released into the merge queue\n", f.Subject)
+		}
+	} else {
+		fmt.Printf("%s Denied %s: %s\n", style.Bold.Render("✗"), id, title)
+		if f.Kind == approval.KindMerge {
+			reason := "rejected: denied by overseer: " + note
+			if err := bd.CloseWithReason(reason, f.Subject); err != nil {
+				style.PrintWarning("could not reject %s: %v", f.Subject, err)
+			} else {
+				fmt.Printf("  %s rejected\n", f.Subject)
+			}
+		}
+	}
+
+	notifyApprovalDecision(townRoot, id, title, f)
+	return nil
+}
+
+// notifyApprovalDecision mails the requester the overseer's decision.
+func notifyApprovalDecision(townRoot, id, title string, f *approval.Fields) {
+	if f.Notify == "" {
+		return
+	}
+	verb := "Approved"
+	if f.Status == approval.StatusDenied {
+		verb = "Denied"
+	}
+	body := fmt.Sprintf("Approval: %s\nKind: %s\nSubject: %s\nDecided by: %s\n", id, f.Kind, f.Subject, f.DecidedBy)
+	if f.Note != "" {
+		body += "\n" + f.Note + "\n"
+	}
+	switch {
+	case f.Kind == approval.KindMerge && f.Status == approval.StatusApproved:
+		body += "\nThe MR is back in the merge queue.\n"
+	case f.Kind == approval.KindMerge:
+		body += "\nThe MR was rejected.\n"
+	case f.Kind == approval.KindBudget && f.Status == approval.StatusApproved:
+		body += "\nWork may continue past the budget.\n"
+	case f.Kind == approval.KindBudget:
+		body += "\nStop spending against this budget until it is raised.\n"
+	}
+
+	msg := &mail.Message{
+		From:     detectSender(),
+		To:       f.Notify,
+		Subject:  fmt.Sprintf("%s: %s", verb, title),
+		Priority: mail.PriorityHigh,
+		Body:     body,
+	}
+	if err := mail.NewRouter(townRoot).Send(msg); err != nil {
+		style.PrintWarning("could not notify %s: %v", f.Notify, err)
+		return
+	}
+	fmt.Printf("  Notified %s\n", f.Notify)
+}
+
+// fileApproval files an approval for f in bd, or returns the one already
+// pending for the same subject. The bool reports whether it is new.
+func fileApproval(bd *beads.Beads, f *approval.Fields, title, details string) (*beads.Issue, bool, error) {
+	approvals := approval.New(bd)
+	issue, _, err := approvals.For(f.Kind, f.Subject)
+	if err == nil {
+		return issue, false, nil
+	}
+	if !errors.Is(err, approval.ErrNotFound) {
+		return nil, false, err
+	}
+	issue, err = approvals.Request(f, title, details)
+	if err != nil {
+		return nil, false, err
+	}
+	return issue, true, nil
+}
+
+// requestApproval files an approval and mails the overseer about it.
+func requestApproval(townRoot string, bd *beads.Beads, f *approval.Fields, title, details string) (*beads.Issue, error) {
+	issue, created, err := fileApproval(bd, f, title, details)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		fmt.Printf("%s Approval %s already pending\n", style.Bold.Render("✓"), issue.ID)
+		return issue, nil
+	}
+	msg := &mail.Message{
+		From:     detectSender(),
+		To:       "overseer",
+		Subject:  fmt.Sprintf("[APPROVAL] %s", title),
+		Priority: mail.PriorityHigh,
+		Body:     issue.Description,
+	}
+	if err := mail.NewRouter(townRoot).Send(msg); err != nil {
+		style.PrintWarning("could not mail overseer about %s: %v", issue.ID, err)
+	}
+	fmt.Printf("%s Approval %s requested from the overseer\n", style.Bold.Render("✓"), issue.ID)
+	return issue, nil
+}
+
+// loadApprovalConfig returns the rig's approval settings, or nil if unset.
+func loadApprovalConfig(rigPath string) *config.ApprovalConfig {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil {
+		return nil
+	}
+	return settings.Approval
+}
+
+// maybeRequestMergeApproval holds a submitted MR for the overseer if it
+// touches any of the rig's protected paths.
+func maybeRequestMergeApproval(townRoot string, bd *beads.Beads, mrID string, mr *beads.MRFields, mrChanges func() *git.ChangeSet) {
+	cfg := loadApprovalConfig(filepath.Join(townRoot, mr.Rig))
+	if cfg == nil || len(cfg.ProtectedPaths) == 0 {
+		return
+	}
+	changes := mrChanges()
+	if changes == nil {
+		style.PrintWarning("could not check %s against protected paths", mrID)
+		return
+	}
+	matched := changes.Match(cfg.ProtectedPaths...)
+	if len(matched) == 0 {
+		return
+	}
+
+	f := &approval.Fields{
+		Kind:        approval.KindMerge,
+		Subject:     mrID,
+		Rig:         mr.Rig,
+		RequestedBy: detectSender(),
+	}
+	if mr.Worker != "" {
+		f.Notify = identity.New(identity.Worker, mr.Rig, mr.Worker).Address()
+	}
+	var details strings.Builder
+	fmt.Fprintf(&details, "MR: %s\nBranch: %s\nTarget: %s\nIssue: %s\n\nProtected paths touched:\n",
+		mrID, mr.Branch, mr.Target, mr.SourceIssue)
+	for _, file := range matched {
+		fmt.Fprintf(&details, "  - %s\n", file)
+	}
+	title := fmt.Sprintf("merge %s into %s (%d protected file(s))", mr.Branch, mr.Target, len(matched))
+	if _, err := requestApproval(townRoot, bd, f, title, details.String()); err != nil {
+		style.PrintWarning("could not request approval for %s: %v", mrID, err)
+		return
+	}
+	fmt.Printf("  MR held until the overseer approves (%d protected file(s))\n", len(matched))
+}
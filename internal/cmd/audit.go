@@ -14,6 +14,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/logrotate"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/townlog"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -40,6 +41,9 @@ Shows a unified timeline of work performed by an actor including:
   - Town log events (spawn, done, handoff, etc.)
   - Activity feed events
 
+Merges to protected branches, config changes, and forced removals are also
+kept in a tamper-evident hash chain; check it with gt audit verify.
+
 Examples:
   gt audit --actor=greenplace/crew/joe       # Show all work by joe
   gt audit --actor=greenplace/polecats/toast # Show polecat toast's work
@@ -404,7 +408,7 @@ func collectFeedEvents(townRoot, actor string, since time.Time) ([]AuditEntry, e
 	var entries []AuditEntry
 
 	eventsPath := filepath.Join(townRoot, events.EventsFile)
-	file, err := os.Open(eventsPath)
+	file, err := logrotate.Open(eventsPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil // No events file yet
@@ -415,10 +419,13 @@ func collectFeedEvents(townRoot, actor string, since time.Time) ([]AuditEntry, e
 
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		var e events.Event
-		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+		e, err := events.Parse(scanner.Bytes())
+		if err != nil {
 			continue // Skip malformed lines
 		}
+		if e.Source == townlog.Source {
+			continue // Already collected from the town log
+		}
 
 		// Apply actor filter
 		if actor != "" && !matchesActor(e.Actor, actor) {
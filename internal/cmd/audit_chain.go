@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/auditchain"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	auditVerifyAnchors []string
+	auditVerifyJSON    bool
+)
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the tamper-evident audit chain",
+	Long: `Verify the town's tamper-evident audit chain (logs/audit.jsonl).
+
+The chain records merges to protected branches, configuration changes, and
+forced removals. Each entry carries the hash of the previous one, so any
+edited, deleted, inserted, or reordered entry is reported with its line.
+
+Entries cut from the end of the log cannot be detected from the log alone.
+Record the head periodically outside the town (gt audit head) and pass it
+back with --anchor to check the log still contains it.
+
+Exits non-zero if the chain is broken.
+
+Examples:
+  gt audit verify
+  gt audit verify --anchor 1042:9f86d081884c7d65...
+  gt audit verify --json`,
+	Args: cobra.NoArgs,
+	RunE: runAuditVerify,
+}
+
+var auditHeadCmd = &cobra.Command{
+	Use:   "head",
+	Short: "Print the audit chain head as an anchor for later verification",
+	Long: `Print the latest audit chain entry as "<seq>:<hash>".
+
+Store the anchor outside the town (a ticket, another host, a notary) and
+pass it to gt audit verify --anchor later to prove no entries up to it
+were removed or changed.`,
+	Args: cobra.NoArgs,
+	RunE: runAuditHead,
+}
+
+func init() {
+	auditVerifyCmd.Flags().StringArrayVar(&auditVerifyAnchors, "anchor", nil, "Previously recorded head (<seq>:<hash>) that must still be present (repeatable)")
+	auditVerifyCmd.Flags().BoolVar(&auditVerifyJSON, "json", false, "Output as JSON")
+
+	auditCmd.AddCommand(auditVerifyCmd)
+	auditCmd.AddCommand(auditHeadCmd)
+}
+
+func runAuditVerify(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	var anchors []auditchain.Anchor
+	for _, s := range auditVerifyAnchors {
+		a, err := auditchain.ParseAnchor(s)
+		if err != nil {
+			return err
+		}
+		anchors = append(anchors, a)
+	}
+
+	res, err := auditchain.VerifyTown(townRoot, anchors...)
+	if err != nil {
+		return fmt.Errorf("reading audit chain: %w", err)
+	}
+
+	if auditVerifyJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(res); err != nil {
+			return err
+		}
+	} else {
+		printAuditVerifyResult(res)
+	}
+	if !res.OK() {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+func printAuditVerifyResult(res auditchain.Result) {
+	if res.OK() {
+		if res.Entries == 0 {
+			fmt.Printf("%s Audit chain is empty\n", style.Dim.Render("○"))
+			return
+		}
+		fmt.Printf("%s Audit chain intact: %d entries\n", style.Bold.Render("✓"), res.Entries)
+		fmt.Printf("  head: %s\n", auditchain.Head(*res.Head))
+		return
+	}
+
+	fmt.Printf("%s Audit chain broken: %d problem(s) in %d entries\n\n",
+		style.Error.Render("✗"), len(res.Problems), res.Entries)
+	for _, p := range res.Problems {
+		where := "anchor"
+		if p.Line > 0 {
+			where = fmt.Sprintf("line %d", p.Line)
+		}
+		if p.Seq > 0 {
+			where += fmt.Sprintf(" (seq %d)", p.Seq)
+		}
+		fmt.Printf("  %s: %s\n", where, p.Reason)
+	}
+}
+
+func runAuditHead(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	res, err := auditchain.VerifyTown(townRoot)
+	if err != nil {
+		return fmt.Errorf("reading audit chain: %w", err)
+	}
+	if res.Head == nil {
+		return fmt.Errorf("audit chain is empty")
+	}
+	if !res.OK() {
+		style.PrintWarning("audit chain is broken (see gt audit verify); this head anchors the damaged chain")
+	}
+	fmt.Println(auditchain.Head(*res.Head))
+	return nil
+}
+
+// recordAudit appends an action to the town's tamper-evident audit chain.
+// The action has already happened, so a failure to record it is a warning.
+func recordAudit(townRoot, action, subject string, details map[string]string) {
+	if file, ok := details["file"]; ok && filepath.IsAbs(file) {
+		if rel, err := filepath.Rel(townRoot, file); err == nil {
+			details["file"] = rel
+		}
+	}
+	if err := auditchain.Record(townRoot, action, detectSender(), subject, details); err != nil {
+		style.PrintWarning("could not record %s in audit chain: %v", action, err)
+	}
+}
+
+// recordMergeAudit records the merge reported by a MERGED protocol message
+// in the audit chain when it landed on the rig's default or a protected
+// branch. Other messages are ignored.
+func recordMergeAudit(townRoot string, msg *mail.Message) {
+	if townRoot == "" || protocol.ParseMessageType(msg.Subject) != protocol.TypeMerged {
+		return
+	}
+	p := protocol.ParseMergedPayload(msg.Body)
+	if p.Rig == "" {
+		return
+	}
+	details := map[string]string{"branch": p.Branch, "commit": p.MergeCommit}
+	if p.Issue != "" {
+		details["source_issue"] = p.Issue
+	}
+	if err := refinery.RecordMerge(townRoot, p.Rig, msg.From, p.TargetBranch, details); err != nil {
+		style.PrintWarning("could not record merge in audit chain: %v", err)
+	}
+}
+
+// configChangeDetails describes a configuration change for the audit chain.
+// An empty new value means the setting was removed.
+func configChangeDetails(file, old, value string) map[string]string {
+	d := map[string]string{"file": file, "new": value}
+	if old != "" {
+		d["old"] = old
+	}
+	return d
+}
+
+// formatConfigValue renders a config value for an audit entry ("" if unset).
+func formatConfigValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/backup"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	backupOutput string
+	backupForce  bool
+)
+
+var backupCmd = &cobra.Command{
+	Use:     "backup",
+	GroupID: GroupWorkspace,
+	Short:   "Back up and restore the whole town",
+	Long: `Archive the town's state so a host failure doesn't erase the work graph.
+
+A backup holds everything in the town that is not a repo checkout:
+configs (mayor/, settings/, rig config.json), town and rig beads (and
+with them mail, merge requests, and wisps), name pools, and other runtime
+metadata. Clones and worktrees are left out, except for the gt state in
+them (.beads, .runtime, crew state and mail); their work lives in git
+remotes. Sockets, pid files, and locks are left out too.
+
+The archive is a .tar.gz ending in a manifest with the SHA-256 of every
+file. gt backup create verifies what it wrote, and gt backup restore
+verifies an archive before extracting anything.
+
+Examples:
+  gt backup create                        # ./<town>-backup-<time>.tar.gz
+  gt backup create -o /mnt/backups/gt.tar.gz
+  gt backup verify gt.tar.gz
+  gt backup restore gt.tar.gz ~/gt`,
+	RunE: requireSubcommand,
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Archive the town's state",
+	Long: `Archive the town's state to a .tar.gz.
+
+Agents can keep working during a backup: files that change while they are
+read are reread, and if beads change before the backup finishes it starts
+over, so the archived databases are consistent.`,
+	Args: cobra.NoArgs,
+	RunE: runBackupCreate,
+}
+
+var backupVerifyCmd = &cobra.Command{
+	Use:   "verify <archive>",
+	Short: "Check a backup against its manifest",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runBackupVerify,
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <archive> <dir>",
+	Short: "Restore a town from a backup",
+	Long: `Verify a backup and extract it into dir, recreating the town there.
+
+Files already in dir are not overwritten unless --force is given. Repo
+clones and worktrees are not in backups: after restoring, clone each rig's
+repo again (see mayor/rigs.json for their URLs) and start the town.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runBackupRestore,
+}
+
+func init() {
+	backupCreateCmd.Flags().StringVarP(&backupOutput, "output", "o", "", "Archive path (default: ./<town>-backup-<time>.tar.gz)")
+	backupRestoreCmd.Flags().BoolVar(&backupForce, "force", false, "Overwrite files already in the destination")
+
+	backupCmd.AddCommand(backupCreateCmd)
+	backupCmd.AddCommand(backupVerifyCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+	rootCmd.AddCommand(backupCmd)
+}
+
+func runBackupCreate(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	town, err := workspace.GetTownName(townRoot)
+	if err != nil {
+		town = filepath.Base(townRoot)
+	}
+
+	output := backupOutput
+	if output == "" {
+		output = fmt.Sprintf("%s-backup-%s.tar.gz", town, time.Now().Format("20060102-150405"))
+	}
+	if _, err := os.Stat(output); err == nil {
+		return fmt.Errorf("%s already exists", output)
+	}
+
+	m, err := backup.CreateFile(townRoot, output, backup.Options{Town: town})
+	if errors.Is(err, backup.ErrChanged) {
+		return fmt.Errorf("%w; beads kept changing, try again when the town is quieter", err)
+	}
+	if err != nil {
+		return fmt.Errorf("creating backup: %w", err)
+	}
+	fmt.Printf("%s Backed up %s to %s\n", style.Bold.Render("✓"), town, output)
+	fmt.Printf("  %s\n", backupSummary(m))
+	return nil
+}
+
+func runBackupVerify(cmd *cobra.Command, args []string) error {
+	m, err := backup.VerifyFile(args[0])
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	fmt.Printf("%s %s is intact\n", style.Bold.Render("✓"), args[0])
+	fmt.Printf("  %s\n", backupSummary(m))
+	return nil
+}
+
+func runBackupRestore(cmd *cobra.Command, args []string) error {
+	archive, dir := args[0], args[1]
+	m, err := backup.Restore(archive, dir, backupForce)
+	if err != nil {
+		return fmt.Errorf("restoring %s: %w", archive, err)
+	}
+	fmt.Printf("%s Restored %s to %s\n", style.Bold.Render("✓"), m.Town, dir)
+	fmt.Printf("  %s\n", backupSummary(m))
+	fmt.Println()
+	fmt.Println("Repo clones and worktrees are not in backups. Next:")
+	fmt.Println("  1. Clone each rig's repo again (URLs are in mayor/rigs.json)")
+	fmt.Printf("  2. cd %s && gt doctor\n", dir)
+	return nil
+}
+
+// backupSummary describes a backup in one line.
+func backupSummary(m *backup.Manifest) string {
+	files := 0
+	for _, f := range m.Files {
+		if f.Mode.IsRegular() {
+			files++
+		}
+	}
+	from := ""
+	if m.Host != "" {
+		from = " on " + m.Host
+	}
+	return style.Dim.Render(fmt.Sprintf("%d files, %.1f MB, taken %s%s",
+		files, float64(m.Size())/(1<<20), m.Created.Local().Format("2006-01-02 15:04"), from))
+}
@@ -3,12 +3,20 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/approval"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/callback"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/github"
 	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
+	"github.com/steveyegge/gastown/internal/schedule"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/townlog"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -34,13 +42,31 @@ var (
 	// SLING_REQUEST: <bead-id> - request to sling work
 	patternSling = regexp.MustCompile(`^SLING_REQUEST:\s+(\S+)`)
 
+	// WORK_CLAIMED <polecat> - polecat started work on an issue
+	patternWorkClaimed = regexp.MustCompile(`^WORK_CLAIMED\s+(\S+)`)
+
+	// TEST_RESULTS <polecat> - refinery test run summary
+	patternTestResults = regexp.MustCompile(`^TEST_RESULTS\s+(\S+)`)
+
+	// BUDGET_ALERT <scope> - spending crossed a budget threshold
+	patternBudgetAlert = regexp.MustCompile(`^BUDGET_ALERT\s+(\S+)`)
+
+	// GH_PR_OPENED <owner/name>#<n> - pull request opened (webhook bridge)
+	patternGitHubPR = regexp.MustCompile(`^GH_PR_OPENED\s+(\S+)`)
+
+	// GH_REVIEW_REQUESTED <owner/name>#<n> - review requested (webhook bridge)
+	patternGitHubReview = regexp.MustCompile(`^GH_REVIEW_REQUESTED\s+(\S+)`)
+
+	// GH_CI_FAILED <owner/name>@<branch> - CI failed on the default branch
+	patternGitHubCI = regexp.MustCompile(`^GH_CI_FAILED\s+(\S+)`)
+
 	// NOTE: WITNESS_REPORT and REFINERY_REPORT removed.
 	// Witnesses and Refineries handle their duties autonomously.
 	// They only escalate genuine problems, not routine status updates.
 )
 
 // CallbackType identifies the type of callback message.
-type CallbackType string
+type CallbackType = callback.Type
 
 const (
 	CallbackPolecatDone    CallbackType = "polecat_done"
@@ -49,11 +75,50 @@ const (
 	CallbackHelp           CallbackType = "help"
 	CallbackEscalation     CallbackType = "escalation"
 	CallbackSling          CallbackType = "sling"
-	CallbackUnknown        CallbackType = "unknown"
+	CallbackWorkClaimed    CallbackType = "work_claimed"
+	CallbackTestResults    CallbackType = "test_results"
+	CallbackBudgetAlert    CallbackType = "budget_alert"
+	CallbackGitHubPR       CallbackType = "github_pr"
+	CallbackGitHubReview   CallbackType = "github_review"
+	CallbackGitHubCI       CallbackType = "github_ci"
+	CallbackScheduled      CallbackType = "scheduled"
+	CallbackUnknown                     = callback.Unknown
 	// NOTE: CallbackWitnessReport and CallbackRefineryReport removed.
 	// Routine status reports are no longer sent to Mayor.
 )
 
+// builtinCallbacks are the handlers gt callbacks process always has, in the
+// order they are tried. Scheduled callbacks carry their trigger's subject,
+// so they are known by their sender and come first.
+var builtinCallbacks = []callback.Handler{
+	{Type: CallbackScheduled, Match: func(msg *mail.Message) bool { return msg.From == schedule.Sender },
+		Subject: "(from scheduler)", Summary: "Forward the ritual to the agent its trigger routes it to", Handle: handleScheduled},
+	{Type: CallbackPolecatDone, Pattern: patternPolecatDone, Subject: "POLECAT_DONE",
+		Summary: "Log completion, update stats", Handle: handlePolecatDone},
+	{Type: CallbackMergeRejected, Pattern: patternMergeRejected, Subject: "Merge Request Rejected:",
+		Summary: "Notify worker of rejection reason", Handle: handleMergeRejected},
+	{Type: CallbackMergeCompleted, Pattern: patternMergeCompleted, Subject: "Merge Request Completed:",
+		Summary: "Notify worker, close source issue", Handle: handleMergeCompleted},
+	{Type: CallbackHelp, Pattern: patternHelp, Subject: "HELP:",
+		Summary: "Route to human or handle if possible", Handle: handleHelp},
+	{Type: CallbackEscalation, Pattern: patternEscalation, Subject: "ESCALATION:",
+		Summary: "Log and route to human", Handle: handleEscalation},
+	{Type: CallbackSling, Pattern: patternSling, Subject: "SLING_REQUEST:",
+		Summary: "Spawn polecat for the work", Handle: handleSling},
+	{Type: CallbackWorkClaimed, Pattern: patternWorkClaimed, Subject: "WORK_CLAIMED",
+		Summary: "Log that a polecat started on an issue", Handle: handleWorkClaimed},
+	{Type: CallbackTestResults, Pattern: patternTestResults, Subject: "TEST_RESULTS",
+		Summary: "Log test results; forward failures to overseer", Handle: handleTestResults},
+	{Type: CallbackBudgetAlert, Pattern: patternBudgetAlert, Subject: "BUDGET_ALERT",
+		Summary: "Log; forward to overseer once a budget is exceeded", Handle: handleBudgetAlert},
+	{Type: CallbackGitHubPR, Pattern: patternGitHubPR, Subject: "GH_PR_OPENED",
+		Summary: "Log a pull request opened on a rig's repo", Handle: handleGitHubPR},
+	{Type: CallbackGitHubReview, Pattern: patternGitHubReview, Subject: "GH_REVIEW_REQUESTED",
+		Summary: "Forward the review request to overseer", Handle: handleGitHubReview},
+	{Type: CallbackGitHubCI, Pattern: patternGitHubCI, Subject: "GH_CI_FAILED",
+		Summary: "Alert the rig's refinery that its target branch is red", Handle: handleGitHubCI},
+}
+
 // CallbackResult tracks the result of processing a callback.
 type CallbackResult struct {
 	MessageID    string
@@ -75,7 +140,9 @@ Callbacks are messages sent to the Mayor from:
 - Witnesses reporting polecat status
 - Refineries reporting merge results
 - Polecats requesting help or escalation
-- External triggers (webhooks, timers)
+- External triggers (webhooks, timers), including GitHub webhooks
+  bridged by the daemon (see gt webhooks) and scheduled rituals
+  (see gt schedules)
 
 This command processes the Mayor's inbox and handles each message
 appropriately, routing to other agents or updating state as needed.`,
@@ -95,6 +162,19 @@ its type:
   HELP:              - Route to human or handle if possible
   ESCALATION:        - Log and route to human
   SLING_REQUEST:     - Spawn polecat for the work
+  WORK_CLAIMED       - Log that a polecat started on an issue
+  TEST_RESULTS       - Log test results; forward failures to overseer
+  BUDGET_ALERT       - Log; forward to overseer once a budget is exceeded
+  GH_PR_OPENED       - Log a pull request opened on a rig's repo
+  GH_REVIEW_REQUESTED - Forward the review request to overseer
+  GH_CI_FAILED       - Alert the rig's refinery that its target branch is red
+
+Callbacks from the daemon's scheduler (see gt schedules) carry their
+trigger's subject, e.g. TRIAGE_REMINDER, and are forwarded to the agent
+the trigger routes them to.
+
+Towns can handle further subjects with plugin executables; see
+gt callbacks list.
 
 Note: Witnesses and Refineries handle routine operations autonomously.
 They only send escalations for genuine problems, not status reports.
@@ -108,14 +188,76 @@ var (
 	callbacksVerbose bool
 )
 
+var callbacksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the registered callback handlers",
+	Long: `List the handlers gt callbacks process routes callbacks to, in the order
+they are tried: the built-in handlers, then the town's plugin handlers.
+
+Plugin handlers run external executables for new callback subjects. They
+are configured in settings/callbacks.json:
+
+  {
+    "handlers": [
+      {"type": "deploy_done", "subject": "^DEPLOY_DONE\\s+(\\S+)",
+       "from": "^ci$", "command": "scripts/deploy-done.sh",
+       "summary": "Record the deploy and notify the rig",
+       "dry_run": "would record the deploy", "timeout": "30s"}
+    ]
+  }
+
+The executable runs in the town root with the message as JSON on stdin,
+and GT_TOWN_ROOT, GT_CALLBACK_TYPE, GT_CALLBACK_MATCH (the subject
+pattern's first group), GT_MESSAGE_ID, GT_MESSAGE_FROM, and
+GT_MESSAGE_SUBJECT set. The last line it prints is reported as the action
+taken; a non-zero exit leaves the callback in the inbox. Dry runs report
+dry_run without running it.`,
+	RunE: runCallbacksList,
+}
+
 func init() {
 	callbacksProcessCmd.Flags().BoolVar(&callbacksDryRun, "dry-run", false, "Show what would be processed without taking action")
 	callbacksProcessCmd.Flags().BoolVarP(&callbacksVerbose, "verbose", "v", false, "Show detailed processing info")
 
+	for _, h := range builtinCallbacks {
+		h.Source = "builtin"
+		callback.MustRegister(h)
+	}
+
 	callbacksCmd.AddCommand(callbacksProcessCmd)
+	callbacksCmd.AddCommand(callbacksListCmd)
 	rootCmd.AddCommand(callbacksCmd)
 }
 
+// callbackRegistry returns the registered handlers plus the town's plugin
+// handlers. A broken plugin config is reported and its handlers skipped,
+// leaving their callbacks in the inbox.
+func callbackRegistry(townRoot string) *callback.Registry {
+	reg := callback.Default().Clone()
+	if err := callback.RegisterPlugins(reg, townRoot); err != nil {
+		style.PrintWarning("callback plugins not loaded: %v", err)
+		return callback.Default()
+	}
+	return reg
+}
+
+func runCallbacksList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	fmt.Printf("%s Callback handlers\n\n", style.Bold.Render("●"))
+	for _, h := range callbackRegistry(townRoot).Handlers() {
+		source := ""
+		if h.Source != "builtin" {
+			source = " " + style.Dim.Render("("+h.Source+")")
+		}
+		fmt.Printf("  %-25s %s %s%s\n", h.Subject, style.Dim.Render(fmt.Sprintf("%-16s", h.Type)), h.Summary, source)
+	}
+	return nil
+}
+
 func runCallbacksProcess(cmd *cobra.Command, args []string) error {
 	townRoot, err := workspace.FindFromCwdOrError()
 	if err != nil {
@@ -142,9 +284,10 @@ func runCallbacksProcess(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("%s Processing %d callback(s)\n", style.Bold.Render("●"), len(messages))
 
+	reg := callbackRegistry(townRoot)
 	var results []CallbackResult
 	for _, msg := range messages {
-		result := processCallback(townRoot, msg, callbacksDryRun)
+		result := processCallbackWith(reg, townRoot, msg, callbacksDryRun)
 		results = append(results, result)
 
 		// Print result
@@ -199,44 +342,28 @@ func runCallbacksProcess(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// processCallback handles a single callback message and returns the result.
+// processCallback handles a single callback message with the registered
+// handlers and returns the result.
 func processCallback(townRoot string, msg *mail.Message, dryRun bool) CallbackResult {
+	return processCallbackWith(callback.Default(), townRoot, msg, dryRun)
+}
+
+// processCallbackWith handles a single callback message with the handlers
+// in reg and returns the result.
+func processCallbackWith(reg *callback.Registry, townRoot string, msg *mail.Message, dryRun bool) CallbackResult {
 	result := CallbackResult{
 		MessageID: msg.ID,
 		From:      msg.From,
 		Subject:   msg.Subject,
 	}
 
-	// Classify the callback
-	result.CallbackType = classifyCallback(msg.Subject)
-
-	// Handle based on type
-	switch result.CallbackType {
-	case CallbackPolecatDone:
-		result.Action, result.Error = handlePolecatDone(townRoot, msg, dryRun)
-		result.Handled = result.Error == nil
-
-	case CallbackMergeCompleted:
-		result.Action, result.Error = handleMergeCompleted(townRoot, msg, dryRun)
+	// Route the callback to the first handler that matches it
+	if h := reg.Lookup(msg); h != nil {
+		result.CallbackType = h.Type
+		result.Action, result.Error = h.Run(townRoot, msg, dryRun)
 		result.Handled = result.Error == nil
-
-	case CallbackMergeRejected:
-		result.Action, result.Error = handleMergeRejected(townRoot, msg, dryRun)
-		result.Handled = result.Error == nil
-
-	case CallbackHelp:
-		result.Action, result.Error = handleHelp(townRoot, msg, dryRun)
-		result.Handled = result.Error == nil
-
-	case CallbackEscalation:
-		result.Action, result.Error = handleEscalation(townRoot, msg, dryRun)
-		result.Handled = result.Error == nil
-
-	case CallbackSling:
-		result.Action, result.Error = handleSling(townRoot, msg, dryRun)
-		result.Handled = result.Error == nil
-
-	default:
+	} else {
+		result.CallbackType = CallbackUnknown
 		result.Action = "unknown message type, skipped"
 		result.Handled = false
 	}
@@ -254,22 +381,7 @@ func processCallback(townRoot string, msg *mail.Message, dryRun bool) CallbackRe
 
 // classifyCallback determines the type of callback from the subject line.
 func classifyCallback(subject string) CallbackType {
-	switch {
-	case patternPolecatDone.MatchString(subject):
-		return CallbackPolecatDone
-	case patternMergeRejected.MatchString(subject):
-		return CallbackMergeRejected
-	case patternMergeCompleted.MatchString(subject):
-		return CallbackMergeCompleted
-	case patternHelp.MatchString(subject):
-		return CallbackHelp
-	case patternEscalation.MatchString(subject):
-		return CallbackEscalation
-	case patternSling.MatchString(subject):
-		return CallbackSling
-	default:
-		return CallbackUnknown
-	}
+	return callback.Default().Classify(subject)
 }
 
 // handlePolecatDone processes a POLECAT_DONE callback.
@@ -479,6 +591,273 @@ func handleSling(townRoot string, msg *mail.Message, dryRun bool) (string, error
 		beadID, targetRig, beadID, targetRig), nil
 }
 
+// handleWorkClaimed processes a WORK_CLAIMED notice from a polecat.
+func handleWorkClaimed(townRoot string, msg *mail.Message, dryRun bool) (string, error) { //nolint:unparam // error return kept for consistency with callback interface
+	payload := protocol.ParseWorkClaimedPayload(msg.Body)
+	if payload.Polecat == "" {
+		if matches := patternWorkClaimed.FindStringSubmatch(msg.Subject); len(matches) > 1 {
+			payload.Polecat = matches[1]
+		}
+	}
+	worker := payload.Polecat
+	if payload.Rig != "" {
+		worker = payload.Rig + "/" + payload.Polecat
+	}
+
+	if dryRun {
+		return fmt.Sprintf("would log claim of %s by %s", payload.Issue, worker), nil
+	}
+
+	logCallback(townRoot, fmt.Sprintf("work_claimed: %s claimed %s (branch: %s)",
+		worker, payload.Issue, payload.Branch))
+
+	return fmt.Sprintf("logged claim of %s by %s", payload.Issue, worker), nil
+}
+
+// handleTestResults processes a TEST_RESULTS summary from a Refinery.
+// Passing runs are logged; failing runs are also forwarded to the overseer.
+func handleTestResults(townRoot string, msg *mail.Message, dryRun bool) (string, error) {
+	payload := protocol.ParseTestResultsPayload(msg.Body)
+	summary := fmt.Sprintf("%d passed, %d failed, %d skipped", payload.Passed, payload.Failed, payload.Skipped)
+	if payload.Success {
+		summary = "pass: " + summary
+	} else {
+		summary = "FAIL: " + summary
+	}
+
+	if dryRun {
+		if payload.Success {
+			return fmt.Sprintf("would log test results for %s (%s)", payload.Branch, summary), nil
+		}
+		return fmt.Sprintf("would forward failing test results for %s to overseer (%s)", payload.Branch, summary), nil
+	}
+
+	logCallback(townRoot, fmt.Sprintf("test_results: %s (issue: %s) %s in %s",
+		payload.Branch, payload.Issue, summary, payload.Duration))
+
+	if payload.Success {
+		return fmt.Sprintf("logged test results for %s (%s)", payload.Branch, summary), nil
+	}
+
+	router := mail.NewRouter(townRoot)
+	fwd := &mail.Message{
+		From:     "mayor/",
+		To:       "overseer",
+		Subject:  fmt.Sprintf("[FWD] Tests failing: %s", payload.Branch),
+		Body:     fmt.Sprintf("Forwarded from: %s\n\n%s", msg.From, msg.Body),
+		Priority: mail.PriorityHigh,
+	}
+	if err := router.Send(fwd); err != nil {
+		return "", fmt.Errorf("forwarding test results: %w", err)
+	}
+
+	return fmt.Sprintf("forwarded failing test results for %s to overseer (%s)", payload.Branch, summary), nil
+}
+
+// handleBudgetAlert processes a BUDGET_ALERT. Warnings are logged; an
+// exceeded budget is also forwarded to the overseer with urgent priority,
+// with an approval bead for overriding it.
+func handleBudgetAlert(townRoot string, msg *mail.Message, dryRun bool) (string, error) {
+	payload := protocol.ParseBudgetAlertPayload(msg.Body)
+	if payload.Scope == "" {
+		if matches := patternBudgetAlert.FindStringSubmatch(msg.Subject); len(matches) > 1 {
+			payload.Scope = matches[1]
+		}
+	}
+	summary := fmt.Sprintf("%s %s budget at %d%% ($%.2f of $%.2f)",
+		payload.Scope, payload.Period, payload.Threshold, payload.Spent, payload.Limit)
+
+	if dryRun {
+		if payload.Exceeded() {
+			return fmt.Sprintf("would forward budget alert to overseer: %s", summary), nil
+		}
+		return fmt.Sprintf("would log budget alert: %s", summary), nil
+	}
+
+	logCallback(townRoot, fmt.Sprintf("budget_alert: from %s: %s", msg.From, summary))
+
+	if !payload.Exceeded() {
+		return fmt.Sprintf("logged budget alert: %s", summary), nil
+	}
+
+	body := fmt.Sprintf("Reported by: %s\n\n%s", msg.From, msg.Body)
+	// File an approval so the override can be granted in one command; the
+	// alert is forwarded even if that fails.
+	override, _, err := fileApproval(beads.New(beads.ResolveBeadsDir(townRoot)), &approval.Fields{
+		Kind:        approval.KindBudget,
+		Subject:     payload.Scope + "/" + payload.Period,
+		RequestedBy: msg.From,
+		Notify:      msg.From,
+	}, fmt.Sprintf("override %s %s budget", payload.Scope, payload.Period), summary)
+	if err == nil {
+		body += fmt.Sprintf("\n\nOverride:  gt approve %s\nStop work: gt deny %s -m \"<why>\"\n", override.ID, override.ID)
+	}
+
+	router := mail.NewRouter(townRoot)
+	fwd := &mail.Message{
+		From:     "mayor/",
+		To:       "overseer",
+		Subject:  fmt.Sprintf("[BUDGET] %s %s budget exceeded", payload.Scope, payload.Period),
+		Body:     body,
+		Priority: mail.PriorityUrgent,
+	}
+	if err := router.Send(fwd); err != nil {
+		return "", fmt.Errorf("forwarding budget alert: %w", err)
+	}
+
+	return fmt.Sprintf("forwarded budget alert to overseer: %s", summary), nil
+}
+
+// callbackField returns the value of a "Key: value" line in a callback body.
+func callbackField(body, key string) string {
+	prefix := key + ":"
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimSpace(strings.TrimPrefix(line, prefix))
+		}
+	}
+	return ""
+}
+
+// rigForRepo returns the rig whose GitHub repository is repo ("owner/name"),
+// from its github settings or its git URL, or "" if there is none.
+func rigForRepo(townRoot, repo string) string {
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil || repo == "" {
+		return ""
+	}
+	names := make([]string, 0, len(rigsConfig.Rigs))
+	for name := range rigsConfig.Rigs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		settings, err := config.LoadRigSettings(config.RigSettingsPath(filepath.Join(townRoot, name)))
+		if err == nil && settings.GitHub != nil && settings.GitHub.Repo != "" {
+			if strings.EqualFold(settings.GitHub.Repo, repo) {
+				return name
+			}
+			continue
+		}
+		if r, ok := github.RepoFromURL(rigsConfig.Rigs[name].GitURL); ok && strings.EqualFold(r, repo) {
+			return name
+		}
+	}
+	return ""
+}
+
+// handleGitHubPR processes a GH_PR_OPENED callback from the webhook bridge.
+func handleGitHubPR(townRoot string, msg *mail.Message, dryRun bool) (string, error) { //nolint:unparam // error return kept for consistency with callback interface
+	repo := callbackField(msg.Body, "Repo")
+	pr := fmt.Sprintf("%s#%s", repo, callbackField(msg.Body, "PR"))
+	rigName := rigForRepo(townRoot, repo)
+	if rigName == "" {
+		rigName = "no rig"
+	}
+
+	if dryRun {
+		return fmt.Sprintf("would log PR %s (%s)", pr, rigName), nil
+	}
+
+	logCallback(townRoot, fmt.Sprintf("github_pr: %s opened by %s (%s): %s",
+		pr, callbackField(msg.Body, "Author"), rigName, callbackField(msg.Body, "Title")))
+
+	return fmt.Sprintf("logged PR %s (%s)", pr, rigName), nil
+}
+
+// handleGitHubReview processes a GH_REVIEW_REQUESTED callback: reviews are
+// for humans, so the request goes to the overseer.
+func handleGitHubReview(townRoot string, msg *mail.Message, dryRun bool) (string, error) {
+	pr := fmt.Sprintf("%s#%s", callbackField(msg.Body, "Repo"), callbackField(msg.Body, "PR"))
+	reviewer := callbackField(msg.Body, "Reviewer")
+
+	if dryRun {
+		return fmt.Sprintf("would forward review request for %s (%s) to overseer", pr, reviewer), nil
+	}
+
+	router := mail.NewRouter(townRoot)
+	fwd := &mail.Message{
+		From:     "mayor/",
+		To:       "overseer",
+		Subject:  fmt.Sprintf("[FWD] Review requested: %s %s", pr, callbackField(msg.Body, "Title")),
+		Body:     fmt.Sprintf("Forwarded from: %s\n\n%s", msg.From, msg.Body),
+		Priority: mail.PriorityHigh,
+	}
+	if err := router.Send(fwd); err != nil {
+		return "", fmt.Errorf("forwarding review request: %w", err)
+	}
+
+	logCallback(townRoot, fmt.Sprintf("github_review: %s requested from %s", pr, reviewer))
+
+	return fmt.Sprintf("forwarded review request for %s (%s) to overseer", pr, reviewer), nil
+}
+
+// handleGitHubCI processes a GH_CI_FAILED callback: the rig's refinery is
+// merging onto a red branch, so it is told; without a rig, the overseer is.
+func handleGitHubCI(townRoot string, msg *mail.Message, dryRun bool) (string, error) {
+	repo := callbackField(msg.Body, "Repo")
+	branch := callbackField(msg.Body, "Branch")
+	to := "overseer"
+	if rigName := rigForRepo(townRoot, repo); rigName != "" {
+		to = rigName + "/refinery"
+	}
+
+	if dryRun {
+		return fmt.Sprintf("would alert %s: CI failing on %s@%s", to, repo, branch), nil
+	}
+
+	router := mail.NewRouter(townRoot)
+	fwd := &mail.Message{
+		From:     "mayor/",
+		To:       to,
+		Subject:  fmt.Sprintf("CI failing on %s", branch),
+		Body:     fmt.Sprintf("CI failed on %s of %s; merges land on a red branch until it is fixed.\n\n%s", branch, repo, msg.Body),
+		Priority: mail.PriorityUrgent,
+	}
+	if err := router.Send(fwd); err != nil {
+		return "", fmt.Errorf("alerting %s: %w", to, err)
+	}
+
+	logCallback(townRoot, fmt.Sprintf("github_ci: %s@%s failed (%s), alerted %s",
+		repo, branch, callbackField(msg.Body, "Workflow"), to))
+
+	return fmt.Sprintf("alerted %s: CI failing on %s@%s", to, repo, branch), nil
+}
+
+// handleScheduled processes a callback from the daemon's scheduler by
+// forwarding it to the agent its trigger routes it to. Forwarded to the
+// Mayor, it is no longer from the scheduler and stays in the inbox for the
+// Mayor agent.
+func handleScheduled(townRoot string, msg *mail.Message, dryRun bool) (string, error) {
+	to := schedule.CallbackRoute(msg.Body)
+	trigger := callbackField(msg.Body, "Schedule")
+
+	if dryRun {
+		return fmt.Sprintf("would forward %s to %s", msg.Subject, to), nil
+	}
+
+	priority := msg.Priority
+	if priority == "" {
+		priority = mail.PriorityNormal
+	}
+	router := mail.NewRouter(townRoot)
+	fwd := &mail.Message{
+		From:     "mayor/",
+		To:       to,
+		Subject:  msg.Subject,
+		Body:     msg.Body,
+		Priority: priority,
+	}
+	if err := router.Send(fwd); err != nil {
+		return "", fmt.Errorf("forwarding %s to %s: %w", msg.Subject, to, err)
+	}
+
+	logCallback(townRoot, fmt.Sprintf("scheduled: %s from %s forwarded to %s", msg.Subject, trigger, to))
+
+	return fmt.Sprintf("forwarded %s to %s", msg.Subject, to), nil
+}
+
 // logCallback logs a callback processing event to the town log.
 func logCallback(townRoot, context string) {
 	logger := townlog.NewLogger(townRoot)
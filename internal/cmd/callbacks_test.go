@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
+	"github.com/steveyegge/gastown/internal/schedule"
+)
+
+func TestClassifyCallback(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    CallbackType
+	}{
+		{"POLECAT_DONE nux", CallbackPolecatDone},
+		{"Merge Request Rejected: polecat/nux", CallbackMergeRejected},
+		{"HELP: stuck on tests", CallbackHelp},
+		{"SLING_REQUEST: gt-abc", CallbackSling},
+		{"WORK_CLAIMED nux", CallbackWorkClaimed},
+		{"TEST_RESULTS nux", CallbackTestResults},
+		{"BUDGET_ALERT gastown", CallbackBudgetAlert},
+		{"BUDGET_ALERT", CallbackUnknown},
+		{"GH_PR_OPENED acme/widgets#12", CallbackGitHubPR},
+		{"GH_REVIEW_REQUESTED acme/widgets#12", CallbackGitHubReview},
+		{"GH_CI_FAILED acme/widgets@main", CallbackGitHubCI},
+		{"hello", CallbackUnknown},
+	}
+	for _, tt := range tests {
+		if got := classifyCallback(tt.subject); got != tt.want {
+			t.Errorf("classifyCallback(%q) = %q, want %q", tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestProtocolCallbacksDryRun(t *testing.T) {
+	claimed := protocol.NewWorkClaimedMessage("gastown", "nux", "gt-abc", "")
+	if res := processCallback("", claimed, true); !res.Handled || !strings.Contains(res.Action, "gt-abc by gastown/nux") {
+		t.Errorf("work claimed = %+v", res)
+	}
+
+	failing := protocol.NewTestResultsMessage(protocol.TestResultsPayload{Rig: "gastown", Polecat: "nux", Branch: "polecat/nux", Failed: 3})
+	if res := processCallback("", failing, true); !res.Handled || !strings.Contains(res.Action, "forward failing") {
+		t.Errorf("failing tests = %+v", res)
+	}
+	passing := protocol.NewTestResultsMessage(protocol.TestResultsPayload{Rig: "gastown", Polecat: "nux", Branch: "polecat/nux", Passed: 3, Success: true})
+	if res := processCallback("", passing, true); !strings.Contains(res.Action, "would log") {
+		t.Errorf("passing tests = %+v", res)
+	}
+
+	warn := protocol.NewBudgetAlertMessage("deacon/", "gastown", "daily", 80, 100, 80)
+	if res := processCallback("", warn, true); !strings.Contains(res.Action, "would log budget alert: gastown daily budget at 80%") {
+		t.Errorf("budget warning = %+v", res)
+	}
+	over := protocol.NewBudgetAlertMessage("deacon/", "town", "daily", 120, 100, 100)
+	if res := processCallback("", over, true); !strings.Contains(res.Action, "would forward") {
+		t.Errorf("budget exceeded = %+v", res)
+	}
+}
+
+func TestGitHubCallbacksRouteToRig(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	rigs := `{"version": 1, "rigs": {"gastown": {"git_url": "git@github.com:acme/widgets.git"}}}`
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "rigs.json"), []byte(rigs), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := rigForRepo(townRoot, "acme/widgets"); got != "gastown" {
+		t.Errorf("rigForRepo = %q", got)
+	}
+
+	ci := &mail.Message{From: "github", Subject: "GH_CI_FAILED acme/widgets@main",
+		Body: "Repo: acme/widgets\nBranch: main\nCommit: abc\nWorkflow: CI"}
+	if res := processCallback(townRoot, ci, true); !res.Handled || res.Action != "would alert gastown/refinery: CI failing on acme/widgets@main" {
+		t.Errorf("ci = %+v", res)
+	}
+	ci.Body = strings.Replace(ci.Body, "acme/widgets", "acme/other", 1)
+	if res := processCallback(townRoot, ci, true); !strings.Contains(res.Action, "would alert overseer") {
+		t.Errorf("unknown repo = %+v", res)
+	}
+
+	pr := &mail.Message{From: "github", Subject: "GH_PR_OPENED acme/widgets#12", Body: "Repo: acme/widgets\nPR: 12\nAuthor: octo"}
+	if res := processCallback(townRoot, pr, true); res.Action != "would log PR acme/widgets#12 (gastown)" {
+		t.Errorf("pr = %+v", res)
+	}
+}
+
+func TestScheduledCallbacksDryRun(t *testing.T) {
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	triage := schedule.NewCallback(schedule.Trigger{Name: "triage", Schedule: "weekday 09:00", Subject: "TRIAGE_REMINDER"}, now)
+	res := processCallback("", triage, true)
+	if res.CallbackType != CallbackScheduled || !res.Handled || res.Action != "would forward TRIAGE_REMINDER to mayor/" {
+		t.Errorf("triage = %+v", res)
+	}
+
+	// Scheduled subjects are not classified by pattern
+	help := schedule.NewCallback(schedule.Trigger{Name: "h", Schedule: "@daily", Subject: "HELP: standup", Route: "overseer"}, now)
+	if res := processCallback("", help, true); res.CallbackType != CallbackScheduled || res.Action != "would forward HELP: standup to overseer" {
+		t.Errorf("help = %+v", res)
+	}
+
+	// Forwarded back to the Mayor, the callback is left for the Mayor agent
+	fwd := &mail.Message{From: "mayor/", Subject: "TRIAGE_REMINDER", Body: triage.Body}
+	if res := processCallback("", fwd, true); res.CallbackType != CallbackUnknown || res.Handled {
+		t.Errorf("forwarded = %+v", res)
+	}
+}
+
+func TestCallbackPluginsDryRun(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	plugins := `{"handlers": [{"type": "deploy_done", "subject": "^DEPLOY_DONE", "command": "scripts/deploy.sh", "dry_run": "would record the deploy"}]}`
+	if err := os.WriteFile(filepath.Join(townRoot, "settings", "callbacks.json"), []byte(plugins), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &mail.Message{From: "ci", Subject: "DEPLOY_DONE v1.2"}
+	res := processCallbackWith(callbackRegistry(townRoot), townRoot, msg, true)
+	if res.CallbackType != "deploy_done" || !res.Handled || res.Action != "would record the deploy" {
+		t.Errorf("plugin = %+v", res)
+	}
+
+	// Plugins extend a copy of the registry, not the built-ins
+	if res := processCallback(townRoot, msg, true); res.CallbackType != CallbackUnknown {
+		t.Errorf("without plugins = %+v", res)
+	}
+}
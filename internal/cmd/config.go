@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/auditchain"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -338,6 +339,10 @@ func runConfigAgentSet(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create or update the agent
+	old := ""
+	if prev := townSettings.Agents[name]; prev != nil {
+		old = strings.Join(append([]string{prev.Command}, prev.Args...), " ")
+	}
 	townSettings.Agents[name] = &config.RuntimeConfig{
 		Command: parts[0],
 		Args:    parts[1:],
@@ -347,6 +352,7 @@ func runConfigAgentSet(cmd *cobra.Command, args []string) error {
 	if err := config.SaveTownSettings(settingsPath, townSettings); err != nil {
 		return fmt.Errorf("saving town settings: %w", err)
 	}
+	recordAudit(townRoot, auditchain.ActionConfigChange, "agents."+name, configChangeDetails(settingsPath, old, commandLine))
 
 	fmt.Printf("Agent '%s' set to: %s\n", style.Bold.Render(name), commandLine)
 
@@ -391,12 +397,15 @@ func runConfigAgentRemove(cmd *cobra.Command, args []string) error {
 	}
 
 	// Remove the agent
+	prev := townSettings.Agents[name]
 	delete(townSettings.Agents, name)
 
 	// Save settings
 	if err := config.SaveTownSettings(settingsPath, townSettings); err != nil {
 		return fmt.Errorf("saving town settings: %w", err)
 	}
+	old := strings.Join(append([]string{prev.Command}, prev.Args...), " ")
+	recordAudit(townRoot, auditchain.ActionConfigChange, "agents."+name, configChangeDetails(settingsPath, old, ""))
 
 	fmt.Printf("Removed custom agent '%s'\n", style.Bold.Render(name))
 	return nil
@@ -454,12 +463,14 @@ func runConfigDefaultAgent(cmd *cobra.Command, args []string) error {
 	}
 
 	// Set default
+	old := townSettings.DefaultAgent
 	townSettings.DefaultAgent = name
 
 	// Save settings
 	if err := config.SaveTownSettings(settingsPath, townSettings); err != nil {
 		return fmt.Errorf("saving town settings: %w", err)
 	}
+	recordAudit(townRoot, auditchain.ActionConfigChange, "default_agent", configChangeDetails(settingsPath, old, name))
 
 	fmt.Printf("Default agent set to '%s'\n", style.Bold.Render(name))
 	return nil
@@ -501,12 +512,14 @@ func runConfigAgentEmailDomain(cmd *cobra.Command, args []string) error {
 	}
 
 	// Set domain
+	old := townSettings.AgentEmailDomain
 	townSettings.AgentEmailDomain = domain
 
 	// Save settings
 	if err := config.SaveTownSettings(settingsPath, townSettings); err != nil {
 		return fmt.Errorf("saving town settings: %w", err)
 	}
+	recordAudit(townRoot, auditchain.ActionConfigChange, "agent_email_domain", configChangeDetails(settingsPath, old, domain))
 
 	fmt.Printf("Agent email domain set to '%s'\n", style.Bold.Render(domain))
 	fmt.Printf("\nExample: gastown/crew/jack → gastown.crew.jack@%s\n", domain)
@@ -6,11 +6,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/auditchain"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
@@ -121,6 +123,10 @@ func runCrewRemove(cmd *cobra.Command, args []string) error {
 		if townRoot == "" {
 			townRoot = r.Path
 		}
+		if forceRemove {
+			recordAudit(townRoot, auditchain.ActionForceRemove, fmt.Sprintf("%s/crew/%s", r.Name, name),
+				map[string]string{"path": crewPath, "purge": strconv.FormatBool(crewPurge)})
+		}
 		prefix := beads.GetPrefixForRig(townRoot, r.Name)
 		agentBeadID := beads.CrewBeadIDWithPrefix(prefix, r.Name, name)
 
@@ -0,0 +1,348 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/dispatch"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/identity"
+	"github.com/steveyegge/gastown/internal/pacing"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	dispatchJSON     bool
+	dispatchRig      string
+	dispatchWait     bool
+	dispatchInterval time.Duration
+	dispatchTimeout  time.Duration
+)
+
+var dispatchCmd = &cobra.Command{
+	Use:     "dispatch",
+	GroupID: GroupWork,
+	Short:   "Pull work from a rig's ready queue",
+	Long: `Let polecats pull their own work instead of waiting to be slung it.
+
+In a rig with pull dispatch (settings/config.json: "dispatch": {"mode": "pull"}),
+a polecat with an empty hook runs gt dispatch claim. It takes the
+highest-priority ready bead nobody holds and hooks it, exactly as if the
+bead had been slung to it. Claims are atomic: two polecats polling at once
+never get the same bead.
+
+A claim lasts for the rig's claim TTL (dispatch.claim_ttl, default 30m)
+and every gt command the claimant runs renews it. If a polecat dies, its
+claim lapses and the bead goes back in the queue for the next polecat.
+gt sling still works in pull mode; slung beads are never claimed.
+
+Claims count against the rig's pacing (pacing.max_slings_per_hour) like
+slings do; over the limit, claim --wait keeps polling until a slot frees.
+
+Examples:
+  gt dispatch queue                  # What a polecat would claim, in order
+  gt dispatch claim                  # Claim the next bead, or exit if none
+  gt dispatch claim --wait           # Poll until there is work
+  gt dispatch release                # Give the claimed bead back`,
+	RunE: requireSubcommand,
+}
+
+var dispatchQueueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "List the beads polecats could claim, in claim order",
+	Args:  cobra.NoArgs,
+	RunE:  runDispatchQueue,
+}
+
+var dispatchClaimCmd = &cobra.Command{
+	Use:   "claim",
+	Short: "Claim the next ready bead and hook it",
+	Args:  cobra.NoArgs,
+	RunE:  runDispatchClaim,
+}
+
+var dispatchRenewCmd = &cobra.Command{
+	Use:   "renew",
+	Short: "Extend your claim by the claim TTL",
+	Args:  cobra.NoArgs,
+	RunE:  runDispatchRenew,
+}
+
+var dispatchReleaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Give up your claim and put the bead back in the queue",
+	Args:  cobra.NoArgs,
+	RunE:  runDispatchRelease,
+}
+
+func init() {
+	dispatchQueueCmd.Flags().BoolVar(&dispatchJSON, "json", false, "Output as JSON")
+	dispatchQueueCmd.Flags().StringVar(&dispatchRig, "rig", "", "Rig to show (default: current rig)")
+	dispatchClaimCmd.Flags().BoolVar(&dispatchWait, "wait", false, "Poll until there is a bead to claim")
+	dispatchClaimCmd.Flags().DurationVar(&dispatchInterval, "interval", 30*time.Second, "How often to poll with --wait")
+	dispatchClaimCmd.Flags().DurationVar(&dispatchTimeout, "timeout", 0, "Give up waiting after this long (0 = never)")
+
+	dispatchCmd.AddCommand(dispatchQueueCmd)
+	dispatchCmd.AddCommand(dispatchClaimCmd)
+	dispatchCmd.AddCommand(dispatchRenewCmd)
+	dispatchCmd.AddCommand(dispatchReleaseCmd)
+	rootCmd.AddCommand(dispatchCmd)
+}
+
+// loadDispatchConfig returns the rig's dispatch settings, or nil if unset.
+func loadDispatchConfig(rigPath string) *config.DispatchConfig {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil {
+		return nil
+	}
+	return settings.Dispatch
+}
+
+// rigPullsWork reports whether the rig uses pull dispatch.
+func rigPullsWork(rigPath string) bool {
+	cfg := loadDispatchConfig(rigPath)
+	return cfg != nil && cfg.Mode == config.DispatchPull
+}
+
+// newRigDispatcher returns the dispatcher for a rig, with its claim TTL.
+func newRigDispatcher(townRoot string, r *rig.Rig) (*dispatch.Dispatcher, error) {
+	var ttl time.Duration
+	if cfg := loadDispatchConfig(r.Path); cfg != nil && cfg.ClaimTTL != "" {
+		d, err := time.ParseDuration(cfg.ClaimTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dispatch.claim_ttl %q: %w", cfg.ClaimTTL, err)
+		}
+		ttl = d
+	}
+	return dispatch.New(townRoot, r.Name, beads.New(r.BeadsPath()), ttl), nil
+}
+
+// dispatchWorker returns the calling worker's identity and rig. Only
+// polecats and crew claim work.
+func dispatchWorker() (townRoot, agent string, r *rig.Rig, err error) {
+	roleInfo, err := GetRole()
+	if err != nil {
+		return "", "", nil, fmt.Errorf("detecting role: %w", err)
+	}
+	if roleInfo.Role != RolePolecat && roleInfo.Role != RoleCrew {
+		return "", "", nil, fmt.Errorf("only polecats and crew claim work (you are %s)", roleInfo.Role)
+	}
+	townRoot, r, err = getRig(roleInfo.Rig)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return townRoot, roleInfo.ActorString(), r, nil
+}
+
+// workerCapabilities returns the capabilities the rig grants a worker.
+func workerCapabilities(rigPath, agent string) []string {
+	id, err := identity.Parse(agent)
+	if err != nil {
+		return nil
+	}
+	return loadDispatchConfig(rigPath).CapabilitiesOf(id.Name)
+}
+
+// renewDispatchClaim renews the calling worker's dispatch claim, if it has
+// one, so a worker keeps its claim for as long as it keeps running gt
+// commands (best-effort).
+func renewDispatchClaim() {
+	rigName, name := os.Getenv("GT_RIG"), os.Getenv("GT_POLECAT")
+	role := identity.Polecat
+	if name == "" {
+		name, role = os.Getenv("GT_CREW"), identity.Crew
+	}
+	if rigName == "" || name == "" {
+		return
+	}
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return
+	}
+	dispatch.RenewRecorded(townRoot, identity.New(role, rigName, name).Actor())
+}
+
+// finishDispatchClaim drops the caller's claim on a bead whose work is done
+// (best-effort; most beads were slung, not claimed).
+func finishDispatchClaim(townRoot, beadID string) {
+	if beadID == "" {
+		return
+	}
+	roleInfo, err := GetRole()
+	if err != nil || (roleInfo.Role != RolePolecat && roleInfo.Role != RoleCrew) {
+		return
+	}
+	if _, err := dispatch.FinishRecorded(townRoot, roleInfo.ActorString(), beadID); err != nil {
+		style.PrintWarning("could not release claim on %s: %v", beadID, err)
+	}
+}
+
+func runDispatchQueue(cmd *cobra.Command, args []string) error {
+	var (
+		townRoot string
+		r        *rig.Rig
+		err      error
+	)
+	if dispatchRig != "" {
+		townRoot, r, err = getRig(dispatchRig)
+	} else {
+		townRoot, err = workspace.FindFromCwdOrError()
+		if err == nil {
+			_, r, err = findCurrentRig(townRoot)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	d, err := newRigDispatcher(townRoot, r)
+	if err != nil {
+		return err
+	}
+	queue, err := d.Queue()
+	if err != nil {
+		return err
+	}
+
+	if dispatchJSON {
+		if queue == nil {
+			queue = []*beads.Issue{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(queue)
+	}
+	mode := config.DispatchPush
+	if rigPullsWork(r.Path) {
+		mode = config.DispatchPull
+	}
+	fmt.Printf("%s %s\n\n", style.Bold.Render(fmt.Sprintf("Ready queue for %s (%d)", r.Name, len(queue))),
+		style.Dim.Render("dispatch: "+mode))
+	if len(queue) == 0 {
+		fmt.Println("  Nothing to claim")
+		return nil
+	}
+	for _, issue := range queue {
+		note := ""
+		if needs := dispatch.Needs(issue); len(needs) > 0 {
+			note += style.Dim.Render("  needs: " + strings.Join(needs, ", "))
+		}
+		if holder := dispatch.ClaimedBy(issue); holder != "" {
+			note += style.Dim.Render(fmt.Sprintf("  (claim by %s lapsed)", holder))
+		}
+		fmt.Printf("  P%d  %s  %s%s\n", issue.Priority, issue.ID, issue.Title, note)
+	}
+	return nil
+}
+
+func runDispatchClaim(cmd *cobra.Command, args []string) error {
+	townRoot, agent, r, err := dispatchWorker()
+	if err != nil {
+		return err
+	}
+	if !rigPullsWork(r.Path) {
+		return fmt.Errorf("rig %s uses push dispatch; set \"dispatch\": {\"mode\": \"pull\"} in its settings to let workers claim work", r.Name)
+	}
+	if current := dispatch.Current(townRoot, agent); current != nil {
+		return fmt.Errorf("you already claimed %s; finish it with gt done or give it back with gt dispatch release", current.Bead)
+	}
+	d, err := newRigDispatcher(townRoot, r)
+	if err != nil {
+		return err
+	}
+
+	caps := workerCapabilities(r.Path, agent)
+	var deadline time.Time
+	if dispatchTimeout > 0 {
+		deadline = time.Now().Add(dispatchTimeout)
+	}
+	for {
+		issue, err := claimPaced(d, r, agent, caps)
+		if err != nil && !(dispatchWait && errors.Is(err, pacing.ErrRateLimited)) {
+			return err
+		}
+		if issue != nil {
+			hookClaimedBead(townRoot, agent, r, issue)
+			fmt.Printf("%s Claimed %s: %s\n", style.Bold.Render("✓"), issue.ID, issue.Title)
+			fmt.Printf("  Work attached to hook (status=hooked)\n")
+			fmt.Println()
+			fmt.Printf("%s\n", style.Dim.Render("Run it: gt hook"))
+			return nil
+		}
+		if !dispatchWait {
+			fmt.Printf("%s Nothing to claim in %s\n", style.Dim.Render("○"), r.Name)
+			return nil
+		}
+		if !deadline.IsZero() && time.Now().Add(dispatchInterval).After(deadline) {
+			return fmt.Errorf("no work to claim in %s after %s", r.Name, dispatchTimeout)
+		}
+		time.Sleep(dispatchInterval)
+	}
+}
+
+// hookClaimedBead finishes hooking a claimed bead the way gt sling does:
+// the agent bead's hook slot, the polecat work molecule, and a feed event.
+func hookClaimedBead(townRoot, agent string, r *rig.Rig, issue *beads.Issue) {
+	cwd, _ := os.Getwd()
+	updateAgentHookBead(agent, issue.ID, cwd, "")
+	if isPolecatTarget(agent) {
+		if err := attachPolecatWorkMolecule(agent, cwd, townRoot); err != nil {
+			fmt.Printf("%s Could not attach work molecule: %v\n", style.Dim.Render("Warning:"), err)
+		}
+	}
+	_ = events.LogFeed(events.TypeClaim, agent, events.ClaimPayload(issue.ID, r.Name))
+}
+
+func runDispatchRenew(cmd *cobra.Command, args []string) error {
+	townRoot, agent, r, err := dispatchWorker()
+	if err != nil {
+		return err
+	}
+	current := dispatch.Current(townRoot, agent)
+	if current == nil {
+		return fmt.Errorf("you have no claim to renew")
+	}
+	d, err := newRigDispatcher(townRoot, r)
+	if err != nil {
+		return err
+	}
+	if err := d.Renew(current.Bead, agent); err != nil {
+		return err
+	}
+	fmt.Printf("%s Renewed claim on %s\n", style.Bold.Render("✓"), current.Bead)
+	return nil
+}
+
+func runDispatchRelease(cmd *cobra.Command, args []string) error {
+	townRoot, agent, r, err := dispatchWorker()
+	if err != nil {
+		return err
+	}
+	current := dispatch.Current(townRoot, agent)
+	if current == nil {
+		return fmt.Errorf("you have no claim to release")
+	}
+	d, err := newRigDispatcher(townRoot, r)
+	if err != nil {
+		return err
+	}
+	if err := d.Release(current.Bead, agent); err != nil {
+		return fmt.Errorf("releasing %s: %w", current.Bead, err)
+	}
+	if agentBeadID := agentIDToBeadID(agent, townRoot); agentBeadID != "" {
+		if err := beads.New(r.BeadsPath()).ClearHookBead(agentBeadID); err != nil {
+			style.PrintWarning("could not clear hook on %s: %v", agentBeadID, err)
+		}
+	}
+	_ = events.LogFeed(events.TypeUnhook, agent, events.UnhookPayload(current.Bead))
+	fmt.Printf("%s Released %s back to the ready queue\n", style.Bold.Render("✓"), current.Bead)
+	return nil
+}
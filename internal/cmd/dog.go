@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/auditchain"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/dog"
@@ -322,6 +323,10 @@ func runDogRemove(cmd *cobra.Command, args []string) error {
 		}
 
 		fmt.Printf("✓ Removed dog %s\n", name)
+		if d.State == dog.StateWorking && townRoot != "" {
+			recordAudit(townRoot, auditchain.ActionForceRemove, "deacon/dogs/"+name,
+				map[string]string{"command": "dog remove", "state": string(d.State)})
+		}
 
 		// Delete agent bead for the dog
 		if b != nil {
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/keepalive"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/rig"
@@ -26,10 +28,13 @@ var doneCmd = &cobra.Command{
 	Long: `Signal that your work is complete and ready for the merge queue.
 
 This is a convenience command for polecats that:
-1. Submits the current branch to the merge queue
-2. Auto-detects issue ID from branch name
-3. Notifies the Witness with the exit outcome
-4. Exits the Claude session (polecats don't stay alive after completion)
+1. Runs the rig's pre-flight check (merge_queue.preflight_command), if any
+2. Pushes the branch and verifies origin has every commit
+3. Submits the branch to the merge queue, filling MR fields from the
+   source bead (title, convoy), and prints the queue position
+4. Auto-detects issue ID from branch name
+5. Notifies the Witness with the exit outcome
+6. Exits the Claude session (polecats don't stay alive after completion)
 
 Exit statuses:
   COMPLETED      - Work done, MR submitted (default)
@@ -37,6 +42,14 @@ Exit statuses:
   DEFERRED       - Work paused, issue still open
   PHASE_COMPLETE - Phase done, awaiting gate (use --phase-complete)
 
+Partial submission:
+  Long-running work can land incrementally. With --partial, gt done submits
+  only some of the branch's commits as an MR (all commits not yet landed, or
+  those chosen with --commits or touching --paths). The Refinery cherry-picks
+  them onto the target; the source issue stays open, the branch is kept, and
+  the session continues. Commits landed this way are skipped by later
+  partial submissions. Finish with a plain gt done as usual.
+
 Phase handoff workflow:
   When a molecule has gate steps (async waits), use --phase-complete to signal
   that the current phase is complete but work continues after the gate closes.
@@ -48,7 +61,10 @@ Examples:
   gt done --issue gt-abc               # Explicit issue ID
   gt done --status ESCALATED           # Signal blocker, skip MR
   gt done --status DEFERRED            # Pause work, skip MR
-  gt done --phase-complete --gate g-x  # Phase done, waiting on gate g-x`,
+  gt done --phase-complete --gate g-x  # Phase done, waiting on gate g-x
+  gt done --partial                    # Land commits so far, keep working
+  gt done --partial --commits a1b2c3d  # Land only the chosen commit(s)
+  gt done --partial --paths docs/      # Land commits touching docs/`,
 	RunE: runDone,
 }
 
@@ -59,6 +75,10 @@ var (
 	donePhaseComplete bool
 	doneGate          string
 	doneCleanupStatus string
+	doneSkipPreflight bool
+	donePartial       bool
+	doneCommits       []string
+	donePaths         []string
 )
 
 // Valid exit types for gt done
@@ -76,6 +96,10 @@ func init() {
 	doneCmd.Flags().BoolVar(&donePhaseComplete, "phase-complete", false, "Signal phase complete - await gate before continuing")
 	doneCmd.Flags().StringVar(&doneGate, "gate", "", "Gate bead ID to wait on (with --phase-complete)")
 	doneCmd.Flags().StringVar(&doneCleanupStatus, "cleanup-status", "", "Git cleanup status: clean, uncommitted, unpushed, stash, unknown (ZFC: agent-observed)")
+	doneCmd.Flags().BoolVar(&doneSkipPreflight, "skip-preflight", false, "Skip the rig's pre-flight check (merge_queue.preflight_command)")
+	doneCmd.Flags().BoolVar(&donePartial, "partial", false, "Submit selected commits as an MR and keep working on the branch")
+	doneCmd.Flags().StringSliceVar(&doneCommits, "commits", nil, "Commits to submit (with --partial; default: all unlanded commits)")
+	doneCmd.Flags().StringSliceVar(&donePaths, "paths", nil, "Submit only commits confined to these paths (with --partial)")
 
 	rootCmd.AddCommand(doneCmd)
 }
@@ -95,6 +119,12 @@ func runDone(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("invalid exit status '%s': must be COMPLETED, ESCALATED, or DEFERRED", doneStatus)
 		}
 	}
+	if (len(doneCommits) > 0 || len(donePaths) > 0) && !donePartial {
+		return fmt.Errorf("--commits and --paths require --partial")
+	}
+	if donePartial && exitType != ExitCompleted {
+		return fmt.Errorf("--partial submits work to the merge queue; it cannot be combined with %s", exitType)
+	}
 
 	// Find workspace with fallback for deleted worktrees (hq-3xaxy)
 	// If the polecat's worktree was deleted by Witness before gt done finishes,
@@ -115,7 +145,7 @@ func runDone(cmd *cobra.Command, args []string) error {
 	}
 
 	// Find current rig
-	rigName, _, err := findCurrentRig(townRoot)
+	rigName, currentRig, err := findCurrentRig(townRoot)
 	if err != nil {
 		return err
 	}
@@ -237,6 +267,9 @@ func runDone(cmd *cobra.Command, args []string) error {
 		if branch == defaultBranch || branch == "master" {
 			return fmt.Errorf("cannot submit %s/master branch to merge queue", defaultBranch)
 		}
+		if err := rig.CheckBranchPolicy(filepath.Join(townRoot, rigName), branch); err != nil {
+			return fmt.Errorf("cannot submit to merge queue: %w", err)
+		}
 
 		// CRITICAL: Verify work exists before completing (hq-xthqf)
 		// Polecats calling gt done without commits results in lost work.
@@ -274,16 +307,47 @@ func runDone(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("branch '%s' has 0 commits ahead of %s; nothing to merge\nMake and commit changes first, or use --status DEFERRED to exit without completing", branch, originDefault)
 		}
 
+		// Partial submission: pick the commits to land now
+		var partialCommits []string
+		if donePartial {
+			partialCommits, err = selectPartialCommits(g, originDefault, doneCommits, donePaths)
+			if err != nil {
+				return fmt.Errorf("cannot submit partial work: %w", err)
+			}
+		}
+
+		// Pre-flight: quick lint/build check so broken branches never reach the queue
+		if !doneSkipPreflight {
+			if err := runDonePreflight(filepath.Join(townRoot, rigName), cwd); err != nil {
+				return err
+			}
+		}
+
 		// CRITICAL: Push branch BEFORE creating MR bead (hq-6dk53, hq-a4ksk)
 		// The MR bead triggers Refinery to process this branch. If the branch
 		// isn't pushed yet, Refinery finds nothing to merge. The worktree gets
 		// nuked at the end of gt done, so the commits are lost forever.
 		fmt.Printf("Pushing branch to remote...\n")
-		if err := g.Push("origin", branch, false); err != nil {
+		// Pushes of large branches can outlast the keepalive thresholds
+		hb := keepalive.StartInWorkspace(townRoot, "gt done", "pushing "+branch)
+		defer hb.Stop()
+		pushGit := rig.WithGitAuth(filepath.Join(townRoot, rigName), g)
+		if err := pushDoneBranch(pushGit, branch); err != nil {
+			var leaseErr *git.LeaseError
+			if errors.As(err, &leaseErr) {
+				escalateRefusedPush(townRoot, rigName, sender, leaseErr)
+				return fmt.Errorf("%w\nAnother agent pushed to this branch. Do not force-push; wait for the Witness.", err)
+			}
 			return fmt.Errorf("pushing branch '%s' to origin: %w\nCommits exist locally but failed to push. Fix the issue and retry.", branch, err)
 		}
+		if err := verifyBranchPushed(pushGit, branch); err != nil {
+			return err
+		}
 		fmt.Printf("%s Branch pushed to origin\n", style.Bold.Render("✓"))
 
+		// Branches stacked on this one move onto what was just pushed
+		restackAbove(townRoot, rigName, branch)
+
 		if issueID == "" {
 			return fmt.Errorf("cannot determine source issue from branch '%s'; use --issue to specify", branch)
 		}
@@ -298,18 +362,19 @@ func runDone(cmd *cobra.Command, args []string) error {
 			target = autoTarget
 		}
 
-		// Get source issue for priority inheritance
+		// Get source issue for priority inheritance and MR field autofill
+		sourceIssue, err := bd.Show(issueID)
+		if err != nil {
+			sourceIssue = nil
+		}
 		var priority int
-		if donePriority >= 0 {
+		switch {
+		case donePriority >= 0:
 			priority = donePriority
-		} else {
-			// Try to inherit from source issue
-			sourceIssue, err := bd.Show(issueID)
-			if err != nil {
-				priority = 2 // Default
-			} else {
-				priority = sourceIssue.Priority
-			}
+		case sourceIssue != nil:
+			priority = sourceIssue.Priority
+		default:
+			priority = 2 // Default
 		}
 
 		// Check if MR bead already exists for this branch (idempotency)
@@ -319,27 +384,40 @@ func runDone(cmd *cobra.Command, args []string) error {
 			// Continue with creation attempt - Create will fail if duplicate
 		}
 
+		if existingMR != nil {
+			// A partial MR lands only some commits; nothing more can be queued
+			// for this branch until the Refinery has landed it.
+			if existing := beads.ParseMRFields(existingMR); existing != nil && existing.IsPartial() {
+				return fmt.Errorf("partial MR %s for this branch is still in the merge queue\nWait for it to land, then run gt done again", existingMR.ID)
+			}
+			if donePartial {
+				return fmt.Errorf("MR %s already submits this whole branch; nothing to submit partially", existingMR.ID)
+			}
+		}
+
 		if existingMR != nil {
 			// MR already exists - use it instead of creating a new one
 			mrID = existingMR.ID
 			fmt.Printf("%s MR already exists (idempotent)\n", style.Bold.Render("✓"))
 			fmt.Printf("  MR ID: %s\n", style.Bold.Render(mrID))
 		} else {
-			// Build MR bead title and description
-			title := fmt.Sprintf("Merge: %s", issueID)
-			description := fmt.Sprintf("branch: %s\ntarget: %s\nsource_issue: %s\nrig: %s",
-				branch, target, issueID, rigName)
-			if worker != "" {
-				description += fmt.Sprintf("\nworker: %s", worker)
-			}
-			if agentBeadID != "" {
-				description += fmt.Sprintf("\nagent_bead: %s", agentBeadID)
+			// Build MR bead title and description, autofilled from the source bead
+			convoyID, convoyCreatedAt := lookupConvoyFields(townRoot, issueID)
+			title := doneMRTitle(issueID, sourceIssue)
+			if donePartial {
+				title += " (partial)"
 			}
-
-			// Add conflict resolution tracking fields (initialized, updated by Refinery)
-			description += "\nretry_count: 0"
-			description += "\nlast_conflict_sha: null"
-			description += "\nconflict_task_id: null"
+			description := buildDoneMRDescription(&beads.MRFields{
+				Branch:          branch,
+				Target:          target,
+				SourceIssue:     issueID,
+				Rig:             rigName,
+				Worker:          worker,
+				AgentBead:       agentBeadID,
+				ConvoyID:        convoyID,
+				ConvoyCreatedAt: convoyCreatedAt,
+				Commits:         partialCommits,
+			})
 
 			// Create MR bead (ephemeral wisp - will be cleaned up after merge)
 			mrIssue, err := bd.Create(beads.CreateOptions{
@@ -372,7 +450,25 @@ func runDone(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  Worker: %s\n", worker)
 		}
 		fmt.Printf("  Priority: P%d\n", priority)
+		if donePartial {
+			fmt.Printf("  Commits: %d (partial)\n", len(partialCommits))
+		}
+		submitted := &beads.MRFields{
+			Branch:      branch,
+			Target:      target,
+			SourceIssue: issueID,
+			Worker:      worker,
+			Rig:         rigName,
+			Commits:     partialCommits,
+		}
+		routeSubmittedMR(townRoot, bd, g, mrID, submitted, sourceIssue, priority)
+		printQueuePosition(currentRig, mrID)
 		fmt.Println()
+		if donePartial {
+			// Work continues: no completion mail, no hook clearing, no self-nuke
+			fmt.Printf("%s\n", style.Dim.Render("The Refinery will land these commits. Keep working on your branch."))
+			return nil
+		}
 		fmt.Printf("%s\n", style.Dim.Render("The Refinery will process your merge request."))
 	} else if exitType == ExitPhaseComplete {
 		// Phase complete - register as waiter on gate, then recycle
@@ -451,9 +547,14 @@ func runDone(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Work submitted: drop any dispatch claim on it so it isn't re-queued
+	if exitType == ExitCompleted {
+		finishDispatchClaim(townRoot, issueID)
+	}
+
 	// Log done event (townlog and activity feed)
 	_ = LogDone(townRoot, sender, issueID)
-	_ = events.LogFeed(events.TypeDone, sender, events.DonePayload(issueID, branch))
+	_ = events.LogFeed(events.TypeDone, sender, events.DonePayload(issueID, branch, exitType))
 
 	// Update agent bead state (ZFC: self-report completion)
 	updateAgentStateOnDone(cwd, townRoot, exitType, issueID)
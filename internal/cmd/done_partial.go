@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// selectPartialCommits returns the commits a partial submission lands, in
+// branch order. Candidates are the commits on HEAD not yet on upstream
+// (commits landed by an earlier partial submission are skipped), narrowed to
+// those touching paths if any are given. If commits is non-empty, each must
+// name one of the candidates and only those are selected.
+//
+// Commits land whole, so with paths a selected commit that also touches
+// files outside them is refused rather than landing those files too.
+func selectPartialCommits(g *git.Git, upstream string, commits, paths []string) ([]string, error) {
+	candidates, err := g.UnlandedCommits(upstream, "HEAD", paths...)
+	if err != nil {
+		return nil, fmt.Errorf("listing unlanded commits: %w", err)
+	}
+	if len(commits) == 0 {
+		if len(candidates) == 0 {
+			if len(paths) > 0 {
+				return nil, fmt.Errorf("no unlanded commits touch %s", strings.Join(paths, ", "))
+			}
+			return nil, fmt.Errorf("no unlanded commits ahead of %s", upstream)
+		}
+		return candidates, checkPartialPaths(g, candidates, paths)
+	}
+
+	wanted := make(map[string]bool, len(commits))
+	for _, c := range commits {
+		sha, err := g.Rev(c + "^{commit}")
+		if err != nil {
+			return nil, fmt.Errorf("resolving commit %q: %w", c, err)
+		}
+		wanted[sha] = true
+	}
+
+	var selected []string
+	for _, sha := range candidates {
+		if wanted[sha] {
+			selected = append(selected, sha)
+			delete(wanted, sha)
+		}
+	}
+	for sha := range wanted {
+		return nil, fmt.Errorf("commit %s is not an unlanded commit on this branch (already landed, a merge, or outside --paths)", git.ShortSHA(sha))
+	}
+	return selected, checkPartialPaths(g, selected, paths)
+}
+
+// checkPartialPaths returns an error listing the commits that change files
+// outside paths, and those files. A no-op without paths.
+func checkPartialPaths(g *git.Git, commits, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	outside := make([]string, 0, len(paths))
+	for _, p := range paths {
+		outside = append(outside, ":(exclude)"+p)
+	}
+
+	var mixed []string
+	for _, c := range commits {
+		files, err := g.CommitFiles(c, outside...)
+		if err != nil {
+			return fmt.Errorf("listing files in %s: %w", git.ShortSHA(c), err)
+		}
+		if len(files) > 0 {
+			mixed = append(mixed, fmt.Sprintf("  %s: %s", git.ShortSHA(c), strings.Join(files, ", ")))
+		}
+	}
+	if len(mixed) > 0 {
+		return fmt.Errorf("commits also change files outside --paths and would land them too:\n%s\nSplit those commits, or widen --paths", strings.Join(mixed, "\n"))
+	}
+	return nil
+}
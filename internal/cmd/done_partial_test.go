@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+func TestSelectPartialCommits(t *testing.T) {
+	origin := filepath.Join(t.TempDir(), "origin.git")
+	gitIn(t, filepath.Dir(origin), "init", "--bare", "-b", "main", origin)
+
+	local := cloneForPush(t, origin)
+	gitIn(t, local, "commit", "--allow-empty", "-m", "initial")
+	gitIn(t, local, "push", "origin", "HEAD:main")
+	gitIn(t, local, "checkout", "-b", "polecat/nux")
+	g := git.NewGit(local)
+
+	commit := func(file, msg string) string {
+		t.Helper()
+		path := filepath.Join(local, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(msg+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		gitIn(t, local, "add", ".")
+		gitIn(t, local, "commit", "-m", msg)
+		sha, err := g.Rev("HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sha
+	}
+	c1 := commit("a.txt", "first")
+	c2 := commit("docs/b.md", "docs")
+	c3 := commit("a.txt", "second")
+
+	tests := []struct {
+		name    string
+		commits []string
+		paths   []string
+		want    []string
+	}{
+		{"all unlanded", nil, nil, []string{c1, c2, c3}},
+		{"by path", nil, []string{"docs"}, []string{c2}},
+		{"chosen commits keep branch order", []string{c3[:7], c1}, nil, []string{c1, c3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectPartialCommits(g, "origin/main", tt.commits, tt.paths)
+			if err != nil {
+				t.Fatalf("selectPartialCommits: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectPartialCommits = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Land c1 on main the way the Refinery does; it is no longer selectable
+	gitIn(t, local, "checkout", "-b", "land", "origin/main")
+	gitIn(t, local, "cherry-pick", "-x", c1)
+	gitIn(t, local, "push", "origin", "HEAD:main")
+	gitIn(t, local, "checkout", "polecat/nux")
+	gitIn(t, local, "fetch", "origin")
+
+	got, err := selectPartialCommits(g, "origin/main", nil, nil)
+	if err != nil {
+		t.Fatalf("selectPartialCommits after landing: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{c2, c3}) {
+		t.Errorf("after landing c1 = %v, want %v", got, []string{c2, c3})
+	}
+	if _, err := selectPartialCommits(g, "origin/main", []string{c1}, nil); err == nil {
+		t.Error("selecting an already-landed commit should fail")
+	}
+	if _, err := selectPartialCommits(g, "origin/main", nil, []string{"missing/"}); err == nil {
+		t.Error("paths touched by no commit should fail")
+	}
+
+	// A commit touching docs/ and other files can't land as docs/ alone
+	if err := os.WriteFile(filepath.Join(local, "a.txt"), []byte("third\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mixed := commit("docs/c.md", "mixed")
+	_, err = selectPartialCommits(g, "origin/main", nil, []string{"docs"})
+	if err == nil || !strings.Contains(err.Error(), git.ShortSHA(mixed)+": a.txt") {
+		t.Errorf("commit with files outside --paths: err = %v, want it listed with a.txt", err)
+	}
+	if _, err := selectPartialCommits(g, "origin/main", []string{c2}, []string{"docs"}); err != nil {
+		t.Errorf("commit entirely inside --paths: %v", err)
+	}
+}
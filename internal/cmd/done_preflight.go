@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// donePreflightCommand returns the rig's configured pre-flight check command,
+// or empty if none is configured.
+func donePreflightCommand(rigPath string) string {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil || settings.MergeQueue == nil {
+		return ""
+	}
+	return strings.TrimSpace(settings.MergeQueue.PreflightCommand)
+}
+
+// runDonePreflight runs the rig's quick check (lint/build) in the worktree.
+// A failing check blocks submission so broken branches never reach the Refinery.
+func runDonePreflight(rigPath, workDir string) error {
+	command := donePreflightCommand(rigPath)
+	if command == "" {
+		return nil
+	}
+
+	fmt.Printf("Running pre-flight check: %s\n", style.Dim.Render(command))
+	c := exec.Command("sh", "-c", command) //nolint:gosec // G204: command comes from rig settings
+	c.Dir = workDir
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("pre-flight check failed (%s): %w\nFix the problem and retry, or use --skip-preflight to submit anyway", command, err)
+	}
+	fmt.Printf("%s Pre-flight check passed\n", style.Bold.Render("✓"))
+	return nil
+}
+
+// pushDoneBranch pushes branch to origin. If the branch was rebased since it
+// was last pushed (e.g. after conflict resolution), history was rewritten and
+// a force push is needed: it is only allowed to replace the remote tip this
+// worktree last saw, so a push by another agent is never clobbered.
+// Returns a *git.LeaseError if the remote moved unexpectedly.
+func pushDoneBranch(g *git.Git, branch string) error {
+	lastSeen, err := g.Rev("refs/remotes/origin/" + branch)
+	if err != nil {
+		// Never pushed from here: plain push (fails safely if the branch exists)
+		return g.Push("origin", branch, false)
+	}
+	if fastForward, err := g.IsAncestor(lastSeen, branch); err == nil && fastForward {
+		return g.Push("origin", branch, false)
+	}
+
+	actual, err := g.RemoteBranchSHA("origin", branch)
+	if err != nil {
+		return err
+	}
+	if actual != "" && actual != lastSeen {
+		return &git.LeaseError{Remote: "origin", Branch: branch, Expected: lastSeen, Actual: actual}
+	}
+	fmt.Printf("Branch history was rewritten; force-pushing with lease on %s\n", lastSeen[:8])
+	return g.PushWithLease("origin", branch, lastSeen)
+}
+
+// escalateRefusedPush notifies the Witness that a force push was refused
+// because another agent pushed to the branch. Resolving this needs judgment
+// about whose commits to keep, so the polecat doesn't retry on its own.
+func escalateRefusedPush(townRoot, rigName, sender string, leaseErr *git.LeaseError) {
+	msg := &mail.Message{
+		To:       fmt.Sprintf("%s/witness", rigName),
+		From:     sender,
+		Subject:  fmt.Sprintf("ESCALATION: force-push refused on %s", leaseErr.Branch),
+		Priority: mail.PriorityHigh,
+		Body: fmt.Sprintf("Branch: %s\nExpected remote tip: %s\nActual remote tip: %s\n\n"+
+			"gt done refused to overwrite commits pushed by someone else.\n"+
+			"Inspect with: git log %s..origin/%s",
+			leaseErr.Branch, leaseErr.Expected, leaseErr.Actual, leaseErr.Expected, leaseErr.Branch),
+	}
+	if err := mail.NewRouter(townRoot).Send(msg); err != nil {
+		style.PrintWarning("could not escalate refused push to witness: %v", err)
+		return
+	}
+	fmt.Printf("%s Escalated to %s/witness\n", style.Bold.Render("⚠"), rigName)
+}
+
+// verifyBranchPushed confirms origin has every local commit on branch.
+// Guards against a push that "succeeded" without updating the remote ref.
+func verifyBranchPushed(g *git.Git, branch string) error {
+	pushed, unpushed, err := g.BranchPushedToRemote(branch, "origin")
+	if err != nil {
+		return fmt.Errorf("verifying push of '%s': %w", branch, err)
+	}
+	if !pushed || unpushed > 0 {
+		return fmt.Errorf("branch '%s' is not fully pushed to origin (%d unpushed commit(s))\nPush manually with: git push origin %s", branch, unpushed, branch)
+	}
+	return nil
+}
+
+// buildDoneMRDescription builds the MR bead description from its fields.
+// The source bead's title goes in the MR title (see doneMRTitle), not
+// here: ParseMRFields reads every "key: value" line of the description,
+// so a title such as "Target: release-2" would change the MR's fields.
+func buildDoneMRDescription(fields *beads.MRFields) string {
+	description := fmt.Sprintf("branch: %s\ntarget: %s\nsource_issue: %s\nrig: %s",
+		fields.Branch, fields.Target, fields.SourceIssue, fields.Rig)
+	if fields.Worker != "" {
+		description += fmt.Sprintf("\nworker: %s", fields.Worker)
+	}
+	if fields.AgentBead != "" {
+		description += fmt.Sprintf("\nagent_bead: %s", fields.AgentBead)
+	}
+	if fields.ConvoyID != "" {
+		description += fmt.Sprintf("\nconvoy_id: %s", fields.ConvoyID)
+	}
+	if fields.ConvoyCreatedAt != "" {
+		description += fmt.Sprintf("\nconvoy_created_at: %s", fields.ConvoyCreatedAt)
+	}
+	if len(fields.Commits) > 0 {
+		description += fmt.Sprintf("\ncommits: %s", strings.Join(fields.Commits, ","))
+	}
+
+	// Add conflict resolution tracking fields (initialized, updated by Refinery)
+	description += "\nretry_count: 0"
+	description += "\nlast_conflict_sha: null"
+	description += "\nconflict_task_id: null"
+	return description
+}
+
+// doneMRTitle returns the MR bead title, including the source bead title when known.
+func doneMRTitle(issueID string, source *beads.Issue) string {
+	if source != nil && source.Title != "" {
+		return fmt.Sprintf("Merge: %s (%s)", issueID, source.Title)
+	}
+	return fmt.Sprintf("Merge: %s", issueID)
+}
+
+// lookupConvoyFields returns the convoy tracking issueID and its creation
+// time, or empty strings if the issue isn't part of a convoy.
+func lookupConvoyFields(townRoot, issueID string) (convoyID, createdAt string) {
+	convoyID = isTrackedByConvoy(issueID)
+	if convoyID == "" {
+		return "", ""
+	}
+	townBeads := beads.New(filepath.Join(townRoot, ".beads"))
+	if convoy, err := townBeads.Show(convoyID); err == nil {
+		createdAt = convoy.CreatedAt
+	}
+	return convoyID, createdAt
+}
+
+// mrQueuePosition returns the 1-based position of mrID in the rig's merge
+// queue and the queue length. Position 0 means currently processing.
+func mrQueuePosition(r *rig.Rig, mrID string) (pos, total int, err error) {
+	items, err := refinery.NewManager(r).Queue()
+	if err != nil {
+		return -1, 0, err
+	}
+	for _, item := range items {
+		if item.MR != nil && item.MR.ID == mrID {
+			return item.Position, len(items), nil
+		}
+	}
+	return -1, len(items), nil
+}
+
+// printQueuePosition reports where the MR landed in the merge queue (best-effort).
+func printQueuePosition(r *rig.Rig, mrID string) {
+	if r == nil || mrID == "" {
+		return
+	}
+	pos, total, err := mrQueuePosition(r, mrID)
+	switch {
+	case err != nil:
+		style.PrintWarning("could not determine queue position: %v", err)
+	case pos == 0:
+		fmt.Printf("  Queue: processing now\n")
+	case pos > 0:
+		fmt.Printf("  Queue: position %d of %d\n", pos, total)
+	}
+}
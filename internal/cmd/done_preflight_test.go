@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+func writePreflightSettings(t *testing.T, rigPath, command string) {
+	t.Helper()
+	settings := config.NewRigSettings()
+	settings.MergeQueue = config.DefaultMergeQueueConfig()
+	settings.MergeQueue.PreflightCommand = command
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+}
+
+func TestRunDonePreflight(t *testing.T) {
+	rigPath := t.TempDir()
+	workDir := t.TempDir()
+
+	// No settings: nothing to run
+	if err := runDonePreflight(rigPath, workDir); err != nil {
+		t.Fatalf("no settings: unexpected error: %v", err)
+	}
+
+	// Passing check runs in the worktree
+	writePreflightSettings(t, rigPath, "touch preflight-ran")
+	if err := runDonePreflight(rigPath, workDir); err != nil {
+		t.Fatalf("passing check: unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "preflight-ran")); err != nil {
+		t.Errorf("pre-flight command did not run in worktree: %v", err)
+	}
+
+	// Failing check blocks
+	writePreflightSettings(t, rigPath, "exit 3")
+	err := runDonePreflight(rigPath, workDir)
+	if err == nil {
+		t.Fatal("failing check: expected error")
+	}
+	if !strings.Contains(err.Error(), "--skip-preflight") {
+		t.Errorf("error should mention --skip-preflight, got: %v", err)
+	}
+}
+
+func TestBuildDoneMRDescription(t *testing.T) {
+	desc := buildDoneMRDescription(&beads.MRFields{
+		Branch:          "polecat/nux/gt-abc@m1",
+		Target:          "main",
+		SourceIssue:     "gt-abc",
+		Rig:             "gastown",
+		Worker:          "nux",
+		AgentBead:       "gt-gastown-polecat-nux",
+		ConvoyID:        "hq-cv-123",
+		ConvoyCreatedAt: "2026-01-02T03:04:05Z",
+		Commits:         []string{"aaa111", "bbb222"},
+	})
+
+	fields := beads.ParseMRFields(&beads.Issue{Description: desc})
+	if fields == nil {
+		t.Fatal("ParseMRFields returned nil")
+	}
+	if fields.Branch != "polecat/nux/gt-abc@m1" || fields.Target != "main" || fields.SourceIssue != "gt-abc" {
+		t.Errorf("core fields not round-tripped: %+v", fields)
+	}
+	if fields.ConvoyID != "hq-cv-123" || fields.ConvoyCreatedAt != "2026-01-02T03:04:05Z" {
+		t.Errorf("convoy fields not round-tripped: %+v", fields)
+	}
+	if fields.AgentBead != "gt-gastown-polecat-nux" {
+		t.Errorf("AgentBead = %q", fields.AgentBead)
+	}
+	if !fields.IsPartial() || strings.Join(fields.Commits, ",") != "aaa111,bbb222" {
+		t.Errorf("Commits = %v, want partial MR with [aaa111 bbb222]", fields.Commits)
+	}
+}
+
+func TestDoneMRFieldsIgnoreFieldShapedTitle(t *testing.T) {
+	source := &beads.Issue{ID: "gt-abc", Title: "Target: release-2"}
+	desc := buildDoneMRDescription(&beads.MRFields{
+		Branch:      "polecat/nux/gt-abc@m1",
+		Target:      "main",
+		SourceIssue: "gt-abc",
+		Rig:         "gastown",
+	})
+	mr := &beads.Issue{Title: doneMRTitle("gt-abc", source), Description: desc}
+
+	fields := beads.ParseMRFields(mr)
+	if fields == nil || fields.Target != "main" || fields.Branch != "polecat/nux/gt-abc@m1" {
+		t.Errorf("source title changed MR fields: %+v", fields)
+	}
+	if !strings.Contains(mr.Title, "Target: release-2") {
+		t.Errorf("MR title missing source title: %q", mr.Title)
+	}
+}
+
+func TestDoneMRTitle(t *testing.T) {
+	if got := doneMRTitle("gt-abc", nil); got != "Merge: gt-abc" {
+		t.Errorf("doneMRTitle(nil) = %q", got)
+	}
+	if got := doneMRTitle("gt-abc", &beads.Issue{Title: "Fix it"}); got != "Merge: gt-abc (Fix it)" {
+		t.Errorf("doneMRTitle(source) = %q", got)
+	}
+}
+
+// gitIn runs git in dir and fails the test on error.
+func gitIn(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// cloneForPush clones origin into a new dir with a commit identity set.
+func cloneForPush(t *testing.T, origin string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "clone")
+	gitIn(t, filepath.Dir(dir), "clone", origin, dir)
+	gitIn(t, dir, "config", "user.email", "test@test.com")
+	gitIn(t, dir, "config", "user.name", "Test User")
+	return dir
+}
+
+func TestPushDoneBranch(t *testing.T) {
+	origin := filepath.Join(t.TempDir(), "origin.git")
+	gitIn(t, filepath.Dir(origin), "init", "--bare", origin)
+
+	local := cloneForPush(t, origin)
+	gitIn(t, local, "checkout", "-b", "polecat/nux")
+	gitIn(t, local, "commit", "--allow-empty", "-m", "work")
+	g := git.NewGit(local)
+
+	// First push: plain push
+	if err := pushDoneBranch(g, "polecat/nux"); err != nil {
+		t.Fatalf("first push: %v", err)
+	}
+
+	// Rebased (rewritten) branch: lease-protected force push succeeds
+	gitIn(t, local, "commit", "--amend", "--allow-empty", "-m", "work (rebased)")
+	if err := pushDoneBranch(g, "polecat/nux"); err != nil {
+		t.Fatalf("push after rewrite: %v", err)
+	}
+
+	// Another agent pushes to the branch; our rewrite must be refused
+	other := cloneForPush(t, origin)
+	gitIn(t, other, "checkout", "polecat/nux")
+	gitIn(t, other, "commit", "--allow-empty", "-m", "someone else's work")
+	gitIn(t, other, "push", "origin", "polecat/nux")
+
+	gitIn(t, local, "commit", "--amend", "--allow-empty", "-m", "work (rebased again)")
+	err := pushDoneBranch(g, "polecat/nux")
+	var leaseErr *git.LeaseError
+	if !errors.As(err, &leaseErr) {
+		t.Fatalf("push over another agent's commits = %v, want *git.LeaseError", err)
+	}
+}
@@ -66,20 +66,55 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	issue, actions, targets, err := fileEscalation(townRoot, escalationConfig, agentID, severity, description,
+		escalateReason, escalateSource, escalateRelatedBead)
+	if err != nil {
+		return err
+	}
+
+	// Output
+	if escalateJSON {
+		result := map[string]interface{}{
+			"id":       issue.ID,
+			"severity": severity,
+			"actions":  actions,
+			"targets":  targets,
+		}
+		if escalateSource != "" {
+			result["source"] = escalateSource
+		}
+		out, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(out))
+	} else {
+		emoji := severityEmoji(severity)
+		fmt.Printf("%s Escalation created: %s\n", emoji, issue.ID)
+		fmt.Printf("  Severity: %s\n", severity)
+		if escalateSource != "" {
+			fmt.Printf("  Source: %s\n", escalateSource)
+		}
+		fmt.Printf("  Routed to: %s\n", strings.Join(targets, ", "))
+	}
+
+	return nil
+}
+
+// fileEscalation creates an escalation bead and routes it by severity:
+// mail to each configured target, external notifications, and the feed.
+func fileEscalation(townRoot string, escalationConfig *config.EscalationConfig, agentID, severity, description, reason, source, related string) (*beads.Issue, []string, []string, error) {
 	// Create escalation bead
 	bd := beads.New(beads.ResolveBeadsDir(townRoot))
 	fields := &beads.EscalationFields{
 		Severity:    severity,
-		Reason:      escalateReason,
-		Source:      escalateSource,
+		Reason:      reason,
+		Source:      source,
 		EscalatedBy: agentID,
 		EscalatedAt: time.Now().Format(time.RFC3339),
-		RelatedBead: escalateRelatedBead,
+		RelatedBead: related,
 	}
 
 	issue, err := bd.CreateEscalationBead(description, fields)
 	if err != nil {
-		return fmt.Errorf("creating escalation bead: %w", err)
+		return nil, nil, nil, fmt.Errorf("creating escalation bead: %w", err)
 	}
 
 	// Get routing actions for this severity
@@ -93,7 +128,7 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 			From:    agentID,
 			To:      target,
 			Subject: fmt.Sprintf("[%s] %s", strings.ToUpper(severity), description),
-			Body:    formatEscalationMailBody(issue.ID, severity, escalateReason, agentID, escalateRelatedBead),
+			Body:    formatEscalationMailBody(issue.ID, severity, reason, agentID, related),
 			Type:    mail.TypeTask,
 		}
 
@@ -121,35 +156,12 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 	payload := events.EscalationPayload(issue.ID, agentID, strings.Join(targets, ","), description)
 	payload["severity"] = severity
 	payload["actions"] = strings.Join(actions, ",")
-	if escalateSource != "" {
-		payload["source"] = escalateSource
+	if source != "" {
+		payload["source"] = source
 	}
 	_ = events.LogFeed(events.TypeEscalationSent, agentID, payload)
 
-	// Output
-	if escalateJSON {
-		result := map[string]interface{}{
-			"id":       issue.ID,
-			"severity": severity,
-			"actions":  actions,
-			"targets":  targets,
-		}
-		if escalateSource != "" {
-			result["source"] = escalateSource
-		}
-		out, _ := json.MarshalIndent(result, "", "  ")
-		fmt.Println(string(out))
-	} else {
-		emoji := severityEmoji(severity)
-		fmt.Printf("%s Escalation created: %s\n", emoji, issue.ID)
-		fmt.Printf("  Severity: %s\n", severity)
-		if escalateSource != "" {
-			fmt.Printf("  Source: %s\n", escalateSource)
-		}
-		fmt.Printf("  Routed to: %s\n", strings.Join(targets, ", "))
-	}
-
-	return nil
+	return issue, actions, targets, nil
 }
 
 func runEscalateList(cmd *cobra.Command, args []string) error {
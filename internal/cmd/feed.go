@@ -53,6 +53,15 @@ By default, launches an interactive TUI dashboard with:
   - Event stream (bottom): Chronological feed you can scroll through
   - Vim-style navigation: j/k to scroll, tab to switch panels, 1/2/3 for panels, q to quit
 
+Event stream controls:
+  p    Pause the stream to read it; new events are counted and shown on resume
+  /    Search events by type, agent, bead, rig, or text (enter applies)
+  f    Cycle the severity filter: all, warnings and errors, errors only
+  esc  Clear the search and severity filter
+
+Warnings (escalations, nudges, restarts, kills) are shown in yellow and
+errors (failures, failed merges, session deaths) in red.
+
 The feed combines multiple event sources:
   - Beads activity: Issue creates, updates, completions (from bd activity)
   - GT events: Agent activity like patrol, sling, handoff (from .events.jsonl)
@@ -74,6 +83,8 @@ Event symbols:
   ⚡  polecat_nudged   - Worker was nudged
   🎯  sling            - Work was slung to worker
   🤝  handoff          - Session handed off
+  ♻  restart           - Daemon restarted a dead agent session
+  ☠  session_death     - Agent session died
 
 MQ (Merge Queue) event symbols:
   ⚙  merge_started   - Refinery began processing an MR
@@ -0,0 +1,344 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/ratelimit"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// EnvGTGating overrides the town's command gating mode ("warn", "block", "off").
+// Intended for the overseer when an agent legitimately needs a gated command.
+const EnvGTGating = "GT_GATING"
+
+// Commands never subject to gating (help and diagnostics must always work).
+var gatingExemptCommands = map[string]bool{
+	"version":    true,
+	"help":       true,
+	"completion": true,
+	"doctor":     true,
+	"whoami":     true,
+	"prime":      true,
+}
+
+// observerCommands are the only commands an observer may run. Observers
+// inspect a town and must never change it, so this allowlist is fixed:
+// unlike the configurable rules it ignores the gating mode and GT_GATING.
+// Paths are matched exactly, so a read-only command doesn't admit its
+// mutating subcommands.
+var observerCommands = map[string]bool{
+	"": true, "version": true, "info": true, "thanks": true, "stale": true,
+	"whoami": true, "prime": true, "doctor": true, // doctor without --fix; see observerMayRun
+
+	// Identity
+	"role": true, "role show": true, "role list": true, "role detect": true, "role env": true, "role home": true,
+
+	// Town and rig state
+	"status": true, "ready": true, "show": true, "cat": true, "hooks": true,
+	"rig list": true, "rig status": true, "polecat list": true, "polecat status": true,
+	"polecat git-state": true, "crew list": true, "crew status": true, "peek": true,
+	"hook show": true, "hook status": true, "locks list": true, "locks show": true,
+	"lease list": true, "lease show": true, "plan list": true, "plan show": true,
+	"approvals": true, "alerts": true, "schedules": true, "schedules next": true,
+	"webhooks": true, "notifications": true, "ratelimit status": true,
+
+	// Queues and convoys
+	"mq list": true, "mq status": true, "mq next": true,
+	"convoy list": true, "convoy status": true,
+
+	// History
+	"log": true, "log query": true, "trail": true, "trail beads": true, "trail commits": true,
+	"trail hooks": true, "feed": true, "stats": true, "usage": true, "audit": true,
+	"audit head": true, "audit verify": true, "transcripts search": true,
+
+	// The observer's own mailbox (checkObserverMailbox keeps it to their own)
+	"mail inbox": true, "mail read": true, "mail peek": true, "mail check": true,
+	"mail thread": true, "mail search": true,
+}
+
+// gateDecision is the outcome of evaluating gating rules for a command.
+type gateDecision struct {
+	Rule   *config.CommandGateRule
+	Action string // "warn" or "block"; empty if no rule matched
+}
+
+// checkCommandGate enforces role-aware command gating for cmd.
+// Returns an error if the command is blocked for the detected role.
+// Role detection or config failures never block: gating is a guard rail,
+// not an access control system.
+func checkCommandGate(cmd *cobra.Command) error {
+	roleInfo, err := GetRole()
+	if err == nil && roleInfo.Role == RoleObserver {
+		return checkObserverGate(cmd)
+	}
+	if gatingExemptCommands[cmd.Name()] {
+		return nil
+	}
+
+	if err != nil || roleInfo.Role == RoleUnknown || roleInfo.Role == RoleMayor {
+		// Outside a workspace, or the Mayor/overseer: nothing to gate
+		return nil
+	}
+
+	cfg, err := config.LoadOrCreateCommandGatingConfig(config.CommandGatingConfigPath(roleInfo.TownRoot))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s command gating config invalid, skipping: %v\n", style.WarningPrefix, err)
+		return nil
+	}
+
+	mode := cfg.Mode
+	if env := os.Getenv(EnvGTGating); env != "" {
+		mode = env
+	}
+	if mode == config.GateModeOff {
+		return nil
+	}
+
+	decision := evaluateCommandGate(cfg, string(roleInfo.Role), buildCommandPath(cmd), changedFlags(cmd))
+	if decision.Rule == nil {
+		return nil
+	}
+
+	path := buildCommandPath(cmd)
+	if decision.Action == config.GateActionBlock && mode != config.GateActionWarn {
+		return fmt.Errorf("'%s' is blocked for role %s: %s\n\nIf this is intended, ask the overseer to run it or set %s=warn",
+			path, roleInfo.Role, gateReason(decision.Rule), EnvGTGating)
+	}
+
+	fmt.Fprintf(os.Stderr, "%s '%s' is not recommended for role %s: %s\n",
+		style.WarningPrefix, path, roleInfo.Role, gateReason(decision.Rule))
+	return nil
+}
+
+// checkObserverGate allows an observer only the read-only commands in
+// observerCommands, whatever the gating mode.
+func checkObserverGate(cmd *cobra.Command) error {
+	path := buildCommandPath(cmd)
+	if observerMayRun(strings.TrimSpace(strings.TrimPrefix(path, "gt")), changedFlags(cmd)) {
+		return nil
+	}
+	return fmt.Errorf("'%s' is not available to observers: observers are read-only\n\n"+
+		"Run 'gt prime' to see the commands observers may use", path)
+}
+
+// observerMayRun reports whether an observer may run the command at path
+// (without the leading "gt") with flags set.
+func observerMayRun(path string, flags map[string]bool) bool {
+	// Help and shell completion are generated by cobra, under any path.
+	switch first, _, _ := strings.Cut(path, " "); first {
+	case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	if path == "doctor" && (flags["fix"] || flags["restart-sessions"]) {
+		return false
+	}
+	return observerCommands[path]
+}
+
+// evaluateCommandGate returns the first rule matching role, command path,
+// and set flags. commandPath is the full path including the leading "gt".
+func evaluateCommandGate(cfg *config.CommandGatingConfig, role, commandPath string, flags map[string]bool) gateDecision {
+	path := strings.TrimSpace(strings.TrimPrefix(commandPath, "gt"))
+	for i := range cfg.Rules {
+		rule := &cfg.Rules[i]
+		if !gateRuleMatchesRole(rule, role) || !gateRuleMatchesCommand(rule, path) {
+			continue
+		}
+		if len(rule.Flags) > 0 && !gateRuleMatchesFlags(rule, flags) {
+			continue
+		}
+		return gateDecision{Rule: rule, Action: rule.Action}
+	}
+	return gateDecision{}
+}
+
+func gateRuleMatchesRole(rule *config.CommandGateRule, role string) bool {
+	for _, r := range rule.Roles {
+		if r == "*" || r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// gateRuleMatchesCommand matches whole words, so "rig" matches "rig remove"
+// but not "rigs".
+func gateRuleMatchesCommand(rule *config.CommandGateRule, path string) bool {
+	prefix := strings.Join(strings.Fields(rule.Command), " ")
+	return path == prefix || strings.HasPrefix(path, prefix+" ")
+}
+
+func gateRuleMatchesFlags(rule *config.CommandGateRule, flags map[string]bool) bool {
+	for _, f := range rule.Flags {
+		if flags[strings.TrimLeft(f, "-")] {
+			return true
+		}
+	}
+	return false
+}
+
+// changedFlags returns the names of flags explicitly set on the command line.
+func changedFlags(cmd *cobra.Command) map[string]bool {
+	flags := make(map[string]bool)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		flags[f.Name] = true
+	})
+	return flags
+}
+
+func gateReason(rule *config.CommandGateRule) string {
+	if rule.Reason != "" {
+		return rule.Reason
+	}
+	return "command gated by town policy (settings/command-gating.json)"
+}
+
+// checkGitCommandGate applies the town's "git ..." gating rules to the git
+// invocations in a shell command an agent is about to run. It is called from
+// the PreToolUse hook (gt ratelimit check), since agents run git directly
+// rather than through gt. Like checkCommandGate it fails open.
+func checkGitCommandGate(townRoot string, role Role, rigName, command string) error {
+	if role == RoleUnknown || role == RoleMayor {
+		return nil
+	}
+
+	cfg, err := config.LoadOrCreateCommandGatingConfig(config.CommandGatingConfigPath(townRoot))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s command gating config invalid, skipping: %v\n", style.WarningPrefix, err)
+		return nil
+	}
+
+	mode := cfg.Mode
+	if env := os.Getenv(EnvGTGating); env != "" {
+		mode = env
+	}
+	if mode == config.GateModeOff {
+		return nil
+	}
+
+	currentBranch := func() string {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return ""
+		}
+		branch, _ := git.NewGit(cwd).CurrentBranch()
+		return branch
+	}
+	defaultBranch := func() string {
+		if rigName == "" {
+			return "main"
+		}
+		return (&rig.Rig{Name: rigName, Path: filepath.Join(townRoot, rigName)}).DefaultBranch()
+	}
+	for _, inv := range ratelimit.ParseGit(command) {
+		decision := evaluateGitGate(cfg, string(role), inv, currentBranch, defaultBranch)
+		if decision.Rule == nil {
+			continue
+		}
+		if decision.Action == config.GateActionBlock && mode != config.GateActionWarn {
+			return fmt.Errorf("'%s' is blocked for role %s: %s\n\nIf this is intended, ask the overseer to run it or set %s=warn",
+				inv.Text, role, gateReason(decision.Rule), EnvGTGating)
+		}
+		fmt.Fprintf(os.Stderr, "%s '%s' is not recommended for role %s: %s\n",
+			style.WarningPrefix, inv.Text, role, gateReason(decision.Rule))
+	}
+	return nil
+}
+
+// evaluateGitGate returns the first "git ..." rule matching role and the
+// git invocation. currentBranch is consulted only for a push rule with
+// Branches when the push names no refspec, and defaultBranch only to
+// resolve config.GateBranchDefault.
+func evaluateGitGate(cfg *config.CommandGatingConfig, role string, inv ratelimit.GitInvocation, currentBranch, defaultBranch func() string) gateDecision {
+	path := "git " + inv.Subcommand
+	var flags map[string]bool
+	var targets []string
+	if inv.Subcommand == "push" {
+		flags, targets = gitPushArgs(inv.Args)
+	}
+	for i := range cfg.Rules {
+		rule := &cfg.Rules[i]
+		if !gateRuleMatchesRole(rule, role) || !gateRuleMatchesCommand(rule, path) {
+			continue
+		}
+		if len(rule.Flags) > 0 && !gateRuleMatchesFlags(rule, flags) {
+			continue
+		}
+		if len(rule.Branches) > 0 {
+			if len(targets) == 0 {
+				if branch := currentBranch(); branch != "" {
+					targets = []string{branch}
+				}
+			}
+			if !gateRuleMatchesBranches(rule, targets, defaultBranch) {
+				continue
+			}
+		}
+		return gateDecision{Rule: rule, Action: rule.Action}
+	}
+	return gateDecision{}
+}
+
+// gitPushValueOptions are git push options whose value is a separate word.
+var gitPushValueOptions = map[string]bool{
+	"-o": true, "--push-option": true, "--repo": true, "--receive-pack": true, "--exec": true,
+}
+
+// gitPushArgs splits git push arguments into the flags set (long names
+// without dashes or values, short flags as single letters) and the branches
+// the refspecs update. A "+" refspec counts as the "force" flag.
+func gitPushArgs(args []string) (flags map[string]bool, targets []string) {
+	flags = make(map[string]bool)
+	remoteSeen := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case gitPushValueOptions[arg]:
+			i++
+		case strings.HasPrefix(arg, "--"):
+			name, _, _ := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+			flags[name] = true
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			for _, c := range arg[1:] {
+				flags[string(c)] = true
+			}
+		case !remoteSeen:
+			remoteSeen = true
+		default:
+			if strings.HasPrefix(arg, "+") {
+				flags["force"] = true
+				arg = arg[1:]
+			}
+			src, dst, ok := strings.Cut(arg, ":")
+			if !ok {
+				dst = src
+			}
+			if dst != "" {
+				targets = append(targets, strings.TrimPrefix(dst, "refs/heads/"))
+			}
+		}
+	}
+	return flags, targets
+}
+
+func gateRuleMatchesBranches(rule *config.CommandGateRule, targets []string, defaultBranch func() string) bool {
+	for _, b := range rule.Branches {
+		if b == config.GateBranchDefault {
+			b = defaultBranch()
+		}
+		for _, t := range targets {
+			if b == t {
+				return true
+			}
+		}
+	}
+	return false
+}
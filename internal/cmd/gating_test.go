@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/ratelimit"
+)
+
+func TestEvaluateCommandGate(t *testing.T) {
+	cfg := config.NewCommandGatingConfig()
+	cfg.Rules = append(cfg.Rules, config.CommandGateRule{
+		Roles:   []string{"refinery"},
+		Command: "polecat nuke",
+		Flags:   []string{"--force"},
+		Action:  config.GateActionBlock,
+	})
+
+	tests := []struct {
+		name       string
+		role       string
+		path       string
+		flags      map[string]bool
+		wantAction string
+	}{
+		{"polecat rig remove blocked", "polecat", "gt rig remove", nil, config.GateActionBlock},
+		{"polecat rig list allowed", "polecat", "gt rig list", nil, ""},
+		{"polecat rig status allowed", "polecat", "gt rig status", nil, ""},
+		{"polecat rig config show allowed", "polecat", "gt rig config show", nil, ""},
+		{"polecat rig config set blocked", "polecat", "gt rig config set", nil, config.GateActionBlock},
+		{"polecat rig add blocked", "polecat", "gt rig add", nil, config.GateActionBlock},
+		{"polecat rig doctor allowed", "polecat", "gt rig doctor", nil, ""},
+		{"polecat rig doctor --fix blocked", "polecat", "gt rig doctor", map[string]bool{"fix": true}, config.GateActionBlock},
+		{"polecat done allowed", "polecat", "gt done", nil, ""},
+		{"crew rig remove warns", "crew", "gt rig remove", nil, config.GateActionWarn},
+		{"refinery rig remove blocked", "refinery", "gt rig remove", nil, config.GateActionBlock},
+		{"whole-word match only", "polecat", "gt rigs", nil, ""},
+		{"flag rule not set", "refinery", "gt polecat nuke", nil, ""},
+		{"flag rule set", "refinery", "gt polecat nuke", map[string]bool{"force": true}, config.GateActionBlock},
+		{"witness down blocked", "witness", "gt down", nil, config.GateActionBlock},
+		{"deacon down allowed", "deacon", "gt down", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateCommandGate(cfg, tt.role, tt.path, tt.flags)
+			if got.Action != tt.wantAction {
+				t.Errorf("evaluateCommandGate(%q, %q) action = %q, want %q", tt.role, tt.path, got.Action, tt.wantAction)
+			}
+		})
+	}
+}
+
+func TestEvaluateCommandGate_Wildcard(t *testing.T) {
+	cfg := &config.CommandGatingConfig{
+		Rules: []config.CommandGateRule{
+			{Roles: []string{"*"}, Command: "town", Action: config.GateActionWarn},
+		},
+	}
+	if got := evaluateCommandGate(cfg, "boot", "gt town next", nil); got.Action != config.GateActionWarn {
+		t.Errorf("wildcard role: action = %q, want %q", got.Action, config.GateActionWarn)
+	}
+}
+
+func TestObserverMayRun(t *testing.T) {
+	tests := []struct {
+		path  string
+		flags map[string]bool
+		want  bool
+	}{
+		{"status", nil, true},
+		{"mq list", nil, true},
+		{"mail inbox", nil, true},
+		{"doctor", nil, true},
+		{"doctor", map[string]bool{"fix": true}, false},
+		{"completion bash", nil, true},
+		{"mail send", nil, false},
+		{"mq submit", nil, false},
+		{"webhooks test", nil, false},
+		{"rig", nil, false},
+		{"sling", nil, false},
+		{"done", nil, false},
+	}
+	for _, tt := range tests {
+		if got := observerMayRun(tt.path, tt.flags); got != tt.want {
+			t.Errorf("observerMayRun(%q, %v) = %v, want %v", tt.path, tt.flags, got, tt.want)
+		}
+	}
+}
+
+// Every command on the observer allowlist must exist, so a rename can't
+// quietly drop a command from it.
+func TestObserverCommandsExist(t *testing.T) {
+	for path := range observerCommands {
+		if path == "" {
+			continue
+		}
+		found, _, err := rootCmd.Find(strings.Fields(path))
+		if err != nil || buildCommandPath(found) != "gt "+path {
+			t.Errorf("observer command %q not found", path)
+		}
+	}
+}
+
+// Default rules for gt commands must name commands that exist, so a rename
+// can't quietly ungate one.
+func TestDefaultGateRulesNameCommands(t *testing.T) {
+	for _, rule := range config.NewCommandGatingConfig().Rules {
+		if strings.HasPrefix(rule.Command, "git ") {
+			continue
+		}
+		found, _, err := rootCmd.Find(strings.Fields(rule.Command))
+		if err != nil || buildCommandPath(found) != "gt "+rule.Command {
+			t.Errorf("gated command %q not found", rule.Command)
+		}
+	}
+}
+
+func TestEvaluateGitGate_RefineryForcePush(t *testing.T) {
+	cfg := config.NewCommandGatingConfig()
+
+	tests := []struct {
+		name       string
+		role       string
+		command    string
+		branch     string // current branch, for pushes without a refspec
+		wantAction string
+	}{
+		{"force push to main blocked", "refinery", "git push --force origin main", "", config.GateActionBlock},
+		{"short force flag blocked", "refinery", "git push -f origin main", "", config.GateActionBlock},
+		{"plus refspec blocked", "refinery", "git push origin +main", "", config.GateActionBlock},
+		{"lease to refs/heads/main blocked", "refinery", "git push --force-with-lease=main origin HEAD:refs/heads/main", "", config.GateActionBlock},
+		{"force push current branch blocked", "refinery", "git push --force", "main", config.GateActionBlock},
+		{"chained command blocked", "refinery", "git fetch && git push -f origin main", "", config.GateActionBlock},
+		{"fast-forward push allowed", "refinery", "git push origin main", "", ""},
+		{"force push to feature branch allowed", "refinery", "git push --force-with-lease origin polecat/toast", "", ""},
+		{"force push current feature branch allowed", "refinery", "git push -f", "polecat/toast", ""},
+		{"other roles not matched", "witness", "git push --force origin main", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got gateDecision
+			for _, inv := range ratelimit.ParseGit(tt.command) {
+				if d := evaluateGitGate(cfg, tt.role, inv, func() string { return tt.branch }, func() string { return "main" }); d.Rule != nil {
+					got = d
+					break
+				}
+			}
+			if got.Action != tt.wantAction {
+				t.Errorf("evaluateGitGate(%q, %q) action = %q, want %q", tt.role, tt.command, got.Action, tt.wantAction)
+			}
+		})
+	}
+}
+
+func TestEvaluateGitGate_RigDefaultBranch(t *testing.T) {
+	// The force-push rule protects whatever the rig's default branch is
+	cfg := config.NewCommandGatingConfig()
+	develop := func() string { return "develop" }
+	noBranch := func() string { return "" }
+
+	for command, want := range map[string]string{
+		"git push -f origin develop": config.GateActionBlock,
+		"git push -f origin main":    "",
+	} {
+		inv := ratelimit.ParseGit(command)[0]
+		if got := evaluateGitGate(cfg, "refinery", inv, noBranch, develop).Action; got != want {
+			t.Errorf("%q on a develop rig: action = %q, want %q", command, got, want)
+		}
+	}
+}
+
+func TestGitPushArgs(t *testing.T) {
+	flags, targets := gitPushArgs(strings.Fields("-o ci.skip --force-with-lease=main origin +HEAD:refs/heads/main feature"))
+	for _, f := range []string{"force-with-lease", "force"} {
+		if !flags[f] {
+			t.Errorf("flags missing %q: %v", f, flags)
+		}
+	}
+	if strings.Join(targets, ",") != "main,feature" {
+		t.Errorf("targets = %v, want [main feature]", targets)
+	}
+}
@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/lease"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	leaseJSON     bool
+	leaseHolder   string
+	leaseCapacity int
+	leaseTTL      time.Duration
+	leaseWait     bool
+	leaseForce    bool
+)
+
+var leaseCmd = &cobra.Command{
+	Use:     "lease",
+	GroupID: GroupServices,
+	Short:   "Take turns on shared resources with named leases",
+	Long: `Serialize access to shared resources with named leases.
+
+A lease is a named slot with a capacity (default 1). Holders acquire it
+and release it when done; agents that find it full can queue with --wait,
+and are handed the lease in order as it frees up. A lease with a TTL
+lapses unless its holder renews it, so a crashed agent can't hold a
+resource forever.
+
+Each rig's merge slot is the lease <rig>/merge-slot: the Refinery takes it
+before creating a conflict-resolution task, serializing conflict
+resolution in the rig. Other resources, such as deploy locks, can use
+leases of their own.
+
+Examples:
+  gt lease list
+  gt lease acquire deploy --ttl 30m       # Fail if someone else holds it
+  gt lease acquire gastown/merge-slot --wait
+  gt lease renew deploy
+  gt lease release deploy
+  gt lease release deploy --force         # Drop every hold (operator)`,
+	RunE: requireSubcommand,
+}
+
+var leaseListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List leases and their holders",
+	Args:  cobra.NoArgs,
+	RunE:  runLeaseList,
+}
+
+var leaseShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a lease's holders and waiters",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runLeaseShow,
+}
+
+var leaseAcquireCmd = &cobra.Command{
+	Use:   "acquire <name>",
+	Short: "Acquire a lease",
+	Long: `Acquire a lease, creating it if needed. Acquiring a lease you already
+hold renews it.
+
+If the lease is full, acquire fails, or with --wait queues you as a waiter
+and exits successfully; the lease is handed to you when it frees up (check
+with gt lease show). --capacity and --ttl change the lease's settings for
+everyone.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLeaseAcquire,
+}
+
+var leaseRenewCmd = &cobra.Command{
+	Use:   "renew <name>",
+	Short: "Extend your hold on a lease by its TTL",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runLeaseRenew,
+}
+
+var leaseReleaseCmd = &cobra.Command{
+	Use:   "release <name>",
+	Short: "Release a lease",
+	Long: `Release your hold on a lease, or your place in its queue. The next
+waiter gets the lease.
+
+With --force, release someone else's hold: the --holder given, or every
+hold on the lease. Use it when a holder is gone for good.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLeaseRelease,
+}
+
+func init() {
+	leaseListCmd.Flags().BoolVar(&leaseJSON, "json", false, "Output as JSON")
+	leaseShowCmd.Flags().BoolVar(&leaseJSON, "json", false, "Output as JSON")
+	leaseAcquireCmd.Flags().BoolVar(&leaseJSON, "json", false, "Output as JSON")
+
+	for _, c := range []*cobra.Command{leaseAcquireCmd, leaseRenewCmd, leaseReleaseCmd} {
+		c.Flags().StringVar(&leaseHolder, "holder", "", "Holder identity (default: your agent identity)")
+	}
+	leaseAcquireCmd.Flags().IntVar(&leaseCapacity, "capacity", 0, "Set the number of concurrent holders")
+	leaseAcquireCmd.Flags().DurationVar(&leaseTTL, "ttl", 0, "Set how long holds last without renewal (e.g. 30m)")
+	leaseAcquireCmd.Flags().BoolVar(&leaseWait, "wait", false, "Queue as a waiter if the lease is full")
+	leaseReleaseCmd.Flags().BoolVar(&leaseForce, "force", false, "Release other holders' holds")
+
+	leaseCmd.AddCommand(leaseListCmd)
+	leaseCmd.AddCommand(leaseShowCmd)
+	leaseCmd.AddCommand(leaseAcquireCmd)
+	leaseCmd.AddCommand(leaseRenewCmd)
+	leaseCmd.AddCommand(leaseReleaseCmd)
+	rootCmd.AddCommand(leaseCmd)
+}
+
+func leaseStore() (*lease.Store, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	return lease.NewStore(townRoot), nil
+}
+
+// leaseHolderOrSelf returns --holder, defaulting to the caller's identity.
+func leaseHolderOrSelf() string {
+	if leaseHolder != "" {
+		return leaseHolder
+	}
+	return detectSender()
+}
+
+func runLeaseList(cmd *cobra.Command, args []string) error {
+	store, err := leaseStore()
+	if err != nil {
+		return err
+	}
+	leases, err := store.List()
+	if err != nil {
+		return err
+	}
+	if leaseJSON {
+		return outputJSON(leases)
+	}
+	if len(leases) == 0 {
+		fmt.Printf("%s No leases\n", style.Dim.Render("○"))
+		return nil
+	}
+	for _, l := range leases {
+		printLease(l)
+	}
+	return nil
+}
+
+func runLeaseShow(cmd *cobra.Command, args []string) error {
+	store, err := leaseStore()
+	if err != nil {
+		return err
+	}
+	l, err := store.Get(args[0])
+	if err != nil {
+		return err
+	}
+	if leaseJSON {
+		return outputJSON(l)
+	}
+	printLease(l)
+	return nil
+}
+
+func runLeaseAcquire(cmd *cobra.Command, args []string) error {
+	store, err := leaseStore()
+	if err != nil {
+		return err
+	}
+	holder := leaseHolderOrSelf()
+	l, err := store.Acquire(args[0], holder, lease.AcquireOptions{
+		Capacity: leaseCapacity,
+		TTL:      leaseTTL,
+		Wait:     leaseWait,
+	})
+	held := errors.Is(err, lease.ErrHeld)
+	if err != nil && !held {
+		return err
+	}
+	if leaseJSON {
+		if err := outputJSON(l); err != nil {
+			return err
+		}
+	} else if !held {
+		fmt.Printf("%s Acquired %s as %s%s\n", style.Bold.Render("✓"), l.Name, holder, leaseExpiry(l.Hold(holder)))
+	} else if pos := l.Waiting(holder); pos > 0 {
+		fmt.Printf("%s %s is held by %s; queued as waiter #%d\n",
+			style.Dim.Render("○"), l.Name, leaseHolderNames(l), pos)
+	}
+	if held && !leaseWait {
+		return fmt.Errorf("%s is held by %s", l.Name, leaseHolderNames(l))
+	}
+	return nil
+}
+
+func runLeaseRenew(cmd *cobra.Command, args []string) error {
+	store, err := leaseStore()
+	if err != nil {
+		return err
+	}
+	holder := leaseHolderOrSelf()
+	l, err := store.Renew(args[0], holder)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Renewed %s%s\n", style.Bold.Render("✓"), l.Name, leaseExpiry(l.Hold(holder)))
+	return nil
+}
+
+func runLeaseRelease(cmd *cobra.Command, args []string) error {
+	store, err := leaseStore()
+	if err != nil {
+		return err
+	}
+
+	if leaseForce {
+		dropped, err := store.ForceRelease(args[0], leaseHolder)
+		if err != nil {
+			return err
+		}
+		if len(dropped) == 0 {
+			fmt.Printf("%s %s has no holders\n", style.Dim.Render("○"), args[0])
+			return nil
+		}
+		fmt.Printf("%s Force-released %s from %s\n", style.Bold.Render("✓"), args[0], strings.Join(dropped, ", "))
+		return nil
+	}
+
+	holder := leaseHolderOrSelf()
+	l, err := store.Release(args[0], holder)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Released %s\n", style.Bold.Render("✓"), l.Name)
+	if len(l.Holders) > 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("now held by "+leaseHolderNames(l)))
+	}
+	return nil
+}
+
+func printLease(l *lease.Lease) {
+	mark := style.Dim.Render("○")
+	if !l.Available() {
+		mark = style.Warning.Render("●")
+	}
+	fmt.Printf("%s %s %s\n", mark, style.Bold.Render(l.Name),
+		style.Dim.Render(fmt.Sprintf("(%d/%d held%s)", len(l.Holders), l.Capacity, leaseTTLNote(l))))
+	for _, h := range l.Holders {
+		fmt.Printf("    %s since %s%s\n", h.Holder, h.Acquired.Local().Format("15:04"), leaseExpiry(&h))
+	}
+	for i, w := range l.Waiters {
+		fmt.Printf("    %s\n", style.Dim.Render(fmt.Sprintf("waiting #%d: %s", i+1, w)))
+	}
+}
+
+func leaseTTLNote(l *lease.Lease) string {
+	if l.TTL == 0 {
+		return ""
+	}
+	return ", ttl " + l.TTL.String()
+}
+
+func leaseExpiry(h *lease.Hold) string {
+	if h == nil || h.Expires.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(" (until %s)", h.Expires.Local().Format("15:04"))
+}
+
+func leaseHolderNames(l *lease.Lease) string {
+	var names []string
+	for _, h := range l.Holders {
+		names = append(names, h.Holder)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/lock"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Locks command flags
+var (
+	locksJSON         bool
+	locksReleaseForce bool
+)
+
+var locksCmd = &cobra.Command{
+	Use:     "locks",
+	GroupID: GroupAgents,
+	Short:   "Inspect and release worker identity locks",
+	RunE:    requireSubcommand,
+	Long: `Inspect and release worker identity locks.
+
+Each polecat and crew worker holds a lease-based identity lock
+(<worker>/.runtime/agent.lock) while an agent runs in it. Active agents
+renew their lease with every gt command; a lease that runs out can be
+claimed by the next agent.
+
+Workers are named rig/polecat or rig/crew/name.
+
+Commands:
+  gt locks list               List all locks in the town
+  gt locks show <worker>      Show lock details for a worker
+  gt locks release <worker>   Release a worker's lock`,
+}
+
+var locksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all locks in the town",
+	Long: `List all worker identity locks in the town with their state.
+
+States:
+  active    Lease is valid; the worker is in use
+  expired   Lease ran out; the next agent may claim it
+  dead      The agent's tmux pane (or, for locks predating leases,
+            the writing process) is gone
+
+Examples:
+  gt locks list
+  gt locks list --json`,
+	RunE: runLocksList,
+}
+
+var locksShowCmd = &cobra.Command{
+	Use:   "show <worker>",
+	Short: "Show lock details for a worker",
+	Long: `Show the holder, lease, and state of a worker's identity lock.
+
+Examples:
+  gt locks show gastown/toast
+  gt locks show gastown/crew/max`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLocksShow,
+}
+
+var locksReleaseCmd = &cobra.Command{
+	Use:   "release <worker>",
+	Short: "Release a worker's lock",
+	Long: `Release a worker's identity lock.
+
+Expired and dead locks are released directly. Releasing an active lock
+requires --force, since the agent holding it is likely still running.
+
+Examples:
+  gt locks release gastown/toast
+  gt locks release gastown/toast --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLocksRelease,
+}
+
+// LockListItem represents a lock in list output.
+type LockListItem struct {
+	Worker    string `json:"worker"`
+	WorkerDir string `json:"worker_dir"`
+	State     string `json:"state"`
+	*lock.LockInfo
+}
+
+func init() {
+	locksListCmd.Flags().BoolVar(&locksJSON, "json", false, "Output as JSON")
+	locksShowCmd.Flags().BoolVar(&locksJSON, "json", false, "Output as JSON")
+	locksReleaseCmd.Flags().BoolVarP(&locksReleaseForce, "force", "f", false, "Release even if the lease is active")
+
+	locksCmd.AddCommand(locksListCmd)
+	locksCmd.AddCommand(locksShowCmd)
+	locksCmd.AddCommand(locksReleaseCmd)
+
+	rootCmd.AddCommand(locksCmd)
+}
+
+// findTownLocks returns every identity lock in the town, sorted by worker.
+func findTownLocks(townRoot string) ([]LockListItem, error) {
+	locks, err := lock.FindAllLocks(townRoot)
+	if err != nil {
+		return nil, fmt.Errorf("finding locks: %w", err)
+	}
+
+	items := make([]LockListItem, 0, len(locks))
+	for workerDir, info := range locks {
+		items = append(items, LockListItem{
+			Worker:    lockWorkerName(townRoot, workerDir),
+			WorkerDir: workerDir,
+			State:     info.State(),
+			LockInfo:  info,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Worker < items[j].Worker })
+	return items, nil
+}
+
+// lockWorkerName names a locked worker directory: rig/polecat, rig/crew/name,
+// or the path relative to the town root for other layouts.
+func lockWorkerName(townRoot, workerDir string) string {
+	rel, err := filepath.Rel(townRoot, workerDir)
+	if err != nil {
+		return workerDir
+	}
+	rel = filepath.ToSlash(rel)
+	parts := strings.Split(rel, "/")
+	if len(parts) >= 3 {
+		switch parts[1] {
+		case "polecats":
+			return parts[0] + "/" + parts[2]
+		case "crew":
+			return parts[0] + "/crew/" + parts[2]
+		}
+	}
+	return rel
+}
+
+// findTownLock resolves a worker name or directory to its lock.
+func findTownLock(townRoot, worker string) (*LockListItem, error) {
+	items, err := findTownLocks(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	abs, _ := filepath.Abs(worker)
+	for i := range items {
+		item := &items[i]
+		if item.Worker == worker || item.WorkerDir == abs ||
+			filepath.ToSlash(strings.TrimPrefix(item.WorkerDir, townRoot+string(filepath.Separator))) == worker {
+			return item, nil
+		}
+	}
+	return nil, fmt.Errorf("no lock found for %s (see gt locks list)", worker)
+}
+
+func runLocksList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	items, err := findTownLocks(townRoot)
+	if err != nil {
+		return err
+	}
+
+	if locksJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	}
+
+	if len(items) == 0 {
+		fmt.Println("No identity locks held.")
+		return nil
+	}
+
+	fmt.Printf("%-28s %-8s %-8s %-24s %s\n", "WORKER", "STATE", "AGENT", "SESSION", "LEASE")
+	for _, item := range items {
+		// Pad before styling so escape codes don't break column alignment
+		fmt.Printf("%-28s %s %-8s %-24s %s\n",
+			item.Worker, renderLockState(fmt.Sprintf("%-8s", item.State)), valueOr(item.Agent, "-"),
+			valueOr(item.SessionID, "-"), describeLease(item.LockInfo))
+	}
+
+	return nil
+}
+
+func runLocksShow(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	item, err := findTownLock(townRoot, args[0])
+	if err != nil {
+		return err
+	}
+
+	if locksJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(item)
+	}
+
+	info := item.LockInfo
+	fmt.Printf("%s %s\n", style.Bold.Render(item.Worker), renderLockState(item.State))
+	fmt.Printf("  Dir:      %s\n", item.WorkerDir)
+	fmt.Printf("  Role:     %s\n", valueOr(info.Role, "-"))
+	fmt.Printf("  Rig:      %s\n", valueOr(info.Rig, "-"))
+	fmt.Printf("  Agent:    %s\n", valueOr(info.Agent, "-"))
+	fmt.Printf("  Command:  %s\n", valueOr(info.Command, "-"))
+	fmt.Printf("  Session:  %s\n", valueOr(info.SessionID, "-"))
+	fmt.Printf("  Host:     %s (PID %d)\n", valueOr(info.Hostname, "-"), info.PID)
+	if info.PanePID > 0 {
+		fmt.Printf("  Pane PID: %d\n", info.PanePID)
+	}
+	fmt.Printf("  Acquired: %s\n", info.AcquiredAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("  Lease:    %s\n", describeLease(info))
+
+	if item.State != lock.StateActive {
+		fmt.Printf("\nThis lock is claimable. Release it with: gt locks release %s\n", item.Worker)
+	}
+	return nil
+}
+
+func runLocksRelease(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	item, err := findTownLock(townRoot, args[0])
+	if err != nil {
+		return err
+	}
+
+	l := lock.New(item.WorkerDir)
+	if locksReleaseForce {
+		if err := l.ForceRelease(); err != nil {
+			return err
+		}
+		fmt.Printf("%s Released lock for %s\n", style.Bold.Render("✓"), item.Worker)
+		return nil
+	}
+
+	released, err := l.ReleaseIfStale()
+	if err != nil {
+		return err
+	}
+	if !released {
+		return fmt.Errorf("%s is held by an active lease (session %s, %s); use --force to release anyway",
+			item.Worker, valueOr(item.SessionID, "unknown"), describeLease(item.LockInfo))
+	}
+	fmt.Printf("%s Released %s lock for %s\n", style.Bold.Render("✓"), item.State, item.Worker)
+	return nil
+}
+
+// renderLockState colors a (possibly padded) lock state for terminal output.
+func renderLockState(state string) string {
+	if strings.TrimSpace(state) == lock.StateActive {
+		return style.Success.Render(state)
+	}
+	return style.Warning.Render(state)
+}
+
+// describeLease summarizes when a lock's lease expires or expired.
+func describeLease(info *lock.LockInfo) string {
+	if info.ExpiresAt.IsZero() {
+		return "no lease (legacy lock)"
+	}
+	remaining := time.Until(info.ExpiresAt).Round(time.Second)
+	if remaining > 0 {
+		return fmt.Sprintf("expires in %s", remaining)
+	}
+	return fmt.Sprintf("expired %s ago", -remaining)
+}
+
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/lock"
+)
+
+func TestLockWorkerName(t *testing.T) {
+	town := filepath.Join(string(filepath.Separator), "town")
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{filepath.Join(town, "gastown", "polecats", "toast"), "gastown/toast"},
+		{filepath.Join(town, "gastown", "polecats", "toast", "gastown"), "gastown/toast"},
+		{filepath.Join(town, "gastown", "crew", "max"), "gastown/crew/max"},
+		{filepath.Join(town, "gastown", "other"), "gastown/other"},
+	}
+	for _, tt := range tests {
+		if got := lockWorkerName(town, tt.dir); got != tt.want {
+			t.Errorf("lockWorkerName(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestFindTownLock(t *testing.T) {
+	town := t.TempDir()
+	workerDir := filepath.Join(town, "gastown", "crew", "max")
+	if err := os.MkdirAll(workerDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.New(workerDir).Acquire("gt-gastown-crew-max"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"gastown/crew/max", workerDir} {
+		item, err := findTownLock(town, name)
+		if err != nil {
+			t.Fatalf("findTownLock(%q) error = %v", name, err)
+		}
+		if item.WorkerDir != workerDir || item.State != lock.StateActive {
+			t.Errorf("findTownLock(%q) = %s (%s)", name, item.WorkerDir, item.State)
+		}
+	}
+	if _, err := findTownLock(town, "gastown/toast"); err == nil {
+		t.Error("expected error for worker without a lock")
+	}
+}
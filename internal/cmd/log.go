@@ -42,13 +42,22 @@ Events logged include:
   crash   - agent exited unexpectedly
   kill    - agent killed intentionally
 
+Identity lock events (with holder session, PID, and host):
+  lock_acquired   - worker identity lock acquired
+  lock_released   - lock released
+  lock_stale      - expired or dead lock cleaned up or taken over
+  lock_collision  - claim refused because another agent holds the lease
+
 Examples:
   gt log                     # Show last 20 events
   gt log -n 50               # Show last 50 events
   gt log --type spawn        # Show only spawn events
   gt log --agent greenplace/    # Show events for gastown rig
   gt log --since 1h          # Show events from last hour
-  gt log -f                  # Follow log (like tail -f)`,
+  gt log -f                  # Follow log (like tail -f)
+
+Use 'gt log query' to search the town log and events log together with
+actor, event type, and time range filters.`,
 	RunE: runLog,
 }
 
@@ -71,7 +80,7 @@ Examples:
 
 func init() {
 	logCmd.Flags().IntVarP(&logTail, "tail", "n", 20, "Number of events to show")
-	logCmd.Flags().StringVarP(&logType, "type", "t", "", "Filter by event type (spawn,wake,nudge,handoff,done,crash,kill)")
+	logCmd.Flags().StringVarP(&logType, "type", "t", "", "Filter by event type (spawn,wake,nudge,handoff,done,crash,kill,lock_collision,...)")
 	logCmd.Flags().StringVarP(&logAgent, "agent", "a", "", "Filter by agent prefix (e.g., gastown/, greenplace/crew/max)")
 	logCmd.Flags().StringVar(&logSince, "since", "", "Show events since duration (e.g., 1h, 30m, 24h)")
 	logCmd.Flags().BoolVarP(&logFollow, "follow", "f", false, "Follow log output (like tail -f)")
@@ -99,13 +108,7 @@ func runLog(cmd *cobra.Command, args []string) error {
 		return followLog(logPath)
 	}
 
-	// Check if log file exists
-	if _, err := os.Stat(logPath); os.IsNotExist(err) {
-		fmt.Printf("%s No log file yet (no events recorded)\n", style.Dim.Render("○"))
-		return nil
-	}
-
-	// Read events
+	// Read events (including rotated segments)
 	events, err := townlog.ReadEvents(townRoot)
 	if err != nil {
 		return fmt.Errorf("reading events: %w", err)
@@ -211,6 +214,12 @@ func printEvent(e townlog.Event) {
 		typeStr = style.Error.Render("[escalation_sent]")
 	case townlog.EventPatrolComplete:
 		typeStr = style.Success.Render("[patrol_complete]")
+	case townlog.EventLockAcquired, townlog.EventLockReleased:
+		typeStr = style.Dim.Render(fmt.Sprintf("[%s]", e.Type))
+	case townlog.EventLockStale:
+		typeStr = style.Warning.Render("[lock_stale]")
+	case townlog.EventLockCollision:
+		typeStr = style.Error.Render("[lock_collision]")
 	default:
 		typeStr = fmt.Sprintf("[%s]", e.Type)
 	}
@@ -287,6 +296,26 @@ func formatEventDetail(e townlog.Event) string {
 			return fmt.Sprintf("patrol complete (%s)", e.Context)
 		}
 		return "patrol complete"
+	case townlog.EventLockAcquired:
+		if e.Context != "" {
+			return fmt.Sprintf("acquired identity lock (%s)", e.Context)
+		}
+		return "acquired identity lock"
+	case townlog.EventLockReleased:
+		if e.Context != "" {
+			return fmt.Sprintf("released identity lock (%s)", e.Context)
+		}
+		return "released identity lock"
+	case townlog.EventLockStale:
+		if e.Context != "" {
+			return fmt.Sprintf("cleaned up stale identity lock (%s)", e.Context)
+		}
+		return "cleaned up stale identity lock"
+	case townlog.EventLockCollision:
+		if e.Context != "" {
+			return fmt.Sprintf("IDENTITY COLLISION (%s)", e.Context)
+		}
+		return "IDENTITY COLLISION"
 	default:
 		if e.Context != "" {
 			return fmt.Sprintf("%s (%s)", e.Type, e.Context)
@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/logrotate"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/townlog"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Log query flags
+var (
+	logQuerySince  string
+	logQueryUntil  string
+	logQueryActor  []string
+	logQueryEvent  []string
+	logQueryRig    string
+	logQuerySource string
+	logQueryGrep   string
+	logQueryLimit  int
+	logQueryJSON   bool
+)
+
+var logQueryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Search the town log and events log with filters",
+	Long: `Search the town's operational logs without grepping raw files.
+
+Queries the town's unified events log (.events.jsonl), which holds every
+subsystem's events in one envelope format. Events have a source:
+  townlog - agent lifecycle events (also shown by gt log)
+  events  - activity events: merges, slings, mail, patrols
+
+Town log history from before the unified log is read from logs/town.log.
+Results are a single timeline, oldest first.
+
+Time bounds accept a duration before now (30m, 2h, 7d), an RFC3339
+timestamp, or a local date/time (2026-01-15, "2026-01-15 14:00").
+
+--actor matches an actor prefix and --event an exact event type; both may
+be repeated or given as comma-separated lists.
+
+Examples:
+  gt log query --since 2h --actor gastown/refinery --event merge_failed
+  gt log query --rig gastown --since 1d --event crash,session_death
+  gt log query --since 2026-01-15 --until 2026-01-16 --source events
+  gt log query --grep gt-abc --json | jq '.[].type'`,
+	RunE: runLogQuery,
+}
+
+func init() {
+	logQueryCmd.Flags().StringVar(&logQuerySince, "since", "", "Only events at or after this time (duration, RFC3339, or date)")
+	logQueryCmd.Flags().StringVar(&logQueryUntil, "until", "", "Only events before this time (duration, RFC3339, or date)")
+	logQueryCmd.Flags().StringSliceVar(&logQueryActor, "actor", nil, "Filter by actor prefix (e.g., gastown/refinery, gastown/polecats/)")
+	logQueryCmd.Flags().StringSliceVar(&logQueryEvent, "event", nil, "Filter by event type (e.g., merge_failed, crash)")
+	logQueryCmd.Flags().StringVar(&logQueryRig, "rig", "", "Filter by rig (actors under <rig>/)")
+	logQueryCmd.Flags().StringVar(&logQuerySource, "source", "", "Only show events from one source: townlog or events")
+	logQueryCmd.Flags().StringVar(&logQueryGrep, "grep", "", "Filter by case-insensitive substring of the event detail")
+	logQueryCmd.Flags().IntVarP(&logQueryLimit, "limit", "n", 0, "Show only the last N matching events (0 for all)")
+	logQueryCmd.Flags().BoolVar(&logQueryJSON, "json", false, "Output as JSON")
+
+	logCmd.AddCommand(logQueryCmd)
+}
+
+// LogRecord is one event returned by gt log query.
+type LogRecord struct {
+	Timestamp time.Time              `json:"timestamp"`
+	Source    string                 `json:"source"` // "townlog" or "events"
+	Type      string                 `json:"type"`
+	Actor     string                 `json:"actor"`
+	Rig       string                 `json:"rig,omitempty"`
+	Subject   string                 `json:"subject,omitempty"`
+	TraceID   string                 `json:"trace_id,omitempty"`
+	Detail    string                 `json:"detail,omitempty"`
+	Payload   map[string]interface{} `json:"payload,omitempty"`
+}
+
+// logQuery holds the filters for gt log query.
+type logQuery struct {
+	Since  time.Time
+	Until  time.Time
+	Actors []string
+	Events []string
+	Rig    string
+	Source string
+	Grep   string
+}
+
+// matches reports whether rec passes every filter in q.
+func (q logQuery) matches(rec LogRecord) bool {
+	if !q.Since.IsZero() && rec.Timestamp.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !rec.Timestamp.Before(q.Until) {
+		return false
+	}
+	if q.Rig != "" && rec.Rig != q.Rig {
+		return false
+	}
+	if q.Source != "" && rec.Source != q.Source {
+		return false
+	}
+	if len(q.Actors) > 0 {
+		found := false
+		for _, a := range q.Actors {
+			if strings.HasPrefix(rec.Actor, a) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(q.Events) > 0 {
+		found := false
+		for _, e := range q.Events {
+			if rec.Type == e {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if q.Grep != "" && !strings.Contains(strings.ToLower(rec.Detail), strings.ToLower(q.Grep)) {
+		return false
+	}
+	return true
+}
+
+func runLogQuery(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	now := time.Now()
+	q := logQuery{
+		Actors: logQueryActor,
+		Events: logQueryEvent,
+		Rig:    strings.TrimSuffix(logQueryRig, "/"),
+		Source: logQuerySource,
+		Grep:   logQueryGrep,
+	}
+	if logQuerySince != "" {
+		if q.Since, err = parseLogTime(logQuerySince, now); err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if logQueryUntil != "" {
+		if q.Until, err = parseLogTime(logQueryUntil, now); err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+
+	switch logQuerySource {
+	case "", "townlog", "events":
+	default:
+		return fmt.Errorf("invalid --source %q: must be townlog or events", logQuerySource)
+	}
+
+	records, err := readEventRecords(filepath.Join(townRoot, events.EventsFile))
+	if err != nil {
+		return fmt.Errorf("reading events log: %w", err)
+	}
+	legacy, err := readTownlogRecords(townRoot)
+	if err != nil {
+		return fmt.Errorf("reading town log: %w", err)
+	}
+	records = append(records, legacyTownlogRecords(legacy, records)...)
+
+	records = queryLogRecords(records, q, logQueryLimit)
+
+	if logQueryJSON {
+		if records == nil {
+			records = []LogRecord{}
+		}
+		data, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(records) == 0 {
+		fmt.Printf("%s No events match query\n", style.Dim.Render("○"))
+		return nil
+	}
+	for _, rec := range records {
+		fmt.Printf("%s %s %s %s\n",
+			style.Dim.Render(rec.Timestamp.Local().Format("2006-01-02 15:04:05")),
+			fmt.Sprintf("[%s]", rec.Type), rec.Actor, rec.Detail)
+	}
+	return nil
+}
+
+// queryLogRecords filters records, sorts them oldest first, and keeps the
+// last limit matches (all if limit <= 0).
+func queryLogRecords(records []LogRecord, q logQuery, limit int) []LogRecord {
+	var result []LogRecord
+	for _, rec := range records {
+		if q.matches(rec) {
+			result = append(result, rec)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[len(result)-limit:]
+	}
+	return result
+}
+
+// parseLogTime parses a query time bound: a duration before now (with d for
+// days), an RFC3339 timestamp, or a local date or date/time.
+func parseLogTime(s string, now time.Time) (time.Time, error) {
+	if d, err := parseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a duration, RFC3339 timestamp, or date", s)
+}
+
+// legacyTownlogRecords returns the town log records that predate the town
+// log's first event in the unified events log; later ones are duplicates.
+func legacyTownlogRecords(townlogRecs, eventRecs []LogRecord) []LogRecord {
+	var first time.Time
+	for _, rec := range eventRecs {
+		if rec.Source == townlog.Source && (first.IsZero() || rec.Timestamp.Before(first)) {
+			first = rec.Timestamp
+		}
+	}
+	if first.IsZero() {
+		return townlogRecs
+	}
+	var result []LogRecord
+	for _, rec := range townlogRecs {
+		if rec.Timestamp.Before(first) {
+			result = append(result, rec)
+		}
+	}
+	return result
+}
+
+// readTownlogRecords reads the town log as query records.
+func readTownlogRecords(townRoot string) ([]LogRecord, error) {
+	evts, err := townlog.ReadEvents(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]LogRecord, 0, len(evts))
+	for _, e := range evts {
+		records = append(records, LogRecord{
+			Timestamp: e.Timestamp,
+			Source:    townlog.Source,
+			Type:      string(e.Type),
+			Actor:     e.Agent,
+			Rig:       events.RigFromActor(e.Agent),
+			Detail:    e.Detail,
+		})
+	}
+	return records, nil
+}
+
+// readEventRecords reads an events log (.events.jsonl) as query records.
+// Rotated segments are included; a missing log yields no records.
+func readEventRecords(path string) ([]LogRecord, error) {
+	f, err := logrotate.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []LogRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		e, err := events.Parse(scanner.Bytes())
+		if err != nil {
+			continue // Skip malformed lines
+		}
+		ts := e.Time()
+		if ts.IsZero() {
+			continue
+		}
+		rec := LogRecord{
+			Timestamp: ts,
+			Source:    "events",
+			Type:      e.Type,
+			Actor:     e.Actor,
+			Rig:       e.Rig,
+			Subject:   e.Subject,
+			TraceID:   e.TraceID,
+			Detail:    formatFeedSummary(e),
+			Payload:   e.Payload,
+		}
+		if e.Source == townlog.Source {
+			rec.Source = townlog.Source
+			rec.Detail, _ = e.Payload["detail"].(string)
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/townlog"
+)
+
+func TestLogQuery_MergesAndFiltersBothLogs(t *testing.T) {
+	townRoot := t.TempDir()
+	now := time.Now().Truncate(time.Second)
+
+	// A town log entry from before town log events were mirrored to the
+	// events log, followed by one that is mirrored
+	if err := os.MkdirAll(filepath.Join(townRoot, "logs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	legacy := now.Add(-3*time.Hour).Format("2006-01-02 15:04:05") + " [crash] gastown/polecats/toast crashed (exit code 1)\n"
+	if err := os.WriteFile(townlog.LogPath(townRoot), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logger := townlog.NewLogger(townRoot)
+	if err := logger.LogEvent(townlog.Event{Timestamp: now.Add(-30 * time.Minute), Type: townlog.EventSpawn, Agent: "gastown/polecats/nux", Context: "gt-abc"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := func(d time.Duration) string { return now.Add(-d).UTC().Format(time.RFC3339) }
+	lines := []string{
+		`{"ts":"` + ts(time.Hour) + `","type":"merge_failed","actor":"gastown/refinery","payload":{"reason":"conflict"}}`,
+		`{"ts":"` + ts(90*time.Minute) + `","type":"merge_failed","actor":"beads/refinery","payload":{"reason":"tests"}}`,
+		`{"ts":"` + ts(10*time.Minute) + `","type":"merged","actor":"gastown/refinery","payload":{"branch":"polecat/nux"}}`,
+		`not json`,
+	}
+	eventsPath := filepath.Join(townRoot, ".events.jsonl")
+	f, err := os.OpenFile(eventsPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	townRecs, err := readTownlogRecords(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := readEventRecords(eventsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records = append(records, legacyTownlogRecords(townRecs, records)...)
+
+	summarize := func(recs []LogRecord) string {
+		var parts []string
+		for _, r := range recs {
+			parts = append(parts, r.Type+"@"+r.Actor)
+		}
+		return strings.Join(parts, ",")
+	}
+
+	since, err := parseLogTime("2h", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		q     logQuery
+		limit int
+		want  string
+	}{
+		{"all sorted", logQuery{}, 0, "crash@gastown/polecats/toast,merge_failed@beads/refinery,merge_failed@gastown/refinery,spawn@gastown/polecats/nux,merged@gastown/refinery"},
+		{"since", logQuery{Since: since}, 0, "merge_failed@beads/refinery,merge_failed@gastown/refinery,spawn@gastown/polecats/nux,merged@gastown/refinery"},
+		{"until", logQuery{Until: now.Add(-time.Hour)}, 0, "crash@gastown/polecats/toast,merge_failed@beads/refinery"},
+		{"actor and event", logQuery{Actors: []string{"gastown/refinery"}, Events: []string{"merge_failed"}}, 0, "merge_failed@gastown/refinery"},
+		{"rig", logQuery{Rig: "gastown", Events: []string{"crash", "spawn"}}, 0, "crash@gastown/polecats/toast,spawn@gastown/polecats/nux"},
+		{"source", logQuery{Source: "townlog"}, 0, "crash@gastown/polecats/toast,spawn@gastown/polecats/nux"},
+		{"grep detail", logQuery{Grep: "GT-ABC"}, 0, "spawn@gastown/polecats/nux"},
+		{"grep payload summary", logQuery{Grep: "conflict"}, 0, "merge_failed@gastown/refinery"},
+		{"limit keeps latest", logQuery{}, 2, "spawn@gastown/polecats/nux,merged@gastown/refinery"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarize(queryLogRecords(records, tt.q, tt.limit)); got != tt.want {
+				t.Errorf("got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLogTime(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2h", now.Add(-2 * time.Hour)},
+		{"7d", now.Add(-7 * 24 * time.Hour)},
+		{"2026-01-14T10:00:00Z", time.Date(2026, 1, 14, 10, 0, 0, 0, time.UTC)},
+		{"2026-01-14", time.Date(2026, 1, 14, 0, 0, 0, 0, time.Local)},
+		{"2026-01-14 09:30", time.Date(2026, 1, 14, 9, 30, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := parseLogTime(tt.in, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseLogTime(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := parseLogTime("yesterday", now); err == nil {
+		t.Error("expected error for unparseable time")
+	}
+}
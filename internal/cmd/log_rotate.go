@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/daemon"
+	"github.com/steveyegge/gastown/internal/logrotate"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var logRotateForce bool
+
+var logRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Rotate the town log and events log now",
+	Long: `Rotate logs/town.log and .events.jsonl per the town's rotation policy.
+
+The daemon does this on every heartbeat. Logs are rotated once they reach
+the size limit or their oldest entry reaches the age limit; rotated segments
+are gzipped and deleted after the retention window. gt log, gt log query,
+gt audit, and gt seance read rotated segments transparently.
+
+Configure in mayor/daemon.json:
+  "log_rotation": {
+    "max_size_mb": 50,     // default 50
+    "max_age": "7d",       // default 7d, "0" for no age limit
+    "retention": "90d",    // default 90d, "0" to keep segments forever
+    "compress": true       // default true
+  }
+
+Examples:
+  gt log rotate            # Rotate logs that are due, prune old segments
+  gt log rotate --force    # Rotate both logs regardless of size or age`,
+	RunE: runLogRotate,
+}
+
+func init() {
+	logRotateCmd.Flags().BoolVar(&logRotateForce, "force", false, "Rotate regardless of size and age limits")
+
+	logCmd.AddCommand(logRotateCmd)
+}
+
+func runLogRotate(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	var cfg *daemon.LogRotationConfig
+	if patrolConfig := daemon.LoadPatrolConfig(townRoot); patrolConfig != nil {
+		cfg = patrolConfig.LogRotation
+	}
+	if cfg != nil && cfg.Disabled {
+		if !logRotateForce {
+			fmt.Printf("%s Log rotation is disabled in mayor/daemon.json (use --force to rotate anyway)\n", style.Dim.Render("○"))
+			return nil
+		}
+		enabled := *cfg
+		enabled.Disabled = false
+		cfg = &enabled
+	}
+
+	now := time.Now()
+	var rotated []string
+	if logRotateForce {
+		logs, err := daemon.TownLogs(townRoot, cfg)
+		if err != nil {
+			return err
+		}
+		for _, l := range logs {
+			ok, err := logrotate.Cut(l.Path, l.Policy, now)
+			if ok {
+				rotated = append(rotated, l.Path)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	// Also prunes expired segments
+	due, err := daemon.RotateTownLogs(townRoot, cfg, now)
+	rotated = append(rotated, due...)
+	if err != nil {
+		return err
+	}
+
+	if len(rotated) == 0 {
+		fmt.Printf("%s No logs due for rotation\n", style.Dim.Render("○"))
+		return nil
+	}
+	for _, path := range rotated {
+		fmt.Printf("%s Rotated %s\n", style.Bold.Render("✓"), filepath.Base(path))
+	}
+	return nil
+}
@@ -28,6 +28,7 @@ var (
 	mailThreadJSON    bool
 	mailReplySubject  string
 	mailReplyMessage  string
+	mailNackReason    string
 
 	// Search flags
 	mailSearchFrom    string
@@ -193,6 +194,23 @@ Examples:
 	RunE: runMailArchive,
 }
 
+var mailNackCmd = &cobra.Command{
+	Use:   "nack <message-id>",
+	Short: "Reject a protocol message you cannot process",
+	Long: `Reply NACK to a protocol message (MERGE_READY, MERGED, MERGE_FAILED,
+REWORK_REQUEST) that you cannot process, and archive it.
+
+Protocol messages must be acknowledged. Archiving or marking one read
+acknowledges it; a message left unprocessed is delivered again and
+eventually escalated to the Mayor. A NACK escalates it to the Mayor at
+once, with your reason.
+
+Examples:
+  gt mail nack hq-abc123 -r "polecat nux no longer exists"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMailNack,
+}
+
 var mailMarkReadCmd = &cobra.Command{
 	Use:     "mark-read <message-id> [message-id...]",
 	Aliases: []string{"ack"},
@@ -453,6 +471,10 @@ func init() {
 	mailReplyCmd.Flags().StringVarP(&mailReplyMessage, "message", "m", "", "Reply message body (required)")
 	_ = mailReplyCmd.MarkFlagRequired("message")
 
+	// Nack flags
+	mailNackCmd.Flags().StringVarP(&mailNackReason, "reason", "r", "", "Why the message cannot be processed (required)")
+	_ = mailNackCmd.MarkFlagRequired("reason")
+
 	// Search flags
 	mailSearchCmd.Flags().StringVar(&mailSearchFrom, "from", "", "Filter by sender address")
 	mailSearchCmd.Flags().BoolVar(&mailSearchSubject, "subject", false, "Only search subject lines")
@@ -473,6 +495,7 @@ func init() {
 	mailCmd.AddCommand(mailPeekCmd)
 	mailCmd.AddCommand(mailDeleteCmd)
 	mailCmd.AddCommand(mailArchiveCmd)
+	mailCmd.AddCommand(mailNackCmd)
 	mailCmd.AddCommand(mailMarkReadCmd)
 	mailCmd.AddCommand(mailMarkUnreadCmd)
 	mailCmd.AddCommand(mailCheckCmd)
@@ -18,6 +18,9 @@ func runMailCheck(cmd *cobra.Command, args []string) error {
 	} else {
 		address = detectSender()
 	}
+	if err := checkObserverMailbox(address); err != nil {
+		return err
+	}
 
 	// All mail uses town beads (two-level architecture)
 	workDir, err := findMailWorkDir()
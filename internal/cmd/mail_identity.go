@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/steveyegge/gastown/internal/identity"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -129,6 +130,11 @@ func detectSenderFromRole(role string) string {
 			return fmt.Sprintf("%s/refinery", rig)
 		}
 		return detectSenderFromCwd()
+	case "observer":
+		if observer := os.Getenv("GT_OBSERVER"); observer != "" {
+			return fmt.Sprintf("observers/%s", observer)
+		}
+		return detectSenderFromCwd()
 	default:
 		// Unknown role, try cwd detection
 		return detectSenderFromCwd()
@@ -142,6 +148,14 @@ func detectSenderFromCwd() string {
 		return "overseer"
 	}
 
+	// If in the town's observers directory, extract address (format: observers/name)
+	if strings.Contains(cwd, "/observers/") {
+		parts := strings.Split(cwd, "/observers/")
+		if len(parts) >= 2 {
+			return fmt.Sprintf("observers/%s", strings.Split(parts[len(parts)-1], "/")[0])
+		}
+	}
+
 	// If in a rig's polecats directory, extract address (format: rig/polecats/name)
 	if strings.Contains(cwd, "/polecats/") {
 		parts := strings.Split(cwd, "/polecats/")
@@ -185,3 +199,16 @@ func detectSenderFromCwd() string {
 	// Default to overseer (human)
 	return "overseer"
 }
+
+// checkObserverMailbox refuses to open any mailbox but an observer's own:
+// observers may read their mail, not other agents'. Other roles are not
+// restricted.
+func checkObserverMailbox(address string) error {
+	if info, err := GetRole(); err != nil || info.Role != RoleObserver {
+		return nil
+	}
+	if own := detectSender(); !identity.Same(address, own) {
+		return fmt.Errorf("observers may only read their own mailbox (%s), not %s", own, address)
+	}
+	return nil
+}
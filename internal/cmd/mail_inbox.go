@@ -9,7 +9,9 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 // getMailbox returns the mailbox for the given address.
@@ -39,6 +41,9 @@ func runMailInbox(cmd *cobra.Command, args []string) error {
 	} else {
 		address = detectSender()
 	}
+	if err := checkObserverMailbox(address); err != nil {
+		return err
+	}
 
 	mailbox, err := getMailbox(address)
 	if err != nil {
@@ -276,6 +281,36 @@ func runMailArchive(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runMailNack(cmd *cobra.Command, args []string) error {
+	address := detectSender()
+	mailbox, err := getMailbox(address)
+	if err != nil {
+		return err
+	}
+	msg, err := mailbox.Get(args[0])
+	if err != nil {
+		return fmt.Errorf("getting message: %w", err)
+	}
+	if !protocol.RequiresAck(protocol.ParseMessageType(msg.Subject)) {
+		return fmt.Errorf("%s is not a protocol message (subject %q)", args[0], msg.Subject)
+	}
+
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	nack := protocol.NewNackMessage(msg, address, mailNackReason)
+	if err := mail.NewRouterWithTownRoot(townRoot, townRoot).Send(nack); err != nil {
+		return fmt.Errorf("sending NACK: %w", err)
+	}
+	recordProtocolSend(townRoot, nack)
+	if err := mailbox.Delete(msg.ID); err != nil {
+		style.PrintWarning("could not archive %s: %v", msg.ID, err)
+	}
+	fmt.Printf("%s Sent NACK to %s and archived %s\n", style.Bold.Render("✓"), msg.From, msg.ID)
+	return nil
+}
+
 func runMailMarkRead(cmd *cobra.Command, args []string) error {
 	// Determine which inbox
 	address := detectSender()
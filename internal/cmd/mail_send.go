@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -123,6 +125,10 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("sending message: %w", err)
 		}
 		_ = events.LogFeed(events.TypeMail, from, events.MailPayload(to, mailSubject))
+		protocol.LogMergeEvent(msg)
+		recordMergeAudit(townRoot, msg)
+		recordMergeChangelog(townRoot, msg)
+		recordProtocolSend(townRoot, msg)
 		fmt.Printf("%s Message sent to %s\n", style.Bold.Render("✓"), to)
 		fmt.Printf("  Subject: %s\n", mailSubject)
 		return nil
@@ -163,6 +169,10 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 
 	// Log mail event to activity feed
 	_ = events.LogFeed(events.TypeMail, from, events.MailPayload(to, mailSubject))
+	protocol.LogMergeEvent(msg)
+	recordMergeAudit(townRoot, msg)
+	recordMergeChangelog(townRoot, msg)
+	recordProtocolSend(townRoot, msg)
 
 	fmt.Printf("%s Message sent to %s\n", style.Bold.Render("✓"), to)
 	fmt.Printf("  Subject: %s\n", mailSubject)
@@ -188,3 +198,14 @@ func generateThreadID() string {
 	_, _ = rand.Read(b) // crypto/rand.Read only fails on broken system
 	return "thread-" + hex.EncodeToString(b)
 }
+
+// recordProtocolSend updates the town's protocol ack ledger for a sent
+// message (see protocol.AckTracker). Best-effort: delivery already happened.
+func recordProtocolSend(townRoot string, msg *mail.Message) {
+	if townRoot == "" {
+		return
+	}
+	if err := protocol.NewAckTracker(townRoot).Record(msg, time.Now()); err != nil {
+		style.PrintWarning("could not track acknowledgment of %q: %v", msg.Subject, err)
+	}
+}
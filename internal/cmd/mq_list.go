@@ -187,6 +187,9 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		if fields != nil {
 			branch = fields.Branch
 			convoyID = fields.ConvoyID
+			if fields.IsPartial() {
+				branch += style.Dim.Render(fmt.Sprintf(" (partial: %d)", len(fields.Commits)))
+			}
 		}
 
 		// Format convoy column
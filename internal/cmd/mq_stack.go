@@ -0,0 +1,610 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/runstate"
+	"github.com/steveyegge/gastown/internal/stack"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	mqStackTarget string
+	mqStackIssue  string
+	mqStackDryRun bool
+	mqStackJSON   bool
+)
+
+var mqStackCmd = &cobra.Command{
+	Use:   "stack",
+	Short: "Manage epic branch stacks",
+	RunE:  requireSubcommand,
+	Long: `Manage branch stacks for epics built across dependent polecat branches.
+
+A stack orders an epic's branches bottom to top: each branch builds on the
+one below it, and the bottom branch on the stack's target (the epic's
+integration branch, or the rig's default branch). When a branch changes or
+lands, the branches above it are restacked: rebased onto the new tip below
+them and force-pushed, so no polecat has to rebase by hand.
+
+Restacks run automatically when a stacked branch is pushed by gt done and
+after every Refinery merge. A restack that conflicts files a
+conflict-resolution task under the epic; the branches above it wait until
+the task is closed.
+
+Commands:
+  add      Put a branch on top of an epic's stack
+  show     Show stacks and where each branch stands
+  restack  Rebase stacked branches onto the branches below them
+  remove   Drop a branch (or a whole stack)`,
+}
+
+var mqStackAddCmd = &cobra.Command{
+	Use:   "add <epic-id> [branch]",
+	Short: "Put a branch on top of an epic's stack",
+	Long: `Put a branch on top of an epic's stack and restack it onto the branch below.
+
+The branch defaults to the current branch, which is pushed to origin if it
+isn't there yet. A new stack is based on the epic's integration branch if it
+has one (see gt mq integration create), otherwise on the rig's default
+branch; use --target to choose another.
+
+If the current checkout is the branch and it moves, the checkout follows.
+
+Examples:
+  gt mq stack add gt-auth-epic                   # Stack the current branch
+  gt mq stack add gt-auth-epic polecat/nux-mk1a2 --issue gt-auth.2`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runMqStackAdd,
+}
+
+var mqStackShowCmd = &cobra.Command{
+	Use:   "show [epic-id]",
+	Short: "Show stacks and where each branch stands",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runMqStackShow,
+}
+
+var mqStackRestackCmd = &cobra.Command{
+	Use:   "restack [epic-id]",
+	Short: "Rebase stacked branches onto the branches below them",
+	Long: `Restack an epic's stack, or every unfinished stack in the rig.
+
+Bottom to top, each branch whose branch below has changed or landed is
+rebased onto the new tip (git rebase --onto) in a scratch worktree and
+force-pushed with a lease. Branches that landed drop out of the stack.
+
+A conflicting rebase is abandoned and filed as a conflict-resolution task
+under the epic; the branches above it are left alone until the task is
+closed. Workers with a restacked branch checked out are mailed RESTACKED
+with the command to bring their checkout along.
+
+Examples:
+  gt mq stack restack                    # Every stack in the rig
+  gt mq stack restack gt-auth-epic --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMqStackRestack,
+}
+
+var mqStackRemoveCmd = &cobra.Command{
+	Use:   "remove <epic-id> [branch]",
+	Short: "Drop a branch (or a whole stack)",
+	Long: `Drop a branch from an epic's stack, or the whole stack if no branch is given.
+
+The branch itself is left alone. The next restack rebases the branches
+above a dropped branch onto the branch below it, leaving out its commits.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runMqStackRemove,
+}
+
+func init() {
+	mqStackAddCmd.Flags().StringVar(&mqStackTarget, "target", "", "Base branch for a new stack (default: epic's integration branch or rig default)")
+	mqStackAddCmd.Flags().StringVar(&mqStackIssue, "issue", "", "Issue the branch implements (default: parse from branch name)")
+	mqStackShowCmd.Flags().BoolVar(&mqStackJSON, "json", false, "Output as JSON")
+	mqStackRestackCmd.Flags().BoolVar(&mqStackDryRun, "dry-run", false, "Show what would be restacked without pushing")
+	mqStackRestackCmd.Flags().BoolVar(&mqStackJSON, "json", false, "Output as JSON")
+
+	mqStackCmd.AddCommand(mqStackAddCmd)
+	mqStackCmd.AddCommand(mqStackShowCmd)
+	mqStackCmd.AddCommand(mqStackRestackCmd)
+	mqStackCmd.AddCommand(mqStackRemoveCmd)
+	mqCmd.AddCommand(mqStackCmd)
+}
+
+func runMqStackAdd(cmd *cobra.Command, args []string) error {
+	epicID := args[0]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	rigName, r, err := findCurrentRig(townRoot)
+	if err != nil {
+		return err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+
+	// The branch to stack: given, or the current checkout's
+	cwdGit := git.NewGit(cwd)
+	current, _ := cwdGit.CurrentBranch()
+	branch := current
+	if len(args) > 1 {
+		branch = args[1]
+	}
+	if branch == "" || branch == "HEAD" {
+		return fmt.Errorf("not on a branch; name the branch to stack")
+	}
+
+	bd := beads.New(r.Path)
+	epic, err := bd.Show(epicID)
+	if err != nil {
+		if err == beads.ErrNotFound {
+			return fmt.Errorf("epic '%s' not found", epicID)
+		}
+		return fmt.Errorf("fetching epic: %w", err)
+	}
+	if epic.Type != "epic" {
+		return fmt.Errorf("'%s' is a %s, not an epic", epicID, epic.Type)
+	}
+
+	// A new stack sits on the epic's integration branch, if it has one
+	st := stack.Open(r.Path)
+	target := mqStackTarget
+	var parent, from string
+	existing, err := st.Get(epicID)
+	switch {
+	case errors.Is(err, stack.ErrNotFound):
+		if target == "" {
+			target = getIntegrationBranchField(epic.Description)
+		}
+		if target == "" {
+			target = r.DefaultBranch()
+		}
+		parent = target
+	case err != nil:
+		return err
+	default:
+		parent = existing.Top()
+		if parent != existing.Target {
+			from = parent
+		}
+	}
+
+	rs, err := stack.NewRestacker(r.Path)
+	if err != nil {
+		return err
+	}
+	if err := rs.Repo.Fetch("origin"); err != nil {
+		return fmt.Errorf("fetching from origin: %w", err)
+	}
+	tip, err := rs.Repo.Rev("origin/" + branch)
+	if err != nil && branch == current {
+		fmt.Printf("Pushing %s to origin...\n", branch)
+		if err := rig.WithGitAuth(r.Path, cwdGit).Push("origin", branch, false); err != nil {
+			return fmt.Errorf("pushing %s: %w", branch, err)
+		}
+		if err := rs.Repo.Fetch("origin"); err != nil {
+			return fmt.Errorf("fetching from origin: %w", err)
+		}
+		tip, err = rs.Repo.Rev("origin/" + branch)
+	}
+	if err != nil {
+		return fmt.Errorf("branch %s is not on origin; push it first", branch)
+	}
+	parentTip, err := rs.Repo.Rev("origin/" + parent)
+	if err != nil {
+		return fmt.Errorf("branch %s is not on origin", parent)
+	}
+	base, err := rs.Repo.MergeBase(parentTip, tip)
+	if err != nil {
+		return fmt.Errorf("%s shares no history with %s: %w", branch, parent, err)
+	}
+
+	info := parseBranchName(branch)
+	if mqStackIssue != "" {
+		info.Issue = mqStackIssue
+	}
+	s, err := st.Add(epicID, target, stack.Entry{
+		Branch: branch,
+		Issue:  info.Issue,
+		Worker: info.Worker,
+		Base:   base,
+		Tip:    tip,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Stacked %s on %s\n", style.Bold.Render("✓"), branch, parent)
+	fmt.Printf("  Epic:     %s\n", epicID)
+	fmt.Printf("  Position: %d (stack onto %s)\n", len(s.Entries), s.Target)
+
+	// Bring the new branch onto the branch below right away
+	reports, err := restackStacks(townRoot, rigName, []string{epicID}, from, false)
+	if err != nil {
+		return err
+	}
+	printStackRestacks(reports, false)
+	for _, res := range reports[0].Results {
+		if res.Branch == branch && res.Action == stack.ActionRestacked && branch == current {
+			followRestack(cwdGit, res)
+		}
+	}
+	return stackRestackErrors(reports)
+}
+
+// followRestack moves the current checkout of a branch that was just
+// restacked onto the branch's new tip, keeping any local commits.
+func followRestack(g *git.Git, res stack.Result) {
+	status, err := g.Status()
+	if err == nil && status.Clean {
+		err = g.RebaseOnto("origin/"+res.Branch, res.OldTip)
+		if err == nil {
+			fmt.Printf("%s Checkout moved to the restacked %s\n", style.Bold.Render("✓"), res.Branch)
+			return
+		}
+		_ = g.AbortRebase()
+	}
+	style.PrintWarning("checkout of %s was not moved; run: git fetch origin && git rebase --onto origin/%s %s",
+		res.Branch, res.Branch, git.ShortSHA(res.OldTip))
+}
+
+func runMqStackShow(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	rigName, r, err := findCurrentRig(townRoot)
+	if err != nil {
+		return err
+	}
+
+	st := stack.Open(r.Path)
+	var stacks []*stack.Stack
+	if len(args) > 0 {
+		s, err := st.Get(args[0])
+		if err != nil {
+			return err
+		}
+		stacks = append(stacks, s)
+	} else if stacks, err = st.List(); err != nil {
+		return err
+	}
+
+	if mqStackJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stacks)
+	}
+	if len(stacks) == 0 {
+		fmt.Printf("No branch stacks in %s\n", rigName)
+		return nil
+	}
+	for i, s := range stacks {
+		if i > 0 {
+			fmt.Println()
+		}
+		printStack(s)
+	}
+	return nil
+}
+
+func printStack(s *stack.Stack) {
+	fmt.Printf("%s %s (onto %s)\n", style.Bold.Render("Stack"), s.Epic, s.Target)
+	for i, e := range s.Entries {
+		issue := ""
+		if e.Issue != "" {
+			issue = "  " + e.Issue
+		}
+		status := ""
+		switch {
+		case e.Landed:
+			status = "  " + style.Dim.Render("landed")
+		case e.ConflictTask != "":
+			status = "  " + style.Warning.Render("conflict: "+e.ConflictTask)
+		}
+		fmt.Printf("  %d. %s%s%s\n", i+1, e.Branch, issue, status)
+	}
+	if !s.Restacked.IsZero() {
+		fmt.Printf("  %s\n", style.Dim.Render("Last restack: "+s.Restacked.Format("2006-01-02 15:04")))
+	}
+}
+
+func runMqStackRestack(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	rigName, _, err := findCurrentRig(townRoot)
+	if err != nil {
+		return err
+	}
+
+	reports, err := restackStacks(townRoot, rigName, args, "", mqStackDryRun)
+	if err != nil {
+		return err
+	}
+	if mqStackJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			return err
+		}
+		return stackRestackErrors(reports)
+	}
+	if len(reports) == 0 {
+		fmt.Printf("No branch stacks to restack in %s\n", rigName)
+		return nil
+	}
+	printStackRestacks(reports, mqStackDryRun)
+	return stackRestackErrors(reports)
+}
+
+func runMqStackRemove(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	_, r, err := findCurrentRig(townRoot)
+	if err != nil {
+		return err
+	}
+
+	branch := ""
+	if len(args) > 1 {
+		branch = args[1]
+	}
+	if err := stack.Open(r.Path).Remove(args[0], branch); err != nil {
+		return err
+	}
+	if branch == "" {
+		fmt.Printf("%s Removed the stack for %s\n", style.Bold.Render("✓"), args[0])
+	} else {
+		fmt.Printf("%s Dropped %s from the stack for %s\n", style.Bold.Render("✓"), branch, args[0])
+		fmt.Printf("  %s\n", style.Dim.Render("Branches above it move onto the branch below on the next restack."))
+	}
+	return nil
+}
+
+// stackRestack is the outcome of restacking one epic's stack.
+type stackRestack struct {
+	Epic    string         `json:"epic"`
+	Target  string         `json:"target,omitempty"`
+	Results []stack.Result `json:"results,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// restackStacks restacks the rig's stacks for epics (every unfinished stack
+// if none). With from, only the branches above from are restacked. Conflicts
+// are filed as tasks under the epic, and workers with a restacked branch
+// checked out are told to bring their checkout along.
+func restackStacks(townRoot, rigName string, epics []string, from string, dryRun bool) ([]stackRestack, error) {
+	rigPath := filepath.Join(townRoot, rigName)
+	st := stack.Open(rigPath)
+	if len(epics) == 0 {
+		stacks, err := st.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range stacks {
+			if !s.Done() {
+				epics = append(epics, s.Epic)
+			}
+		}
+		if len(epics) == 0 {
+			return nil, nil
+		}
+	}
+
+	rs, err := stack.NewRestacker(rigPath)
+	if err != nil {
+		return nil, err
+	}
+	bd := beads.New(rigPath)
+	rs.DryRun, rs.From = dryRun, from
+	rs.TaskOpen = func(id string) bool {
+		issue, err := bd.Show(id)
+		return err == nil && issue.Status != "closed"
+	}
+	rs.FileConflict = func(s *stack.Stack, e *stack.Entry, res *stack.Result) (string, error) {
+		return fileRestackConflict(bd, s, e, res)
+	}
+
+	var reports []stackRestack
+	for _, epic := range epics {
+		rep := stackRestack{Epic: epic}
+		err := st.Update(epic, func(s *stack.Stack) error {
+			rep.Target = s.Target
+			results, err := rs.Restack(s)
+			if err != nil {
+				return err
+			}
+			rep.Results = results
+			if dryRun {
+				return runstate.ErrSkip
+			}
+			s.Restacked = time.Now()
+			return nil
+		})
+		if err != nil {
+			rep.Error = err.Error()
+		} else if !dryRun {
+			notifyRestacked(townRoot, rigName, rep.Results)
+		}
+		reports = append(reports, rep)
+	}
+	return reports, nil
+}
+
+// fileRestackConflict files a conflict-resolution task for a restack of e
+// that conflicted, under the epic. Like the Refinery's merge conflict tasks
+// it can be slung to a fresh polecat.
+func fileRestackConflict(bd *beads.Beads, s *stack.Stack, e *stack.Entry, res *stack.Result) (string, error) {
+	title, priority := e.Branch, 2
+	if e.Issue != "" {
+		if issue, err := bd.Show(e.Issue); err == nil && issue != nil {
+			title, priority = issue.Title, issue.Priority
+		}
+	}
+
+	description := fmt.Sprintf(`Restack branch %s onto %s
+
+## Metadata
+- Epic: %s
+- Branch: %s
+- Restack onto: %s
+- Stacked on: %s
+- Original issue: %s
+- Conflicting files: %s
+
+## Instructions
+1. Check out the branch: git fetch origin && git checkout -B %s origin/%s
+2. Replay its commits onto the branch below: git rebase --onto origin/%s %s
+3. Resolve conflicts in your editor
+4. Complete the rebase: git add . && git rebase --continue
+5. Force-push the resolved branch: git push --force-with-lease origin %s
+6. Close this task: bd close <this-task-id>
+
+Branches stacked above %s are restacked once this task is closed.`,
+		e.Branch, res.Onto,
+		s.Epic,
+		e.Branch,
+		res.Onto,
+		git.ShortSHA(e.Base),
+		e.Issue,
+		strings.Join(res.Conflicts, ", "),
+		e.Branch, e.Branch,
+		res.Onto, git.ShortSHA(e.Base),
+		e.Branch,
+		e.Branch,
+	)
+
+	task, err := bd.Create(beads.CreateOptions{
+		Title:       "Resolve restack conflicts: " + title,
+		Type:        "task",
+		Priority:    priority,
+		Description: description,
+		Parent:      s.Epic,
+		Actor:       detectSender(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("creating restack conflict task: %w", err)
+	}
+	return task.ID, nil
+}
+
+// notifyRestacked mails RESTACKED to the workers whose checked-out branches
+// were restacked under them, with the command to follow.
+func notifyRestacked(townRoot, rigName string, results []stack.Result) {
+	sender := detectSender()
+	router := mail.NewRouter(townRoot)
+	for _, res := range results {
+		if res.Action != stack.ActionRestacked || !res.CheckedOut || res.Worker == "" {
+			continue
+		}
+		to := fmt.Sprintf("%s/%s", rigName, res.Worker)
+		if to == sender {
+			continue // Moves its own checkout
+		}
+		msg := mail.NewMessage(sender, to, "RESTACKED "+res.Branch, fmt.Sprintf(
+			"Branch: %s\nOnto: %s\nOld-Tip: %s\nNew-Tip: %s\n\n"+
+				"Your branch was rebased onto the new tip of %s and force-pushed.\n"+
+				"Bring your checkout along (keeps local commits):\n"+
+				"  git fetch origin && git rebase --onto origin/%s %s",
+			res.Branch, res.Onto, res.OldTip, res.NewTip, res.Onto, res.Branch, git.ShortSHA(res.OldTip)))
+		if err := router.Send(msg); err != nil {
+			style.PrintWarning("could not tell %s about the restack of %s: %v", to, res.Branch, err)
+		}
+	}
+}
+
+// restackAbove restacks the branches stacked above branch after it was
+// pushed. Does nothing if branch isn't stacked.
+func restackAbove(townRoot, rigName, branch string) {
+	s, err := stack.Open(filepath.Join(townRoot, rigName)).Of(branch)
+	if err != nil {
+		style.PrintWarning("could not check branch stacks: %v", err)
+		return
+	}
+	if s == nil {
+		return
+	}
+	reports, err := restackStacks(townRoot, rigName, []string{s.Epic}, branch, false)
+	if err != nil {
+		style.PrintWarning("could not restack the branches above %s: %v", branch, err)
+		return
+	}
+	printStackRestacks(reports, false)
+}
+
+func printStackRestacks(reports []stackRestack, dryRun bool) {
+	for _, rep := range reports {
+		fmt.Printf("%s %s (onto %s)\n", style.Bold.Render("Restack"), rep.Epic, rep.Target)
+		if rep.Error != "" {
+			fmt.Printf("  %s %s\n", style.Error.Render("✗"), rep.Error)
+			continue
+		}
+		if len(rep.Results) == 0 {
+			fmt.Printf("  %s\n", style.Dim.Render("nothing to restack"))
+		}
+		for _, res := range rep.Results {
+			fmt.Printf("  %s\n", formatRestackResult(res, dryRun))
+		}
+	}
+}
+
+func formatRestackResult(res stack.Result, dryRun bool) string {
+	switch res.Action {
+	case stack.ActionRestacked:
+		if dryRun {
+			return fmt.Sprintf("%s %s would be restacked onto %s", style.Bold.Render("→"), res.Branch, res.Onto)
+		}
+		return fmt.Sprintf("%s %s restacked onto %s (%s → %s)", style.Bold.Render("✓"), res.Branch, res.Onto,
+			git.ShortSHA(res.OldTip), git.ShortSHA(res.NewTip))
+	case stack.ActionLanded:
+		return fmt.Sprintf("%s %s landed", style.Bold.Render("✓"), res.Branch)
+	case stack.ActionUpToDate:
+		return style.Dim.Render(fmt.Sprintf("○ %s is up to date on %s", res.Branch, res.Onto))
+	case stack.ActionConflict:
+		s := fmt.Sprintf("%s %s conflicts with %s in %s", style.Warning.Render("⚠"), res.Branch, res.Onto,
+			strings.Join(res.Conflicts, ", "))
+		if res.Task != "" {
+			s += fmt.Sprintf("; filed %s", res.Task)
+		}
+		if res.Error != "" {
+			s += fmt.Sprintf(" (%s)", res.Error)
+		}
+		return s
+	case stack.ActionBlocked:
+		return style.Dim.Render(fmt.Sprintf("○ %s blocked: %s", res.Branch, res.Error))
+	default:
+		return fmt.Sprintf("%s %s %s: %s", style.Error.Render("✗"), res.Branch, res.Action, res.Error)
+	}
+}
+
+// stackRestackErrors returns an error if any stack could not be restacked.
+func stackRestackErrors(reports []stackRestack) error {
+	failed := 0
+	for _, rep := range reports {
+		if rep.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d stack(s) could not be restacked", failed)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/stack"
+)
+
+func TestFormatRestackResult(t *testing.T) {
+	tests := []struct {
+		res    stack.Result
+		dryRun bool
+		want   string
+	}{
+		{
+			res:  stack.Result{Branch: "polecat/b", Action: stack.ActionRestacked, Onto: "polecat/a", OldTip: "1111111111", NewTip: "2222222222"},
+			want: "polecat/b restacked onto polecat/a (11111111 → 22222222)",
+		},
+		{
+			res:    stack.Result{Branch: "polecat/b", Action: stack.ActionRestacked, Onto: "polecat/a"},
+			dryRun: true,
+			want:   "polecat/b would be restacked onto polecat/a",
+		},
+		{
+			res:  stack.Result{Branch: "polecat/b", Action: stack.ActionConflict, Onto: "polecat/a", Conflicts: []string{"x.go", "y.go"}, Task: "gt-123"},
+			want: "polecat/b conflicts with polecat/a in x.go, y.go; filed gt-123",
+		},
+		{
+			res:  stack.Result{Branch: "polecat/c", Action: stack.ActionBlocked, Error: "waiting on polecat/b"},
+			want: "polecat/c blocked: waiting on polecat/b",
+		},
+		{
+			res:  stack.Result{Branch: "polecat/c", Action: stack.ActionMissing, Error: "origin/polecat/c not found"},
+			want: "polecat/c missing: origin/polecat/c not found",
+		},
+	}
+	for _, tt := range tests {
+		if got := formatRestackResult(tt.res, tt.dryRun); !strings.Contains(got, tt.want) {
+			t.Errorf("formatRestackResult(%s) = %q, want it to contain %q", tt.res.Action, got, tt.want)
+		}
+	}
+}
+
+func TestStackRestackErrors(t *testing.T) {
+	if err := stackRestackErrors([]stackRestack{{Epic: "gt-a"}}); err != nil {
+		t.Errorf("no failures: %v", err)
+	}
+	err := stackRestackErrors([]stackRestack{{Epic: "gt-a"}, {Epic: "gt-b", Error: "fetching origin: boom"}})
+	if err == nil || !strings.Contains(err.Error(), "1 stack(s)") {
+		t.Errorf("one failure: %v", err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/style"
 )
 
@@ -178,6 +179,13 @@ func printMqStatus(issue *beads.Issue, mrFields *beads.MRFields) error {
 		if mrFields.Rig != "" {
 			fmt.Printf("   Rig:          %s\n", mrFields.Rig)
 		}
+		if mrFields.IsPartial() {
+			short := make([]string, len(mrFields.Commits))
+			for i, c := range mrFields.Commits {
+				short[i] = git.ShortSHA(c)
+			}
+			fmt.Printf("   Partial:      %d commit(s): %s\n", len(mrFields.Commits), strings.Join(short, " "))
+		}
 		if mrFields.MergeCommit != "" {
 			fmt.Printf("   Merge Commit: %s\n", mrFields.MergeCommit)
 		}
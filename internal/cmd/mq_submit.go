@@ -112,6 +112,9 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 	if branch == defaultBranch || branch == "master" {
 		return fmt.Errorf("cannot submit %s/master branch to merge queue", defaultBranch)
 	}
+	if err := rig.CheckBranchPolicy(filepath.Join(townRoot, rigName), branch); err != nil {
+		return fmt.Errorf("cannot submit to merge queue: %w", err)
+	}
 
 	// Parse branch info
 	info := parseBranchName(branch)
@@ -202,6 +205,15 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Worker: %s\n", worker)
 	}
 	fmt.Printf("  Priority: P%d\n", priority)
+	sourceIssue, _ := bd.Show(issueID)
+	submitted := &beads.MRFields{
+		Branch:      branch,
+		Target:      target,
+		SourceIssue: issueID,
+		Worker:      worker,
+		Rig:         rigName,
+	}
+	routeSubmittedMR(townRoot, bd, g, mrIssue.ID, submitted, sourceIssue, priority)
 
 	// Auto-cleanup for polecats: if this is a polecat branch and cleanup not disabled,
 	// send lifecycle request and wait for termination
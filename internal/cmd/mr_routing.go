@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// routeSubmittedMR labels a newly submitted MR by the files it changes,
+// then opens its review and requests protected-path approval as the rig
+// requires. The changed files are listed at most once, when first needed.
+func routeSubmittedMR(townRoot string, bd *beads.Beads, g *git.Git, mrID string, mr *beads.MRFields, source *beads.Issue, priority int) {
+	rigPath := filepath.Join(townRoot, mr.Rig)
+	changes := sync.OnceValue(func() *git.ChangeSet {
+		_ = g.Fetch("origin")
+		c, err := g.ChangedFiles("origin/"+mr.Target, "origin/"+mr.Branch)
+		if err != nil {
+			style.PrintWarning("could not list files changed by %s: %v", mrID, err)
+			return nil
+		}
+		return c
+	})
+
+	labels := labelMR(rigPath, bd, mrID, changes)
+	maybeOpenReview(rigPath, bd, g, mrID, mr, labels, source, priority)
+	maybeRequestMergeApproval(townRoot, bd, mrID, mr, changes)
+}
+
+// loadRoutingConfig returns the rig's routing settings, or nil if unset.
+func loadRoutingConfig(rigPath string) *config.RoutingConfig {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil {
+		return nil
+	}
+	return settings.Routing
+}
+
+// labelMR adds the rig's path labels (routing.path_labels) for the files
+// an MR changes to its bead, and returns them.
+func labelMR(rigPath string, bd *beads.Beads, mrID string, mrChanges func() *git.ChangeSet) []string {
+	cfg := loadRoutingConfig(rigPath)
+	if cfg == nil || len(cfg.PathLabels) == 0 {
+		return nil
+	}
+	changes := mrChanges()
+	if changes == nil {
+		return nil
+	}
+	labels := pathLabels(cfg.PathLabels, changes)
+	if len(labels) == 0 {
+		return nil
+	}
+	if err := bd.Update(mrID, beads.UpdateOptions{AddLabels: labels}); err != nil {
+		style.PrintWarning("could not label %s: %v", mrID, err)
+		return nil
+	}
+	return labels
+}
+
+// pathLabels returns the labels of the rules whose paths changes touches,
+// in rule order, without duplicates.
+func pathLabels(rules []config.PathLabel, changes *git.ChangeSet) []string {
+	seen := make(map[string]bool)
+	var labels []string
+	for _, rule := range rules {
+		if rule.Label != "" && !seen[rule.Label] && changes.Touches(rule.Paths...) {
+			seen[rule.Label] = true
+			labels = append(labels, rule.Label)
+		}
+	}
+	return labels
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+func TestPathLabels(t *testing.T) {
+	rules := []config.PathLabel{
+		{Label: "area:db", Paths: []string{"migrations/**"}},
+		{Label: "area:docs", Paths: []string{"docs/", "*.md"}},
+		{Label: "area:db", Paths: []string{"*.sql"}},
+	}
+	changes := &git.ChangeSet{Files: []string{"README.md", "migrations/001.sql", "main.go"}}
+	if got := strings.Join(pathLabels(rules, changes), ","); got != "area:db,area:docs" {
+		t.Errorf("pathLabels = %s, want area:db,area:docs", got)
+	}
+	if got := pathLabels(rules, &git.ChangeSet{Files: []string{"main.go"}}); len(got) != 0 {
+		t.Errorf("pathLabels(main.go) = %v, want none", got)
+	}
+}
+
+func TestReviewerFor(t *testing.T) {
+	cfg := &config.ReviewConfig{
+		Reviewer:  "gastown/crew/max",
+		Reviewers: map[string]string{"area:db": "gastown/crew/dba", "area:web": "gastown/crew/fe"},
+	}
+	tests := []struct {
+		labels []string
+		want   string
+	}{
+		{nil, "gastown/crew/max"},
+		{[]string{"area:docs"}, "gastown/crew/max"},
+		{[]string{"area:web", "area:db"}, "gastown/crew/dba"},
+	}
+	for _, tt := range tests {
+		if got := reviewerFor(cfg, "gastown", tt.labels); got != tt.want {
+			t.Errorf("reviewerFor(%v) = %q, want %q", tt.labels, got, tt.want)
+		}
+	}
+	if got := reviewerFor(nil, "gastown", []string{"area:db"}); got != "gastown" {
+		t.Errorf("reviewerFor(nil config) = %q, want gastown", got)
+	}
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/dispatch"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	nextJSON   bool
+	nextDryRun bool
+)
+
+var nextCmd = &cobra.Command{
+	Use:     "next",
+	GroupID: GroupWork,
+	Short:   "Claim your next bead and show what to do",
+	Long: `Claim the highest-priority ready bead you can do, hook it, and print it.
+
+gt next replaces the claim ritual (bd ready, bd update --assignee,
+gt hook, bd show) with one race-free step: the claim is taken under a
+lease, so two workers running gt next at once never get the same bead.
+
+Beads labelled needs:<capability> only go to workers with that capability,
+as listed in the rig's settings/config.json:
+
+  "dispatch": {"capabilities": {"*": ["go"], "Toast": ["frontend"]}}
+
+gt next refuses to run while you still have work on your hook; finish it
+with gt done first. It works in push and pull rigs alike (see gt dispatch).
+
+Examples:
+  gt next              # Claim and show the next bead
+  gt next --dry-run    # Show what you would claim
+  gt next --json       # Claim and print the bead as JSON`,
+	Args: cobra.NoArgs,
+	RunE: runNext,
+}
+
+func init() {
+	nextCmd.Flags().BoolVar(&nextJSON, "json", false, "Output the claimed bead as JSON")
+	nextCmd.Flags().BoolVarP(&nextDryRun, "dry-run", "n", false, "Show what would be claimed without claiming it")
+	rootCmd.AddCommand(nextCmd)
+}
+
+func runNext(cmd *cobra.Command, args []string) error {
+	townRoot, agent, r, err := dispatchWorker()
+	if err != nil {
+		return err
+	}
+	bd := beads.New(r.BeadsPath())
+
+	hooked, err := bd.List(beads.ListOptions{Status: beads.StatusHooked, Assignee: agent, Priority: -1})
+	if err != nil {
+		return fmt.Errorf("checking your hook: %w", err)
+	}
+	if len(hooked) > 0 {
+		return fmt.Errorf("%s is still on your hook; finish it with gt done first", hooked[0].ID)
+	}
+
+	d, err := newRigDispatcher(townRoot, r)
+	if err != nil {
+		return err
+	}
+	caps := workerCapabilities(r.Path, agent)
+
+	var issue *beads.Issue
+	if nextDryRun {
+		queue, err := d.Queue()
+		if err != nil {
+			return err
+		}
+		for _, candidate := range queue {
+			if dispatch.CanDo(candidate, caps) {
+				issue = candidate
+				break
+			}
+		}
+	} else if issue, err = claimPaced(d, r, agent, caps); err != nil {
+		return err
+	}
+
+	if issue == nil {
+		if nextJSON {
+			fmt.Println("null")
+			return nil
+		}
+		fmt.Printf("%s Nothing ready for you in %s\n", style.Dim.Render("○"), r.Name)
+		return nil
+	}
+	if !nextDryRun {
+		hookClaimedBead(townRoot, agent, r, issue)
+		// The claim only changed status, assignee and labels; reload so
+		// the context printed below is complete.
+		if full, err := bd.Show(issue.ID); err == nil {
+			issue = full
+		}
+	}
+
+	if nextJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(issue)
+	}
+	printNextContext(issue, nextDryRun)
+	return nil
+}
+
+// printNextContext prints a claimed bead and what to do with it.
+func printNextContext(issue *beads.Issue, dryRun bool) {
+	if dryRun {
+		fmt.Printf("%s Would claim %s: %s\n", style.Bold.Render("→"), issue.ID, issue.Title)
+	} else {
+		fmt.Printf("%s Claimed %s: %s\n", style.Bold.Render("✓"), issue.ID, issue.Title)
+	}
+	kind := issue.Type
+	if kind == "" {
+		kind = "task"
+	}
+	fmt.Printf("  P%d %s", issue.Priority, kind)
+	if needs := dispatch.Needs(issue); len(needs) > 0 {
+		fmt.Printf(", needs %s", strings.Join(needs, ", "))
+	}
+	if issue.Parent != "" {
+		fmt.Printf(", part of %s", issue.Parent)
+	}
+	fmt.Println()
+
+	if desc := strings.TrimSpace(issue.Description); desc != "" {
+		fmt.Println()
+		for _, line := range strings.Split(desc, "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+	if dryRun {
+		return
+	}
+	fmt.Println()
+	fmt.Printf("%s\n", style.Dim.Render("Work attached to hook (status=hooked). When finished: gt done"))
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/daemon"
+	"github.com/steveyegge/gastown/internal/notifier"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var notificationsJSON bool
+
+var notificationsCmd = &cobra.Command{
+	Use:     "notifications",
+	GroupID: GroupDiag,
+	Short:   "Show external notification channels and their delivery state",
+	Long: `Show where the town sends notifications outside itself, and how delivery
+is going.
+
+The daemon sends escalations (gt escalate), firing alert rules (gt alerts),
+and exhausted SLO error budgets (gt stats) to the channels configured under
+"notifications" in mayor/daemon.json:
+
+  {
+    "notifications": {
+      "batch": "1m",
+      "dedup": "1h",
+      "smtp": {"host": "smtp.example.com", "username": "gt",
+               "password": "${GT_SMTP_PASSWORD}", "from": "gt@example.com"},
+      "channels": [
+        {"name": "oncall", "type": "slack", "url": "${GT_SLACK_WEBHOOK}",
+         "min_severity": "high"},
+        {"name": "gastown-dev", "type": "discord", "url": "${GT_DISCORD_WEBHOOK}",
+         "rigs": ["gastown"], "kinds": ["alert", "slo"],
+         "quiet_hours": {"start": "22:00", "end": "07:00",
+                         "timezone": "America/Los_Angeles"}},
+        {"name": "overseer-email", "type": "email", "to": ["me@example.com"],
+         "kinds": ["escalation"]}
+      ]
+    }
+  }
+
+Channel types: slack, discord, webhook (POSTs the batch as JSON), email.
+Kinds: escalation, alert, slo. Severities: low, medium, high, critical
+(alerts and SLO breaches are high; escalations carry their own).
+
+Notifications are sent every batch interval, one message per channel. A
+notification repeated within the dedup window is counted on the pending
+one or suppressed. During a channel's quiet hours only critical
+notifications are sent; the rest go out as a digest when they end.`,
+	RunE: runNotifications,
+}
+
+var notificationsTestCmd = &cobra.Command{
+	Use:   "test [channel]",
+	Short: "Send a test notification",
+	Long: `Send a test notification to one channel, or to every enabled channel,
+immediately (ignoring routing, dedup, and quiet hours).`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runNotificationsTest,
+}
+
+func init() {
+	notificationsCmd.Flags().BoolVar(&notificationsJSON, "json", false, "Output as JSON")
+	notificationsCmd.AddCommand(notificationsTestCmd)
+	rootCmd.AddCommand(notificationsCmd)
+}
+
+// notificationChannelStatus is one channel in gt notifications output.
+type notificationChannelStatus struct {
+	Name     string                 `json:"name"`
+	Type     string                 `json:"type"`
+	Routes   string                 `json:"routes"`
+	Disabled bool                   `json:"disabled,omitempty"`
+	Quiet    bool                   `json:"quiet,omitempty"`
+	State    *notifier.ChannelState `json:"state,omitempty"`
+}
+
+func loadNotificationsConfig(townRoot string) notifier.Config {
+	if cfg := daemon.LoadPatrolConfig(townRoot); cfg != nil && cfg.Notifications != nil {
+		return *cfg.Notifications
+	}
+	return notifier.Config{}
+}
+
+func runNotifications(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg := loadNotificationsConfig(townRoot)
+	if _, _, err := cfg.Durations(); err != nil {
+		style.PrintWarning("invalid notifications config: %v", err)
+	}
+	state, err := notifier.LoadState(townRoot)
+	if err != nil {
+		return fmt.Errorf("reading notification state: %w", err)
+	}
+
+	now := time.Now()
+	statuses := make([]notificationChannelStatus, 0, len(cfg.Channels))
+	for _, ch := range cfg.Channels {
+		statuses = append(statuses, notificationChannelStatus{
+			Name:     ch.ChannelName(),
+			Type:     ch.Type,
+			Routes:   describeChannelRoutes(ch),
+			Disabled: ch.Disabled,
+			Quiet:    ch.Quiet(now),
+			State:    state[ch.ChannelName()],
+		})
+	}
+
+	if notificationsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statuses)
+	}
+
+	if len(statuses) == 0 {
+		fmt.Printf("%s No notification channels configured (see gt notifications --help)\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	fmt.Printf("\n%s Notification Channels\n\n", style.Bold.Render("📣"))
+	for _, s := range statuses {
+		var status string
+		switch {
+		case s.Disabled:
+			status = style.Dim.Render("disabled")
+		case s.State != nil && s.State.Error != "":
+			status = style.Error.Render("FAILING")
+		case s.Quiet:
+			status = style.Warning.Render("quiet hours")
+		case s.State == nil:
+			status = style.Dim.Render("nothing sent yet")
+		default:
+			status = style.Success.Render("ok")
+		}
+		fmt.Printf("  %s  %s  %s\n", style.Bold.Render(s.Name), style.Dim.Render(s.Type), status)
+		fmt.Printf("     gets: %s\n", s.Routes)
+		if st := s.State; st != nil {
+			line := fmt.Sprintf("     sent %d, suppressed %d as duplicates", st.Sent, st.Suppressed)
+			if !st.SentAt.IsZero() {
+				line += ", last " + formatDuration(now.Sub(st.SentAt)) + " ago"
+			}
+			fmt.Println(line)
+			if len(st.Pending) > 0 {
+				fmt.Printf("     %d pending\n", len(st.Pending))
+			}
+			if st.Error != "" {
+				fmt.Printf("     %s\n", style.Error.Render(st.Error))
+			}
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+// describeChannelRoutes summarizes what a channel receives, e.g.
+// "alert, slo ≥ high in gastown (quiet 22:00-07:00)".
+func describeChannelRoutes(ch notifier.Channel) string {
+	desc := "all notifications"
+	if len(ch.Kinds) > 0 {
+		desc = strings.Join(ch.Kinds, ", ")
+	}
+	if ch.MinSeverity != "" {
+		desc += " ≥ " + ch.MinSeverity
+	}
+	if len(ch.Rigs) > 0 {
+		desc += " in " + strings.Join(ch.Rigs, ", ")
+	}
+	if q := ch.QuietHours; q != nil {
+		desc += fmt.Sprintf(" (quiet %s-%s", q.Start, q.End)
+		if q.Timezone != "" {
+			desc += " " + q.Timezone
+		}
+		desc += ")"
+	}
+	return desc
+}
+
+func runNotificationsTest(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	bridge, err := notifier.NewBridge(townRoot, loadNotificationsConfig(townRoot), func(string, ...interface{}) {})
+	if err != nil {
+		return err
+	}
+	if bridge.Channels() == 0 {
+		return fmt.Errorf("no notification channels configured (see gt notifications --help)")
+	}
+	channel := ""
+	if len(args) > 0 {
+		channel = args[0]
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := bridge.Test(ctx, channel); err != nil {
+		return err
+	}
+	fmt.Printf("%s Test notification sent\n", style.Bold.Render("✓"))
+	return nil
+}
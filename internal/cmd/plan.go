@@ -0,0 +1,388 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/plan"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	planAgent   string
+	planRig     string
+	planDryRun  bool
+	planMessage string
+	planNoSling bool
+)
+
+var planCmd = &cobra.Command{
+	Use:     "plan <bead>",
+	GroupID: GroupWork,
+	Short:   "Draft a swarm plan for a large bead",
+	Long: `Decompose a large bead into a swarm plan and file it as a draft.
+
+The rig's configured agent runs once, non-interactively and read-only, in
+the rig's repo. It splits the bead into steps, each with its dependencies
+(Needs) and an estimated model tier (haiku, sonnet, or opus), written in
+the molecule step format. The plan is filed as a draft plan bead and the
+Mayor is mailed to review it; nothing is spawned until it is approved.
+
+Approving instantiates the plan like a molecule: one child bead per step
+under the planned bead, with dependencies wired. A convoy tracks the
+children, and the steps with no dependencies are slung to the rig (pull
+dispatch rigs claim them instead). The rest become ready as the steps they
+need close.
+
+Plans are named by the plan bead or the planned bead.
+
+Examples:
+  gt plan gt-big                   # Draft a plan and mail the Mayor
+  gt plan gt-big --dry-run         # Show the plan without filing it
+  gt plan show gt-big
+  gt plan approve gt-big
+  gt plan reject gt-big -m "Split the API work by endpoint"`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPlan,
+}
+
+var planShowCmd = &cobra.Command{
+	Use:   "show <plan|bead>",
+	Short: "Show a draft plan",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPlanShow,
+}
+
+var planListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List draft plans awaiting approval",
+	Args:  cobra.NoArgs,
+	RunE:  runPlanList,
+}
+
+var planApproveCmd = &cobra.Command{
+	Use:   "approve <plan|bead>",
+	Short: "Approve a plan: create its steps and spawn the swarm",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPlanApprove,
+}
+
+var planRejectCmd = &cobra.Command{
+	Use:   "reject <plan|bead>",
+	Short: "Reject a draft plan",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPlanReject,
+}
+
+func init() {
+	planCmd.Flags().StringVar(&planAgent, "agent", "", "Agent that drafts the plan (default: the rig's agent)")
+	planCmd.Flags().StringVar(&planRig, "rig", "", "Rig to run the steps in (default: the bead's rig)")
+	planCmd.Flags().BoolVarP(&planDryRun, "dry-run", "n", false, "Show the plan without filing it")
+	planApproveCmd.Flags().StringVarP(&planMessage, "message", "m", "", "Note to record with the decision")
+	planApproveCmd.Flags().BoolVar(&planNoSling, "no-sling", false, "Create the steps without slinging any")
+	planRejectCmd.Flags().StringVarP(&planMessage, "message", "m", "", "Why the plan is rejected (required)")
+	_ = planRejectCmd.MarkFlagRequired("message")
+
+	planCmd.AddCommand(planShowCmd)
+	planCmd.AddCommand(planListCmd)
+	planCmd.AddCommand(planApproveCmd)
+	planCmd.AddCommand(planRejectCmd)
+	rootCmd.AddCommand(planCmd)
+}
+
+func runPlan(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return requireSubcommand(cmd, args)
+	}
+	beadID := args[0]
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	bd := beadStoreFor(townRoot, beadID)
+	issue, err := bd.Show(beadID)
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", beadID, err)
+	}
+	if _, _, err := plan.New(bd).For(beadID); err == nil {
+		return fmt.Errorf("%s already has a draft plan (gt plan show %s)", beadID, beadID)
+	}
+
+	rigName := planRig
+	if rigName == "" {
+		rigName = rigOfBead(townRoot, beadID)
+	}
+	if rigName == "" {
+		return fmt.Errorf("cannot tell which rig %s belongs to; use --rig", beadID)
+	}
+	_, r, err := getRig(rigName)
+	if err != nil {
+		return err
+	}
+
+	agent, err := planningAgent(townRoot, r.Path)
+	if err != nil {
+		return err
+	}
+	argv := config.BuildNonInteractiveCommand(agent, plan.Prompt(issue))
+
+	fmt.Printf("%s Planning %s with %s...\n", style.Bold.Render("→"), beadID, agent)
+	c := exec.Command(argv[0], argv[1:]...) //nolint:gosec // G204: argv from agent preset
+	c.Dir = r.Path
+	if repo := filepath.Join(r.Path, "mayor", "rig"); dirExists(repo) {
+		c.Dir = repo
+	}
+	c.Stderr = os.Stderr
+	out, err := c.Output()
+	if err != nil {
+		return fmt.Errorf("running %s: %w", agent, err)
+	}
+	p, err := plan.Parse(string(out))
+	if err != nil {
+		return fmt.Errorf("%w\n\nAgent output:\n%s", err, out)
+	}
+
+	fmt.Println()
+	printPlan(p)
+	if planDryRun {
+		return nil
+	}
+
+	f := &plan.Fields{For: beadID, Rig: r.Name, Agent: agent}
+	draft, err := plan.New(bd).Draft(f, p, issue.Title)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\n%s Draft plan %s filed for %s\n", style.SuccessPrefix, draft.ID, beadID)
+
+	msg := &mail.Message{
+		From:     detectSender(),
+		To:       "mayor/",
+		Subject:  fmt.Sprintf("[PLAN] %s: %d steps", issue.Title, len(p.Steps)),
+		Priority: mail.PriorityNormal,
+		Body: fmt.Sprintf("Draft plan %s for %s (%s), %d steps: %s\n\n%s\n"+
+			"Approve:  gt plan approve %s\nReject:   gt plan reject %s -m \"<why>\"\n",
+			draft.ID, beadID, issue.Title, len(p.Steps), tierSummary(p), p.Markdown(), draft.ID, draft.ID),
+	}
+	if err := mail.NewRouter(townRoot).Send(msg); err != nil {
+		style.PrintWarning("could not mail the Mayor: %v", err)
+	} else {
+		fmt.Printf("  Mailed mayor/ for approval\n")
+	}
+	return nil
+}
+
+// rigOfBead returns the rig a bead belongs to by its prefix's route, or ""
+// for town beads and unknown prefixes.
+func rigOfBead(townRoot, beadID string) string {
+	dir := beads.GetRigPathForPrefix(townRoot, beads.ExtractPrefix(beadID))
+	rel, err := filepath.Rel(townRoot, dir)
+	if dir == "" || err != nil || rel == "." {
+		return ""
+	}
+	return strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+}
+
+// planningAgent returns the agent preset that drafts plans for a rig.
+func planningAgent(townRoot, rigPath string) (string, error) {
+	rc, name, err := config.ResolveAgentConfigWithOverride(townRoot, rigPath, planAgent)
+	if err != nil {
+		return "", err
+	}
+	if config.GetAgentPresetByName(name) != nil {
+		return name, nil
+	}
+	if info := config.GetAgentPresetByCommand(rc.Command); info != nil {
+		return string(info.Name), nil
+	}
+	return "", fmt.Errorf("agent %q has no non-interactive mode; use --agent", rc.Command)
+}
+
+// dirExists reports whether path is a directory.
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// printPlan prints a plan's steps with their tiers and dependencies.
+func printPlan(p *plan.Plan) {
+	fmt.Printf("%s\n\n", style.Bold.Render(fmt.Sprintf("%d steps (%s):", len(p.Steps), tierSummary(p))))
+	for _, s := range p.Steps {
+		tier := s.Tier
+		if tier == "" {
+			tier = "-"
+		}
+		fmt.Printf("  %-20s %-7s %s\n", s.Ref, tier, s.Title)
+		if len(s.Needs) > 0 {
+			fmt.Printf("  %-20s %-7s %s\n", "", "", style.Dim.Render("needs "+strings.Join(s.Needs, ", ")))
+		}
+	}
+}
+
+// tierSummary counts a plan's steps by tier, e.g. "2 sonnet, 1 haiku".
+func tierSummary(p *plan.Plan) string {
+	counts := p.Tiers()
+	var tiers []string
+	for tier := range counts {
+		tiers = append(tiers, tier)
+	}
+	sort.Strings(tiers)
+	var parts []string
+	for _, tier := range tiers {
+		name := tier
+		if name == "" {
+			name = "untiered"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", counts[tier], name))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// loadPlan finds a draft plan by the plan bead or the planned bead.
+func loadPlan(id string) (string, *beads.Beads, *beads.Issue, *plan.Fields, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return "", nil, nil, nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	bd := beadStoreFor(townRoot, id)
+	issue, f, err := plan.New(bd).Get(id)
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+	return townRoot, bd, issue, f, nil
+}
+
+func runPlanShow(cmd *cobra.Command, args []string) error {
+	_, _, issue, f, err := loadPlan(args[0])
+	if err != nil {
+		return err
+	}
+	p, err := plan.FromIssue(issue)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %s for %s (rig %s, drafted by %s)\n\n", style.Bold.Render(issue.ID), f.Status, f.For, f.Rig, f.Agent)
+	printPlan(p)
+	fmt.Printf("\n%s\n", p.Markdown())
+	return nil
+}
+
+func runPlanList(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	found := 0
+	for _, s := range beadStores(townRoot) {
+		issues, err := plan.New(s.bd).Drafts()
+		if err != nil {
+			continue // rig without beads
+		}
+		for _, issue := range issues {
+			f := plan.ParseFields(issue)
+			if f == nil {
+				continue
+			}
+			fmt.Printf("  %s  %-12s %s\n", style.Bold.Render(issue.ID), f.For, strings.TrimPrefix(issue.Title, "Plan: "))
+			found++
+		}
+	}
+	if found == 0 {
+		fmt.Printf("%s No draft plans\n", style.Dim.Render("○"))
+	}
+	return nil
+}
+
+func runPlanApprove(cmd *cobra.Command, args []string) error {
+	_, bd, issue, f, err := loadPlan(args[0])
+	if err != nil {
+		return err
+	}
+	p, err := plan.FromIssue(issue)
+	if err != nil {
+		return err
+	}
+	plans := plan.New(bd)
+	children, err := plans.Approve(issue, f, detectSender(), planMessage)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Approved plan %s: %d steps under %s\n", style.SuccessPrefix, issue.ID, len(children), f.For)
+
+	// Children are created in step order, so step i is children[i].
+	stepIDs := make(map[string]string, len(children))
+	var ids []string
+	for i, child := range children {
+		if i < len(p.Steps) {
+			stepIDs[p.Steps[i].Ref] = child.ID
+		}
+		ids = append(ids, child.ID)
+		fmt.Printf("  %s %s\n", child.ID, child.Title)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if convoyID, err := createAutoConvoy(ids[0], strings.TrimPrefix(issue.Title, "Plan: ")); err != nil {
+		style.PrintWarning("could not create convoy: %v", err)
+	} else {
+		if len(ids) > 1 {
+			add := exec.Command("gt", append([]string{"convoy", "add", convoyID}, ids[1:]...)...) //nolint:gosec // G204: IDs from bd
+			add.Stderr = os.Stderr
+			if err := add.Run(); err != nil {
+				style.PrintWarning("could not add steps to convoy %s: %v", convoyID, err)
+			}
+		}
+		f.Convoy = convoyID
+		_ = plans.Record(issue, f)
+		fmt.Printf("  Convoy %s tracks the steps\n", convoyID)
+	}
+
+	_, r, err := getRig(f.Rig)
+	if err != nil {
+		return err
+	}
+	switch {
+	case planNoSling:
+		return nil
+	case rigPullsWork(r.Path):
+		fmt.Printf("  %s pulls work; its polecats will claim the ready steps\n", r.Name)
+		return nil
+	}
+	for _, root := range p.Roots() {
+		id := stepIDs[root.Ref]
+		if id == "" {
+			continue
+		}
+		c := exec.Command("gt", "sling", id, r.Name, "--no-convoy") //nolint:gosec // G204: bead ID from bd, rig from plan
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			style.PrintWarning("could not sling %s: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func runPlanReject(cmd *cobra.Command, args []string) error {
+	_, bd, issue, f, err := loadPlan(args[0])
+	if err != nil {
+		return err
+	}
+	if err := plan.New(bd).Reject(issue, f, detectSender(), planMessage); err != nil {
+		return fmt.Errorf("rejecting plan %s: %w", issue.ID, err)
+	}
+	fmt.Printf("%s Rejected plan %s for %s\n", style.Bold.Render("✗"), issue.ID, f.For)
+	fmt.Printf("  %s\n", style.Dim.Render("Re-plan with: gt plan "+f.For))
+	return nil
+}
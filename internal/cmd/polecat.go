@@ -7,12 +7,15 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/auditchain"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/keepalive"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/runtime"
@@ -136,7 +139,10 @@ Displays comprehensive information including:
   - Assigned issue (if any)
   - Session status (running/stopped, attached/detached)
   - Session creation time
-  - Last activity time
+  - Last activity time and freshness (fresh, stale, very-stale)
+
+Freshness cutoffs default to 2m/5m and can be raised per rig with
+keepalive.fresh and keepalive.stale in settings/config.json.
 
 Examples:
   gt polecat status greenplace/Toast
@@ -154,6 +160,7 @@ var (
 	polecatNukeAll           bool
 	polecatNukeDryRun        bool
 	polecatNukeForce         bool
+	polecatNukeRequest       bool
 	polecatCheckRecoveryJSON bool
 )
 
@@ -194,6 +201,9 @@ SAFETY CHECKS: The command refuses to nuke a polecat if:
   - Polecat has work on its hook
 
 Use --force to bypass safety checks (LOSES WORK).
+Use --request-approval to ask the overseer instead: blocked polecats get an
+approval bead (see gt approvals) and are nuked when it is approved; the
+rest are nuked now.
 Use --dry-run to see what would happen and safety check status.
 
 Examples:
@@ -201,7 +211,8 @@ Examples:
   gt polecat nuke greenplace/Toast greenplace/Furiosa
   gt polecat nuke greenplace --all
   gt polecat nuke greenplace --all --dry-run
-  gt polecat nuke greenplace/Toast --force  # bypass safety checks`,
+  gt polecat nuke greenplace/Toast --force  # bypass safety checks
+  gt polecat nuke greenplace/Toast --request-approval`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runPolecatNuke,
 }
@@ -302,6 +313,7 @@ func init() {
 	polecatNukeCmd.Flags().BoolVar(&polecatNukeAll, "all", false, "Nuke all polecats in the rig")
 	polecatNukeCmd.Flags().BoolVar(&polecatNukeDryRun, "dry-run", false, "Show what would be nuked without doing it")
 	polecatNukeCmd.Flags().BoolVarP(&polecatNukeForce, "force", "f", false, "Force nuke, bypassing all safety checks (LOSES WORK)")
+	polecatNukeCmd.Flags().BoolVar(&polecatNukeRequest, "request-approval", false, "Ask the overseer to approve nuking blocked polecats")
 
 	// Check-recovery flags
 	polecatCheckRecoveryCmd.Flags().BoolVar(&polecatCheckRecoveryJSON, "json", false, "Output as JSON")
@@ -510,6 +522,10 @@ func runPolecatRemove(cmd *cobra.Command, args []string) error {
 		}
 
 		fmt.Printf("  %s removed\n", style.Success.Render("✓"))
+		if polecatForce {
+			recordAudit(filepath.Dir(p.r.Path), auditchain.ActionForceRemove, fmt.Sprintf("%s/polecats/%s", p.rigName, p.polecatName),
+				map[string]string{"command": "polecat remove"})
+		}
 		removed++
 	}
 
@@ -631,6 +647,8 @@ type PolecatStatus struct {
 	Windows        int           `json:"windows,omitempty"`
 	CreatedAt      string        `json:"created_at,omitempty"`
 	LastActivity   string        `json:"last_activity,omitempty"`
+	ActivityState  string        `json:"activity_state,omitempty"`
+	Phase          string        `json:"phase,omitempty"`
 }
 
 func runPolecatStatus(cmd *cobra.Command, args []string) error {
@@ -662,6 +680,15 @@ func runPolecatStatus(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// A long command's heartbeat is activity even while the pane is quiet
+	var phase string
+	if ka := keepalive.ReadSession(filepath.Dir(r.Path), sessInfo.SessionID); ka != nil && sessInfo.Running {
+		if ka.Timestamp.After(sessInfo.LastActivity) {
+			sessInfo.LastActivity = ka.Timestamp
+		}
+		phase = ka.Phase
+	}
+
 	// JSON output
 	if polecatStatusJSON {
 		status := PolecatStatus{
@@ -675,12 +702,14 @@ func runPolecatStatus(cmd *cobra.Command, args []string) error {
 			SessionID:      sessInfo.SessionID,
 			Attached:       sessInfo.Attached,
 			Windows:        sessInfo.Windows,
+			Phase:          phase,
 		}
 		if !sessInfo.Created.IsZero() {
 			status.CreatedAt = sessInfo.Created.Format("2006-01-02 15:04:05")
 		}
 		if !sessInfo.LastActivity.IsZero() {
 			status.LastActivity = sessInfo.LastActivity.Format("2006-01-02 15:04:05")
+			status.ActivityState = keepalive.RigThresholds(r.Path).Classify(time.Since(sessInfo.LastActivity))
 		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
@@ -743,6 +772,22 @@ func runPolecatStatus(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  Last Activity: %s (%s)\n",
 				sessInfo.LastActivity.Format("15:04:05"),
 				style.Dim.Render(ago))
+
+			th := keepalive.RigThresholds(r.Path)
+			activityStr := th.Classify(time.Since(sessInfo.LastActivity))
+			switch activityStr {
+			case keepalive.Fresh:
+				activityStr = style.Success.Render(activityStr)
+			case keepalive.Stale:
+				activityStr = style.Warning.Render(activityStr)
+			default:
+				activityStr = style.Error.Render(activityStr)
+			}
+			fmt.Printf("  Activity:      %s %s\n", activityStr,
+				style.Dim.Render(fmt.Sprintf("(fresh <%s, very stale ≥%s)", th.Fresh, th.Stale)))
+		}
+		if phase != "" {
+			fmt.Printf("  Phase:         %s\n", phase)
 		}
 	} else {
 		fmt.Printf("  Status:        %s\n", style.Dim.Render("not running"))
@@ -1154,7 +1199,12 @@ func runPolecatNuke(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		if len(blocked) > 0 {
+		if len(blocked) > 0 && polecatNukeRequest {
+			targets = requestNukeApprovals(targets, blocked)
+			if len(targets) == 0 {
+				return nil
+			}
+		} else if len(blocked) > 0 {
 			displaySafetyCheckBlocked(blocked)
 			return fmt.Errorf("blocked: %d polecat(s) have active work", len(blocked))
 		}
@@ -1214,6 +1264,8 @@ func runPolecatNuke(cmd *cobra.Command, args []string) error {
 		} else {
 			fmt.Printf("  %s deleted worktree\n", style.Success.Render("✓"))
 		}
+		recordAudit(filepath.Dir(p.r.Path), auditchain.ActionForceRemove, fmt.Sprintf("%s/polecats/%s", p.rigName, p.polecatName),
+			map[string]string{"command": "polecat nuke", "branch": branchToDelete, "force": strconv.FormatBool(polecatNukeForce)})
 
 		// Step 4: Delete branch (if we know it)
 		// Use bare repo if it exists (matches where worktree was created), otherwise mayor/rig
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/steveyegge/gastown/internal/approval"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/rig"
@@ -207,6 +208,41 @@ func displaySafetyCheckBlocked(blocked []*SafetyCheckResult) {
 	fmt.Println()
 }
 
+// requestNukeApprovals files an approval for each blocked polecat, so the
+// overseer can nuke it with gt approve, and returns the targets that are
+// safe to nuke now.
+func requestNukeApprovals(targets []polecatTarget, blocked []*SafetyCheckResult) []polecatTarget {
+	byName := make(map[string]*SafetyCheckResult, len(blocked))
+	for _, b := range blocked {
+		byName[b.Polecat] = b
+	}
+
+	var safe []polecatTarget
+	for _, p := range targets {
+		b, ok := byName[fmt.Sprintf("%s/%s", p.rigName, p.polecatName)]
+		if !ok {
+			safe = append(safe, p)
+			continue
+		}
+		f := &approval.Fields{
+			Kind:        approval.KindNuke,
+			Subject:     b.Polecat,
+			Rig:         p.rigName,
+			RequestedBy: detectSender(),
+			Notify:      detectSender(),
+		}
+		details := "Work at risk:\n"
+		for _, r := range b.Reasons {
+			details += fmt.Sprintf("  - %s\n", r)
+		}
+		townRoot := filepath.Dir(p.r.Path)
+		if _, err := requestApproval(townRoot, beads.New(p.r.BeadsPath()), f, "nuke "+b.Polecat, details); err != nil {
+			style.PrintWarning("could not request approval to nuke %s: %v", b.Polecat, err)
+		}
+	}
+	return safe
+}
+
 // displayDryRunSafetyCheck shows safety check status for dry-run mode.
 func displayDryRunSafetyCheck(target polecatTarget) {
 	fmt.Printf("\n  Safety checks:\n")
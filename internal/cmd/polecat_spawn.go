@@ -38,6 +38,8 @@ type SlingSpawnOptions struct {
 	Create   bool   // Create polecat if it doesn't exist (currently always true for sling)
 	HookBead string // Bead ID to set as hook_bead at spawn time (atomic assignment)
 	Agent    string // Agent override for this spawn (e.g., "gemini", "codex", "claude-haiku")
+
+	IgnoreSchedule bool // Spawn even outside the rig's working hours
 }
 
 // SpawnPolecatForSling creates a fresh polecat and optionally starts its session.
@@ -63,6 +65,9 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 	if err != nil {
 		return nil, fmt.Errorf("rig '%s' not found", rigName)
 	}
+	if err := checkSpawnHours(r, opts.IgnoreSchedule); err != nil {
+		return nil, err
+	}
 
 	// Get polecat manager (with tmux for session-aware allocation)
 	polecatGit := git.NewGit(r.Path)
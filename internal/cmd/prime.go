@@ -12,6 +12,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/identity"
 	"github.com/steveyegge/gastown/internal/lock"
 	"github.com/steveyegge/gastown/internal/state"
 	"github.com/steveyegge/gastown/internal/style"
@@ -35,6 +37,7 @@ const (
 	RoleRefinery Role = "refinery"
 	RolePolecat  Role = "polecat"
 	RoleCrew     Role = "crew"
+	RoleObserver Role = "observer"
 	RoleUnknown  Role = "unknown"
 )
 
@@ -181,6 +184,11 @@ func runPrime(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Observers only read: no lock, session event, hook, or beads workflow
+	if ctx.Role == RoleObserver {
+		return primeObserver(ctx)
+	}
+
 	// Check and acquire identity lock for worker roles
 	if !primeDryRun {
 		if err := acquireIdentityLock(ctx); err != nil {
@@ -255,6 +263,21 @@ func runPrime(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// primeObserver outputs an observer's context and pending mail. It touches
+// nothing an agent's prime would: observers must leave the town unchanged.
+func primeObserver(ctx RoleContext) error {
+	explain(true, "Role context: observer (read-only), skipping locks, hooks, and bd prime")
+	if err := outputPrimeContext(ctx); err != nil {
+		return err
+	}
+	if !primeDryRun {
+		runMailCheckInject(ctx.WorkDir)
+	} else {
+		explain(true, "gt mail check --inject: skipped in dry-run mode")
+	}
+	return nil
+}
+
 func detectRole(cwd, townRoot string) RoleInfo {
 	ctx := RoleInfo{
 		Role:     RoleUnknown,
@@ -297,6 +320,13 @@ func detectRole(cwd, townRoot string) RoleInfo {
 		return ctx
 	}
 
+	// Check for observer role: observers/<name>/
+	if len(parts) >= 2 && parts[0] == "observers" {
+		ctx.Role = RoleObserver
+		ctx.Polecat = parts[1] // Use Polecat field for observer name
+		return ctx
+	}
+
 	// At this point, first part should be a rig name
 	if len(parts) < 1 {
 		return ctx
@@ -521,6 +551,8 @@ func buildRoleAnnouncement(ctx RoleContext) string {
 		return fmt.Sprintf("%s Polecat %s, checking in.", ctx.Rig, ctx.Polecat)
 	case RoleCrew:
 		return fmt.Sprintf("%s Crew %s, checking in.", ctx.Rig, ctx.Polecat)
+	case RoleObserver:
+		return fmt.Sprintf("Observer %s, checking in.", ctx.Polecat)
 	default:
 		return "Agent, checking in."
 	}
@@ -538,24 +570,23 @@ func getGitRoot() (string, error) {
 
 // getAgentIdentity returns the agent identity string for hook lookup.
 func getAgentIdentity(ctx RoleContext) string {
+	id, ok := roleIdentity(ctx)
+	if !ok {
+		return ""
+	}
+	return id.Actor()
+}
+
+// roleIdentity returns the identity of the agent in ctx. It reports false
+// for unknown roles and for contexts missing the rig or name the role needs.
+func roleIdentity(ctx RoleContext) (identity.Identity, bool) {
 	switch ctx.Role {
-	case RoleCrew:
-		return fmt.Sprintf("%s/crew/%s", ctx.Rig, ctx.Polecat)
-	case RolePolecat:
-		return fmt.Sprintf("%s/polecats/%s", ctx.Rig, ctx.Polecat)
-	case RoleMayor:
-		return "mayor"
-	case RoleDeacon:
-		return "deacon"
-	case RoleBoot:
-		return "boot"
-	case RoleWitness:
-		return fmt.Sprintf("%s/witness", ctx.Rig)
-	case RoleRefinery:
-		return fmt.Sprintf("%s/refinery", ctx.Rig)
+	case RoleMayor, RoleDeacon, RoleBoot, RoleWitness, RoleRefinery, RolePolecat, RoleCrew:
 	default:
-		return ""
+		return identity.Identity{}, false
 	}
+	id := identity.New(identity.Role(ctx.Role), ctx.Rig, ctx.Polecat)
+	return id, id.Validate() == nil
 }
 
 // acquireIdentityLock checks and acquires the identity lock for worker roles.
@@ -569,15 +600,23 @@ func acquireIdentityLock(ctx RoleContext) error {
 		return nil
 	}
 
-	// Create lock for this worker directory
-	l := lock.New(ctx.WorkDir)
-
-	// Determine session ID from environment or context
-	sessionID := os.Getenv("TMUX_PANE")
-	if sessionID == "" {
-		// Fall back to a descriptive identifier
-		sessionID = fmt.Sprintf("%s/%s", ctx.Rig, ctx.Polecat)
+	// Create lock for this worker directory, recording who holds it
+	rigPath := ""
+	if ctx.Rig != "" {
+		rigPath = filepath.Join(ctx.TownRoot, ctx.Rig)
 	}
+	agentName, _ := config.ResolveRoleAgentName(string(ctx.Role), ctx.TownRoot, rigPath)
+	l := lock.New(ctx.WorkDir).WithHolder(lock.Holder{
+		Role:    string(ctx.Role),
+		Rig:     ctx.Rig,
+		Agent:   agentName,
+		Command: config.ResolveRoleAgentConfig(string(ctx.Role), ctx.TownRoot, rigPath).BuildCommand(),
+	})
+
+	// Identify the holder by tmux session name, which survives restarts of
+	// the same agent, so a restarted agent renews its own lease. Fall back
+	// to a descriptive identifier outside tmux.
+	sessionID := lock.CurrentSessionID(fmt.Sprintf("%s/%s", ctx.Rig, ctx.Polecat))
 
 	// Try to acquire the lock
 	if err := l.Acquire(sessionID); err != nil {
@@ -591,14 +630,25 @@ func acquireIdentityLock(ctx RoleContext) error {
 				fmt.Printf("Lock holder:\n")
 				fmt.Printf("  PID: %d\n", info.PID)
 				fmt.Printf("  Session: %s\n", info.SessionID)
+				if info.Agent != "" {
+					fmt.Printf("  Agent: %s\n", info.Agent)
+				}
 				fmt.Printf("  Acquired: %s\n", info.AcquiredAt.Format("2006-01-02 15:04:05"))
+				if !info.ExpiresAt.IsZero() {
+					fmt.Printf("  Lease expires: %s\n", info.ExpiresAt.Format("2006-01-02 15:04:05"))
+				}
 				fmt.Println()
 			}
 
 			fmt.Printf("To resolve:\n")
 			fmt.Printf("  1. Find the other session and close it, OR\n")
-			fmt.Printf("  2. Run: gt doctor --fix (cleans stale locks)\n")
-			fmt.Printf("  3. If lock is stale: rm %s/.runtime/agent.lock\n", ctx.WorkDir)
+			fmt.Printf("  2. Wait for the lease to expire, then run gt prime again\n")
+			fmt.Printf("     (an active agent renews its lease with every gt command), OR\n")
+			worker := ctx.Rig + "/" + ctx.Polecat
+			if ctx.Role == RoleCrew {
+				worker = ctx.Rig + "/crew/" + ctx.Polecat
+			}
+			fmt.Printf("  3. Inspect or release the lock: gt locks show %s\n", worker)
 			fmt.Println()
 
 			return fmt.Errorf("cannot claim identity %s/%s: %w", ctx.Rig, ctx.Polecat, err)
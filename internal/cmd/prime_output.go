@@ -40,6 +40,8 @@ func outputPrimeContext(ctx RoleContext) error {
 		roleName = "polecat"
 	case RoleCrew:
 		roleName = "crew"
+	case RoleObserver:
+		roleName = "observer"
 	default:
 		// Unknown role - use fallback
 		return outputPrimeContextFallback(ctx)
@@ -92,6 +94,8 @@ func outputPrimeContextFallback(ctx RoleContext) error {
 		outputPolecatContext(ctx)
 	case RoleCrew:
 		outputCrewContext(ctx)
+	case RoleObserver:
+		outputObserverContext(ctx)
 	default:
 		outputUnknownContext(ctx)
 	}
@@ -210,6 +214,22 @@ func outputCrewContext(ctx RoleContext) {
 		style.Dim.Render(ctx.Polecat), style.Dim.Render(ctx.Rig))
 }
 
+func outputObserverContext(ctx RoleContext) {
+	fmt.Printf("%s\n\n", style.Bold.Render("# Observer Context"))
+	fmt.Printf("You are observer **%s**: you inspect the town, you never change it.\n\n",
+		style.Bold.Render(ctx.Polecat))
+	fmt.Println("## Key Commands")
+	fmt.Println("- `gt status` - Show overall town status")
+	fmt.Println("- `gt ready` - Work ready across the town")
+	fmt.Println("- `gt mq list <rig>` - The merge queue")
+	fmt.Println("- `gt log` - Agent lifecycle events")
+	fmt.Println("- `gt mail inbox` - Your inbox (only yours)")
+	fmt.Println()
+	fmt.Println("Any command that would change the town is refused.")
+	fmt.Println()
+	fmt.Printf("Observer: %s\n", style.Dim.Render(ctx.Polecat))
+}
+
 func outputUnknownContext(ctx RoleContext) {
 	fmt.Printf("%s\n\n", style.Bold.Render("# Gas Town Context"))
 	fmt.Println("Could not determine specific role from current directory.")
@@ -222,6 +242,7 @@ func outputUnknownContext(ctx RoleContext) {
 	fmt.Println("- `<rig>/witness/rig/` - Witness role")
 	fmt.Println("- `<rig>/refinery/rig/` - Refinery role")
 	fmt.Println("- Town root or `mayor/` - Mayor role")
+	fmt.Println("- `observers/<name>/` - Observer role (read-only)")
 	fmt.Println()
 	fmt.Printf("Town root: %s\n", style.Dim.Render(ctx.TownRoot))
 }
@@ -288,7 +309,11 @@ func outputStartupDirective(ctx RoleContext) {
 		fmt.Println("3. If there's a 🤝 HANDOFF message, read it for context")
 		fmt.Println("4. Check for attached work: `gt hook`")
 		fmt.Println("   - If mol attached → **RUN IT** (you were spawned with this work)")
-		fmt.Println("   - If no mol → ERROR: polecats must have work attached; escalate to Witness")
+		if ctx.TownRoot != "" && rigPullsWork(filepath.Join(ctx.TownRoot, ctx.Rig)) {
+			fmt.Println("   - If no mol → claim work: `gt dispatch claim --wait`")
+		} else {
+			fmt.Println("   - If no mol → ERROR: polecats must have work attached; escalate to Witness")
+		}
 	case RoleRefinery:
 		fmt.Println()
 		fmt.Println("---")
@@ -16,11 +16,17 @@ import (
 )
 
 // hookInput represents the JSON input from LLM runtime hooks.
-// Claude Code sends this on stdin for SessionStart hooks.
+// Claude Code sends this on stdin for SessionStart and PreToolUse hooks.
 type hookInput struct {
 	SessionID      string `json:"session_id"`
 	TranscriptPath string `json:"transcript_path"`
 	Source         string `json:"source"` // startup, resume, clear, compact
+
+	// PreToolUse hooks: the tool about to run and its input
+	ToolName  string `json:"tool_name"`
+	ToolInput struct {
+		Command string `json:"command"` // Bash tool
+	} `json:"tool_input"`
 }
 
 // readHookSessionID reads session ID from available sources in hook mode.
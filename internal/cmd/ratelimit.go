@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/ratelimit"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var ratelimitStatusJSON bool
+
+var ratelimitCmd = &cobra.Command{
+	Use:     "ratelimit",
+	GroupID: GroupDiag,
+	Short:   "Contain runaway agents issuing destructive git commands",
+	Long: `Rate limit the destructive git commands agents issue: force pushes
+(--force, --force-with-lease, +refspec), remote branch deletions
+(push --delete, :refspec), and forced local deletions (branch -D).
+
+Every Bash command an agent runs passes through a PreToolUse hook
+(gt ratelimit check). When an agent issues more destructive commands than
+the limit within the window, its circuit breaker trips: the command is
+refused, an escalation is filed, and every git command the agent issues
+after that is blocked until the overseer runs gt ratelimit reset.
+
+Configure in <town>/settings/rate-limits.json:
+
+  {"type": "rate-limits", "version": 1, "mode": "block",
+   "limit": 3, "window": "10m", "severity": "high"}
+
+mode is block, warn (trip and escalate, but don't block), or off.`,
+	RunE: requireSubcommand,
+}
+
+var ratelimitCheckCmd = &cobra.Command{
+	Use:   "check [command]",
+	Short: "Check a shell command against the rate limit (PreToolUse hook)",
+	Long: `Check a shell command the current agent is about to run.
+
+With no arguments, reads the Claude Code PreToolUse hook input from stdin.
+Exits 2 with the reason on stderr when the command is blocked, which makes
+the agent's runtime refuse to run it.
+
+Humans (no GT_ROLE) are never rate limited.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: runRatelimitCheck,
+}
+
+var ratelimitStatusCmd = &cobra.Command{
+	Use:   "status [agent]",
+	Short: "Show agents' recent destructive commands and tripped breakers",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runRatelimitStatus,
+}
+
+var ratelimitResetCmd = &cobra.Command{
+	Use:   "reset <agent>",
+	Short: "Close a tripped breaker so the agent can use git again",
+	Long: `Reset an agent's rate limit: its breaker is closed, its recent
+destructive commands are forgotten, and the escalation filed when it
+tripped is closed. Only the overseer may reset a breaker.
+
+Example:
+  gt ratelimit reset gastown/polecats/nux`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRatelimitReset,
+}
+
+func init() {
+	ratelimitStatusCmd.Flags().BoolVar(&ratelimitStatusJSON, "json", false, "Output as JSON")
+
+	ratelimitCmd.AddCommand(ratelimitCheckCmd)
+	ratelimitCmd.AddCommand(ratelimitStatusCmd)
+	ratelimitCmd.AddCommand(ratelimitResetCmd)
+	rootCmd.AddCommand(ratelimitCmd)
+}
+
+// runRatelimitCheck is the PreToolUse hook. It fails open: a missing
+// workspace or broken config must not stop agents from working.
+func runRatelimitCheck(cmd *cobra.Command, args []string) error {
+	command := strings.Join(args, " ")
+	if command == "" {
+		if input := readStdinJSON(); input != nil {
+			command = input.ToolInput.Command
+		}
+	}
+	// Most commands aren't git at all; answer those without touching the town.
+	if os.Getenv("GT_ROLE") == "" || len(ratelimit.ParseGit(command)) == 0 {
+		return nil
+	}
+	agent := detectSender()
+
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return nil
+	}
+	if roleInfo, err := GetRole(); err == nil {
+		if err := checkGitCommandGate(townRoot, roleInfo.Role, roleInfo.Rig, command); err != nil {
+			fmt.Fprintf(os.Stderr, "Blocked: %v\n", err)
+			return NewSilentExit(2)
+		}
+	}
+	cfg, err := config.LoadOrCreateRateLimitConfig(config.RateLimitConfigPath(townRoot))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s rate limit config invalid, skipping: %v\n", style.WarningPrefix, err)
+		return nil
+	}
+
+	limiter := ratelimit.New(townRoot, cfg)
+	d, err := limiter.Check(agent, command)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s rate limit check failed, allowing: %v\n", style.WarningPrefix, err)
+		return nil
+	}
+
+	if d.Tripped {
+		escalateRateLimit(townRoot, cfg, limiter, agent, d.State)
+	}
+	switch {
+	case d.Block && d.Tripped:
+		fmt.Fprintf(os.Stderr, "Blocked: %s. Your git commands are suspended and the overseer has been notified.\n"+
+			"Stop, and explain what you were doing with gt mail send mayor/ while you wait.\n", d.State.Reason)
+		return NewSilentExit(2)
+	case d.Block:
+		fmt.Fprintf(os.Stderr, "Blocked: git is suspended for %s since %s (%s).\n"+
+			"Only the overseer can lift this (gt ratelimit reset %s).\n",
+			agent, d.State.TrippedAt.Format(time.Kitchen), d.State.Reason, agent)
+		return NewSilentExit(2)
+	case d.Tripped:
+		fmt.Fprintf(os.Stderr, "%s %s; the overseer has been notified.\n", style.WarningPrefix, d.State.Reason)
+	}
+	return nil
+}
+
+// escalateRateLimit files the escalation for a tripped breaker and records
+// it on the agent's state, so reset can close it.
+func escalateRateLimit(townRoot string, cfg *config.RateLimitConfig, limiter *ratelimit.Limiter, agent string, s *ratelimit.State) {
+	escalationConfig, err := config.LoadOrCreateEscalationConfig(config.EscalationConfigPath(townRoot))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s loading escalation config: %v\n", style.WarningPrefix, err)
+		return
+	}
+	severity := cfg.Severity
+	if severity == "" {
+		severity = config.SeverityHigh
+	}
+
+	var reason strings.Builder
+	fmt.Fprintf(&reason, "%s. Git is blocked for %s until: gt ratelimit reset %s\n\nRecent destructive commands:\n",
+		s.Reason, agent, agent)
+	for _, e := range s.Recent {
+		fmt.Fprintf(&reason, "  %s  %-13s %s\n", e.At.Format("15:04:05"), e.Kind, e.Command)
+	}
+
+	issue, _, _, err := fileEscalation(townRoot, escalationConfig, agent, severity,
+		fmt.Sprintf("Rate limit tripped: %s", agent), reason.String(), "ratelimit", "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s escalating tripped rate limit: %v\n", style.WarningPrefix, err)
+		return
+	}
+	if err := limiter.SetEscalation(agent, issue.ID); err != nil {
+		fmt.Fprintf(os.Stderr, "%s recording escalation %s: %v\n", style.WarningPrefix, issue.ID, err)
+	}
+}
+
+func runRatelimitStatus(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := config.LoadOrCreateRateLimitConfig(config.RateLimitConfigPath(townRoot))
+	if err != nil {
+		return err
+	}
+	limiter := ratelimit.New(townRoot, cfg)
+
+	var states []*ratelimit.State
+	if len(args) == 1 {
+		s, err := limiter.Get(args[0])
+		if err != nil {
+			return err
+		}
+		states = append(states, s)
+	} else if states, err = limiter.List(); err != nil {
+		return err
+	}
+
+	if ratelimitStatusJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(states)
+	}
+
+	mode := cfg.Mode
+	if mode == "" {
+		mode = config.GateActionBlock
+	}
+	fmt.Printf("%s %d destructive git command(s) per %s (mode: %s)\n\n",
+		style.Bold.Render("Rate limit:"), cfg.GetLimit(), cfg.GetWindow(), mode)
+	if len(states) == 0 {
+		fmt.Printf("%s No destructive git commands recorded\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	cutoff := time.Now().Add(-cfg.GetWindow())
+	for _, s := range states {
+		recent := 0
+		for _, e := range s.Recent {
+			if e.At.After(cutoff) {
+				recent++
+			}
+		}
+		if s.Tripped {
+			fmt.Printf("  %s %s  tripped %s, %d blocked\n", style.Error.Render("✗"), style.Bold.Render(s.Agent),
+				s.TrippedAt.Format("2006-01-02 15:04"), s.Blocked)
+			fmt.Printf("      %s\n", style.Dim.Render(s.Reason))
+			if s.Escalation != "" {
+				fmt.Printf("      %s\n", style.Dim.Render("escalation "+s.Escalation))
+			}
+			continue
+		}
+		fmt.Printf("  %s %s  %d/%d in window\n", style.Success.Render("✓"), s.Agent, recent, cfg.GetLimit())
+	}
+	return nil
+}
+
+func runRatelimitReset(cmd *cobra.Command, args []string) error {
+	if role := os.Getenv("GT_ROLE"); role != "" {
+		return fmt.Errorf("rate limits are reset by the overseer, not agents (GT_ROLE=%s)", role)
+	}
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := config.LoadOrCreateRateLimitConfig(config.RateLimitConfigPath(townRoot))
+	if err != nil {
+		return err
+	}
+	limiter := ratelimit.New(townRoot, cfg)
+
+	agent := args[0]
+	s, err := limiter.Get(agent)
+	if err != nil {
+		return err
+	}
+	if err := limiter.Reset(agent); err != nil {
+		return err
+	}
+	if s.Escalation != "" {
+		if err := beadStoreFor(townRoot, s.Escalation).CloseWithReason("rate limit reset by overseer", s.Escalation); err != nil {
+			style.PrintWarning("could not close escalation %s: %v", s.Escalation, err)
+		}
+	}
+
+	if s.Tripped {
+		fmt.Printf("%s Reset %s; git is unblocked\n", style.Bold.Render("✓"), agent)
+	} else {
+		fmt.Printf("%s Reset %s (breaker was not tripped)\n", style.Bold.Render("✓"), agent)
+	}
+	return nil
+}
@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/keepalive"
 	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/style"
@@ -217,6 +221,28 @@ Examples:
 
 var refineryBlockedJSON bool
 
+var refineryProcessCmd = &cobra.Command{
+	Use:   "process [rig]",
+	Short: "Merge the next batch of ready MRs",
+	Long: `Claim and merge the next batch of ready merge requests.
+
+With the worktree pool enabled (merge_queue.worktree_pool in the rig's
+config.json), up to max_concurrent MRs are merged and tested at once, each
+in its own pool worktree. Otherwise one MR is processed.
+
+Merged MRs are pushed and closed along with their source issues. Failed
+MRs are reported to the Witness (conflicts get a resolution task) and
+released back to the queue.
+
+Examples:
+  gt refinery process
+  gt refinery process --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRefineryProcess,
+}
+
+var refineryProcessJSON bool
+
 func init() {
 	// Start flags
 	refineryStartCmd.Flags().BoolVar(&refineryForeground, "foreground", false, "Run in foreground (default: background)")
@@ -243,6 +269,9 @@ func init() {
 	// Blocked flags
 	refineryBlockedCmd.Flags().BoolVar(&refineryBlockedJSON, "json", false, "Output as JSON")
 
+	// Process flags
+	refineryProcessCmd.Flags().BoolVar(&refineryProcessJSON, "json", false, "Output as JSON")
+
 	// Add subcommands
 	refineryCmd.AddCommand(refineryStartCmd)
 	refineryCmd.AddCommand(refineryStopCmd)
@@ -255,6 +284,7 @@ func init() {
 	refineryCmd.AddCommand(refineryUnclaimedCmd)
 	refineryCmd.AddCommand(refineryReadyCmd)
 	refineryCmd.AddCommand(refineryBlockedCmd)
+	refineryCmd.AddCommand(refineryProcessCmd)
 
 	rootCmd.AddCommand(refineryCmd)
 }
@@ -690,6 +720,13 @@ func runRefineryReady(cmd *cobra.Command, args []string) error {
 	// Create engineer for the rig (it has beads access for status checking)
 	eng := refinery.NewEngineer(r)
 
+	// A paused queue has nothing ready; say so rather than "(none ready)"
+	if reason, paused := refinery.QueuePaused(filepath.Dir(r.Path), rigName); paused && !refineryReadyJSON {
+		fmt.Printf("%s Merge queue for '%s' is paused: %s\n", style.Warning.Render("⏸"), rigName, reason)
+		fmt.Printf("  Resume with: gt refinery resume %s\n", rigName)
+		return nil
+	}
+
 	// Get ready MRs (unclaimed AND unblocked)
 	ready, err := eng.ListReadyMRs()
 	if err != nil {
@@ -766,3 +803,68 @@ func runRefineryBlocked(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// refineryProcessed is one MR handled by gt refinery process.
+type refineryProcessed struct {
+	*refinery.MRInfo
+	Result refinery.ProcessResult `json:"result"`
+}
+
+func runRefineryProcess(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+
+	_, r, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	if reason, paused := refinery.QueuePaused(filepath.Dir(r.Path), rigName); paused {
+		return fmt.Errorf("merge queue for '%s' is paused: %s", rigName, reason)
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	if refineryProcessJSON {
+		eng.SetOutput(os.Stderr)
+	}
+
+	// Merging and testing a batch can take many minutes
+	hb := keepalive.StartInWorkspace(filepath.Dir(r.Path), "gt refinery process", "processing ready MRs")
+	mrs, results, err := eng.ProcessReady(context.Background(), rigName+"/refinery")
+	hb.Stop()
+	if err != nil {
+		return err
+	}
+
+	processed := make([]refineryProcessed, len(mrs))
+	for i, mr := range mrs {
+		processed[i] = refineryProcessed{MRInfo: mr, Result: results[i]}
+	}
+	if refineryProcessJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(processed)
+	}
+
+	fmt.Println()
+	if len(processed) == 0 {
+		fmt.Printf("%s No ready MRs for '%s'\n", style.Dim.Render("○"), rigName)
+		return nil
+	}
+	for _, p := range processed {
+		if p.Result.Success {
+			fmt.Printf("%s Merged %s (%s → %s) at %s\n", style.Bold.Render("✓"), p.ID, p.Branch, p.Target, git.ShortSHA(p.Result.MergeCommit))
+			continue
+		}
+		if p.Result.Requeue {
+			fmt.Printf("%s Requeued %s (%s): %s\n", style.Dim.Render("↻"), p.ID, p.Branch, p.Result.Error)
+			continue
+		}
+		fmt.Printf("%s Failed %s (%s): %s\n", style.Error.Render("✗"), p.ID, p.Branch, p.Result.Error)
+	}
+	return nil
+}
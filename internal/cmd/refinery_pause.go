@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var refineryPauseReason string
+
+var refineryPauseCmd = &cobra.Command{
+	Use:   "pause [rig]",
+	Short: "Pause a rig's merge queue",
+	Long: `Pause a rig's merge queue.
+
+While paused, 'gt refinery ready' reports no MRs, so the Refinery stops
+picking up new merges. A merge already in progress finishes. Polecats can
+still submit MRs; they wait in the queue until it is resumed.
+
+The pause is local to this town (wisp layer). Alert rules with a
+pause_queue action pause the queue the same way.
+
+Examples:
+  gt refinery pause gastown --reason "main is red"
+  gt refinery resume gastown`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRefineryPause,
+}
+
+var refineryResumeCmd = &cobra.Command{
+	Use:   "resume [rig]",
+	Short: "Resume a paused merge queue",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runRefineryResume,
+}
+
+func init() {
+	refineryPauseCmd.Flags().StringVar(&refineryPauseReason, "reason", "", "Why the queue is paused (shown by gt refinery ready)")
+
+	refineryCmd.AddCommand(refineryPauseCmd)
+	refineryCmd.AddCommand(refineryResumeCmd)
+}
+
+func runRefineryPause(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+	_, r, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	townRoot := filepath.Dir(r.Path)
+
+	if reason, paused := refinery.QueuePaused(townRoot, rigName); paused {
+		fmt.Printf("%s Merge queue for %s already paused: %s\n", style.Dim.Render("○"), rigName, reason)
+		return nil
+	}
+	if err := refinery.PauseQueue(townRoot, rigName, refineryPauseReason); err != nil {
+		return fmt.Errorf("pausing merge queue: %w", err)
+	}
+	_ = events.Emit(events.Event{
+		Type:       events.TypeQueuePaused,
+		Actor:      detectSender(),
+		Rig:        rigName,
+		Payload:    events.QueuePausePayload(rigName, refineryPauseReason),
+		Visibility: events.VisibilityFeed,
+	})
+
+	fmt.Printf("%s Merge queue for %s paused\n", style.Bold.Render("✓"), rigName)
+	fmt.Printf("  Resume with: gt refinery resume %s\n", rigName)
+	return nil
+}
+
+func runRefineryResume(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+	_, r, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	townRoot := filepath.Dir(r.Path)
+
+	if _, paused := refinery.QueuePaused(townRoot, rigName); !paused {
+		fmt.Printf("%s Merge queue for %s is not paused\n", style.Dim.Render("○"), rigName)
+		return nil
+	}
+	if err := refinery.ResumeQueue(townRoot, rigName); err != nil {
+		return fmt.Errorf("resuming merge queue: %w", err)
+	}
+	_ = events.Emit(events.Event{
+		Type:       events.TypeQueueResumed,
+		Actor:      detectSender(),
+		Rig:        rigName,
+		Payload:    events.QueuePausePayload(rigName, ""),
+		Visibility: events.VisibilityFeed,
+	})
+
+	fmt.Printf("%s Merge queue for %s resumed\n", style.Bold.Render("✓"), rigName)
+	return nil
+}
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/refinery/refinerytest"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	refinerySimRecord       string
+	refinerySimReplay       string
+	refinerySimConfig       string
+	refinerySimTestDuration time.Duration
+	refinerySimResolveAfter time.Duration
+	refinerySimHorizon      time.Duration
+	refinerySimEvents       bool
+	refinerySimJSON         bool
+)
+
+var refinerySimulateCmd = &cobra.Command{
+	Use:   "simulate [rig]",
+	Short: "Replay a recorded merge queue against proposed config changes",
+	Long: `Simulate a rig's merge queue without touching any repository.
+
+Record the live queue to a file, then replay it through the Refinery's
+queue policy (scoring and priority aging, conflict handling and the merge
+slot, test retries, pooled merges rebuilt when the target moves) under the
+rig's current merge_queue config and a proposed one, and compare the two.
+
+--record snapshots the ready and blocked MRs with their priority, age,
+convoy age, and the files each branch changes. Two MRs touching the same
+file are treated as conflicting once one of them lands; blocked MRs replay
+one conflict. Test runs take --test-duration and conflict resolution takes
+--resolve-after. The recording is plain JSON: edit it to script test
+outcomes ("tests": ["fail", "pass"]), forced conflicts, or extra MRs.
+
+--config is a JSON file holding a merge_queue section, or a whole rig
+config.json; fields it sets override the rig's current config.
+
+Examples:
+  gt refinery simulate gastown --record queue.json
+  gt refinery simulate gastown --replay queue.json --config proposed.json
+  gt refinery simulate gastown --replay queue.json --config proposed.json --events`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRefinerySimulate,
+}
+
+func init() {
+	refinerySimulateCmd.Flags().StringVar(&refinerySimRecord, "record", "", "Record the live queue to this file")
+	refinerySimulateCmd.Flags().StringVar(&refinerySimReplay, "replay", "", "Replay the recorded queue in this file")
+	refinerySimulateCmd.Flags().StringVar(&refinerySimConfig, "config", "", "Proposed merge_queue config (JSON) to compare against the current one")
+	refinerySimulateCmd.Flags().DurationVar(&refinerySimTestDuration, "test-duration", 10*time.Minute, "Duration of one test run (with --record)")
+	refinerySimulateCmd.Flags().DurationVar(&refinerySimResolveAfter, "resolve-after", 30*time.Minute, "Time to resolve a conflict (with --record)")
+	refinerySimulateCmd.Flags().DurationVar(&refinerySimHorizon, "horizon", refinerytest.DefaultHorizon, "Stop the simulation after this much simulated time")
+	refinerySimulateCmd.Flags().BoolVar(&refinerySimEvents, "events", false, "Print the simulated event log")
+	refinerySimulateCmd.Flags().BoolVar(&refinerySimJSON, "json", false, "Output as JSON")
+
+	refineryCmd.AddCommand(refinerySimulateCmd)
+}
+
+func runRefinerySimulate(cmd *cobra.Command, args []string) error {
+	if (refinerySimRecord == "") == (refinerySimReplay == "") {
+		return fmt.Errorf("exactly one of --record or --replay is required")
+	}
+	if refinerySimConfig != "" && refinerySimReplay == "" {
+		return fmt.Errorf("--config requires --replay")
+	}
+
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+	_, r, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+
+	if refinerySimRecord != "" {
+		return recordRefineryQueue(eng, r.Path, rigName)
+	}
+
+	rec, err := refinerytest.LoadRecording(refinerySimReplay)
+	if err != nil {
+		return fmt.Errorf("loading recording: %w", err)
+	}
+	current := eng.Config()
+	opts := refinerytest.Options{Horizon: refinerySimHorizon}
+	reports := map[string]*refinerytest.Report{
+		"current": refinerytest.Simulate(rec, current, opts),
+	}
+	if refinerySimConfig != "" {
+		proposed, err := loadProposedMergeQueueConfig(current, refinerySimConfig)
+		if err != nil {
+			return err
+		}
+		reports["proposed"] = refinerytest.Simulate(rec, proposed, opts)
+	}
+
+	if refinerySimJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(reports)
+	}
+	printSimulation(rigName, rec, reports)
+	return nil
+}
+
+// recordRefineryQueue snapshots the rig's ready and blocked MRs to
+// refinerySimRecord.
+func recordRefineryQueue(eng *refinery.Engineer, rigPath, rigName string) error {
+	if reason, paused := refinery.QueuePaused(filepath.Dir(rigPath), rigName); paused {
+		style.PrintWarning("merge queue is paused (%s); no ready MRs will be recorded", reason)
+	}
+	ready, err := eng.ListReadyMRs()
+	if err != nil {
+		return fmt.Errorf("listing ready MRs: %w", err)
+	}
+	blocked, err := eng.ListBlockedMRs()
+	if err != nil {
+		return fmt.Errorf("listing blocked MRs: %w", err)
+	}
+
+	// Same checkout the Engineer merges in
+	gitDir := filepath.Join(rigPath, "refinery", "rig")
+	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
+		gitDir = filepath.Join(rigPath, "mayor", "rig")
+	}
+	g := git.NewGit(gitDir)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	rec := &refinerytest.Recording{
+		Rig:          rigName,
+		Target:       eng.Config().TargetBranch,
+		Start:        now,
+		TestDuration: refinerytest.Duration(refinerySimTestDuration),
+		ResolveAfter: refinerytest.Duration(refinerySimResolveAfter),
+	}
+	add := func(mr *refinery.MRInfo, isBlocked bool) {
+		var files []string
+		if stat, err := g.DiffStat("origin/"+mr.Target, mr.Branch); err == nil {
+			for _, f := range stat.Files {
+				files = append(files, f.Path)
+			}
+		}
+		rec.MRs = append(rec.MRs, scriptedMRFromInfo(mr, files, isBlocked, now))
+	}
+	for _, mr := range ready {
+		add(mr, false)
+	}
+	for _, mr := range blocked {
+		add(mr, true)
+	}
+
+	if err := rec.Save(refinerySimRecord); err != nil {
+		return fmt.Errorf("writing recording: %w", err)
+	}
+	fmt.Printf("%s Recorded %d MR(s) (%d ready, %d blocked) to %s\n",
+		style.Bold.Render("✓"), len(rec.MRs), len(ready), len(blocked), refinerySimRecord)
+	fmt.Printf("  Replay with: gt refinery simulate %s --replay %s --config <proposed.json>\n", rigName, refinerySimRecord)
+	return nil
+}
+
+// scriptedMRFromInfo records a queued MR as seen at now. A blocked MR is
+// waiting on conflict resolution, so it replays one conflict.
+func scriptedMRFromInfo(mr *refinery.MRInfo, files []string, blocked bool, now time.Time) refinerytest.ScriptedMR {
+	s := refinerytest.ScriptedMR{
+		ID:         mr.ID,
+		Branch:     mr.Branch,
+		Priority:   mr.Priority,
+		RetryCount: mr.RetryCount,
+		Files:      files,
+	}
+	if !mr.CreatedAt.IsZero() {
+		s.SubmittedAt = refinerytest.Duration(mr.CreatedAt.Sub(now))
+	}
+	if mr.ConvoyCreatedAt != nil {
+		age := refinerytest.Duration(now.Sub(*mr.ConvoyCreatedAt))
+		s.ConvoyAge = &age
+	}
+	if blocked {
+		s.Conflicts = 1
+	}
+	return s
+}
+
+// loadProposedMergeQueueConfig returns current with the merge_queue config in
+// path applied. path holds either a merge_queue section or a whole rig config.
+func loadProposedMergeQueueConfig(current *refinery.MergeQueueConfig, path string) (*refinery.MergeQueueConfig, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is from the user
+	if err != nil {
+		return nil, fmt.Errorf("reading proposed config: %w", err)
+	}
+	var wrapped struct {
+		MergeQueue json.RawMessage `json:"merge_queue"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	raw := json.RawMessage(data)
+	if wrapped.MergeQueue != nil {
+		raw = wrapped.MergeQueue
+	}
+
+	proposed := *current
+	if err := refinery.ApplyMergeQueueConfig(&proposed, raw); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &proposed, nil
+}
+
+func printSimulation(rigName string, rec *refinerytest.Recording, reports map[string]*refinerytest.Report) {
+	fmt.Printf("%s Simulated merge queue for '%s' (%d MR(s) from %s)\n\n",
+		style.Bold.Render("⚙"), rigName, len(rec.MRs), refinerySimReplay)
+
+	columns := []string{"current"}
+	if reports["proposed"] != nil {
+		columns = append(columns, "proposed")
+	}
+	row := func(label string, value func(*refinerytest.Report) string) {
+		line := fmt.Sprintf("  %-15s", label)
+		for _, c := range columns {
+			line += fmt.Sprintf(" %-12s", value(reports[c]))
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+	count := func(n func(*refinerytest.Report) int) func(*refinerytest.Report) string {
+		return func(r *refinerytest.Report) string { return fmt.Sprintf("%d", n(r)) }
+	}
+	duration := func(d func(*refinerytest.Report) refinerytest.Duration) func(*refinerytest.Report) string {
+		return func(r *refinerytest.Report) string { return time.Duration(d(r)).Round(time.Second).String() }
+	}
+
+	header := fmt.Sprintf("  %-15s", "")
+	for _, c := range columns {
+		header += fmt.Sprintf(" %-12s", c)
+	}
+	fmt.Println(style.Dim.Render(strings.TrimRight(header, " ")))
+	row("workers", count(func(r *refinerytest.Report) int { return r.Workers }))
+	row("merged", count(func(r *refinerytest.Report) int { return r.Merged }))
+	row("failed", count(func(r *refinerytest.Report) int { return r.Failed }))
+	row("pending", count(func(r *refinerytest.Report) int { return r.Pending }))
+	row("conflicts", count(func(r *refinerytest.Report) int { return r.Conflicts }))
+	row("slot deferrals", count(func(r *refinerytest.Report) int { return r.SlotDeferrals }))
+	row("test runs", count(func(r *refinerytest.Report) int { return r.TestRuns }))
+	row("rebuilds", count(func(r *refinerytest.Report) int { return r.Rebuilds }))
+	row("makespan", duration(func(r *refinerytest.Report) refinerytest.Duration { return r.Makespan }))
+	row("mean wait", duration(func(r *refinerytest.Report) refinerytest.Duration { return r.MeanWait }))
+	row("max wait", duration(func(r *refinerytest.Report) refinerytest.Duration { return r.MaxWait }))
+
+	fmt.Println()
+	for _, c := range columns {
+		fmt.Printf("  Merge order (%s): %s\n", c, strings.Join(reports[c].MergeOrder, " "))
+	}
+
+	if refinerySimEvents {
+		for _, c := range columns {
+			fmt.Printf("\n%s Events (%s):\n", style.Bold.Render("⚙"), c)
+			for _, e := range reports[c].Events {
+				fmt.Printf("  %s\n", style.Dim.Render(e.String()))
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/refinery"
+)
+
+func TestLoadProposedMergeQueueConfig(t *testing.T) {
+	current := refinery.DefaultMergeQueueConfig()
+	current.TestCommand = "make test"
+
+	tests := map[string]string{
+		"section":     `{"worktree_pool": true, "max_concurrent": 4, "retry_flaky_tests": 2}`,
+		"full config": `{"name": "gastown", "merge_queue": {"worktree_pool": true, "max_concurrent": 4, "retry_flaky_tests": 2}}`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "proposed.json")
+			if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := loadProposedMergeQueueConfig(current, path)
+			if err != nil {
+				t.Fatalf("loadProposedMergeQueueConfig: %v", err)
+			}
+			if got.Concurrency() != 4 || got.RetryFlakyTests != 2 {
+				t.Errorf("proposed = %+v", got)
+			}
+			if got.TestCommand != "make test" {
+				t.Errorf("unset fields should keep current values, TestCommand = %q", got.TestCommand)
+			}
+			if current.WorktreePool {
+				t.Error("current config was modified")
+			}
+		})
+	}
+}
+
+func TestScriptedMRFromInfo(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	convoy := now.Add(-26 * time.Hour)
+	mr := &refinery.MRInfo{
+		ID:              "gt-mr1",
+		Branch:          "polecat/nux",
+		Priority:        1,
+		RetryCount:      2,
+		CreatedAt:       now.Add(-90 * time.Minute),
+		ConvoyCreatedAt: &convoy,
+	}
+
+	s := scriptedMRFromInfo(mr, []string{"a.go"}, true, now)
+	if time.Duration(s.SubmittedAt) != -90*time.Minute {
+		t.Errorf("SubmittedAt = %v", time.Duration(s.SubmittedAt))
+	}
+	if s.ConvoyAge == nil || time.Duration(*s.ConvoyAge) != 26*time.Hour {
+		t.Errorf("ConvoyAge = %v", s.ConvoyAge)
+	}
+	if s.Conflicts != 1 || s.RetryCount != 2 || len(s.Files) != 1 {
+		t.Errorf("scripted = %+v", s)
+	}
+}
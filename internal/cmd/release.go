@@ -27,7 +27,9 @@ Examples:
   gt release gt-abc -r "worker died"  # Release with reason
 
 This implements nondeterministic idempotence - work can be safely
-retried by releasing and reclaiming stuck steps.`,
+retried by releasing and reclaiming stuck steps.
+
+For release notes compiled from merge history, see gt release notes.`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runRelease,
 }
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/changelog"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/identity"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	releaseNotesSince  string
+	releaseNotesUntil  string
+	releaseNotesRig    string
+	releaseNotesOutput string
+	releaseNotesJSON   bool
+)
+
+var releaseNotesCmd = &cobra.Command{
+	Use:   "notes",
+	Short: "Compile release notes from merge-queue history",
+	Long: `Compile release notes for a rig from its merge-queue history.
+
+Every merge the Refinery lands is recorded in the town's changelog
+(logs/changelog.jsonl) with its issue, title, worker, and merge commit.
+The notes list the recorded merges whose commits are between --since and
+--until in the rig's repo, grouped into features, fixes, and other changes.
+
+Examples:
+  gt release notes --since v1.2.0
+  gt release notes --since v1.2.0 --until v1.3.0 --rig gastown
+  gt release notes --since v1.2.0 -o RELEASE_NOTES.md`,
+	Args: cobra.NoArgs,
+	RunE: runReleaseNotes,
+}
+
+func init() {
+	releaseNotesCmd.Flags().StringVar(&releaseNotesSince, "since", "", "Tag or commit the notes start after (required)")
+	releaseNotesCmd.Flags().StringVar(&releaseNotesUntil, "until", "", "Tag or commit the notes end at (default: the rig's default branch)")
+	releaseNotesCmd.Flags().StringVar(&releaseNotesRig, "rig", "", "Rig (default: the rig you are in)")
+	releaseNotesCmd.Flags().StringVarP(&releaseNotesOutput, "output", "o", "", "Write the notes to a file")
+	releaseNotesCmd.Flags().BoolVar(&releaseNotesJSON, "json", false, "Output the changelog entries as JSON")
+	_ = releaseNotesCmd.MarkFlagRequired("since")
+	releaseCmd.AddCommand(releaseNotesCmd)
+}
+
+func runReleaseNotes(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	var r *rig.Rig
+	if releaseNotesRig != "" {
+		_, r, err = getRig(releaseNotesRig)
+	} else {
+		_, r, err = findCurrentRig(townRoot)
+	}
+	if err != nil {
+		return fmt.Errorf("%w (use --rig)", err)
+	}
+
+	g := releaseNotesGit(r)
+	_ = g.Fetch("origin") // Best effort: notes can still come from what we have
+	until := releaseNotesUntil
+	if until == "" {
+		until = "origin/" + r.DefaultBranch()
+	}
+	commits, err := g.CommitsBetween(releaseNotesSince, until)
+	if err != nil {
+		return fmt.Errorf("listing commits %s..%s: %w", releaseNotesSince, until, err)
+	}
+
+	entries, err := changelog.Read(townRoot, r.Name)
+	if err != nil {
+		return err
+	}
+	entries = changelog.InCommits(entries, commits)
+
+	if releaseNotesJSON {
+		if releaseNotesOutput != "" {
+			return writeJSON(releaseNotesOutput, entries)
+		}
+		return outputJSON(entries)
+	}
+
+	var w io.Writer = os.Stdout
+	if releaseNotesOutput != "" {
+		f, err := os.Create(releaseNotesOutput)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	untilName := releaseNotesUntil
+	if untilName == "" {
+		untilName = r.DefaultBranch()
+	}
+	title := fmt.Sprintf("%s release notes: %s..%s", r.Name, releaseNotesSince, untilName)
+	if err := changelog.Render(w, title, entries); err != nil {
+		return err
+	}
+	if releaseNotesOutput != "" {
+		fmt.Printf("%s Wrote notes for %d merges to %s\n", style.Bold.Render("✓"), len(entries), releaseNotesOutput)
+	}
+	return nil
+}
+
+// releaseNotesGit returns a git for the rig's repo: the Mayor's clone, or
+// the Refinery's worktree if the rig has no Mayor clone.
+func releaseNotesGit(r *rig.Rig) *git.Git {
+	dir := filepath.Join(r.Path, "mayor", "rig")
+	if _, err := os.Stat(dir); err != nil {
+		dir = filepath.Join(r.Path, "refinery", "rig")
+	}
+	return rig.WithGitAuth(r.Path, git.NewGit(dir))
+}
+
+// recordMergeChangelog appends the merge reported by a MERGED protocol
+// message to the town's changelog, so merges reported by hand make the
+// release notes too. Other messages are ignored.
+func recordMergeChangelog(townRoot string, msg *mail.Message) {
+	if townRoot == "" || protocol.ParseMessageType(msg.Subject) != protocol.TypeMerged {
+		return
+	}
+	p := protocol.ParseMergedPayload(msg.Body)
+	if p.Rig == "" || p.MergeCommit == "" {
+		return
+	}
+	entry := changelog.Entry{
+		Merged: p.MergedAt,
+		Rig:    p.Rig,
+		Issue:  p.Issue,
+		Branch: p.Branch,
+		Target: p.TargetBranch,
+		Commit: p.MergeCommit,
+	}
+	if p.Polecat != "" {
+		entry.Worker = identity.New(identity.Polecat, p.Rig, p.Polecat).Actor()
+	}
+	if p.Issue != "" {
+		if issue, err := beads.New(filepath.Join(townRoot, p.Rig)).Show(p.Issue); err == nil && issue != nil {
+			entry.Title, entry.Type = issue.Title, issue.Type
+		}
+	}
+	if entry.Title == "" {
+		entry.Title = p.Branch
+	}
+	if err := changelog.Append(townRoot, entry); err != nil {
+		style.PrintWarning("could not record merge in changelog: %v", err)
+	}
+}
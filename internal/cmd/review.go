@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/identity"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/review"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	reviewJSON     bool
+	reviewRig      string
+	reviewMessage  string
+	reviewReviewer string
+)
+
+var reviewCmd = &cobra.Command{
+	Use:     "review",
+	GroupID: GroupWork,
+	Short:   "Review merge requests before they enter the queue",
+	Long: `Review merge requests before the Refinery merges them.
+
+When a rig enables review (settings/config.json: "review": {"enabled": true}),
+gt done opens a review bead for each new MR and slings it to the rig's
+reviewer. The review bead lists the diff summary, changed files, and a
+checklist. The MR depends on the review, so it stays out of the merge queue
+until the review is approved.
+
+Requesting changes mails the worker with the reviewer's note. The MR stays
+held; when the worker pushes fixes and runs gt done again, the review goes
+back to the reviewer for another round.
+
+Reviews are named by the review bead or the MR under review.
+
+Examples:
+  gt review list
+  gt review approve gt-mr-abc -m "LGTM"
+  gt review request-changes gt-mr-abc -m "Handle the empty-input case"
+  gt review request gt-mr-abc --reviewer gastown/crew/max`,
+	RunE: requireSubcommand,
+}
+
+var reviewListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List open reviews in a rig",
+	Args:  cobra.NoArgs,
+	RunE:  runReviewList,
+}
+
+var reviewRequestCmd = &cobra.Command{
+	Use:   "request <mr-id>",
+	Short: "Open a review of an MR and sling it to a reviewer",
+	Long: `Open a review of an MR and sling it to a reviewer, holding the MR out of
+the merge queue until the review is approved. gt done does this
+automatically in rigs with review enabled; use this for an MR in any rig.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReviewRequest,
+}
+
+var reviewApproveCmd = &cobra.Command{
+	Use:   "approve <id>",
+	Short: "Approve a review, releasing the MR into the merge queue",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runReviewApprove,
+}
+
+var reviewRequestChangesCmd = &cobra.Command{
+	Use:   "request-changes <id>",
+	Short: "Send an MR back to its worker with changes to make",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runReviewRequestChanges,
+}
+
+func init() {
+	reviewListCmd.Flags().BoolVar(&reviewJSON, "json", false, "Output as JSON")
+	for _, c := range []*cobra.Command{reviewListCmd, reviewRequestCmd, reviewApproveCmd, reviewRequestChangesCmd} {
+		c.Flags().StringVar(&reviewRig, "rig", "", "Rig holding the MR (default: current rig)")
+	}
+	reviewRequestCmd.Flags().StringVar(&reviewReviewer, "reviewer", "", "Sling target for the review (default: the rig's configured reviewer)")
+	reviewApproveCmd.Flags().StringVarP(&reviewMessage, "message", "m", "", "Approval note")
+	reviewRequestChangesCmd.Flags().StringVarP(&reviewMessage, "message", "m", "", "What the worker should change (required)")
+	_ = reviewRequestChangesCmd.MarkFlagRequired("message")
+
+	reviewCmd.AddCommand(reviewListCmd)
+	reviewCmd.AddCommand(reviewRequestCmd)
+	reviewCmd.AddCommand(reviewApproveCmd)
+	reviewCmd.AddCommand(reviewRequestChangesCmd)
+	rootCmd.AddCommand(reviewCmd)
+}
+
+// reviewRigContext resolves --rig (or the current rig) to the town root and rig.
+func reviewRigContext() (string, *rig.Rig, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return "", nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	if reviewRig != "" {
+		_, r, err := getRig(reviewRig)
+		return townRoot, r, err
+	}
+	_, r, err := findCurrentRig(townRoot)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w\nUse --rig to name the rig", err)
+	}
+	return townRoot, r, nil
+}
+
+// loadReviewConfig returns the rig's review settings, or nil if unset.
+func loadReviewConfig(rigPath string) *config.ReviewConfig {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil {
+		return nil
+	}
+	return settings.Review
+}
+
+// reviewerFor returns where to sling the review of an MR with the given
+// labels: --reviewer, else the reviewer routed to by the first label with
+// one (review.reviewers), else the rig's reviewer.
+func reviewerFor(cfg *config.ReviewConfig, rigName string, labels []string) string {
+	if reviewReviewer != "" {
+		return reviewReviewer
+	}
+	if cfg != nil {
+		sorted := append([]string(nil), labels...)
+		sort.Strings(sorted)
+		for _, label := range sorted {
+			if reviewer := cfg.Reviewers[label]; reviewer != "" {
+				return reviewer
+			}
+		}
+		if cfg.Reviewer != "" {
+			return cfg.Reviewer
+		}
+	}
+	return rigName
+}
+
+// reviewSummary gathers the diff summary for an MR's branch.
+// Git failures leave the summary without a diff rather than failing.
+func reviewSummary(g *git.Git, f *review.Fields, source *beads.Issue, cfg *config.ReviewConfig) review.Summary {
+	s := review.Summary{}
+	if source != nil {
+		s.Title = source.Title
+	}
+	if cfg != nil {
+		s.Checklist = cfg.Checklist
+	}
+	_ = g.Fetch("origin")
+	base, head := "origin/"+f.Target, "origin/"+f.Branch
+	if stat, err := g.DiffStat(base, head); err == nil {
+		s.Stat = stat
+	}
+	if commits, err := g.CommitsAhead(base, head); err == nil {
+		s.Commits = commits
+	}
+	return s
+}
+
+// openReview puts an MR under review: a new review bead slung to the
+// reviewer, or, if the MR already had changes requested, the next round of
+// its existing review. A pending review is left as it is.
+func openReview(bd *beads.Beads, g *git.Git, reviewer string, cfg *config.ReviewConfig, mrID string, mr *beads.MRFields, source *beads.Issue, priority int) (string, error) {
+	reviews := review.New(bd)
+	issue, f, err := reviews.ForMR(mrID)
+	switch {
+	case err == nil && f.Status == review.StatusChangesRequested:
+		if err := reviews.Resubmit(issue, f, reviewSummary(g, f, source, cfg)); err != nil {
+			return "", fmt.Errorf("resubmitting review %s: %w", issue.ID, err)
+		}
+		fmt.Printf("%s Review %s resubmitted (round %d)\n", style.Bold.Render("✓"), issue.ID, f.Round)
+		slingReview(issue.ID, f.Reviewer)
+		return issue.ID, nil
+	case err == nil:
+		fmt.Printf("%s Review %s already pending\n", style.Bold.Render("✓"), issue.ID)
+		return issue.ID, nil
+	case !errors.Is(err, review.ErrNotFound):
+		return "", err
+	}
+
+	f = &review.Fields{
+		MR:          mrID,
+		Branch:      mr.Branch,
+		Target:      mr.Target,
+		SourceIssue: mr.SourceIssue,
+		Worker:      mr.Worker,
+		Rig:         mr.Rig,
+		Reviewer:    reviewer,
+	}
+	issue, err = reviews.Open(f, reviewSummary(g, f, source, cfg), priority)
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("%s Review %s opened; MR held until approved\n", style.Bold.Render("✓"), issue.ID)
+	slingReview(issue.ID, reviewer)
+	return issue.ID, nil
+}
+
+// slingReview slings a review bead to its reviewer (best-effort: an
+// unslung review can still be approved by anyone who finds it).
+func slingReview(reviewID, reviewer string) {
+	c := exec.Command("gt", "sling", reviewID, reviewer, "--no-convoy") //nolint:gosec // G204: bead ID from bd, reviewer from config
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		style.PrintWarning("could not sling review %s to %s: %v", reviewID, reviewer, err)
+	}
+}
+
+// maybeOpenReview opens a review for a submitted MR if the rig requires one.
+func maybeOpenReview(rigPath string, bd *beads.Beads, g *git.Git, mrID string, mr *beads.MRFields, labels []string, source *beads.Issue, priority int) {
+	cfg := loadReviewConfig(rigPath)
+	if cfg == nil || !cfg.Enabled {
+		return
+	}
+	if _, err := openReview(bd, g, reviewerFor(cfg, mr.Rig, labels), cfg, mrID, mr, source, priority); err != nil {
+		style.PrintWarning("could not open review for %s: %v", mrID, err)
+	}
+}
+
+func runReviewList(cmd *cobra.Command, args []string) error {
+	_, r, err := reviewRigContext()
+	if err != nil {
+		return err
+	}
+	issues, err := review.New(beads.New(r.BeadsPath())).List()
+	if err != nil {
+		return fmt.Errorf("listing reviews: %w", err)
+	}
+
+	type reviewRow struct {
+		ID       string `json:"id"`
+		MR       string `json:"mr"`
+		Branch   string `json:"branch"`
+		Worker   string `json:"worker,omitempty"`
+		Reviewer string `json:"reviewer,omitempty"`
+		Status   string `json:"status"`
+		Round    int    `json:"round"`
+		Note     string `json:"note,omitempty"`
+	}
+	rows := []reviewRow{}
+	for _, issue := range issues {
+		f := review.ParseFields(issue)
+		if f == nil {
+			continue
+		}
+		rows = append(rows, reviewRow{
+			ID: issue.ID, MR: f.MR, Branch: f.Branch, Worker: f.Worker,
+			Reviewer: f.Reviewer, Status: f.Status, Round: f.Round, Note: f.Note,
+		})
+	}
+
+	if reviewJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	}
+	if len(rows) == 0 {
+		fmt.Printf("No open reviews in %s\n", r.Name)
+		return nil
+	}
+	fmt.Printf("%s\n\n", style.Bold.Render(fmt.Sprintf("Open reviews in %s (%d)", r.Name, len(rows))))
+	for _, row := range rows {
+		status := row.Status
+		if status == review.StatusChangesRequested {
+			status = style.Warning.Render(status)
+		}
+		fmt.Printf("  %s  %s  %s  round %d  %s\n", row.ID, row.MR, status, row.Round, style.Dim.Render(row.Branch))
+		if row.Reviewer != "" {
+			fmt.Printf("      reviewer: %s\n", row.Reviewer)
+		}
+		if row.Note != "" {
+			fmt.Printf("      note: %s\n", row.Note)
+		}
+	}
+	return nil
+}
+
+func runReviewRequest(cmd *cobra.Command, args []string) error {
+	_, r, err := reviewRigContext()
+	if err != nil {
+		return err
+	}
+	bd := beads.New(r.BeadsPath())
+	mr, err := bd.Show(args[0])
+	if err != nil {
+		return fmt.Errorf("looking up MR %s: %w", args[0], err)
+	}
+	fields := beads.ParseMRFields(mr)
+	if fields == nil || fields.Branch == "" {
+		return fmt.Errorf("%s is not a merge request", args[0])
+	}
+	if fields.Rig == "" {
+		fields.Rig = r.Name
+	}
+	if fields.Target == "" {
+		fields.Target = "main"
+	}
+	var source *beads.Issue
+	if fields.SourceIssue != "" {
+		source, _ = bd.Show(fields.SourceIssue)
+	}
+
+	cfg := loadReviewConfig(r.Path)
+	_, err = openReview(bd, git.NewGit(filepath.Join(r.Path, "mayor", "rig")), reviewerFor(cfg, r.Name, mr.Labels), cfg, mr.ID, fields, source, mr.Priority)
+	return err
+}
+
+// resolveReview finds the open review named by id in the --rig (or current) rig.
+func resolveReview(id string) (string, *rig.Rig, *review.Reviews, *beads.Issue, *review.Fields, error) {
+	townRoot, r, err := reviewRigContext()
+	if err != nil {
+		return "", nil, nil, nil, nil, err
+	}
+	reviews := review.New(beads.New(r.BeadsPath()))
+	issue, f, err := reviews.Resolve(id)
+	if err != nil {
+		return "", nil, nil, nil, nil, err
+	}
+
+	// Reviewing your own work defeats the point of the gate.
+	if f.Worker != "" && f.Rig != "" {
+		worker := identity.New(identity.Worker, f.Rig, f.Worker).Address()
+		if identity.Same(detectSender(), worker) {
+			return "", nil, nil, nil, nil, fmt.Errorf("%s is your own MR; another agent must review it", f.MR)
+		}
+	}
+	return townRoot, r, reviews, issue, f, nil
+}
+
+func runReviewApprove(cmd *cobra.Command, args []string) error {
+	_, _, reviews, issue, f, err := resolveReview(args[0])
+	if err != nil {
+		return err
+	}
+	if err := reviews.Approve(issue, f, reviewMessage); err != nil {
+		return fmt.Errorf("approving review %s: %w", issue.ID, err)
+	}
+	fmt.Printf("%s Approved %s; it can now enter the merge queue\n", style.Bold.Render("✓"), f.MR)
+	return nil
+}
+
+func runReviewRequestChanges(cmd *cobra.Command, args []string) error {
+	if strings.TrimSpace(reviewMessage) == "" {
+		return fmt.Errorf("--message is required: tell the worker what to change")
+	}
+	townRoot, r, reviews, issue, f, err := resolveReview(args[0])
+	if err != nil {
+		return err
+	}
+	if err := reviews.RequestChanges(issue, f, reviewMessage); err != nil {
+		return fmt.Errorf("requesting changes on %s: %w", issue.ID, err)
+	}
+	fmt.Printf("%s Changes requested on %s (round %d)\n", style.Bold.Render("✓"), f.MR, f.Round)
+	notifyChangesRequested(townRoot, r.Name, f)
+	return nil
+}
+
+// notifyChangesRequested mails the worker the reviewer's note, copying the
+// Witness in case the worker's session is gone and the fix needs a new polecat.
+func notifyChangesRequested(townRoot, rigName string, f *review.Fields) {
+	recipients := []string{fmt.Sprintf("%s/witness", rigName)}
+	if f.Worker != "" {
+		recipients = append([]string{identity.New(identity.Worker, rigName, f.Worker).Address()}, recipients...)
+	}
+	router := mail.NewRouter(townRoot)
+	for _, to := range recipients {
+		msg := &mail.Message{
+			To:       to,
+			From:     detectSender(),
+			Subject:  fmt.Sprintf("Changes requested: %s", f.MR),
+			Priority: mail.PriorityHigh,
+			Body: fmt.Sprintf("MR: %s\nBranch: %s\nIssue: %s\nRound: %d\n\n%s\n\n"+
+				"Push fixes to %s and run gt done again; the review goes back to the reviewer.",
+				f.MR, f.Branch, f.SourceIssue, f.Round, f.Note, f.Branch),
+		}
+		if err := router.Send(msg); err != nil {
+			style.PrintWarning("could not notify %s: %v", to, err)
+			continue
+		}
+		fmt.Printf("  Notified %s\n", to)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/steveyegge/gastown/internal/crew"
 	"github.com/steveyegge/gastown/internal/deps"
 	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/keepalive"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/rig"
@@ -60,9 +61,16 @@ The command also:
   - Creates ~/gt/plugins/ (town-level) if it doesn't exist
   - Creates <rig>/plugins/ (rig-level)
 
+Shared object mirror:
+  With --mirror, clones borrow objects from a shared mirror of the origin
+  (<town>/.repo-mirrors/, or $GT_MIRROR_DIR to share across towns), so
+  several rigs of the same large repo store and download history once.
+  Once a mirror exists for a URL, later rigs of that URL use it automatically.
+
 Example:
   gt rig add gastown https://github.com/steveyegge/gastown
-  gt rig add my-project git@github.com:user/repo.git --prefix mp`,
+  gt rig add my-project git@github.com:user/repo.git --prefix mp
+  gt rig add big_repo git@github.com:org/monorepo.git --mirror`,
 	Args: cobra.ExactArgs(2),
 	RunE: runRigAdd,
 }
@@ -256,6 +264,7 @@ var (
 	rigAddPrefix       string
 	rigAddLocalRepo    string
 	rigAddBranch       string
+	rigAddMirror       bool
 	rigResetHandoff    bool
 	rigResetMail       bool
 	rigResetStale      bool
@@ -283,9 +292,10 @@ func init() {
 	rigCmd.AddCommand(rigStatusCmd)
 	rigCmd.AddCommand(rigStopCmd)
 
-	rigAddCmd.Flags().StringVar(&rigAddPrefix, "prefix", "", "Beads issue prefix (default: derived from name)")
+	rigAddCmd.Flags().StringVar(&rigAddPrefix, "prefix", "", "Beads issue prefix (default: derived from name, unique in the town)")
 	rigAddCmd.Flags().StringVar(&rigAddLocalRepo, "local-repo", "", "Local repo path to share git objects (optional)")
 	rigAddCmd.Flags().StringVar(&rigAddBranch, "branch", "", "Default branch name (default: auto-detected from remote)")
+	rigAddCmd.Flags().BoolVar(&rigAddMirror, "mirror", false, "Share git objects via the town's mirror of this origin (created if needed)")
 
 	rigResetCmd.Flags().BoolVar(&rigResetHandoff, "handoff", false, "Clear handoff content")
 	rigResetCmd.Flags().BoolVar(&rigResetMail, "mail", false, "Clear stale mail messages")
@@ -343,6 +353,9 @@ func runRigAdd(cmd *cobra.Command, args []string) error {
 
 	startTime := time.Now()
 
+	// Cloning a large repository takes minutes; keep the keepalive fresh
+	hb := keepalive.StartInWorkspace(townRoot, "gt rig add", "cloning "+name)
+
 	// Add the rig
 	newRig, err := mgr.AddRig(rig.AddRigOptions{
 		Name:          name,
@@ -350,7 +363,9 @@ func runRigAdd(cmd *cobra.Command, args []string) error {
 		BeadsPrefix:   rigAddPrefix,
 		LocalRepo:     rigAddLocalRepo,
 		DefaultBranch: rigAddBranch,
+		SharedMirror:  rigAddMirror,
 	})
+	hb.Stop()
 	if err != nil {
 		return fmt.Errorf("adding rig: %w", err)
 	}
@@ -1138,6 +1153,9 @@ func runRigStatus(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  %s %s: %s\n", sessionIcon, p.Name, stateStr)
 		}
 	}
+	if line := pacingStatusLine(r); line != "" {
+		fmt.Printf("  %s\n", line)
+	}
 	fmt.Println()
 
 	// Crew
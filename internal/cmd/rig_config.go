@@ -9,6 +9,7 @@ import (
 	"strconv"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/auditchain"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/style"
@@ -169,6 +170,7 @@ func runRigConfigSet(cmd *cobra.Command, args []string) error {
 		if err := wispCfg.Block(key); err != nil {
 			return fmt.Errorf("blocking %s: %w", key, err)
 		}
+		recordAudit(townRoot, auditchain.ActionConfigChange, rigName+"."+key, configChangeDetails(wispCfg.ConfigPath(), "", "(blocked)"))
 		fmt.Printf("%s Blocked %s for rig %s\n", style.Success.Render("✓"), key, rigName)
 		return nil
 	}
@@ -180,6 +182,7 @@ func runRigConfigSet(cmd *cobra.Command, args []string) error {
 		if err := setBeadLabel(townRoot, r, key, value); err != nil {
 			return fmt.Errorf("setting bead label: %w", err)
 		}
+		recordAudit(townRoot, auditchain.ActionConfigChange, rigName+"."+key, configChangeDetails("rig bead labels", "", value))
 		fmt.Printf("%s Set %s=%s in bead layer for rig %s\n", style.Success.Render("✓"), key, value, rigName)
 	} else {
 		// Set in wisp layer
@@ -191,9 +194,11 @@ func runRigConfigSet(cmd *cobra.Command, args []string) error {
 		} else if i, err := strconv.Atoi(value); err == nil {
 			typedValue = i
 		}
+		old := wispCfg.Get(key)
 		if err := wispCfg.Set(key, typedValue); err != nil {
 			return fmt.Errorf("setting %s: %w", key, err)
 		}
+		recordAudit(townRoot, auditchain.ActionConfigChange, rigName+"."+key, configChangeDetails(wispCfg.ConfigPath(), formatConfigValue(old), value))
 		fmt.Printf("%s Set %s=%s in wisp layer for rig %s\n", style.Success.Render("✓"), key, value, rigName)
 	}
 
@@ -210,9 +215,11 @@ func runRigConfigUnset(cmd *cobra.Command, args []string) error {
 	}
 
 	wispCfg := wisp.NewConfig(townRoot, r.Name)
+	old := wispCfg.Get(key)
 	if err := wispCfg.Unset(key); err != nil {
 		return fmt.Errorf("unsetting %s: %w", key, err)
 	}
+	recordAudit(townRoot, auditchain.ActionConfigChange, rigName+"."+key, configChangeDetails(wispCfg.ConfigPath(), formatConfigValue(old), ""))
 
 	fmt.Printf("%s Unset %s from wisp layer for rig %s\n", style.Success.Render("✓"), key, rigName)
 	return nil
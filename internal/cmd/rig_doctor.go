@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/doctor"
+)
+
+var (
+	rigDoctorFix       bool
+	rigDoctorVerbose   bool
+	rigDoctorSkipTests bool
+)
+
+var rigDoctorCmd = &cobra.Command{
+	Use:   "doctor <rig>",
+	Short: "Verify a rig is ready for work, end to end",
+	Long: `Check the whole chain a newly added rig depends on, in order:
+
+  - mayor/rig is a working git clone
+  - origin is reachable with the rig's credentials
+  - the configured default branch exists and matches origin's default
+  - a polecat worktree can be created from the default branch
+  - the merge queue's test command passes on the default branch
+  - the beads redirect resolves
+  - the Refinery can push to the default branch (dry run)
+
+Each failure comes with a fix. Unlike gt doctor --rig, this talks to the
+remote and runs the test suite, so it can take a while; use --skip-tests
+to leave the test run out.
+
+Examples:
+  gt rig doctor gastown
+  gt rig doctor gastown --fix          # Apply automatic fixes
+  gt rig doctor gastown --skip-tests`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRigDoctor,
+}
+
+func init() {
+	rigDoctorCmd.Flags().BoolVar(&rigDoctorFix, "fix", false, "Attempt to automatically fix issues")
+	rigDoctorCmd.Flags().BoolVarP(&rigDoctorVerbose, "verbose", "v", false, "Show detailed output")
+	rigDoctorCmd.Flags().BoolVar(&rigDoctorSkipTests, "skip-tests", false, "Don't run the test command on the default branch")
+	rigCmd.AddCommand(rigDoctorCmd)
+}
+
+func runRigDoctor(cmd *cobra.Command, args []string) error {
+	townRoot, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+
+	ctx := &doctor.CheckContext{
+		TownRoot: townRoot,
+		RigName:  r.Name,
+		Verbose:  rigDoctorVerbose,
+	}
+	d := doctor.NewDoctor()
+	d.RegisterAll(doctor.OnboardingChecks(rigDoctorSkipTests)...)
+
+	var report *doctor.Report
+	if rigDoctorFix {
+		report = d.Fix(ctx)
+	} else {
+		report = d.Run(ctx)
+	}
+	report.Print(os.Stdout, rigDoctorVerbose)
+
+	if report.HasErrors() {
+		return fmt.Errorf("rig %s is not ready: %d error(s)", r.Name, report.Summary.Errors)
+	}
+	return nil
+}
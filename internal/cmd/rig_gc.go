@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/branchgc"
+	"github.com/steveyegge/gastown/internal/daemon"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	rigGCDryRun bool
+	rigGCJSON   bool
+)
+
+var rigGCCmd = &cobra.Command{
+	Use:   "gc <rig>",
+	Short: "Delete merged and orphaned branches",
+	Long: `Delete a rig's dead branches, locally and on origin:
+
+  - branches whose MR merged more than branch_gc.merged_after ago (default 7d),
+    if everything on them landed in the MR's target
+  - polecat/* branches with no polecat worktree and no MR, or whose MR
+    closed unmerged that long ago
+
+Branches checked out in a worktree, named by an open MR, the default branch,
+and branches matching branch_gc.keep are never deleted.
+
+With "branch_gc": {"enabled": true} in mayor/daemon.json the daemon does
+this on a schedule, mailing the Refinery what it will delete and waiting
+out branch_gc.grace (default 24h) first. Run by hand, gt rig gc deletes
+right away; use --dry-run to see the plan first.
+
+Examples:
+  gt rig gc gastown --dry-run
+  gt rig gc gastown`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRigGC,
+}
+
+func init() {
+	rigGCCmd.Flags().BoolVarP(&rigGCDryRun, "dry-run", "n", false, "Show what would be deleted without deleting")
+	rigGCCmd.Flags().BoolVar(&rigGCJSON, "json", false, "Output the plan as JSON (implies --dry-run)")
+	rigCmd.AddCommand(rigGCCmd)
+}
+
+func runRigGC(cmd *cobra.Command, args []string) error {
+	townRoot, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+	var cfg *branchgc.Config
+	if patrolConfig := daemon.LoadPatrolConfig(townRoot); patrolConfig != nil {
+		cfg = patrolConfig.BranchGC
+	}
+	policy, err := cfg.Policy()
+	if err != nil {
+		return err
+	}
+
+	inv, err := branchgc.Collect(r.Path)
+	if err != nil {
+		return err
+	}
+	plan, unmerged := branchgc.Plan(inv, policy, time.Now())
+
+	if rigGCJSON {
+		if plan == nil {
+			plan = []branchgc.Candidate{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(plan)
+	}
+	for _, c := range unmerged {
+		style.PrintWarning("keeping %s", c)
+	}
+	if len(plan) == 0 {
+		fmt.Printf("%s No branches to delete in %s\n", style.Dim.Render("○"), r.Name)
+		return nil
+	}
+
+	if rigGCDryRun {
+		fmt.Printf("%s\n\n", style.Bold.Render(fmt.Sprintf("Would delete %d branch(es) in %s:", len(plan), r.Name)))
+		for _, c := range plan {
+			fmt.Printf("  %s\n", c)
+		}
+		return nil
+	}
+
+	deleted := 0
+	for _, c := range plan {
+		if err := branchgc.Delete(r.Path, c); err != nil {
+			style.PrintWarning("could not delete %s: %v", c.Branch, err)
+			continue
+		}
+		fmt.Printf("  %s %s\n", style.Dim.Render("deleted"), c)
+		deleted++
+	}
+	fmt.Printf("\n%s Deleted %d of %d branch(es) in %s\n", style.SuccessPrefix, deleted, len(plan), r.Name)
+	return nil
+}
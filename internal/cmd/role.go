@@ -172,6 +172,8 @@ func GetRoleWithContext(cwd, townRoot string) (RoleInfo, error) {
 		if info.Polecat == "" {
 			if envCrew := os.Getenv("GT_CREW"); envCrew != "" {
 				info.Polecat = envCrew
+			} else if envObserver := os.Getenv("GT_OBSERVER"); envObserver != "" {
+				info.Polecat = envObserver
 			} else if envPolecat := os.Getenv("GT_POLECAT"); envPolecat != "" {
 				info.Polecat = envPolecat
 			}
@@ -180,7 +182,7 @@ func GetRoleWithContext(cwd, townRoot string) (RoleInfo, error) {
 		// If env is incomplete (missing rig/polecat for roles that need them),
 		// fill gaps from cwd detection and mark as incomplete
 		needsRig := parsedRole == RoleWitness || parsedRole == RoleRefinery || parsedRole == RolePolecat || parsedRole == RoleCrew
-		needsPolecat := parsedRole == RolePolecat || parsedRole == RoleCrew
+		needsPolecat := parsedRole == RolePolecat || parsedRole == RoleCrew || parsedRole == RoleObserver
 
 		if needsRig && info.Rig == "" && cwdCtx.Rig != "" {
 			info.Rig = cwdCtx.Rig
@@ -219,6 +221,8 @@ func parseRoleString(s string) (Role, string, string) {
 		return RoleMayor, "", ""
 	case "deacon":
 		return RoleDeacon, "", ""
+	case "observer":
+		return RoleObserver, "", ""
 	}
 
 	// Compound roles: rig/role or rig/polecats/name or rig/crew/name
@@ -228,6 +232,11 @@ func parseRoleString(s string) (Role, string, string) {
 		return Role(s), "", ""
 	}
 
+	// Observers are town-level: observers/name
+	if parts[0] == "observers" {
+		return RoleObserver, "", parts[1]
+	}
+
 	rig := parts[0]
 
 	switch parts[1] {
@@ -256,6 +265,7 @@ func parseRoleString(s string) (Role, string, string) {
 //   - Simple roles: "mayor", "deacon"
 //   - Rig-specific: "gastown/witness", "gastown/refinery"
 //   - Workers: "gastown/crew/max", "gastown/polecats/Toast"
+//   - Observers: "observers/alice"
 func (info RoleInfo) ActorString() string {
 	switch info.Role {
 	case RoleMayor:
@@ -282,6 +292,11 @@ func (info RoleInfo) ActorString() string {
 			return fmt.Sprintf("%s/crew/%s", info.Rig, info.Polecat)
 		}
 		return "crew"
+	case RoleObserver:
+		if info.Polecat != "" {
+			return fmt.Sprintf("observers/%s", info.Polecat)
+		}
+		return "observer"
 	default:
 		return string(info.Role)
 	}
@@ -314,6 +329,11 @@ func getRoleHome(role Role, rig, polecat, townRoot string) string {
 			return ""
 		}
 		return filepath.Join(townRoot, rig, "crew", polecat, "rig")
+	case RoleObserver:
+		if polecat == "" {
+			return ""
+		}
+		return filepath.Join(townRoot, "observers", polecat)
 	default:
 		return ""
 	}
@@ -460,6 +480,7 @@ func runRoleList(cmd *cobra.Command, args []string) error {
 		{RoleRefinery, "Per-rig merge queue processor"},
 		{RolePolecat, "Ephemeral worker with own worktree"},
 		{RoleCrew, "Persistent worker with own worktree"},
+		{RoleObserver, "Read-only stakeholder at observers/<name>/"},
 	}
 
 	fmt.Println("Available roles:")
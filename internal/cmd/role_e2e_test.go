@@ -172,6 +172,7 @@ func TestRoleHomeCwdDetection(t *testing.T) {
 		filepath.Join(hqPath, rigName, "refinery", "rig"),
 		filepath.Join(hqPath, rigName, "polecats", "Toast", "rig"),
 		filepath.Join(hqPath, rigName, "crew", "worker1", "rig"),
+		filepath.Join(hqPath, "observers", "alice"),
 	}
 	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, 0755); err != nil {
@@ -214,6 +215,11 @@ func TestRoleHomeCwdDetection(t *testing.T) {
 			cwd:      filepath.Join(hqPath, rigName, "crew", "worker1", "rig"),
 			expected: filepath.Join(hqPath, rigName, "crew", "worker1", "rig"),
 		},
+		{
+			name:     "observer from observers/alice dir",
+			cwd:      filepath.Join(hqPath, "observers", "alice"),
+			expected: filepath.Join(hqPath, "observers", "alice"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -377,7 +383,7 @@ func TestRoleListE2E(t *testing.T) {
 	}
 
 	// Check all roles are listed
-	roles := []string{"mayor", "deacon", "witness", "refinery", "polecat", "crew"}
+	roles := []string{"mayor", "deacon", "witness", "refinery", "polecat", "crew", "observer"}
 	for _, role := range roles {
 		if !strings.Contains(got, role) {
 			t.Errorf("output missing role %q\ngot: %s", role, got)
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/keepalive"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/version"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -30,6 +31,7 @@ var beadsExemptCommands = map[string]bool{
 	"version":    true,
 	"help":       true,
 	"completion": true,
+	"ratelimit":  true, // Runs from a hook on every agent Bash call
 }
 
 // Commands exempt from the town root branch warning.
@@ -41,20 +43,40 @@ var branchCheckExemptCommands = map[string]bool{
 	"doctor":     true, // Used to fix the problem
 	"install":    true, // Initial setup
 	"git-init":   true, // Git setup
+	"ratelimit":  true, // Runs from a hook on every agent Bash call
+}
+
+// Commands that don't count as a worker's activity: they neither touch the
+// keepalive nor renew the dispatch claim. Hooks run them on every tool call,
+// where a lock renewal per call would cost more than the command itself.
+var activityExemptCommands = map[string]bool{
+	"ratelimit": true,
 }
 
 // persistentPreRun runs before every command.
 func persistentPreRun(cmd *cobra.Command, args []string) error {
-	// Get the root command name being run
-	cmdName := cmd.Name()
+	// Get the command name being run, and the top-level command it is under
+	// ("ratelimit" for "gt ratelimit check")
+	cmdName, topName := cmd.Name(), topCommandName(cmd)
 
 	// Check town root branch (warning only, non-blocking)
-	if !branchCheckExemptCommands[cmdName] {
+	if !branchCheckExemptCommands[cmdName] && !branchCheckExemptCommands[topName] {
 		warnIfTownRootOffMain()
 	}
 
+	// Role-aware command gating (may block commands inappropriate for the role)
+	if err := checkCommandGate(cmd); err != nil {
+		return err
+	}
+
+	// Signal activity and renew this worker's identity lease (best-effort)
+	if !activityExemptCommands[topName] {
+		keepalive.TouchWithArgs(cmd.CommandPath(), args)
+		renewDispatchClaim()
+	}
+
 	// Skip beads check for exempt commands
-	if beadsExemptCommands[cmdName] {
+	if beadsExemptCommands[cmdName] || beadsExemptCommands[topName] {
 		return nil
 	}
 
@@ -62,6 +84,15 @@ func persistentPreRun(cmd *cobra.Command, args []string) error {
 	return CheckBeadsVersion()
 }
 
+// topCommandName returns the name of the top-level command cmd is under
+// (cmd itself if it is top-level).
+func topCommandName(cmd *cobra.Command) string {
+	for cmd.HasParent() && cmd.Parent().HasParent() {
+		cmd = cmd.Parent()
+	}
+	return cmd.Name()
+}
+
 // warnIfTownRootOffMain prints a warning if the town root is not on main branch.
 // This is a non-blocking warning to help catch accidental branch switches.
 func warnIfTownRootOffMain() {
@@ -0,0 +1,37 @@
+package cmd
+
+import "testing"
+
+func TestTopCommandName(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"ratelimit", "check"}, "ratelimit"},
+		{[]string{"mq", "stack", "show"}, "mq"},
+		{[]string{"version"}, "version"},
+		{nil, "gt"},
+	}
+	for _, tt := range tests {
+		cmd, _, err := rootCmd.Find(tt.args)
+		if err != nil {
+			t.Fatalf("Find(%v): %v", tt.args, err)
+		}
+		if got := topCommandName(cmd); got != tt.want {
+			t.Errorf("topCommandName(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+// The rate limit hook runs on every agent Bash call; it must skip the
+// pre-run checks that spawn processes or renew leases.
+func TestRateLimitHookSkipsPreRun(t *testing.T) {
+	cmd, _, err := rootCmd.Find([]string{"ratelimit", "check"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	top := topCommandName(cmd)
+	if !beadsExemptCommands[top] || !branchCheckExemptCommands[top] || !activityExemptCommands[top] {
+		t.Errorf("%q is not exempt from the beads, branch, and activity pre-run checks", cmd.CommandPath())
+	}
+}
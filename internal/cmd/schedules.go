@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/daemon"
+	"github.com/steveyegge/gastown/internal/schedule"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	schedulesJSON  bool
+	schedulesCount int
+)
+
+var schedulesCmd = &cobra.Command{
+	Use:     "schedules",
+	GroupID: GroupServices,
+	Short:   "Show scheduled callbacks and when they next fire",
+	Long: `Show the daemon's scheduled callbacks: timer triggers that mail the Mayor
+a callback on a schedule, so recurring rituals (standup summaries, backlog
+triage) run through gt callbacks process like any other callback.
+
+Triggers are configured under "schedules" in mayor/daemon.json:
+
+  {
+    "schedules": {
+      "timezone": "America/New_York",
+      "triggers": [
+        {"name": "triage", "schedule": "weekday 09:00",
+         "subject": "TRIAGE_REMINDER",
+         "body": "Triage new beads: set priorities and sling what is ready."},
+        {"name": "standup", "schedule": "0 17 * * 1-5",
+         "subject": "STANDUP_SUMMARY", "route": "overseer", "priority": "low",
+         "body": "Summarize today's merges and open escalations."}
+      ]
+    }
+  }
+
+Schedules are five-field cron expressions, @-shorthands (@hourly, @daily,
+@weekly), or "<days> HH:MM" with days one of daily, weekday, weekend, or
+day names ("mon,thu 14:30", "mon-fri 17:00").
+
+When a trigger comes due, the daemon mails its subject and body to the
+Mayor from "scheduler". gt callbacks process forwards it to the trigger's
+route (default: the Mayor's own inbox, as an instruction for the Mayor).
+A run missed by more than an hour, e.g. while the daemon was down, is
+skipped rather than caught up.`,
+	RunE: runSchedules,
+}
+
+var schedulesFireCmd = &cobra.Command{
+	Use:   "fire <trigger>",
+	Short: "Deliver a trigger's callback now",
+	Long: `Mail a trigger's callback to the Mayor now, regardless of its schedule.
+The trigger's next scheduled run is unaffected.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSchedulesFire,
+}
+
+var schedulesNextCmd = &cobra.Command{
+	Use:   "next <schedule>",
+	Short: "Show when a schedule expression fires",
+	Long: `Show the next times a schedule expression fires, to check it before
+adding it to a trigger.
+
+Examples:
+  gt schedules next "weekday 09:00"
+  gt schedules next "*/15 9-17 * * mon-fri" -n 10`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSchedulesNext,
+}
+
+func init() {
+	schedulesCmd.Flags().BoolVar(&schedulesJSON, "json", false, "Output as JSON")
+	schedulesNextCmd.Flags().IntVarP(&schedulesCount, "count", "n", 5, "Number of times to show")
+	schedulesCmd.AddCommand(schedulesFireCmd)
+	schedulesCmd.AddCommand(schedulesNextCmd)
+	rootCmd.AddCommand(schedulesCmd)
+}
+
+// loadScheduler builds a scheduler from mayor/daemon.json, for inspection
+// and manual firing; the daemon runs its own.
+func loadScheduler(townRoot string) (*schedule.Scheduler, error) {
+	var cfg schedule.Config
+	if pc := daemon.LoadPatrolConfig(townRoot); pc != nil && pc.Schedules != nil {
+		cfg = *pc.Schedules
+	}
+	s, err := schedule.New(townRoot, cfg, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedules config: %w", err)
+	}
+	return s, nil
+}
+
+func runSchedules(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	s, err := loadScheduler(townRoot)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	statuses := s.Status(now)
+
+	if schedulesJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statuses)
+	}
+
+	if len(statuses) == 0 {
+		fmt.Printf("%s No schedule triggers configured (see gt schedules --help)\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	fmt.Printf("\n%s Scheduled Callbacks\n\n", style.Bold.Render("⏰"))
+	for _, st := range statuses {
+		fmt.Printf("  %s  %s → %s\n", style.Bold.Render(st.Name), style.Dim.Render(st.Schedule), st.Subject)
+		next := "never"
+		if !st.Next.IsZero() {
+			next = fmt.Sprintf("%s (in %s)", st.Next.Format("Mon Jan 2 15:04"), formatDuration(st.Next.Sub(now)))
+		}
+		route := st.Route
+		if route == "" {
+			route = schedule.DefaultRoute
+		}
+		fmt.Printf("     next %s, routed to %s\n", next, route)
+		if state := st.State; state != nil {
+			if !state.FiredAt.IsZero() {
+				fmt.Printf("     last fired %s ago (%d run(s)", formatDuration(now.Sub(state.FiredAt)), state.Fired)
+				if state.Missed > 0 {
+					fmt.Printf(", %d missed", state.Missed)
+				}
+				fmt.Println(")")
+			}
+			if state.Error != "" {
+				fmt.Printf("     %s\n", style.Error.Render(state.Error))
+			}
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+func runSchedulesFire(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	s, err := loadScheduler(townRoot)
+	if err != nil {
+		return err
+	}
+	if err := s.Fire(args[0], time.Now()); err != nil {
+		return err
+	}
+	fmt.Printf("%s Sent %s callback to mayor/\n", style.Bold.Render("✓"), args[0])
+	return nil
+}
+
+func runSchedulesNext(cmd *cobra.Command, args []string) error {
+	spec, err := schedule.Parse(args[0])
+	if err != nil {
+		return err
+	}
+	t := time.Now()
+	for i := 0; i < schedulesCount; i++ {
+		t = spec.Next(t)
+		if t.IsZero() {
+			if i == 0 {
+				fmt.Printf("%s %q never fires\n", style.Dim.Render("○"), args[0])
+			}
+			return nil
+		}
+		fmt.Printf("  %s\n", t.Format("Mon 2006-01-02 15:04 MST"))
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -12,7 +13,11 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/identity"
+	"github.com/steveyegge/gastown/internal/logrotate"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -24,10 +29,11 @@ var (
 	seanceTalk   string
 	seancePrompt string
 	seanceJSON   bool
+	seanceAgent  string
 )
 
 var seanceCmd = &cobra.Command{
-	Use:     "seance",
+	Use:     "seance [<agent>]",
 	GroupID: GroupDiag,
 	Short:   "Talk to your predecessor sessions",
 	Long: `Seance lets you literally talk to predecessor sessions.
@@ -50,12 +56,24 @@ THE SEANCE (talk to predecessor):
   gt seance --talk <session-id>              # Interactive conversation
   gt seance --talk <id> -p "Where is X?"     # One-shot question
 
-The --talk flag spawns: claude --fork-session --resume <id>
-This loads the predecessor's full context without modifying their session.
+ASK A DEAD AGENT (its last recorded session):
+  gt seance gastown/polecats/Toast           # Interactive conversation
+  gt seance gastown/Toast -p "Where were you in the migration?"
+
+The seance spawns e.g.: claude --fork-session --resume <id> --permission-mode plan
+This loads the predecessor's full context without modifying their session,
+in the agent's read-only mode. Agents that can't fork a session resume it
+instead; the agent runtime is the one the role is configured with (override
+with --agent).
+
+Questions asked of an agent with -p are recorded with their answers in a
+seance bead in the town beads, linked to the agent's latest handoff mail,
+so the agent's successor can read what was learned.
 
 Sessions are discovered from:
   1. Events emitted by SessionStart hooks (~/gt/.events.jsonl)
   2. The [GAS TOWN] beacon makes sessions searchable in /resume`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runSeance,
 }
 
@@ -66,6 +84,7 @@ func init() {
 	seanceCmd.Flags().StringVarP(&seanceTalk, "talk", "t", "", "Session ID to commune with")
 	seanceCmd.Flags().StringVarP(&seancePrompt, "prompt", "p", "", "One-shot prompt (with --talk)")
 	seanceCmd.Flags().BoolVar(&seanceJSON, "json", false, "Output as JSON")
+	seanceCmd.Flags().StringVar(&seanceAgent, "agent", "", "Agent runtime of the session (default: the role's configured agent)")
 
 	rootCmd.AddCommand(seanceCmd)
 }
@@ -79,9 +98,19 @@ type sessionEvent struct {
 }
 
 func runSeance(cmd *cobra.Command, args []string) error {
+	// An agent named: commune with its last recorded session
+	if len(args) == 1 {
+		return runSeanceAgent(args[0], seancePrompt)
+	}
+
 	// If --talk is provided, spawn a seance
 	if seanceTalk != "" {
-		return runSeanceTalk(seanceTalk, seancePrompt)
+		agent := seanceAgent
+		if agent == "" {
+			agent = string(config.DefaultAgentPreset())
+		}
+		_, err := runSeanceTalk(agent, seanceTalk, "", seancePrompt)
+		return err
 	}
 
 	// Otherwise, list discoverable sessions
@@ -187,34 +216,41 @@ func runSeanceList() error {
 	return nil
 }
 
-func runSeanceTalk(sessionID, prompt string) error {
-	// Expand short IDs if needed (user might provide partial)
-	// For now, require full ID or let claude --resume handle it
+// runSeanceTalk spawns agent on a fork (or resume) of sessionID in
+// read-only mode, in dir if set. With a prompt it returns the answer.
+func runSeanceTalk(agent, sessionID, dir, prompt string) (string, error) {
+	argv := config.BuildSeanceCommand(agent, sessionID, prompt)
+	if argv == nil {
+		return "", fmt.Errorf("agent %q cannot resume sessions", agent)
+	}
+	if info := config.GetAgentPresetByName(agent); info != nil {
+		if !info.SupportsForkSession {
+			style.PrintWarning("%s can't fork sessions; resuming %s itself", agent, sessionID)
+		}
+		if len(info.ReadOnlyArgs) == 0 {
+			style.PrintWarning("%s has no read-only mode; the session can still change files", agent)
+		}
+	}
 
 	fmt.Printf("%s Summoning session %s...\n\n", style.Bold.Render("🔮"), sessionID)
 
-	// Build the command
-	args := []string{"--fork-session", "--resume", sessionID}
+	cmd := exec.Command(argv[0], argv[1:]...) //nolint:gosec // G204: argv from agent preset
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
 
 	if prompt != "" {
-		// One-shot mode with --print
-		args = append(args, "--print", prompt)
-
-		cmd := exec.Command("claude", args...)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-
+		// One-shot mode: show the answer and keep it for the transcript
+		var answer strings.Builder
+		cmd.Stdout = io.MultiWriter(os.Stdout, &answer)
 		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("seance failed: %w", err)
+			return "", fmt.Errorf("seance failed: %w", err)
 		}
-		return nil
+		return answer.String(), nil
 	}
 
-	// Interactive mode - just launch claude
-	cmd := exec.Command("claude", args...)
+	// Interactive mode - hand over the terminal
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
 
 	fmt.Printf("%s\n", style.Dim.Render("You are now talking to your predecessor. Ask them anything."))
 	fmt.Printf("%s\n\n", style.Dim.Render("Exit with /exit or Ctrl+C"))
@@ -223,20 +259,146 @@ func runSeanceTalk(sessionID, prompt string) error {
 		// Exit errors are normal when user exits
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			if exitErr.ExitCode() == 0 || exitErr.ExitCode() == 130 {
-				return nil // Normal exit or Ctrl+C
+				return "", nil // Normal exit or Ctrl+C
 			}
 		}
-		return fmt.Errorf("seance ended: %w", err)
+		return "", fmt.Errorf("seance ended: %w", err)
 	}
 
+	return "", nil
+}
+
+// runSeanceAgent communes with the last recorded session of an agent.
+func runSeanceAgent(target, prompt string) error {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return fmt.Errorf("not in a Gas Town workspace")
+	}
+	sessions, err := discoverSessions(townRoot)
+	if err != nil {
+		return fmt.Errorf("discovering sessions: %w", err)
+	}
+	last := lastResumableSession(sessions, target)
+	if last == nil {
+		return fmt.Errorf("no recorded session for %s (see gt seance --role/--rig)", target)
+	}
+	sessionID := getPayloadString(last.Payload, "session_id")
+
+	agent := seanceAgent
+	if agent == "" {
+		agent = sessionAgent(townRoot, last.Actor)
+	}
+	// Resume from the session's working directory, where the agent keeps it
+	dir := getPayloadString(last.Payload, "cwd")
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir = ""
+	}
+
+	fmt.Printf("%s %s, last seen %s\n", style.Dim.Render("○"), last.Actor, formatEventTime(last.Timestamp))
+	answer, err := runSeanceTalk(agent, sessionID, dir, prompt)
+	if err != nil || prompt == "" {
+		return err
+	}
+
+	id, err := recordSeance(townRoot, last.Actor, sessionID, prompt, answer)
+	if err != nil {
+		style.PrintWarning("could not record seance transcript: %v", err)
+		return nil
+	}
+	fmt.Printf("\n%s Transcript recorded in %s\n", style.Bold.Render("✓"), id)
+	return nil
+}
+
+// lastResumableSession returns the most recent session of target that
+// has a real session ID (not the actor-pid placeholder gt prime falls back
+// to). sessions must be sorted newest first.
+func lastResumableSession(sessions []sessionEvent, target string) *sessionEvent {
+	for i, s := range sessions {
+		if !identity.Same(s.Actor, target) {
+			continue
+		}
+		id := getPayloadString(s.Payload, "session_id")
+		if id == "" || id == getPayloadString(s.Payload, "actor_pid") {
+			continue
+		}
+		return &sessions[i]
+	}
 	return nil
 }
 
+// sessionAgent returns the agent preset the actor's role is configured to
+// run, falling back to the default agent.
+func sessionAgent(townRoot, actor string) string {
+	id, err := identity.Parse(actor)
+	if err != nil {
+		return string(config.DefaultAgentPreset())
+	}
+	rigPath := ""
+	if id.Rig != "" {
+		rigPath = filepath.Join(townRoot, id.Rig)
+	}
+	rc := config.ResolveRoleAgentConfig(string(id.Role), townRoot, rigPath)
+	if info := config.GetAgentPresetByCommand(rc.Command); info != nil {
+		return string(info.Name)
+	}
+	return string(config.DefaultAgentPreset())
+}
+
+// recordSeance files a closed seance bead holding the question and answer
+// in the town beads, next to the agent's handoff mail.
+func recordSeance(townRoot, actor, sessionID, prompt, answer string) (string, error) {
+	bd := beads.New(beads.ResolveBeadsDir(townRoot))
+
+	var desc strings.Builder
+	fmt.Fprintf(&desc, "agent: %s\nsession: %s\n", actor, sessionID)
+	if handoff := latestHandoff(bd, actor); handoff != "" {
+		fmt.Fprintf(&desc, "handoff: %s\n", handoff)
+	}
+	fmt.Fprintf(&desc, "asked_by: %s\n\n## Q\n\n%s\n\n## A\n\n%s\n", detectSender(), prompt, strings.TrimSpace(answer))
+
+	issue, err := bd.Create(beads.CreateOptions{
+		Title:       fmt.Sprintf("Seance: %s", actor),
+		Type:        "seance",
+		Priority:    3,
+		Description: desc.String(),
+	})
+	if err != nil {
+		return "", err
+	}
+	_ = bd.CloseWithReason("seance transcript", issue.ID)
+	return issue.ID, nil
+}
+
+// latestHandoff returns the ID of the most recent handoff mail the agent
+// left itself, or "" if there is none.
+func latestHandoff(bd *beads.Beads, actor string) string {
+	assignees := []string{actor}
+	if id, err := identity.Parse(actor); err == nil && id.Address() != actor {
+		assignees = append(assignees, id.Address())
+	}
+	var latest *beads.Issue
+	for _, assignee := range assignees {
+		issues, err := bd.List(beads.ListOptions{Status: "all", Assignee: assignee, Priority: -1})
+		if err != nil {
+			continue
+		}
+		for _, issue := range issues {
+			if strings.Contains(issue.Title, "HANDOFF") && (latest == nil || issue.CreatedAt > latest.CreatedAt) {
+				latest = issue
+			}
+		}
+	}
+	if latest == nil {
+		return ""
+	}
+	return latest.ID
+}
+
 // discoverSessions reads session_start events from our event stream.
 func discoverSessions(townRoot string) ([]sessionEvent, error) {
 	eventsPath := filepath.Join(townRoot, events.EventsFile)
 
-	file, err := os.Open(eventsPath)
+	file, err := logrotate.Open(eventsPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil
@@ -253,13 +415,18 @@ func discoverSessions(townRoot string) ([]sessionEvent, error) {
 	scanner.Buffer(buf, 1024*1024)
 
 	for scanner.Scan() {
-		var event sessionEvent
-		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+		event, err := events.Parse(scanner.Bytes())
+		if err != nil {
 			continue
 		}
 
 		if event.Type == events.TypeSessionStart {
-			sessions = append(sessions, event)
+			sessions = append(sessions, sessionEvent{
+				Timestamp: event.Timestamp,
+				Type:      event.Type,
+				Actor:     event.Actor,
+				Payload:   event.Payload,
+			})
 		}
 	}
 
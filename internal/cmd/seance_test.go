@@ -0,0 +1,21 @@
+package cmd
+
+import "testing"
+
+func TestLastResumableSession(t *testing.T) {
+	sessions := []sessionEvent{ // newest first
+		{Actor: "gastown/polecats/Toast", Payload: map[string]interface{}{
+			"session_id": "gastown/polecats/Toast-42", "actor_pid": "gastown/polecats/Toast-42"}},
+		{Actor: "gastown/polecats/Nux", Payload: map[string]interface{}{"session_id": "nux-1"}},
+		{Actor: "gastown/polecats/Toast", Payload: map[string]interface{}{"session_id": "toast-2"}},
+		{Actor: "gastown/polecats/Toast", Payload: map[string]interface{}{"session_id": "toast-1"}},
+	}
+
+	got := lastResumableSession(sessions, "gastown/Toast")
+	if got == nil || getPayloadString(got.Payload, "session_id") != "toast-2" {
+		t.Errorf("lastResumableSession(gastown/Toast) = %+v, want session toast-2", got)
+	}
+	if got := lastResumableSession(sessions, "gastown/polecats/Slit"); got != nil {
+		t.Errorf("lastResumableSession(unknown) = %+v, want nil", got)
+	}
+}
@@ -44,12 +44,30 @@ Target Resolution:
   gt sling gt-abc mayor                 # Mayor
   gt sling gt-abc deacon/dogs           # Auto-dispatch to idle dog
   gt sling gt-abc deacon/dogs/alpha     # Specific dog
+  gt sling gt-abc me@box:gt/gastown     # Rig in another town (over SSH)
 
 Spawning Options (when target is a rig):
   gt sling gp-abc greenplace --create               # Create polecat if missing
   gt sling gp-abc greenplace --force                # Ignore unread mail
   gt sling gp-abc greenplace --account work         # Use specific Claude account
 
+Pacing:
+  A rig's "pacing" settings limit when polecats are spawned (working_hours)
+  and how many beads per hour are slung to the rig or its polecats
+  (max_slings_per_hour). Slings outside them fail; --ignore-schedule
+  overrides both for one sling.
+
+Remote Towns:
+  A target of the form [user@]host:town/agent slings to an agent in the town
+  at ~/town on another machine, so a central coordinator can farm work out
+  to satellite towns. Over SSH, the remote town creates the work as a wisp,
+  slings it to the agent (any local target form), and mails the agent where
+  it came from. The local bead is hooked with the remote target as assignee.
+  Needs key-based SSH access and gt in the remote PATH (or GT_REMOTE_BIN).
+
+  gt sling gt-abc me@box:gt/gastown         # Auto-spawn polecat there
+  gt sling gt-abc box:gt/gastown/crew/max   # Specific crew member there
+
 Natural Language Args:
   gt sling gt-abc --args "patch release"
   gt sling code-review --args "focus on security"
@@ -95,6 +113,8 @@ var (
 	slingAccount  string // --account: Claude Code account handle to use
 	slingAgent    string // --agent: override runtime agent for this sling/spawn
 	slingNoConvoy bool   // --no-convoy: skip auto-convoy creation
+
+	slingIgnoreSchedule bool // --ignore-schedule: bypass the rig's working hours and sling rate
 )
 
 func init() {
@@ -111,11 +131,12 @@ func init() {
 	slingCmd.Flags().StringVar(&slingAccount, "account", "", "Claude Code account handle to use")
 	slingCmd.Flags().StringVar(&slingAgent, "agent", "", "Override agent/runtime for this sling (e.g., claude, gemini, codex, or custom alias)")
 	slingCmd.Flags().BoolVar(&slingNoConvoy, "no-convoy", false, "Skip auto-convoy creation for single-issue sling")
+	slingCmd.Flags().BoolVar(&slingIgnoreSchedule, "ignore-schedule", false, "Sling even outside the rig's working hours or over its sling rate")
 
 	rootCmd.AddCommand(slingCmd)
 }
 
-func runSling(cmd *cobra.Command, args []string) error {
+func runSling(cmd *cobra.Command, args []string) (err error) {
 	// Polecats cannot sling - check early before writing anything
 	if polecatName := os.Getenv("GT_POLECAT"); polecatName != "" {
 		return fmt.Errorf("polecats cannot sling (use gt done for handoff)")
@@ -129,6 +150,15 @@ func runSling(cmd *cobra.Command, args []string) error {
 	}
 	townBeadsDir := filepath.Join(townRoot, ".beads")
 
+	// Remote target: gt sling gt-abc user@host:town/rig
+	if len(args) == 2 && isRemoteTarget(args[1]) {
+		rt, err := parseRemoteTarget(args[1])
+		if err != nil {
+			return err
+		}
+		return runRemoteSling(townRoot, args[0], rt)
+	}
+
 	// --var is only for standalone formula mode, not formula-on-bead mode
 	if slingOnTarget != "" && len(slingVars) > 0 {
 		return fmt.Errorf("--var cannot be used with --on (formula-on-bead mode doesn't support variables)")
@@ -185,6 +215,19 @@ func runSling(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Count the sling against the target rig's pacing before spawning
+	if len(args) > 1 && !slingDryRun {
+		undoPace, paceErr := takeSlingPace(townRoot, args[1], beadID)
+		if paceErr != nil {
+			return paceErr
+		}
+		defer func() {
+			if err != nil {
+				undoPace()
+			}
+		}()
+	}
+
 	// Determine target agent (self or specified)
 	var targetAgent string
 	var targetPane string
@@ -232,11 +275,12 @@ func runSling(cmd *cobra.Command, args []string) error {
 				// Spawn a fresh polecat in the rig
 				fmt.Printf("Target is rig '%s', spawning fresh polecat...\n", rigName)
 				spawnOpts := SlingSpawnOptions{
-					Force:    slingForce,
-					Account:  slingAccount,
-					Create:   slingCreate,
-					HookBead: beadID, // Set atomically at spawn time
-					Agent:    slingAgent,
+					Force:          slingForce,
+					Account:        slingAccount,
+					Create:         slingCreate,
+					HookBead:       beadID, // Set atomically at spawn time
+					Agent:          slingAgent,
+					IgnoreSchedule: slingIgnoreSchedule,
 				}
 				spawnInfo, spawnErr := SpawnPolecatForSling(rigName, spawnOpts)
 				if spawnErr != nil {
@@ -263,11 +307,12 @@ func runSling(cmd *cobra.Command, args []string) error {
 						rigName := parts[0]
 						fmt.Printf("Target polecat has no active session, spawning fresh polecat in rig '%s'...\n", rigName)
 						spawnOpts := SlingSpawnOptions{
-							Force:    slingForce,
-							Account:  slingAccount,
-							Create:   slingCreate,
-							HookBead: beadID,
-							Agent:    slingAgent,
+							Force:          slingForce,
+							Account:        slingAccount,
+							Create:         slingCreate,
+							HookBead:       beadID,
+							Agent:          slingAgent,
+							IgnoreSchedule: slingIgnoreSchedule,
 						}
 						spawnInfo, spawnErr := SpawnPolecatForSling(rigName, spawnOpts)
 						if spawnErr != nil {
@@ -58,16 +58,27 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 			continue
 		}
 
+		// Count the sling against the rig's pacing
+		townRoot := filepath.Dir(townBeadsDir)
+		undoPace, err := takeSlingPace(townRoot, rigName, beadID)
+		if err != nil {
+			results = append(results, slingResult{beadID: beadID, success: false, errMsg: err.Error()})
+			fmt.Printf("  %s %v\n", style.Dim.Render("✗"), err)
+			continue
+		}
+
 		// Spawn a fresh polecat
 		spawnOpts := SlingSpawnOptions{
-			Force:    slingForce,
-			Account:  slingAccount,
-			Create:   slingCreate,
-			HookBead: beadID, // Set atomically at spawn time
-			Agent:    slingAgent,
+			Force:          slingForce,
+			Account:        slingAccount,
+			Create:         slingCreate,
+			HookBead:       beadID, // Set atomically at spawn time
+			Agent:          slingAgent,
+			IgnoreSchedule: slingIgnoreSchedule,
 		}
 		spawnInfo, err := SpawnPolecatForSling(rigName, spawnOpts)
 		if err != nil {
+			undoPace()
 			results = append(results, slingResult{beadID: beadID, success: false, errMsg: err.Error()})
 			fmt.Printf("  %s Failed to spawn polecat: %v\n", style.Dim.Render("✗"), err)
 			continue
@@ -92,11 +103,11 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 		}
 
 		// Hook the bead. See: https://github.com/steveyegge/gastown/issues/148
-		townRoot := filepath.Dir(townBeadsDir)
 		hookCmd := exec.Command("bd", "--no-daemon", "update", beadID, "--status=hooked", "--assignee="+targetAgent)
 		hookCmd.Dir = beads.ResolveHookDir(townRoot, beadID, hookWorkDir)
 		hookCmd.Stderr = os.Stderr
 		if err := hookCmd.Run(); err != nil {
+			undoPace()
 			results = append(results, slingResult{beadID: beadID, polecat: spawnInfo.PolecatName, success: false, errMsg: "hook failed"})
 			fmt.Printf("  %s Failed to hook bead: %v\n", style.Dim.Render("✗"), err)
 			continue
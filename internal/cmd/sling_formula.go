@@ -71,7 +71,7 @@ func verifyFormulaExists(formulaName string) error {
 
 // runSlingFormula handles standalone formula slinging.
 // Flow: cook → wisp → attach to hook → nudge
-func runSlingFormula(args []string) error {
+func runSlingFormula(args []string) (err error) {
 	formulaName := args[0]
 
 	// Get town root early - needed for BEADS_DIR when running bd commands
@@ -87,6 +87,19 @@ func runSlingFormula(args []string) error {
 		target = args[1]
 	}
 
+	// Count the sling against the target rig's pacing before spawning
+	if target != "" && !slingDryRun {
+		undoPace, paceErr := takeSlingPace(townRoot, target, formulaName)
+		if paceErr != nil {
+			return paceErr
+		}
+		defer func() {
+			if err != nil {
+				undoPace()
+			}
+		}()
+	}
+
 	// Resolve target agent and pane
 	var targetAgent string
 	var targetPane string
@@ -131,10 +144,11 @@ func runSlingFormula(args []string) error {
 				// Spawn a fresh polecat in the rig
 				fmt.Printf("Target is rig '%s', spawning fresh polecat...\n", rigName)
 				spawnOpts := SlingSpawnOptions{
-					Force:   slingForce,
-					Account: slingAccount,
-					Create:  slingCreate,
-					Agent:   slingAgent,
+					Force:          slingForce,
+					Account:        slingAccount,
+					Create:         slingCreate,
+					Agent:          slingAgent,
+					IgnoreSchedule: slingIgnoreSchedule,
 				}
 				spawnInfo, spawnErr := SpawnPolecatForSling(rigName, spawnOpts)
 				if spawnErr != nil {
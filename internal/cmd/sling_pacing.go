@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/dispatch"
+	"github.com/steveyegge/gastown/internal/pacing"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// pacedRigOf returns the rig whose pacing applies to slinging to target: a
+// rig (which spawns a polecat) or one of its polecats. Crew, dogs, and town
+// agents are not paced.
+func pacedRigOf(target string) (string, bool) {
+	if rigName, ok := IsRigName(target); ok {
+		return rigName, true
+	}
+	if isPolecatTarget(target) {
+		if parts := strings.Split(target, "/"); len(parts) >= 3 && parts[1] == "polecats" {
+			return parts[0], true
+		}
+	}
+	return "", false
+}
+
+// takeSlingPace counts a sling of bead to target against its rig's hourly
+// limit. The returned undo gives the slot back if the sling then fails.
+func takeSlingPace(townRoot, target, bead string) (undo func(), err error) {
+	rigName, ok := pacedRigOf(target)
+	if !ok || slingIgnoreSchedule {
+		return func() {}, nil
+	}
+	p, err := pacing.ForRig(rigName, filepath.Join(townRoot, rigName))
+	if err != nil {
+		return nil, err
+	}
+	undo, err = p.Take(bead)
+	if errors.Is(err, pacing.ErrRateLimited) {
+		return nil, fmt.Errorf("%w\nUse --ignore-schedule to sling anyway", err)
+	}
+	return undo, err
+}
+
+// claimPaced claims the next bead in r for agent, counting the claim
+// against the rig's hourly sling limit.
+func claimPaced(d *dispatch.Dispatcher, r *rig.Rig, agent string, caps []string) (*beads.Issue, error) {
+	p, err := pacing.ForRig(r.Name, r.Path)
+	if err != nil {
+		return nil, err
+	}
+	undo, err := p.Take("")
+	if err != nil {
+		return nil, err
+	}
+	issue, err := d.Claim(agent, caps)
+	if err != nil || issue == nil {
+		undo()
+	}
+	return issue, err
+}
+
+// checkSpawnHours returns an error if the rig may not spawn polecats now.
+func checkSpawnHours(r *rig.Rig, ignore bool) error {
+	if ignore {
+		return nil
+	}
+	p, err := pacing.ForRig(r.Name, r.Path)
+	if err != nil {
+		return err
+	}
+	if err := p.CheckSpawn(); err != nil {
+		return fmt.Errorf("%w\nUse --ignore-schedule to spawn anyway", err)
+	}
+	return nil
+}
+
+// pacingStatusLine summarizes a rig's pacing for gt rig status ("" if the
+// rig isn't paced).
+func pacingStatusLine(r *rig.Rig) string {
+	p, err := pacing.ForRig(r.Name, r.Path)
+	if err != nil {
+		return style.Warning.Render("Pacing: " + err.Error())
+	}
+	if !p.Paced() {
+		return ""
+	}
+	st, err := p.Status()
+	if err != nil {
+		return style.Warning.Render("Pacing: " + err.Error())
+	}
+	return formatPacingStatus(st)
+}
+
+func formatPacingStatus(st *pacing.Status) string {
+	var parts []string
+	if len(st.WorkingHours) > 0 {
+		if st.InHours {
+			parts = append(parts, "in working hours ("+strings.Join(st.WorkingHours, ", ")+")")
+		} else {
+			parts = append(parts, fmt.Sprintf("outside working hours, spawning resumes %s", st.NextOpen.Format("Mon 15:04")))
+		}
+	}
+	if st.Limit > 0 {
+		s := fmt.Sprintf("%d/%d slung this hour", st.Slung, st.Limit)
+		if !st.NextSlot.IsZero() {
+			s += fmt.Sprintf(", next slot %s", st.NextSlot.Format("15:04"))
+		}
+		parts = append(parts, s)
+	}
+	return style.Dim.Render("Pacing: " + strings.Join(parts, "; "))
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/pacing"
+)
+
+func TestFormatPacingStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		st   pacing.Status
+		want []string
+	}{
+		{
+			name: "open with room",
+			st:   pacing.Status{WorkingHours: []string{"weekday 09:00-18:00"}, InHours: true, Limit: 4, Slung: 1},
+			want: []string{"in working hours (weekday 09:00-18:00)", "1/4 slung this hour"},
+		},
+		{
+			name: "closed and full",
+			st: pacing.Status{
+				WorkingHours: []string{"weekday 09:00-18:00"},
+				NextOpen:     time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC),
+				Limit:        2,
+				Slung:        2,
+				NextSlot:     time.Date(2026, 3, 2, 19, 30, 0, 0, time.UTC),
+			},
+			want: []string{"outside working hours, spawning resumes Tue 09:00", "2/2 slung this hour, next slot 19:30"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatPacingStatus(&tt.st)
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("formatPacingStatus = %q, want it to contain %q", got, w)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,376 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/connection"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// remoteGTEnv names the environment variable that overrides the gt binary
+// run on remote machines (default "gt", looked up in the remote PATH).
+const remoteGTEnv = "GT_REMOTE_BIN"
+
+// remoteGT returns the gt binary to run on remote machines.
+func remoteGT() string {
+	if gt := os.Getenv(remoteGTEnv); gt != "" {
+		return gt
+	}
+	return "gt"
+}
+
+// remoteTarget is a sling target in another town: [user@]host:town/agent.
+type remoteTarget struct {
+	Host   string // ssh destination, [user@]host
+	Town   string // town directory, relative to the remote home
+	Target string // sling target within the remote town, e.g. gastown/Toast
+}
+
+// String returns the target in the form it was given.
+func (t remoteTarget) String() string {
+	return t.Host + ":" + t.Town + "/" + t.Target
+}
+
+// isRemoteTarget reports whether a sling target names another town.
+// Local targets never contain a colon.
+func isRemoteTarget(target string) bool {
+	return strings.Contains(target, ":")
+}
+
+// parseRemoteTarget parses a remote sling target, [user@]host:town/agent.
+// The town is a directory in the remote home; the agent is any target gt
+// sling accepts there (a rig, rig/polecat, mayor, ...).
+func parseRemoteTarget(s string) (remoteTarget, error) {
+	host, path, ok := strings.Cut(s, ":")
+	if !ok || host == "" || strings.HasPrefix(host, "-") || strings.HasSuffix(host, "@") {
+		return remoteTarget{}, fmt.Errorf("invalid remote target %q: want [user@]host:town/agent", s)
+	}
+	path = strings.TrimPrefix(path, "~/")
+	town, target, _ := strings.Cut(path, "/")
+	target = strings.Trim(target, "/")
+	if town == "" || target == "" {
+		return remoteTarget{}, fmt.Errorf("invalid remote target %q: want [user@]host:town/agent", s)
+	}
+	return remoteTarget{Host: host, Town: town, Target: target}, nil
+}
+
+// remoteSlingRequest is what gt sling sends gt sling-receive on the remote
+// town: the work to create there and how to sling it.
+type remoteSlingRequest struct {
+	Origin      remoteSlingOrigin `json:"origin"`
+	Target      string            `json:"target"`
+	Title       string            `json:"title"`
+	Description string            `json:"description,omitempty"`
+	Type        string            `json:"type,omitempty"`
+	Priority    int               `json:"priority"`
+	Subject     string            `json:"subject,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	Args        string            `json:"args,omitempty"`
+	Create      bool              `json:"create,omitempty"`
+	Force       bool              `json:"force,omitempty"`
+	Account     string            `json:"account,omitempty"`
+	Agent       string            `json:"agent,omitempty"`
+	NoConvoy    bool              `json:"no_convoy,omitempty"`
+}
+
+// remoteSlingOrigin identifies where remote work came from.
+type remoteSlingOrigin struct {
+	Host       string `json:"host"`
+	Town       string `json:"town"`
+	Bead       string `json:"bead"`
+	Dispatcher string `json:"dispatcher"`
+}
+
+// String returns the origin as a mail sender, e.g. "mayor@central:hq".
+func (o remoteSlingOrigin) String() string {
+	return o.Dispatcher + "@" + o.Host + ":" + o.Town
+}
+
+// remoteSlingResult is what gt sling-receive prints on success.
+type remoteSlingResult struct {
+	Bead  string `json:"bead"`
+	Agent string `json:"agent"`
+	Mail  string `json:"mail,omitempty"`
+}
+
+// sendRemoteSling runs gt sling-receive on the remote town with req on
+// stdin and returns its result. Tests replace it.
+var sendRemoteSling = func(rt remoteTarget, req *remoteSlingRequest) (*remoteSlingResult, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	cmd := connection.NewSSH(rt.Host, "").Command("~/"+rt.Town, remoteGT(), "sling-receive")
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("remote sling on %s: %w", rt.Host, err)
+	}
+
+	// The result is the last line; anything before it is remote noise
+	// (login banners and the like).
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	var res remoteSlingResult
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &res); err != nil {
+		return nil, fmt.Errorf("parsing remote sling result from %s: %w", rt.Host, err)
+	}
+	return &res, nil
+}
+
+// newRemoteSlingRequest builds the request that slings issue to rt.
+func newRemoteSlingRequest(townRoot string, issue *beads.Issue, rt remoteTarget) *remoteSlingRequest {
+	host, _ := os.Hostname()
+	town, err := workspace.GetTownName(townRoot)
+	if err != nil {
+		town = filepath.Base(townRoot)
+	}
+	return &remoteSlingRequest{
+		Origin:      remoteSlingOrigin{Host: host, Town: town, Bead: issue.ID, Dispatcher: detectActor()},
+		Target:      rt.Target,
+		Title:       issue.Title,
+		Description: issue.Description,
+		Type:        issue.Type,
+		Priority:    issue.Priority,
+		Subject:     slingSubject,
+		Message:     slingMessage,
+		Args:        slingArgs,
+		Create:      slingCreate,
+		Force:       slingForce,
+		Account:     slingAccount,
+		Agent:       slingAgent,
+		NoConvoy:    slingNoConvoy,
+	}
+}
+
+// runRemoteSling slings a local bead to an agent in another town over SSH.
+// The remote town gets its own copy of the work as a wisp, hooked to the
+// agent, plus a mail telling the agent where the work came from; the local
+// bead is hooked to the remote target so it shows as assigned.
+func runRemoteSling(townRoot, beadID string, rt remoteTarget) error {
+	if slingOnTarget != "" || len(slingVars) > 0 {
+		return fmt.Errorf("formulas cannot be slung to a remote town")
+	}
+
+	issue, err := beads.New(townRoot).Show(beadID)
+	if err != nil {
+		return fmt.Errorf("bead '%s' not found", beadID)
+	}
+	if (issue.Status == "pinned" || issue.Status == "hooked") && !slingForce {
+		assignee := issue.Assignee
+		if assignee == "" {
+			assignee = "(unknown)"
+		}
+		return fmt.Errorf("bead %s is already %s to %s\nUse --force to re-sling", beadID, issue.Status, assignee)
+	}
+
+	req := newRemoteSlingRequest(townRoot, issue, rt)
+	fmt.Printf("%s Slinging %s to %s...\n", style.Bold.Render("🎯"), beadID, rt)
+
+	if slingDryRun {
+		fmt.Printf("Would run on %s (in ~/%s): gt sling-receive\n", rt.Host, rt.Town)
+		fmt.Printf("  1. Create wisp %q there\n", req.Title)
+		fmt.Printf("  2. gt sling <wisp> %s\n", rt.Target)
+		fmt.Printf("  3. Mail the agent from %s\n", req.Origin)
+		fmt.Printf("Would run: bd update %s --status=hooked --assignee=%s\n", beadID, rt)
+		return nil
+	}
+
+	res, err := sendRemoteSling(rt, req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Remote wisp %s hooked to %s on %s\n", style.Bold.Render("✓"), res.Bead, res.Agent, rt.Host)
+
+	hookCmd := exec.Command("bd", "--no-daemon", "update", beadID, "--status=hooked", "--assignee="+rt.String())
+	hookCmd.Dir = beads.ResolveHookDir(townRoot, beadID, "")
+	hookCmd.Stderr = os.Stderr
+	if err := hookCmd.Run(); err != nil {
+		return fmt.Errorf("hooking bead: %w", err)
+	}
+	fmt.Printf("%s Work attached to hook (status=hooked)\n", style.Bold.Render("✓"))
+
+	payload := events.SlingPayload(beadID, rt.String())
+	payload["remote_bead"] = res.Bead
+	_ = events.LogFeed(events.TypeSling, req.Origin.Dispatcher, payload)
+	return nil
+}
+
+var slingReceiveCmd = &cobra.Command{
+	Use:    "sling-receive",
+	Short:  "Accept work slung from another town (used by gt sling over SSH)",
+	Hidden: true,
+	Long: `Read a remote sling request as JSON on stdin, create the work as a wisp
+in this town, sling it to the requested agent, and mail the agent where it
+came from. Prints the result as JSON on stdout.
+
+gt sling runs this over SSH for targets of the form host:town/agent.`,
+	Args: cobra.NoArgs,
+	RunE: runSlingReceive,
+}
+
+func init() {
+	rootCmd.AddCommand(slingReceiveCmd)
+}
+
+// slingLocally runs gt sling in this town. Tests replace it.
+var slingLocally = func(townRoot string, args []string) error {
+	self, err := os.Executable()
+	if err != nil {
+		self = "gt"
+	}
+	cmd := exec.Command(self, append([]string{"sling"}, args...)...) //nolint:gosec // G204: args are built from the request
+	cmd.Dir = townRoot
+	// Keep stdout for the result
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func runSlingReceive(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	var req remoteSlingRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		return fmt.Errorf("reading remote sling request: %w", err)
+	}
+	res, err := receiveRemoteSling(townRoot, &req)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(os.Stdout).Encode(res)
+}
+
+// receiveRemoteSling creates the requested work as a wisp in the target's
+// rig (or the town, for town-level agents), slings it, and mails the agent.
+func receiveRemoteSling(townRoot string, req *remoteSlingRequest) (*remoteSlingResult, error) {
+	if req.Target == "" || req.Title == "" {
+		return nil, fmt.Errorf("remote sling request needs a target and a title")
+	}
+
+	dir := townRoot
+	if rig := remoteTargetRig(req.Target); rig != "" {
+		dir = filepath.Join(townRoot, rig)
+	}
+	b := beads.New(dir)
+	typ := req.Type
+	if typ == "" {
+		typ = "task"
+	}
+	wisp, err := b.Create(beads.CreateOptions{
+		Title:       req.Title,
+		Type:        typ,
+		Priority:    req.Priority,
+		Description: remoteWispDescription(req),
+		Actor:       req.Origin.String(),
+		Ephemeral:   true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating wisp: %w", err)
+	}
+
+	if err := slingLocally(townRoot, remoteSlingArgs(wisp.ID, req)); err != nil {
+		return nil, fmt.Errorf("slinging %s to %s: %w", wisp.ID, req.Target, err)
+	}
+
+	res := &remoteSlingResult{Bead: wisp.ID, Agent: req.Target}
+	if hooked, err := b.Show(wisp.ID); err == nil && hooked.Assignee != "" {
+		res.Agent = hooked.Assignee
+	}
+
+	msg := mail.NewMessage(req.Origin.String(), res.Agent, "Remote work: "+req.Title, remoteSlingMailBody(wisp.ID, req))
+	msg.Type = mail.TypeTask
+	if err := mail.NewRouterWithTownRoot(townRoot, townRoot).Send(msg); err != nil {
+		// The work is hooked; the agent finds it without the mail
+		fmt.Fprintf(os.Stderr, "%s Could not mail %s: %v\n", style.Dim.Render("Warning:"), res.Agent, err)
+	} else {
+		res.Mail = msg.ID
+	}
+	return res, nil
+}
+
+// remoteTargetRig returns the rig a sling target lives in, or "" for
+// town-level agents.
+func remoteTargetRig(target string) string {
+	first, _, _ := strings.Cut(target, "/")
+	switch first {
+	case "mayor", "deacon":
+		return ""
+	}
+	return first
+}
+
+// remoteSlingArgs returns the gt sling arguments that hook wispID as req asks.
+func remoteSlingArgs(wispID string, req *remoteSlingRequest) []string {
+	args := []string{wispID, req.Target}
+	if req.Subject != "" {
+		args = append(args, "--subject", req.Subject)
+	}
+	if req.Message != "" {
+		args = append(args, "--message", req.Message)
+	}
+	if req.Args != "" {
+		args = append(args, "--args", req.Args)
+	}
+	if req.Create {
+		args = append(args, "--create")
+	}
+	if req.Force {
+		args = append(args, "--force")
+	}
+	if req.Account != "" {
+		args = append(args, "--account", req.Account)
+	}
+	if req.Agent != "" {
+		args = append(args, "--agent", req.Agent)
+	}
+	if req.NoConvoy {
+		args = append(args, "--no-convoy")
+	}
+	return args
+}
+
+// remoteWispDescription is the remote wisp's description: the original
+// description followed by where the work came from.
+func remoteWispDescription(req *remoteSlingRequest) string {
+	var sb strings.Builder
+	if req.Description != "" {
+		sb.WriteString(strings.TrimRight(req.Description, "\n"))
+		sb.WriteString("\n\n")
+	}
+	fmt.Fprintf(&sb, "remote_origin: %s:%s\n", req.Origin.Host, req.Origin.Town)
+	fmt.Fprintf(&sb, "remote_bead: %s\n", req.Origin.Bead)
+	fmt.Fprintf(&sb, "remote_dispatcher: %s", req.Origin.Dispatcher)
+	return sb.String()
+}
+
+// remoteSlingMailBody is the body of the mail telling the agent about
+// remote work.
+func remoteSlingMailBody(wispID string, req *remoteSlingRequest) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Work slung from %s:%s by %s.\n\n", req.Origin.Host, req.Origin.Town, req.Origin.Dispatcher)
+	fmt.Fprintf(&sb, "Bead: %s\n", wispID)
+	fmt.Fprintf(&sb, "Origin-Bead: %s\n", req.Origin.Bead)
+	fmt.Fprintf(&sb, "Priority: P%d\n", req.Priority)
+	if req.Args != "" {
+		fmt.Fprintf(&sb, "Args: %s\n", req.Args)
+	}
+	if req.Message != "" {
+		fmt.Fprintf(&sb, "\n%s\n", req.Message)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
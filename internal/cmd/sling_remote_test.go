@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRemoteTarget(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    remoteTarget
+		wantErr bool
+	}{
+		{input: "me@box:gt/gastown", want: remoteTarget{Host: "me@box", Town: "gt", Target: "gastown"}},
+		{input: "box:~/gt/gastown/crew/max", want: remoteTarget{Host: "box", Town: "gt", Target: "gastown/crew/max"}},
+		{input: "box:gt/mayor/", want: remoteTarget{Host: "box", Town: "gt", Target: "mayor"}},
+		{input: "box:gt", wantErr: true},
+		{input: "box:/gastown", wantErr: true},
+		{input: ":gt/gastown", wantErr: true},
+		{input: "me@:gt/gastown", wantErr: true},
+		{input: "-oProxyCommand=touch /tmp/x:gt/gastown", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseRemoteTarget(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRemoteTarget(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseRemoteTarget(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+
+	if isRemoteTarget("gastown/Toast") || !isRemoteTarget("box:gt/gastown") {
+		t.Error("isRemoteTarget misclassifies targets")
+	}
+	if got := (remoteTarget{Host: "box", Town: "gt", Target: "gastown/Toast"}).String(); got != "box:gt/gastown/Toast" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestRemoteSlingRequest(t *testing.T) {
+	req := &remoteSlingRequest{
+		Origin:      remoteSlingOrigin{Host: "central", Town: "hq", Bead: "hq-abc", Dispatcher: "mayor"},
+		Target:      "gastown",
+		Title:       "Fix login",
+		Description: "Login fails on Safari.\n",
+		Priority:    1,
+		Args:        "patch release",
+		Create:      true,
+		NoConvoy:    true,
+	}
+
+	wantArgs := []string{"gt-wisp-1", "gastown", "--args", "patch release", "--create", "--no-convoy"}
+	if got := remoteSlingArgs("gt-wisp-1", req); !reflect.DeepEqual(got, wantArgs) {
+		t.Errorf("remoteSlingArgs = %q, want %q", got, wantArgs)
+	}
+
+	desc := remoteWispDescription(req)
+	want := "Login fails on Safari.\n\nremote_origin: central:hq\nremote_bead: hq-abc\nremote_dispatcher: mayor"
+	if desc != want {
+		t.Errorf("remoteWispDescription = %q, want %q", desc, want)
+	}
+
+	body := remoteSlingMailBody("gt-wisp-1", req)
+	for _, line := range []string{"Work slung from central:hq by mayor.", "Bead: gt-wisp-1", "Origin-Bead: hq-abc", "Priority: P1", "Args: patch release"} {
+		if !strings.Contains(body, line) {
+			t.Errorf("mail body missing %q:\n%s", line, body)
+		}
+	}
+	if got := req.Origin.String(); got != "mayor@central:hq" {
+		t.Errorf("origin = %q", got)
+	}
+
+	for target, rig := range map[string]string{"gastown": "gastown", "gastown/crew/max": "gastown", "mayor": "", "deacon/dogs": ""} {
+		if got := remoteTargetRig(target); got != rig {
+			t.Errorf("remoteTargetRig(%q) = %q, want %q", target, got, rig)
+		}
+	}
+}
@@ -35,15 +35,15 @@ func resolveTargetAgent(target string) (agentID string, pane string, hookRoot st
 	return agentID, pane, hookRoot, nil
 }
 
-// sessionToAgentID converts a session name to agent ID format.
-// Uses session.ParseSessionName for consistent parsing across the codebase.
+// sessionToAgentID converts a session name to the agent ID used as the
+// assignee of hooked beads, the same form resolveSelfTarget produces.
 func sessionToAgentID(sessionName string) string {
-	identity, err := session.ParseSessionName(sessionName)
+	agent, err := session.ParseSessionName(sessionName)
 	if err != nil {
 		// Fallback for unparseable sessions
 		return sessionName
 	}
-	return identity.Address()
+	return agent.Identity().Assignee()
 }
 
 // resolveSelfTarget determines agent identity, pane, and hook root for slinging to self.
@@ -53,24 +53,13 @@ func resolveSelfTarget() (agentID string, pane string, hookRoot string, err erro
 		return "", "", "", fmt.Errorf("detecting role: %w", err)
 	}
 
-	// Build agent identity from role
-	// Town-level agents use trailing slash to match addressToIdentity() normalization
-	switch roleInfo.Role {
-	case RoleMayor:
-		agentID = "mayor/"
-	case RoleDeacon:
-		agentID = "deacon/"
-	case RoleWitness:
-		agentID = fmt.Sprintf("%s/witness", roleInfo.Rig)
-	case RoleRefinery:
-		agentID = fmt.Sprintf("%s/refinery", roleInfo.Rig)
-	case RolePolecat:
-		agentID = fmt.Sprintf("%s/polecats/%s", roleInfo.Rig, roleInfo.Polecat)
-	case RoleCrew:
-		agentID = fmt.Sprintf("%s/crew/%s", roleInfo.Rig, roleInfo.Polecat)
-	default:
+	// Build agent identity from role, in the assignee form that
+	// buildAgentIdentity looks up (town-level agents keep a trailing slash)
+	id, ok := roleIdentity(roleInfo)
+	if !ok {
 		return "", "", "", fmt.Errorf("cannot determine agent identity (role: %s)", roleInfo.Role)
 	}
+	agentID = id.Assignee()
 
 	pane = os.Getenv("TMUX_PANE")
 	hookRoot = roleInfo.Home
@@ -0,0 +1,470 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/daemon"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/logrotate"
+	"github.com/steveyegge/gastown/internal/slo"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	statsSince string
+	statsUntil string
+	statsRig   string
+	statsJSON  bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:     "stats",
+	GroupID: GroupDiag,
+	Short:   "Show delivery metrics: lead time, queue wait, merge success",
+	Long: `Show how work flows through the town over a time window, per rig.
+
+Metrics are computed from the events log (gt done, merge_started, merged,
+merge_failed) and bead creation times:
+
+  MERGED      branches merged in the window
+  FAILED      merge attempts that failed in the window
+  FIRST-TRY   share of branches whose first merge attempt succeeded
+  CONFLICTS   share of merge attempts that failed on a conflict
+  LEAD TIME   bead created → branch merged (median / p90)
+  QUEUE WAIT  gt done → refinery starts the merge (median / p90)
+
+Service level objectives for the merge queue are configured under "slos" in
+mayor/daemon.json and reported over their own rolling windows, ending at
+--until:
+
+  {
+    "slos": [
+      {"name": "merge-2h", "rig": "gastown", "target": 0.9, "within": "2h", "window": "7d"}
+    ]
+  }
+
+An MR meets the objective when it merges within the target time of its
+first gt done; one that merges later, or is still unmerged past the target
+time, is a miss. The allowed share of misses is the error budget. The daemon
+alerts when a budget is exhausted (see gt alerts).
+
+Examples:
+  gt stats                   # Last 7 days
+  gt stats --since 30d --rig gastown
+  gt stats --json            # For reporting`,
+	RunE: runStats,
+}
+
+func init() {
+	statsCmd.Flags().StringVar(&statsSince, "since", "7d", "Start time: duration ago (1h, 7d), RFC3339, or local date")
+	statsCmd.Flags().StringVar(&statsUntil, "until", "", "End time: duration ago, RFC3339, or local date")
+	statsCmd.Flags().StringVar(&statsRig, "rig", "", "Only this rig")
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Output as JSON")
+
+	rootCmd.AddCommand(statsCmd)
+}
+
+// DeliveryStats are the delivery metrics of a rig (or the whole town).
+type DeliveryStats struct {
+	Rig              string        `json:"rig,omitempty"`
+	Merged           int           `json:"merged"`
+	Failed           int           `json:"failed"`
+	Conflicts        int           `json:"conflicts"`
+	ConflictRate     float64       `json:"conflict_rate"`
+	FirstAttempts    int           `json:"first_attempts"`
+	FirstAttemptWins int           `json:"first_attempt_merged"`
+	FirstAttemptRate float64       `json:"first_attempt_success_rate"`
+	LeadTime         DurationStats `json:"lead_time"`
+	QueueWait        DurationStats `json:"queue_wait"`
+}
+
+// DurationStats summarizes a set of durations, in seconds.
+type DurationStats struct {
+	Count  int     `json:"count"`
+	Median float64 `json:"median_seconds"`
+	P90    float64 `json:"p90_seconds"`
+	Mean   float64 `json:"mean_seconds"`
+
+	samples []time.Duration
+}
+
+func (d *DurationStats) add(v time.Duration) {
+	if v >= 0 {
+		d.samples = append(d.samples, v)
+	}
+}
+
+func (d *DurationStats) finish() {
+	d.Count = len(d.samples)
+	if d.Count == 0 {
+		return
+	}
+	sort.Slice(d.samples, func(i, j int) bool { return d.samples[i] < d.samples[j] })
+	var sum time.Duration
+	for _, v := range d.samples {
+		sum += v
+	}
+	rank := func(p float64) time.Duration {
+		i := int(p*float64(d.Count)+0.999999) - 1 // Nearest rank
+		if i < 0 {
+			i = 0
+		}
+		return d.samples[i]
+	}
+	d.Median = rank(0.5).Seconds()
+	d.P90 = rank(0.9).Seconds()
+	d.Mean = (sum / time.Duration(d.Count)).Seconds()
+}
+
+// StatsReport is the output of gt stats.
+type StatsReport struct {
+	Since time.Time       `json:"since"`
+	Until time.Time       `json:"until"`
+	Total DeliveryStats   `json:"total"`
+	Rigs  []DeliveryStats `json:"rigs"`
+	SLOs  []slo.Status    `json:"slos,omitempty"`
+}
+
+// deliveryMR tracks one branch through the merge queue.
+type deliveryMR struct {
+	bead      string
+	submitted time.Time // Last gt done
+	started   bool      // A merge started since submission
+	outcomes  int
+}
+
+// leadSample is a merge awaiting its bead's creation time.
+type leadSample struct {
+	rig, bead string
+	merged    time.Time
+}
+
+// deliveryCollector computes delivery stats from events in time order.
+type deliveryCollector struct {
+	since, until time.Time
+	total        DeliveryStats
+	rigs         map[string]*DeliveryStats
+	mrs          map[string]*deliveryMR
+	leads        []leadSample
+}
+
+func newDeliveryCollector(since, until time.Time) *deliveryCollector {
+	return &deliveryCollector{
+		since: since,
+		until: until,
+		rigs:  make(map[string]*DeliveryStats),
+		mrs:   make(map[string]*deliveryMR),
+	}
+}
+
+func (c *deliveryCollector) inWindow(ts time.Time) bool {
+	return !ts.Before(c.since) && (c.until.IsZero() || ts.Before(c.until))
+}
+
+// each applies fn to the town totals and the rig's stats.
+func (c *deliveryCollector) each(rig string, fn func(s *DeliveryStats)) {
+	s := c.rigs[rig]
+	if s == nil {
+		s = &DeliveryStats{Rig: rig}
+		c.rigs[rig] = s
+	}
+	fn(&c.total)
+	fn(s)
+}
+
+// add processes one event; events must be added oldest first.
+func (c *deliveryCollector) add(e events.Event) {
+	branch, _ := e.Payload["branch"].(string)
+	ts := e.Time()
+	if branch == "" || ts.IsZero() {
+		return
+	}
+	key := e.Rig + "\x00" + branch
+	mr := c.mrs[key]
+	if mr == nil {
+		mr = &deliveryMR{}
+		c.mrs[key] = mr
+	}
+
+	switch e.Type {
+	case events.TypeDone:
+		mr.submitted = ts
+		mr.started = false
+		if bead, _ := e.Payload["bead"].(string); bead != "" {
+			mr.bead = bead
+		}
+
+	case events.TypeMergeStarted:
+		if mr.bead == "" {
+			mr.bead, _ = e.Payload["issue"].(string)
+		}
+		if !mr.submitted.IsZero() && !mr.started {
+			mr.started = true
+			if c.inWindow(ts) {
+				c.each(e.Rig, func(s *DeliveryStats) { s.QueueWait.add(ts.Sub(mr.submitted)) })
+			}
+		}
+
+	case events.TypeMerged, events.TypeMergeFailed:
+		first := mr.outcomes == 0
+		mr.outcomes++
+		merged := e.Type == events.TypeMerged
+		if c.inWindow(ts) {
+			reason, _ := e.Payload["reason"].(string)
+			c.each(e.Rig, func(s *DeliveryStats) {
+				if merged {
+					s.Merged++
+				} else {
+					s.Failed++
+					if reason == "conflict" {
+						s.Conflicts++
+					}
+				}
+				if first {
+					s.FirstAttempts++
+					if merged {
+						s.FirstAttemptWins++
+					}
+				}
+			})
+			if merged && mr.bead != "" {
+				c.leads = append(c.leads, leadSample{rig: e.Rig, bead: mr.bead, merged: ts})
+			}
+		}
+		if merged {
+			delete(c.mrs, key) // A later branch of the same name starts afresh
+		}
+	}
+}
+
+// resolveLeadTimes looks up the creation times of merged beads, per rig,
+// and records lead times.
+func (c *deliveryCollector) resolveLeadTimes(createdAt func(rig string, beads []string) map[string]time.Time) {
+	byRig := make(map[string][]string)
+	for _, l := range c.leads {
+		byRig[l.rig] = append(byRig[l.rig], l.bead)
+	}
+	created := make(map[string]map[string]time.Time)
+	for rig, ids := range byRig {
+		created[rig] = createdAt(rig, ids)
+	}
+	for _, l := range c.leads {
+		if t, ok := created[l.rig][l.bead]; ok {
+			c.each(l.rig, func(s *DeliveryStats) { s.LeadTime.add(l.merged.Sub(t)) })
+		}
+	}
+}
+
+// report finalizes the stats, rigs sorted by name.
+func (c *deliveryCollector) report() StatsReport {
+	r := StatsReport{Since: c.since, Until: c.until, Rigs: []DeliveryStats{}}
+	finish := func(s *DeliveryStats) {
+		attempts := s.Merged + s.Failed
+		if attempts > 0 {
+			s.ConflictRate = float64(s.Conflicts) / float64(attempts)
+		}
+		if s.FirstAttempts > 0 {
+			s.FirstAttemptRate = float64(s.FirstAttemptWins) / float64(s.FirstAttempts)
+		}
+		s.LeadTime.finish()
+		s.QueueWait.finish()
+	}
+	for _, s := range c.rigs {
+		if s.Merged+s.Failed+len(s.QueueWait.samples) == 0 {
+			continue
+		}
+		finish(s)
+		r.Rigs = append(r.Rigs, *s)
+	}
+	sort.Slice(r.Rigs, func(i, j int) bool { return r.Rigs[i].Rig < r.Rigs[j].Rig })
+	finish(&c.total)
+	r.Total = c.total
+	return r
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	now := time.Now()
+	since, err := parseLogTime(statsSince, now)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	until := now
+	if statsUntil != "" {
+		if until, err = parseLogTime(statsUntil, now); err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+
+	evts, err := readDeliveryEvents(filepath.Join(townRoot, events.EventsFile), "")
+	if err != nil {
+		return fmt.Errorf("reading events log: %w", err)
+	}
+	c := newDeliveryCollector(since, until)
+	for _, e := range evts {
+		if statsRig == "" || e.Rig == statsRig {
+			c.add(e)
+		}
+	}
+	c.resolveLeadTimes(func(rig string, ids []string) map[string]time.Time {
+		return beadCreationTimes(townRoot, rig, ids)
+	})
+	report := c.report()
+	if cfg := daemon.LoadPatrolConfig(townRoot); cfg != nil {
+		report.SLOs = measureSLOs(cfg.SLOs, evts, statsRig, until)
+	}
+
+	if statsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	fmt.Printf("\n%s Delivery Stats %s → %s\n\n", style.Bold.Render("📊"),
+		since.Format("2006-01-02 15:04"), until.Format("2006-01-02 15:04"))
+	if report.Total.Merged+report.Total.Failed == 0 && report.Total.QueueWait.Count == 0 {
+		fmt.Printf("%s No merge activity in this window\n", style.Dim.Render("○"))
+	} else {
+		fmt.Printf("  %-16s %6s %6s %9s %9s  %-22s %s\n", "", "MERGED", "FAILED", "FIRST-TRY", "CONFLICTS", "LEAD TIME (p50/p90)", "QUEUE WAIT (p50/p90)")
+		for _, s := range report.Rigs {
+			printDeliveryRow(s.Rig, s, false)
+		}
+		if len(report.Rigs) > 1 {
+			printDeliveryRow("Total", report.Total, true)
+		}
+	}
+	printSLOs(report.SLOs)
+	return nil
+}
+
+// measureSLOs measures the enabled objectives at until. With a rig filter
+// only town-wide objectives and those of the rig are measured.
+func measureSLOs(objectives []slo.Objective, evts []events.Event, rig string, until time.Time) []slo.Status {
+	var result []slo.Status
+	for _, o := range objectives {
+		if o.Disabled || (rig != "" && o.Rig != "" && o.Rig != rig) {
+			continue
+		}
+		st, err := slo.Measure(o, evts, until)
+		if err != nil {
+			style.PrintWarning("%v", err)
+			continue
+		}
+		result = append(result, st)
+	}
+	return result
+}
+
+func printSLOs(statuses []slo.Status) {
+	if len(statuses) == 0 {
+		return
+	}
+	fmt.Printf("\n  %-16s %-16s %8s %5s %6s %7s  %s\n", "SLO", "OBJECTIVE", "ATTAINED", "MET", "MISSED", "PENDING", "ERROR BUDGET")
+	for _, s := range statuses {
+		objective := fmt.Sprintf("%.4g%% ≤%s/%s", s.Target*100, s.Within, s.Window)
+		budget := s.BudgetString()
+		switch {
+		case s.Exhausted:
+			budget = style.Error.Render(budget)
+		case s.BurnRate > 1:
+			budget = style.Warning.Render(fmt.Sprintf("%s (burning %.1fx)", budget, s.BurnRate))
+		}
+		fmt.Printf("  %-16s %-16s %8s %5d %6d %7d  %s\n", s.Name, objective,
+			formatRate(s.Attainment, s.Met+s.Missed), s.Met, s.Missed, s.Pending, budget)
+	}
+}
+
+func printDeliveryRow(name string, s DeliveryStats, bold bool) {
+	label := fmt.Sprintf("%-16s", name)
+	if name == "" {
+		label = fmt.Sprintf("%-16s", "(town)")
+	}
+	if bold {
+		label = style.Bold.Render(label)
+	}
+	fmt.Printf("  %s %6d %6d %9s %9s  %-22s %s\n", label, s.Merged, s.Failed,
+		formatRate(s.FirstAttemptRate, s.FirstAttempts), formatRate(s.ConflictRate, s.Merged+s.Failed),
+		formatDurationStats(s.LeadTime), formatDurationStats(s.QueueWait))
+}
+
+func formatRate(rate float64, n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", rate*100)
+}
+
+func formatDurationStats(d DurationStats) string {
+	if d.Count == 0 {
+		return "-"
+	}
+	seconds := func(s float64) string { return formatDuration(time.Duration(s * float64(time.Second))) }
+	return seconds(d.Median) + " / " + seconds(d.P90)
+}
+
+// readDeliveryEvents reads the merge lifecycle events of the events log,
+// including rotated segments, oldest first.
+func readDeliveryEvents(path, rig string) ([]events.Event, error) {
+	f, err := logrotate.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var result []events.Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		e, err := events.Parse(scanner.Bytes())
+		if err != nil {
+			continue
+		}
+		switch e.Type {
+		case events.TypeDone, events.TypeMergeStarted, events.TypeMerged, events.TypeMergeFailed:
+		default:
+			continue
+		}
+		if rig != "" && e.Rig != rig {
+			continue
+		}
+		result = append(result, e)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Time().Before(result[j].Time()) })
+	return result, scanner.Err()
+}
+
+// beadCreationTimes returns the creation times of a rig's beads (town
+// beads for rig "").
+func beadCreationTimes(townRoot, rig string, ids []string) map[string]time.Time {
+	dir := beads.GetTownBeadsPath(townRoot)
+	if rig != "" {
+		dir = filepath.Join(townRoot, rig, "mayor", "rig")
+	}
+	issues, err := beads.New(dir).ShowMultiple(ids)
+	result := make(map[string]time.Time)
+	if err != nil {
+		return result
+	}
+	for id, issue := range issues {
+		if t, err := time.Parse(time.RFC3339, issue.CreatedAt); err == nil {
+			result[id] = t
+		}
+	}
+	return result
+}
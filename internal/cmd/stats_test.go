@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+func TestDeliveryCollector(t *testing.T) {
+	base := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	at := func(min int) string { return base.Add(time.Duration(min) * time.Minute).Format(time.RFC3339) }
+	ev := func(min int, typ, rig string, payload map[string]interface{}) events.Event {
+		return events.Event{Timestamp: at(min), Type: typ, Rig: rig, Payload: payload}
+	}
+	evts := []events.Event{
+		// gt-a: merged on the first try, 10m queue wait
+		ev(0, events.TypeDone, "gastown", events.DonePayload("gt-a", "polecat/toast", "COMPLETED")),
+		ev(10, events.TypeMergeStarted, "gastown", map[string]interface{}{"branch": "polecat/toast"}),
+		ev(12, events.TypeMerged, "gastown", events.MergePayload("", "toast", "polecat/toast", "")),
+
+		// gt-b: conflict, resubmitted, then merged; 4m then 2m queue waits
+		ev(20, events.TypeDone, "gastown", events.DonePayload("gt-b", "polecat/nux", "COMPLETED")),
+		ev(24, events.TypeMergeStarted, "gastown", map[string]interface{}{"branch": "polecat/nux"}),
+		ev(25, events.TypeMergeFailed, "gastown", events.MergePayload("", "nux", "polecat/nux", "conflict")),
+		ev(30, events.TypeDone, "gastown", events.DonePayload("gt-b", "polecat/nux", "COMPLETED")),
+		ev(32, events.TypeMergeStarted, "gastown", map[string]interface{}{"branch": "polecat/nux"}),
+		ev(33, events.TypeMerged, "gastown", events.MergePayload("", "nux", "polecat/nux", "")),
+
+		// bd-c in another rig: tests failed, bead known only from merge_started
+		ev(40, events.TypeMergeStarted, "beads", map[string]interface{}{"branch": "polecat/max", "issue": "bd-c"}),
+		ev(41, events.TypeMergeFailed, "beads", events.MergePayload("", "max", "polecat/max", "tests_fail")),
+
+		// Outside the window
+		ev(200, events.TypeMerged, "beads", events.MergePayload("", "max", "polecat/max", "")),
+	}
+
+	c := newDeliveryCollector(base, base.Add(2*time.Hour))
+	for _, e := range evts {
+		c.add(e)
+	}
+	var looked []string
+	c.resolveLeadTimes(func(rig string, ids []string) map[string]time.Time {
+		looked = append(looked, ids...)
+		return map[string]time.Time{"gt-a": base.Add(-48 * time.Minute), "gt-b": base.Add(-27 * time.Minute)}
+	})
+	r := c.report()
+
+	if len(looked) != 2 {
+		t.Errorf("looked up %v, want the two merged beads", looked)
+	}
+	tot := r.Total
+	if tot.Merged != 2 || tot.Failed != 2 || tot.Conflicts != 1 || tot.ConflictRate != 0.25 {
+		t.Errorf("totals = %+v", tot)
+	}
+	if tot.FirstAttempts != 3 || tot.FirstAttemptWins != 1 {
+		t.Errorf("first attempts = %d/%d, want 1/3", tot.FirstAttemptWins, tot.FirstAttempts)
+	}
+	if tot.QueueWait.Count != 3 || tot.QueueWait.Median != (4*time.Minute).Seconds() || tot.QueueWait.P90 != (10*time.Minute).Seconds() {
+		t.Errorf("queue wait = %+v", tot.QueueWait)
+	}
+	if tot.LeadTime.Count != 2 || tot.LeadTime.Median != (60*time.Minute).Seconds() {
+		t.Errorf("lead time = %+v", tot.LeadTime)
+	}
+
+	if len(r.Rigs) != 2 || r.Rigs[0].Rig != "beads" || r.Rigs[1].Rig != "gastown" {
+		t.Fatalf("rigs = %+v", r.Rigs)
+	}
+	if b := r.Rigs[0]; b.Failed != 1 || b.FirstAttemptRate != 0 || b.QueueWait.Count != 0 {
+		t.Errorf("beads = %+v", b)
+	}
+	if g := r.Rigs[1]; g.Merged != 2 || g.FirstAttemptRate != 0.5 {
+		t.Errorf("gastown = %+v", g)
+	}
+}
@@ -0,0 +1,385 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/backup"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/connection"
+	"github.com/steveyegge/gastown/internal/crew"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/util"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	townMigrateTo     string
+	townMigrateForce  bool
+	townMigrateDryRun bool
+)
+
+var townMigrateCmd = &cobra.Command{
+	Use:   "migrate --to [user@]host:path",
+	Short: "Move the town to another machine",
+	Long: `Move the town to another machine over SSH.
+
+Migration backs up the town's state (see gt backup), streams the archive
+to the new host, and restores it at path there. The new host then clones
+each rig again from its recorded git URL, recreates the infrastructure
+checkouts (shared bare repo, Mayor clone, refinery worktree) and crew
+workspaces, and validates the result. This town is left untouched.
+
+Work that lives only in this machine's checkouts can't move, so migration
+refuses to start while polecats exist or crew workspaces have uncommitted
+changes, stashes, or unpushed commits (--force overrides). Untracked files
+in crew workspaces are not moved.
+
+Stop the town first (gt down) so agents don't keep working here. The new
+host needs key-based SSH access, git, bd, and gt in its PATH (or set
+GT_REMOTE_BIN).
+
+Examples:
+  gt town migrate --to me@newbox:gt --dry-run
+  gt town migrate --to me@newbox:/srv/gt`,
+	Args: cobra.NoArgs,
+	RunE: runTownMigrate,
+}
+
+var townReceiveCmd = &cobra.Command{
+	Use:    "receive <dir>",
+	Short:  "Restore a migrated town (used by gt town migrate over SSH)",
+	Hidden: true,
+	Long: `Read a town backup on stdin, restore it into dir, recreate the rigs'
+checkouts, and validate the result. Prints the result as JSON on stdout.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTownReceive,
+}
+
+func init() {
+	townMigrateCmd.Flags().StringVar(&townMigrateTo, "to", "", "Destination, [user@]host:path (path relative to the remote home unless absolute)")
+	townMigrateCmd.Flags().BoolVar(&townMigrateForce, "force", false, "Migrate even if polecats or unpushed crew work would be lost")
+	townMigrateCmd.Flags().BoolVarP(&townMigrateDryRun, "dry-run", "n", false, "Check the town and show what would be done")
+	_ = townMigrateCmd.MarkFlagRequired("to")
+
+	townCmd.AddCommand(townMigrateCmd)
+	townCmd.AddCommand(townReceiveCmd)
+}
+
+// townMigrateResult is what gt town receive prints.
+type townMigrateResult struct {
+	Town     string   `json:"town"`
+	Root     string   `json:"root"`
+	Rigs     []string `json:"rigs"`
+	Crew     []string `json:"crew,omitempty"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+// parseMigrateTarget splits [user@]host:path. A path starting with "~/"
+// is made relative, since ssh commands start in the remote home.
+func parseMigrateTarget(s string) (host, path string, err error) {
+	host, path, ok := strings.Cut(s, ":")
+	path = strings.TrimPrefix(path, "~/")
+	if !ok || host == "" || strings.HasSuffix(host, "@") || path == "" || path == "~" {
+		return "", "", fmt.Errorf("invalid destination %q: want [user@]host:path", s)
+	}
+	return host, path, nil
+}
+
+func runTownMigrate(cmd *cobra.Command, args []string) error {
+	host, path, err := parseMigrateTarget(townMigrateTo)
+	if err != nil {
+		return err
+	}
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	town, err := workspace.GetTownName(townRoot)
+	if err != nil {
+		town = filepath.Base(townRoot)
+	}
+
+	problems := migratePreflight(townRoot)
+	for _, p := range problems {
+		fmt.Printf("%s %s\n", style.Warning.Render("⚠"), p)
+	}
+	if len(problems) > 0 && !townMigrateForce && !townMigrateDryRun {
+		return fmt.Errorf("%d workspace(s) hold work that would not move (use --force to migrate anyway)", len(problems))
+	}
+
+	if townMigrateDryRun {
+		fmt.Printf("Would migrate %s to %s:%s\n", town, host, path)
+		fmt.Printf("  1. Back up town state (gt backup create)\n")
+		fmt.Printf("  2. Stream it to %s and restore it at %s\n", host, path)
+		fmt.Printf("  3. Clone rigs and recreate checkouts there, then validate\n")
+		return nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gt-migrate-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	archive := filepath.Join(tmpDir, town+".tar.gz")
+
+	fmt.Printf("%s Backing up %s...\n", style.Bold.Render("📦"), town)
+	m, err := backup.CreateFile(townRoot, archive, backup.Options{Town: town})
+	if err != nil {
+		return fmt.Errorf("backing up town: %w", err)
+	}
+	fmt.Printf("  %s\n", backupSummary(m))
+
+	fmt.Printf("%s Restoring on %s (cloning rigs may take a while)...\n", style.Bold.Render("🚚"), host)
+	res, err := sendTownMigration(host, path, archive)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Migrated %s to %s:%s\n", style.Bold.Render("✓"), town, host, res.Root)
+	if len(res.Rigs) > 0 {
+		fmt.Printf("  Rigs: %s\n", strings.Join(res.Rigs, ", "))
+	}
+	if len(res.Crew) > 0 {
+		fmt.Printf("  Crew: %s\n", strings.Join(res.Crew, ", "))
+	}
+	if len(res.Problems) > 0 {
+		for _, p := range res.Problems {
+			fmt.Printf("  %s %s\n", style.Error.Render("✗"), p)
+		}
+		return fmt.Errorf("migrated town failed validation (%d problem(s))", len(res.Problems))
+	}
+	fmt.Println()
+	fmt.Println("This town is untouched. Next:")
+	fmt.Println("  1. gt down (here), if it is still running")
+	fmt.Printf("  2. ssh %s, cd %s, gt doctor && gt up\n", host, res.Root)
+	return nil
+}
+
+// sendTownMigration streams archive to gt town receive on host. Tests
+// replace it.
+var sendTownMigration = func(host, path, archive string) (*townMigrateResult, error) {
+	f, err := os.Open(archive) //nolint:gosec // G304: archive is our temp file
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cmd := connection.NewSSH(host, "").Command("", remoteGT(), "town", "receive", path)
+	cmd.Stdin = f
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("restoring on %s: %w", host, err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	var res townMigrateResult
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &res); err != nil {
+		return nil, fmt.Errorf("parsing migration result from %s: %w", host, err)
+	}
+	return &res, nil
+}
+
+// migratePreflight lists workspaces holding work that can't move: polecat
+// worktrees, whose branches live only in this machine's bare repos, and
+// crew workspaces with uncommitted, stashed, or unpushed work.
+func migratePreflight(townRoot string) []string {
+	var problems []string
+	for _, name := range migrateRigNames(townRoot) {
+		rigPath := filepath.Join(townRoot, name)
+		for _, p := range subdirs(filepath.Join(rigPath, "polecats")) {
+			problems = append(problems, fmt.Sprintf("%s/polecats/%s: polecat worktrees don't move; finish or nuke it (gt polecat nuke %s/%s)", name, p, name, p))
+		}
+		for _, c := range subdirs(filepath.Join(rigPath, "crew")) {
+			dir := filepath.Join(rigPath, "crew", c)
+			if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+				continue
+			}
+			st, err := git.NewGit(dir).CheckUncommittedWork()
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s/crew/%s: %v", name, c, err))
+				continue
+			}
+			if len(st.ModifiedFiles) > 0 || st.StashCount > 0 || st.UnpushedCommits > 0 {
+				problems = append(problems, fmt.Sprintf("%s/crew/%s: %s; commit and push it first", name, c, st))
+			}
+		}
+	}
+	return problems
+}
+
+// migrateRigNames returns the town's registered rigs, sorted.
+func migrateRigNames(townRoot string) []string {
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for name := range rigsConfig.Rigs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// subdirs returns the names of the directories in dir.
+func subdirs(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+func runTownReceive(cmd *cobra.Command, args []string) error {
+	dir, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "gt-migrate-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, os.Stdin); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("receiving backup: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	m, err := backup.Restore(tmp.Name(), dir, false)
+	if err != nil {
+		return fmt.Errorf("restoring town: %w", err)
+	}
+	// Progress goes to stderr; stdout carries the result
+	fmt.Fprintf(os.Stderr, "%s Restored %s to %s\n", style.Bold.Render("✓"), m.Town, dir)
+
+	res := receiveTown(dir, m.Town)
+	return json.NewEncoder(os.Stdout).Encode(res)
+}
+
+// receiveTown recreates the checkouts of a town restored at townRoot and
+// validates it.
+func receiveTown(townRoot, town string) *townMigrateResult {
+	res := &townMigrateResult{Town: town, Root: townRoot}
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		res.Problems = append(res.Problems, fmt.Sprintf("loading rigs: %v", err))
+		return res
+	}
+	mgr := rig.NewManager(townRoot, rigsConfig, git.NewGit(townRoot))
+
+	for _, name := range migrateRigNames(townRoot) {
+		fmt.Fprintf(os.Stderr, "  Cloning %s...\n", name)
+		if err := mgr.RestoreClones(name); err != nil {
+			res.Problems = append(res.Problems, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		res.Rigs = append(res.Rigs, name)
+
+		r, err := mgr.GetRig(name)
+		if err != nil {
+			continue
+		}
+		for _, c := range subdirs(filepath.Join(r.Path, "crew")) {
+			if err := restoreCrewClone(r, c); err != nil {
+				res.Problems = append(res.Problems, fmt.Sprintf("%s/crew/%s: %v", name, c, err))
+				continue
+			}
+			res.Crew = append(res.Crew, name+"/"+c)
+		}
+	}
+
+	res.Problems = append(res.Problems, validateMigratedTown(townRoot)...)
+	return res
+}
+
+// restoreCrewClone clones a crew workspace again around its restored state
+// and points its state at the new location.
+func restoreCrewClone(r *rig.Rig, name string) error {
+	dir := filepath.Join(r.Path, "crew", name)
+	statePath := filepath.Join(dir, "state.json")
+	var state crew.CrewWorker
+	if data, err := os.ReadFile(statePath); err == nil { //nolint:gosec // G304: path is within the town
+		_ = json.Unmarshal(data, &state)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		if err := rig.CloneInto(dir, func(dest string) error {
+			return git.NewGit(r.Path).Clone(r.GitURL, dest)
+		}); err != nil {
+			return err
+		}
+		if state.Branch != "" && state.Branch != r.DefaultBranch() {
+			// Crew branches that were pushed come back; others start over
+			// from the default branch.
+			if err := git.NewGit(dir).Checkout(state.Branch); err != nil {
+				fmt.Fprintf(os.Stderr, "  Warning: %s/crew/%s: branch %s not on origin, staying on %s\n", r.Name, name, state.Branch, r.DefaultBranch())
+				state.Branch = r.DefaultBranch()
+			}
+		}
+		if err := beads.SetupRedirect(filepath.Dir(r.Path), dir); err != nil {
+			fmt.Fprintf(os.Stderr, "  Warning: %s/crew/%s: could not set up beads redirect: %v\n", r.Name, name, err)
+		}
+	}
+
+	if state.Name != "" && state.ClonePath != dir {
+		state.ClonePath = dir
+		if err := util.AtomicWriteJSON(statePath, state); err != nil {
+			return fmt.Errorf("updating state: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateMigratedTown checks that a migrated town is whole: it is a
+// workspace, and each rig has its checkouts and reachable beads.
+func validateMigratedTown(townRoot string) []string {
+	var problems []string
+	if root, err := workspace.Find(townRoot); err != nil || root != townRoot {
+		problems = append(problems, fmt.Sprintf("%s is not a town root", townRoot))
+	}
+	if _, err := os.Stat(filepath.Join(townRoot, ".beads")); err != nil {
+		problems = append(problems, "town beads are missing")
+	}
+	for _, name := range migrateRigNames(townRoot) {
+		rigPath := filepath.Join(townRoot, name)
+		checks := []struct{ path, what string }{
+			{filepath.Join(rigPath, ".repo.git"), "shared bare repo"},
+			{filepath.Join(rigPath, "mayor", "rig", ".git"), "mayor clone"},
+			{filepath.Join(rigPath, "refinery", "rig", ".git"), "refinery worktree"},
+			{beads.ResolveBeadsDir(rigPath), "beads"},
+		}
+		for _, c := range checks {
+			if _, err := os.Stat(c.path); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s missing", name, c.what))
+			}
+		}
+		for _, c := range subdirs(filepath.Join(rigPath, "crew")) {
+			if _, err := os.Stat(filepath.Join(rigPath, "crew", c, ".git")); err != nil {
+				problems = append(problems, fmt.Sprintf("%s/crew/%s: clone missing", name, c))
+			}
+		}
+	}
+	return problems
+}
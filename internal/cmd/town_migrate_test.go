@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseMigrateTarget(t *testing.T) {
+	tests := []struct {
+		input, host, path string
+		wantErr           bool
+	}{
+		{input: "me@newbox:/srv/gt", host: "me@newbox", path: "/srv/gt"},
+		{input: "newbox:~/gt", host: "newbox", path: "gt"},
+		{input: "newbox:gt", host: "newbox", path: "gt"},
+		{input: "newbox", wantErr: true},
+		{input: "newbox:", wantErr: true},
+		{input: "me@:gt", wantErr: true},
+	}
+	for _, tt := range tests {
+		host, path, err := parseMigrateTarget(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseMigrateTarget(%q) error = %v", tt.input, err)
+			continue
+		}
+		if host != tt.host || path != tt.path {
+			t.Errorf("parseMigrateTarget(%q) = %q, %q", tt.input, host, path)
+		}
+	}
+}
+
+// writeMigrateTown writes a town's configs with one rig, gastown, whose
+// repo is gitURL.
+func writeMigrateTown(t *testing.T, townRoot, gitURL string) {
+	t.Helper()
+	rigs, _ := json.Marshal(map[string]any{"version": 1, "rigs": map[string]any{"gastown": map[string]any{"git_url": gitURL}}})
+	files := map[string]string{
+		"mayor/town.json":                   `{"type": "town", "name": "test"}`,
+		"mayor/rigs.json":                   string(rigs),
+		".beads/beads.db":                   "town db",
+		"gastown/config.json":               `{"type": "rig", "name": "gastown", "git_url": "` + gitURL + `", "default_branch": "main"}`,
+		"gastown/.beads/redirect":           "mayor/rig/.beads",
+		"gastown/mayor/rig/.beads/beads.db": "rig db",
+		"gastown/crew/max/state.json":       `{"name": "max", "rig": "gastown", "clone_path": "/old/host/gastown/crew/max", "branch": "main"}`,
+	}
+	for name, content := range files {
+		p := filepath.Join(townRoot, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMigratePreflight(t *testing.T) {
+	townRoot := t.TempDir()
+	writeMigrateTown(t, townRoot, "https://example.com/repo.git")
+	if problems := migratePreflight(townRoot); len(problems) != 0 {
+		t.Errorf("clean town: %v", problems)
+	}
+
+	if err := os.MkdirAll(filepath.Join(townRoot, "gastown", "polecats", "Toast", "rig"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	problems := migratePreflight(townRoot)
+	if len(problems) != 1 || !strings.Contains(problems[0], "gt polecat nuke gastown/Toast") {
+		t.Errorf("with polecat: %v", problems)
+	}
+}
+
+func TestReceiveTownRecreatesCheckouts(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	upstream := filepath.Join(t.TempDir(), "upstream")
+	if err := os.MkdirAll(upstream, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"git", "init", "--initial-branch=main"},
+		{"git", "config", "user.email", "test@test.com"},
+		{"git", "config", "user.name", "Test User"},
+		{"git", "commit", "--allow-empty", "-m", "Initial commit"},
+	} {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = upstream
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v: %v\n%s", args, err, out)
+		}
+	}
+
+	// A town as gt backup restore leaves it: state, no checkouts
+	townRoot := t.TempDir()
+	writeMigrateTown(t, townRoot, upstream)
+	if problems := validateMigratedTown(townRoot); len(problems) == 0 {
+		t.Error("restored town without checkouts validated")
+	}
+
+	res := receiveTown(townRoot, "test")
+	if len(res.Problems) != 0 {
+		t.Fatalf("problems: %v", res.Problems)
+	}
+	if strings.Join(res.Rigs, ",") != "gastown" || strings.Join(res.Crew, ",") != "gastown/max" {
+		t.Errorf("result = %+v", res)
+	}
+
+	// Restored state survives re-cloning around it
+	if data, err := os.ReadFile(filepath.Join(townRoot, "gastown", "mayor", "rig", ".beads", "beads.db")); err != nil || string(data) != "rig db" {
+		t.Errorf("rig beads = %q, %v", data, err)
+	}
+	var state struct {
+		ClonePath string `json:"clone_path"`
+	}
+	data, _ := os.ReadFile(filepath.Join(townRoot, "gastown", "crew", "max", "state.json"))
+	if err := json.Unmarshal(data, &state); err != nil || state.ClonePath != filepath.Join(townRoot, "gastown", "crew", "max") {
+		t.Errorf("crew clone path = %q, %v", state.ClonePath, err)
+	}
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/transcript"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	transcriptsAgent   string
+	transcriptsSince   string
+	transcriptsUntil   string
+	transcriptsKinds   []string
+	transcriptsRegex   bool
+	transcriptsLimit   int
+	transcriptsJSON    bool
+	transcriptsRebuild bool
+)
+
+var transcriptsCmd = &cobra.Command{
+	Use:     "transcripts",
+	GroupID: GroupDiag,
+	Short:   "Search agent session transcripts",
+	Long: `Index and search the transcripts of agent sessions.
+
+Agent CLIs that keep JSON session logs (presets with a usage_log format:
+claude and codex) record every prompt, reply, reasoning step, tool call, and
+tool output. gt transcripts extracts their text into an index under
+.runtime/transcripts, attributed to the agent whose directory each session
+ran in, so you can find when an agent saw or decided something without
+scrolling tmux scrollback.
+
+The index is refreshed incrementally before every search.`,
+	RunE: requireSubcommand,
+}
+
+var transcriptsSearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search indexed transcripts",
+	Long: `Search agent transcripts for a term (case-insensitive).
+
+Matches are listed oldest first with the agent, session, entry kind, and the
+text around the match. Kinds: user, assistant, thinking, tool_call,
+tool_result.
+
+--agent takes a full address (gastown/polecats/toast), a glob
+(gastown/polecats/*), or a bare name (toast).
+
+Examples:
+  gt transcripts search "force push"
+  gt transcripts search "rm -rf" --agent toast --since 1d
+  gt transcripts search 'git (reset|push) --force' --regex --kind tool_call
+  gt transcripts search "skip the tests" --agent 'gastown/polecats/*' --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTranscriptsSearch,
+}
+
+var transcriptsIndexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Update the transcript index",
+	Long: `Update the town's transcript index from agent session logs.
+
+Only logs changed since they were last indexed are read; --rebuild reads
+them all again. Searching updates the index automatically, so this is
+only needed to warm it up or repair it.`,
+	Args: cobra.NoArgs,
+	RunE: runTranscriptsIndex,
+}
+
+func init() {
+	transcriptsSearchCmd.Flags().StringVar(&transcriptsAgent, "agent", "", "Only this agent (address, glob, or name)")
+	transcriptsSearchCmd.Flags().StringVar(&transcriptsSince, "since", "", "Start time: duration ago (1h, 7d), RFC3339, or local date")
+	transcriptsSearchCmd.Flags().StringVar(&transcriptsUntil, "until", "", "End time: duration ago, RFC3339, or local date")
+	transcriptsSearchCmd.Flags().StringSliceVar(&transcriptsKinds, "kind", nil, "Only these entry kinds (user, assistant, thinking, tool_call, tool_result)")
+	transcriptsSearchCmd.Flags().BoolVar(&transcriptsRegex, "regex", false, "Treat the query as a regular expression")
+	transcriptsSearchCmd.Flags().IntVarP(&transcriptsLimit, "limit", "n", 50, "Show at most this many of the most recent matches (0 for all)")
+	transcriptsSearchCmd.Flags().BoolVar(&transcriptsJSON, "json", false, "Output as JSON")
+
+	transcriptsIndexCmd.Flags().BoolVar(&transcriptsRebuild, "rebuild", false, "Re-read every session log")
+
+	transcriptsCmd.AddCommand(transcriptsSearchCmd)
+	transcriptsCmd.AddCommand(transcriptsIndexCmd)
+	rootCmd.AddCommand(transcriptsCmd)
+}
+
+func runTranscriptsSearch(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	q := transcript.Query{Text: args[0], Regex: transcriptsRegex, Agent: transcriptsAgent, Limit: transcriptsLimit}
+	for _, k := range transcriptsKinds {
+		switch k {
+		case transcript.KindUser, transcript.KindAssistant, transcript.KindThinking, transcript.KindToolCall, transcript.KindToolResult:
+			q.Kinds = append(q.Kinds, k)
+		default:
+			return fmt.Errorf("invalid --kind %q: must be user, assistant, thinking, tool_call, or tool_result", k)
+		}
+	}
+	now := time.Now()
+	if transcriptsSince != "" {
+		if q.Since, err = parseLogTime(transcriptsSince, now); err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if transcriptsUntil != "" {
+		if q.Until, err = parseLogTime(transcriptsUntil, now); err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+	re, err := q.Pattern()
+	if err != nil {
+		return err
+	}
+
+	if _, err := transcript.Update(townRoot, config.UsageLogFormats(), false); err != nil {
+		style.PrintWarning("could not update transcript index: %v", err)
+	}
+	matches, err := transcript.Search(townRoot, q)
+	if err != nil {
+		return fmt.Errorf("searching transcripts: %w", err)
+	}
+
+	if transcriptsJSON {
+		if matches == nil {
+			matches = []transcript.Match{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(matches)
+	}
+
+	if len(matches) == 0 {
+		fmt.Printf("%s No transcript entries match %q\n", style.Dim.Render("○"), args[0])
+		return nil
+	}
+	session := ""
+	for _, m := range matches {
+		if m.SessionID != session {
+			session = m.SessionID
+			fmt.Printf("\n%s %s\n", style.Bold.Render(m.Agent), style.Dim.Render("session "+m.SessionID))
+		}
+		fmt.Printf("  %s  %-11s %s\n", m.Time.Local().Format("2006-01-02 15:04:05"), m.Kind, transcript.Snippet(m.Text, re, 100))
+	}
+	fmt.Println()
+	if transcriptsLimit > 0 && len(matches) == transcriptsLimit {
+		fmt.Printf("%s Showing the %d most recent matches (use -n to see more)\n", style.Dim.Render("○"), len(matches))
+	}
+	return nil
+}
+
+func runTranscriptsIndex(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	formats := config.UsageLogFormats()
+	res, err := transcript.Update(townRoot, formats, transcriptsRebuild)
+	if err != nil {
+		return err
+	}
+	ix, err := transcript.Load(townRoot)
+	if err != nil {
+		return err
+	}
+	sessions, entries := 0, 0
+	for _, s := range ix.Sessions {
+		if s.File != "" {
+			sessions++
+			entries += s.Entries
+		}
+	}
+	fmt.Printf("%s Indexed %d session log(s) (%d unchanged, %d removed)\n", style.Bold.Render("✓"), res.Indexed, res.Unchanged, res.Removed)
+	fmt.Printf("  %d agent session(s), %d entries from %s logs\n", sessions, entries, strings.Join(formats, ", "))
+	return nil
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/usage"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	usageSince string
+	usageUntil string
+	usageRig   string
+	usageBy    string
+	usageJSON  bool
+)
+
+var usageCmd = &cobra.Command{
+	Use:     "usage",
+	GroupID: GroupDiag,
+	Short:   "Report token usage and response latency by rig and agent",
+	Long: `Report the tokens agents spent and how long their model responses took.
+
+Usage is read from the session logs agent CLIs write with token metadata
+(presets with a usage_log format: claude and codex). Each session is
+attributed to the agent whose directory it ran in, and totalled per agent,
+role, and day.
+
+Columns:
+  TURNS    model responses
+  INPUT    uncached input tokens
+  OUTPUT   output tokens
+  CACHE    cache reads + cache writes
+  TOTAL    all tokens processed
+  LATENCY  mean (and max) time from prompt or tool result to response
+
+Examples:
+  gt usage                   # Last 7 days, by rig and by agent
+  gt usage --since 1d        # Since yesterday
+  gt usage --by polecat      # Polecats only, one row each
+  gt usage --by day --rig gastown
+  gt usage --json            # Per agent per day, for scripts`,
+	RunE: runUsage,
+}
+
+func init() {
+	usageCmd.Flags().StringVar(&usageSince, "since", "7d", "Start time: duration ago (1h, 7d), RFC3339, or local date")
+	usageCmd.Flags().StringVar(&usageUntil, "until", "", "End time: duration ago, RFC3339, or local date")
+	usageCmd.Flags().StringVar(&usageRig, "rig", "", "Only agents of this rig")
+	usageCmd.Flags().StringVar(&usageBy, "by", "", "Group by: rig, agent, role, polecat, or day (default: rig and agent)")
+	usageCmd.Flags().BoolVar(&usageJSON, "json", false, "Output per-agent daily entries as JSON")
+
+	rootCmd.AddCommand(usageCmd)
+}
+
+// usageRow is one line of a usage report.
+type usageRow struct {
+	Name    string
+	Turns   int
+	Tokens  usage.Tokens
+	Latency usage.Latency
+}
+
+func runUsage(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	switch usageBy {
+	case "", "rig", "agent", "role", "polecat", "day":
+	default:
+		return fmt.Errorf("invalid --by %q: must be rig, agent, role, polecat, or day", usageBy)
+	}
+
+	now := time.Now()
+	since, err := parseLogTime(usageSince, now)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	var until time.Time
+	if usageUntil != "" {
+		if until, err = parseLogTime(usageUntil, now); err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+
+	sessions, err := usage.Sessions(townRoot, config.UsageLogFormats(), since)
+	if err != nil {
+		return fmt.Errorf("reading session logs: %w", err)
+	}
+	entries := filterUsageEntries(usage.Accumulate(townRoot, sessions, since, until), usageRig, usageBy)
+
+	if usageJSON {
+		if entries == nil {
+			entries = []usage.Entry{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("%s No agent usage recorded since %s\n", style.Dim.Render("○"), since.Format("2006-01-02 15:04"))
+		return nil
+	}
+
+	fmt.Printf("\n%s Agent Usage since %s\n", style.Bold.Render("📊"), since.Format("2006-01-02 15:04"))
+	total := groupUsage(entries, func(usage.Entry) string { return "Total" })
+	switch usageBy {
+	case "":
+		printUsageTable("By Rig", groupUsage(entries, usageRigName))
+		printUsageTable("By Agent", groupUsage(entries, func(e usage.Entry) string { return e.Address }))
+	case "rig":
+		printUsageTable("By Rig", groupUsage(entries, usageRigName))
+	case "agent", "polecat":
+		printUsageTable("By Agent", groupUsage(entries, func(e usage.Entry) string { return e.Address }))
+	case "role":
+		printUsageTable("By Role", groupUsage(entries, func(e usage.Entry) string { return e.Role }))
+	case "day":
+		rows := groupUsage(entries, func(e usage.Entry) string { return e.Day })
+		sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+		printUsageTable("By Day", rows)
+	}
+	fmt.Println()
+	printUsageRow(total[0], true)
+	return nil
+}
+
+// filterUsageEntries keeps the entries of one rig, and only polecats when
+// grouping by polecat.
+func filterUsageEntries(entries []usage.Entry, rig, by string) []usage.Entry {
+	var result []usage.Entry
+	for _, e := range entries {
+		if rig != "" && e.Rig != rig {
+			continue
+		}
+		if by == "polecat" && e.Role != "polecat" {
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+func usageRigName(e usage.Entry) string {
+	if e.Rig == "" {
+		return "(town)"
+	}
+	return e.Rig
+}
+
+// groupUsage totals entries by key, largest total first.
+func groupUsage(entries []usage.Entry, key func(usage.Entry) string) []usageRow {
+	index := make(map[string]int)
+	var rows []usageRow
+	for _, e := range entries {
+		k := key(e)
+		i, ok := index[k]
+		if !ok {
+			i = len(rows)
+			index[k] = i
+			rows = append(rows, usageRow{Name: k})
+		}
+		rows[i].Turns += e.Turns
+		rows[i].Tokens.Add(e.Tokens)
+		rows[i].Latency.Merge(e.Latency)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Tokens.Total() > rows[j].Tokens.Total() })
+	return rows
+}
+
+func printUsageTable(title string, rows []usageRow) {
+	fmt.Printf("\n%s\n", style.Bold.Render(title+":"))
+	fmt.Printf("  %-28s %7s %9s %9s %9s %9s  %s\n", "", "TURNS", "INPUT", "OUTPUT", "CACHE", "TOTAL", "LATENCY")
+	for _, row := range rows {
+		printUsageRow(row, false)
+	}
+}
+
+func printUsageRow(row usageRow, bold bool) {
+	latency := style.Dim.Render("-")
+	if row.Latency.Count > 0 {
+		latency = fmt.Sprintf("%s (max %s)", formatLatency(row.Latency.Mean()), formatLatency(row.Latency.Max))
+	}
+	name := fmt.Sprintf("%-28s", row.Name)
+	if bold {
+		name = style.Bold.Render(name)
+	}
+	fmt.Printf("  %s %7d %9s %9s %9s %9s  %s\n", name, row.Turns,
+		formatTokens(row.Tokens.Input), formatTokens(row.Tokens.Output),
+		formatTokens(row.Tokens.CacheRead+row.Tokens.CacheWrite), formatTokens(row.Tokens.Total()), latency)
+}
+
+// formatTokens abbreviates a token count: 950, 12.3k, 4.56M.
+func formatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000_000:
+		return fmt.Sprintf("%.2fB", float64(n)/1e9)
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.2fM", float64(n)/1e6)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// formatLatency formats a response latency to a tenth of a second.
+func formatLatency(d time.Duration) string {
+	if d >= time.Minute {
+		return formatDuration(d)
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
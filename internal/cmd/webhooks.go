@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/daemon"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/webhook"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var webhooksJSON bool
+
+var webhooksCmd = &cobra.Command{
+	Use:     "webhooks",
+	GroupID: GroupServices,
+	Short:   "Show the inbound webhook receiver and what it has done",
+	Long: `Show the daemon's inbound webhook receiver: its rules and recent deliveries.
+
+The receiver turns events from outside the town - issue tracker webhooks,
+monitoring alerts - into beads, and can sling them to a rig so a polecat
+starts on them. It is configured under "webhooks" in mayor/daemon.json:
+
+  {
+    "webhooks": {
+      "enabled": true,
+      "listen": "127.0.0.1:9465",
+      "token": "${GT_WEBHOOK_TOKEN}",
+      "dedup": "6h",
+      "rules": [
+        {"name": "prod-pages", "source": "alertmanager",
+         "match": {"status": "^firing$", "commonLabels.severity": "^(critical|page)$"},
+         "rig": "gastown", "sling": true, "type": "bug", "priority": 0,
+         "title": "[alert] {{.commonLabels.alertname}}",
+         "description": "{{.commonAnnotations.summary}}",
+         "key": "{{.groupKey}}", "labels": ["alert"]},
+        {"name": "tracker-bugs", "source": "tracker",
+         "match": {"action": "^opened$", "issue.labels.0.name": "^bug$"},
+         "rig": "gastown",
+         "title": "{{.issue.title}}",
+         "description": "{{.issue.html_url}}\n\n{{.issue.body}}"}
+      ]
+    }
+  }
+
+Senders POST JSON to http://<listen>/hooks/<source> with the token as a
+bearer token, an X-Gastown-Token header, or a ?token= query parameter.
+
+Rules are tried in order; the first whose source and match patterns fit
+creates a bead. Match keys and the field template function take dotted
+paths into the payload, with numbers indexing arrays:
+  {{field . "alerts.0.labels.alertname"}}
+An event whose key (default: its title) was seen within the dedup window
+maps to the bead already created instead of creating another.
+
+GitHub webhooks get a bridge of their own. With "github" set, requests to
+/hooks/github signed with its secret (X-Hub-Signature-256) are accepted
+without the token, and pull requests opened, reviews requested, and CI
+failing on the default branch become mayor callbacks (GH_PR_OPENED,
+GH_REVIEW_REQUESTED, GH_CI_FAILED) for gt callbacks process to route:
+
+  "github": {"secret": "${GT_GITHUB_WEBHOOK_SECRET}",
+             "repos": ["acme/widgets"]}
+
+Point the repository's webhook at http://<host>/hooks/github with content
+type application/json and the pull_request, workflow_run, and check_suite
+events.
+
+Use 'gt webhooks test <source> <payload.json>' to check rules against a
+sample payload without creating anything.`,
+	RunE: runWebhooks,
+}
+
+var webhooksTestCmd = &cobra.Command{
+	Use:   "test <source> [payload-file]",
+	Short: "Show which rule matches a payload, without creating a bead",
+	Long: `Route a sample JSON payload through the webhook rules as if it had been
+POSTed to /hooks/<source>, and show the bead it would create. Reads the
+payload from stdin if no file is given.
+
+Examples:
+  gt webhooks test alertmanager alert.json
+  curl -s https://example.com/sample | gt webhooks test tracker`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runWebhooksTest,
+}
+
+func init() {
+	webhooksCmd.Flags().BoolVar(&webhooksJSON, "json", false, "Output as JSON")
+	webhooksCmd.AddCommand(webhooksTestCmd)
+	rootCmd.AddCommand(webhooksCmd)
+}
+
+func loadWebhooksConfig(townRoot string) webhook.Config {
+	if cfg := daemon.LoadPatrolConfig(townRoot); cfg != nil && cfg.Webhooks != nil {
+		return *cfg.Webhooks
+	}
+	return webhook.Config{}
+}
+
+func runWebhooks(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg := loadWebhooksConfig(townRoot)
+	if _, err := cfg.Compile(); err != nil {
+		style.PrintWarning("invalid webhooks config: %v", err)
+	}
+	state, err := webhook.LoadState(townRoot)
+	if err != nil {
+		return fmt.Errorf("reading webhook state: %w", err)
+	}
+	listen := cfg.Listen
+	if listen == "" {
+		listen = webhook.DefaultListen
+	}
+
+	if webhooksJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Enabled bool           `json:"enabled"`
+			Listen  string         `json:"listen"`
+			Rules   []webhook.Rule `json:"rules"`
+			State   *webhook.State `json:"state"`
+		}{cfg.Enabled, listen, cfg.Rules, state})
+	}
+
+	if len(cfg.Rules) == 0 && cfg.GitHub == nil {
+		fmt.Printf("%s No webhook rules configured (see gt webhooks --help)\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	status := style.Success.Render("enabled")
+	if !cfg.Enabled {
+		status = style.Dim.Render("disabled")
+	}
+	fmt.Printf("\n%s Webhook Receiver  %s\n", style.Bold.Render("🪝"), status)
+	fmt.Printf("   http://%s/hooks/<source>\n\n", listen)
+
+	if gh := cfg.GitHub; gh != nil {
+		repos := "any repository"
+		if len(gh.Repos) > 0 {
+			repos = strings.Join(gh.Repos, ", ")
+		}
+		auth := "signed"
+		if gh.Secret == "" {
+			auth = style.Warning.Render("no secret: token required")
+		}
+		fmt.Printf("  %s  %s → mayor callbacks (%s)\n", style.Bold.Render("github"), style.Dim.Render(repos), auth)
+	}
+
+	for _, r := range cfg.Rules {
+		name := style.Bold.Render(r.Name)
+		if r.Disabled {
+			name += " " + style.Dim.Render("(disabled)")
+		}
+		source := r.Source
+		if source == "" {
+			source = "any source"
+		}
+		target := "town beads"
+		if r.Rig != "" {
+			target = r.Rig
+		}
+		if r.Sling {
+			target += ", slung"
+		}
+		fmt.Printf("  %s  %s → %s\n", name, style.Dim.Render(source), target)
+		if len(r.Match) > 0 {
+			paths := make([]string, 0, len(r.Match))
+			for path := range r.Match {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			conds := make([]string, 0, len(paths))
+			for _, path := range paths {
+				conds = append(conds, fmt.Sprintf("%s =~ %s", path, r.Match[path]))
+			}
+			fmt.Printf("     when %s\n", strings.Join(conds, ", "))
+		}
+	}
+
+	fmt.Printf("\n  Created %d, duplicates %d, callbacks %d, unmatched %d, rejected %d, failed %d\n",
+		state.Created, state.Duplicates, state.Callbacks, state.Unmatched, state.Rejected, state.Failed)
+	if len(state.Recent) > 0 {
+		fmt.Printf("\n%s Recent\n\n", style.Bold.Render("●"))
+		now := time.Now()
+		for i := len(state.Recent) - 1; i >= 0; i-- {
+			d := state.Recent[i]
+			var what string
+			switch {
+			case d.Error != "":
+				what = style.Error.Render(d.Error)
+			case d.Callback != "":
+				what = "callback " + d.Callback
+			case d.Rule == "":
+				what = style.Dim.Render("no rule matched")
+			case d.Duplicate:
+				what = fmt.Sprintf("%s: duplicate of %s", d.Rule, d.Bead)
+			default:
+				what = fmt.Sprintf("%s: created %s", d.Rule, d.Bead)
+				if d.Slung {
+					what += " and slung to " + d.Rig
+				}
+			}
+			fmt.Printf("  %s ago  %s  %s\n", formatDuration(now.Sub(d.Time)), style.Dim.Render(d.Source), what)
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+func runWebhooksTest(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	router, err := loadWebhooksConfig(townRoot).Compile()
+	if err != nil {
+		return fmt.Errorf("invalid webhooks config: %w", err)
+	}
+
+	var data []byte
+	if len(args) > 1 && args[1] != "-" {
+		data, err = os.ReadFile(args[1])
+	} else {
+		data, err = io.ReadAll(io.LimitReader(os.Stdin, webhook.MaxBodyBytes))
+	}
+	if err != nil {
+		return fmt.Errorf("reading payload: %w", err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return fmt.Errorf("payload must be a JSON object: %w", err)
+	}
+
+	m, err := router.Route(args[0], payload)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		fmt.Printf("%s No rule matches this %s payload\n", style.Dim.Render("○"), args[0])
+		return nil
+	}
+
+	target := "town beads"
+	if m.Rig != "" {
+		target = m.Rig
+	}
+	fmt.Printf("%s Rule %s would create in %s:\n\n", style.Bold.Render("✓"), style.Bold.Render(m.Rule), target)
+	fmt.Printf("  Title:    %s\n", m.Title)
+	fmt.Printf("  Type:     %s (P%d)\n", m.Type, m.Priority)
+	if len(m.Labels) > 0 {
+		fmt.Printf("  Labels:   %s\n", strings.Join(m.Labels, ", "))
+	}
+	fmt.Printf("  Key:      %s\n", m.Key)
+	if m.Sling {
+		fmt.Printf("  Sling:    gt sling <bead> %s\n", m.Rig)
+	}
+	if m.Description != "" {
+		fmt.Printf("\n%s\n", m.Description)
+	}
+	return nil
+}
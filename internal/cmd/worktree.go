@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/auditchain"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/git"
@@ -335,6 +336,10 @@ func runWorktreeRemove(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("%s Removed worktree at %s\n", style.Success.Render("✓"), worktreePath)
+	if worktreeRemoveForce {
+		recordAudit(filepath.Dir(targetRigInfo.Path), auditchain.ActionForceRemove, fmt.Sprintf("%s/crew/%s", targetRig, worktreeName),
+			map[string]string{"command": "worktree remove", "path": worktreePath})
+	}
 
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -67,8 +68,18 @@ type AgentPresetInfo struct {
 	// Claude-only feature for seance command.
 	SupportsForkSession bool `json:"supports_fork_session,omitempty"`
 
+	// ReadOnlyArgs are flags that keep the agent from changing anything
+	// (e.g., claude's plan mode). Used by gt seance when questioning a
+	// finished session. Empty if the agent has no such mode.
+	ReadOnlyArgs []string `json:"read_only_args,omitempty"`
+
 	// NonInteractive contains settings for non-interactive mode.
 	NonInteractive *NonInteractiveConfig `json:"non_interactive,omitempty"`
+
+	// UsageLog is the format of the JSON session logs the agent writes with
+	// token usage metadata, read by gt usage: "claude" or "codex".
+	// Empty if the agent doesn't record usage.
+	UsageLog string `json:"usage_log,omitempty"`
 }
 
 // NonInteractiveConfig contains settings for running agents non-interactively.
@@ -108,7 +119,9 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		ResumeStyle:         "flag",
 		SupportsHooks:       true,
 		SupportsForkSession: true,
+		ReadOnlyArgs:        []string{"--permission-mode", "plan"},
 		NonInteractive:      nil, // Claude is native non-interactive
+		UsageLog:            "claude",
 	},
 	AgentGemini: {
 		Name:                AgentGemini,
@@ -139,6 +152,7 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 			Subcommand: "exec",
 			OutputFlag: "--json",
 		},
+		UsageLog: "codex",
 	},
 	AgentCursor: {
 		Name:                AgentCursor,
@@ -300,6 +314,21 @@ func GetAgentPresetByName(name string) *AgentPresetInfo {
 	return globalRegistry.Agents[name]
 }
 
+// GetAgentPresetByCommand returns the preset whose CLI binary is command
+// (a bare name or a path), or nil if there is none.
+func GetAgentPresetByCommand(command string) *AgentPresetInfo {
+	ensureRegistry()
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	base := filepath.Base(command)
+	for _, info := range globalRegistry.Agents {
+		if info.Command == command || info.Command == base {
+			return info
+		}
+	}
+	return nil
+}
+
 // ListAgentPresets returns all known agent preset names.
 func ListAgentPresets() []string {
 	ensureRegistry()
@@ -312,6 +341,24 @@ func ListAgentPresets() []string {
 	return names
 }
 
+// UsageLogFormats returns the distinct usage log formats of all known
+// agent presets, sorted.
+func UsageLogFormats() []string {
+	ensureRegistry()
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	seen := make(map[string]bool)
+	var formats []string
+	for _, info := range globalRegistry.Agents {
+		if info.UsageLog != "" && !seen[info.UsageLog] {
+			seen[info.UsageLog] = true
+			formats = append(formats, info.UsageLog)
+		}
+	}
+	sort.Strings(formats)
+	return formats
+}
+
 // DefaultAgentPreset returns the default agent preset (Claude).
 func DefaultAgentPreset() AgentPreset {
 	return AgentClaude
@@ -363,6 +410,67 @@ func BuildResumeCommand(agentName, sessionID string) string {
 	}
 }
 
+// BuildSeanceCommand builds the argv for questioning a finished session.
+// Agents that can fork a session get a fork, leaving the original as it
+// was; others resume it. Autonomous-mode args are left out and the agent's
+// ReadOnlyArgs added. With a prompt, the agent answers it and exits.
+// Returns nil if sessionID is empty or the agent doesn't support resume.
+func BuildSeanceCommand(agentName, sessionID, prompt string) []string {
+	if sessionID == "" {
+		return nil
+	}
+	info := GetAgentPresetByName(agentName)
+	if info == nil || info.ResumeFlag == "" {
+		return nil
+	}
+
+	argv := []string{info.Command}
+	switch info.ResumeStyle {
+	case "subcommand":
+		// e.g., "codex resume <session_id>", "amp threads continue <id>"
+		argv = append(argv, strings.Fields(info.ResumeFlag)...)
+		argv = append(argv, sessionID)
+	default:
+		if info.SupportsForkSession {
+			argv = append(argv, "--fork-session")
+		}
+		argv = append(argv, info.ResumeFlag, sessionID)
+	}
+	argv = append(argv, info.ReadOnlyArgs...)
+	if prompt != "" {
+		argv = appendPrompt(info, argv, prompt)
+	}
+	return argv
+}
+
+// BuildNonInteractiveCommand builds the argv for running an agent once on a
+// prompt, read-only: it answers on stdout and exits. Returns nil if the
+// agent is unknown.
+func BuildNonInteractiveCommand(agentName, prompt string) []string {
+	info := GetAgentPresetByName(agentName)
+	if info == nil {
+		return nil
+	}
+	argv := []string{info.Command}
+	if info.NonInteractive != nil && info.NonInteractive.Subcommand != "" {
+		argv = append(argv, info.NonInteractive.Subcommand)
+	}
+	argv = append(argv, info.ReadOnlyArgs...)
+	return appendPrompt(info, argv, prompt)
+}
+
+// appendPrompt adds a one-shot prompt to argv the way the agent takes it.
+func appendPrompt(info *AgentPresetInfo, argv []string, prompt string) []string {
+	switch {
+	case info.Name == AgentClaude:
+		return append(argv, "--print", prompt)
+	case info.NonInteractive != nil && info.NonInteractive.PromptFlag != "":
+		return append(argv, info.NonInteractive.PromptFlag, prompt)
+	default:
+		return append(argv, prompt)
+	}
+}
+
 // SupportsSessionResume checks if an agent supports session resumption.
 func SupportsSessionResume(agentName string) bool {
 	info := GetAgentPresetByName(agentName)
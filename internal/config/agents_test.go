@@ -303,6 +303,55 @@ func TestBuildResumeCommand(t *testing.T) {
 	}
 }
 
+func TestBuildSeanceCommand(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		agentName string
+		prompt    string
+		want      []string
+	}{
+		{"claude", "", []string{"claude", "--fork-session", "--resume", "s1", "--permission-mode", "plan"}},
+		{"claude", "where?", []string{"claude", "--fork-session", "--resume", "s1", "--permission-mode", "plan", "--print", "where?"}},
+		{"gemini", "where?", []string{"gemini", "--resume", "s1", "-p", "where?"}},
+		{"amp", "", []string{"amp", "threads", "continue", "s1"}},
+		{"unknown", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.agentName+"/"+tt.prompt, func(t *testing.T) {
+			got := BuildSeanceCommand(tt.agentName, "s1", tt.prompt)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") || (got == nil) != (tt.want == nil) {
+				t.Errorf("BuildSeanceCommand(%s) = %q, want %q", tt.agentName, got, tt.want)
+			}
+		})
+	}
+	if got := BuildSeanceCommand("claude", "", ""); got != nil {
+		t.Errorf("BuildSeanceCommand with no session = %q, want nil", got)
+	}
+}
+
+func TestBuildNonInteractiveCommand(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		agentName string
+		want      []string
+	}{
+		{"claude", []string{"claude", "--permission-mode", "plan", "--print", "plan it"}},
+		{"codex", []string{"codex", "exec", "plan it"}},
+		{"gemini", []string{"gemini", "-p", "plan it"}},
+		{"unknown", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.agentName, func(t *testing.T) {
+			got := BuildNonInteractiveCommand(tt.agentName, "plan it")
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") || (got == nil) != (tt.want == nil) {
+				t.Errorf("BuildNonInteractiveCommand(%s) = %q, want %q", tt.agentName, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSupportsSessionResume(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -382,6 +431,14 @@ func TestGetProcessNames(t *testing.T) {
 	}
 }
 
+func TestUsageLogFormats(t *testing.T) {
+	t.Parallel()
+	got := UsageLogFormats()
+	if strings.Join(got, ",") != "claude,codex" {
+		t.Errorf("UsageLogFormats() = %v, want [claude codex]", got)
+	}
+}
+
 func TestListAgentPresetsMatchesConstants(t *testing.T) {
 	t.Parallel()
 	// Ensure all AgentPreset constants are returned by ListAgentPresets
@@ -79,6 +79,11 @@ func AgentEnv(cfg AgentEnvConfig) map[string]string {
 		env["GT_CREW"] = cfg.AgentName
 		env["BD_ACTOR"] = fmt.Sprintf("%s/crew/%s", cfg.Rig, cfg.AgentName)
 		env["GIT_AUTHOR_NAME"] = cfg.AgentName
+
+	case "observer":
+		env["GT_OBSERVER"] = cfg.AgentName
+		env["BD_ACTOR"] = fmt.Sprintf("observers/%s", cfg.AgentName)
+		env["GIT_AUTHOR_NAME"] = cfg.AgentName
 	}
 
 	// Only set GT_ROOT if provided
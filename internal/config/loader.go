@@ -212,6 +212,31 @@ func validateRigSettings(c *RigSettings) error {
 			return err
 		}
 	}
+	if c.Keepalive != nil {
+		if err := validateKeepaliveConfig(c.Keepalive); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateKeepaliveConfig validates a KeepaliveConfig.
+func validateKeepaliveConfig(c *KeepaliveConfig) error {
+	var fresh, stale time.Duration
+	var err error
+	if c.Fresh != "" {
+		if fresh, err = time.ParseDuration(c.Fresh); err != nil {
+			return fmt.Errorf("invalid keepalive.fresh: %w", err)
+		}
+	}
+	if c.Stale != "" {
+		if stale, err = time.ParseDuration(c.Stale); err != nil {
+			return fmt.Errorf("invalid keepalive.stale: %w", err)
+		}
+	}
+	if fresh > 0 && stale > 0 && stale < fresh {
+		return fmt.Errorf("invalid keepalive: stale (%s) must not be shorter than fresh (%s)", c.Stale, c.Fresh)
+	}
 	return nil
 }
 
@@ -1639,3 +1664,171 @@ func (c *EscalationConfig) GetMaxReescalations() int {
 	}
 	return c.MaxReescalations
 }
+
+// CommandGatingConfigPath returns the standard path for command gating config in a town.
+func CommandGatingConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, "settings", "command-gating.json")
+}
+
+// LoadCommandGatingConfig loads and validates a command gating configuration file.
+func LoadCommandGatingConfig(path string) (*CommandGatingConfig, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally, not from user input
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+		}
+		return nil, fmt.Errorf("reading command gating config: %w", err)
+	}
+
+	var config CommandGatingConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parsing command gating config: %w", err)
+	}
+
+	if err := validateCommandGatingConfig(&config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// LoadOrCreateCommandGatingConfig loads the command gating config, returning
+// the default rules if not found.
+func LoadOrCreateCommandGatingConfig(path string) (*CommandGatingConfig, error) {
+	config, err := LoadCommandGatingConfig(path)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return NewCommandGatingConfig(), nil
+		}
+		return nil, err
+	}
+	return config, nil
+}
+
+// SaveCommandGatingConfig saves a command gating configuration to a file.
+func SaveCommandGatingConfig(path string, config *CommandGatingConfig) error {
+	if err := validateCommandGatingConfig(config); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding command gating config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil { //nolint:gosec // G306: gating config doesn't contain secrets
+		return fmt.Errorf("writing command gating config: %w", err)
+	}
+
+	return nil
+}
+
+// validateCommandGatingConfig validates a CommandGatingConfig.
+func validateCommandGatingConfig(c *CommandGatingConfig) error {
+	if c.Type != "command-gating" && c.Type != "" {
+		return fmt.Errorf("%w: expected type 'command-gating', got '%s'", ErrInvalidType, c.Type)
+	}
+	if c.Version > CurrentCommandGatingVersion {
+		return fmt.Errorf("%w: got %d, max supported %d", ErrInvalidVersion, c.Version, CurrentCommandGatingVersion)
+	}
+
+	switch c.Mode {
+	case "", GateActionWarn, GateActionBlock, GateModeOff:
+	default:
+		return fmt.Errorf("invalid mode '%s' (valid: warn, block, off)", c.Mode)
+	}
+
+	for i, rule := range c.Rules {
+		if strings.TrimSpace(rule.Command) == "" {
+			return fmt.Errorf("%w: rules[%d].command", ErrMissingField, i)
+		}
+		if len(rule.Roles) == 0 {
+			return fmt.Errorf("%w: rules[%d].roles", ErrMissingField, i)
+		}
+		if rule.Action != GateActionWarn && rule.Action != GateActionBlock {
+			return fmt.Errorf("rules[%d]: invalid action '%s' (valid: warn, block)", i, rule.Action)
+		}
+	}
+
+	return nil
+}
+
+// RateLimitConfigPath returns the standard path for the rate limit config in a town.
+func RateLimitConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, "settings", "rate-limits.json")
+}
+
+// LoadRateLimitConfig loads and validates a rate limit configuration file.
+func LoadRateLimitConfig(path string) (*RateLimitConfig, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally, not from user input
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+		}
+		return nil, fmt.Errorf("reading rate limit config: %w", err)
+	}
+
+	config := NewRateLimitConfig()
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("parsing rate limit config: %w", err)
+	}
+
+	if err := validateRateLimitConfig(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// LoadOrCreateRateLimitConfig loads the rate limit config, returning the
+// defaults if not found.
+func LoadOrCreateRateLimitConfig(path string) (*RateLimitConfig, error) {
+	config, err := LoadRateLimitConfig(path)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return NewRateLimitConfig(), nil
+		}
+		return nil, err
+	}
+	return config, nil
+}
+
+// validateRateLimitConfig validates a RateLimitConfig.
+func validateRateLimitConfig(c *RateLimitConfig) error {
+	if c.Type != "rate-limits" && c.Type != "" {
+		return fmt.Errorf("%w: expected type 'rate-limits', got '%s'", ErrInvalidType, c.Type)
+	}
+	if c.Version > CurrentRateLimitVersion {
+		return fmt.Errorf("%w: got %d, max supported %d", ErrInvalidVersion, c.Version, CurrentRateLimitVersion)
+	}
+
+	switch c.Mode {
+	case "", GateActionWarn, GateActionBlock, GateModeOff:
+	default:
+		return fmt.Errorf("invalid mode '%s' (valid: warn, block, off)", c.Mode)
+	}
+	if c.Limit < 0 {
+		return fmt.Errorf("invalid limit %d: must be positive", c.Limit)
+	}
+	if c.Window != "" {
+		if d, err := time.ParseDuration(c.Window); err != nil || d <= 0 {
+			return fmt.Errorf("invalid window '%s': must be a positive duration like 10m", c.Window)
+		}
+	}
+	for _, kind := range c.Kinds {
+		switch kind {
+		case RateLimitKindForcePush, RateLimitKindBranchDelete, RateLimitKindRemoteDelete:
+		default:
+			return fmt.Errorf("invalid kind '%s' (valid: force-push, branch-delete, remote-delete)", kind)
+		}
+	}
+	if c.Severity != "" && !IsValidSeverity(c.Severity) {
+		return fmt.Errorf("invalid severity '%s': must be critical, high, medium, or low", c.Severity)
+	}
+
+	return nil
+}
@@ -334,6 +334,24 @@ func TestRigSettingsValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid keepalive duration",
+			settings: &RigSettings{
+				Type:      "rig-settings",
+				Version:   1,
+				Keepalive: &KeepaliveConfig{Fresh: "later"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "keepalive stale shorter than fresh",
+			settings: &RigSettings{
+				Type:      "rig-settings",
+				Version:   1,
+				Keepalive: &KeepaliveConfig{Fresh: "10m", Stale: "5m"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -2603,3 +2621,111 @@ func TestBuildStartupCommandWithAgentOverride_IncludesGTRoot(t *testing.T) {
 		t.Errorf("expected GT_ROOT=%s in command, got: %q", townRoot, cmd)
 	}
 }
+
+func TestCommandGatingConfigRoundTrip(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	path := CommandGatingConfigPath(dir)
+
+	original := NewCommandGatingConfig()
+	original.Mode = GateActionWarn
+	if err := SaveCommandGatingConfig(path, original); err != nil {
+		t.Fatalf("SaveCommandGatingConfig: %v", err)
+	}
+
+	loaded, err := LoadCommandGatingConfig(path)
+	if err != nil {
+		t.Fatalf("LoadCommandGatingConfig: %v", err)
+	}
+	if loaded.Mode != GateActionWarn {
+		t.Errorf("Mode = %q, want %q", loaded.Mode, GateActionWarn)
+	}
+	if len(loaded.Rules) != len(original.Rules) {
+		t.Errorf("Rules count = %d, want %d", len(loaded.Rules), len(original.Rules))
+	}
+}
+
+func TestLoadOrCreateCommandGatingConfig_Missing(t *testing.T) {
+	t.Parallel()
+	cfg, err := LoadOrCreateCommandGatingConfig(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("LoadOrCreateCommandGatingConfig: %v", err)
+	}
+	if cfg.Mode != GateActionBlock {
+		t.Errorf("Mode = %q, want %q", cfg.Mode, GateActionBlock)
+	}
+	if len(cfg.Rules) == 0 {
+		t.Error("expected default rules")
+	}
+}
+
+func TestValidateCommandGatingConfig(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		config  CommandGatingConfig
+		wantErr bool
+	}{
+		{"empty", CommandGatingConfig{}, false},
+		{"bad type", CommandGatingConfig{Type: "wrong"}, true},
+		{"future version", CommandGatingConfig{Version: CurrentCommandGatingVersion + 1}, true},
+		{"bad mode", CommandGatingConfig{Mode: "maybe"}, true},
+		{"missing command", CommandGatingConfig{Rules: []CommandGateRule{{Roles: []string{"polecat"}, Action: GateActionBlock}}}, true},
+		{"missing roles", CommandGatingConfig{Rules: []CommandGateRule{{Command: "rig", Action: GateActionBlock}}}, true},
+		{"bad action", CommandGatingConfig{Rules: []CommandGateRule{{Roles: []string{"*"}, Command: "rig", Action: "deny"}}}, true},
+		{"valid rule", CommandGatingConfig{Rules: []CommandGateRule{{Roles: []string{"*"}, Command: "rig", Action: GateActionWarn}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCommandGatingConfig(&tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateCommandGatingConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadRateLimitConfig_Defaults(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "rate-limits.json")
+	if err := os.WriteFile(path, []byte(`{"type": "rate-limits", "version": 1, "kinds": ["force-push"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadRateLimitConfig(path)
+	if err != nil {
+		t.Fatalf("LoadRateLimitConfig: %v", err)
+	}
+	if cfg.Mode != GateActionBlock || cfg.GetLimit() != 3 || cfg.GetWindow() != 10*time.Minute {
+		t.Errorf("defaults not applied: %+v", cfg)
+	}
+	if !cfg.Counts(RateLimitKindForcePush) || cfg.Counts(RateLimitKindBranchDelete) {
+		t.Errorf("Kinds = %v, want only force-push counted", cfg.Kinds)
+	}
+}
+
+func TestValidateRateLimitConfig(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		config  RateLimitConfig
+		wantErr bool
+	}{
+		{"empty", RateLimitConfig{}, false},
+		{"bad type", RateLimitConfig{Type: "wrong"}, true},
+		{"future version", RateLimitConfig{Version: CurrentRateLimitVersion + 1}, true},
+		{"bad mode", RateLimitConfig{Mode: "maybe"}, true},
+		{"negative limit", RateLimitConfig{Limit: -1}, true},
+		{"bad window", RateLimitConfig{Window: "soon"}, true},
+		{"bad kind", RateLimitConfig{Kinds: []string{"rm-rf"}}, true},
+		{"bad severity", RateLimitConfig{Severity: "dire"}, true},
+		{"valid", RateLimitConfig{Mode: GateActionWarn, Limit: 5, Window: "1h", Kinds: []string{RateLimitKindBranchDelete}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRateLimitConfig(&tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateRateLimitConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
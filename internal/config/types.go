@@ -210,6 +210,15 @@ type RigSettings struct {
 	Crew       *CrewConfig       `json:"crew,omitempty"`        // crew startup settings
 	Workflow   *WorkflowConfig   `json:"workflow,omitempty"`    // workflow settings
 	Runtime    *RuntimeConfig    `json:"runtime,omitempty"`     // LLM runtime settings (deprecated: use Agent)
+	Git        *RigGitConfig     `json:"git,omitempty"`         // git repository handling (LFS, submodules)
+	GitHub     *GitHubConfig     `json:"github,omitempty"`      // GitHub integration (commit statuses)
+	Keepalive  *KeepaliveConfig  `json:"keepalive,omitempty"`   // agent activity freshness thresholds
+	Tracing    *TracingConfig    `json:"tracing,omitempty"`     // OpenTelemetry tracing of merges
+	Review     *ReviewConfig     `json:"review,omitempty"`      // pre-queue review of merge requests
+	Dispatch   *DispatchConfig   `json:"dispatch,omitempty"`    // how polecats get work (push or pull)
+	Approval   *ApprovalConfig   `json:"approval,omitempty"`    // changes that need the overseer's approval
+	Routing    *RoutingConfig    `json:"routing,omitempty"`     // labels for merge requests by the files they change
+	Pacing     *PacingConfig     `json:"pacing,omitempty"`      // when polecats spawn and how fast work is slung
 
 	// Agent selects which agent preset to use for this rig.
 	// Can be a built-in preset ("claude", "gemini", "codex", "cursor", "auggie", "amp")
@@ -231,6 +240,217 @@ type RigSettings struct {
 	RoleAgents map[string]string `json:"role_agents,omitempty"`
 }
 
+// KeepaliveConfig sets when an agent's last activity counts as fresh, stale,
+// or very stale. Raise them for rigs whose test suites or builds legitimately
+// run longer than the defaults.
+type KeepaliveConfig struct {
+	// Fresh is how long after its last activity an agent still counts as
+	// actively working (e.g. "10m"). Default: 2m.
+	Fresh string `json:"fresh,omitempty"`
+
+	// Stale is how long after its last activity a stale agent becomes very
+	// stale and the Witness should nudge it (e.g. "30m"). Default: 5m.
+	Stale string `json:"stale,omitempty"`
+}
+
+// RigGitConfig represents git repository handling settings for a rig.
+type RigGitConfig struct {
+	// LFS configures Git LFS handling. Only consulted when the repo uses LFS.
+	LFS *LFSConfig `json:"lfs,omitempty"`
+
+	// Submodules controls submodule init/update on worktree creation and
+	// before merge-queue test runs. nil (default) means auto: update when the
+	// checkout has a .gitmodules file. Set false to disable, true to force.
+	Submodules *bool `json:"submodules,omitempty"`
+
+	// Auth configures credentials for the rig's remote. Applied when
+	// polecats and dogs fetch and when polecats and the refinery push, so
+	// rigs don't depend on the host's global git config.
+	Auth *GitAuthConfig `json:"auth,omitempty"`
+
+	// LargeRepo enables git performance settings (fsmonitor, untracked
+	// cache, index v4) in the mayor and polecat checkouts. Use for monorepos
+	// where status checks otherwise take seconds.
+	LargeRepo bool `json:"large_repo,omitempty"`
+
+	// Branches restricts which branch names polecats may create and submit
+	// to the merge queue.
+	Branches *BranchPolicyConfig `json:"branches,omitempty"`
+}
+
+// BranchPolicyConfig represents a rig's branch namespace policy.
+// Checked when polecat branches are created and when MRs are submitted.
+type BranchPolicyConfig struct {
+	// Patterns lists allowed branch name patterns; a branch must match at
+	// least one. Placeholders: <name> (agent name), <bead> (issue ID with
+	// optional @suffix), * (one path segment), ** (anything).
+	// Example: ["polecat/<name>/<bead>", "integration/*"]. Empty allows any.
+	Patterns []string `json:"patterns,omitempty"`
+
+	// ProtectedPrefixes lists branch name prefixes agents may never create
+	// or submit (e.g. "release/"). Matched as plain string prefixes.
+	ProtectedPrefixes []string `json:"protected_prefixes,omitempty"`
+}
+
+// GitAuthConfig represents per-rig git credentials.
+// Secrets are never stored here: tokens are read from the environment or
+// from a command (e.g. a secrets manager CLI) at use time. Token and helper
+// credentials are offered only to the host of the rig's git_url.
+type GitAuthConfig struct {
+	// SSHKey is the path to a private key for SSH remotes ("~/" is expanded).
+	SSHKey string `json:"ssh_key,omitempty"`
+
+	// Username is the HTTPS username sent with the token (default "x-access-token").
+	Username string `json:"username,omitempty"`
+
+	// TokenEnv names an environment variable holding an HTTPS token.
+	TokenEnv string `json:"token_env,omitempty"`
+
+	// TokenCommand is a shell command that prints an HTTPS token on stdout,
+	// e.g. "op read op://vault/github/token". Used if TokenEnv is unset or empty.
+	// It runs in the rig directory each time git asks for credentials.
+	TokenCommand string `json:"token_command,omitempty"`
+
+	// CredentialHelper replaces the global credential helpers for the rig's remote,
+	// e.g. "store --file ~/.config/gt/creds" or "!aws codecommit credential-helper $@".
+	CredentialHelper string `json:"credential_helper,omitempty"`
+}
+
+// GitHubConfig represents GitHub integration settings for a rig.
+// When present and a token is available, the Refinery posts commit statuses
+// (queue:tests, queue:merge) to each MR's source branch head.
+type GitHubConfig struct {
+	// Repo is "owner/name". Derived from the rig's git URL if empty.
+	Repo string `json:"repo,omitempty"`
+
+	// TokenEnv names the environment variable holding the API token
+	// (default "GITHUB_TOKEN"). Needs repo:status scope.
+	TokenEnv string `json:"token_env,omitempty"`
+
+	// APIURL is the REST API base URL, for GitHub Enterprise
+	// (default "https://api.github.com").
+	APIURL string `json:"api_url,omitempty"`
+}
+
+// TracingConfig enables OpenTelemetry tracing of the Refinery's merge
+// pipeline. Each merge is exported over OTLP/HTTP as a trace with spans for
+// fetch, conflict check, tests, merge, and push.
+type TracingConfig struct {
+	// Endpoint is the collector's OTLP/HTTP base URL
+	// (e.g. "http://localhost:4318"). Falls back to the standard
+	// OTEL_EXPORTER_OTLP_ENDPOINT environment variable.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// ServiceName is the service.name reported with spans. Falls back to
+	// the OTEL_SERVICE_NAME environment variable, then "gastown-refinery".
+	ServiceName string `json:"service_name,omitempty"`
+
+	// HeadersEnv names an environment variable holding extra export
+	// headers such as API keys, as comma-separated key=value pairs
+	// (default "OTEL_EXPORTER_OTLP_HEADERS").
+	HeadersEnv string `json:"headers_env,omitempty"`
+}
+
+// ReviewConfig enables a review pass on merge requests before they enter the
+// merge queue. Each new MR gets a review bead, slung to Reviewer, and the
+// Refinery holds the MR until the review is approved.
+type ReviewConfig struct {
+	// Enabled turns on pre-queue review for the rig.
+	Enabled bool `json:"enabled"`
+
+	// Reviewer is the sling target for review tasks: an agent such as
+	// "gastown/crew/max", or a rig name to spawn a polecat reviewer.
+	// Default: the rig itself.
+	Reviewer string `json:"reviewer,omitempty"`
+
+	// Checklist replaces the default review checklist.
+	Checklist []string `json:"checklist,omitempty"`
+
+	// Reviewers routes reviews by MR label (see RoutingConfig): an MR with
+	// a listed label is reviewed by that reviewer instead of Reviewer. With
+	// several matching labels, the first in label order wins.
+	Reviewers map[string]string `json:"reviewers,omitempty"`
+}
+
+// RoutingConfig labels merge requests by the files they change, so they
+// can be routed by label (see ReviewConfig.Reviewers).
+type RoutingConfig struct {
+	// PathLabels label each new MR that changes a matching file.
+	PathLabels []PathLabel `json:"path_labels,omitempty"`
+}
+
+// PathLabel adds Label to MRs that change files matching any of Paths
+// (repo path patterns, see git.MatchPath).
+type PathLabel struct {
+	Label string   `json:"label"`
+	Paths []string `json:"paths"`
+}
+
+// ApprovalConfig lists the changes that need the overseer's approval before
+// they merge.
+type ApprovalConfig struct {
+	// ProtectedPaths are repo path patterns (see git.MatchPath, e.g.
+	// "migrations/**", ".github/workflows/*"). An MR touching any of them
+	// waits for gt approve before entering the merge queue.
+	ProtectedPaths []string `json:"protected_paths,omitempty"`
+}
+
+// PacingConfig paces a rig's autonomous work to the people reviewing it.
+type PacingConfig struct {
+	// WorkingHours are the windows in which polecats may be spawned, such as
+	// "weekday 09:00-18:00" (see schedule.ParseWindow). Empty: any time.
+	WorkingHours []string `json:"working_hours,omitempty"`
+
+	// Timezone is the IANA zone of WorkingHours, e.g. "Europe/Berlin"
+	// (default: local).
+	Timezone string `json:"timezone,omitempty"`
+
+	// MaxSlingsPerHour caps the beads slung to (or claimed in) the rig in
+	// any rolling hour. 0: unlimited.
+	MaxSlingsPerHour int `json:"max_slings_per_hour,omitempty"`
+}
+
+// DispatchConfig selects how polecats in a rig get work.
+type DispatchConfig struct {
+	// Mode is "push" (default): work is slung to polecats, or "pull":
+	// polecats with an empty hook claim ready beads themselves with
+	// gt dispatch claim, so work keeps flowing when the Mayor is busy or down.
+	Mode string `json:"mode,omitempty"`
+
+	// ClaimTTL is how long a claim lasts without renewal (e.g. "1h").
+	// A claim that lapses can be taken over by another polecat. Default: 30m.
+	ClaimTTL string `json:"claim_ttl,omitempty"`
+
+	// Capabilities lists what each worker can do, keyed by worker name
+	// ("*" applies to every worker). A bead labelled needs:<capability> is
+	// only claimed by workers with that capability.
+	Capabilities map[string][]string `json:"capabilities,omitempty"`
+}
+
+// CapabilitiesOf returns the capabilities of the named worker: those listed
+// under "*" plus those listed under its name.
+func (c *DispatchConfig) CapabilitiesOf(worker string) []string {
+	if c == nil {
+		return nil
+	}
+	caps := append([]string(nil), c.Capabilities["*"]...)
+	return append(caps, c.Capabilities[worker]...)
+}
+
+// Dispatch modes.
+const (
+	DispatchPush = "push"
+	DispatchPull = "pull"
+)
+
+// LFSConfig represents Git LFS settings for a rig.
+type LFSConfig struct {
+	// SkipSmudge creates polecat worktrees with LFS pointer files instead of
+	// downloading binary assets. Use for rigs where polecats don't need them;
+	// a polecat can still fetch them with "git lfs pull".
+	SkipSmudge bool `json:"skip_smudge,omitempty"`
+}
+
 // CrewConfig represents crew workspace settings for a rig.
 type CrewConfig struct {
 	// Startup is a natural language instruction for which crew to start on boot.
@@ -650,6 +870,12 @@ type MergeQueueConfig struct {
 	// TestCommand is the command to run for tests.
 	TestCommand string `json:"test_command,omitempty"`
 
+	// PreflightCommand is a quick check (lint/build) that `gt done` runs in
+	// the polecat's worktree before submitting to the queue. Catching a broken
+	// build here is cheaper than a Refinery test run. Empty disables it.
+	// Example: "go vet ./..."
+	PreflightCommand string `json:"preflight_command,omitempty"`
+
 	// DeleteMergedBranches controls whether to delete branches after merging.
 	DeleteMergedBranches bool `json:"delete_merged_branches"`
 
@@ -661,6 +887,11 @@ type MergeQueueConfig struct {
 
 	// MaxConcurrent is the maximum number of concurrent merges.
 	MaxConcurrent int `json:"max_concurrent"`
+
+	// WorktreePool runs each merge and test run in its own worktree from a
+	// pool of MaxConcurrent checkouts (refinery/pool/<n>) instead of
+	// serializing everything through refinery/rig.
+	WorktreePool bool `json:"worktree_pool,omitempty"`
 }
 
 // OnConflict strategy constants.
@@ -889,3 +1120,190 @@ func NewEscalationConfig() *EscalationConfig {
 		MaxReescalations: 2,
 	}
 }
+
+// CommandGatingConfig controls role-aware command gating (settings/command-gating.json).
+// Rules warn or block gt commands that are inappropriate for the detected role,
+// containing the blast radius of a confused agent (e.g., a polecat removing a rig).
+type CommandGatingConfig struct {
+	Type    string `json:"type"`    // "command-gating"
+	Version int    `json:"version"` // schema version
+
+	// Mode is the town-wide enforcement mode: "warn", "block", or "off".
+	// In "warn" mode, blocking rules are downgraded to warnings.
+	// Default: "block"
+	Mode string `json:"mode,omitempty"`
+
+	// Rules lists the gating rules. The first matching rule wins.
+	Rules []CommandGateRule `json:"rules"`
+}
+
+// CommandGateRule gates a command (and its subcommands) for a set of roles.
+type CommandGateRule struct {
+	// Roles the rule applies to (e.g., "polecat", "refinery"). "*" matches any role.
+	Roles []string `json:"roles"`
+
+	// Command is the command path prefix without the "gt" prefix
+	// (e.g., "rig remove" matches "gt rig remove" only). A command starting
+	// with "git" (e.g., "git push") gates git commands agents run in their
+	// shell; these are checked by the PreToolUse hook (gt ratelimit check).
+	Command string `json:"command"`
+
+	// Flags restricts the rule to invocations where at least one of these
+	// flags was set (e.g., ["force"]). Empty means always.
+	Flags []string `json:"flags,omitempty"`
+
+	// Branches restricts a "git push" rule to pushes that update one of
+	// these branches (e.g., ["main"]). GateBranchDefault stands for the
+	// rig's default branch, resolved when the rule is evaluated. A push
+	// without a refspec updates the current branch. Empty means any branch.
+	Branches []string `json:"branches,omitempty"`
+
+	// Action is "warn" or "block".
+	Action string `json:"action"`
+
+	// Reason is shown to the agent when the rule fires.
+	Reason string `json:"reason,omitempty"`
+}
+
+// CurrentCommandGatingVersion is the current schema version for CommandGatingConfig.
+const CurrentCommandGatingVersion = 1
+
+// Command gating modes and rule actions.
+const (
+	GateActionWarn  = "warn"
+	GateActionBlock = "block"
+	GateModeOff     = "off"
+)
+
+// GateBranchDefault in a rule's Branches matches the default branch of the
+// rig the command runs in (its default_branch, else "main").
+const GateBranchDefault = "$default"
+
+// polecatRigMutations are the gt rig subcommands that change a rig, which
+// polecats may not run. Read-only ones (list, status, config show, doctor
+// without --fix) stay open to them.
+var polecatRigMutations = []string{
+	"add", "quick-add", "remove", "reset", "config set", "config unset",
+	"boot", "start", "stop", "restart", "reboot", "shutdown",
+	"dock", "undock", "park", "unpark", "gc",
+}
+
+// NewCommandGatingConfig creates a CommandGatingConfig with the default rules.
+func NewCommandGatingConfig() *CommandGatingConfig {
+	agents := []string{"polecat", "crew", "witness", "refinery", "deacon", "boot"}
+	const rigReason = "polecats work inside a rig; rig management belongs to the Mayor or overseer"
+	var rigRules []CommandGateRule
+	for _, sub := range polecatRigMutations {
+		rigRules = append(rigRules, CommandGateRule{Roles: []string{"polecat"}, Command: "rig " + sub,
+			Action: GateActionBlock, Reason: rigReason})
+	}
+	rigRules = append(rigRules, CommandGateRule{Roles: []string{"polecat"}, Command: "rig doctor", Flags: []string{"--fix"},
+		Action: GateActionBlock, Reason: rigReason})
+
+	return &CommandGatingConfig{
+		Type:    "command-gating",
+		Version: CurrentCommandGatingVersion,
+		Mode:    GateActionBlock,
+		Rules: append(rigRules, []CommandGateRule{
+			{Roles: []string{"polecat"}, Command: "polecat nuke", Action: GateActionBlock,
+				Reason: "polecats must not nuke workers; use 'gt done' to finish your own session"},
+			{Roles: []string{"polecat"}, Command: "polecat remove", Action: GateActionBlock,
+				Reason: "polecats must not remove workers; use 'gt done' to finish your own session"},
+			{Roles: agents, Command: "uninstall", Action: GateActionBlock,
+				Reason: "only the overseer may uninstall Gas Town"},
+			{Roles: agents, Command: "install", Action: GateActionBlock,
+				Reason: "only the overseer may install or reinstall Gas Town"},
+			{Roles: []string{"polecat", "crew", "witness", "refinery"}, Command: "down", Action: GateActionBlock,
+				Reason: "stopping the town is an overseer/Mayor decision"},
+			{Roles: []string{"refinery"}, Command: "rig remove", Action: GateActionBlock,
+				Reason: "the Refinery processes the merge queue; it must not remove rigs"},
+			{Roles: []string{"refinery"}, Command: "git push", Flags: []string{"--force", "-f", "--force-with-lease", "--mirror"},
+				Branches: []string{GateBranchDefault}, Action: GateActionBlock,
+				Reason: "the Refinery lands merges with fast-forward pushes; force-pushing the default branch rewrites everyone's history"},
+			{Roles: []string{"crew"}, Command: "rig remove", Action: GateActionWarn,
+				Reason: "removing a rig affects every agent working in it"},
+		}...),
+	}
+}
+
+// RateLimitConfig controls the rate limiter for destructive git commands
+// issued by agents (settings/rate-limits.json). An agent that exceeds Limit
+// destructive commands within Window trips a circuit breaker: its git
+// commands are blocked and the town is escalated to until an overseer resets
+// it, containing a runaway agent before it churns the repo.
+type RateLimitConfig struct {
+	Type    string `json:"type"`    // "rate-limits"
+	Version int    `json:"version"` // schema version
+
+	// Mode is "block", "warn", or "off". In "warn" mode the breaker still
+	// trips and escalates, but commands are not blocked.
+	// Default: "block"
+	Mode string `json:"mode,omitempty"`
+
+	// Limit is how many destructive commands an agent may issue per Window.
+	// Default: 3
+	Limit int `json:"limit,omitempty"`
+
+	// Window is the sliding window the limit applies to (e.g., "10m").
+	// Default: "10m"
+	Window string `json:"window,omitempty"`
+
+	// Kinds lists the destructive command kinds that count toward the limit:
+	// "force-push", "branch-delete", "remote-delete". Empty means all.
+	Kinds []string `json:"kinds,omitempty"`
+
+	// Severity of the escalation filed when the breaker trips.
+	// Default: "high"
+	Severity string `json:"severity,omitempty"`
+}
+
+// CurrentRateLimitVersion is the current schema version for RateLimitConfig.
+const CurrentRateLimitVersion = 1
+
+// Destructive git command kinds counted by the rate limiter.
+const (
+	RateLimitKindForcePush    = "force-push"
+	RateLimitKindBranchDelete = "branch-delete"
+	RateLimitKindRemoteDelete = "remote-delete"
+)
+
+// NewRateLimitConfig creates a RateLimitConfig with the defaults.
+func NewRateLimitConfig() *RateLimitConfig {
+	return &RateLimitConfig{
+		Type:     "rate-limits",
+		Version:  CurrentRateLimitVersion,
+		Mode:     GateActionBlock,
+		Limit:    3,
+		Window:   "10m",
+		Severity: SeverityHigh,
+	}
+}
+
+// GetWindow returns the sliding window as a duration, defaulting to 10m.
+func (c *RateLimitConfig) GetWindow() time.Duration {
+	if d, err := time.ParseDuration(c.Window); err == nil && d > 0 {
+		return d
+	}
+	return 10 * time.Minute
+}
+
+// GetLimit returns the per-window limit, defaulting to 3.
+func (c *RateLimitConfig) GetLimit() int {
+	if c.Limit > 0 {
+		return c.Limit
+	}
+	return 3
+}
+
+// Counts reports whether a destructive command kind counts toward the limit.
+func (c *RateLimitConfig) Counts(kind string) bool {
+	if len(c.Kinds) == 0 {
+		return true
+	}
+	for _, k := range c.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package connection
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// SSH runs commands on a remote machine with the system ssh client.
+// It covers command execution only; a full SSH Connection (file and tmux
+// operations) is not implemented yet.
+type SSH struct {
+	// Host is the ssh destination, [user@]host.
+	Host string
+
+	// KeyPath, if set, is the private key to authenticate with.
+	KeyPath string
+
+	// Binary is the ssh client to run (default "ssh").
+	Binary string
+}
+
+// NewSSH returns an SSH runner for host.
+func NewSSH(host, keyPath string) *SSH {
+	return &SSH{Host: host, KeyPath: keyPath}
+}
+
+// Command returns an unstarted command that runs name with args in dir on
+// the remote machine. A dir starting with "~/" is relative to the remote
+// home directory; an empty dir runs in it. The caller wires up stdin,
+// stdout, and stderr.
+//
+// ssh runs without prompting (BatchMode), so keys must already be set up.
+func (s *SSH) Command(dir, name string, args ...string) *exec.Cmd {
+	binary := s.Binary
+	if binary == "" {
+		binary = "ssh"
+	}
+	sshArgs := []string{"-o", "BatchMode=yes"}
+	if s.KeyPath != "" {
+		sshArgs = append(sshArgs, "-i", s.KeyPath)
+	}
+	// "--" ends option parsing, so a host such as "-oProxyCommand=..." is
+	// taken as a destination rather than an ssh option
+	sshArgs = append(sshArgs, "--", s.Host, RemoteCommand(dir, name, args...))
+	return exec.Command(binary, sshArgs...) //nolint:gosec // G204: host and command are built by the caller
+}
+
+// RemoteCommand returns the shell command line that runs name with args in
+// dir, quoted for the remote shell.
+func RemoteCommand(dir, name string, args ...string) string {
+	words := make([]string, 0, len(args)+1)
+	words = append(words, ShellQuote(name))
+	for _, a := range args {
+		words = append(words, ShellQuote(a))
+	}
+	line := strings.Join(words, " ")
+	if dir == "" {
+		return line
+	}
+	cd := ShellQuote(dir)
+	if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+		// Leave the tilde unquoted so the remote shell expands it
+		cd = "~/" + ShellQuote(rest)
+	}
+	return "cd " + cd + " && " + line
+}
+
+// ShellQuote quotes s as a single POSIX shell word.
+func ShellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:@,+") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,54 @@
+package connection
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRemoteCommand(t *testing.T) {
+	tests := []struct {
+		dir, name string
+		args      []string
+		want      string
+	}{
+		{"", "gt", []string{"status"}, "gt status"},
+		{"~/gt", "gt", []string{"sling-receive"}, "cd ~/gt && gt sling-receive"},
+		{"/srv/my town", "gt", nil, "cd '/srv/my town' && gt"},
+		{"", "echo", []string{"it's", ""}, `echo 'it'\''s' ''`},
+	}
+	for _, tt := range tests {
+		if got := RemoteCommand(tt.dir, tt.name, tt.args...); got != tt.want {
+			t.Errorf("RemoteCommand(%q, %q, %q) = %q, want %q", tt.dir, tt.name, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestSSHCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	// A fake ssh that runs the remote command line locally
+	fake := filepath.Join(t.TempDir(), "ssh")
+	script := "#!/bin/sh\nwhile [ \"$1\" != \"--\" ]; do shift; done\nshift 2\nexec sh -c \"$1\"\n"
+	if err := os.WriteFile(fake, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	s := &SSH{Host: "me@box", KeyPath: "/keys/id", Binary: fake}
+	cmd := s.Command(dir, "sh", "-c", `pwd; printf '%s\n' "$0"`, "a 'quoted' arg")
+	if got := strings.Join(cmd.Args[1:7], " "); got != "-o BatchMode=yes -i /keys/id -- me@box" {
+		t.Errorf("ssh args = %q", got)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved, _ := filepath.EvalSymlinks(dir)
+	if got := strings.TrimSpace(string(out)); got != resolved+"\na 'quoted' arg" && got != dir+"\na 'quoted' arg" {
+		t.Errorf("output = %q", got)
+	}
+}
@@ -137,6 +137,9 @@ const (
 
 	// RoleDeacon is the deacon agent role.
 	RoleDeacon = "deacon"
+
+	// RoleObserver is the read-only observer role.
+	RoleObserver = "observer"
 )
 
 // Role emojis - centralized for easy customization.
@@ -159,6 +162,9 @@ const (
 
 	// EmojiPolecat is the polecat emoji (transient worker).
 	EmojiPolecat = "😺"
+
+	// EmojiObserver is the observer emoji (looking on from afar).
+	EmojiObserver = "🔭"
 )
 
 // RoleEmoji returns the emoji for a given role name.
@@ -176,6 +182,8 @@ func RoleEmoji(role string) string {
 		return EmojiCrew
 	case RolePolecat:
 		return EmojiPolecat
+	case RoleObserver:
+		return EmojiObserver
 	default:
 		return "❓"
 	}
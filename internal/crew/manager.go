@@ -1,7 +1,6 @@
 package crew
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -15,9 +14,9 @@ import (
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/runstate"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
-	"github.com/steveyegge/gastown/internal/util"
 )
 
 // Common errors
@@ -283,36 +282,34 @@ func (m *Manager) Get(name string) (*CrewWorker, error) {
 	return m.loadState(name)
 }
 
+// stateDoc returns a crew worker's state document. A missing state file
+// loads as nil.
+func (m *Manager) stateDoc(name string) *runstate.Doc[CrewWorker] {
+	return runstate.NewDocAt(m.stateFile(name), func() *CrewWorker { return nil })
+}
+
 // saveState persists crew worker state to disk using atomic write.
 func (m *Manager) saveState(crew *CrewWorker) error {
-	stateFile := m.stateFile(crew.Name)
-	if err := util.AtomicWriteJSON(stateFile, crew); err != nil {
+	if err := m.stateDoc(crew.Name).Save(crew); err != nil {
 		return fmt.Errorf("writing state: %w", err)
 	}
-
+	_ = excludeStateFiles(m.crewDir(crew.Name)) // non-fatal: only keeps git status clean
 	return nil
 }
 
 // loadState reads crew worker state from disk.
 func (m *Manager) loadState(name string) (*CrewWorker, error) {
-	stateFile := m.stateFile(name)
-
-	data, err := os.ReadFile(stateFile)
+	crew, err := m.stateDoc(name).Load()
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Return minimal crew worker if state file missing
-			return &CrewWorker{
-				Name:      name,
-				Rig:       m.rig.Name,
-				ClonePath: m.crewDir(name),
-			}, nil
-		}
 		return nil, fmt.Errorf("reading state: %w", err)
 	}
-
-	var crew CrewWorker
-	if err := json.Unmarshal(data, &crew); err != nil {
-		return nil, fmt.Errorf("parsing state: %w", err)
+	if crew == nil {
+		// Return minimal crew worker if state file missing
+		return &CrewWorker{
+			Name:      name,
+			Rig:       m.rig.Name,
+			ClonePath: m.crewDir(name),
+		}, nil
 	}
 
 	// Backfill essential fields if missing (handles empty or incomplete state.json)
@@ -326,7 +323,38 @@ func (m *Manager) loadState(name string) (*CrewWorker, error) {
 		crew.ClonePath = m.crewDir(name)
 	}
 
-	return &crew, nil
+	return crew, nil
+}
+
+// stateExclude matches the state file and the lock and temp files written
+// beside it, which live in the root of the crew worker's clone.
+const stateExclude = "/state.json*"
+
+// excludeStateFiles adds stateExclude to the clone's .git/info/exclude so
+// the state files never show up in git status or get committed.
+func excludeStateFiles(clonePath string) error {
+	gitDir := filepath.Join(clonePath, ".git")
+	if info, err := os.Stat(gitDir); err != nil || !info.IsDir() {
+		return err
+	}
+	excludePath := filepath.Join(gitDir, "info", "exclude")
+	content, err := os.ReadFile(excludePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == stateExclude {
+			return nil
+		}
+	}
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		content = append(content, '\n')
+	}
+	content = append(content, []byte("# Gas Town crew state\n"+stateExclude+"\n")...)
+	if err := os.MkdirAll(filepath.Dir(excludePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(excludePath, content, 0644)
 }
 
 // Rename renames a crew worker from oldName to newName.
@@ -0,0 +1,42 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/steveyegge/gastown/internal/branchgc"
+)
+
+// collectBranches runs a branch GC pass over each operational rig that is
+// due for one, when branch_gc is enabled in mayor/daemon.json.
+func (d *Daemon) collectBranches() {
+	if d.patrolConfig == nil || d.patrolConfig.BranchGC == nil || !d.patrolConfig.BranchGC.Enabled {
+		return
+	}
+	policy, err := d.patrolConfig.BranchGC.Policy()
+	if err != nil {
+		d.logger.Printf("Warning: branch GC: %v", err)
+		return
+	}
+
+	for _, rigName := range d.getKnownRigs() {
+		if ok, _ := d.isRigOperational(rigName); !ok {
+			continue
+		}
+		res, err := branchgc.Pass(d.config.TownRoot, rigName, policy, time.Now())
+		if err != nil {
+			d.logger.Printf("Warning: branch GC for %s: %v", rigName, err)
+		}
+		if res == nil {
+			continue
+		}
+		if len(res.Reported) > 0 {
+			d.logger.Printf("Branch GC: reported %d branch(es) in %s to the refinery", len(res.Reported), rigName)
+		}
+		for _, c := range res.Unmerged {
+			d.logger.Printf("Branch GC: keeping %s in %s", c, rigName)
+		}
+		for _, c := range res.Deleted {
+			d.logger.Printf("Branch GC: deleted %s in %s", c, rigName)
+		}
+	}
+}
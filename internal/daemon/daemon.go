@@ -16,19 +16,27 @@ import (
 	"time"
 
 	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/alert"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/boot"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/deacon"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/eventsink"
 	"github.com/steveyegge/gastown/internal/feed"
+	"github.com/steveyegge/gastown/internal/keepalive"
+	"github.com/steveyegge/gastown/internal/metrics"
+	"github.com/steveyegge/gastown/internal/notifier"
 	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/protocol"
 	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/schedule"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/util"
+	"github.com/steveyegge/gastown/internal/webhook"
 	"github.com/steveyegge/gastown/internal/wisp"
 	"github.com/steveyegge/gastown/internal/witness"
 )
@@ -38,14 +46,20 @@ import (
 // This is recovery-focused: normal wake is handled by feed subscription (bd activity --follow).
 // The daemon is the safety net for dead sessions, GUPP violations, and orphaned work.
 type Daemon struct {
-	config       *Config
-	patrolConfig *DaemonPatrolConfig
-	tmux         *tmux.Tmux
-	logger       *log.Logger
-	ctx          context.Context
-	cancel       context.CancelFunc
-	curator      *feed.Curator
+	config        *Config
+	patrolConfig  *DaemonPatrolConfig
+	tmux          *tmux.Tmux
+	logger        *log.Logger
+	ctx           context.Context
+	cancel        context.CancelFunc
+	curator       *feed.Curator
 	convoyWatcher *ConvoyWatcher
+	metrics       *MetricsServer       // nil unless enabled in mayor/daemon.json
+	sinks         *eventsink.Forwarder // nil unless sinks are configured in mayor/daemon.json
+	alerts        *alert.Engine        // nil unless alert rules or SLOs are configured in mayor/daemon.json
+	notifications *notifier.Bridge     // nil unless notification channels are configured in mayor/daemon.json
+	webhooks      *webhook.Receiver    // nil unless enabled in mayor/daemon.json
+	scheduler     *schedule.Scheduler  // nil unless schedule triggers are configured in mayor/daemon.json
 
 	// Mass death detection: track recent session deaths
 	deathsMu     sync.Mutex
@@ -56,6 +70,15 @@ type Daemon struct {
 	// See: https://github.com/steveyegge/gastown/issues/567
 	// Note: Only accessed from heartbeat loop goroutine - no sync needed.
 	deaconLastStarted time.Time
+
+	// Infrastructure clone divergence tracking, keyed by clone path.
+	// Note: Only accessed from heartbeat loop goroutine - no sync needed.
+	infraDiverged map[string]*infraDivergence
+
+	// Agents whose sessions this daemon has seen, so that only starting a
+	// session that existed before counts as a restart.
+	// Note: Only accessed from heartbeat loop goroutine - no sync needed.
+	sessionsSeen map[string]bool
 }
 
 // sessionDeath records a detected session death for mass death analysis.
@@ -167,6 +190,102 @@ func (d *Daemon) Run() error {
 		d.logger.Println("Convoy watcher started")
 	}
 
+	// Start Prometheus metrics endpoint if enabled in mayor/daemon.json
+	if d.patrolConfig != nil && d.patrolConfig.Metrics != nil && d.patrolConfig.Metrics.Enabled {
+		d.metrics = NewMetricsServer(d.config.TownRoot, d.patrolConfig.Metrics.Listen, d.getKnownRigs, d.logger.Printf)
+		if err := d.metrics.Start(); err != nil {
+			d.logger.Printf("Warning: failed to start metrics endpoint: %v", err)
+			d.metrics = nil
+		} else {
+			d.logger.Printf("Metrics endpoint serving on http://%s/metrics", d.metrics.Addr())
+		}
+	}
+
+	// Start inbound webhook receiver if enabled in mayor/daemon.json
+	if d.patrolConfig != nil && d.patrolConfig.Webhooks != nil && d.patrolConfig.Webhooks.Enabled {
+		receiver, err := webhook.NewReceiver(d.config.TownRoot, *d.patrolConfig.Webhooks, d.logger.Printf)
+		if err == nil {
+			err = receiver.Start()
+		}
+		if err != nil {
+			d.logger.Printf("Warning: failed to start webhook receiver: %v", err)
+		} else {
+			d.webhooks = receiver
+			d.logger.Printf("Webhook receiver serving on http://%s/hooks/", receiver.Addr())
+		}
+	}
+
+	// Start event sink forwarding if configured in mayor/daemon.json
+	var sinks *eventsink.Forwarder
+	if d.patrolConfig != nil && len(d.patrolConfig.EventSinks) > 0 {
+		var err error
+		if sinks, err = eventsink.NewForwarder(d.config.TownRoot, d.patrolConfig.EventSinks, d.logger.Printf); err != nil {
+			d.logger.Printf("Warning: failed to start event sinks: %v", err)
+		} else if sinks.Sinks() > 0 {
+			d.logger.Printf("Forwarding events to %d sink(s)", sinks.Sinks())
+		}
+	}
+
+	// Start the notification bridge if channels are configured in mayor/daemon.json.
+	// It receives its events through the forwarder, under its own cursor.
+	if d.patrolConfig != nil && d.patrolConfig.Notifications != nil && len(d.patrolConfig.Notifications.Channels) > 0 {
+		bridge, err := notifier.NewBridge(d.config.TownRoot, *d.patrolConfig.Notifications, d.logger.Printf)
+		switch {
+		case err != nil:
+			d.logger.Printf("Warning: failed to start notifications: %v", err)
+		case bridge.Channels() > 0:
+			if sinks == nil {
+				sinks, _ = eventsink.NewForwarder(d.config.TownRoot, nil, d.logger.Printf)
+			}
+			sinks.Attach("notifications", notifier.EventTypes, bridge)
+			d.notifications = bridge
+			_ = d.notifications.Start()
+			d.logger.Printf("Sending notifications to %d channel(s)", bridge.Channels())
+		}
+	}
+	if sinks != nil && sinks.Sinks() > 0 {
+		d.sinks = sinks
+		_ = d.sinks.Start()
+	}
+
+	// Start alert rule evaluation if rules or SLOs are configured in mayor/daemon.json
+	if d.patrolConfig != nil && ((d.patrolConfig.Alerts != nil && len(d.patrolConfig.Alerts.Rules) > 0) || len(d.patrolConfig.SLOs) > 0) {
+		var cfg alert.Config
+		if d.patrolConfig.Alerts != nil {
+			cfg = *d.patrolConfig.Alerts
+		}
+		var metricsFn func() []*metrics.Family
+		if alert.UsesMetrics(cfg) {
+			if d.metrics == nil {
+				// Collect without serving, so metric rules work (and
+				// restarts are counted) without the endpoint enabled.
+				d.metrics = NewMetricsServer(d.config.TownRoot, "", d.getKnownRigs, d.logger.Printf)
+			}
+			metricsFn = d.metrics.Families
+		}
+		engine, err := alert.NewEngine(d.config.TownRoot, cfg, d.patrolConfig.SLOs, metricsFn, d.logger.Printf)
+		switch {
+		case err != nil:
+			d.logger.Printf("Warning: failed to start alert rules: %v", err)
+		case engine.Rules() > 0:
+			d.alerts = engine
+			_ = d.alerts.Start()
+			d.logger.Printf("Evaluating %d alert rule(s)", d.alerts.Rules())
+		}
+	}
+
+	// Start scheduled callbacks if triggers are configured in mayor/daemon.json
+	if d.patrolConfig != nil && d.patrolConfig.Schedules != nil && len(d.patrolConfig.Schedules.Triggers) > 0 {
+		scheduler, err := schedule.New(d.config.TownRoot, *d.patrolConfig.Schedules, d.logger.Printf)
+		if err != nil {
+			d.logger.Printf("Warning: failed to start schedules: %v", err)
+		} else {
+			d.scheduler = scheduler
+			_ = d.scheduler.Start()
+			d.logger.Printf("Scheduling %d callback trigger(s)", d.scheduler.Triggers())
+		}
+	}
+
 	// Initial heartbeat
 	d.heartbeat(state)
 
@@ -211,6 +330,11 @@ const recoveryHeartbeatInterval = 3 * time.Minute
 func (d *Daemon) heartbeat(state *State) {
 	d.logger.Println("Heartbeat starting (recovery-focused)")
 
+	// A pass that syncs clones and collects branches across many rigs can
+	// run for minutes; keep the daemon's own keepalive fresh while it does.
+	hb := keepalive.StartForSession(d.config.TownRoot, keepalive.DaemonSession, "gt daemon", "heartbeat")
+	defer hb.Stop()
+
 	// 1. Ensure Deacon is running (restart if dead)
 	// Check patrol config - can be disabled in mayor/daemon.json
 	if IsPatrolEnabled(d.patrolConfig, "deacon") {
@@ -274,6 +398,25 @@ func (d *Daemon) heartbeat(state *State) {
 	// This is a safety net - Deacon patrol also does this more frequently.
 	d.cleanupOrphanedProcesses()
 
+	// 13. Keep mayor/refinery clones in sync with origin (fast-forward only),
+	// escalating clones that have accidental local commits
+	hb.SetPhase("syncing clones")
+	d.syncInfraClones()
+
+	// 14. Rotate the town log and events log, pruning expired segments
+	hb.SetPhase("rotating logs")
+	d.rotateLogs()
+
+	// 15. Redeliver unacknowledged protocol messages, escalating to the Mayor
+	// those that were never processed
+	hb.SetPhase("checking protocol acks")
+	d.checkProtocolAcks()
+
+	// 16. Prune merged and orphaned branches, reporting to each Refinery
+	// before deleting anything
+	hb.SetPhase("collecting branches")
+	d.collectBranches()
+
 	// Update state
 	state.LastHeartbeat = time.Now()
 	state.HeartbeatCount++
@@ -284,6 +427,21 @@ func (d *Daemon) heartbeat(state *State) {
 	d.logger.Printf("Heartbeat complete (#%d)", state.HeartbeatCount)
 }
 
+// checkProtocolAcks redelivers protocol messages (MERGE_FAILED and friends)
+// whose recipients have not processed them, and escalates to the Mayor
+// those delivered too many times.
+func (d *Daemon) checkProtocolAcks() {
+	res, err := protocol.NewAckTracker(d.config.TownRoot).Check(time.Now())
+	if err != nil {
+		d.logger.Printf("Warning: checking protocol acknowledgments: %v", err)
+		return
+	}
+	if res.Retried > 0 || res.Escalated > 0 {
+		d.logger.Printf("Protocol acks: %d redelivered, %d escalated to mayor, %d pending",
+			res.Retried, res.Escalated, res.Pending)
+	}
+}
+
 // DeaconRole is the role name for the Deacon's handoff bead.
 const DeaconRole = "deacon"
 
@@ -347,6 +505,7 @@ func (d *Daemon) runDegradedBootTriage(b *boot.Boot) {
 		status.LastAction = "start"
 		status.Target = "deacon"
 	} else {
+		d.markSessionSeen("deacon")
 		status.LastAction = "nothing"
 	}
 
@@ -366,6 +525,7 @@ func (d *Daemon) ensureDeaconRunning() {
 	if err := mgr.Start(""); err != nil {
 		if err == deacon.ErrAlreadyRunning {
 			// Deacon is running - nothing to do
+			d.markSessionSeen("deacon")
 			return
 		}
 		d.logger.Printf("Error starting Deacon: %v", err)
@@ -375,6 +535,7 @@ func (d *Daemon) ensureDeaconRunning() {
 	// Track when we started the Deacon to prevent race condition in checkDeaconHeartbeat.
 	// The heartbeat file will still be stale until the Deacon runs a full patrol cycle.
 	d.deaconLastStarted = time.Now()
+	d.recordStart("deacon", "", "deacon")
 	d.logger.Println("Deacon started successfully")
 }
 
@@ -475,6 +636,7 @@ func (d *Daemon) ensureWitnessRunning(rigName string) {
 	if err := mgr.Start(false, "", nil); err != nil {
 		if err == witness.ErrAlreadyRunning {
 			// Already running - this is the expected case
+			d.markSessionSeen(rigName + "/witness")
 			d.logger.Printf("Witness for %s already running, skipping spawn", rigName)
 			return
 		}
@@ -482,6 +644,7 @@ func (d *Daemon) ensureWitnessRunning(rigName string) {
 		return
 	}
 
+	d.recordStart("witness", rigName, rigName+"/witness")
 	d.logger.Printf("Witness session for %s started successfully", rigName)
 }
 
@@ -515,6 +678,7 @@ func (d *Daemon) ensureRefineryRunning(rigName string) {
 	if err := mgr.Start(false, ""); err != nil {
 		if err == refinery.ErrAlreadyRunning {
 			// Already running - this is the expected case when fix is working
+			d.markSessionSeen(rigName + "/refinery")
 			d.logger.Printf("Refinery for %s already running, skipping spawn", rigName)
 			return
 		}
@@ -522,6 +686,7 @@ func (d *Daemon) ensureRefineryRunning(rigName string) {
 		return
 	}
 
+	d.recordStart("refinery", rigName, rigName+"/refinery")
 	d.logger.Printf("Refinery session for %s started successfully", rigName)
 }
 
@@ -655,6 +820,42 @@ func (d *Daemon) shutdown(state *State) error { //nolint:unparam // error return
 		d.logger.Println("Convoy watcher stopped")
 	}
 
+	// Stop scheduled callbacks
+	if d.scheduler != nil {
+		d.scheduler.Stop()
+		d.logger.Println("Schedules stopped")
+	}
+
+	// Stop alert rule evaluation
+	if d.alerts != nil {
+		d.alerts.Stop()
+		d.logger.Println("Alert rules stopped")
+	}
+
+	// Stop event sink forwarding
+	if d.sinks != nil {
+		d.sinks.Stop()
+		d.logger.Println("Event sinks stopped")
+	}
+
+	// Stop notification delivery (after the forwarder that feeds it)
+	if d.notifications != nil {
+		d.notifications.Stop()
+		d.logger.Println("Notifications stopped")
+	}
+
+	// Stop webhook receiver
+	if d.webhooks != nil {
+		d.webhooks.Stop()
+		d.logger.Println("Webhook receiver stopped")
+	}
+
+	// Stop metrics endpoint
+	if d.metrics != nil {
+		d.metrics.Stop()
+		d.logger.Println("Metrics endpoint stopped")
+	}
+
 	state.Running = false
 	if err := SaveState(d.config.TownRoot, state); err != nil {
 		d.logger.Printf("Warning: failed to save final state: %v", err)
@@ -842,10 +1043,44 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 		// Notify witness as fallback
 		d.notifyWitnessOfCrashedPolecat(rigName, polecatName, info.HookBead, err)
 	} else {
+		d.recordRestart("polecat", rigName, rigName+"/polecats/"+polecatName)
 		d.logger.Printf("Successfully restarted crashed polecat %s/%s", rigName, polecatName)
 	}
 }
 
+// recordStart notes that the daemon started agent's session. Only a start
+// of a session the daemon has seen before is a restart; bringing an agent
+// up for the first time (e.g. when the daemon itself starts) is not.
+func (d *Daemon) recordStart(role, rigName, agent string) {
+	if d.markSessionSeen(agent) {
+		d.recordRestart(role, rigName, agent)
+	}
+}
+
+// markSessionSeen records that agent's session exists and reports whether
+// it had been seen before.
+func (d *Daemon) markSessionSeen(agent string) bool {
+	if d.sessionsSeen == nil {
+		d.sessionsSeen = make(map[string]bool)
+	}
+	seen := d.sessionsSeen[agent]
+	d.sessionsSeen[agent] = true
+	return seen
+}
+
+// recordRestart counts an agent session the daemon restarted and reports
+// it on the activity feed.
+func (d *Daemon) recordRestart(role, rigName, agent string) {
+	d.metrics.RecordRestart(role, rigName)
+	_ = events.EmitTo(d.config.TownRoot, events.Event{
+		Type:    events.TypeRestart,
+		Actor:   "daemon",
+		Rig:     rigName,
+		Subject: agent,
+		Payload: events.RestartPayload(role, agent),
+	})
+}
+
 // recordSessionDeath records a session death and checks for mass death pattern.
 func (d *Daemon) recordSessionDeath(sessionName string) {
 	d.deathsMu.Lock()
@@ -0,0 +1,91 @@
+package daemon
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// infraDivergence records an infrastructure clone seen with local commits.
+type infraDivergence struct {
+	head      string // HEAD when the divergence was first seen
+	escalated bool
+}
+
+// syncInfraClones fetches origin for each operational rig's mayor/rig and
+// refinery/rig and fast-forwards mayor/rig when it is behind; refinery/rig
+// belongs to the Refinery and is only checked for drift. Clones with local
+// commits are escalated to the Mayor once they stay diverged at the same
+// HEAD across two heartbeats; the Refinery is briefly ahead of origin
+// between merging and pushing, and that must not raise an alarm.
+func (d *Daemon) syncInfraClones() {
+	if d.infraDiverged == nil {
+		d.infraDiverged = make(map[string]*infraDivergence)
+	}
+	seen := make(map[string]bool)
+
+	for _, rigName := range d.getKnownRigs() {
+		if ok, _ := d.isRigOperational(rigName); !ok {
+			continue
+		}
+		rigPath := filepath.Join(d.config.TownRoot, rigName)
+		results, err := rig.SyncInfraClones(rigPath)
+		if err != nil {
+			d.logger.Printf("Warning: infra clone sync for %s: %v", rigName, err)
+		}
+
+		for _, s := range results {
+			if !s.NeedsEscalation() {
+				continue
+			}
+			seen[s.Path] = true
+			prev := d.infraDiverged[s.Path]
+			if prev == nil || prev.head != s.Head {
+				d.infraDiverged[s.Path] = &infraDivergence{head: s.Head}
+				continue
+			}
+			if !prev.escalated {
+				d.escalateInfraDivergence(rigName, s)
+				prev.escalated = true
+			}
+		}
+	}
+
+	// Forget clones that recovered so a later divergence escalates again
+	for path := range d.infraDiverged {
+		if !seen[path] {
+			delete(d.infraDiverged, path)
+		}
+	}
+}
+
+// escalateInfraDivergence mails the Mayor about an infrastructure clone
+// with local commits that can't be synced automatically.
+func (d *Daemon) escalateInfraDivergence(rigName string, s *rig.CloneSync) {
+	rel, err := filepath.Rel(d.config.TownRoot, s.Path)
+	if err != nil {
+		rel = s.Path
+	}
+	subject := fmt.Sprintf("ESCALATION: %s %s", rel, s.State)
+	body := fmt.Sprintf(`Infrastructure clone %s is %s and can't be fast-forwarded.
+
+rig: %s
+branch: %s
+head: %s
+local_commits: %d
+upstream_commits: %d
+
+Infrastructure clones should never carry their own commits.
+Inspect with: git -C %s log origin/%s..HEAD
+Then push the commits elsewhere or reset the clone to origin/%s.`,
+		rel, s, rigName, s.Branch, s.Head, s.Ahead, s.Behind, s.Path, s.Branch, s.Branch)
+
+	d.logger.Printf("Infra clone %s: %s, escalating to mayor", rel, s)
+	cmd := exec.Command("gt", "mail", "send", "mayor/", "-s", subject, "-m", body) //nolint:gosec // G204: args are constructed internally
+	cmd.Dir = d.config.TownRoot
+	if err := cmd.Run(); err != nil {
+		d.logger.Printf("Warning: failed to escalate infra clone divergence: %v", err)
+	}
+}
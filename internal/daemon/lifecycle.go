@@ -13,6 +13,8 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/identity"
+	"github.com/steveyegge/gastown/internal/keepalive"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
@@ -218,54 +220,19 @@ type ParsedIdentity struct {
 	AgentName string // Empty for singletons (mayor, deacon, witness, refinery)
 }
 
-// parseIdentity extracts role type, rig name, and agent name from an identity string.
-// This is the ONLY place where identity string patterns are parsed.
+// parseIdentity extracts role type, rig name, and agent name from an identity
+// string, in the daemon's dash form or any other identity.Parse accepts.
 // All other functions should use the extracted components to look up role beads.
-func parseIdentity(identity string) (*ParsedIdentity, error) {
-	switch identity {
-	case "mayor":
-		return &ParsedIdentity{RoleType: "mayor"}, nil
-	case "deacon":
-		return &ParsedIdentity{RoleType: "deacon"}, nil
-	}
-
-	// Pattern: <rig>-witness → witness role
-	if strings.HasSuffix(identity, "-witness") {
-		rigName := strings.TrimSuffix(identity, "-witness")
-		return &ParsedIdentity{RoleType: "witness", RigName: rigName}, nil
-	}
-
-	// Pattern: <rig>-refinery → refinery role
-	if strings.HasSuffix(identity, "-refinery") {
-		rigName := strings.TrimSuffix(identity, "-refinery")
-		return &ParsedIdentity{RoleType: "refinery", RigName: rigName}, nil
-	}
-
-	// Pattern: <rig>-crew-<name> → crew role
-	if strings.Contains(identity, "-crew-") {
-		parts := strings.SplitN(identity, "-crew-", 2)
-		if len(parts) == 2 {
-			return &ParsedIdentity{RoleType: "crew", RigName: parts[0], AgentName: parts[1]}, nil
-		}
-	}
-
-	// Pattern: <rig>-polecat-<name> → polecat role
-	if strings.Contains(identity, "-polecat-") {
-		parts := strings.SplitN(identity, "-polecat-", 2)
-		if len(parts) == 2 {
-			return &ParsedIdentity{RoleType: "polecat", RigName: parts[0], AgentName: parts[1]}, nil
-		}
+func parseIdentity(s string) (*ParsedIdentity, error) {
+	id, err := identity.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("unknown identity format: %s", s)
 	}
-
-	// Pattern: <rig>/polecats/<name> → polecat role (slash format)
-	if strings.Contains(identity, "/polecats/") {
-		parts := strings.Split(identity, "/polecats/")
-		if len(parts) == 2 {
-			return &ParsedIdentity{RoleType: "polecat", RigName: parts[0], AgentName: parts[1]}, nil
-		}
+	switch id.Role {
+	case identity.Mayor, identity.Deacon, identity.Witness, identity.Refinery, identity.Crew, identity.Polecat:
+		return &ParsedIdentity{RoleType: string(id.Role), RigName: id.Rig, AgentName: id.Name}, nil
 	}
-
-	return nil, fmt.Errorf("unknown identity format: %s", identity)
+	return nil, fmt.Errorf("not a daemon-managed agent: %s", s)
 }
 
 // getRoleConfigForIdentity looks up the role bead for an identity and returns its config.
@@ -746,33 +713,12 @@ func (d *Daemon) identityToAgentBeadID(identity string) string {
 // "Discover, don't track" principle: observable state should not be recorded.
 
 // identityToBDActor converts a daemon identity to BD_ACTOR format (with slashes).
-// Uses parseIdentity to extract components, then builds the slash format.
-func identityToBDActor(identity string) string {
-	// Handle already-slash-formatted identities
-	if strings.Contains(identity, "/polecats/") || strings.Contains(identity, "/crew/") ||
-		strings.Contains(identity, "/witness") || strings.Contains(identity, "/refinery") {
-		return identity
-	}
-
-	parsed, err := parseIdentity(identity)
+func identityToBDActor(s string) string {
+	id, err := identity.Parse(s)
 	if err != nil {
-		return identity // Unknown format - return as-is
-	}
-
-	switch parsed.RoleType {
-	case "mayor", "deacon":
-		return parsed.RoleType
-	case "witness":
-		return parsed.RigName + "/witness"
-	case "refinery":
-		return parsed.RigName + "/refinery"
-	case "crew":
-		return parsed.RigName + "/crew/" + parsed.AgentName
-	case "polecat":
-		return parsed.RigName + "/polecats/" + parsed.AgentName
-	default:
-		return identity
+		return s // Unknown format - return as-is
 	}
+	return id.Actor()
 }
 
 // GUPPViolationTimeout is how long an agent can have work on hook without
@@ -847,6 +793,11 @@ func (d *Daemon) checkRigGUPPViolations(rigName string) {
 			}
 
 			age := time.Since(updatedAt)
+			// A long command (a test run, a push) keeps the session's
+			// keepalive fresh without touching the agent bead
+			if ka := keepalive.ReadSession(d.config.TownRoot, sessionName); ka.Age() < age {
+				age = ka.Age()
+			}
 			if age > GUPPViolationTimeout {
 				d.logger.Printf("GUPP violation: agent %s has hook_bead=%s but hasn't updated in %v (timeout: %v)",
 					agent.ID, agent.HookBead, age.Round(time.Minute), GUPPViolationTimeout)
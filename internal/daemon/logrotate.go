@@ -0,0 +1,108 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/logrotate"
+	"github.com/steveyegge/gastown/internal/townlog"
+)
+
+// Log rotation defaults, used when mayor/daemon.json doesn't override them.
+const (
+	DefaultLogMaxSizeMB = 50
+	DefaultLogMaxAge    = "7d"
+	DefaultLogRetention = "90d"
+)
+
+// RotatedLog pairs a town log with the policy used to rotate it.
+type RotatedLog struct {
+	Path   string
+	Policy logrotate.Policy
+}
+
+// TownLogs returns the town's rotated logs with their policies, or nil if
+// rotation is disabled in cfg. A nil cfg uses the defaults.
+func TownLogs(townRoot string, cfg *LogRotationConfig) ([]RotatedLog, error) {
+	if cfg == nil {
+		cfg = &LogRotationConfig{}
+	}
+	if cfg.Disabled {
+		return nil, nil
+	}
+
+	maxSizeMB := cfg.MaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = DefaultLogMaxSizeMB
+	}
+	maxAge, err := logrotate.ParseDuration(orDefault(cfg.MaxAge, DefaultLogMaxAge))
+	if err != nil {
+		return nil, fmt.Errorf("log_rotation.max_age: %w", err)
+	}
+	retention, err := logrotate.ParseDuration(orDefault(cfg.Retention, DefaultLogRetention))
+	if err != nil {
+		return nil, fmt.Errorf("log_rotation.retention: %w", err)
+	}
+
+	base := logrotate.Policy{
+		MaxSize:   int64(maxSizeMB) << 20,
+		MaxAge:    maxAge,
+		Retention: retention,
+		Compress:  cfg.Compress == nil || *cfg.Compress,
+	}
+	townPolicy, eventsPolicy := base, base
+	townPolicy.LineTime = townlog.LineTime
+	eventsPolicy.LineTime = events.LineTime
+
+	return []RotatedLog{
+		{Path: townlog.LogPath(townRoot), Policy: townPolicy},
+		{Path: filepath.Join(townRoot, events.EventsFile), Policy: eventsPolicy},
+	}, nil
+}
+
+// RotateTownLogs rotates the town log and events log per cfg and prunes
+// expired segments. Returns the paths of the logs that were rotated.
+func RotateTownLogs(townRoot string, cfg *LogRotationConfig, now time.Time) ([]string, error) {
+	logs, err := TownLogs(townRoot, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var rotated []string
+	var errs []error
+	for _, l := range logs {
+		ok, err := logrotate.Rotate(l.Path, l.Policy, now)
+		if ok {
+			rotated = append(rotated, l.Path)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return rotated, errors.Join(errs...)
+}
+
+// rotateLogs rotates the town logs during heartbeat.
+func (d *Daemon) rotateLogs() {
+	var cfg *LogRotationConfig
+	if d.patrolConfig != nil {
+		cfg = d.patrolConfig.LogRotation
+	}
+	rotated, err := RotateTownLogs(d.config.TownRoot, cfg, time.Now())
+	for _, path := range rotated {
+		d.logger.Printf("Rotated %s", filepath.Base(path))
+	}
+	if err != nil {
+		d.logger.Printf("Warning: log rotation: %v", err)
+	}
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
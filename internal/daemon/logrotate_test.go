@@ -0,0 +1,75 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/logrotate"
+	"github.com/steveyegge/gastown/internal/townlog"
+)
+
+func TestTownLogs_Policy(t *testing.T) {
+	townRoot := t.TempDir()
+
+	logs, err := TownLogs(townRoot, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 || logs[0].Path != townlog.LogPath(townRoot) || logs[1].Path != filepath.Join(townRoot, events.EventsFile) {
+		t.Fatalf("logs = %+v", logs)
+	}
+	p := logs[0].Policy
+	if p.MaxSize != 50<<20 || p.MaxAge != 7*24*time.Hour || p.Retention != 90*24*time.Hour || !p.Compress || p.LineTime == nil {
+		t.Errorf("default policy = %+v", p)
+	}
+
+	off := false
+	logs, err = TownLogs(townRoot, &LogRotationConfig{MaxSizeMB: 1, MaxAge: "0", Retention: "0", Compress: &off})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := logs[1].Policy; p.MaxSize != 1<<20 || p.MaxAge != 0 || p.Retention != 0 || p.Compress {
+		t.Errorf("configured policy = %+v", p)
+	}
+
+	if logs, _ := TownLogs(townRoot, &LogRotationConfig{Disabled: true}); logs != nil {
+		t.Errorf("disabled: logs = %+v", logs)
+	}
+	if _, err := TownLogs(townRoot, &LogRotationConfig{Retention: "forever"}); err == nil {
+		t.Error("expected error for invalid retention")
+	}
+}
+
+func TestRotateTownLogs_ReadersSeeSegments(t *testing.T) {
+	townRoot := t.TempDir()
+	eventsPath := filepath.Join(townRoot, events.EventsFile)
+	old := time.Now().Add(-8 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	line := `{"ts":"` + old + `","type":"merged","actor":"gastown/refinery"}` + "\n"
+	if err := os.WriteFile(eventsPath, []byte(line), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The events log's oldest entry is past the default 7d max age
+	rotated, err := RotateTownLogs(townRoot, nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rotated) != 1 || rotated[0] != eventsPath {
+		t.Fatalf("rotated = %v", rotated)
+	}
+	if _, err := os.Stat(eventsPath); !os.IsNotExist(err) {
+		t.Errorf("events log still present after rotation: %v", err)
+	}
+
+	data, err := logrotate.ReadFile(eventsPath)
+	if err != nil || !strings.Contains(string(data), `"merged"`) {
+		t.Errorf("ReadFile across segments = %q, %v", data, err)
+	}
+	if stats := readMergeStats(eventsPath, time.Now(), &mergeCounters{}); stats.total["gastown"] != 1 {
+		t.Errorf("merge stats after rotation = %v", stats.total)
+	}
+}
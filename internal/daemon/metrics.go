@@ -0,0 +1,479 @@
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/logrotate"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/metrics"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/runstate"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// DefaultMetricsListen is the metrics endpoint address unless
+// mayor/daemon.json sets metrics.listen. Loopback only by default.
+const DefaultMetricsListen = "127.0.0.1:9464"
+
+// metricsCacheTTL bounds how often scrapes recompute town metrics.
+// Collection shells out to bd for every rig and mailbox, so scrapes
+// arriving faster than this reuse the last result.
+const metricsCacheTTL = 30 * time.Second
+
+// MetricsServer serves town metrics at /metrics in the Prometheus text
+// format: merge queue depth, merge throughput and failures, polecat states,
+// mail backlog, and agent restarts.
+//
+// Everything except restarts and merge totals is derived from beads, the
+// filesystem, and the town events log on each collection. Merge totals
+// persist in .runtime/metrics/merges.json and advance as merge events are
+// logged (see mergeCounters); restarts are counted in memory as the daemon
+// performs them. A nil *MetricsServer ignores RecordRestart.
+type MetricsServer struct {
+	townRoot string
+	listen   string
+	rigs     func() []string
+	logger   func(format string, args ...interface{})
+	server   *http.Server
+
+	mu       sync.Mutex // protects restarts
+	restarts map[restartKey]int
+
+	cacheMu  sync.Mutex // protects cached, held while collecting
+	cached   []byte
+	cachedAt time.Time
+}
+
+type restartKey struct {
+	role string
+	rig  string
+}
+
+// NewMetricsServer creates a metrics server for the town.
+// rigs returns the rig names to report on at collection time.
+func NewMetricsServer(townRoot, listen string, rigs func() []string, logger func(format string, args ...interface{})) *MetricsServer {
+	if listen == "" {
+		listen = DefaultMetricsListen
+	}
+	return &MetricsServer{
+		townRoot: townRoot,
+		listen:   listen,
+		rigs:     rigs,
+		logger:   logger,
+		restarts: make(map[restartKey]int),
+	}
+}
+
+// Start begins serving /metrics.
+func (m *MetricsServer) Start() error {
+	ln, err := net.Listen("tcp", m.listen)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", m.listen, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	m.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := m.server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			m.logger("metrics server error: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop shuts the server down.
+func (m *MetricsServer) Stop() {
+	if m.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = m.server.Shutdown(ctx)
+}
+
+// Addr returns the address the server listens on.
+func (m *MetricsServer) Addr() string {
+	return m.listen
+}
+
+// RecordRestart counts an agent session restarted by the daemon.
+func (m *MetricsServer) RecordRestart(role, rigName string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.restarts[restartKey{role: role, rig: rigName}]++
+}
+
+// ServeHTTP writes the current metrics.
+func (m *MetricsServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	m.cacheMu.Lock()
+	if m.cached == nil || time.Since(m.cachedAt) > metricsCacheTTL {
+		var buf bytes.Buffer
+		if err := metrics.Write(&buf, m.collect()); err != nil {
+			m.cacheMu.Unlock()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		m.cached, m.cachedAt = buf.Bytes(), time.Now()
+	}
+	body := m.cached
+	m.cacheMu.Unlock()
+
+	w.Header().Set("Content-Type", metrics.ContentType)
+	_, _ = w.Write(body)
+}
+
+// Families collects the current metric families, for alert rules.
+// Unlike scrapes it always collects fresh values.
+func (m *MetricsServer) Families() []*metrics.Family {
+	return m.collect()
+}
+
+// collect gathers all metric families.
+func (m *MetricsServer) collect() []*metrics.Family {
+	rigs := m.rigs()
+	sort.Strings(rigs)
+
+	queue := metrics.NewFamily("gastown_merge_queue_depth", metrics.Gauge,
+		"Open merge requests per rig, by state (pending, in_flight, blocked).")
+	polecats := metrics.NewFamily("gastown_polecats", metrics.Gauge,
+		"Polecats per rig, by state.")
+	mailUnread := metrics.NewFamily("gastown_mail_unread", metrics.Gauge,
+		"Unread messages per agent mailbox.")
+
+	mailboxes := []string{"mayor/", "deacon/"}
+	for _, rigName := range rigs {
+		rigPath := filepath.Join(m.townRoot, rigName)
+
+		if depth, err := mergeQueueDepth(rigPath); err == nil {
+			for _, state := range []string{"pending", "in_flight", "blocked"} {
+				queue.Add(float64(depth[state]), metrics.Labels{"rig": rigName, "state": state})
+			}
+		}
+
+		r := &rig.Rig{Name: rigName, Path: rigPath}
+		counts := map[polecat.State]int{polecat.StateWorking: 0, polecat.StateDone: 0, polecat.StateStuck: 0}
+		if list, err := polecat.NewManager(r, git.NewGit(rigPath), tmux.NewTmux()).List(); err == nil {
+			for _, p := range list {
+				counts[p.State]++
+				mailboxes = append(mailboxes, rigName+"/"+p.Name)
+			}
+		}
+		states := make([]string, 0, len(counts))
+		for state := range counts {
+			states = append(states, string(state))
+		}
+		sort.Strings(states)
+		for _, state := range states {
+			polecats.Add(float64(counts[polecat.State(state)]), metrics.Labels{"rig": rigName, "state": state})
+		}
+
+		mailboxes = append(mailboxes, rigName+"/witness", rigName+"/refinery")
+	}
+
+	router := mail.NewRouterWithTownRoot(m.townRoot, m.townRoot)
+	for _, address := range mailboxes {
+		mailbox, err := router.GetMailbox(address)
+		if err != nil {
+			continue
+		}
+		if _, unread, err := mailbox.Count(); err == nil {
+			mailUnread.Add(float64(unread), metrics.Labels{"address": address})
+		}
+	}
+
+	var merges mergeStats
+	eventsPath := filepath.Join(m.townRoot, events.EventsFile)
+	if _, err := mergeCountersDoc(m.townRoot).Update(func(c *mergeCounters) error {
+		merges = readMergeStats(eventsPath, time.Now(), c)
+		return nil
+	}); err != nil {
+		m.logger("metrics: updating merge counters: %v", err)
+	}
+	mergesTotal := metrics.NewFamily("gastown_merges_total", metrics.Counter,
+		"Merges reported by refineries, per rig.")
+	mergesHour := metrics.NewFamily("gastown_merges_last_hour", metrics.Gauge,
+		"Merges reported by refineries in the last hour, per rig.")
+	failures := metrics.NewFamily("gastown_merge_failures_total", metrics.Counter,
+		"Failed merges reported by refineries, per rig and failure type.")
+	for _, rigName := range rigs {
+		mergesTotal.Add(float64(merges.total[rigName]), metrics.Labels{"rig": rigName})
+		mergesHour.Add(float64(merges.lastHour[rigName]), metrics.Labels{"rig": rigName})
+	}
+	for _, key := range sortedFailureKeys(merges.failures) {
+		failures.Add(float64(merges.failures[key]), metrics.Labels{"rig": key.rig, "type": key.failureType})
+	}
+
+	restarts := metrics.NewFamily("gastown_agent_restarts_total", metrics.Counter,
+		"Agent sessions restarted by the daemon after finding them dead, by role.")
+	m.mu.Lock()
+	counts := make(map[restartKey]int, len(m.restarts))
+	keys := make([]restartKey, 0, len(m.restarts))
+	for key, n := range m.restarts {
+		counts[key] = n
+		keys = append(keys, key)
+	}
+	m.mu.Unlock()
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].role != keys[j].role {
+			return keys[i].role < keys[j].role
+		}
+		return keys[i].rig < keys[j].rig
+	})
+	for _, key := range keys {
+		labels := metrics.Labels{"role": key.role}
+		if key.rig != "" {
+			labels["rig"] = key.rig
+		}
+		restarts.Add(float64(counts[key]), labels)
+	}
+
+	return []*metrics.Family{queue, mergesTotal, mergesHour, failures, polecats, mailUnread, restarts}
+}
+
+// mergeQueueDepth counts a rig's open merge requests by state.
+func mergeQueueDepth(rigPath string) (map[string]int, error) {
+	b := beads.New(rigPath)
+	opts := beads.ListOptions{Type: "merge-request", Status: "open", Priority: -1}
+	open, err := b.List(opts)
+	if err != nil {
+		return nil, err
+	}
+	opts.Status = "in_progress"
+	inProgress, err := b.List(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	depth := map[string]int{"in_flight": len(inProgress)}
+	for _, mr := range open {
+		if len(mr.BlockedBy) > 0 || mr.BlockedByCount > 0 {
+			depth["blocked"]++
+		} else {
+			depth["pending"]++
+		}
+	}
+	return depth, nil
+}
+
+// mergeStats aggregates merge events from the town events log.
+type mergeStats struct {
+	total    map[string]int
+	lastHour map[string]int
+	failures map[failureKey]int
+}
+
+type failureKey struct {
+	rig         string
+	failureType string
+}
+
+// mergeCounters holds the merge totals exported as counters. Retention
+// prunes old events log segments, so totals recounted from the log would
+// go down, which Prometheus reads as a counter reset; instead the totals
+// persist and advance by the merge events logged since the last count.
+//
+// The cursor is a position in the log, not a timestamp, so an event
+// appended out of timestamp order is still counted exactly once. Rotation
+// renames the live log to a segment newer than every existing one, and
+// retention prunes the oldest segments first, so the position survives
+// both.
+type mergeCounters struct {
+	// Segment names the newest rotated segment counted in full ("" before
+	// the first rotation), and Offset how many bytes of the next piece of
+	// the log, a newer segment or else the live log, were counted.
+	Segment string                    `json:"segment,omitempty"`
+	Offset  int64                     `json:"offset,omitempty"`
+	Merged  map[string]int            `json:"merged,omitempty"` // rig -> merges
+	Failed  map[string]map[string]int `json:"failed,omitempty"` // rig -> failure type -> failures
+}
+
+func mergeCountersDoc(townRoot string) *runstate.Doc[mergeCounters] {
+	return runstate.NewDoc[mergeCounters](runstate.Open(townRoot), "metrics/merges.json", nil)
+}
+
+// readMergeStats reads merged and merge_failed events per rig, including
+// rotated segments of the events log, using each event's rig. Events
+// logged after c's cursor advance c's totals, which stats reports; the
+// last hour's merges are counted from the log itself.
+func readMergeStats(eventsPath string, now time.Time, c *mergeCounters) mergeStats {
+	lastHour := make(map[string]int)
+	scanMergeLog(eventsPath, now.Add(-time.Hour), c, lastHour)
+
+	stats := mergeStats{
+		total:    make(map[string]int),
+		lastHour: lastHour,
+		failures: make(map[failureKey]int),
+	}
+	for rigName, n := range c.Merged {
+		stats.total[rigName] = n
+	}
+	for rigName, types := range c.Failed {
+		for failureType, n := range types {
+			stats.failures[failureKey{rig: rigName, failureType: failureType}] = n
+		}
+	}
+	return stats
+}
+
+// scanMergeLog scans the events log at path, its rotated segments oldest
+// first and then the live log, counting merge events past c's cursor into
+// c, and moves the cursor to the end of the log.
+func scanMergeLog(path string, hourAgo time.Time, c *mergeCounters, lastHour map[string]int) {
+	// Open the live log before listing segments, and leave the cursor for
+	// next time if a rotation lands in between
+	live, err := os.Open(path) //nolint:gosec // G304: town events log
+	if err != nil && !os.IsNotExist(err) {
+		return
+	}
+	if live != nil {
+		defer func() { _ = live.Close() }()
+	}
+	segments, err := logrotate.Segments(path)
+	if err != nil || (live != nil && !isLive(live, path)) {
+		return
+	}
+	// Pieces before start are counted in full. A cursor segment no longer
+	// listed was pruned, and with it every older segment.
+	start := 0
+	for i, s := range segments {
+		if segmentName(s) == c.Segment {
+			start = i + 1
+		}
+	}
+	countFrom := func(piece int) int64 {
+		switch {
+		case piece < start:
+			return math.MaxInt64
+		case piece == start:
+			return c.Offset
+		default:
+			return 0
+		}
+	}
+
+	for i, s := range segments {
+		r, err := s.Open()
+		if err != nil {
+			continue // Pruned since listing
+		}
+		scanMergeEvents(r, countFrom(i), hourAgo, c, lastHour)
+		_ = r.Close()
+	}
+	var end int64
+	if live != nil {
+		end = scanMergeEvents(live, countFrom(len(segments)), hourAgo, c, lastHour)
+	}
+
+	c.Segment, c.Offset = "", end
+	if len(segments) > 0 {
+		c.Segment = segmentName(segments[len(segments)-1])
+	}
+}
+
+// isLive reports whether f is still the live log at path.
+func isLive(f *os.File, path string) bool {
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	open, err := f.Stat()
+	return err == nil && os.SameFile(open, current)
+}
+
+// segmentName identifies a rotated segment whether or not it has been
+// compressed yet.
+func segmentName(s logrotate.Segment) string {
+	return strings.TrimSuffix(filepath.Base(s.Path), ".gz")
+}
+
+// scanMergeEvents counts the merge events in r starting at byte offset
+// from or later into c, and counts merges since hourAgo into lastHour.
+// It returns the offset after the last complete line: a trailing partial
+// line is still being written and is read whole next time.
+func scanMergeEvents(r io.Reader, from int64, hourAgo time.Time, c *mergeCounters, lastHour map[string]int) int64 {
+	if c.Merged == nil {
+		c.Merged = make(map[string]int)
+	}
+	if c.Failed == nil {
+		c.Failed = make(map[string]map[string]int)
+	}
+
+	var offset int64
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if err != nil {
+			return offset
+		}
+		at := offset
+		offset += int64(len(line))
+
+		// Cheap filter before decoding: most events aren't merges
+		if !bytes.Contains(line, []byte(`"merge`)) {
+			continue
+		}
+		event, err := events.Parse(line)
+		if err != nil || (event.Type != events.TypeMerged && event.Type != events.TypeMergeFailed) {
+			continue
+		}
+		rigName := event.Rig
+		if ts, err := time.Parse(time.RFC3339, event.Timestamp); err == nil && event.Type == events.TypeMerged && ts.After(hourAgo) {
+			lastHour[rigName]++
+		}
+		if at < from {
+			continue
+		}
+
+		switch event.Type {
+		case events.TypeMerged:
+			c.Merged[rigName]++
+		case events.TypeMergeFailed:
+			failureType, _ := event.Payload["reason"].(string)
+			if failureType == "" {
+				failureType = "unknown"
+			}
+			if c.Failed[rigName] == nil {
+				c.Failed[rigName] = make(map[string]int)
+			}
+			c.Failed[rigName][failureType]++
+		}
+	}
+}
+
+func sortedFailureKeys(failures map[failureKey]int) []failureKey {
+	keys := make([]failureKey, 0, len(failures))
+	for key := range failures {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].rig != keys[j].rig {
+			return keys[i].rig < keys[j].rig
+		}
+		return keys[i].failureType < keys[j].failureType
+	})
+	return keys
+}
@@ -0,0 +1,198 @@
+package daemon
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/metrics"
+)
+
+func TestReadMergeStats(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-10 * time.Minute).Format(time.RFC3339)
+	old := now.Add(-2 * time.Hour).Format(time.RFC3339)
+
+	lines := []string{
+		`{"ts":"` + recent + `","type":"merged","actor":"gastown/refinery","payload":{"worker":"toast"}}`,
+		`{"ts":"` + old + `","type":"merged","actor":"gastown/refinery"}`,
+		`{"ts":"` + recent + `","type":"merged","actor":"beads/refinery"}`,
+		`{"ts":"` + recent + `","type":"merge_failed","actor":"gastown/refinery","payload":{"reason":"conflict"}}`,
+		`{"ts":"` + recent + `","type":"merge_failed","actor":"gastown/refinery","payload":{"reason":"conflict"}}`,
+		`{"ts":"` + recent + `","type":"merge_failed","actor":"gastown/refinery"}`,
+		`{"ts":"` + recent + `","type":"merge_skipped","actor":"gastown/refinery"}`,
+		`{"ts":"` + recent + `","type":"sling","actor":"mayor"}`,
+		`not json with "merged" in it`,
+	}
+	path := filepath.Join(t.TempDir(), ".events.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := readMergeStats(path, now, &mergeCounters{})
+	if stats.total["gastown"] != 2 || stats.total["beads"] != 1 {
+		t.Errorf("total = %v, want gastown:2 beads:1", stats.total)
+	}
+	if stats.lastHour["gastown"] != 1 {
+		t.Errorf("lastHour[gastown] = %d, want 1", stats.lastHour["gastown"])
+	}
+	if got := stats.failures[failureKey{rig: "gastown", failureType: "conflict"}]; got != 2 {
+		t.Errorf("conflict failures = %d, want 2", got)
+	}
+	if got := stats.failures[failureKey{rig: "gastown", failureType: "unknown"}]; got != 1 {
+		t.Errorf("unknown failures = %d, want 1", got)
+	}
+
+	// A missing log is empty, not an error
+	if stats := readMergeStats(filepath.Join(t.TempDir(), "missing"), now, &mergeCounters{}); len(stats.total) != 0 {
+		t.Errorf("missing log: total = %v", stats.total)
+	}
+}
+
+func TestReadMergeStats_TotalsSurvivePruning(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	event := func(ts time.Time, typ string) string {
+		return `{"ts":"` + ts.Format(time.RFC3339) + `","type":"` + typ + `","actor":"gastown/refinery"}` + "\n"
+	}
+	old := now.Add(-100 * 24 * time.Hour)
+	recent := now.Add(-time.Minute)
+	path := filepath.Join(t.TempDir(), ".events.jsonl")
+	appendLog := func(lines ...string) {
+		t.Helper()
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(strings.Join(lines, "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	total := func(c *mergeCounters) int {
+		t.Helper()
+		return readMergeStats(path, now, c).total["gastown"]
+	}
+
+	var c mergeCounters
+	appendLog(event(old, "merged"), event(old, "merge_failed"), event(recent, "merged"))
+	if got := total(&c); got != 2 {
+		t.Fatalf("total = %d, want 2", got)
+	}
+
+	// Rereading the same log counts nothing twice
+	if got := total(&c); got != 2 {
+		t.Errorf("reread: total = %d, want 2", got)
+	}
+
+	// An event appended with an earlier timestamp is still new
+	appendLog(event(old, "merged"))
+	if got := total(&c); got != 3 {
+		t.Errorf("out of order: total = %d, want 3", got)
+	}
+
+	// A line still being written is counted once it is complete
+	line := event(recent, "merged")
+	appendLog(line[:20])
+	if got := total(&c); got != 3 {
+		t.Errorf("partial line: total = %d, want 3", got)
+	}
+	appendLog(line[20:])
+	if got := total(&c); got != 4 {
+		t.Errorf("completed line: total = %d, want 4", got)
+	}
+
+	// Rotation moves the counted events into a segment
+	if err := os.Rename(path, path+".20260115T115900Z"); err != nil {
+		t.Fatal(err)
+	}
+	appendLog(event(recent, "merged"))
+	if got := total(&c); got != 5 {
+		t.Errorf("after rotation: total = %d, want 5", got)
+	}
+
+	// Retention prunes the segment, and the live log rotates again
+	if err := os.Remove(path + ".20260115T115900Z"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(path, path+".20260115T115930Z"); err != nil {
+		t.Fatal(err)
+	}
+	appendLog(event(recent, "merged"))
+	stats := readMergeStats(path, now, &c)
+	if stats.total["gastown"] != 6 {
+		t.Errorf("after pruning: total = %v, want gastown:6", stats.total)
+	}
+	if got := stats.failures[failureKey{rig: "gastown", failureType: "unknown"}]; got != 1 {
+		t.Errorf("after pruning: failures = %d, want 1", got)
+	}
+
+	// Pruning everything keeps the totals
+	if err := os.Remove(path + ".20260115T115930Z"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := total(&c); got != 6 {
+		t.Errorf("empty log: total = %d, want 6", got)
+	}
+}
+
+func TestRecordStart_CountsOnlyRestarts(t *testing.T) {
+	d := testDaemon()
+	d.config.TownRoot = t.TempDir()
+	d.metrics = NewMetricsServer(d.config.TownRoot, "", func() []string { return nil }, t.Logf)
+
+	d.recordStart("witness", "gastown", "gastown/witness") // First start
+	d.markSessionSeen("gastown/refinery")                  // Found running
+	d.recordStart("refinery", "gastown", "gastown/refinery")
+	d.recordStart("witness", "gastown", "gastown/witness")
+
+	got := d.metrics.restarts
+	want := map[restartKey]int{
+		{role: "witness", rig: "gastown"}:  1,
+		{role: "refinery", rig: "gastown"}: 1,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("restarts = %v, want %v", got, want)
+	}
+	for key, n := range want {
+		if got[key] != n {
+			t.Errorf("restarts[%v] = %d, want %d", key, got[key], n)
+		}
+	}
+}
+
+func TestMetricsServer_ServeHTTP(t *testing.T) {
+	var nilServer *MetricsServer
+	nilServer.RecordRestart("deacon", "") // must not panic
+
+	m := NewMetricsServer(t.TempDir(), "", func() []string { return nil }, t.Logf)
+	if m.Addr() != DefaultMetricsListen {
+		t.Errorf("Addr() = %q, want default %q", m.Addr(), DefaultMetricsListen)
+	}
+	m.RecordRestart("witness", "gastown")
+	m.RecordRestart("witness", "gastown")
+	m.RecordRestart("deacon", "")
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != metrics.ContentType {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE gastown_merge_queue_depth gauge",
+		"# TYPE gastown_merges_total counter",
+		`gastown_agent_restarts_total{role="deacon"} 1`,
+		`gastown_agent_restarts_total{rig="gastown",role="witness"} 2`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q:\n%s", want, body)
+		}
+	}
+}
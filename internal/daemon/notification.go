@@ -1,11 +1,12 @@
 package daemon
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/runstate"
 )
 
 // NotificationSlot tracks a pending notification for deduplication.
@@ -49,23 +50,15 @@ func (m *NotificationManager) slotPath(session, slot string) string {
 	return filepath.Join(m.stateDir, fmt.Sprintf("slot-%s-%s.json", safeSession, slot))
 }
 
+// slotDoc returns the state document of a notification slot.
+// A missing slot loads as nil.
+func (m *NotificationManager) slotDoc(session, slot string) *runstate.Doc[NotificationSlot] {
+	return runstate.NewDocAt(m.slotPath(session, slot), func() *NotificationSlot { return nil })
+}
+
 // GetSlot reads the current state of a notification slot.
 func (m *NotificationManager) GetSlot(session, slot string) (*NotificationSlot, error) {
-	path := m.slotPath(session, slot)
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil // No slot state
-		}
-		return nil, err
-	}
-
-	var ns NotificationSlot
-	if err := json.Unmarshal(data, &ns); err != nil {
-		return nil, err
-	}
-
-	return &ns, nil
+	return m.slotDoc(session, slot).Load()
 }
 
 // ShouldSend checks if a notification should be sent for this slot.
@@ -97,47 +90,35 @@ func (m *NotificationManager) ShouldSend(session, slot string) (bool, error) {
 
 // RecordSend records that a notification was sent for a slot.
 func (m *NotificationManager) RecordSend(session, slot, message string) error {
-	// Ensure directory exists
-	if err := os.MkdirAll(m.stateDir, 0755); err != nil {
-		return err
-	}
-
-	ns := &NotificationSlot{
+	return m.slotDoc(session, slot).Save(&NotificationSlot{
 		Slot:     slot,
 		Session:  session,
 		Message:  message,
 		SentAt:   time.Now(),
 		Consumed: false,
-	}
-
-	data, err := json.Marshal(ns)
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(m.slotPath(session, slot), data, 0600)
+	})
 }
 
 // MarkConsumed marks a slot's notification as consumed (agent responded).
 func (m *NotificationManager) MarkConsumed(session, slot string) error {
-	ns, err := m.GetSlot(session, slot)
-	if err != nil {
-		return err
-	}
+	return m.markConsumed(m.slotDoc(session, slot))
+}
 
-	if ns == nil {
+// markConsumed marks the notification in a slot document as consumed.
+// A missing or already consumed slot is left alone.
+func (m *NotificationManager) markConsumed(doc *runstate.Doc[NotificationSlot]) error {
+	if !doc.Exists() {
 		return nil // Nothing to mark
 	}
-
-	ns.Consumed = true
-	ns.ConsumedAt = time.Now()
-
-	data, err := json.Marshal(ns)
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(m.slotPath(session, slot), data, 0600)
+	_, err := doc.Update(func(ns *NotificationSlot) error {
+		if ns == nil || ns.Consumed {
+			return runstate.ErrSkip
+		}
+		ns.Consumed = true
+		ns.ConsumedAt = time.Now()
+		return nil
+	})
+	return err
 }
 
 // MarkSessionActive marks all slots for a session as consumed.
@@ -151,23 +132,7 @@ func (m *NotificationManager) MarkSessionActive(session string) error {
 	}
 
 	for _, path := range matches {
-		data, err := os.ReadFile(path)
-		if err != nil {
-			continue
-		}
-
-		var ns NotificationSlot
-		if err := json.Unmarshal(data, &ns); err != nil {
-			continue
-		}
-
-		if !ns.Consumed {
-			ns.Consumed = true
-			ns.ConsumedAt = time.Now()
-			if data, err := json.Marshal(&ns); err == nil {
-				_ = os.WriteFile(path, data, 0644) // non-fatal: state file update
-			}
-		}
+		_ = m.markConsumed(runstate.NewDocAt(path, func() *NotificationSlot { return nil })) // non-fatal: state file update
 	}
 
 	return nil
@@ -175,12 +140,7 @@ func (m *NotificationManager) MarkSessionActive(session string) error {
 
 // ClearSlot removes the state file for a slot.
 func (m *NotificationManager) ClearSlot(session, slot string) error {
-	path := m.slotPath(session, slot)
-	err := os.Remove(path)
-	if os.IsNotExist(err) {
-		return nil
-	}
-	return err
+	return m.slotDoc(session, slot).Delete()
 }
 
 // ClearStaleSlots removes slot files older than maxAge.
@@ -198,7 +158,8 @@ func (m *NotificationManager) ClearStaleSlots() error {
 		}
 
 		if time.Since(info.ModTime()) > m.maxAge {
-			_ = os.Remove(path) // best-effort cleanup
+			_ = os.Remove(path)           // best-effort cleanup
+			_ = os.Remove(path + ".lock") // and its lock
 		}
 	}
 
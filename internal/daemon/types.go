@@ -14,7 +14,14 @@ import (
 	"path/filepath"
 	"time"
 
-	"github.com/steveyegge/gastown/internal/util"
+	"github.com/steveyegge/gastown/internal/alert"
+	"github.com/steveyegge/gastown/internal/branchgc"
+	"github.com/steveyegge/gastown/internal/eventsink"
+	"github.com/steveyegge/gastown/internal/notifier"
+	"github.com/steveyegge/gastown/internal/runstate"
+	"github.com/steveyegge/gastown/internal/schedule"
+	"github.com/steveyegge/gastown/internal/slo"
+	"github.com/steveyegge/gastown/internal/webhook"
 )
 
 // Config holds daemon configuration.
@@ -66,34 +73,20 @@ func StateFile(townRoot string) string {
 	return filepath.Join(townRoot, "daemon", "state.json")
 }
 
+// stateDoc returns the daemon state document. A missing state file loads
+// as an empty State.
+func stateDoc(townRoot string) *runstate.Doc[State] {
+	return runstate.NewDocAt(StateFile(townRoot), func() *State { return &State{} })
+}
+
 // LoadState loads daemon state from disk.
 func LoadState(townRoot string) (*State, error) {
-	stateFile := StateFile(townRoot)
-	data, err := os.ReadFile(stateFile)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return &State{}, nil
-		}
-		return nil, err
-	}
-
-	var state State
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, err
-	}
-	return &state, nil
+	return stateDoc(townRoot).Load()
 }
 
 // SaveState saves daemon state to disk using atomic write.
 func SaveState(townRoot string, state *State) error {
-	stateFile := StateFile(townRoot)
-
-	// Ensure daemon directory exists
-	if err := os.MkdirAll(filepath.Dir(stateFile), 0755); err != nil {
-		return err
-	}
-
-	return util.AtomicWriteJSON(stateFile, state)
+	return stateDoc(townRoot).Save(state)
 }
 
 // PatrolConfig holds configuration for a single patrol.
@@ -121,6 +114,67 @@ type DaemonPatrolConfig struct {
 	Version   int            `json:"version"`
 	Heartbeat *PatrolConfig  `json:"heartbeat,omitempty"`
 	Patrols   *PatrolsConfig `json:"patrols,omitempty"`
+	Metrics   *MetricsConfig `json:"metrics,omitempty"`
+
+	LogRotation *LogRotationConfig `json:"log_rotation,omitempty"`
+
+	// EventSinks forward the town's events to external destinations.
+	EventSinks []eventsink.Config `json:"event_sinks,omitempty"`
+
+	// Alerts are rules over the event and metric streams, evaluated by the
+	// daemon, that mail, post to webhooks, or pause merge queues.
+	Alerts *alert.Config `json:"alerts,omitempty"`
+
+	// SLOs are merge queue service level objectives. gt stats reports
+	// their attainment; the daemon alerts when an error budget runs out.
+	SLOs []slo.Objective `json:"slos,omitempty"`
+
+	// Notifications route escalations, alerts, and SLO breaches to Slack,
+	// Discord, email, or webhooks, batched and deduplicated.
+	Notifications *notifier.Config `json:"notifications,omitempty"`
+
+	// Webhooks receive events from outside the town (issue trackers,
+	// monitoring) and turn them into beads, optionally slung to a rig.
+	Webhooks *webhook.Config `json:"webhooks,omitempty"`
+
+	// Schedules deliver callbacks to the Mayor on cron schedules, driving
+	// recurring rituals such as backlog triage.
+	Schedules *schedule.Config `json:"schedules,omitempty"`
+
+	// BranchGC prunes branches whose MRs merged long ago and orphaned
+	// polecat branches, after reporting them to each rig's Refinery.
+	BranchGC *branchgc.Config `json:"branch_gc,omitempty"`
+}
+
+// MetricsConfig controls the daemon's Prometheus metrics endpoint.
+type MetricsConfig struct {
+	// Enabled starts the /metrics endpoint with the daemon.
+	Enabled bool `json:"enabled"`
+
+	// Listen is the address to serve on (default DefaultMetricsListen).
+	Listen string `json:"listen,omitempty"`
+}
+
+// LogRotationConfig controls rotation of the town log (logs/town.log) and
+// the events log (.events.jsonl). Rotation runs each heartbeat and is on by
+// default; readers such as gt log and gt audit see rotated segments too.
+type LogRotationConfig struct {
+	// Disabled turns rotation off; the logs then grow without bound.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// MaxSizeMB rotates a log once it reaches this size (default 50).
+	MaxSizeMB int `json:"max_size_mb,omitempty"`
+
+	// MaxAge rotates a log once its oldest entry is this old, e.g. "7d"
+	// (default "7d", "0" for no age limit).
+	MaxAge string `json:"max_age,omitempty"`
+
+	// Retention deletes rotated segments this long after rotation, e.g.
+	// "90d" (default "90d", "0" to keep them forever).
+	Retention string `json:"retention,omitempty"`
+
+	// Compress gzips rotated segments (default true).
+	Compress *bool `json:"compress,omitempty"`
 }
 
 // PatrolConfigFile returns the path to the patrol config file.
@@ -4,10 +4,10 @@
 package deacon
 
 import (
-	"encoding/json"
-	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/runstate"
 )
 
 // Heartbeat represents the Deacon's heartbeat file contents.
@@ -35,45 +35,30 @@ func HeartbeatFile(townRoot string) string {
 	return filepath.Join(townRoot, "deacon", "heartbeat.json")
 }
 
+// heartbeatDoc returns the heartbeat document. A missing heartbeat loads
+// with a zero Timestamp.
+func heartbeatDoc(townRoot string) *runstate.Doc[Heartbeat] {
+	return runstate.NewDocAt[Heartbeat](HeartbeatFile(townRoot), nil)
+}
+
 // WriteHeartbeat writes a new heartbeat to disk.
 // Called by the Deacon at the start of each wake cycle.
 func WriteHeartbeat(townRoot string, hb *Heartbeat) error {
-	hbFile := HeartbeatFile(townRoot)
-
-	// Ensure deacon directory exists
-	if err := os.MkdirAll(filepath.Dir(hbFile), 0755); err != nil {
-		return err
-	}
-
 	// Set timestamp if not already set
 	if hb.Timestamp.IsZero() {
 		hb.Timestamp = time.Now().UTC()
 	}
-
-	data, err := json.MarshalIndent(hb, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(hbFile, data, 0600)
+	return heartbeatDoc(townRoot).Save(hb)
 }
 
 // ReadHeartbeat reads the Deacon heartbeat from disk.
 // Returns nil if the file doesn't exist or can't be read.
 func ReadHeartbeat(townRoot string) *Heartbeat {
-	hbFile := HeartbeatFile(townRoot)
-
-	data, err := os.ReadFile(hbFile) //nolint:gosec // G304: path is constructed from trusted townRoot
-	if err != nil {
-		return nil
-	}
-
-	var hb Heartbeat
-	if err := json.Unmarshal(data, &hb); err != nil {
+	hb, err := heartbeatDoc(townRoot).Load()
+	if err != nil || hb.Timestamp.IsZero() {
 		return nil
 	}
-
-	return &hb
+	return hb
 }
 
 // Age returns how old the heartbeat is.
@@ -118,32 +103,26 @@ func (hb *Heartbeat) ShouldPoke() bool {
 // Touch writes a minimal heartbeat with just the timestamp.
 // This is a convenience function for simple heartbeat updates.
 func Touch(townRoot string) error {
-	// Read existing heartbeat to increment cycle
-	existing := ReadHeartbeat(townRoot)
-	cycle := int64(1)
-	if existing != nil {
-		cycle = existing.Cycle + 1
-	}
-
-	return WriteHeartbeat(townRoot, &Heartbeat{
-		Timestamp: time.Now().UTC(),
-		Cycle:     cycle,
-	})
+	return nextHeartbeat(townRoot, Heartbeat{})
 }
 
 // TouchWithAction writes a heartbeat with an action description.
 func TouchWithAction(townRoot, action string, healthy, unhealthy int) error {
-	existing := ReadHeartbeat(townRoot)
-	cycle := int64(1)
-	if existing != nil {
-		cycle = existing.Cycle + 1
-	}
-
-	return WriteHeartbeat(townRoot, &Heartbeat{
-		Timestamp:       time.Now().UTC(),
-		Cycle:           cycle,
+	return nextHeartbeat(townRoot, Heartbeat{
 		LastAction:      action,
 		HealthyAgents:   healthy,
 		UnhealthyAgents: unhealthy,
 	})
 }
+
+// nextHeartbeat replaces the heartbeat with hb, stamped now and numbered
+// one cycle after the current one.
+func nextHeartbeat(townRoot string, hb Heartbeat) error {
+	_, err := heartbeatDoc(townRoot).Update(func(cur *Heartbeat) error {
+		hb.Timestamp = time.Now().UTC()
+		hb.Cycle = cur.Cycle + 1
+		*cur = hb
+		return nil
+	})
+	return err
+}
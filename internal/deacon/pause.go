@@ -2,10 +2,9 @@
 package deacon
 
 import (
-	"encoding/json"
-	"os"
-	"path/filepath"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/runstate"
 )
 
 // PauseState represents the Deacon pause file contents.
@@ -26,63 +25,37 @@ type PauseState struct {
 
 // GetPauseFile returns the path to the Deacon pause file.
 func GetPauseFile(townRoot string) string {
-	return filepath.Join(townRoot, ".runtime", "deacon", "paused.json")
+	return pauseDoc(townRoot).Path()
+}
+
+// pauseDoc returns the pause file in the town's runtime state store.
+// A missing pause file loads as nil.
+func pauseDoc(townRoot string) *runstate.Doc[PauseState] {
+	return runstate.NewDoc(runstate.Open(townRoot), "deacon/paused.json", func() *PauseState { return nil })
 }
 
 // IsPaused checks if the Deacon is currently paused.
 // Returns (isPaused, pauseState, error).
 // If the pause file doesn't exist, returns (false, nil, nil).
 func IsPaused(townRoot string) (bool, *PauseState, error) {
-	pauseFile := GetPauseFile(townRoot)
-
-	data, err := os.ReadFile(pauseFile) //nolint:gosec // G304: path is constructed from trusted townRoot
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil, nil
-		}
+	state, err := pauseDoc(townRoot).Load()
+	if err != nil || state == nil {
 		return false, nil, err
 	}
-
-	var state PauseState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return false, nil, err
-	}
-
-	return state.Paused, &state, nil
+	return state.Paused, state, nil
 }
 
 // Pause pauses the Deacon by creating the pause file.
 func Pause(townRoot, reason, pausedBy string) error {
-	pauseFile := GetPauseFile(townRoot)
-
-	// Ensure parent directory exists
-	if err := os.MkdirAll(filepath.Dir(pauseFile), 0755); err != nil {
-		return err
-	}
-
-	state := PauseState{
+	return pauseDoc(townRoot).Save(&PauseState{
 		Paused:   true,
 		Reason:   reason,
 		PausedAt: time.Now().UTC(),
 		PausedBy: pausedBy,
-	}
-
-	data, err := json.MarshalIndent(state, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(pauseFile, data, 0600)
+	})
 }
 
 // Resume resumes the Deacon by removing the pause file.
 func Resume(townRoot string) error {
-	pauseFile := GetPauseFile(townRoot)
-
-	err := os.Remove(pauseFile)
-	if err != nil && !os.IsNotExist(err) {
-		return err
-	}
-
-	return nil
+	return pauseDoc(townRoot).Delete()
 }
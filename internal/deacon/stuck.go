@@ -2,14 +2,13 @@
 package deacon
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/runstate"
 )
 
 // Default parameters for stuck-session detection.
@@ -103,51 +102,30 @@ func HealthCheckStateFile(townRoot string) string {
 	return filepath.Join(townRoot, "deacon", "health-check-state.json")
 }
 
+// healthCheckDoc returns the health check state document.
+func healthCheckDoc(townRoot string) *runstate.Doc[HealthCheckState] {
+	return runstate.NewDocAt(HealthCheckStateFile(townRoot), func() *HealthCheckState {
+		return &HealthCheckState{Agents: make(map[string]*AgentHealthState)}
+	})
+}
+
 // LoadHealthCheckState loads the health check state from disk.
 // Returns empty state if file doesn't exist.
 func LoadHealthCheckState(townRoot string) (*HealthCheckState, error) {
-	stateFile := HealthCheckStateFile(townRoot)
-
-	data, err := os.ReadFile(stateFile) //nolint:gosec // G304: path is constructed from trusted townRoot
+	state, err := healthCheckDoc(townRoot).Load()
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Return empty state
-			return &HealthCheckState{
-				Agents: make(map[string]*AgentHealthState),
-			}, nil
-		}
 		return nil, fmt.Errorf("reading health check state: %w", err)
 	}
-
-	var state HealthCheckState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("parsing health check state: %w", err)
-	}
-
 	if state.Agents == nil {
 		state.Agents = make(map[string]*AgentHealthState)
 	}
-
-	return &state, nil
+	return state, nil
 }
 
 // SaveHealthCheckState saves the health check state to disk.
 func SaveHealthCheckState(townRoot string, state *HealthCheckState) error {
-	stateFile := HealthCheckStateFile(townRoot)
-
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(stateFile), 0755); err != nil {
-		return fmt.Errorf("creating deacon directory: %w", err)
-	}
-
 	state.LastUpdated = time.Now().UTC()
-
-	data, err := json.MarshalIndent(state, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshaling health check state: %w", err)
-	}
-
-	return os.WriteFile(stateFile, data, 0600)
+	return healthCheckDoc(townRoot).Save(state)
 }
 
 // GetAgentState returns the health state for an agent, creating if needed.
@@ -0,0 +1,370 @@
+// Package dispatch lets idle polecats pull work from a rig's ready queue
+// instead of waiting for the Mayor to sling it to them.
+//
+// A claim takes two steps. The polecat first acquires the lease
+// "<rig>/claims/<bead>" (see package lease); the lease's file lock makes
+// the claim atomic, so two polecats polling at once can't both win the same
+// bead. It then hooks the bead to itself in beads, labelled
+// claimed-by:<agent>, which is what every other tool sees.
+//
+// Claims carry a TTL. Each agent's current claim is recorded under the
+// town's .runtime/claims directory, and every gt command the agent runs
+// renews it (see RenewRecorded). A claim whose lease has lapsed - its
+// polecat died without finishing - goes back in the queue, and the next
+// polecat to poll takes it over.
+package dispatch
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/lease"
+	"github.com/steveyegge/gastown/internal/runstate"
+)
+
+// DefaultClaimTTL is how long a claim lasts without renewal.
+const DefaultClaimTTL = 30 * time.Minute
+
+// Claim labels recorded on claimed beads.
+const (
+	claimedByPrefix = "claimed-by:"
+	claimedAtPrefix = "claimed-at:"
+)
+
+// needsPrefix labels a bead with a capability its claimant must have.
+const needsPrefix = "needs:"
+
+// ErrNotClaimed is returned when an agent doesn't hold a claim on a bead.
+var ErrNotClaimed = errors.New("bead not claimed")
+
+// LeaseName returns the name of the lease guarding claims on a bead.
+func LeaseName(rig, beadID string) string {
+	return rig + "/claims/" + beadID
+}
+
+// workTypes are the issue types polecats may claim. Everything else in the
+// ready queue (merge requests, agents, messages, molecules, ...) is
+// infrastructure handled by other roles.
+var workTypes = map[string]bool{"": true, "task": true, "bug": true, "feature": true, "chore": true}
+
+// Claimable reports whether a bead is work a polecat may claim. It doesn't
+// look at status; see Dispatcher.Queue.
+func Claimable(issue *beads.Issue) bool {
+	if !workTypes[issue.Type] {
+		return false
+	}
+	for _, label := range issue.Labels {
+		if strings.HasPrefix(label, "gt:") && !workTypes[strings.TrimPrefix(label, "gt:")] {
+			return false
+		}
+	}
+	return true
+}
+
+// ClaimedBy returns the agent recorded as having claimed a bead, or "".
+func ClaimedBy(issue *beads.Issue) string {
+	for _, label := range issue.Labels {
+		if strings.HasPrefix(label, claimedByPrefix) {
+			return strings.TrimPrefix(label, claimedByPrefix)
+		}
+	}
+	return ""
+}
+
+// Needs returns the capabilities a bead requires of its claimant.
+func Needs(issue *beads.Issue) []string {
+	var needs []string
+	for _, label := range issue.Labels {
+		if strings.HasPrefix(label, needsPrefix) {
+			needs = append(needs, strings.TrimPrefix(label, needsPrefix))
+		}
+	}
+	return needs
+}
+
+// CanDo reports whether a worker with caps has every capability a bead needs.
+func CanDo(issue *beads.Issue, caps []string) bool {
+	for _, need := range Needs(issue) {
+		found := false
+		for _, c := range caps {
+			if c == need {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// claimLabels returns a bead's claim labels.
+func claimLabels(issue *beads.Issue) []string {
+	var labels []string
+	for _, label := range issue.Labels {
+		if strings.HasPrefix(label, claimedByPrefix) || strings.HasPrefix(label, claimedAtPrefix) {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// beadStore is the subset of *beads.Beads the dispatcher uses.
+type beadStore interface {
+	Ready() ([]*beads.Issue, error)
+	List(opts beads.ListOptions) ([]*beads.Issue, error)
+	Show(id string) (*beads.Issue, error)
+	Update(id string, opts beads.UpdateOptions) error
+}
+
+// Record is an agent's current claim.
+type Record struct {
+	Rig     string    `json:"rig"`
+	Bead    string    `json:"bead"`
+	Claimed time.Time `json:"claimed"`
+}
+
+// recordsDir returns the directory of a town's claim records.
+func recordsDir(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), "claims")
+}
+
+func recordPath(dir, agent string) string {
+	return filepath.Join(dir, strings.ReplaceAll(agent, "/", "-")+".json")
+}
+
+// recordDoc returns agent's claim record document. A missing record loads
+// as nil.
+func recordDoc(dir, agent string) *runstate.Doc[Record] {
+	return runstate.NewDocAt(recordPath(dir, agent), func() *Record { return nil })
+}
+
+func readRecord(dir, agent string) *Record {
+	r, err := recordDoc(dir, agent).Load()
+	if err != nil || r == nil || r.Bead == "" {
+		return nil
+	}
+	return r
+}
+
+// Current returns agent's recorded claim, or nil.
+func Current(townRoot, agent string) *Record {
+	return readRecord(recordsDir(townRoot), agent)
+}
+
+// RenewRecorded renews agent's recorded claim, if any, so an agent that
+// keeps running gt commands keeps its claim. Best-effort: errors are ignored.
+func RenewRecorded(townRoot, agent string) {
+	r := Current(townRoot, agent)
+	if r == nil {
+		return
+	}
+	_, _ = lease.NewStore(townRoot).Renew(LeaseName(r.Rig, r.Bead), agent)
+}
+
+// FinishRecorded drops agent's claim on beadID once its work is done, if
+// that is the agent's recorded claim. It reports whether there was one.
+func FinishRecorded(townRoot, agent, beadID string) (bool, error) {
+	r := Current(townRoot, agent)
+	if r == nil || r.Bead != beadID {
+		return false, nil
+	}
+	d := newDispatcher(r.Rig, nil, lease.NewStore(townRoot), recordsDir(townRoot), 0)
+	if err := d.Finish(beadID, agent); err != nil && !errors.Is(err, ErrNotClaimed) {
+		return true, err
+	}
+	return true, nil
+}
+
+// Dispatcher hands out a rig's ready work to the agents that claim it.
+type Dispatcher struct {
+	rig     string
+	bd      beadStore
+	leases  *lease.Store
+	records string
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// New returns a dispatcher for rig, whose beads are in bd. A ttl of zero
+// uses DefaultClaimTTL.
+func New(townRoot, rig string, bd *beads.Beads, ttl time.Duration) *Dispatcher {
+	return newDispatcher(rig, bd, lease.NewStore(townRoot), recordsDir(townRoot), ttl)
+}
+
+func newDispatcher(rig string, bd beadStore, leases *lease.Store, records string, ttl time.Duration) *Dispatcher {
+	if ttl <= 0 {
+		ttl = DefaultClaimTTL
+	}
+	return &Dispatcher{rig: rig, bd: bd, leases: leases, records: records, ttl: ttl, now: time.Now}
+}
+
+// Queue returns the beads an agent could claim now, highest priority first:
+// ready, unassigned work, and hooked work whose claim has lapsed.
+func (d *Dispatcher) Queue() ([]*beads.Issue, error) {
+	ready, err := d.bd.Ready()
+	if err != nil {
+		return nil, fmt.Errorf("listing ready work: %w", err)
+	}
+	var queue []*beads.Issue
+	for _, issue := range ready {
+		if issue.Status == "open" && issue.Assignee == "" && Claimable(issue) {
+			queue = append(queue, issue)
+		}
+	}
+
+	hooked, err := d.bd.List(beads.ListOptions{Status: "hooked", Priority: -1})
+	if err != nil {
+		return nil, fmt.Errorf("listing claimed work: %w", err)
+	}
+	for _, issue := range hooked {
+		if Claimable(issue) && d.lapsed(issue) {
+			queue = append(queue, issue)
+		}
+	}
+
+	sort.SliceStable(queue, func(i, j int) bool {
+		if queue[i].Priority != queue[j].Priority {
+			return queue[i].Priority < queue[j].Priority
+		}
+		return queue[i].CreatedAt < queue[j].CreatedAt
+	})
+	return queue, nil
+}
+
+// lapsed reports whether a bead was claimed through the dispatcher and its
+// claimant no longer holds the claim. Beads hooked by gt sling have no
+// claim and never lapse.
+func (d *Dispatcher) lapsed(issue *beads.Issue) bool {
+	holder := ClaimedBy(issue)
+	if holder == "" {
+		return false
+	}
+	l, err := d.leases.Get(LeaseName(d.rig, issue.ID))
+	if errors.Is(err, lease.ErrNotFound) {
+		return true
+	}
+	return err == nil && l.Hold(holder) == nil
+}
+
+// Claim claims the first bead in the queue that a worker with caps can do
+// for agent, and hooks it to them. It returns nil if there is nothing to
+// claim.
+func (d *Dispatcher) Claim(agent string, caps []string) (*beads.Issue, error) {
+	queue, err := d.Queue()
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range queue {
+		if !CanDo(candidate, caps) {
+			continue
+		}
+		issue, err := d.claim(candidate.ID, agent)
+		if err != nil {
+			return nil, err
+		}
+		if issue != nil {
+			return issue, nil
+		}
+	}
+	return nil, nil
+}
+
+// claim tries to claim one bead, returning nil if someone else got it first.
+func (d *Dispatcher) claim(beadID, agent string) (*beads.Issue, error) {
+	name := LeaseName(d.rig, beadID)
+	if _, err := d.leases.Acquire(name, agent, lease.AcquireOptions{TTL: d.ttl}); err != nil {
+		if errors.Is(err, lease.ErrHeld) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("claiming %s: %w", beadID, err)
+	}
+
+	// Holding the lease, re-check the bead: it may have been slung, or
+	// claimed and recorded, since the queue was read.
+	issue, err := d.bd.Show(beadID)
+	if err != nil {
+		_, _ = d.leases.Release(name, agent)
+		return nil, fmt.Errorf("claiming %s: %w", beadID, err)
+	}
+	// A claimed bead is ours to take over: its old claim can't still be
+	// held while we hold the lease.
+	free := issue.Status == "open" && issue.Assignee == ""
+	takeover := issue.Status == "hooked" && ClaimedBy(issue) != ""
+	if !free && !takeover {
+		_, _ = d.leases.Release(name, agent)
+		return nil, nil
+	}
+
+	status := "hooked"
+	update := beads.UpdateOptions{
+		Status:    &status,
+		Assignee:  &agent,
+		AddLabels: []string{claimedByPrefix + agent, claimedAtPrefix + d.now().UTC().Format(time.RFC3339)},
+	}
+	for _, label := range claimLabels(issue) {
+		if label != claimedByPrefix+agent {
+			update.RemoveLabels = append(update.RemoveLabels, label)
+		}
+	}
+	if err := d.bd.Update(beadID, update); err != nil {
+		_, _ = d.leases.Release(name, agent)
+		return nil, fmt.Errorf("hooking %s: %w", beadID, err)
+	}
+	issue.Status = status
+	issue.Assignee = agent
+
+	// A failed record only means the claim isn't renewed automatically.
+	_ = recordDoc(d.records, agent).Save(&Record{Rig: d.rig, Bead: beadID, Claimed: d.now().UTC()})
+	return issue, nil
+}
+
+// Renew extends agent's claim on a bead by the claim TTL.
+func (d *Dispatcher) Renew(beadID, agent string) error {
+	if _, err := d.leases.Renew(LeaseName(d.rig, beadID), agent); err != nil {
+		if errors.Is(err, lease.ErrNotHeld) || errors.Is(err, lease.ErrNotFound) {
+			return fmt.Errorf("%w: %s by %s", ErrNotClaimed, beadID, agent)
+		}
+		return err
+	}
+	return nil
+}
+
+// Release gives up agent's claim and puts the bead back in the queue.
+func (d *Dispatcher) Release(beadID, agent string) error {
+	if err := d.Finish(beadID, agent); err != nil {
+		return err
+	}
+	issue, err := d.bd.Show(beadID)
+	if err != nil {
+		return err
+	}
+	status, none := "open", ""
+	return d.bd.Update(beadID, beads.UpdateOptions{
+		Status:       &status,
+		Assignee:     &none,
+		RemoveLabels: claimLabels(issue),
+	})
+}
+
+// Finish drops agent's claim lease once the bead's work is done, leaving
+// the bead itself alone.
+func (d *Dispatcher) Finish(beadID, agent string) error {
+	if r := readRecord(d.records, agent); r != nil && r.Rig == d.rig && r.Bead == beadID {
+		_ = recordDoc(d.records, agent).Delete()
+	}
+	if _, err := d.leases.Release(LeaseName(d.rig, beadID), agent); err != nil {
+		if errors.Is(err, lease.ErrNotHeld) || errors.Is(err, lease.ErrNotFound) {
+			return fmt.Errorf("%w: %s by %s", ErrNotClaimed, beadID, agent)
+		}
+		return err
+	}
+	return nil
+}
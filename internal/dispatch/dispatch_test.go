@@ -0,0 +1,242 @@
+package dispatch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/lease"
+)
+
+// fakeBeads is an in-memory beadStore.
+type fakeBeads struct {
+	issues map[string]*beads.Issue
+}
+
+func newFakeBeads(issues ...*beads.Issue) *fakeBeads {
+	f := &fakeBeads{issues: make(map[string]*beads.Issue)}
+	for _, issue := range issues {
+		f.issues[issue.ID] = issue
+	}
+	return f
+}
+
+func (f *fakeBeads) Ready() ([]*beads.Issue, error) {
+	return f.byStatus("open"), nil
+}
+
+func (f *fakeBeads) List(opts beads.ListOptions) ([]*beads.Issue, error) {
+	return f.byStatus(opts.Status), nil
+}
+
+func (f *fakeBeads) byStatus(status string) []*beads.Issue {
+	var out []*beads.Issue
+	for _, issue := range f.issues {
+		if issue.Status == status {
+			copied := *issue
+			out = append(out, &copied)
+		}
+	}
+	return out
+}
+
+func (f *fakeBeads) Show(id string) (*beads.Issue, error) {
+	issue, ok := f.issues[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	copied := *issue
+	copied.Labels = append([]string(nil), issue.Labels...)
+	return &copied, nil
+}
+
+func (f *fakeBeads) Update(id string, opts beads.UpdateOptions) error {
+	issue := f.issues[id]
+	if opts.Status != nil {
+		issue.Status = *opts.Status
+	}
+	if opts.Assignee != nil {
+		issue.Assignee = *opts.Assignee
+	}
+	issue.Labels = append(issue.Labels, opts.AddLabels...)
+	for _, remove := range opts.RemoveLabels {
+		for i, label := range issue.Labels {
+			if label == remove {
+				issue.Labels = append(issue.Labels[:i], issue.Labels[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func testDispatcher(t *testing.T, ttl time.Duration, issues ...*beads.Issue) (*Dispatcher, *fakeBeads) {
+	t.Helper()
+	bd := newFakeBeads(issues...)
+	dir := t.TempDir()
+	return newDispatcher("gastown", bd, lease.NewStore(dir), recordsDir(dir), ttl), bd
+}
+
+func TestClaimable(t *testing.T) {
+	tests := []struct {
+		issue *beads.Issue
+		want  bool
+	}{
+		{&beads.Issue{Type: "task"}, true},
+		{&beads.Issue{Type: "bug", Labels: []string{"gt:bug", "area:ui"}}, true},
+		{&beads.Issue{}, true},
+		{&beads.Issue{Type: "epic"}, false},
+		{&beads.Issue{Labels: []string{"gt:merge-request"}}, false},
+		{&beads.Issue{Type: "task", Labels: []string{"gt:agent"}}, false},
+	}
+	for _, tt := range tests {
+		if got := Claimable(tt.issue); got != tt.want {
+			t.Errorf("Claimable(type=%q labels=%v) = %v, want %v", tt.issue.Type, tt.issue.Labels, got, tt.want)
+		}
+	}
+}
+
+func TestQueueOrderAndFilter(t *testing.T) {
+	d, _ := testDispatcher(t, 0,
+		&beads.Issue{ID: "gt-low", Status: "open", Type: "task", Priority: 3, CreatedAt: "2026-01-01"},
+		&beads.Issue{ID: "gt-old", Status: "open", Type: "task", Priority: 1, CreatedAt: "2026-01-01"},
+		&beads.Issue{ID: "gt-new", Status: "open", Type: "task", Priority: 1, CreatedAt: "2026-02-01"},
+		&beads.Issue{ID: "gt-taken", Status: "open", Type: "task", Assignee: "gastown/crew/max"},
+		&beads.Issue{ID: "gt-mr", Status: "open", Labels: []string{"gt:merge-request"}},
+		&beads.Issue{ID: "gt-slung", Status: "hooked", Type: "task", Assignee: "gastown/polecats/Toast"},
+	)
+	queue, err := d.Queue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, issue := range queue {
+		ids = append(ids, issue.ID)
+	}
+	want := []string{"gt-old", "gt-new", "gt-low"}
+	if len(ids) != len(want) {
+		t.Fatalf("queue = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("queue = %v, want %v", ids, want)
+		}
+	}
+}
+
+func TestClaimHooksBeadOnce(t *testing.T) {
+	d, bd := testDispatcher(t, 0,
+		&beads.Issue{ID: "gt-a", Status: "open", Type: "task", Priority: 1},
+		&beads.Issue{ID: "gt-b", Status: "open", Type: "task", Priority: 2},
+	)
+
+	first, err := d.Claim("gastown/polecats/Toast", nil)
+	if err != nil || first == nil || first.ID != "gt-a" {
+		t.Fatalf("first claim = %v, %v; want gt-a", first, err)
+	}
+	got := bd.issues["gt-a"]
+	if got.Status != "hooked" || got.Assignee != "gastown/polecats/Toast" || ClaimedBy(got) != "gastown/polecats/Toast" {
+		t.Errorf("claimed bead = %+v", got)
+	}
+
+	second, err := d.Claim("gastown/polecats/Nux", nil)
+	if err != nil || second == nil || second.ID != "gt-b" {
+		t.Fatalf("second claim = %v, %v; want gt-b", second, err)
+	}
+
+	none, err := d.Claim("gastown/polecats/Slit", nil)
+	if err != nil || none != nil {
+		t.Fatalf("third claim = %v, %v; want nothing", none, err)
+	}
+}
+
+func TestClaimSkipsBeadHeldByLease(t *testing.T) {
+	d, _ := testDispatcher(t, 0,
+		&beads.Issue{ID: "gt-a", Status: "open", Type: "task", Priority: 1},
+		&beads.Issue{ID: "gt-b", Status: "open", Type: "task", Priority: 2},
+	)
+	// Another polecat has won the lease on gt-a but not yet hooked it.
+	if _, err := d.leases.Acquire(LeaseName("gastown", "gt-a"), "gastown/polecats/Nux", lease.AcquireOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	issue, err := d.Claim("gastown/polecats/Toast", nil)
+	if err != nil || issue == nil || issue.ID != "gt-b" {
+		t.Fatalf("claim = %v, %v; want gt-b", issue, err)
+	}
+}
+
+func TestLapsedClaimIsTakenOver(t *testing.T) {
+	d, bd := testDispatcher(t, time.Millisecond,
+		&beads.Issue{ID: "gt-a", Status: "open", Type: "task"},
+	)
+	if _, err := d.Claim("gastown/polecats/Toast", nil); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	issue, err := d.Claim("gastown/polecats/Nux", nil)
+	if err != nil || issue == nil || issue.ID != "gt-a" {
+		t.Fatalf("takeover = %v, %v; want gt-a", issue, err)
+	}
+	got := bd.issues["gt-a"]
+	if got.Assignee != "gastown/polecats/Nux" || ClaimedBy(got) != "gastown/polecats/Nux" {
+		t.Errorf("after takeover: %+v", got)
+	}
+	if len(claimLabels(got)) != 2 {
+		t.Errorf("stale claim labels left: %v", got.Labels)
+	}
+}
+
+func TestRenewAndRelease(t *testing.T) {
+	d, bd := testDispatcher(t, 0, &beads.Issue{ID: "gt-a", Status: "open", Type: "task"})
+	if _, err := d.Claim("gastown/polecats/Toast", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Renew("gt-a", "gastown/polecats/Nux"); !errors.Is(err, ErrNotClaimed) {
+		t.Errorf("renew by non-claimant = %v, want ErrNotClaimed", err)
+	}
+	if err := d.Renew("gt-a", "gastown/polecats/Toast"); err != nil {
+		t.Errorf("renew: %v", err)
+	}
+	if r := readRecord(d.records, "gastown/polecats/Toast"); r == nil || r.Bead != "gt-a" || r.Rig != "gastown" {
+		t.Errorf("claim record = %+v, want gt-a in gastown", r)
+	}
+
+	if err := d.Release("gt-a", "gastown/polecats/Toast"); err != nil {
+		t.Fatal(err)
+	}
+	got := bd.issues["gt-a"]
+	if got.Status != "open" || got.Assignee != "" || ClaimedBy(got) != "" {
+		t.Errorf("released bead = %+v", got)
+	}
+	if r := readRecord(d.records, "gastown/polecats/Toast"); r != nil {
+		t.Errorf("claim record kept after release: %+v", r)
+	}
+	if issue, err := d.Claim("gastown/polecats/Nux", nil); err != nil || issue == nil {
+		t.Errorf("reclaim after release = %v, %v", issue, err)
+	}
+}
+
+func TestClaimMatchesCapabilities(t *testing.T) {
+	d, _ := testDispatcher(t, 0,
+		&beads.Issue{ID: "gt-ui", Status: "open", Type: "task", Priority: 0, Labels: []string{"needs:frontend"}},
+		&beads.Issue{ID: "gt-go", Status: "open", Type: "task", Priority: 1, Labels: []string{"needs:go"}},
+		&beads.Issue{ID: "gt-any", Status: "open", Type: "task", Priority: 2},
+	)
+
+	issue, err := d.Claim("gastown/polecats/Toast", []string{"go"})
+	if err != nil || issue == nil || issue.ID != "gt-go" {
+		t.Fatalf("claim with go = %v, %v; want gt-go", issue, err)
+	}
+	issue, err = d.Claim("gastown/polecats/Nux", nil)
+	if err != nil || issue == nil || issue.ID != "gt-any" {
+		t.Fatalf("claim with no capabilities = %v, %v; want gt-any", issue, err)
+	}
+	issue, err = d.Claim("gastown/polecats/Slit", []string{"go"})
+	if err != nil || issue != nil {
+		t.Fatalf("claim with go after queue drained = %v, %v; want nothing", issue, err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/steveyegge/gastown/internal/beads"
@@ -395,6 +396,112 @@ func (c *PrefixMismatchCheck) Fix(ctx *CheckContext) error {
 	return nil
 }
 
+// RigPrefixCheck validates the per-rig beads prefixes in rigs.json, which is
+// the source of truth for each rig's ID namespace. Every rig needs a prefix of
+// its own: two rigs sharing one collide on IDs and routing can only send the
+// prefix to one of them. The rig's beads database must also have been
+// initialized with the registered prefix, or new beads get IDs that route
+// elsewhere.
+type RigPrefixCheck struct {
+	BaseCheck
+}
+
+// NewRigPrefixCheck creates a new rig prefix check.
+func NewRigPrefixCheck() *RigPrefixCheck {
+	return &RigPrefixCheck{
+		BaseCheck: BaseCheck{
+			CheckName:        "rig-prefixes",
+			CheckDescription: "Check each rig has a unique beads prefix matching its database",
+			CheckCategory:    CategoryConfig,
+		},
+	}
+}
+
+// Run checks the prefixes registered in rigs.json.
+func (c *RigPrefixCheck) Run(ctx *CheckContext) *CheckResult {
+	rigsConfig, err := loadRigsConfig(filepath.Join(ctx.TownRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: "No rigs.json found (nothing to check)",
+		}
+	}
+
+	rigNames := make([]string, 0, len(rigsConfig.Rigs))
+	for name := range rigsConfig.Rigs {
+		rigNames = append(rigNames, name)
+	}
+	sort.Strings(rigNames)
+
+	var problems []string
+	owners := make(map[string][]string) // prefix -> rigs registering it
+	for _, rigName := range rigNames {
+		entry := rigsConfig.Rigs[rigName]
+		if entry.BeadsConfig == nil || entry.BeadsConfig.Prefix == "" {
+			problems = append(problems, fmt.Sprintf("Rig '%s' has no beads prefix in rigs.json", rigName))
+			continue
+		}
+		prefix := entry.BeadsConfig.Prefix
+		owners[prefix] = append(owners[prefix], rigName)
+
+		if prefix == beads.TownBeadsPrefix {
+			problems = append(problems, fmt.Sprintf("Rig '%s' uses '%s', which is reserved for town beads", rigName, prefix))
+		}
+
+		rigBeadsDir := beads.ResolveBeadsDir(filepath.Join(ctx.TownRoot, rigName, "mayor", "rig"))
+		if dbPrefix := beadsConfigPrefix(rigBeadsDir); dbPrefix != "" && dbPrefix != prefix {
+			problems = append(problems, fmt.Sprintf("Rig '%s': rigs.json says '%s', but its beads database creates '%s-' IDs",
+				rigName, prefix, dbPrefix))
+		}
+	}
+
+	prefixes := make([]string, 0, len(owners))
+	for prefix := range owners {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		if rigs := owners[prefix]; len(rigs) > 1 {
+			problems = append(problems, fmt.Sprintf("Prefix '%s' is shared by rigs: %s", prefix, strings.Join(rigs, ", ")))
+		}
+	}
+
+	if len(problems) == 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: fmt.Sprintf("%d rig(s) have unique beads prefixes", len(rigNames)),
+		}
+	}
+
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  StatusError,
+		Message: fmt.Sprintf("%d rig prefix problem(s)", len(problems)),
+		Details: problems,
+		FixHint: "Give each rig its own prefix in mayor/rigs.json and re-initialize its beads with 'bd init --prefix <prefix>'",
+	}
+}
+
+// beadsConfigPrefix reads the issue prefix a beads database was initialized
+// with from its config.yaml. Returns "" if it can't be determined.
+func beadsConfigPrefix(beadsDir string) string {
+	data, err := os.ReadFile(filepath.Join(beadsDir, "config.yaml"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		for _, key := range []string{"issue-prefix:", "prefix:"} {
+			if value, ok := strings.CutPrefix(line, key); ok {
+				return strings.Trim(strings.TrimSpace(value), `"'`)
+			}
+		}
+	}
+	return ""
+}
+
 // rigsConfigEntry is a local type for loading rigs.json without importing config package
 // to avoid circular dependencies and keep the check self-contained.
 type rigsConfigEntry struct {
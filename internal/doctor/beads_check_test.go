@@ -318,6 +318,73 @@ func TestPrefixMismatchCheck_Fix(t *testing.T) {
 	}
 }
 
+func TestRigPrefixCheck(t *testing.T) {
+	tmpDir := t.TempDir()
+	mayorDir := filepath.Join(tmpDir, "mayor")
+	if err := os.MkdirAll(mayorDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	rigsContent := `{
+		"version": 1,
+		"rigs": {
+			"gastown": {"beads": {"prefix": "gt"}},
+			"greentree": {"beads": {"prefix": "gt"}},
+			"beads": {"beads": {"prefix": "bd"}},
+			"hub": {"beads": {"prefix": "hq"}},
+			"bare": {}
+		}
+	}`
+	if err := os.WriteFile(filepath.Join(mayorDir, "rigs.json"), []byte(rigsContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The beads rig's database was initialized with another prefix.
+	rigBeadsDir := filepath.Join(tmpDir, "beads", "mayor", "rig", ".beads")
+	if err := os.MkdirAll(rigBeadsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rigBeadsDir, "config.yaml"), []byte("issue-prefix: be\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	check := NewRigPrefixCheck()
+	result := check.Run(&CheckContext{TownRoot: tmpDir})
+
+	if result.Status != StatusError {
+		t.Fatalf("expected StatusError, got %v: %s", result.Status, result.Message)
+	}
+	want := []string{
+		"Rig 'bare' has no beads prefix in rigs.json",
+		"Rig 'beads': rigs.json says 'bd', but its beads database creates 'be-' IDs",
+		"Rig 'hub' uses 'hq', which is reserved for town beads",
+		"Prefix 'gt' is shared by rigs: gastown, greentree",
+	}
+	if len(result.Details) != len(want) {
+		t.Fatalf("details = %q, want %q", result.Details, want)
+	}
+	for i := range want {
+		if result.Details[i] != want[i] {
+			t.Errorf("details[%d] = %q, want %q", i, result.Details[i], want[i])
+		}
+	}
+}
+
+func TestRigPrefixCheck_Unique(t *testing.T) {
+	tmpDir := t.TempDir()
+	mayorDir := filepath.Join(tmpDir, "mayor")
+	if err := os.MkdirAll(mayorDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	rigsContent := `{"version": 1, "rigs": {"gastown": {"beads": {"prefix": "gt"}}, "beads": {"beads": {"prefix": "bd"}}}}`
+	if err := os.WriteFile(filepath.Join(mayorDir, "rigs.json"), []byte(rigsContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := NewRigPrefixCheck().Run(&CheckContext{TownRoot: tmpDir})
+	if result.Status != StatusOK {
+		t.Errorf("expected StatusOK, got %v: %v", result.Status, result.Details)
+	}
+}
+
 func TestNewRoleLabelCheck(t *testing.T) {
 	check := NewRoleLabelCheck()
 
@@ -337,6 +337,7 @@ func (c *ClaudeSettingsCheck) checkSettings(path, _ string) []string {
 	// 2. PATH export in hooks
 	// 3. Stop hook with gt costs record (for autonomous)
 	// 4. gt nudge deacon session-started in SessionStart
+	// 5. PreToolUse hook with gt ratelimit check
 
 	// Check enabledPlugins
 	if _, ok := actual["enabledPlugins"]; !ok {
@@ -364,6 +365,11 @@ func (c *ClaudeSettingsCheck) checkSettings(path, _ string) []string {
 		missing = append(missing, "Stop hook")
 	}
 
+	// Check PreToolUse hook runs agents' shell commands past the rate limiter
+	if !c.hookHasPattern(hooks, "PreToolUse", "gt ratelimit check") {
+		missing = append(missing, "rate limit hook")
+	}
+
 	return missing
 }
 
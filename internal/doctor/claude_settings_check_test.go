@@ -56,6 +56,17 @@ func createValidSettings(t *testing.T, path string) {
 					},
 				},
 			},
+			"PreToolUse": []any{
+				map[string]any{
+					"matcher": "Bash",
+					"hooks": []any{
+						map[string]any{
+							"type":    "command",
+							"command": "gt ratelimit check",
+						},
+					},
+				},
+			},
 			"Stop": []any{
 				map[string]any{
 					"matcher": "**",
@@ -106,6 +117,17 @@ func createStaleSettings(t *testing.T, path string, missingElements ...string) {
 					},
 				},
 			},
+			"PreToolUse": []any{
+				map[string]any{
+					"matcher": "Bash",
+					"hooks": []any{
+						map[string]any{
+							"type":    "command",
+							"command": "gt ratelimit check",
+						},
+					},
+				},
+			},
 			"Stop": []any{
 				map[string]any{
 					"matcher": "**",
@@ -159,6 +181,9 @@ func createStaleSettings(t *testing.T, path string, missingElements ...string) {
 		case "Stop":
 			hooks := settings["hooks"].(map[string]any)
 			delete(hooks, "Stop")
+		case "PreToolUse":
+			hooks := settings["hooks"].(map[string]any)
+			delete(hooks, "PreToolUse")
 		}
 	}
 
@@ -401,6 +426,30 @@ func TestClaudeSettingsCheck_MissingStopHook(t *testing.T) {
 	}
 }
 
+func TestClaudeSettingsCheck_MissingRateLimitHook(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	mayorSettings := filepath.Join(tmpDir, "mayor", ".claude", "settings.json")
+	createStaleSettings(t, mayorSettings, "PreToolUse")
+
+	check := NewClaudeSettingsCheck()
+	result := check.Run(&CheckContext{TownRoot: tmpDir})
+
+	if result.Status != StatusError {
+		t.Errorf("expected StatusError for missing rate limit hook, got %v", result.Status)
+	}
+	found := false
+	for _, d := range result.Details {
+		if strings.Contains(d, "rate limit hook") {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("expected details to mention rate limit hook, got %v", result.Details)
+	}
+}
+
 func TestClaudeSettingsCheck_WrongLocationWitness(t *testing.T) {
 	tmpDir := t.TempDir()
 	rigName := "testrig"
@@ -0,0 +1,115 @@
+package doctor
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// InfraCloneSyncCheck detects infrastructure clones (mayor/rig, refinery/rig)
+// that have drifted from origin. Clones that are merely behind are fixable
+// by fast-forwarding; clones with local commits are errors, since those
+// commits would be lost by an automatic reset and need a decision.
+type InfraCloneSyncCheck struct {
+	FixableCheck
+}
+
+// NewInfraCloneSyncCheck creates a new infrastructure clone sync check.
+func NewInfraCloneSyncCheck() *InfraCloneSyncCheck {
+	return &InfraCloneSyncCheck{
+		FixableCheck: FixableCheck{
+			BaseCheck: BaseCheck{
+				CheckName:        "infra-clone-sync",
+				CheckDescription: "Detect mayor/refinery clones behind or diverged from origin",
+				CheckCategory:    CategoryRig,
+			},
+		},
+	}
+}
+
+// Run inspects each rig's infrastructure clones against their
+// remote-tracking refs (no fetch; run with --fix to fetch and sync).
+func (c *InfraCloneSyncCheck) Run(ctx *CheckContext) *CheckResult {
+	var behind, diverged []string
+	checked := 0
+
+	for _, rigPath := range infraCheckRigPaths(ctx) {
+		for _, path := range rig.InfraClonePaths(rigPath) {
+			s, err := rig.InspectClone(rigPath, path, false)
+			if err != nil {
+				continue // Missing origin ref, detached HEAD: not this check's concern
+			}
+			checked++
+			rel, _ := filepath.Rel(ctx.TownRoot, path)
+			switch {
+			case s.NeedsEscalation():
+				diverged = append(diverged, fmt.Sprintf("%s: %s", rel, s))
+			case s.State == rig.CloneBehind && !s.ReportOnly: // the Refinery updates refinery/rig itself
+				behind = append(behind, fmt.Sprintf("%s: %s", rel, s))
+			}
+		}
+	}
+
+	if len(diverged) > 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusError,
+			Message: fmt.Sprintf("%d infrastructure clone(s) have local commits", len(diverged)),
+			Details: append(diverged, behind...),
+			FixHint: "Inspect with 'git log origin/main..HEAD'; push or discard the commits, then run 'gt doctor --fix'",
+		}
+	}
+	if len(behind) > 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusWarning,
+			Message: fmt.Sprintf("%d infrastructure clone(s) behind origin", len(behind)),
+			Details: behind,
+			FixHint: "Run 'gt doctor --fix' to fetch and fast-forward",
+		}
+	}
+	if checked == 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: "No infrastructure clones found",
+		}
+	}
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  StatusOK,
+		Message: fmt.Sprintf("All %d infrastructure clones in sync with origin", checked),
+	}
+}
+
+// Fix fetches origin and fast-forwards clones that are safely behind.
+// Clones with local commits or uncommitted changes are left alone.
+func (c *InfraCloneSyncCheck) Fix(ctx *CheckContext) error {
+	var lastErr error
+	for _, rigPath := range infraCheckRigPaths(ctx) {
+		if _, err := rig.SyncInfraClones(rigPath); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// infraCheckRigPaths returns the rig to check (--rig) or every registered rig.
+func infraCheckRigPaths(ctx *CheckContext) []string {
+	if ctx.RigName != "" {
+		return []string{ctx.RigPath()}
+	}
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(ctx.TownRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for name := range rigsConfig.Rigs {
+		paths = append(paths, filepath.Join(ctx.TownRoot, name))
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,448 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// Onboarding checks walk the chain a new rig depends on, from the remote to
+// the Refinery's push. They talk to the network and run the rig's tests, so
+// they run only from gt rig doctor, not gt doctor.
+
+// remoteTimeout bounds each call to the rig's remote.
+const remoteTimeout = 30 * time.Second
+
+// testTimeout bounds the test run on the default branch.
+const testTimeout = 15 * time.Minute
+
+// rigRepoDir returns the git repository polecat worktrees are created from:
+// the shared bare repo if the rig has one, otherwise the mayor's clone.
+func rigRepoDir(rigPath string) string {
+	bare := filepath.Join(rigPath, ".repo.git")
+	if _, err := os.Stat(bare); err == nil {
+		return bare
+	}
+	return filepath.Join(rigPath, "mayor", "rig")
+}
+
+// rigDefaultBranch returns the rig's configured default branch.
+func rigDefaultBranch(rigPath string) string {
+	cfg, err := rig.LoadRigConfig(rigPath)
+	if err != nil || cfg.DefaultBranch == "" {
+		return "main"
+	}
+	return cfg.DefaultBranch
+}
+
+// gitOutput runs git in dir with a timeout and returns its trimmed output,
+// folding stderr into the error.
+func gitOutput(timeout time.Duration, dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return "", fmt.Errorf("%s", lastLine(msg))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// lastLine returns the last line of s, which for git is usually the error.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return lines[len(lines)-1]
+}
+
+// withScratchWorktree checks out ref in a temporary detached worktree of the
+// rig's repository, runs fn in it, and removes the worktree.
+func withScratchWorktree(rigPath, ref string, fn func(dir string) error) error {
+	repo := rigRepoDir(rigPath)
+	tmp, err := os.MkdirTemp("", "gt-rig-doctor-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	dir := filepath.Join(tmp, "worktree")
+	if _, err := gitOutput(remoteTimeout, repo, "worktree", "add", "--detach", dir, ref); err != nil {
+		return fmt.Errorf("git worktree add: %w", err)
+	}
+	defer func() {
+		_, _ = gitOutput(remoteTimeout, repo, "worktree", "remove", "--force", dir)
+		_, _ = gitOutput(remoteTimeout, repo, "worktree", "prune")
+	}()
+	return fn(dir)
+}
+
+// defaultBranchRef returns the ref of the rig's default branch as the rig's
+// repository sees it: origin/<branch> in a clone, <branch> in a bare repo.
+func defaultBranchRef(rigPath string) (string, error) {
+	repo := rigRepoDir(rigPath)
+	branch := rigDefaultBranch(rigPath)
+	for _, ref := range []string{"origin/" + branch, branch} {
+		if _, err := gitOutput(remoteTimeout, repo, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err == nil {
+			return ref, nil
+		}
+	}
+	return "", fmt.Errorf("branch %s not found in %s", branch, repo)
+}
+
+// RigOriginReachableCheck verifies the rig's origin remote answers.
+type RigOriginReachableCheck struct {
+	BaseCheck
+}
+
+// NewRigOriginReachableCheck creates a new origin reachability check.
+func NewRigOriginReachableCheck() *RigOriginReachableCheck {
+	return &RigOriginReachableCheck{
+		BaseCheck: BaseCheck{
+			CheckName:        "rig-origin-reachable",
+			CheckDescription: "Verify the rig's origin remote is reachable",
+			CheckCategory:    CategoryRig,
+		},
+	}
+}
+
+// Run lists origin's branches.
+func (c *RigOriginReachableCheck) Run(ctx *CheckContext) *CheckResult {
+	repo := rigRepoDir(ctx.RigPath())
+	url, err := gitOutput(remoteTimeout, repo, "remote", "get-url", "origin")
+	if err != nil {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusError,
+			Message: "No origin remote",
+			Details: []string{err.Error()},
+			FixHint: fmt.Sprintf("git -C %s remote add origin <url>", repo),
+		}
+	}
+	if _, err := gitOutput(remoteTimeout, repo, "ls-remote", "--heads", "origin"); err != nil {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusError,
+			Message: fmt.Sprintf("Cannot reach origin (%s)", url),
+			Details: []string{err.Error()},
+			FixHint: "Check the URL, network access, and credentials (ssh-agent or credential helper)",
+		}
+	}
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  StatusOK,
+		Message: fmt.Sprintf("origin reachable (%s)", url),
+	}
+}
+
+// RigDefaultBranchCheck verifies the rig's configured default branch
+// exists on origin and matches origin's HEAD.
+type RigDefaultBranchCheck struct {
+	FixableCheck
+	remoteHead string // origin's HEAD branch, cached for Fix
+}
+
+// NewRigDefaultBranchCheck creates a new default branch check.
+func NewRigDefaultBranchCheck() *RigDefaultBranchCheck {
+	return &RigDefaultBranchCheck{
+		FixableCheck: FixableCheck{
+			BaseCheck: BaseCheck{
+				CheckName:        "rig-default-branch",
+				CheckDescription: "Verify the rig's default branch matches origin",
+				CheckCategory:    CategoryRig,
+			},
+		},
+	}
+}
+
+// Run compares default_branch in the rig config with origin.
+func (c *RigDefaultBranchCheck) Run(ctx *CheckContext) *CheckResult {
+	c.remoteHead = ""
+	repo := rigRepoDir(ctx.RigPath())
+	branch := rigDefaultBranch(ctx.RigPath())
+
+	out, err := gitOutput(remoteTimeout, repo, "ls-remote", "--symref", "origin", "HEAD")
+	if err != nil {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusWarning,
+			Message: "Cannot read origin's HEAD",
+			Details: []string{err.Error()},
+		}
+	}
+	c.remoteHead = parseSymrefHead(out)
+
+	exists, err := gitOutput(remoteTimeout, repo, "ls-remote", "--heads", "origin", branch)
+	if err == nil && exists == "" {
+		hint := "Set default_branch in the rig's config.json"
+		if c.remoteHead != "" {
+			hint = fmt.Sprintf("Run gt rig doctor --fix to use %s, origin's default", c.remoteHead)
+		}
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusError,
+			Message: fmt.Sprintf("Default branch %s does not exist on origin", branch),
+			FixHint: hint,
+		}
+	}
+	if c.remoteHead != "" && c.remoteHead != branch {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusWarning,
+			Message: fmt.Sprintf("Default branch is %s but origin's default is %s", branch, c.remoteHead),
+			Details: []string{"Polecats branch from and the Refinery merges to the configured branch"},
+			FixHint: fmt.Sprintf("Run gt rig doctor --fix to use %s, or ignore if %s is intended", c.remoteHead, branch),
+		}
+	}
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  StatusOK,
+		Message: fmt.Sprintf("Default branch %s matches origin", branch),
+	}
+}
+
+// Fix sets default_branch in the rig config to origin's HEAD, keeping the
+// config's other fields as they are.
+func (c *RigDefaultBranchCheck) Fix(ctx *CheckContext) error {
+	if c.remoteHead == "" {
+		return fmt.Errorf("origin's default branch is unknown")
+	}
+	path := filepath.Join(ctx.RigPath(), "config.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var cfg map[string]any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	cfg["default_branch"] = c.remoteHead
+	data, err = json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// parseSymrefHead extracts the branch from `git ls-remote --symref origin
+// HEAD` output ("ref: refs/heads/main\tHEAD").
+func parseSymrefHead(out string) string {
+	for _, line := range strings.Split(out, "\n") {
+		ref, ok := strings.CutPrefix(line, "ref: ")
+		if !ok {
+			continue
+		}
+		ref, _, _ = strings.Cut(ref, "\t")
+		return strings.TrimPrefix(ref, "refs/heads/")
+	}
+	return ""
+}
+
+// RigWorktreeCheck verifies a polecat worktree can be created from the rig's
+// repository on its default branch.
+type RigWorktreeCheck struct {
+	BaseCheck
+}
+
+// NewRigWorktreeCheck creates a new worktree creation check.
+func NewRigWorktreeCheck() *RigWorktreeCheck {
+	return &RigWorktreeCheck{
+		BaseCheck: BaseCheck{
+			CheckName:        "rig-worktree",
+			CheckDescription: "Verify polecat worktrees can be created",
+			CheckCategory:    CategoryRig,
+		},
+	}
+}
+
+// Run creates and removes a scratch worktree.
+func (c *RigWorktreeCheck) Run(ctx *CheckContext) *CheckResult {
+	ref, err := defaultBranchRef(ctx.RigPath())
+	if err != nil {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusError,
+			Message: "Default branch not fetched",
+			Details: []string{err.Error()},
+			FixHint: fmt.Sprintf("git -C %s fetch origin", rigRepoDir(ctx.RigPath())),
+		}
+	}
+	if err := withScratchWorktree(ctx.RigPath(), ref, func(string) error { return nil }); err != nil {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusError,
+			Message: "Cannot create a worktree",
+			Details: []string{err.Error()},
+			FixHint: "Run git worktree prune in the rig's repository and check disk space",
+		}
+	}
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  StatusOK,
+		Message: fmt.Sprintf("Worktree created from %s", ref),
+	}
+}
+
+// RigTestCommandCheck runs the merge queue's test command on the default
+// branch, which must be green before the Refinery can merge anything.
+type RigTestCommandCheck struct {
+	BaseCheck
+}
+
+// NewRigTestCommandCheck creates a new test command check.
+func NewRigTestCommandCheck() *RigTestCommandCheck {
+	return &RigTestCommandCheck{
+		BaseCheck: BaseCheck{
+			CheckName:        "rig-test-command",
+			CheckDescription: "Verify the test command passes on the default branch",
+			CheckCategory:    CategoryRig,
+		},
+	}
+}
+
+// Run runs the test command in a scratch worktree of the default branch.
+func (c *RigTestCommandCheck) Run(ctx *CheckContext) *CheckResult {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(ctx.RigPath()))
+	if err != nil || settings.MergeQueue == nil || !settings.MergeQueue.RunTests || settings.MergeQueue.TestCommand == "" {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusWarning,
+			Message: "No test command configured; the Refinery merges untested",
+			FixHint: "Set merge_queue.test_command and run_tests in the rig's settings/config.json",
+		}
+	}
+	testCmd := settings.MergeQueue.TestCommand
+
+	ref, err := defaultBranchRef(ctx.RigPath())
+	if err != nil {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusError,
+			Message: "Default branch not fetched",
+			Details: []string{err.Error()},
+		}
+	}
+
+	var output string
+	runErr := withScratchWorktree(ctx.RigPath(), ref, func(dir string) error {
+		tctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(tctx, "sh", "-c", testCmd)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		output = string(out)
+		if tctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timed out after %s", testTimeout)
+		}
+		return err
+	})
+	if runErr != nil {
+		result := &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusError,
+			Message: fmt.Sprintf("%s fails on %s", testCmd, ref),
+			Details: []string{runErr.Error()},
+			FixHint: "Fix the tests on the default branch, or correct merge_queue.test_command",
+		}
+		if tail := lastLines(output, 10); tail != "" {
+			result.Details = append(result.Details, strings.Split(tail, "\n")...)
+		}
+		return result
+	}
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  StatusOK,
+		Message: fmt.Sprintf("%s passes on %s", testCmd, ref),
+	}
+}
+
+// lastLines returns the last n lines of s.
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RigRefineryPushCheck verifies the Refinery's clone may push to origin's
+// default branch, without pushing anything.
+type RigRefineryPushCheck struct {
+	BaseCheck
+}
+
+// NewRigRefineryPushCheck creates a new refinery push check.
+func NewRigRefineryPushCheck() *RigRefineryPushCheck {
+	return &RigRefineryPushCheck{
+		BaseCheck: BaseCheck{
+			CheckName:        "rig-refinery-push",
+			CheckDescription: "Verify the Refinery can push to the default branch",
+			CheckCategory:    CategoryRig,
+		},
+	}
+}
+
+// Run dry-runs a push of the default branch to itself.
+func (c *RigRefineryPushCheck) Run(ctx *CheckContext) *CheckResult {
+	dir := filepath.Join(ctx.RigPath(), "refinery", "rig")
+	if _, err := os.Stat(dir); err != nil {
+		dir = rigRepoDir(ctx.RigPath())
+	}
+	branch := rigDefaultBranch(ctx.RigPath())
+	ref, err := defaultBranchRef(ctx.RigPath())
+	if err != nil {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusError,
+			Message: "Default branch not fetched",
+			Details: []string{err.Error()},
+		}
+	}
+
+	// Pushing origin's own tip is a no-op that still authenticates and
+	// checks write access.
+	if _, err := gitOutput(remoteTimeout, dir, "push", "--dry-run", "--porcelain", "origin", ref+":refs/heads/"+branch); err != nil {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusError,
+			Message: fmt.Sprintf("Refinery cannot push to %s", branch),
+			Details: []string{err.Error()},
+			FixHint: "Grant the Refinery's credentials write access to origin (and check branch protection)",
+		}
+	}
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  StatusOK,
+		Message: fmt.Sprintf("Refinery can push to %s", branch),
+	}
+}
+
+// OnboardingChecks returns the checks run by gt rig doctor, in chain order.
+// skipTests leaves out the test run on the default branch.
+func OnboardingChecks(skipTests bool) []Check {
+	checks := []Check{
+		NewRigIsGitRepoCheck(),
+		NewRigOriginReachableCheck(),
+		NewRigDefaultBranchCheck(),
+		NewRigWorktreeCheck(),
+	}
+	if !skipTests {
+		checks = append(checks, NewRigTestCommandCheck())
+	}
+	return append(checks,
+		NewBeadsRedirectCheck(),
+		NewRigRefineryPushCheck(),
+	)
+}
@@ -0,0 +1,132 @@
+package doctor
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupOnboardingRig creates a town with rig "demo" whose mayor/rig clones a
+// local origin that has one commit on main.
+func setupOnboardingRig(t *testing.T, defaultBranch, testCmd string) *CheckContext {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	townRoot := t.TempDir()
+	origin := filepath.Join(townRoot, "origin.git")
+	seed := filepath.Join(townRoot, "seed")
+	rigPath := filepath.Join(townRoot, "demo")
+
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	git(townRoot, "init", "--bare", "--initial-branch=main", origin)
+	git(townRoot, "init", "--initial-branch=main", seed)
+	if err := os.WriteFile(filepath.Join(seed, "README"), []byte("demo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(seed, "add", "README")
+	git(seed, "commit", "-m", "initial")
+	git(seed, "push", origin, "main")
+	if err := os.MkdirAll(filepath.Join(rigPath, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	git(townRoot, "clone", origin, filepath.Join(rigPath, "mayor", "rig"))
+
+	writeJSON := func(path string, v any) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := json.Marshal(v)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeJSON(filepath.Join(rigPath, "config.json"), map[string]any{"type": "rig", "name": "demo", "default_branch": defaultBranch})
+	if testCmd != "" {
+		writeJSON(filepath.Join(rigPath, "settings", "config.json"), map[string]any{
+			"type":        "rig-settings",
+			"version":     1,
+			"merge_queue": map[string]any{"run_tests": true, "test_command": testCmd},
+		})
+	}
+	return &CheckContext{TownRoot: townRoot, RigName: "demo"}
+}
+
+func TestOnboardingChecksHealthyRig(t *testing.T) {
+	ctx := setupOnboardingRig(t, "main", "test -f README")
+	for _, check := range []Check{
+		NewRigOriginReachableCheck(),
+		NewRigDefaultBranchCheck(),
+		NewRigWorktreeCheck(),
+		NewRigTestCommandCheck(),
+		NewRigRefineryPushCheck(),
+	} {
+		if result := check.Run(ctx); result.Status != StatusOK {
+			t.Errorf("%s: %s %v", check.Name(), result.Message, result.Details)
+		}
+	}
+
+	// The scratch worktrees are cleaned up.
+	out, err := exec.Command("git", "-C", filepath.Join(ctx.RigPath(), "mayor", "rig"), "worktree", "list").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(strings.Split(strings.TrimSpace(string(out)), "\n")); n != 1 {
+		t.Errorf("worktrees left behind:\n%s", out)
+	}
+}
+
+func TestRigDefaultBranchCheckFix(t *testing.T) {
+	ctx := setupOnboardingRig(t, "master", "")
+	check := NewRigDefaultBranchCheck()
+
+	result := check.Run(ctx)
+	if result.Status != StatusError || !strings.Contains(result.Message, "master") {
+		t.Fatalf("Run = %v %q, want error about master", result.Status, result.Message)
+	}
+	if err := check.Fix(ctx); err != nil {
+		t.Fatalf("Fix: %v", err)
+	}
+	if got := rigDefaultBranch(ctx.RigPath()); got != "main" {
+		t.Errorf("default branch after fix = %q, want main", got)
+	}
+	if result := check.Run(ctx); result.Status != StatusOK {
+		t.Errorf("after fix: %v %q", result.Status, result.Message)
+	}
+}
+
+func TestRigTestCommandCheck(t *testing.T) {
+	ctx := setupOnboardingRig(t, "main", "echo boom; exit 1")
+	result := NewRigTestCommandCheck().Run(ctx)
+	if result.Status != StatusError {
+		t.Fatalf("failing tests: status = %v, want error", result.Status)
+	}
+	if !strings.Contains(strings.Join(result.Details, "\n"), "boom") {
+		t.Errorf("test output missing from details: %v", result.Details)
+	}
+
+	ctx = setupOnboardingRig(t, "main", "")
+	if result := NewRigTestCommandCheck().Run(ctx); result.Status != StatusWarning {
+		t.Errorf("no test command: status = %v, want warning", result.Status)
+	}
+}
+
+func TestParseSymrefHead(t *testing.T) {
+	out := "ref: refs/heads/trunk\tHEAD\nabc123\tHEAD"
+	if got := parseSymrefHead(out); got != "trunk" {
+		t.Errorf("parseSymrefHead = %q, want trunk", got)
+	}
+	if got := parseSymrefHead("abc123\tHEAD"); got != "" {
+		t.Errorf("parseSymrefHead without symref = %q, want empty", got)
+	}
+}
@@ -1,7 +1,6 @@
 package dog
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -11,6 +10,7 @@ import (
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/runstate"
 )
 
 // Common errors
@@ -146,11 +146,17 @@ func (m *Manager) createRigWorktree(dogPath, dogName, rigName string) (string, e
 	// Unique branch per dog-rig combination
 	branchName := fmt.Sprintf("dog/%s-%s-%d", dogName, rigName, time.Now().UnixMilli())
 
-	// Create worktree with new branch from default branch
-	if err := repoGit.WorktreeAddFromRef(worktreePath, branchName, startPoint); err != nil {
+	// Create worktree with new branch from default branch (LFS-aware)
+	wtGit := rig.WorktreeGit(rigPath, repoGit, startPoint)
+	if err := wtGit.WorktreeAddFromRef(worktreePath, branchName, startPoint); err != nil {
 		return "", fmt.Errorf("creating worktree from %s: %w", startPoint, err)
 	}
 
+	// Populate submodules (non-fatal: the dog can still work without them)
+	if err := rig.UpdateSubmodules(rigPath, worktreePath); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
 	return worktreePath, nil
 }
 
@@ -160,7 +166,7 @@ func (m *Manager) findRepoBase(rigPath string) (*git.Git, error) {
 	// Check for shared bare repo
 	bareRepoPath := filepath.Join(rigPath, ".repo.git")
 	if info, err := os.Stat(bareRepoPath); err == nil && info.IsDir() {
-		return git.NewGitWithDir(bareRepoPath, ""), nil
+		return rig.WithGitAuth(rigPath, git.NewGitWithDir(bareRepoPath, "")), nil
 	}
 
 	// Fall back to mayor/rig
@@ -168,7 +174,7 @@ func (m *Manager) findRepoBase(rigPath string) (*git.Git, error) {
 	if _, err := os.Stat(mayorPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("no repo base found (neither .repo.git nor mayor/rig exists)")
 	}
-	return git.NewGit(mayorPath), nil
+	return rig.WithGitAuth(rigPath, git.NewGit(mayorPath)), nil
 }
 
 // Remove deletes a dog from the kennel.
@@ -272,16 +278,9 @@ func (m *Manager) SetState(name string, state State) error {
 		return ErrDogNotFound
 	}
 
-	dogState, err := m.loadState(name)
-	if err != nil {
-		return fmt.Errorf("loading state: %w", err)
-	}
-
-	dogState.State = state
-	dogState.LastActive = time.Now()
-	dogState.UpdatedAt = time.Now()
-
-	return m.saveState(name, dogState)
+	return m.touchState(name, func(dogState *DogState) {
+		dogState.State = state
+	})
 }
 
 // AssignWork assigns work to a dog and sets it to working state.
@@ -290,17 +289,10 @@ func (m *Manager) AssignWork(name, work string) error {
 		return ErrDogNotFound
 	}
 
-	state, err := m.loadState(name)
-	if err != nil {
-		return fmt.Errorf("loading state: %w", err)
-	}
-
-	state.State = StateWorking
-	state.Work = work
-	state.LastActive = time.Now()
-	state.UpdatedAt = time.Now()
-
-	return m.saveState(name, state)
+	return m.touchState(name, func(state *DogState) {
+		state.State = StateWorking
+		state.Work = work
+	})
 }
 
 // ClearWork clears a dog's work assignment and sets it to idle.
@@ -309,17 +301,10 @@ func (m *Manager) ClearWork(name string) error {
 		return ErrDogNotFound
 	}
 
-	state, err := m.loadState(name)
-	if err != nil {
-		return fmt.Errorf("loading state: %w", err)
-	}
-
-	state.State = StateIdle
-	state.Work = ""
-	state.LastActive = time.Now()
-	state.UpdatedAt = time.Now()
-
-	return m.saveState(name, state)
+	return m.touchState(name, func(state *DogState) {
+		state.State = StateIdle
+		state.Work = ""
+	})
 }
 
 // Refresh recreates all worktrees for a dog with fresh branches.
@@ -355,7 +340,10 @@ func (m *Manager) Refresh(name string) error {
 			_ = repoGit.WorktreePrune()
 		}
 
-		// Fetch latest from origin
+		// Fetch latest from origin (refreshing the shared mirror first, if any)
+		if err := rig.RefreshMirror(rigPath); err != nil {
+			fmt.Printf("Warning: could not refresh shared mirror for %s: %v\n", rigName, err)
+		}
 		_ = repoGit.Fetch("origin")
 
 		// Create fresh worktree
@@ -406,7 +394,10 @@ func (m *Manager) RefreshRig(name, rigName string) error {
 		_ = repoGit.WorktreePrune()
 	}
 
-	// Fetch latest
+	// Fetch latest (refreshing the shared mirror first, if any)
+	if err := rig.RefreshMirror(rigPath); err != nil {
+		fmt.Printf("Warning: could not refresh shared mirror for %s: %v\n", rigName, err)
+	}
 	_ = repoGit.Fetch("origin")
 
 	// Create fresh worktree
@@ -494,29 +485,39 @@ func (m *Manager) cleanupStaleBranchesForRig(repoGit *git.Git, rigName string) (
 	return deleted, nil
 }
 
+// stateDoc returns a dog's .dog.json state document. A missing state file
+// loads as nil.
+func (m *Manager) stateDoc(name string) *runstate.Doc[DogState] {
+	return runstate.NewDocAt(m.stateFilePath(name), func() *DogState { return nil })
+}
+
 // loadState loads a dog's state from .dog.json.
 func (m *Manager) loadState(name string) (*DogState, error) {
-	data, err := os.ReadFile(m.stateFilePath(name))
-	if err != nil {
-		return nil, err
+	state, err := m.stateDoc(name).Load()
+	if err == nil && state == nil {
+		return nil, fmt.Errorf("%s: %w", m.stateFilePath(name), os.ErrNotExist)
 	}
-
-	var state DogState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, err
-	}
-
-	return &state, nil
+	return state, err
 }
 
 // saveState saves a dog's state to .dog.json.
 func (m *Manager) saveState(name string, state *DogState) error {
-	data, err := json.MarshalIndent(state, "", "  ")
-	if err != nil {
-		return err
-	}
+	return m.stateDoc(name).Save(state)
+}
 
-	return os.WriteFile(m.stateFilePath(name), data, 0644) //nolint:gosec // G306: dog state is non-sensitive operational data
+// touchState applies fn to a dog's state and marks it active, holding the
+// state file's lock so concurrent updates aren't lost.
+func (m *Manager) touchState(name string, fn func(state *DogState)) error {
+	_, err := m.stateDoc(name).Update(func(state *DogState) error {
+		if state == nil {
+			return fmt.Errorf("loading state: %s: %w", m.stateFilePath(name), os.ErrNotExist)
+		}
+		fn(state)
+		state.LastActive = time.Now()
+		state.UpdatedAt = time.Now()
+		return nil
+	})
+	return err
 }
 
 // GetIdleDog returns an idle dog suitable for work assignment.
@@ -2,6 +2,10 @@
 //
 // Events are written to ~/gt/.events.jsonl (raw audit log) and later
 // curated by the feed daemon into ~/.feed.jsonl (user-facing).
+//
+// Event is the one structured envelope for every subsystem: gt commands,
+// the town log, and the refinery's merge pipeline all append Events to the
+// same log, so consumers need only Parse.
 package events
 
 import (
@@ -12,15 +16,19 @@ import (
 	"sync"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/identity"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 // Event represents an activity event in Gas Town.
 type Event struct {
-	Timestamp  string                 `json:"ts"`
-	Source     string                 `json:"source"`
-	Type       string                 `json:"type"`
-	Actor      string                 `json:"actor"`
+	Timestamp  string                 `json:"ts"`                 // RFC3339, UTC
+	Source     string                 `json:"source"`             // Emitting subsystem: "gt", "townlog", "refinery"
+	Type       string                 `json:"type"`               // e.g. "sling", "spawn", "merge_failed"
+	Actor      string                 `json:"actor"`              // Agent address, e.g. "gastown/refinery"
+	Rig        string                 `json:"rig,omitempty"`      // Rig the event belongs to, if any
+	Subject    string                 `json:"subject,omitempty"`  // What it is about: bead ID, MR, or branch
+	TraceID    string                 `json:"trace_id,omitempty"` // Trace of the operation, when traced
 	Payload    map[string]interface{} `json:"payload,omitempty"`
 	Visibility string                 `json:"visibility"`
 }
@@ -35,6 +43,7 @@ const (
 // Common event types for gt commands.
 const (
 	TypeSling   = "sling"
+	TypeClaim   = "claim" // Polecat pulled a bead from the ready queue
 	TypeHook    = "hook"
 	TypeUnhook  = "unhook"
 	TypeHandoff = "handoff"
@@ -53,6 +62,7 @@ const (
 	// Session death events (for crash investigation)
 	TypeSessionDeath = "session_death" // Feed-visible session termination
 	TypeMassDeath    = "mass_death"    // Multiple sessions died in short window
+	TypeRestart      = "restart"       // Daemon restarted a dead agent session
 
 	// Witness patrol events
 	TypePatrolStarted   = "patrol_started"
@@ -68,11 +78,35 @@ const (
 	TypeMerged       = "merged"
 	TypeMergeFailed  = "merge_failed"
 	TypeMergeSkipped = "merge_skipped"
+	TypeQueuePaused  = "queue_paused"
+	TypeQueueResumed = "queue_resumed"
+
+	// Alert events (emitted by the daemon's alert rules)
+	TypeAlertFired    = "alert_fired"
+	TypeAlertResolved = "alert_resolved"
+
+	// Protocol delivery events
+	TypeProtocolUnacked = "protocol_unacked" // Protocol message never acknowledged, or rejected
+
+	// Inbound webhook events (emitted by the daemon's webhook receiver)
+	TypeWebhookReceived = "webhook_received"
 )
 
 // EventsFile is the name of the raw events log.
 const EventsFile = ".events.jsonl"
 
+// LineTime returns the timestamp of a raw events log line, for log rotation.
+func LineTime(line []byte) (time.Time, bool) {
+	var event struct {
+		Timestamp string `json:"ts"`
+	}
+	if err := json.Unmarshal(line, &event); err != nil {
+		return time.Time{}, false
+	}
+	ts, err := time.Parse(time.RFC3339, event.Timestamp)
+	return ts, err == nil
+}
+
 // mutex protects concurrent writes to the events file.
 var mutex sync.Mutex
 
@@ -80,15 +114,90 @@ var mutex sync.Mutex
 // The event is appended to ~/gt/.events.jsonl.
 // Returns nil if logging fails (events are best-effort).
 func Log(eventType, actor string, payload map[string]interface{}, visibility string) error {
-	event := Event{
-		Timestamp:  time.Now().UTC().Format(time.RFC3339),
-		Source:     "gt",
+	return Emit(Event{
 		Type:       eventType,
 		Actor:      actor,
 		Payload:    payload,
 		Visibility: visibility,
+	})
+}
+
+// Emit appends e to the events log of the current workspace, filling in
+// defaults (see Normalize). Returns nil outside a workspace.
+func Emit(e Event) error {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		// Silently ignore - we're not in a Gas Town workspace
+		return nil
+	}
+	return EmitTo(townRoot, e)
+}
+
+// EmitTo appends e to the events log of the town at townRoot.
+func EmitTo(townRoot string, e Event) error {
+	return write(filepath.Join(townRoot, EventsFile), Normalize(e))
+}
+
+// Normalize fills the envelope fields an emitter left empty: timestamp
+// (now), source ("gt"), visibility (feed), rig (from the payload's "rig" or
+// the actor's address), and subject (from the payload's bead, MR, issue, or
+// branch).
+func Normalize(e Event) Event {
+	if e.Timestamp == "" {
+		e.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	if e.Source == "" {
+		e.Source = "gt"
+	}
+	if e.Visibility == "" {
+		e.Visibility = VisibilityFeed
+	}
+	if e.Rig == "" {
+		if rig, ok := e.Payload["rig"].(string); ok && rig != "town" {
+			e.Rig = rig
+		} else {
+			e.Rig = RigFromActor(e.Actor)
+		}
 	}
-	return write(event)
+	if e.Subject == "" {
+		for _, key := range []string{"bead", "mr", "issue", "branch"} {
+			if v, ok := e.Payload[key].(string); ok && v != "" {
+				e.Subject = v
+				break
+			}
+		}
+	}
+	return e
+}
+
+// RigFromActor returns the rig of an agent such as "gastown/refinery" or
+// "gastown/polecats/toast", or "" for town-level agents (mayor, deacon,
+// dogs) and actors that aren't agents.
+func RigFromActor(actor string) string {
+	return identity.Rig(actor)
+}
+
+// Parse decodes one events log line, normalizing events written before the
+// envelope had rig and subject fields.
+func Parse(line []byte) (Event, error) {
+	var e Event
+	if err := json.Unmarshal(line, &e); err != nil {
+		return Event{}, err
+	}
+	if e.Type == "" {
+		return Event{}, fmt.Errorf("event has no type")
+	}
+	ts := e.Timestamp
+	e = Normalize(e)
+	e.Timestamp = ts // Don't invent a time for events that lack one
+	return e, nil
+}
+
+// Time returns the event's timestamp, or the zero time if it is missing
+// or malformed.
+func (e Event) Time() time.Time {
+	ts, _ := time.Parse(time.RFC3339, e.Timestamp)
+	return ts
 }
 
 // LogFeed is a convenience wrapper for feed-visible events.
@@ -102,16 +211,7 @@ func LogAudit(eventType, actor string, payload map[string]interface{}) error {
 }
 
 // write appends an event to the events file.
-func write(event Event) error {
-	// Find town root
-	townRoot, err := workspace.FindFromCwd()
-	if err != nil || townRoot == "" {
-		// Silently ignore - we're not in a Gas Town workspace
-		return nil
-	}
-
-	eventsPath := filepath.Join(townRoot, EventsFile)
-
+func write(eventsPath string, event Event) error {
 	// Marshal event to JSON
 	data, err := json.Marshal(event)
 	if err != nil {
@@ -146,6 +246,14 @@ func SlingPayload(beadID, target string) map[string]interface{} {
 	}
 }
 
+// ClaimPayload creates a payload for claim events.
+func ClaimPayload(beadID, rig string) map[string]interface{} {
+	return map[string]interface{}{
+		"bead": beadID,
+		"rig":  rig,
+	}
+}
+
 // HookPayload creates a payload for hook events.
 func HookPayload(beadID string) map[string]interface{} {
 	return map[string]interface{}{
@@ -164,11 +272,14 @@ func HandoffPayload(subject string, toSession bool) map[string]interface{} {
 	return p
 }
 
-// DonePayload creates a payload for done events.
-func DonePayload(beadID, branch string) map[string]interface{} {
+// DonePayload creates a payload for done events. exit is the gt done exit
+// status (COMPLETED, ESCALATED, DEFERRED, ...); only COMPLETED submits the
+// branch to the merge queue.
+func DonePayload(beadID, branch, exit string) map[string]interface{} {
 	return map[string]interface{}{
 		"bead":   beadID,
 		"branch": branch,
+		"exit":   exit,
 	}
 }
 
@@ -294,6 +405,69 @@ func SessionDeathPayload(session, agent, reason, caller string) map[string]inter
 	}
 }
 
+// RestartPayload creates a payload for restart events.
+// role: role of the restarted agent (deacon, witness, refinery, polecat)
+// agent: Gas Town agent identity (e.g., "gastown/witness")
+func RestartPayload(role, agent string) map[string]interface{} {
+	return map[string]interface{}{
+		"role":  role,
+		"agent": agent,
+	}
+}
+
+// QueuePausePayload creates a payload for queue_paused and queue_resumed events.
+func QueuePausePayload(rig, reason string) map[string]interface{} {
+	p := map[string]interface{}{
+		"rig": rig,
+	}
+	if reason != "" {
+		p["reason"] = reason
+	}
+	return p
+}
+
+// AlertPayload creates a payload for alert_fired and alert_resolved events.
+// rule: name of the alert rule
+// value: the observed value that crossed (or returned within) the threshold
+// summary: human-readable description of the condition
+func AlertPayload(rule string, value float64, summary string) map[string]interface{} {
+	return map[string]interface{}{
+		"rule":    rule,
+		"value":   value,
+		"summary": summary,
+	}
+}
+
+// ProtocolUnackedPayload creates a payload for protocol_unacked events.
+// msgType: protocol message type (e.g., "MERGE_FAILED")
+// to: the recipient that did not acknowledge it
+// reason: why it was escalated (no acknowledgment, or the NACK reason)
+func ProtocolUnackedPayload(msgType, to, subject, reason string, deliveries int) map[string]interface{} {
+	return map[string]interface{}{
+		"type":       msgType,
+		"to":         to,
+		"subject":    subject,
+		"reason":     reason,
+		"deliveries": deliveries,
+	}
+}
+
+// WebhookPayload creates a payload for webhook_received events.
+// source: the /hooks/<source> the event arrived on
+// rule: the webhook rule that matched it
+// bead: the bead created (or found, for a duplicate)
+// duplicate: whether the event repeated one already turned into a bead
+// slung: whether the bead is being slung to a rig
+func WebhookPayload(source, rule, bead string, duplicate, slung bool) map[string]interface{} {
+	return map[string]interface{}{
+		"source":    source,
+		"rule":      rule,
+		"bead":      bead,
+		"duplicate": duplicate,
+		"slung":     slung,
+	}
+}
+
 // MassDeathPayload creates a payload for mass death events.
 // count: number of sessions that died
 // window: time window in which deaths occurred (e.g., "5s")
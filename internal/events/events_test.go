@@ -0,0 +1,83 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalize_FillsEnvelope(t *testing.T) {
+	e := Normalize(Event{Type: TypeSling, Actor: "gastown/crew/max", Payload: SlingPayload("gt-abc", "gastown/polecats/toast")})
+	if e.Timestamp == "" || e.Time().IsZero() {
+		t.Errorf("Timestamp = %q, want now", e.Timestamp)
+	}
+	if e.Source != "gt" || e.Visibility != VisibilityFeed {
+		t.Errorf("Source, Visibility = %q, %q; want gt, feed", e.Source, e.Visibility)
+	}
+	if e.Rig != "gastown" || e.Subject != "gt-abc" {
+		t.Errorf("Rig, Subject = %q, %q; want gastown, gt-abc", e.Rig, e.Subject)
+	}
+
+	// Payload rig wins over the actor; explicit fields are kept
+	e = Normalize(Event{Type: TypeMergeFailed, Actor: "mayor", Source: "refinery", Subject: "mr-1", Payload: map[string]interface{}{"rig": "beads", "branch": "polecat/nux"}})
+	if e.Rig != "beads" || e.Subject != "mr-1" || e.Source != "refinery" {
+		t.Errorf("got Rig=%q Subject=%q Source=%q", e.Rig, e.Subject, e.Source)
+	}
+}
+
+func TestRigFromActor(t *testing.T) {
+	for actor, want := range map[string]string{
+		"gastown/refinery":       "gastown",
+		"gastown/polecats/toast": "gastown",
+		"mayor":                  "",
+		"mayor/":                 "",
+		"deacon/dogs/alpha":      "",
+		"":                       "",
+	} {
+		if got := RigFromActor(actor); got != want {
+			t.Errorf("RigFromActor(%q) = %q, want %q", actor, got, want)
+		}
+	}
+}
+
+func TestParse_LegacyAndEnvelopeLines(t *testing.T) {
+	// A line written before the envelope had source, rig, and subject
+	e, err := Parse([]byte(`{"ts":"2026-01-15T12:00:00Z","type":"merged","actor":"gastown/refinery","payload":{"branch":"polecat/nux"},"visibility":"feed"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Source != "gt" || e.Rig != "gastown" || e.Subject != "polecat/nux" || e.Time().IsZero() {
+		t.Errorf("legacy line parsed as %+v", e)
+	}
+
+	// A line without a timestamp keeps none
+	e, err = Parse([]byte(`{"type":"spawn","actor":"gastown/polecats/toast"}`))
+	if err != nil || e.Timestamp != "" {
+		t.Errorf("Parse(no ts) = %+v, %v", e, err)
+	}
+
+	for _, line := range []string{`not json`, `{"actor":"mayor"}`} {
+		if _, err := Parse([]byte(line)); err == nil {
+			t.Errorf("Parse(%s) succeeded, want error", line)
+		}
+	}
+}
+
+func TestEmitTo_RoundTrips(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := EmitTo(townRoot, Event{Type: TypeMergeStarted, Actor: "gastown/refinery", Source: "refinery", TraceID: "abc123"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(townRoot, EventsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := Parse([]byte(strings.TrimSpace(string(data))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.TraceID != "abc123" || e.Rig != "gastown" || e.Source != "refinery" {
+		t.Errorf("round-tripped event = %+v", e)
+	}
+}
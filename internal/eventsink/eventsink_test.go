@@ -0,0 +1,178 @@
+package eventsink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/logrotate"
+)
+
+func emit(t *testing.T, townRoot, eventType string) {
+	t.Helper()
+	if err := events.EmitTo(townRoot, events.Event{Type: eventType, Actor: "gastown/refinery"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTypes(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line == "" {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatal(err)
+		}
+		if r.Town != "town" || r.Rig != "gastown" {
+			t.Errorf("record missing town or rig: %s", line)
+		}
+		types = append(types, r.Type)
+	}
+	return strings.Join(types, ",")
+}
+
+func TestForwarder_FileSinkResumesAcrossRestartAndRotation(t *testing.T) {
+	townRoot := filepath.Join(t.TempDir(), "town")
+	if err := os.MkdirAll(townRoot, 0755); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(townRoot, "export", "events.ndjson")
+	cfgs := []Config{{Type: TypeFile, Path: "export/events.ndjson", Types: []string{"merged", "merge_failed"}}}
+	logf := func(string, ...interface{}) {}
+	ctx := context.Background()
+
+	// Events from before the sink existed are not exported
+	emit(t, townRoot, "merged")
+	f, err := NewForwarder(townRoot, cfgs, logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Poll(ctx, time.Now())
+	emit(t, townRoot, "merge_failed")
+	emit(t, townRoot, "sling") // Filtered by type
+	f.Poll(ctx, time.Now())
+	if got := readTypes(t, out); got != "merge_failed" {
+		t.Fatalf("after first polls got %q", got)
+	}
+
+	// Events written while the daemon was down, across a rotation, are
+	// delivered by a new forwarder from the saved cursor
+	emit(t, townRoot, "merged")
+	if _, err := logrotate.Cut(filepath.Join(townRoot, events.EventsFile), logrotate.Policy{Compress: true}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	emit(t, townRoot, "merge_failed")
+	f, err = NewForwarder(townRoot, cfgs, logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Poll(ctx, time.Now())
+	f.Poll(ctx, time.Now())
+	if got := readTypes(t, out); got != "merge_failed,merged,merge_failed" {
+		t.Errorf("after rotation got %q", got)
+	}
+}
+
+func TestWebhookSink_RetriesTransientFailures(t *testing.T) {
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = time.Second }()
+
+	var calls atomic.Int32
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer s3cret" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+			t.Errorf("headers = %v", r.Header)
+		}
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer srv.Close()
+
+	t.Setenv("GT_TEST_SINK_TOKEN", "s3cret")
+	sink, err := New("", Config{Type: TypeWebhook, URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer ${GT_TEST_SINK_TOKEN}"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := []Record{{Town: "t", Event: events.Event{Type: "merged"}}, {Town: "t", Event: events.Event{Type: "sling"}}}
+	if err := sink.Send(context.Background(), records); err != nil {
+		t.Fatalf("Send = %v", err)
+	}
+	if calls.Load() != 3 || strings.Count(body, "\n") != 2 {
+		t.Errorf("calls = %d, body = %q", calls.Load(), body)
+	}
+
+	// Client errors are not retried
+	calls.Store(0)
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	if err := sink.Send(context.Background(), records); err == nil || calls.Load() != 1 {
+		t.Errorf("Send = %v after %d calls; want one failed call", err, calls.Load())
+	}
+}
+
+func TestKafkaSink_ProducesKeyedRecords(t *testing.T) {
+	var path, contentType string
+	var batch struct {
+		Records []struct {
+			Key   string `json:"key"`
+			Value Record `json:"value"`
+		} `json:"records"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		_ = json.NewDecoder(r.Body).Decode(&batch)
+	}))
+	defer srv.Close()
+
+	sink, err := New("", Config{Type: TypeKafka, URL: srv.URL + "/", Topic: "gt-events"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Send(context.Background(), []Record{{Town: "t", Event: events.Event{Type: "merged", Rig: "gastown"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/topics/gt-events" || contentType != "application/vnd.kafka.json.v2+json" {
+		t.Errorf("request = %s (%s)", path, contentType)
+	}
+	if len(batch.Records) != 1 || batch.Records[0].Key != "gastown" || batch.Records[0].Value.Type != "merged" {
+		t.Errorf("batch = %+v", batch)
+	}
+}
+
+func TestNew_ValidatesConfig(t *testing.T) {
+	for _, cfg := range []Config{
+		{Type: TypeWebhook},
+		{Type: TypeFile},
+		{Type: TypeKafka, URL: "http://proxy"},
+		{Type: "syslog"},
+	} {
+		if _, err := New("", cfg); err == nil {
+			t.Errorf("New(%+v) succeeded, want error", cfg)
+		}
+	}
+	if _, err := NewForwarder(t.TempDir(), []Config{{Type: TypeFile, Path: "a"}, {Type: TypeFile, Path: "b"}}, nil); err == nil {
+		t.Error("duplicate sink names accepted")
+	}
+}
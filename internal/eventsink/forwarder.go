@@ -0,0 +1,388 @@
+package eventsink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/logrotate"
+	"github.com/steveyegge/gastown/internal/runstate"
+)
+
+const (
+	// PollInterval is how often the forwarder checks the events log.
+	PollInterval = 5 * time.Second
+
+	// batchSize bounds the events delivered to a sink per Send.
+	batchSize = 500
+
+	// maxFailureBackoff caps the wait before retrying a failing sink.
+	maxFailureBackoff = 5 * time.Minute
+)
+
+// CursorFile returns the path of the forwarder's saved cursors.
+func CursorFile(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "event-sinks.json")
+}
+
+// cursor is a sink's position in the events log. Head identifies the log
+// file (a hash of its first line), so the position survives rotation: once
+// the live log no longer starts with Head, the rest of that file is read
+// from its rotated segment.
+type cursor struct {
+	Head   string `json:"head"`
+	Offset int64  `json:"offset"`
+}
+
+type target struct {
+	name     string
+	cfg      Config
+	sink     Sink
+	failures int
+	retryAt  time.Time
+}
+
+// Forwarder tails the events log and delivers new events to sinks.
+// Each sink keeps its own cursor, so a failing sink neither blocks the
+// others nor loses events: its batch is retried until delivered.
+type Forwarder struct {
+	townRoot  string
+	town      string
+	eventsLog string
+	targets   []*target
+	cursors   map[string]cursor
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	logger    func(format string, args ...interface{})
+}
+
+// NewForwarder creates a forwarder for the enabled sinks in configs.
+// A new sink starts with events logged after it was added.
+func NewForwarder(townRoot string, configs []Config, logger func(format string, args ...interface{})) (*Forwarder, error) {
+	town := filepath.Base(townRoot)
+	if tc, err := config.LoadTownConfig(filepath.Join(townRoot, "mayor", "town.json")); err == nil && tc.Name != "" {
+		town = tc.Name
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &Forwarder{
+		townRoot:  townRoot,
+		town:      town,
+		eventsLog: filepath.Join(townRoot, events.EventsFile),
+		cursors:   make(map[string]cursor),
+		ctx:       ctx,
+		cancel:    cancel,
+		logger:    logger,
+	}
+
+	seen := make(map[string]bool)
+	for _, cfg := range configs {
+		if cfg.Disabled {
+			continue
+		}
+		name := cfg.SinkName()
+		if seen[name] {
+			cancel()
+			return nil, fmt.Errorf("duplicate sink name %q", name)
+		}
+		seen[name] = true
+		sink, err := New(townRoot, cfg)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		f.targets = append(f.targets, &target{name: name, cfg: cfg, sink: sink})
+	}
+
+	if cursors, err := cursorDoc(townRoot).Load(); err == nil && *cursors != nil {
+		f.cursors = *cursors
+	}
+	return f, nil
+}
+
+// Attach adds a sink implemented in-process, such as the notification
+// bridge, under its own cursor. Only events of the given types (default:
+// all) are delivered to it. Attach before Start.
+func (f *Forwarder) Attach(name string, types []string, sink Sink) {
+	f.targets = append(f.targets, &target{name: name, cfg: Config{Name: name, Types: types}, sink: sink})
+}
+
+// Sinks returns the number of enabled sinks.
+func (f *Forwarder) Sinks() int {
+	return len(f.targets)
+}
+
+// Start begins the forwarder goroutine.
+func (f *Forwarder) Start() error {
+	f.wg.Add(1)
+	go f.run()
+	return nil
+}
+
+// Stop gracefully stops the forwarder.
+func (f *Forwarder) Stop() {
+	f.cancel()
+	f.wg.Wait()
+}
+
+func (f *Forwarder) run() {
+	defer f.wg.Done()
+
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+	for {
+		f.Poll(f.ctx, time.Now())
+		select {
+		case <-f.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll delivers all pending events to each sink that isn't backing off
+// after a failure.
+func (f *Forwarder) Poll(ctx context.Context, now time.Time) {
+	for _, t := range f.targets {
+		if now.Before(t.retryAt) {
+			continue
+		}
+		if err := f.drain(ctx, t); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			t.failures++
+			backoff := PollInterval << min(t.failures, 10)
+			if backoff > maxFailureBackoff {
+				backoff = maxFailureBackoff
+			}
+			t.retryAt = now.Add(backoff)
+			f.logger("event sink %s: %v (retrying in %v)", t.name, err, backoff)
+			continue
+		}
+		t.failures = 0
+	}
+}
+
+// drain sends batches to t until it has caught up with the events log.
+func (f *Forwarder) drain(ctx context.Context, t *target) error {
+	for {
+		c, ok := f.cursors[t.name]
+		if !ok {
+			// New sink: start at the end of the live log
+			c = f.endOfLog()
+			f.cursors[t.name] = c
+			if err := f.saveCursors(); err != nil {
+				return err
+			}
+			return nil
+		}
+
+		lines, next, err := f.readSince(c, batchSize)
+		if err != nil {
+			return err
+		}
+		if next == c {
+			return nil // Caught up
+		}
+
+		var records []Record
+		for _, line := range lines {
+			e, err := events.Parse(line)
+			if err != nil || !t.cfg.Wants(e.Type) {
+				continue
+			}
+			records = append(records, Record{Town: f.town, Event: e})
+		}
+		if len(records) > 0 {
+			if err := t.sink.Send(ctx, records); err != nil {
+				return err
+			}
+		}
+
+		f.cursors[t.name] = next
+		if err := f.saveCursors(); err != nil {
+			return err
+		}
+	}
+}
+
+// cursorDoc returns the document holding the forwarder's cursors.
+func cursorDoc(townRoot string) *runstate.Doc[map[string]cursor] {
+	return runstate.NewDocAt(CursorFile(townRoot), func() *map[string]cursor {
+		return &map[string]cursor{}
+	})
+}
+
+func (f *Forwarder) saveCursors() error {
+	return cursorDoc(f.townRoot).Save(&f.cursors)
+}
+
+// endOfLog returns a cursor at the end of the live log's complete lines.
+func (f *Forwarder) endOfLog() cursor {
+	file, err := os.Open(f.eventsLog)
+	if err != nil {
+		return cursor{}
+	}
+	defer file.Close()
+	head, _ := firstLine(file)
+	if head == nil {
+		return cursor{}
+	}
+	_, _ = file.Seek(0, io.SeekStart)
+	_, end := readLines(file, -1)
+	return cursor{Head: lineHash(head), Offset: end}
+}
+
+// readSince returns up to max complete lines after c, and the cursor
+// following them. If the log rotated since c, the rest of c's file is
+// read from its segment before moving on to newer files.
+func (f *Forwarder) readSince(c cursor, max int) ([][]byte, cursor, error) {
+	liveHead, err := fileHead(f.eventsLog)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, c, err
+	}
+
+	if c.Head == "" || c.Head == liveHead {
+		return readFileFrom(f.eventsLog, cursor{Head: liveHead, Offset: c.Offset}, max)
+	}
+
+	// Rotated: find c's file among the segments, oldest first
+	segments, err := logrotate.Segments(f.eventsLog)
+	if err != nil {
+		return nil, c, err
+	}
+	found := false
+	for _, s := range segments {
+		head, err := segmentHead(s)
+		if err != nil {
+			continue
+		}
+		if !found {
+			if head != c.Head {
+				continue
+			}
+			found = true
+		} else {
+			c = cursor{Head: head}
+		}
+		lines, next, err := readSegmentFrom(s, c, max)
+		if err != nil {
+			return nil, c, err
+		}
+		if len(lines) > 0 {
+			return lines, next, nil
+		}
+	}
+	if !found {
+		f.logger("event sinks: events log segment with unsent events was pruned; some events were not forwarded")
+	}
+	if liveHead == "" {
+		return nil, cursor{}, nil
+	}
+	return readFileFrom(f.eventsLog, cursor{Head: liveHead}, max)
+}
+
+func readFileFrom(path string, c cursor, max int) ([][]byte, cursor, error) {
+	file, err := os.Open(path) //nolint:gosec // G304: town events log
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, c, nil
+		}
+		return nil, c, err
+	}
+	defer file.Close()
+
+	if info, err := file.Stat(); err == nil && info.Size() < c.Offset {
+		c.Offset = 0 // Truncated in place
+	}
+	if _, err := file.Seek(c.Offset, io.SeekStart); err != nil {
+		return nil, c, err
+	}
+	lines, n := readLines(file, max)
+	return lines, cursor{Head: c.Head, Offset: c.Offset + n}, nil
+}
+
+func readSegmentFrom(s logrotate.Segment, c cursor, max int) ([][]byte, cursor, error) {
+	r, err := s.Open()
+	if err != nil {
+		return nil, c, err
+	}
+	defer r.Close()
+	if _, err := io.CopyN(io.Discard, r, c.Offset); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, c, nil
+		}
+		return nil, c, err
+	}
+	lines, n := readLines(r, max)
+	return lines, cursor{Head: c.Head, Offset: c.Offset + n}, nil
+}
+
+// readLines reads up to max complete lines (all if max < 0), returning them
+// without newlines along with the number of bytes they spanned. A trailing
+// partial line is left for the next read.
+func readLines(r io.Reader, max int) ([][]byte, int64) {
+	br := bufio.NewReader(r)
+	var lines [][]byte
+	var n int64
+	for max < 0 || len(lines) < max {
+		line, err := br.ReadBytes('\n')
+		if err != nil {
+			break
+		}
+		n += int64(len(line))
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines, n
+}
+
+func firstLine(r io.Reader) ([]byte, error) {
+	line, err := bufio.NewReader(r).ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+func fileHead(path string) (string, error) {
+	file, err := os.Open(path) //nolint:gosec // G304: town events log
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	line, _ := firstLine(file)
+	return lineHash(line), nil
+}
+
+func segmentHead(s logrotate.Segment) (string, error) {
+	r, err := s.Open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	line, _ := firstLine(r)
+	return lineHash(line), nil
+}
+
+func lineHash(line []byte) string {
+	if len(line) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,264 @@
+// Package eventsink forwards the town's unified event stream (.events.jsonl)
+// to external destinations, so events from many towns can be aggregated in
+// one place.
+//
+// Three sink types are supported:
+//   - webhook: POSTs batches of events as NDJSON, retrying transient failures
+//   - file:    appends events as NDJSON to a local file
+//   - kafka:   produces events to a topic through a Kafka REST proxy
+//
+// The daemon runs a Forwarder that tails the events log and delivers new
+// events to each configured sink, resuming from a saved cursor after a
+// restart or log rotation.
+package eventsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// Sink types.
+const (
+	TypeWebhook = "webhook"
+	TypeFile    = "file"
+	TypeKafka   = "kafka"
+)
+
+// DefaultMaxRetries is how many times a webhook or Kafka sink retries a
+// batch that failed transiently before giving up until the next poll.
+const DefaultMaxRetries = 3
+
+// Config configures one event sink (an entry of "event_sinks" in
+// mayor/daemon.json).
+type Config struct {
+	// Name identifies the sink in logs and its saved cursor (default: Type).
+	Name string `json:"name,omitempty"`
+
+	// Type is "webhook", "file", or "kafka".
+	Type string `json:"type"`
+
+	// Disabled stops forwarding to this sink without removing it.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// URL is the webhook endpoint, or the Kafka REST proxy base URL.
+	URL string `json:"url,omitempty"`
+
+	// Headers are added to webhook and Kafka requests. Values may reference
+	// environment variables, e.g. "Bearer ${GT_SINK_TOKEN}".
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Path is the NDJSON file for a file sink, relative to the town root
+	// unless absolute.
+	Path string `json:"path,omitempty"`
+
+	// Topic is the Kafka topic to produce to.
+	Topic string `json:"topic,omitempty"`
+
+	// Types limits forwarding to these event types (default: all).
+	Types []string `json:"types,omitempty"`
+
+	// MaxRetries bounds retries of transient failures (default DefaultMaxRetries).
+	MaxRetries int `json:"max_retries,omitempty"`
+}
+
+// SinkName returns the name the sink is known by.
+func (c Config) SinkName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Type
+}
+
+// Wants reports whether the sink forwards events of the given type.
+func (c Config) Wants(eventType string) bool {
+	if len(c.Types) == 0 {
+		return true
+	}
+	for _, t := range c.Types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Record is an event as delivered to sinks: the unified envelope plus the
+// name of the town it came from.
+type Record struct {
+	Town string `json:"town"`
+	events.Event
+}
+
+// Sink delivers batches of records to a destination.
+type Sink interface {
+	// Send delivers records in order. On error none of the batch is
+	// considered delivered and it is sent again later.
+	Send(ctx context.Context, records []Record) error
+}
+
+// New creates the sink described by cfg. Relative file paths are resolved
+// against townRoot.
+func New(townRoot string, cfg Config) (Sink, error) {
+	retries := cfg.MaxRetries
+	if retries <= 0 {
+		retries = DefaultMaxRetries
+	}
+	switch cfg.Type {
+	case TypeWebhook:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("webhook sink %q: url is required", cfg.SinkName())
+		}
+		return &webhookSink{url: cfg.URL, headers: cfg.Headers, retries: retries, client: defaultClient}, nil
+	case TypeFile:
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("file sink %q: path is required", cfg.SinkName())
+		}
+		path := cfg.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(townRoot, path)
+		}
+		return &fileSink{path: path}, nil
+	case TypeKafka:
+		if cfg.URL == "" || cfg.Topic == "" {
+			return nil, fmt.Errorf("kafka sink %q: url and topic are required", cfg.SinkName())
+		}
+		url := strings.TrimSuffix(cfg.URL, "/") + "/topics/" + cfg.Topic
+		return &kafkaSink{webhookSink{url: url, headers: cfg.Headers, retries: retries, client: defaultClient}}, nil
+	default:
+		return nil, fmt.Errorf("sink %q: unknown type %q (want webhook, file, or kafka)", cfg.SinkName(), cfg.Type)
+	}
+}
+
+var defaultClient = &http.Client{Timeout: 30 * time.Second}
+
+// retryBackoff is the delay before the first retry; it doubles per attempt.
+var retryBackoff = time.Second
+
+// webhookSink POSTs each batch as NDJSON.
+type webhookSink struct {
+	url     string
+	headers map[string]string
+	retries int
+	client  *http.Client
+}
+
+func (s *webhookSink) Send(ctx context.Context, records []Record) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("encoding event: %w", err)
+		}
+	}
+	return s.post(ctx, "application/x-ndjson", body.Bytes())
+}
+
+// post sends body, retrying network errors, 429s, and 5xx responses with
+// exponential backoff.
+func (s *webhookSink) post(ctx context.Context, contentType string, body []byte) error {
+	backoff := retryBackoff
+	var lastErr error
+	for attempt := 0; attempt <= s.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", contentType)
+		for k, v := range s.headers {
+			req.Header.Set(k, os.ExpandEnv(v))
+		}
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		_ = resp.Body.Close()
+		if resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("%s returned %s: %s", s.url, resp.Status, strings.TrimSpace(string(snippet)))
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			return lastErr // Not transient
+		}
+	}
+	return lastErr
+}
+
+// kafkaSink produces to a topic through the Kafka REST proxy v2 API
+// (POST /topics/<topic>), keyed by rig so a rig's events stay ordered
+// within a partition.
+type kafkaSink struct {
+	webhookSink
+}
+
+type kafkaRecord struct {
+	Key   string `json:"key,omitempty"`
+	Value Record `json:"value"`
+}
+
+func (s *kafkaSink) Send(ctx context.Context, records []Record) error {
+	batch := struct {
+		Records []kafkaRecord `json:"records"`
+	}{Records: make([]kafkaRecord, 0, len(records))}
+	for _, r := range records {
+		batch.Records = append(batch.Records, kafkaRecord{Key: r.Rig, Value: r})
+	}
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("encoding events: %w", err)
+	}
+	return s.post(ctx, "application/vnd.kafka.json.v2+json", body)
+}
+
+// fileSink appends records to an NDJSON file.
+type fileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (s *fileSink) Send(_ context.Context, records []Record) error {
+	var data bytes.Buffer
+	enc := json.NewEncoder(&data)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("encoding event: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: exported events are non-sensitive operational data
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data.Bytes()); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/logrotate"
 )
 
 // FeedFile is the name of the curated feed file.
@@ -103,8 +104,9 @@ func (c *Curator) Stop() {
 // ZFC: No in-memory state to clean up - state is derived from the events file.
 func (c *Curator) run(file *os.File) {
 	defer c.wg.Done()
-	defer file.Close()
+	defer func() { _ = file.Close() }()
 
+	eventsPath := filepath.Join(c.townRoot, events.EventsFile)
 	reader := bufio.NewReader(file)
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
@@ -123,6 +125,15 @@ func (c *Curator) run(file *os.File) {
 				}
 				c.processLine(line)
 			}
+
+			// After a rotation, continue from the start of the new log
+			if logrotate.Replaced(file, eventsPath) {
+				if next, err := os.Open(eventsPath); err == nil { //nolint:gosec // G304: town events log
+					_ = file.Close()
+					file = next
+					reader = bufio.NewReader(file)
+				}
+			}
 		}
 	}
 }
@@ -133,8 +144,8 @@ func (c *Curator) processLine(line string) {
 		return
 	}
 
-	var rawEvent events.Event
-	if err := json.Unmarshal([]byte(line), &rawEvent); err != nil {
+	rawEvent, err := events.Parse([]byte(line))
+	if err != nil {
 		return // Skip malformed lines
 	}
 
@@ -376,6 +387,12 @@ func (c *Curator) generateSummary(event *events.Event) string {
 		}
 		return "Session terminated"
 
+	case events.TypeRestart:
+		if agent, ok := event.Payload["agent"].(string); ok {
+			return fmt.Sprintf("Restarted %s", agent)
+		}
+		return "Agent restarted"
+
 	case events.TypeMassDeath:
 		count, _ := event.Payload["count"].(float64) // JSON numbers are float64
 		possibleCause, _ := event.Payload["possible_cause"].(string)
@@ -0,0 +1,130 @@
+package git
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Auth holds credentials applied to git commands via the environment, so
+// nothing is written to repo config and the global git config is untouched.
+type Auth struct {
+	SSHKey           string // private key for SSH remotes
+	Username         string // HTTPS username used with Token
+	Token            string // HTTPS token
+	TokenCommand     string // shell command printing an HTTPS token, used if Token is empty
+	TokenDir         string // directory TokenCommand runs in
+	CredentialHelper string // credential helper replacing any global helpers
+	URL              string // remote URL; helpers apply only to its host
+}
+
+// tokenHelper answers git credential "get" requests from the environment so
+// the token never appears in argv or on disk.
+const tokenHelper = `!f() { test "$1" = get || exit 0; echo "username=${GT_GIT_USERNAME}"; echo "password=${GT_GIT_TOKEN}"; }; f`
+
+// tokenCommandHelper answers git credential "get" requests by running the
+// token command each time, so a short-lived token (one a secrets manager
+// rotates) is fresh for every fetch and push of a long-running process.
+const tokenCommandHelper = `!f() { test "$1" = get || exit 0; t=$(cd "${GT_GIT_TOKEN_DIR:-.}" && sh -c "$GT_GIT_TOKEN_COMMAND") && test -n "$t" || exit 1; echo "username=${GT_GIT_USERNAME}"; echo "password=$t"; }; f`
+
+// Env returns the environment variables (KEY=VALUE) that apply a to git.
+// Returns nil if a is nil or empty.
+func (a *Auth) Env() []string {
+	if a == nil {
+		return nil
+	}
+
+	var env []string
+	if a.SSHKey != "" {
+		env = append(env, fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %s -o IdentitiesOnly=yes", shellQuote(a.SSHKey)))
+	}
+
+	var helpers []string
+	username := a.Username
+	if username == "" {
+		username = "x-access-token"
+	}
+	switch {
+	case a.Token != "":
+		env = append(env, "GT_GIT_USERNAME="+username, "GT_GIT_TOKEN="+a.Token)
+		helpers = append(helpers, tokenHelper)
+	case a.TokenCommand != "":
+		env = append(env, "GT_GIT_USERNAME="+username,
+			"GT_GIT_TOKEN_COMMAND="+a.TokenCommand, "GT_GIT_TOKEN_DIR="+a.TokenDir)
+		helpers = append(helpers, tokenCommandHelper)
+	}
+	if a.CredentialHelper != "" {
+		helpers = append(helpers, a.CredentialHelper)
+	}
+	if len(helpers) > 0 {
+		// An empty value resets the helper list inherited from global config.
+		// Scoping to the remote keeps the token from being sent to any
+		// other host git talks to (submodules, redirects, LFS).
+		key := "credential.helper"
+		if scope := credentialScope(a.URL); scope != "" {
+			key = "credential." + scope + ".helper"
+		}
+		helpers = append([]string{""}, helpers...)
+		env = append(env, fmt.Sprintf("GIT_CONFIG_COUNT=%d", len(helpers)))
+		for i, h := range helpers {
+			env = append(env,
+				fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", i, key),
+				fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, h))
+		}
+	}
+
+	if len(env) > 0 {
+		// Agents can't answer prompts; fail fast instead of hanging
+		env = append(env, "GIT_TERMINAL_PROMPT=0")
+	}
+	return env
+}
+
+// credentialScope returns the scheme://host[:port] that git matches credential
+// config against for remote. SSH and scp-like remotes map to https on the same
+// host, the only way a credential helper is consulted for them. Returns "" if
+// remote is empty or has no host.
+func credentialScope(remote string) string {
+	if remote == "" {
+		return ""
+	}
+	if !strings.Contains(remote, "://") {
+		// scp-like: [user@]host:path
+		host, _, ok := strings.Cut(remote, ":")
+		if !ok {
+			return ""
+		}
+		if i := strings.LastIndex(host, "@"); i >= 0 {
+			host = host[i+1:]
+		}
+		if host == "" {
+			return ""
+		}
+		return "https://" + host
+	}
+	u, err := url.Parse(remote)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return u.Scheme + "://" + u.Host
+	default:
+		return "https://" + u.Hostname()
+	}
+}
+
+// WithAuth returns a copy of g that runs git commands with auth applied.
+// Returns g unchanged if auth is nil or empty.
+func (g *Git) WithAuth(auth *Auth) *Git {
+	env := auth.Env()
+	if len(env) == 0 {
+		return g
+	}
+	return g.WithEnv(env...)
+}
+
+// shellQuote quotes s for use in a POSIX shell command line.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,124 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuthEnv(t *testing.T) {
+	var nilAuth *Auth
+	if env := nilAuth.Env(); env != nil {
+		t.Errorf("nil Auth Env = %v, want nil", env)
+	}
+	if env := (&Auth{}).Env(); env != nil {
+		t.Errorf("empty Auth Env = %v, want nil", env)
+	}
+
+	env := (&Auth{SSHKey: "/keys/it's"}).Env()
+	joined := strings.Join(env, "\n")
+	if !strings.Contains(joined, `GIT_SSH_COMMAND=ssh -i '/keys/it'\''s' -o IdentitiesOnly=yes`) {
+		t.Errorf("ssh env missing quoted key: %v", env)
+	}
+	if !strings.Contains(joined, "GIT_TERMINAL_PROMPT=0") {
+		t.Errorf("env should disable terminal prompts: %v", env)
+	}
+
+	env = (&Auth{CredentialHelper: "store"}).Env()
+	joined = strings.Join(env, "\n")
+	for _, want := range []string{"GIT_CONFIG_COUNT=2", "GIT_CONFIG_VALUE_0=\n", "GIT_CONFIG_VALUE_1=store"} {
+		if !strings.Contains(joined+"\n", want) {
+			t.Errorf("helper env missing %q: %v", want, env)
+		}
+	}
+}
+
+func TestAuthEnvScopedToRemote(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"", "credential.helper"},
+		{"https://example.com/org/repo.git", "credential.https://example.com.helper"},
+		{"http://git.local:8080/repo", "credential.http://git.local:8080.helper"},
+		{"git@github.com:org/repo.git", "credential.https://github.com.helper"},
+		{"ssh://git@github.com:22/org/repo.git", "credential.https://github.com.helper"},
+		{"/srv/git/repo.git", "credential.helper"},
+	}
+	for _, tt := range tests {
+		env := (&Auth{Token: "x", URL: tt.url}).Env()
+		joined := strings.Join(env, "\n") + "\n"
+		for _, i := range []string{"0", "1"} {
+			if want := "GIT_CONFIG_KEY_" + i + "=" + tt.want + "\n"; !strings.Contains(joined, want) {
+				t.Errorf("URL %q: env missing %q: %v", tt.url, want, env)
+			}
+		}
+	}
+}
+
+func credentialFill(t *testing.T, auth *Auth, host string) (string, error) {
+	t.Helper()
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Env = append(os.Environ(), auth.Env()...)
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=" + host + "\n\n")
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+func TestAuthTokenCredentialFill(t *testing.T) {
+	auth := &Auth{Token: "s3cret", URL: "https://example.com/org/repo.git"}
+	out, err := credentialFill(t, auth, "example.com")
+	if err != nil {
+		t.Fatalf("git credential fill: %v", err)
+	}
+	if !strings.Contains(out, "username=x-access-token") || !strings.Contains(out, "password=s3cret") {
+		t.Errorf("credential fill output = %q", out)
+	}
+
+	// Any other host must not see the token; with prompts disabled and no
+	// helper answering, fill fails or returns no password
+	out, _ = credentialFill(t, auth, "other.example.org")
+	if strings.Contains(out, "s3cret") {
+		t.Errorf("token leaked to another host: %q", out)
+	}
+}
+
+func TestAuthTokenCommandCredentialFill(t *testing.T) {
+	// The command runs for every request, in TokenDir
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "calls")
+	auth := &Auth{TokenCommand: `echo x >> calls; echo "tok-$(wc -l < calls | tr -d ' ')"`, TokenDir: dir,
+		URL: "https://example.com/org/repo.git"}
+	fill := func() string {
+		t.Helper()
+		out, err := credentialFill(t, auth, "example.com")
+		if err != nil {
+			t.Fatalf("git credential fill: %v", err)
+		}
+		return out
+	}
+	if out := fill(); !strings.Contains(out, "username=x-access-token") || !strings.Contains(out, "password=tok-1\n") {
+		t.Errorf("first credential fill output = %q", out)
+	}
+	if out := fill(); !strings.Contains(out, "password=tok-2\n") {
+		t.Errorf("second credential fill output = %q, want a fresh token", out)
+	}
+	if _, err := os.Stat(counter); err != nil {
+		t.Errorf("token command did not run in TokenDir: %v", err)
+	}
+	if out, _ := credentialFill(t, auth, "other.example.org"); strings.Contains(out, "tok-") {
+		t.Errorf("token command ran for another host: %q", out)
+	}
+}
+
+func TestWithAuth(t *testing.T) {
+	g := NewGit(t.TempDir())
+	if g.WithAuth(nil) != g {
+		t.Error("WithAuth(nil) should return g unchanged")
+	}
+	if authed := g.WithAuth(&Auth{Token: "x"}); len(authed.env) == 0 || len(g.env) != 0 {
+		t.Error("WithAuth should return a copy with env applied")
+	}
+}
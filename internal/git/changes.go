@@ -0,0 +1,208 @@
+package git
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ChangeSet lists the files a branch changes relative to its merge base with
+// a target (the equivalent of "git diff --name-only --no-renames target...branch").
+// Renamed files appear under both their old and new paths.
+//
+// Use it for routing and gating decisions (protected paths, test selection,
+// label rules) instead of shelling out to git diff: results are computed
+// in-process when possible and cached by commit.
+type ChangeSet struct {
+	Target string   // resolved target commit SHA
+	Head   string   // resolved branch commit SHA
+	Files  []string // changed paths, sorted
+}
+
+// changeCacheSize bounds the number of cached change sets.
+const changeCacheSize = 256
+
+// changeCache holds change sets keyed by "<target sha>...<head sha>".
+// Commit SHAs are immutable, so entries never go stale and can be shared by
+// every repo (and worktree) that has the commits.
+var changeCache = struct {
+	sync.Mutex
+	entries map[string][]string
+	order   []string
+}{entries: make(map[string][]string)}
+
+func cachedChanges(key string) ([]string, bool) {
+	changeCache.Lock()
+	defer changeCache.Unlock()
+	files, ok := changeCache.entries[key]
+	return append([]string(nil), files...), ok
+}
+
+func storeChanges(key string, files []string) {
+	changeCache.Lock()
+	defer changeCache.Unlock()
+	if _, ok := changeCache.entries[key]; ok {
+		return
+	}
+	if len(changeCache.order) >= changeCacheSize {
+		oldest := changeCache.order[0]
+		changeCache.order = changeCache.order[1:]
+		delete(changeCache.entries, oldest)
+	}
+	changeCache.entries[key] = files
+	changeCache.order = append(changeCache.order, key)
+}
+
+// ChangedFiles returns the files branch changes since its merge base with target.
+func (g *Git) ChangedFiles(target, branch string) (*ChangeSet, error) {
+	targetSHA, err := g.Rev(target)
+	if err != nil {
+		return nil, err
+	}
+	headSHA, err := g.Rev(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	key := targetSHA + "..." + headSHA
+	if files, ok := cachedChanges(key); ok {
+		return &ChangeSet{Target: targetSHA, Head: headSHA, Files: files}, nil
+	}
+
+	files, err := g.goGitChangedFiles(targetSHA, headSHA)
+	if err != nil {
+		out, err := g.run("diff", "--name-only", "--no-renames", "-z", key)
+		if err != nil {
+			return nil, err
+		}
+		files = parseNameList(out)
+	}
+	storeChanges(key, files)
+	return &ChangeSet{Target: targetSHA, Head: headSHA, Files: files}, nil
+}
+
+// goGitChangedFiles lists paths changed on head since its merge base with target.
+func (g *Git) goGitChangedFiles(target, head string) ([]string, error) {
+	repo, err := g.openRepo()
+	if err != nil {
+		return nil, err
+	}
+	t, err := resolveCommit(repo, target)
+	if err != nil {
+		return nil, err
+	}
+	h, err := resolveCommit(repo, head)
+	if err != nil {
+		return nil, err
+	}
+	mb, err := mergeBaseCommit(t, h)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := changedPaths(mb, h)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(paths))
+	for p := range paths {
+		files = append(files, p)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// parseNameList parses NUL-separated path output, returning sorted unique paths.
+func parseNameList(out string) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, p := range strings.Split(out, "\x00") {
+		p = strings.TrimSpace(p)
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		files = append(files, p)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Dirs returns the directories containing changed files, sorted.
+// Files at the repository root are reported as ".".
+func (c *ChangeSet) Dirs() []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, f := range c.Files {
+		d := path.Dir(f)
+		if !seen[d] {
+			seen[d] = true
+			dirs = append(dirs, d)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// Match returns the changed files matching any of the patterns.
+// See MatchPath for the pattern syntax.
+func (c *ChangeSet) Match(patterns ...string) []string {
+	var matched []string
+	for _, f := range c.Files {
+		for _, p := range patterns {
+			if MatchPath(p, f) {
+				matched = append(matched, f)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// Touches reports whether any changed file matches any of the patterns.
+func (c *ChangeSet) Touches(patterns ...string) bool {
+	return len(c.Match(patterns...)) > 0
+}
+
+// MatchPath reports whether a slash-separated repo path matches pattern.
+// Segments use path.Match syntax (*, ?, [...]); a "**" segment matches zero
+// or more directories; a pattern ending in "/" matches everything under
+// that directory. Examples: "docs/", "internal/**/auth/*.go", "*.md".
+// As in .gitignore, a pattern without "/" matches the file name at any
+// depth, and a leading "/" anchors a pattern to the repository root.
+func MatchPath(pattern, file string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(file))
+		return ok
+	}
+	pattern = strings.TrimPrefix(pattern, "/")
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(file, "/"))
+}
+
+func matchSegments(pattern, file []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			if len(rest) == 0 {
+				return len(file) > 0
+			}
+			for i := 0; i <= len(file); i++ {
+				if matchSegments(rest, file[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(file) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], file[0]); !ok {
+			return false
+		}
+		pattern, file = pattern[1:], file[1:]
+	}
+	return len(file) == 0
+}
@@ -0,0 +1,101 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestChangedFiles(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	base, _ := g.CurrentBranch()
+	run("checkout", "-b", "feature")
+	write("internal/auth/token.go", "package auth\n")
+	write("docs/guide.md", "# Guide\n")
+	run("add", ".")
+	run("commit", "-m", "feature work")
+
+	// A change on the target after branching must not show up
+	run("checkout", base)
+	write("unrelated.txt", "x\n")
+	run("add", ".")
+	run("commit", "-m", "target work")
+
+	changes, err := g.ChangedFiles(base, "feature")
+	if err != nil {
+		t.Fatalf("ChangedFiles: %v", err)
+	}
+	want := []string{"docs/guide.md", "internal/auth/token.go"}
+	if !reflect.DeepEqual(changes.Files, want) {
+		t.Errorf("Files = %v, want %v", changes.Files, want)
+	}
+	if got := changes.Dirs(); !reflect.DeepEqual(got, []string{"docs", "internal/auth"}) {
+		t.Errorf("Dirs = %v", got)
+	}
+	if !changes.Touches("internal/**/auth/") || changes.Touches("cmd/") {
+		t.Error("Touches gave wrong result for protected paths")
+	}
+	if got := changes.Match("*.md"); !reflect.DeepEqual(got, []string{"docs/guide.md"}) {
+		t.Errorf("Match(*.md) = %v", got)
+	}
+
+	// The CLI fallback agrees with the in-process result
+	out, err := g.run("diff", "--name-only", "--no-renames", "-z", changes.Target+"..."+changes.Head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parseNameList(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("git diff fallback = %v, want %v", got, want)
+	}
+
+	// Second call is served from the cache
+	if _, ok := cachedChanges(changes.Target + "..." + changes.Head); !ok {
+		t.Error("change set was not cached")
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"docs/", "docs/a/b.md", true},
+		{"docs/", "docsx/a.md", false},
+		{"*.md", "deep/nested/README.md", true},
+		{"internal/**/auth/*.go", "internal/auth/token.go", true},
+		{"internal/**/auth/*.go", "internal/x/y/auth/token.go", true},
+		{"internal/**/auth/*.go", "internal/auth/sub/token.go", false},
+		{"go.mod", "go.mod", true},
+		{"go.mod", "tools/go.mod", true},
+		{"/go.mod", "go.mod", true},
+		{"/go.mod", "tools/go.mod", false},
+		{"cmd/*/main.go", "cmd/gt/main.go", true},
+	}
+	for _, tt := range tests {
+		if got := MatchPath(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
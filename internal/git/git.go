@@ -1,4 +1,6 @@
 // Package git provides a wrapper for git operations via subprocess.
+// Read-heavy queries (BranchExists, Rev, IsAncestor, DiffStat, conflict
+// pre-checks) are answered in-process via go-git when possible; see gogit.go.
 package git
 
 import (
@@ -36,7 +38,8 @@ func (e *GitError) Unwrap() error {
 // Git wraps git operations for a working directory.
 type Git struct {
 	workDir string
-	gitDir  string // Optional: explicit git directory (for bare repos)
+	gitDir  string   // Optional: explicit git directory (for bare repos)
+	env     []string // Optional: extra environment (KEY=VALUE) for git commands
 }
 
 // NewGit creates a new Git wrapper for the given directory.
@@ -51,6 +54,14 @@ func NewGitWithDir(gitDir, workDir string) *Git {
 	return &Git{gitDir: gitDir, workDir: workDir}
 }
 
+// WithEnv returns a copy of g that runs git commands with extra environment
+// variables (KEY=VALUE), e.g. GIT_LFS_SKIP_SMUDGE=1 for a single checkout.
+func (g *Git) WithEnv(env ...string) *Git {
+	c := *g
+	c.env = append(append([]string{}, g.env...), env...)
+	return &c
+}
+
 // WorkDir returns the working directory for this Git instance.
 func (g *Git) WorkDir() string {
 	return g.workDir
@@ -69,21 +80,32 @@ func (g *Git) run(args ...string) (string, error) {
 		args = append([]string{"--git-dir=" + g.gitDir}, args...)
 	}
 
+	stdout, stderr, err := withLockRetry(func() (string, string, error) {
+		return g.exec(args)
+	})
+	if err != nil {
+		return "", g.wrapError(err, stdout, stderr, args)
+	}
+
+	return strings.TrimSpace(stdout), nil
+}
+
+// exec runs git once with the given (already --git-dir prefixed) args.
+func (g *Git) exec(args []string) (string, string, error) {
 	cmd := exec.Command("git", args...)
 	if g.workDir != "" {
 		cmd.Dir = g.workDir
 	}
+	if len(g.env) > 0 {
+		cmd.Env = append(os.Environ(), g.env...)
+	}
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	err := cmd.Run()
-	if err != nil {
-		return "", g.wrapError(err, stdout.String(), stderr.String(), args)
-	}
-
-	return strings.TrimSpace(stdout.String()), nil
+	return stdout.String(), stderr.String(), err
 }
 
 // wrapError wraps git errors with context.
@@ -222,12 +244,138 @@ func (g *Git) Checkout(ref string) error {
 	return err
 }
 
+// CheckoutDetached force-checks out ref with a detached HEAD, discarding
+// local modifications to tracked files.
+func (g *Git) CheckoutDetached(ref string) error {
+	_, err := g.run("checkout", "--force", "--detach", ref)
+	return err
+}
+
+// Clean removes untracked files and directories. Ignored files (build
+// caches, dependencies) are kept.
+func (g *Git) Clean() error {
+	_, err := g.run("clean", "-ffd")
+	return err
+}
+
 // Fetch fetches from the remote.
 func (g *Git) Fetch(remote string) error {
 	_, err := g.run("fetch", remote)
 	return err
 }
 
+// FetchNoPrune fetches from the remote, keeping refs deleted there even if
+// fetch.prune is set.
+func (g *Git) FetchNoPrune(remote string) error {
+	_, err := g.run("fetch", "--no-prune", remote)
+	return err
+}
+
+// GetConfig returns the value of a git config key in this repository.
+// Returns an error if the key is unset.
+func (g *Git) GetConfig(key string) (string, error) {
+	return g.run("config", "--get", key)
+}
+
+// SetConfig sets a git config key in this repository.
+func (g *Git) SetConfig(key, value string) error {
+	_, err := g.run("config", key, value)
+	return err
+}
+
+// GitDir returns the absolute path of this worktree's own git directory
+// (.git for a plain clone, .git/worktrees/<name> for a linked worktree).
+func (g *Git) GitDir() (string, error) {
+	return g.run("rev-parse", "--absolute-git-dir")
+}
+
+// CommonDir returns the absolute path of the git directory shared by all
+// worktrees of this repository.
+func (g *Git) CommonDir() (string, error) {
+	dir, err := g.run("rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(g.workDir, dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// HooksDir returns the absolute path of the hooks directory git currently
+// uses for this worktree, honoring core.hooksPath.
+func (g *Git) HooksDir() (string, error) {
+	dir, err := g.run("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(g.workDir, dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// SetWorktreeConfig sets a git config key for this worktree only, leaving
+// the repository's other worktrees untouched. Enables the worktreeConfig
+// extension on the repository if needed.
+func (g *Git) SetWorktreeConfig(key, value string) error {
+	if v, _ := g.GetConfig("extensions.worktreeConfig"); v != "true" {
+		if err := g.enableWorktreeConfig(); err != nil {
+			return fmt.Errorf("enabling worktree config: %w", err)
+		}
+	}
+	_, err := g.run("config", "--worktree", key, value)
+	return err
+}
+
+// enableWorktreeConfig turns on extensions.worktreeConfig. Once enabled,
+// core.bare in the shared config would apply to every linked worktree, so a
+// bare repo's core.bare=true is moved into the main worktree's config first.
+func (g *Git) enableWorktreeConfig() error {
+	common, err := g.CommonDir()
+	if err != nil {
+		return err
+	}
+	shared := filepath.Join(common, "config")
+	cg := NewGitWithDir(common, "")
+
+	bare, _ := cg.run("config", "--file", shared, "--get", "core.bare")
+	if bare == "true" {
+		if _, err := cg.run("config", "--file", filepath.Join(common, "config.worktree"), "core.bare", "true"); err != nil {
+			return err
+		}
+	}
+	if _, err := cg.run("config", "--file", shared, "extensions.worktreeConfig", "true"); err != nil {
+		return err
+	}
+	if bare == "true" {
+		if _, err := cg.run("config", "--file", shared, "--unset", "core.bare"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AheadBehind returns how many commits local has that upstream doesn't
+// (ahead) and how many upstream has that local doesn't (behind).
+func (g *Git) AheadBehind(local, upstream string) (ahead, behind int, err error) {
+	out, err := g.run("rev-list", "--left-right", "--count", local+"..."+upstream)
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := fmt.Sscanf(out, "%d %d", &ahead, &behind); err != nil {
+		return 0, 0, fmt.Errorf("parsing ahead/behind counts %q: %w", out, err)
+	}
+	return ahead, behind, nil
+}
+
+// MergeFFOnly fast-forwards the current branch to ref.
+// Fails without changing anything if a fast-forward isn't possible.
+func (g *Git) MergeFFOnly(ref string) error {
+	_, err := g.run("merge", "--ff-only", ref)
+	return err
+}
+
 // FetchBranch fetches a specific branch from the remote.
 func (g *Git) FetchBranch(remote, branch string) error {
 	_, err := g.run("fetch", remote, branch)
@@ -241,15 +389,78 @@ func (g *Git) Pull(remote, branch string) error {
 }
 
 // Push pushes to the remote branch.
+// A forced push uses --force-with-lease: it only overwrites the remote branch
+// if it still matches our remote-tracking ref, so a concurrent push by
+// another agent is never clobbered.
 func (g *Git) Push(remote, branch string, force bool) error {
 	args := []string{"push", remote, branch}
 	if force {
-		args = append(args, "--force")
+		args = append(args, "--force-with-lease")
 	}
 	_, err := g.run(args...)
 	return err
 }
 
+// LeaseError is returned when a force push is refused because the remote
+// branch is not at the expected commit (someone else pushed to it).
+type LeaseError struct {
+	Remote   string
+	Branch   string
+	Expected string // commit we expected the remote branch to be at ("" = absent)
+	Actual   string // commit the remote branch is at, if known
+}
+
+func (e *LeaseError) Error() string {
+	msg := fmt.Sprintf("refusing to force-push %s/%s: remote branch moved unexpectedly", e.Remote, e.Branch)
+	if e.Actual != "" {
+		msg += fmt.Sprintf(" (expected %s, found %s)", ShortSHA(e.Expected), ShortSHA(e.Actual))
+	}
+	return msg
+}
+
+// PushWithLease force-pushes branch only if the remote branch is still at
+// expected (an empty expected requires the remote branch not to exist).
+// Returns a *LeaseError if the remote moved.
+func (g *Git) PushWithLease(remote, branch, expected string) error {
+	return g.PushRefWithLease(remote, branch, branch, expected)
+}
+
+// PushRefWithLease force-pushes ref (a local branch or a commit) to branch
+// on the remote, with the same lease as PushWithLease.
+func (g *Git) PushRefWithLease(remote, ref, branch, expected string) error {
+	lease := fmt.Sprintf("--force-with-lease=refs/heads/%s:%s", branch, expected)
+	_, err := g.run("push", lease, remote, ref+":refs/heads/"+branch)
+	if err != nil && strings.Contains(err.Error(), "stale info") {
+		actual, _ := g.RemoteBranchSHA(remote, branch)
+		return &LeaseError{Remote: remote, Branch: branch, Expected: expected, Actual: actual}
+	}
+	return err
+}
+
+// RemoteBranchSHA returns the commit a branch points to on the remote,
+// or "" if the branch doesn't exist there.
+func (g *Git) RemoteBranchSHA(remote, branch string) (string, error) {
+	out, err := g.run("ls-remote", "--heads", remote, "refs/heads/"+branch)
+	if err != nil {
+		return "", err
+	}
+	if sha, _, ok := strings.Cut(out, "\t"); ok {
+		return sha, nil
+	}
+	return "", nil
+}
+
+// ShortSHA abbreviates a commit SHA for display.
+func ShortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	if sha == "" {
+		return "(none)"
+	}
+	return sha
+}
+
 // Add stages files for commit.
 func (g *Git) Add(paths ...string) error {
 	args := append([]string{"add"}, paths...)
@@ -411,6 +622,13 @@ func (g *Git) Rebase(onto string) error {
 	return err
 }
 
+// RebaseOnto replays the commits of the current branch that are not on
+// upstream onto newBase ("git rebase --onto newBase upstream").
+func (g *Git) RebaseOnto(newBase, upstream string) error {
+	_, err := g.run("rebase", "--onto", newBase, upstream)
+	return err
+}
+
 // AbortMerge aborts a merge in progress.
 func (g *Git) AbortMerge() error {
 	_, err := g.run("merge", "--abort")
@@ -429,6 +647,12 @@ func (g *Git) CheckConflicts(source, target string) ([]string, error) {
 		return nil, fmt.Errorf("checkout target %s: %w", target, err)
 	}
 
+	// Fast path: if no path changed on both sides since the merge base, the
+	// merge cannot conflict and the trial merge can be skipped entirely.
+	if overlap, err := g.goGitOverlappingPaths(source, target); err == nil && len(overlap) == 0 {
+		return nil, nil
+	}
+
 	// Attempt test merge with --no-commit --no-ff
 	// We need to capture both stdout and stderr to detect conflicts
 	_, mergeErr := g.runMergeCheck("merge", "--no-commit", "--no-ff", source)
@@ -457,20 +681,15 @@ func (g *Git) CheckConflicts(source, target string) ([]string, error) {
 // runMergeCheck runs a git merge command and returns error info from both stdout and stderr.
 // ZFC: Returns GitError with raw output for agent observation.
 func (g *Git) runMergeCheck(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = g.workDir
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
+	stdout, stderr, err := withLockRetry(func() (string, string, error) {
+		return g.exec(args)
+	})
 	if err != nil {
 		// ZFC: Return raw output for observation, don't interpret CONFLICT
-		return "", g.wrapError(err, stdout.String(), stderr.String(), args)
+		return "", g.wrapError(err, stdout, stderr, args)
 	}
 
-	return strings.TrimSpace(stdout.String()), nil
+	return strings.TrimSpace(stdout), nil
 }
 
 // GetConflictingFiles returns the list of files with merge conflicts.
@@ -518,6 +737,9 @@ func (g *Git) CreateBranchFrom(name, ref string) error {
 
 // BranchExists checks if a branch exists locally.
 func (g *Git) BranchExists(name string) (bool, error) {
+	if exists, err := g.goGitBranchExists(name); err == nil {
+		return exists, nil
+	}
 	_, err := g.run("show-ref", "--verify", "--quiet", "refs/heads/"+name)
 	if err != nil {
 		// Exit code 1 means branch doesn't exist
@@ -543,6 +765,23 @@ func (g *Git) RemoteBranchExists(remote, branch string) (bool, error) {
 	return out != "", nil
 }
 
+// RemoteBranchHeads returns the branches on the remote, mapped to the
+// SHAs of their tips.
+func (g *Git) RemoteBranchHeads(remote string) (map[string]string, error) {
+	out, err := g.run("ls-remote", "--heads", remote)
+	if err != nil {
+		return nil, err
+	}
+	heads := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		sha, ref, ok := strings.Cut(line, "\t")
+		if ok {
+			heads[strings.TrimPrefix(ref, "refs/heads/")] = sha
+		}
+	}
+	return heads, nil
+}
+
 // DeleteBranch deletes a local branch.
 func (g *Git) DeleteBranch(name string, force bool) error {
 	flag := "-d"
@@ -580,11 +819,22 @@ func (g *Git) ResetBranch(name, ref string) error {
 
 // Rev returns the commit hash for the given ref.
 func (g *Git) Rev(ref string) (string, error) {
+	if sha, err := g.goGitRev(ref); err == nil {
+		return sha, nil
+	}
 	return g.run("rev-parse", ref)
 }
 
+// MergeBase returns the best common ancestor of two commits.
+func (g *Git) MergeBase(a, b string) (string, error) {
+	return g.run("merge-base", a, b)
+}
+
 // IsAncestor checks if ancestor is an ancestor of descendant.
 func (g *Git) IsAncestor(ancestor, descendant string) (bool, error) {
+	if ok, err := g.goGitIsAncestor(ancestor, descendant); err == nil {
+		return ok, nil
+	}
 	_, err := g.run("merge-base", "--is-ancestor", ancestor, descendant)
 	if err != nil {
 		// Exit code 1 means not an ancestor, not an error
@@ -648,24 +898,21 @@ func (g *Git) WorktreeAddExistingForce(path, branch string) error {
 // This ensures source repo settings don't override Gas Town agent settings.
 // Exported for use by doctor checks.
 func ConfigureSparseCheckout(repoPath string) error {
-	// Enable sparse checkout
-	cmd := exec.Command("git", "-C", repoPath, "config", "core.sparseCheckout", "true")
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("enabling sparse checkout: %s", strings.TrimSpace(stderr.String()))
+	// Enable sparse checkout. The config file is shared by every worktree of
+	// the bare repo, so concurrent spawns contend for its lock.
+	g := NewGit(repoPath)
+	if _, stderr, err := withLockRetry(func() (string, string, error) {
+		return g.exec([]string{"config", "core.sparseCheckout", "true"})
+	}); err != nil {
+		return fmt.Errorf("enabling sparse checkout: %s", strings.TrimSpace(stderr))
 	}
 
 	// Get git dir for this repo/worktree
-	cmd = exec.Command("git", "-C", repoPath, "rev-parse", "--git-dir")
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-	stderr.Reset()
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("getting git dir: %s", strings.TrimSpace(stderr.String()))
+	stdout, stderr, err := g.exec([]string{"rev-parse", "--git-dir"})
+	if err != nil {
+		return fmt.Errorf("getting git dir: %s", strings.TrimSpace(stderr))
 	}
-	gitDir := strings.TrimSpace(stdout.String())
+	gitDir := strings.TrimSpace(stdout)
 	if !filepath.IsAbs(gitDir) {
 		gitDir = filepath.Join(repoPath, gitDir)
 	}
@@ -697,11 +944,10 @@ func ConfigureSparseCheckout(repoPath string) error {
 	}
 
 	// Reapply to remove excluded files
-	cmd = exec.Command("git", "-C", repoPath, "read-tree", "-mu", "HEAD")
-	stderr.Reset()
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("applying sparse checkout: %s", strings.TrimSpace(stderr.String()))
+	if _, stderr, err := withLockRetry(func() (string, string, error) {
+		return g.exec([]string{"read-tree", "-mu", "HEAD"})
+	}); err != nil {
+		return fmt.Errorf("applying sparse checkout: %s", strings.TrimSpace(stderr))
 	}
 	return nil
 }
@@ -890,6 +1136,113 @@ func (g *Git) CommitsAhead(base, branch string) (int, error) {
 	return count, nil
 }
 
+// CommitsBetween returns the full SHAs of the commits reachable from head
+// but not from base ("git rev-list base..head"), newest first.
+func (g *Git) CommitsBetween(base, head string) ([]string, error) {
+	out, err := g.run("rev-list", base+".."+head)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// UnlandedCommits returns the full SHAs of the non-merge commits on head
+// whose changes are not yet on upstream, oldest first. A commit already
+// cherry-picked onto upstream counts as landed, even though its SHA differs.
+// With paths, only commits touching one of them are returned.
+func (g *Git) UnlandedCommits(upstream, head string, paths ...string) ([]string, error) {
+	args := []string{"log", "--format=%H", "--reverse", "--no-merges", "--cherry-pick", "--right-only",
+		upstream + "..." + head}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	out, err := g.run(args...)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// CommitFiles returns the files a (non-merge) commit changes, limited to
+// pathspecs if any are given.
+func (g *Git) CommitFiles(commit string, pathspecs ...string) ([]string, error) {
+	args := []string{"diff-tree", "-r", "--root", "--no-commit-id", "--name-only", "--no-renames", "-z", commit}
+	if len(pathspecs) > 0 {
+		args = append(append(args, "--"), pathspecs...)
+	}
+	out, err := g.run(args...)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range strings.Split(out, "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// CherryPick applies commits, in order, onto the current branch. Each new
+// commit records the commit it was picked from (-x).
+func (g *Git) CherryPick(commits ...string) error {
+	_, err := g.run(append([]string{"cherry-pick", "-x"}, commits...)...)
+	return err
+}
+
+// AbortCherryPick aborts a cherry-pick in progress.
+func (g *Git) AbortCherryPick() error {
+	_, err := g.run("cherry-pick", "--abort")
+	return err
+}
+
+// FileStat holds line counts for a single changed file.
+type FileStat struct {
+	Path       string
+	Insertions int
+	Deletions  int
+}
+
+// DiffStat summarizes the changes a branch introduces.
+type DiffStat struct {
+	Files      []FileStat
+	Insertions int
+	Deletions  int
+}
+
+// DiffStat returns diff statistics for head relative to its merge base with
+// base (the equivalent of "git diff --numstat base...head").
+func (g *Git) DiffStat(base, head string) (*DiffStat, error) {
+	if stat, err := g.goGitDiffStat(base, head); err == nil {
+		return stat, nil
+	}
+
+	out, err := g.run("diff", "--numstat", base+"..."+head)
+	if err != nil {
+		return nil, err
+	}
+	return parseNumstat(out), nil
+}
+
+// parseNumstat parses "git diff --numstat" output.
+// Binary files ("-\t-\tpath") are counted with zero line changes.
+func parseNumstat(out string) *DiffStat {
+	stat := &DiffStat{}
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		fs := FileStat{Path: parts[2]}
+		_, _ = fmt.Sscanf(parts[0], "%d", &fs.Insertions)
+		_, _ = fmt.Sscanf(parts[1], "%d", &fs.Deletions)
+		stat.Files = append(stat.Files, fs)
+		stat.Insertions += fs.Insertions
+		stat.Deletions += fs.Deletions
+	}
+	return stat
+}
+
 // CountCommitsBehind returns the number of commits that HEAD is behind the given ref.
 // For example, CountCommitsBehind("origin/main") returns how many commits
 // are on origin/main that are not on the current HEAD.
@@ -1026,7 +1379,19 @@ func (s *UncommittedWorkStatus) String() string {
 func (g *Git) CheckUncommittedWork() (*UncommittedWorkStatus, error) {
 	status := &UncommittedWorkStatus{}
 
-	// Check git status
+	// One status call covers changes, stashes, and unpushed commits; on
+	// large repos each extra call can take seconds.
+	if gitStatus, stashes, ahead, err := g.statusSummary(); err == nil {
+		status.HasUncommittedChanges = !gitStatus.Clean
+		status.ModifiedFiles = append(gitStatus.Modified, gitStatus.Added...)
+		status.ModifiedFiles = append(status.ModifiedFiles, gitStatus.Deleted...)
+		status.UntrackedFiles = gitStatus.Untracked
+		status.StashCount = stashes
+		status.UnpushedCommits = ahead
+		return status, nil
+	}
+
+	// Fallback for older git without --show-stash: check git status
 	gitStatus, err := g.Status()
 	if err != nil {
 		return nil, fmt.Errorf("checking git status: %w", err)
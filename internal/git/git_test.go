@@ -1,9 +1,11 @@
 package git
 
 import (
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -242,6 +244,161 @@ func TestRev(t *testing.T) {
 	}
 }
 
+func TestCommitsBetween(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	base, err := g.Rev("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []string
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Add(name); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Commit("add " + name); err != nil {
+			t.Fatal(err)
+		}
+		sha, _ := g.Rev("HEAD")
+		want = append([]string{sha}, want...)
+	}
+
+	got, err := g.CommitsBetween(base, "HEAD")
+	if err != nil {
+		t.Fatalf("CommitsBetween: %v", err)
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("CommitsBetween = %v, want %v", got, want)
+	}
+	if got, _ := g.CommitsBetween("HEAD", "HEAD"); len(got) != 0 {
+		t.Errorf("CommitsBetween(HEAD, HEAD) = %v, want none", got)
+	}
+}
+
+func TestUnlandedCommitsAndCherryPick(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	main, err := g.CurrentBranch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.CreateBranch("work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Checkout("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	var shas []string
+	for _, name := range []string{"docs/a.md", "src/b.go", "docs/c.md"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Add(name); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Commit("add " + name); err != nil {
+			t.Fatal(err)
+		}
+		sha, _ := g.Rev("HEAD")
+		shas = append(shas, sha)
+	}
+
+	got, err := g.UnlandedCommits(main, "work")
+	if err != nil {
+		t.Fatalf("UnlandedCommits: %v", err)
+	}
+	if strings.Join(got, " ") != strings.Join(shas, " ") {
+		t.Errorf("UnlandedCommits = %v, want %v", got, shas)
+	}
+	got, _ = g.UnlandedCommits(main, "work", "docs")
+	if want := []string{shas[0], shas[2]}; strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("UnlandedCommits(docs) = %v, want %v", got, want)
+	}
+
+	// Land the first commit on main by cherry-picking it.
+	if err := g.Checkout(main); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.CherryPick(shas[0]); err != nil {
+		t.Fatalf("CherryPick: %v", err)
+	}
+	got, _ = g.UnlandedCommits(main, "work")
+	if want := shas[1:]; strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("UnlandedCommits after pick = %v, want %v", got, want)
+	}
+}
+
+func TestRebaseOnto(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	commit := func(name string) string {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Add(name); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Commit("add " + name); err != nil {
+			t.Fatal(err)
+		}
+		sha, _ := g.Rev("HEAD")
+		return sha
+	}
+
+	// lower <- upper, then lower is rewritten
+	if err := g.CreateBranch("lower"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Checkout("lower"); err != nil {
+		t.Fatal(err)
+	}
+	oldLower := commit("a.txt")
+	if err := g.CreateBranch("upper"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Checkout("upper"); err != nil {
+		t.Fatal(err)
+	}
+	upper := commit("b.txt")
+	if base, err := g.MergeBase("lower", "upper"); err != nil || base != oldLower {
+		t.Fatalf("MergeBase = %q, %v; want %s", base, err, oldLower)
+	}
+	if err := g.Checkout("lower"); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("git", "commit", "--amend", "-m", "rewritten")
+	cmd.Dir = dir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("git commit --amend: %v", err)
+	}
+	newLower, _ := g.Rev("HEAD")
+
+	if err := g.Checkout("upper"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.RebaseOnto("lower", oldLower); err != nil {
+		t.Fatalf("RebaseOnto: %v", err)
+	}
+	if ok, _ := g.IsAncestor(newLower, "HEAD"); !ok {
+		t.Error("upper not rebased onto the rewritten lower")
+	}
+	if ok, _ := g.IsAncestor(oldLower, "HEAD"); ok {
+		t.Error("upper still contains the old lower commit")
+	}
+	if got, _ := g.CommitsBetween("lower", "HEAD"); len(got) != 1 || got[0] == upper {
+		t.Errorf("commits above lower = %v, want one replayed commit", got)
+	}
+}
+
 func TestFetchBranch(t *testing.T) {
 	// Create a "remote" repo
 	remoteDir := t.TempDir()
@@ -326,6 +483,38 @@ func TestCheckConflicts_NoConflict(t *testing.T) {
 	}
 }
 
+// A file on one side against a directory on the other changes no common
+// path but still conflicts.
+func TestCheckConflicts_FileDirectory(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	mainBranch, _ := g.CurrentBranch()
+
+	if err := g.CreateBranch("feature"); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if err := g.Checkout("feature"); err != nil {
+		t.Fatalf("Checkout feature: %v", err)
+	}
+	commitFile(t, g, dir, "foo", "file\n")
+
+	if err := g.Checkout(mainBranch); err != nil {
+		t.Fatalf("Checkout main: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "foo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, g, dir, "foo/bar", "nested\n")
+
+	conflicts, err := g.CheckConflicts("feature", mainBranch)
+	if err != nil {
+		t.Fatalf("CheckConflicts: %v", err)
+	}
+	if len(conflicts) == 0 {
+		t.Error("expected a file/directory conflict, got none")
+	}
+}
+
 func TestCheckConflicts_WithConflict(t *testing.T) {
 	dir := initTestRepo(t)
 	g := NewGit(dir)
@@ -480,6 +669,78 @@ func TestCloneBareHasOriginRefs(t *testing.T) {
 	}
 }
 
+func TestPushWithLease(t *testing.T) {
+	remoteDir := t.TempDir()
+	cmd := exec.Command("git", "init", "--bare")
+	cmd.Dir = remoteDir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("git init --bare: %v", err)
+	}
+
+	localDir := initTestRepo(t)
+	g := NewGit(localDir)
+	cmd = exec.Command("git", "remote", "add", "origin", remoteDir)
+	cmd.Dir = localDir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("git remote add: %v", err)
+	}
+	if err := g.Push("origin", "HEAD:refs/heads/feature", false); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	pushed, _ := g.Rev("HEAD")
+
+	sha, err := g.RemoteBranchSHA("origin", "feature")
+	if err != nil || sha != pushed {
+		t.Fatalf("RemoteBranchSHA = %q, %v; want %s", sha, err, pushed)
+	}
+	if sha, err := g.RemoteBranchSHA("origin", "missing"); err != nil || sha != "" {
+		t.Errorf("RemoteBranchSHA(missing) = %q, %v; want empty", sha, err)
+	}
+
+	// Rewrite history locally on a feature branch
+	if err := g.CreateBranch("feature"); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if err := g.Checkout("feature"); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	cmd = exec.Command("git", "commit", "--amend", "-m", "rewritten")
+	cmd.Dir = localDir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("git commit --amend: %v", err)
+	}
+
+	// Stale expectation: refused with a LeaseError
+	err = g.PushWithLease("origin", "feature", "0000000000000000000000000000000000000001")
+	var leaseErr *LeaseError
+	if !errors.As(err, &leaseErr) {
+		t.Fatalf("PushWithLease with stale lease = %v, want *LeaseError", err)
+	}
+	if leaseErr.Actual != pushed {
+		t.Errorf("LeaseError.Actual = %s, want %s", leaseErr.Actual, pushed)
+	}
+
+	// Correct expectation: force push succeeds
+	if err := g.PushWithLease("origin", "feature", pushed); err != nil {
+		t.Fatalf("PushWithLease: %v", err)
+	}
+	head, _ := g.Rev("HEAD")
+	if sha, _ := g.RemoteBranchSHA("origin", "feature"); sha != head {
+		t.Errorf("remote feature = %s, want rewritten %s", sha, head)
+	}
+
+	// A commit can be pushed to a branch that must not exist yet
+	if err := g.PushRefWithLease("origin", pushed, "stacked", ""); err != nil {
+		t.Fatalf("PushRefWithLease: %v", err)
+	}
+	if sha, _ := g.RemoteBranchSHA("origin", "stacked"); sha != pushed {
+		t.Errorf("remote stacked = %s, want %s", sha, pushed)
+	}
+	if err := g.PushRefWithLease("origin", head, "stacked", ""); !errors.As(err, &leaseErr) {
+		t.Errorf("PushRefWithLease onto existing branch = %v, want *LeaseError", err)
+	}
+}
+
 func stringContains(s, substr string) bool {
 	for i := 0; i <= len(s)-len(substr); i++ {
 		if s[i:i+len(substr)] == substr {
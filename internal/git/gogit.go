@@ -0,0 +1,241 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// EnvGitBackend selects the backend for read-heavy git queries.
+//
+//	auto (default) - answer in-process via go-git, fall back to the git binary on any error
+//	exec           - always shell out to the git binary
+//
+// Mutating operations (merge, push, checkout, worktrees) always use the git
+// binary: go-git's support for them is incomplete and hooks would not run.
+const EnvGitBackend = "GT_GIT_BACKEND"
+
+// errGoGitDisabled is returned by openRepo when the go-git backend is disabled.
+var errGoGitDisabled = errors.New("go-git backend disabled")
+
+// goGitEnabled reports whether read queries should try go-git first.
+func goGitEnabled() bool {
+	return strings.ToLower(os.Getenv(EnvGitBackend)) != "exec"
+}
+
+// openRepo opens the repository in-process. It is re-opened per query rather
+// than cached so refs and packs written by the git binary (fetch, merge) are
+// always visible; opening is cheap compared to a fork/exec.
+func (g *Git) openRepo() (*gogit.Repository, error) {
+	if !goGitEnabled() {
+		return nil, errGoGitDisabled
+	}
+	if g.gitDir != "" {
+		return gogit.PlainOpen(g.gitDir)
+	}
+	dir := g.workDir
+	if dir == "" {
+		dir = "."
+	}
+	return gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true, // worktrees share refs/objects with the main repo
+	})
+}
+
+// goGitBranchExists checks for refs/heads/<name> in-process.
+func (g *Git) goGitBranchExists(name string) (bool, error) {
+	repo, err := g.openRepo()
+	if err != nil {
+		return false, err
+	}
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(name), false)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// goGitRev resolves a revision to a full SHA in-process.
+func (g *Git) goGitRev(ref string) (string, error) {
+	repo, err := g.openRepo()
+	if err != nil {
+		return "", err
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+// resolveCommit resolves a revision to a commit object.
+func resolveCommit(repo *gogit.Repository, rev string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", rev, err)
+	}
+	return repo.CommitObject(*hash)
+}
+
+// goGitIsAncestor checks ancestry in-process.
+func (g *Git) goGitIsAncestor(ancestor, descendant string) (bool, error) {
+	repo, err := g.openRepo()
+	if err != nil {
+		return false, err
+	}
+	a, err := resolveCommit(repo, ancestor)
+	if err != nil {
+		return false, err
+	}
+	d, err := resolveCommit(repo, descendant)
+	if err != nil {
+		return false, err
+	}
+	return a.IsAncestor(d)
+}
+
+// mergeBaseCommit returns the single best merge base of a and b.
+func mergeBaseCommit(a, b *object.Commit) (*object.Commit, error) {
+	bases, err := a.MergeBase(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(bases) != 1 {
+		// No common history, or criss-cross merge: let the git binary decide
+		return nil, fmt.Errorf("expected 1 merge base, found %d", len(bases))
+	}
+	return bases[0], nil
+}
+
+// changedPaths returns the paths that differ between two commits' trees.
+func changedPaths(from, to *object.Commit) (map[string]bool, error) {
+	fromTree, err := from.Tree()
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[string]bool, len(changes))
+	for _, c := range changes {
+		if c.From.Name != "" {
+			paths[c.From.Name] = true
+		}
+		if c.To.Name != "" {
+			paths[c.To.Name] = true
+		}
+	}
+	return paths, nil
+}
+
+// goGitOverlappingPaths returns the paths changed on both sides since the
+// merge base of source and target, counting a path changed on one side under
+// a directory path changed on the other (a file/directory conflict, "foo"
+// against "foo/bar") as changed on both. An empty result proves the merge is
+// free of conflicts; a non-empty one only means a conflict is possible.
+func (g *Git) goGitOverlappingPaths(source, target string) ([]string, error) {
+	repo, err := g.openRepo()
+	if err != nil {
+		return nil, err
+	}
+	src, err := resolveCommit(repo, source)
+	if err != nil {
+		return nil, err
+	}
+	tgt, err := resolveCommit(repo, target)
+	if err != nil {
+		return nil, err
+	}
+	base, err := mergeBaseCommit(src, tgt)
+	if err != nil {
+		return nil, err
+	}
+	srcPaths, err := changedPaths(base, src)
+	if err != nil {
+		return nil, err
+	}
+	tgtPaths, err := changedPaths(base, tgt)
+	if err != nil {
+		return nil, err
+	}
+
+	var overlap []string
+	tgtDirs := parentDirs(tgtPaths)
+	for p := range srcPaths {
+		if tgtPaths[p] || tgtDirs[p] {
+			overlap = append(overlap, p)
+		}
+	}
+	for p := range parentDirs(srcPaths) {
+		if tgtPaths[p] && !srcPaths[p] {
+			overlap = append(overlap, p)
+		}
+	}
+	sort.Strings(overlap)
+	return overlap, nil
+}
+
+// parentDirs returns every directory above the given paths.
+func parentDirs(paths map[string]bool) map[string]bool {
+	dirs := make(map[string]bool)
+	for p := range paths {
+		for i := strings.LastIndexByte(p, '/'); i > 0; i = strings.LastIndexByte(p[:i], '/') {
+			if dirs[p[:i]] {
+				break
+			}
+			dirs[p[:i]] = true
+		}
+	}
+	return dirs
+}
+
+// goGitDiffStat computes diff stats for head relative to its merge base with base.
+func (g *Git) goGitDiffStat(base, head string) (*DiffStat, error) {
+	repo, err := g.openRepo()
+	if err != nil {
+		return nil, err
+	}
+	b, err := resolveCommit(repo, base)
+	if err != nil {
+		return nil, err
+	}
+	h, err := resolveCommit(repo, head)
+	if err != nil {
+		return nil, err
+	}
+	mb, err := mergeBaseCommit(b, h)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := mb.Patch(h)
+	if err != nil {
+		return nil, err
+	}
+
+	stat := &DiffStat{}
+	for _, fs := range patch.Stats() {
+		stat.Files = append(stat.Files, FileStat{
+			Path:       fs.Name,
+			Insertions: fs.Addition,
+			Deletions:  fs.Deletion,
+		})
+		stat.Insertions += fs.Addition
+		stat.Deletions += fs.Deletion
+	}
+	return stat, nil
+}
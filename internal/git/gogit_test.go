@@ -0,0 +1,212 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// commitFile writes content to name and commits it on the current branch.
+func commitFile(t *testing.T, g *Git, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	if err := g.Add(name); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := g.Commit("update " + name); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+}
+
+// TestGoGitMatchesExec verifies the in-process backend agrees with the git
+// binary for each read query it answers.
+func TestGoGitMatchesExec(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	mainBranch, _ := g.CurrentBranch()
+
+	if err := g.CreateBranch("feature"); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+
+	for _, ref := range []string{"HEAD", mainBranch, "feature"} {
+		fast, err := g.goGitRev(ref)
+		if err != nil {
+			t.Fatalf("goGitRev(%s): %v", ref, err)
+		}
+		slow, err := g.run("rev-parse", ref)
+		if err != nil {
+			t.Fatalf("rev-parse %s: %v", ref, err)
+		}
+		if fast != slow {
+			t.Errorf("goGitRev(%s) = %s, git says %s", ref, fast, slow)
+		}
+	}
+
+	for name, want := range map[string]bool{"feature": true, mainBranch: true, "nope": false} {
+		got, err := g.goGitBranchExists(name)
+		if err != nil {
+			t.Fatalf("goGitBranchExists(%s): %v", name, err)
+		}
+		if got != want {
+			t.Errorf("goGitBranchExists(%s) = %v, want %v", name, got, want)
+		}
+	}
+
+	if err := g.Checkout("feature"); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	commitFile(t, g, dir, "feature.txt", "one\ntwo\n")
+
+	ok, err := g.goGitIsAncestor(mainBranch, "feature")
+	if err != nil || !ok {
+		t.Errorf("goGitIsAncestor(main, feature) = %v, %v; want true", ok, err)
+	}
+	ok, err = g.goGitIsAncestor("feature", mainBranch)
+	if err != nil || ok {
+		t.Errorf("goGitIsAncestor(feature, main) = %v, %v; want false", ok, err)
+	}
+}
+
+func TestGoGitRevInWorktree(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	wtPath := filepath.Join(t.TempDir(), "wt")
+	if err := g.WorktreeAdd(wtPath, "wt-branch"); err != nil {
+		t.Fatalf("WorktreeAdd: %v", err)
+	}
+	wt := NewGit(wtPath)
+	commitFile(t, wt, wtPath, "wt.txt", "worktree\n")
+
+	fast, err := wt.goGitRev("HEAD")
+	if err != nil {
+		t.Fatalf("goGitRev(HEAD) in worktree: %v", err)
+	}
+	slow, _ := wt.run("rev-parse", "HEAD")
+	if fast != slow {
+		t.Errorf("worktree HEAD: go-git %s, git %s", fast, slow)
+	}
+
+	// The branch created by the worktree is visible from the main repo
+	exists, err := g.goGitBranchExists("wt-branch")
+	if err != nil || !exists {
+		t.Errorf("goGitBranchExists(wt-branch) = %v, %v; want true", exists, err)
+	}
+}
+
+func TestDiffStat(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	mainBranch, _ := g.CurrentBranch()
+
+	if err := g.CreateBranch("feature"); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if err := g.Checkout("feature"); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	commitFile(t, g, dir, "a.txt", "1\n2\n3\n")
+	commitFile(t, g, dir, "README.md", "# Changed\n")
+
+	for _, backend := range []string{"auto", "exec"} {
+		t.Run(backend, func(t *testing.T) {
+			t.Setenv(EnvGitBackend, backend)
+			stat, err := g.DiffStat(mainBranch, "feature")
+			if err != nil {
+				t.Fatalf("DiffStat: %v", err)
+			}
+			if len(stat.Files) != 2 {
+				t.Errorf("Files = %v, want 2 entries", stat.Files)
+			}
+			if stat.Insertions != 4 || stat.Deletions != 1 {
+				t.Errorf("Insertions/Deletions = %d/%d, want 4/1", stat.Insertions, stat.Deletions)
+			}
+		})
+	}
+}
+
+func TestGoGitOverlappingPaths(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	mainBranch, _ := g.CurrentBranch()
+
+	if err := g.CreateBranch("feature"); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if err := g.Checkout("feature"); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	commitFile(t, g, dir, "feature.txt", "feature\n")
+	commitFile(t, g, dir, "README.md", "# Feature\n")
+
+	if err := g.Checkout(mainBranch); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	commitFile(t, g, dir, "main.txt", "main\n")
+
+	overlap, err := g.goGitOverlappingPaths("feature", mainBranch)
+	if err != nil {
+		t.Fatalf("goGitOverlappingPaths: %v", err)
+	}
+	if len(overlap) != 0 {
+		t.Errorf("overlap = %v, want none", overlap)
+	}
+
+	commitFile(t, g, dir, "README.md", "# Main\n")
+	overlap, err = g.goGitOverlappingPaths("feature", mainBranch)
+	if err != nil {
+		t.Fatalf("goGitOverlappingPaths: %v", err)
+	}
+	if len(overlap) != 1 || overlap[0] != "README.md" {
+		t.Errorf("overlap = %v, want [README.md]", overlap)
+	}
+
+	// A file on one side where the other side adds a directory overlaps
+	if err := g.Checkout("feature"); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	commitFile(t, g, dir, "foo", "file\n")
+	if err := g.Checkout(mainBranch); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "foo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, g, dir, "foo/bar", "nested\n")
+	for _, args := range [][2]string{{"feature", mainBranch}, {mainBranch, "feature"}} {
+		overlap, err = g.goGitOverlappingPaths(args[0], args[1])
+		if err != nil {
+			t.Fatalf("goGitOverlappingPaths: %v", err)
+		}
+		if len(overlap) != 2 || overlap[0] != "README.md" || overlap[1] != "foo" {
+			t.Errorf("overlap(%s, %s) = %v, want [README.md foo]", args[0], args[1], overlap)
+		}
+	}
+}
+
+func TestGoGitDisabled(t *testing.T) {
+	t.Setenv(EnvGitBackend, "exec")
+	g := NewGit(initTestRepo(t))
+	if _, err := g.goGitRev("HEAD"); err != errGoGitDisabled {
+		t.Errorf("goGitRev with backend=exec: err = %v, want errGoGitDisabled", err)
+	}
+	if _, err := g.Rev("HEAD"); err != nil {
+		t.Errorf("Rev should fall back to git binary: %v", err)
+	}
+}
+
+func TestParseNumstat(t *testing.T) {
+	stat := parseNumstat("3\t1\tfoo.go\n-\t-\timage.png\n10\t0\tdir/bar.go")
+	if len(stat.Files) != 3 {
+		t.Fatalf("Files = %d, want 3", len(stat.Files))
+	}
+	if stat.Insertions != 13 || stat.Deletions != 1 {
+		t.Errorf("totals = %d/%d, want 13/1", stat.Insertions, stat.Deletions)
+	}
+	if stat.Files[1].Path != "image.png" || stat.Files[1].Insertions != 0 {
+		t.Errorf("binary file = %+v", stat.Files[1])
+	}
+}
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// EnvLFSSkipSmudge tells git-lfs to leave pointer files instead of downloading
+// LFS objects during checkout.
+const EnvLFSSkipSmudge = "GIT_LFS_SKIP_SMUDGE=1"
+
+// LFSAvailable reports whether the git-lfs extension is installed.
+func LFSAvailable() bool {
+	_, err := exec.LookPath("git-lfs")
+	return err == nil
+}
+
+// UsesLFS reports whether the tree at ref tracks any files with Git LFS,
+// i.e. some .gitattributes file contains "filter=lfs". Works in bare repos.
+// An empty ref means HEAD.
+func (g *Git) UsesLFS(ref string) bool {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	// git grep exits 1 when nothing matches; any error means "no LFS" here
+	_, err := g.run("grep", "-q", "-e", "filter=lfs", ref, "--", ":(glob)**/.gitattributes")
+	return err == nil
+}
+
+// InstallLFS configures the LFS filters and hooks for this repository
+// (git lfs install --local). The config is shared by all worktrees.
+// If hooks can't be written (e.g., a custom core.hooksPath owns them), the
+// filters are still configured so checkouts smudge correctly.
+func (g *Git) InstallLFS() error {
+	if _, err := g.run("lfs", "install", "--local"); err != nil {
+		if _, err2 := g.run("lfs", "install", "--local", "--skip-repo"); err2 == nil {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// LFSConfigured reports whether the LFS smudge filter is configured for this repo.
+func (g *Git) LFSConfigured() bool {
+	out, err := g.run("config", "--get", "filter.lfs.process")
+	if err != nil {
+		out, err = g.run("config", "--get", "filter.lfs.smudge")
+	}
+	return err == nil && strings.Contains(out, "git-lfs")
+}
+
+// PullLFS downloads and checks out LFS objects for the current checkout.
+func (g *Git) PullLFS() error {
+	_, err := g.run("lfs", "pull")
+	return err
+}
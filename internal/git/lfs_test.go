@@ -0,0 +1,47 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUsesLFS(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	if g.UsesLFS("") {
+		t.Error("UsesLFS = true for repo without .gitattributes")
+	}
+
+	commitFile(t, g, dir, ".gitattributes", "*.txt text\n")
+	if g.UsesLFS("HEAD") {
+		t.Error("UsesLFS = true for .gitattributes without filter=lfs")
+	}
+
+	commitFile(t, g, dir, ".gitattributes", "*.psd filter=lfs diff=lfs merge=lfs -text\n")
+	if !g.UsesLFS("HEAD") {
+		t.Error("UsesLFS = false for root .gitattributes with filter=lfs")
+	}
+	if g.UsesLFS("HEAD~1") {
+		t.Error("UsesLFS should inspect the given ref, not the working tree")
+	}
+}
+
+func TestWithEnv(t *testing.T) {
+	g := NewGit(initTestRepo(t))
+	withEnv := g.WithEnv("GIT_AUTHOR_NAME=Env Tester")
+
+	out, err := withEnv.run("var", "GIT_AUTHOR_IDENT")
+	if err != nil {
+		t.Fatalf("git var: %v", err)
+	}
+	if !strings.HasPrefix(out, "Env Tester") {
+		t.Errorf("GIT_AUTHOR_IDENT = %q, want env override", out)
+	}
+
+	// Original is unchanged
+	out, _ = g.run("var", "GIT_AUTHOR_IDENT")
+	if strings.HasPrefix(out, "Env Tester") {
+		t.Error("WithEnv mutated the original Git")
+	}
+}
@@ -0,0 +1,71 @@
+package git
+
+import (
+	"math/rand/v2"
+	"regexp"
+	"time"
+)
+
+// Lock contention retry. The shared .repo.git is used concurrently by the
+// refinery and every polecat worktree, so git commands intermittently fail
+// on index.lock / ref locks held by a sibling process. These failures are
+// transient: git bails out before changing anything, so the command is
+// simply retried with jittered exponential backoff.
+//
+// This is transport-level robustness, not ZFC interpretation: only the
+// "somebody else holds the lock" condition is recognized, and the final
+// GitError still carries the raw output.
+var (
+	lockRetryAttempts  = 5
+	lockRetryBaseDelay = 50 * time.Millisecond
+	lockRetryMaxDelay  = 2 * time.Second
+	lockRetrySleep     = time.Sleep // replaced in tests
+)
+
+// lockContentionPatterns match the errors git gives when another process
+// holds a lock file. Other lock failures ("cannot lock ref ...: is at X but
+// expected Y", a missing index.lock directory, permissions) are permanent
+// and must not be retried, so only the "File exists" forms are recognized.
+var lockContentionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)unable to create '[^']*\.lock': file exists`),
+	regexp.MustCompile(`(?i)cannot lock ref '[^']*':.*file exists`),
+	regexp.MustCompile(`(?i)could not lock config file .*: file exists`),
+}
+
+// isLockContention reports whether git's stderr indicates a held lock.
+func isLockContention(stderr string) bool {
+	for _, re := range lockContentionPatterns {
+		if re.MatchString(stderr) {
+			return true
+		}
+	}
+	return false
+}
+
+// lockRetryDelay returns the backoff before retry number attempt (0-based):
+// exponential from lockRetryBaseDelay, capped at lockRetryMaxDelay, plus up
+// to 50% jitter so contending processes don't retry in lockstep.
+func lockRetryDelay(attempt int) time.Duration {
+	d := lockRetryBaseDelay << attempt
+	if d <= 0 || d > lockRetryMaxDelay {
+		d = lockRetryMaxDelay
+	}
+	return d + time.Duration(rand.Int64N(int64(d)/2+1))
+}
+
+// withLockRetry runs fn, retrying while it fails with lock contention.
+// fn returns git's stderr alongside its error.
+func withLockRetry(fn func() (string, string, error)) (string, string, error) {
+	var stdout, stderr string
+	var err error
+	for attempt := 0; attempt < lockRetryAttempts; attempt++ {
+		if attempt > 0 {
+			lockRetrySleep(lockRetryDelay(attempt - 1))
+		}
+		stdout, stderr, err = fn()
+		if err == nil || !isLockContention(stderr) {
+			return stdout, stderr, err
+		}
+	}
+	return stdout, stderr, err
+}
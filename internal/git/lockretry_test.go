@@ -0,0 +1,105 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIsLockContention(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   bool
+	}{
+		{"fatal: Unable to create '/repo/.git/index.lock': File exists.", true},
+		{"fatal: Unable to create '/repo/.git/index.lock': File exists.\n\nAnother git process seems to be running in this repository", true},
+		{"error: cannot lock ref 'refs/heads/main': Unable to create '/r/refs/heads/main.lock': File exists.", true},
+		{"error: cannot lock ref 'refs/heads/main': File exists", true},
+		{"error: could not lock config file .git/config: File exists", true},
+		{"error: cannot lock ref 'refs/heads/main': is at 1234 but expected 5678", false},
+		{"error: cannot lock ref 'refs/heads/a/b': 'refs/heads/a' exists; cannot create 'refs/heads/a/b'", false},
+		{"fatal: Unable to create '/repo/.git/index.lock': No such file or directory", false},
+		{"fatal: Unable to create '/repo/.git/index.lock': Permission denied", false},
+		{"CONFLICT (content): Merge conflict in README.md", false},
+		{"fatal: not a git repository", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isLockContention(tt.stderr); got != tt.want {
+			t.Errorf("isLockContention(%q) = %v, want %v", tt.stderr, got, tt.want)
+		}
+	}
+}
+
+func TestLockRetryDelay(t *testing.T) {
+	for attempt := 0; attempt < 10; attempt++ {
+		d := lockRetryDelay(attempt)
+		if d < lockRetryBaseDelay || d > lockRetryMaxDelay*3/2 {
+			t.Errorf("lockRetryDelay(%d) = %v, out of bounds", attempt, d)
+		}
+	}
+}
+
+func TestWithLockRetry(t *testing.T) {
+	origSleep := lockRetrySleep
+	defer func() { lockRetrySleep = origSleep }()
+	var slept int
+	lockRetrySleep = func(time.Duration) { slept++ }
+
+	// Succeeds after transient contention
+	calls := 0
+	_, _, err := withLockRetry(func() (string, string, error) {
+		calls++
+		if calls < 3 {
+			return "", "fatal: Unable to create 'x/index.lock': File exists.", errors.New("exit status 128")
+		}
+		return "ok", "", nil
+	})
+	if err != nil || calls != 3 || slept != 2 {
+		t.Errorf("transient: err=%v calls=%d slept=%d, want nil/3/2", err, calls, slept)
+	}
+
+	// Non-lock errors are not retried
+	calls = 0
+	_, _, err = withLockRetry(func() (string, string, error) {
+		calls++
+		return "", "fatal: bad revision", errors.New("exit status 128")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("non-lock: err=%v calls=%d, want error/1", err, calls)
+	}
+
+	// Persistent contention gives up after the bounded attempts
+	calls = 0
+	_, _, err = withLockRetry(func() (string, string, error) {
+		calls++
+		return "", "fatal: Unable to create 'x/index.lock': File exists.", errors.New("exit status 128")
+	})
+	if err == nil || calls != lockRetryAttempts {
+		t.Errorf("persistent: err=%v calls=%d, want error/%d", err, calls, lockRetryAttempts)
+	}
+}
+
+func TestRunRetriesOnIndexLock(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	lockPath := filepath.Join(dir, ".git", "index.lock")
+	if err := os.WriteFile(lockPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate the sibling process releasing the lock during backoff
+	origSleep := lockRetrySleep
+	defer func() { lockRetrySleep = origSleep }()
+	lockRetrySleep = func(time.Duration) { _ = os.Remove(lockPath) }
+
+	if err := g.Add("new.txt"); err != nil {
+		t.Fatalf("Add should succeed after lock released: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package git
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CloneMirror creates a bare mirror of url at dest (git clone --mirror).
+// Mirrors hold every ref of the origin and are used as a shared object
+// store (--reference) by clones of the same repository.
+func (g *Git) CloneMirror(url, dest string) error {
+	_, err := g.run("clone", "--mirror", url, dest)
+	return err
+}
+
+// Alternates returns the object directories listed in the repository's
+// objects/info/alternates (absolute paths). gitDir is the repository's git
+// directory (the repo itself for bare repos).
+func Alternates(gitDir string) ([]string, error) {
+	objectsDir := filepath.Join(gitDir, "objects")
+	f, err := os.Open(filepath.Join(objectsDir, "info", "alternates"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var dirs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(objectsDir, line)
+		}
+		dirs = append(dirs, filepath.Clean(line))
+	}
+	return dirs, scanner.Err()
+}
@@ -0,0 +1,133 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FSMonitorSupported reports whether git's builtin filesystem monitor
+// daemon is available on this platform.
+func FSMonitorSupported() bool {
+	_, err := NewGit("").run("fsmonitor--daemon", "status")
+	if err == nil {
+		return true
+	}
+	// "not running" exits non-zero too; only the platform check is fatal
+	return !strings.Contains(err.Error(), "not supported")
+}
+
+// EnableLargeRepoMode turns on git's settings for repositories with many
+// files: the builtin fsmonitor (where supported) so status doesn't scan the
+// whole tree, the untracked cache, and the smaller v4 index format.
+// Settings are written to the repository config, so linked worktrees of
+// the same repo share them.
+func (g *Git) EnableLargeRepoMode() error {
+	settings := [][2]string{
+		{"core.untrackedCache", "true"},
+		{"index.version", "4"},
+		{"index.threads", "true"},
+		{"core.preloadIndex", "true"},
+	}
+	if FSMonitorSupported() {
+		settings = append(settings, [2]string{"core.fsmonitor", "true"})
+	}
+	for _, kv := range settings {
+		if err := g.SetConfig(kv[0], kv[1]); err != nil {
+			return fmt.Errorf("setting %s: %w", kv[0], err)
+		}
+	}
+	// Rewrite the index now so the first status call benefits
+	if _, err := g.run("update-index", "--index-version", "4", "--untracked-cache"); err != nil {
+		return fmt.Errorf("upgrading index: %w", err)
+	}
+	return nil
+}
+
+// statusSummary returns the working tree status, stash count, and number
+// of commits ahead of upstream from a single "git status" call. Returns an
+// error if this git doesn't support the needed porcelain v2 options.
+func (g *Git) statusSummary() (*GitStatus, int, int, error) {
+	out, err := g.run("status", "--porcelain=v2", "--branch", "--show-stash", "-z")
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return parseStatusV2(out)
+}
+
+// parseStatusV2 parses NUL-separated "git status --porcelain=v2 --branch
+// --show-stash" output. Files are classified the same way Status does.
+func parseStatusV2(out string) (*GitStatus, int, int, error) {
+	status := &GitStatus{Clean: true}
+	stashes, ahead := 0, 0
+
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if entry == "" {
+			continue
+		}
+
+		var code, file string
+		switch entry[0] {
+		case '#':
+			fields := strings.Fields(entry)
+			if len(fields) < 3 {
+				continue
+			}
+			switch fields[1] {
+			case "branch.ab":
+				n, err := strconv.Atoi(strings.TrimPrefix(fields[2], "+"))
+				if err != nil {
+					return nil, 0, 0, fmt.Errorf("parsing ahead count %q: %w", entry, err)
+				}
+				ahead = n
+			case "stash":
+				n, err := strconv.Atoi(fields[2])
+				if err != nil {
+					return nil, 0, 0, fmt.Errorf("parsing stash count %q: %w", entry, err)
+				}
+				stashes = n
+			}
+			continue
+		case '1':
+			parts := strings.SplitN(entry, " ", 9)
+			if len(parts) < 9 {
+				return nil, 0, 0, fmt.Errorf("malformed status entry %q", entry)
+			}
+			code, file = parts[1], parts[8]
+		case '2':
+			parts := strings.SplitN(entry, " ", 10)
+			if len(parts) < 10 {
+				return nil, 0, 0, fmt.Errorf("malformed status entry %q", entry)
+			}
+			code, file = parts[1], parts[9]
+			i++ // original path follows as its own field
+		case 'u':
+			parts := strings.SplitN(entry, " ", 11)
+			if len(parts) < 11 {
+				return nil, 0, 0, fmt.Errorf("malformed status entry %q", entry)
+			}
+			code, file = parts[1], parts[10]
+		case '?':
+			code, file = "??", strings.TrimPrefix(entry, "? ")
+		case '!':
+			continue
+		default:
+			return nil, 0, 0, fmt.Errorf("unknown status entry %q", entry)
+		}
+
+		status.Clean = false
+		switch {
+		case strings.Contains(code, "M"):
+			status.Modified = append(status.Modified, file)
+		case strings.Contains(code, "A"):
+			status.Added = append(status.Added, file)
+		case strings.Contains(code, "D"):
+			status.Deleted = append(status.Deleted, file)
+		case strings.Contains(code, "?"):
+			status.Untracked = append(status.Untracked, file)
+		}
+	}
+	return status, stashes, ahead, nil
+}
@@ -0,0 +1,115 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseStatusV2(t *testing.T) {
+	out := "# branch.oid abc123\x00" +
+		"# branch.head polecat/toast\x00" +
+		"# branch.upstream origin/polecat/toast\x00" +
+		"# branch.ab +2 -1\x00" +
+		"# stash 3\x00" +
+		"1 .M N... 100644 100644 100644 aaa aaa has space.go\x00" +
+		"1 A. N... 000000 100644 100644 000 bbb added.go\x00" +
+		"1 D. N... 100644 000000 000000 ccc 000 gone.go\x00" +
+		"2 R. N... 100644 100644 100644 ddd ddd R100 new.go\x00old.go\x00" +
+		"? untracked.txt\x00"
+
+	status, stashes, ahead, err := parseStatusV2(out)
+	if err != nil {
+		t.Fatalf("parseStatusV2: %v", err)
+	}
+	if status.Clean {
+		t.Error("expected dirty status")
+	}
+	if stashes != 3 || ahead != 2 {
+		t.Errorf("stashes, ahead = %d, %d; want 3, 2", stashes, ahead)
+	}
+	if !reflect.DeepEqual(status.Modified, []string{"has space.go"}) {
+		t.Errorf("Modified = %v", status.Modified)
+	}
+	if !reflect.DeepEqual(status.Added, []string{"added.go"}) {
+		t.Errorf("Added = %v", status.Added)
+	}
+	if !reflect.DeepEqual(status.Deleted, []string{"gone.go"}) {
+		t.Errorf("Deleted = %v", status.Deleted)
+	}
+	if !reflect.DeepEqual(status.Untracked, []string{"untracked.txt"}) {
+		t.Errorf("Untracked = %v", status.Untracked)
+	}
+
+	clean, _, _, err := parseStatusV2("# branch.oid abc\x00# branch.head main\x00")
+	if err != nil || !clean.Clean {
+		t.Errorf("clean output = %+v, %v", clean, err)
+	}
+}
+
+func TestCheckUncommittedWork(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	// Upstream one commit behind, one stash, one modified and one untracked file
+	run("branch", "upstream")
+	run("branch", "--set-upstream-to=upstream")
+	run("commit", "--allow-empty", "-m", "ahead")
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("stashed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("stash")
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := g.CheckUncommittedWork()
+	if err != nil {
+		t.Fatalf("CheckUncommittedWork: %v", err)
+	}
+	if !status.HasUncommittedChanges {
+		t.Error("expected uncommitted changes")
+	}
+	if status.StashCount != 1 || status.UnpushedCommits != 1 {
+		t.Errorf("StashCount, UnpushedCommits = %d, %d; want 1, 1", status.StashCount, status.UnpushedCommits)
+	}
+	if !reflect.DeepEqual(status.ModifiedFiles, []string{"README.md"}) {
+		t.Errorf("ModifiedFiles = %v", status.ModifiedFiles)
+	}
+	if !reflect.DeepEqual(status.UntrackedFiles, []string{"new.txt"}) {
+		t.Errorf("UntrackedFiles = %v", status.UntrackedFiles)
+	}
+}
+
+func TestEnableLargeRepoMode(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	if err := g.EnableLargeRepoMode(); err != nil {
+		t.Fatalf("EnableLargeRepoMode: %v", err)
+	}
+	for key, want := range map[string]string{
+		"core.untrackedCache": "true",
+		"index.version":       "4",
+	} {
+		if got, _ := g.GetConfig(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	if _, err := g.Status(); err != nil {
+		t.Errorf("Status after enabling large-repo mode: %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// HasSubmodules reports whether the working tree declares submodules (.gitmodules).
+func (g *Git) HasSubmodules() bool {
+	if g.workDir == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(g.workDir, ".gitmodules"))
+	return err == nil
+}
+
+// UpdateSubmodules initializes and updates all submodules recursively
+// to the commits recorded in the current checkout.
+func (g *Git) UpdateSubmodules() error {
+	_, err := g.run("submodule", "update", "--init", "--recursive")
+	return err
+}
@@ -0,0 +1,105 @@
+// Package github provides a minimal GitHub REST API client for reporting
+// merge queue progress (commit statuses) back to GitHub.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the public GitHub REST API endpoint.
+const DefaultAPIURL = "https://api.github.com"
+
+// Commit status states accepted by the GitHub API.
+const (
+	StatePending = "pending"
+	StateSuccess = "success"
+	StateFailure = "failure"
+	StateError   = "error"
+)
+
+// maxDescriptionLen is GitHub's limit, in characters, on commit status
+// descriptions.
+const maxDescriptionLen = 140
+
+// CommitStatus is a status posted to a commit.
+type CommitStatus struct {
+	State       string `json:"state"`
+	Context     string `json:"context"`
+	Description string `json:"description,omitempty"`
+	TargetURL   string `json:"target_url,omitempty"`
+}
+
+// Client is a GitHub REST API client authenticated with a token.
+type Client struct {
+	apiURL string
+	token  string
+	http   *http.Client
+}
+
+// NewClient creates a client for apiURL (DefaultAPIURL if empty).
+func NewClient(apiURL, token string) *Client {
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	return &Client{
+		apiURL: strings.TrimRight(apiURL, "/"),
+		token:  token,
+		http:   &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// CreateStatus posts a commit status to sha in repo ("owner/name").
+func (c *Client) CreateStatus(ctx context.Context, repo, sha string, status CommitStatus) error {
+	if runes := []rune(status.Description); len(runes) > maxDescriptionLen {
+		status.Description = string(runes[:maxDescriptionLen-3]) + "..."
+	}
+	body, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/statuses/%s", c.apiURL, repo, sha)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting commit status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("posting commit status: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// repoURLPattern matches the owner/name path of GitHub remote URLs:
+// https://github.com/owner/name(.git), git@github.com:owner/name(.git),
+// ssh://git@github.com/owner/name(.git).
+var repoURLPattern = regexp.MustCompile(`github\.com[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// RepoFromURL extracts "owner/name" from a GitHub remote URL.
+// Returns false for non-GitHub URLs.
+func RepoFromURL(url string) (string, bool) {
+	m := repoURLPattern.FindStringSubmatch(strings.TrimSpace(url))
+	if m == nil {
+		return "", false
+	}
+	return m[1] + "/" + m[2], true
+}
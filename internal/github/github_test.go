@@ -0,0 +1,102 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCreateStatus(t *testing.T) {
+	var gotPath, gotAuth string
+	var got CommitStatus
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "tok")
+	err := c.CreateStatus(context.Background(), "acme/widgets", "abc123", CommitStatus{
+		State:       StateSuccess,
+		Context:     "queue:tests",
+		Description: strings.Repeat("x", 200),
+	})
+	if err != nil {
+		t.Fatalf("CreateStatus: %v", err)
+	}
+	if gotPath != "/repos/acme/widgets/statuses/abc123" {
+		t.Errorf("path = %s", gotPath)
+	}
+	if gotAuth != "Bearer tok" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if got.State != StateSuccess || got.Context != "queue:tests" {
+		t.Errorf("status = %+v", got)
+	}
+	if len(got.Description) != maxDescriptionLen {
+		t.Errorf("description length = %d, want truncated to %d", len(got.Description), maxDescriptionLen)
+	}
+}
+
+func TestCreateStatus_TruncatesOnRuneBoundary(t *testing.T) {
+	var got CommitStatus
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	err := NewClient(srv.URL, "tok").CreateStatus(context.Background(), "a/b", "sha", CommitStatus{
+		State:       StateFailure,
+		Context:     "queue:tests",
+		Description: strings.Repeat("✗", 200),
+	})
+	if err != nil {
+		t.Fatalf("CreateStatus: %v", err)
+	}
+	if !utf8.ValidString(got.Description) {
+		t.Errorf("description %q is not valid UTF-8", got.Description)
+	}
+	if n := utf8.RuneCountInString(got.Description); n != maxDescriptionLen {
+		t.Errorf("description length = %d characters, want truncated to %d", n, maxDescriptionLen)
+	}
+}
+
+func TestCreateStatus_HTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	err := NewClient(srv.URL, "bad").CreateStatus(context.Background(), "a/b", "sha", CommitStatus{State: StatePending, Context: "x"})
+	if err == nil || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("err = %v, want HTTP error with message", err)
+	}
+}
+
+func TestRepoFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+		ok   bool
+	}{
+		{"https://github.com/acme/widgets.git", "acme/widgets", true},
+		{"https://github.com/acme/widgets", "acme/widgets", true},
+		{"git@github.com:acme/widgets.git", "acme/widgets", true},
+		{"ssh://git@github.com/acme/widgets.git", "acme/widgets", true},
+		{"https://gitlab.com/acme/widgets.git", "", false},
+		{"/local/path/repo", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := RepoFromURL(tt.url)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("RepoFromURL(%q) = %q, %v; want %q, %v", tt.url, got, ok, tt.want, tt.ok)
+		}
+	}
+}
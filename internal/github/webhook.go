@@ -0,0 +1,185 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Callback subject prefixes for GitHub webhook events sent to the Mayor.
+const (
+	SubjectPROpened        = "GH_PR_OPENED"
+	SubjectReviewRequested = "GH_REVIEW_REQUESTED"
+	SubjectCIFailed        = "GH_CI_FAILED"
+)
+
+// Callback is a GitHub webhook event rendered as a mayor callback message.
+type Callback struct {
+	// Subject is "<prefix> owner/name#<pr>" or "<prefix> owner/name@<branch>".
+	Subject string
+
+	// Body holds "Key: value" lines (Repo, PR, Title, Author, URL, ...).
+	Body string
+
+	// Repo is the "owner/name" the event is about.
+	Repo string
+
+	// Urgent marks events that need attention now (CI failing on the
+	// default branch).
+	Urgent bool
+}
+
+// VerifySignature checks a webhook's X-Hub-Signature-256 header against the
+// HMAC-SHA256 of its body under secret.
+func VerifySignature(secret string, body []byte, header string) bool {
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok || secret == "" {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// webhookPayload holds the fields of GitHub webhook payloads that callbacks use.
+type webhookPayload struct {
+	Action     string `json:"action"`
+	Repository struct {
+		FullName      string `json:"full_name"`
+		DefaultBranch string `json:"default_branch"`
+	} `json:"repository"`
+	PullRequest *struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+		Draft   bool   `json:"draft"`
+		User    struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		Head struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	} `json:"pull_request"`
+	RequestedReviewer *struct {
+		Login string `json:"login"`
+	} `json:"requested_reviewer"`
+	RequestedTeam *struct {
+		Name string `json:"name"`
+	} `json:"requested_team"`
+	WorkflowRun *struct {
+		Name       string `json:"name"`
+		HeadBranch string `json:"head_branch"`
+		HeadSHA    string `json:"head_sha"`
+		Conclusion string `json:"conclusion"`
+		HTMLURL    string `json:"html_url"`
+	} `json:"workflow_run"`
+	CheckSuite *struct {
+		HeadBranch string `json:"head_branch"`
+		HeadSHA    string `json:"head_sha"`
+		Conclusion string `json:"conclusion"`
+		App        struct {
+			Slug string `json:"slug"`
+			Name string `json:"name"`
+		} `json:"app"`
+	} `json:"check_suite"`
+}
+
+// ParseWebhook converts a GitHub webhook (event is the X-GitHub-Event
+// header) into a callback. It returns nil for events that are not
+// forwarded: anything but a pull request opened (or marked ready for
+// review), a review requested, or CI failing on the default branch.
+func ParseWebhook(event string, body []byte) (*Callback, error) {
+	var p webhookPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("parsing %s webhook: %w", event, err)
+	}
+	repo := p.Repository.FullName
+
+	switch event {
+	case "pull_request":
+		pr := p.PullRequest
+		if pr == nil {
+			return nil, nil
+		}
+		var lines []string
+		var prefix string
+		switch {
+		case (p.Action == "opened" || p.Action == "reopened") && !pr.Draft, p.Action == "ready_for_review":
+			prefix = SubjectPROpened
+		case p.Action == "review_requested":
+			prefix = SubjectReviewRequested
+			reviewer := ""
+			if p.RequestedReviewer != nil {
+				reviewer = p.RequestedReviewer.Login
+			} else if p.RequestedTeam != nil {
+				reviewer = "team " + p.RequestedTeam.Name
+			}
+			lines = append(lines, "Reviewer: "+reviewer)
+		default:
+			return nil, nil
+		}
+		lines = append([]string{
+			"Repo: " + repo,
+			fmt.Sprintf("PR: %d", pr.Number),
+			"Title: " + pr.Title,
+			"Author: " + pr.User.Login,
+			"Branch: " + pr.Head.Ref,
+			"Base: " + pr.Base.Ref,
+		}, lines...)
+		lines = append(lines, "URL: "+pr.HTMLURL)
+		return &Callback{
+			Subject: fmt.Sprintf("%s %s#%d", prefix, repo, pr.Number),
+			Body:    strings.Join(lines, "\n"),
+			Repo:    repo,
+		}, nil
+
+	case "workflow_run":
+		run := p.WorkflowRun
+		if run == nil || p.Action != "completed" || !failed(run.Conclusion) || run.HeadBranch != p.Repository.DefaultBranch {
+			return nil, nil
+		}
+		return ciFailed(repo, run.HeadBranch, run.HeadSHA, run.Name, run.Conclusion, run.HTMLURL), nil
+
+	case "check_suite":
+		// GitHub Actions reports through workflow_run; check suites cover
+		// other CI apps
+		cs := p.CheckSuite
+		if cs == nil || cs.App.Slug == "github-actions" || p.Action != "completed" ||
+			!failed(cs.Conclusion) || cs.HeadBranch != p.Repository.DefaultBranch {
+			return nil, nil
+		}
+		url := fmt.Sprintf("https://github.com/%s/commit/%s", repo, cs.HeadSHA)
+		return ciFailed(repo, cs.HeadBranch, cs.HeadSHA, cs.App.Name, cs.Conclusion, url), nil
+	}
+	return nil, nil
+}
+
+func failed(conclusion string) bool {
+	return conclusion == "failure" || conclusion == "timed_out"
+}
+
+func ciFailed(repo, branch, sha, workflow, conclusion, url string) *Callback {
+	return &Callback{
+		Subject: fmt.Sprintf("%s %s@%s", SubjectCIFailed, repo, branch),
+		Body: strings.Join([]string{
+			"Repo: " + repo,
+			"Branch: " + branch,
+			"Commit: " + sha,
+			"Workflow: " + workflow,
+			"Conclusion: " + conclusion,
+			"URL: " + url,
+		}, "\n"),
+		Repo:   repo,
+		Urgent: true,
+	}
+}
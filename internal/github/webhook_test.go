@@ -0,0 +1,94 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := `{"zen":"hi"}`
+	if !VerifySignature("s3cret", []byte(body), sign("s3cret", body)) {
+		t.Error("valid signature rejected")
+	}
+	for name, header := range map[string]string{
+		"wrong secret": sign("other", body),
+		"no prefix":    strings.TrimPrefix(sign("s3cret", body), "sha256="),
+		"not hex":      "sha256=zz",
+		"empty":        "",
+	} {
+		if VerifySignature("s3cret", []byte(body), header) {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+	if VerifySignature("", []byte(body), sign("", body)) {
+		t.Error("empty secret accepted")
+	}
+}
+
+const repoJSON = `"repository": {"full_name": "acme/widgets", "default_branch": "main"}`
+
+func TestParseWebhook(t *testing.T) {
+	prJSON := `"pull_request": {"number": 12, "title": "Add gears", "html_url": "https://github.com/acme/widgets/pull/12",
+		"user": {"login": "octo"}, "head": {"ref": "gears"}, "base": {"ref": "main"}, "draft": %s}`
+	pr := func(action, draft, extra string) string {
+		return `{"action": "` + action + `", ` + repoJSON + `, ` + strings.Replace(prJSON, "%s", draft, 1) + extra + `}`
+	}
+
+	tests := []struct {
+		name, event, body string
+		subject           string
+		urgent            bool
+	}{
+		{"pr opened", "pull_request", pr("opened", "false", ""), "GH_PR_OPENED acme/widgets#12", false},
+		{"draft opened", "pull_request", pr("opened", "true", ""), "", false},
+		{"ready for review", "pull_request", pr("ready_for_review", "false", ""), "GH_PR_OPENED acme/widgets#12", false},
+		{"pr closed", "pull_request", pr("closed", "false", ""), "", false},
+		{"review requested", "pull_request", pr("review_requested", "false", `, "requested_reviewer": {"login": "hubot"}`), "GH_REVIEW_REQUESTED acme/widgets#12", false},
+		{"ci failed on main", "workflow_run", `{"action": "completed", ` + repoJSON + `, "workflow_run": {"name": "CI", "head_branch": "main", "head_sha": "abc", "conclusion": "failure", "html_url": "u"}}`, "GH_CI_FAILED acme/widgets@main", true},
+		{"ci failed on branch", "workflow_run", `{"action": "completed", ` + repoJSON + `, "workflow_run": {"head_branch": "gears", "conclusion": "failure"}}`, "", false},
+		{"ci passed", "workflow_run", `{"action": "completed", ` + repoJSON + `, "workflow_run": {"head_branch": "main", "conclusion": "success"}}`, "", false},
+		{"other ci app", "check_suite", `{"action": "completed", ` + repoJSON + `, "check_suite": {"head_branch": "main", "head_sha": "abc", "conclusion": "timed_out", "app": {"slug": "buildkite", "name": "Buildkite"}}}`, "GH_CI_FAILED acme/widgets@main", true},
+		{"actions check suite", "check_suite", `{"action": "completed", ` + repoJSON + `, "check_suite": {"head_branch": "main", "conclusion": "failure", "app": {"slug": "github-actions"}}}`, "", false},
+		{"ping", "ping", `{"zen": "Design for failure."}`, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb, err := ParseWebhook(tt.event, []byte(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.subject == "" {
+				if cb != nil {
+					t.Errorf("got callback %q, want none", cb.Subject)
+				}
+				return
+			}
+			if cb == nil || cb.Subject != tt.subject || cb.Urgent != tt.urgent || cb.Repo != "acme/widgets" {
+				t.Fatalf("callback = %+v", cb)
+			}
+			if !strings.Contains(cb.Body, "Repo: acme/widgets") {
+				t.Errorf("body = %q", cb.Body)
+			}
+		})
+	}
+
+	cb, _ := ParseWebhook("pull_request", []byte(pr("review_requested", "false", `, "requested_reviewer": {"login": "hubot"}`)))
+	for _, want := range []string{"PR: 12", "Author: octo", "Reviewer: hubot", "URL: https://github.com/acme/widgets/pull/12"} {
+		if !strings.Contains(cb.Body, want) {
+			t.Errorf("body missing %q: %s", want, cb.Body)
+		}
+	}
+
+	if _, err := ParseWebhook("pull_request", []byte("not json")); err == nil {
+		t.Error("invalid JSON accepted")
+	}
+}
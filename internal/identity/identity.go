@@ -0,0 +1,297 @@
+// Package identity parses and formats agent identities.
+//
+// The same agent is written several ways depending on where the string
+// goes:
+//
+//	Address   mail                "mayor/", "gastown/Toast", "observers/alice"
+//	Actor     BD_ACTOR, events    "mayor", "gastown/polecats/Toast"
+//	Assignee  hooked beads        "mayor/", "gastown/polecats/Toast"
+//	DaemonID  daemon lifecycle    "mayor", "gastown-polecat-Toast"
+//
+// Parse accepts any of them, so code that compares or converts identities
+// should go through an Identity rather than string surgery.
+package identity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Role is an agent's role.
+type Role string
+
+const (
+	Overseer Role = "overseer" // the human operator
+	Mayor    Role = "mayor"
+	Deacon   Role = "deacon"
+	Boot     Role = "boot" // the Deacon's health-triage dog
+	Dog      Role = "dog"  // deacon/dogs/<name>
+	Witness  Role = "witness"
+	Refinery Role = "refinery"
+	Crew     Role = "crew"
+	Polecat  Role = "polecat"
+	Observer Role = "observer" // observers/<name>, read-only
+
+	// Worker is a crew member or polecat named by the short mail form,
+	// "<rig>/<name>", which doesn't say which.
+	Worker Role = "worker"
+)
+
+// Identity identifies an agent.
+type Identity struct {
+	Role Role
+	Rig  string // empty for town-level agents
+	Name string // empty for singletons (mayor, witness, ...)
+}
+
+// Town-level agents.
+var (
+	MayorIdentity    = Identity{Role: Mayor}
+	DeaconIdentity   = Identity{Role: Deacon}
+	OverseerIdentity = Identity{Role: Overseer}
+)
+
+// New returns the identity of an agent with role in rig, named name.
+// Rig and name are ignored where the role doesn't have them.
+func New(role Role, rig, name string) Identity {
+	switch role {
+	case Overseer, Mayor, Deacon, Boot:
+		return Identity{Role: role}
+	case Dog, Observer:
+		return Identity{Role: role, Name: name}
+	case Witness, Refinery:
+		return Identity{Role: role, Rig: rig}
+	}
+	return Identity{Role: role, Rig: rig, Name: name}
+}
+
+// ErrInvalid is wrapped by Parse errors.
+var ErrInvalid = errors.New("invalid agent identity")
+
+// Parse parses an identity in any of its forms (see the package comment).
+func Parse(s string) (Identity, error) {
+	id, ok := parse(s)
+	if !ok {
+		return Identity{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+	if err := id.Validate(); err != nil {
+		return Identity{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+	return id, nil
+}
+
+// MustParse is like Parse but panics on error. It is meant for constants.
+func MustParse(s string) Identity {
+	id, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+func parse(s string) (Identity, bool) {
+	s = strings.TrimSpace(s)
+	switch strings.TrimSuffix(s, "/") {
+	case "overseer":
+		return OverseerIdentity, true
+	case "mayor":
+		return MayorIdentity, true
+	case "deacon":
+		return DeaconIdentity, true
+	case "boot":
+		return Identity{Role: Boot}, true
+	}
+
+	if !strings.Contains(s, "/") {
+		return parseDaemonID(s)
+	}
+
+	parts := strings.Split(strings.TrimSuffix(s, "/"), "/")
+	switch len(parts) {
+	case 2:
+		if parts[0] == "observers" {
+			return Identity{Role: Observer, Name: parts[1]}, true
+		}
+		switch parts[1] {
+		case "witness":
+			return Identity{Role: Witness, Rig: parts[0]}, true
+		case "refinery":
+			return Identity{Role: Refinery, Rig: parts[0]}, true
+		case "crew", "polecats":
+			return Identity{}, false
+		}
+		return Identity{Role: Worker, Rig: parts[0], Name: parts[1]}, true
+	case 3:
+		switch {
+		case parts[0] == "deacon" && parts[1] == "dogs":
+			return Identity{Role: Dog, Name: parts[2]}, true
+		case parts[1] == "crew":
+			return Identity{Role: Crew, Rig: parts[0], Name: parts[2]}, true
+		case parts[1] == "polecats":
+			return Identity{Role: Polecat, Rig: parts[0], Name: parts[2]}, true
+		}
+	}
+	return Identity{}, false
+}
+
+// parseDaemonID parses the daemon's dash form. New rigs can't have hyphens
+// in their names, but older ones may, so the role is found from the end.
+func parseDaemonID(s string) (Identity, bool) {
+	if rig, ok := strings.CutSuffix(s, "-witness"); ok {
+		return Identity{Role: Witness, Rig: rig}, true
+	}
+	if rig, ok := strings.CutSuffix(s, "-refinery"); ok {
+		return Identity{Role: Refinery, Rig: rig}, true
+	}
+	if rig, name, ok := strings.Cut(s, "-crew-"); ok {
+		return Identity{Role: Crew, Rig: rig, Name: name}, true
+	}
+	if rig, name, ok := strings.Cut(s, "-polecat-"); ok {
+		return Identity{Role: Polecat, Rig: rig, Name: name}, true
+	}
+	return Identity{}, false
+}
+
+// Validate checks that the identity has the parts its role needs, and that
+// they can be written in every form.
+func (id Identity) Validate() error {
+	needRig, needName := false, false
+	switch id.Role {
+	case Overseer, Mayor, Deacon, Boot:
+	case Dog, Observer:
+		needName = true
+	case Witness, Refinery:
+		needRig = true
+	case Crew, Polecat, Worker:
+		needRig, needName = true, true
+	default:
+		return fmt.Errorf("unknown role %q", id.Role)
+	}
+	if needRig && (!validPart(id.Rig) || id.Rig == "mayor" || id.Rig == "deacon" || id.Rig == "observers") {
+		return fmt.Errorf("invalid rig %q", id.Rig)
+	}
+	if needName && !validPart(id.Name) {
+		return fmt.Errorf("invalid name %q", id.Name)
+	}
+	return nil
+}
+
+// validPart reports whether s can be a rig or agent name. Anything else,
+// such as the ':' of "list:ops" or the '@' of "@town", belongs to some
+// other kind of address.
+func validPart(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_' || r == '-' || r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// IsTownLevel reports whether the agent belongs to the town, not a rig.
+func (id Identity) IsTownLevel() bool {
+	return id.Rig == ""
+}
+
+// Address returns the mail address: "mayor/", "gastown/witness", and
+// "<rig>/<name>" for crew members and polecats alike.
+func (id Identity) Address() string {
+	switch id.Role {
+	case Mayor, Deacon:
+		return string(id.Role) + "/"
+	case Crew, Polecat, Worker:
+		return id.Rig + "/" + id.Name
+	}
+	return id.Actor()
+}
+
+// Actor returns the BD_ACTOR form, also used for event actors:
+// "mayor", "gastown/crew/max", "gastown/polecats/Toast".
+func (id Identity) Actor() string {
+	switch id.Role {
+	case Overseer, Mayor, Deacon, Boot:
+		return string(id.Role)
+	case Dog:
+		return "deacon/dogs/" + id.Name
+	case Observer:
+		return "observers/" + id.Name
+	case Witness, Refinery:
+		return id.Rig + "/" + string(id.Role)
+	case Crew:
+		return id.Rig + "/crew/" + id.Name
+	case Polecat:
+		return id.Rig + "/polecats/" + id.Name
+	case Worker:
+		return id.Rig + "/" + id.Name
+	}
+	return ""
+}
+
+// Assignee returns the form used as the assignee of hooked beads. It is
+// the Actor form, except that the Mayor and Deacon keep a trailing slash.
+func (id Identity) Assignee() string {
+	switch id.Role {
+	case Mayor, Deacon:
+		return string(id.Role) + "/"
+	}
+	return id.Actor()
+}
+
+// DaemonID returns the daemon's lifecycle form: "mayor", "gastown-witness",
+// "gastown-crew-max", "gastown-polecat-Toast". Agents the daemon doesn't
+// manage get their Actor form.
+func (id Identity) DaemonID() string {
+	switch id.Role {
+	case Witness, Refinery:
+		return id.Rig + "-" + string(id.Role)
+	case Crew, Polecat:
+		return id.Rig + "-" + string(id.Role) + "-" + id.Name
+	}
+	return id.Actor()
+}
+
+// String returns the Actor form.
+func (id Identity) String() string {
+	return id.Actor()
+}
+
+// Matches reports whether other names the same agent. A Worker matches a
+// crew member or polecat of the same rig and name.
+func (id Identity) Matches(other Identity) bool {
+	if id.Rig != other.Rig || id.Name != other.Name {
+		return false
+	}
+	if id.Role == other.Role {
+		return true
+	}
+	worker := func(r Role) bool { return r == Crew || r == Polecat || r == Worker }
+	return (id.Role == Worker && worker(other.Role)) || (other.Role == Worker && worker(id.Role))
+}
+
+// Same reports whether two identity strings, in any forms, name the same
+// agent. Strings that don't parse are compared as they are.
+func Same(a, b string) bool {
+	ia, errA := Parse(a)
+	ib, errB := Parse(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return ia.Matches(ib)
+}
+
+// Rig returns the rig of an identity string, or "" for town-level agents
+// and strings that aren't identities.
+func Rig(s string) string {
+	id, err := Parse(s)
+	if err != nil {
+		return ""
+	}
+	return id.Rig
+}
@@ -0,0 +1,139 @@
+package identity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Identity
+	}{
+		{"mayor", Identity{Role: Mayor}},
+		{"mayor/", Identity{Role: Mayor}},
+		{"deacon/", Identity{Role: Deacon}},
+		{"overseer", Identity{Role: Overseer}},
+		{"boot", Identity{Role: Boot}},
+		{"deacon/dogs/alpha", Identity{Role: Dog, Name: "alpha"}},
+		{"observers/alice", Identity{Role: Observer, Name: "alice"}},
+		{"gastown/witness", Identity{Role: Witness, Rig: "gastown"}},
+		{"gastown/refinery/", Identity{Role: Refinery, Rig: "gastown"}},
+		{"gastown/crew/max", Identity{Role: Crew, Rig: "gastown", Name: "max"}},
+		{"gastown/polecats/Toast", Identity{Role: Polecat, Rig: "gastown", Name: "Toast"}},
+		{"gastown/Toast", Identity{Role: Worker, Rig: "gastown", Name: "Toast"}},
+		{"gastown-witness", Identity{Role: Witness, Rig: "gastown"}},
+		{"my-rig-refinery", Identity{Role: Refinery, Rig: "my-rig"}},
+		{"gastown-crew-max", Identity{Role: Crew, Rig: "gastown", Name: "max"}},
+		{"gastown-polecat-Toast", Identity{Role: Polecat, Rig: "gastown", Name: "Toast"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"gastown",
+		"gastown/",
+		"gastown/crew",
+		"gastown/polecats/",
+		"mayor/Toast",
+		"a/b/c/d",
+		"list:ops",
+		"queue:merge-witness",
+		"@town",
+		"gastown/To ast",
+	} {
+		if id, err := Parse(in); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q) = %+v, %v; want ErrInvalid", in, id, err)
+		}
+	}
+}
+
+func TestForms(t *testing.T) {
+	tests := []struct {
+		id                                 Identity
+		address, actor, assignee, daemonID string
+	}{
+		{MayorIdentity, "mayor/", "mayor", "mayor/", "mayor"},
+		{DeaconIdentity, "deacon/", "deacon", "deacon/", "deacon"},
+		{OverseerIdentity, "overseer", "overseer", "overseer", "overseer"},
+		{New(Dog, "", "alpha"), "deacon/dogs/alpha", "deacon/dogs/alpha", "deacon/dogs/alpha", "deacon/dogs/alpha"},
+		{New(Observer, "gastown", "alice"), "observers/alice", "observers/alice", "observers/alice", "observers/alice"},
+		{New(Witness, "gastown", ""), "gastown/witness", "gastown/witness", "gastown/witness", "gastown-witness"},
+		{New(Crew, "gastown", "max"), "gastown/max", "gastown/crew/max", "gastown/crew/max", "gastown-crew-max"},
+		{New(Polecat, "gastown", "Toast"), "gastown/Toast", "gastown/polecats/Toast", "gastown/polecats/Toast", "gastown-polecat-Toast"},
+	}
+	for _, tt := range tests {
+		if got := tt.id.Address(); got != tt.address {
+			t.Errorf("%v.Address() = %q, want %q", tt.id, got, tt.address)
+		}
+		if got := tt.id.Actor(); got != tt.actor {
+			t.Errorf("%v.Actor() = %q, want %q", tt.id, got, tt.actor)
+		}
+		if got := tt.id.Assignee(); got != tt.assignee {
+			t.Errorf("%v.Assignee() = %q, want %q", tt.id, got, tt.assignee)
+		}
+		if got := tt.id.DaemonID(); got != tt.daemonID {
+			t.Errorf("%v.DaemonID() = %q, want %q", tt.id, got, tt.daemonID)
+		}
+
+		// Every form parses back to the same agent.
+		for _, s := range []string{tt.address, tt.actor, tt.assignee, tt.daemonID} {
+			back, err := Parse(s)
+			if err != nil {
+				t.Errorf("Parse(%q): %v", s, err)
+			} else if !back.Matches(tt.id) {
+				t.Errorf("Parse(%q) = %+v, doesn't match %+v", s, back, tt.id)
+			}
+		}
+	}
+}
+
+func TestSame(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"mayor", "mayor/", true},
+		{"gastown/Toast", "gastown/polecats/Toast", true},
+		{"gastown/max", "gastown-crew-max", true},
+		{"gastown/witness", "gastown-witness", true},
+		{"gastown/crew/Toast", "gastown/polecats/Toast", false},
+		{"gastown/Toast", "beads/Toast", false},
+		{"mayor", "deacon", false},
+		{"list:ops", "list:ops", true},
+		{"list:ops", "list:dev", false},
+	}
+	for _, tt := range tests {
+		if got := Same(tt.a, tt.b); got != tt.want {
+			t.Errorf("Same(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, id := range []Identity{
+		{Role: "king"},
+		{Role: Witness},
+		{Role: Crew, Rig: "gastown"},
+		{Role: Polecat, Rig: "mayor", Name: "Toast"},
+		{Role: Polecat, Rig: "gas/town", Name: "Toast"},
+		{Role: Dog},
+		{Role: Observer},
+		{Role: Crew, Rig: "observers", Name: "max"},
+	} {
+		if err := id.Validate(); err == nil {
+			t.Errorf("%+v.Validate() = nil, want error", id)
+		}
+	}
+}
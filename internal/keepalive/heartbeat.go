@@ -0,0 +1,160 @@
+package keepalive
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// HeartbeatInterval is how often a Heartbeat refreshes the keepalive file.
+var HeartbeatInterval = 30 * time.Second
+
+// Heartbeat keeps the keepalive signal fresh for the duration of a long
+// command (a refinery merge running a 30-minute test suite, for example).
+// Without it the signal is only touched at command start and a healthy
+// agent looks stale. It writes the keepalive of the agent session running
+// the command, so concurrent agents (and the daemon) never overwrite each
+// other's phase.
+//
+// Like the rest of this package it is best-effort: Start returns nil outside
+// a workspace, and all methods accept a nil receiver.
+//
+//	hb := keepalive.Start("gt refinery process", "running tests")
+//	defer hb.Stop()
+type Heartbeat struct {
+	root    string
+	session string // "" writes the workspace keepalive
+	command string
+	renew   bool // renew the identity lease of the session's worker
+
+	mu         sync.Mutex
+	phase      string
+	phaseStart time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// DaemonSession is the session the daemon's own heartbeat is recorded under.
+const DaemonSession = "daemon"
+
+// Start begins a heartbeat in the workspace containing the current directory.
+func Start(command, phase string) *Heartbeat {
+	root, err := workspace.FindFromCwd()
+	if err != nil || root == "" {
+		return nil
+	}
+	return StartInWorkspace(root, command, phase)
+}
+
+// StartInWorkspace begins a heartbeat for the current agent session in a
+// specific workspace (the workspace keepalive if the session is unknown).
+// It touches the keepalive immediately and then every HeartbeatInterval
+// until Stop.
+func StartInWorkspace(workspaceRoot, command, phase string) *Heartbeat {
+	return start(workspaceRoot, currentSession(), command, phase, true)
+}
+
+// StartForSession begins a heartbeat recorded under session, for processes
+// that aren't an agent session themselves (the daemon uses DaemonSession).
+func StartForSession(workspaceRoot, session, command, phase string) *Heartbeat {
+	return start(workspaceRoot, session, command, phase, false)
+}
+
+func start(workspaceRoot, session, command, phase string, renew bool) *Heartbeat {
+	h := &Heartbeat{
+		root:       workspaceRoot,
+		session:    session,
+		command:    command,
+		renew:      renew,
+		phase:      phase,
+		phaseStart: time.Now(),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	h.beat()
+	go h.run()
+	return h
+}
+
+func (h *Heartbeat) run() {
+	defer close(h.done)
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+			h.beat()
+		}
+	}
+}
+
+// beat writes the keepalive with the current phase and renews the lease.
+func (h *Heartbeat) beat() {
+	h.mu.Lock()
+	phase := formatPhase(h.phase, time.Since(h.phaseStart))
+	h.mu.Unlock()
+	h.write(phase)
+	if h.renew {
+		renewLease(h.root, h.session)
+	}
+}
+
+// write saves the keepalive with phase.
+func (h *Heartbeat) write(phase string) {
+	state := State{
+		LastCommand: h.command,
+		Timestamp:   time.Now().UTC(),
+		Phase:       phase,
+	}
+	if h.session == "" {
+		writeState(h.root, state)
+		return
+	}
+	_ = sessionDoc(h.root, h.session).Save(&state) // non-fatal: best-effort signal
+}
+
+// SetPhase switches to a new phase and refreshes the keepalive right away.
+func (h *Heartbeat) SetPhase(phase string) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	h.phase = phase
+	h.phaseStart = time.Now()
+	h.mu.Unlock()
+	h.beat()
+}
+
+// Stop ends the heartbeat and clears its phase, so status doesn't report a
+// finished command as still running.
+func (h *Heartbeat) Stop() {
+	if h == nil {
+		return
+	}
+	h.stopOnce.Do(func() {
+		close(h.stop)
+		<-h.done
+		h.write("")
+	})
+	<-h.done
+}
+
+// formatPhase appends the time spent in a phase: "running tests 12m".
+func formatPhase(phase string, elapsed time.Duration) string {
+	if phase == "" {
+		return ""
+	}
+	if elapsed < time.Minute {
+		return fmt.Sprintf("%s %ds", phase, int(elapsed.Seconds()))
+	}
+	if elapsed < time.Hour {
+		return fmt.Sprintf("%s %dm", phase, int(elapsed.Minutes()))
+	}
+	return fmt.Sprintf("%s %dh%02dm", phase, int(elapsed.Hours()), int(elapsed.Minutes())%60)
+}
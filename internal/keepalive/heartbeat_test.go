@@ -0,0 +1,110 @@
+package keepalive
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHeartbeat(t *testing.T) {
+	orig := HeartbeatInterval
+	HeartbeatInterval = 10 * time.Millisecond
+	defer func() { HeartbeatInterval = orig }()
+
+	// Outside any agent session: the workspace keepalive
+	for _, env := range []string{"TMUX_PANE", "GT_POLECAT", "GT_CREW", "GT_RIG"} {
+		t.Setenv(env, "")
+	}
+	tmpDir := t.TempDir()
+	hb := StartInWorkspace(tmpDir, "gt refinery", "running tests")
+
+	// Touched immediately
+	state := Read(tmpDir)
+	if state == nil || state.LastCommand != "gt refinery" || !strings.HasPrefix(state.Phase, "running tests ") {
+		t.Fatalf("initial state = %+v", state)
+	}
+
+	// Refreshed by the ticker
+	first := state.Timestamp
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if state := Read(tmpDir); state != nil && state.Timestamp.After(first) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("heartbeat did not refresh keepalive")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	hb.SetPhase("pushing")
+	if state := Read(tmpDir); state == nil || !strings.HasPrefix(state.Phase, "pushing ") {
+		t.Errorf("phase after SetPhase = %+v", state)
+	}
+
+	hb.Stop()
+	hb.Stop() // idempotent
+	stopped := Read(tmpDir)
+	if stopped.Phase != "" {
+		t.Errorf("phase after Stop = %q, want cleared", stopped.Phase)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if !Read(tmpDir).Timestamp.Equal(stopped.Timestamp) {
+		t.Error("keepalive refreshed after Stop")
+	}
+}
+
+func TestHeartbeatPerSession(t *testing.T) {
+	// Each session keeps its own phase; the daemon's heartbeat doesn't
+	// overwrite an agent's, and neither touches the workspace keepalive
+	tmpDir := t.TempDir()
+	agent := StartForSession(tmpDir, "gt-rig-nux", "gt done", "pushing polecat/nux")
+	defer agent.Stop()
+	daemon := StartForSession(tmpDir, DaemonSession, "gt daemon", "heartbeat")
+	defer daemon.Stop()
+
+	if state := ReadSession(tmpDir, "gt-rig-nux"); state == nil || !strings.HasPrefix(state.Phase, "pushing polecat/nux ") {
+		t.Errorf("agent keepalive = %+v", state)
+	}
+	if state := ReadSession(tmpDir, DaemonSession); state == nil || !strings.HasPrefix(state.Phase, "heartbeat ") {
+		t.Errorf("daemon keepalive = %+v", state)
+	}
+	if state := Read(tmpDir); state != nil {
+		t.Errorf("workspace keepalive = %+v, want untouched", state)
+	}
+	if state := ReadSession(tmpDir, "gt-rig-other"); state != nil {
+		t.Errorf("unknown session keepalive = %+v, want nil", state)
+	}
+
+	// A short command in the same session refreshes the signal and keeps
+	// the running command's phase
+	touchSession(tmpDir, "gt-rig-nux", "gt mail inbox")
+	if state := ReadSession(tmpDir, "gt-rig-nux"); state.LastCommand != "gt mail inbox" || !strings.HasPrefix(state.Phase, "pushing ") {
+		t.Errorf("after touch = %+v, want new command with phase kept", state)
+	}
+}
+
+func TestHeartbeatNil(t *testing.T) {
+	var hb *Heartbeat
+	hb.SetPhase("anything")
+	hb.Stop()
+}
+
+func TestFormatPhase(t *testing.T) {
+	tests := []struct {
+		elapsed time.Duration
+		want    string
+	}{
+		{12 * time.Second, "running tests 12s"},
+		{12*time.Minute + 30*time.Second, "running tests 12m"},
+		{time.Hour + 5*time.Minute, "running tests 1h05m"},
+	}
+	for _, tt := range tests {
+		if got := formatPhase("running tests", tt.elapsed); got != tt.want {
+			t.Errorf("formatPhase(%v) = %q, want %q", tt.elapsed, got, tt.want)
+		}
+	}
+	if got := formatPhase("", time.Minute); got != "" {
+		t.Errorf("formatPhase with empty phase = %q", got)
+	}
+}
@@ -9,7 +9,14 @@
 //
 // Functions in this package write JSON files to .runtime/ or daemon/ directories.
 // These files are used by the daemon to detect agent activity and implement
-// features like exponential backoff during idle periods.
+// features like exponential backoff during idle periods. Touching also renews
+// the identity lock lease of the worker the command runs in, so an active
+// agent keeps its identity and a dead one's lease runs out.
+//
+// Each agent session also has its own keepalive (.runtime/keepalive/<session>.json),
+// which carries the phase of a long command (see [Heartbeat]). Status output
+// and staleness checks read it with [ReadSession], so an agent busy in a long
+// test run isn't mistaken for a stuck one.
 //
 // # Sentinel Pattern
 //
@@ -33,12 +40,13 @@
 package keepalive
 
 import (
-	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/lock"
+	"github.com/steveyegge/gastown/internal/runstate"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -46,6 +54,7 @@ import (
 type State struct {
 	LastCommand string    `json:"last_command"`
 	Timestamp   time.Time `json:"timestamp"`
+	Phase       string    `json:"phase,omitempty"` // set by a Heartbeat, e.g. "running tests 12m"
 }
 
 // Touch updates the keepalive file in the workspace's .runtime directory.
@@ -69,30 +78,90 @@ func TouchWithArgs(command string, args []string) {
 	}
 
 	TouchInWorkspace(root, fullCmd)
+	session := currentSession()
+	if session != "" {
+		touchSession(root, session, fullCmd)
+	}
+	renewLease(root, session)
 }
 
-// TouchInWorkspace updates the keepalive file in a specific workspace.
-// It silently ignores errors (best-effort signaling).
-func TouchInWorkspace(workspaceRoot, command string) {
-	runtimeDir := filepath.Join(workspaceRoot, ".runtime")
+// currentSession returns the agent session this process runs in: the tmux
+// session name, or outside tmux the same fallback identity gt prime uses.
+// Returns "" when neither is known.
+func currentSession() string {
+	fallback := ""
+	name := os.Getenv("GT_POLECAT")
+	if name == "" {
+		name = os.Getenv("GT_CREW")
+	}
+	if rig := os.Getenv("GT_RIG"); rig != "" && name != "" {
+		fallback = rig + "/" + name
+	}
+	return lock.CurrentSessionID(fallback)
+}
 
-	// Ensure .runtime directory exists
-	if err := os.MkdirAll(runtimeDir, 0755); err != nil {
+// renewLease renews the identity lock of the worker containing the current
+// directory, if session holds it.
+// It silently ignores errors (best-effort signaling).
+func renewLease(workspaceRoot, session string) {
+	if session == "" {
 		return
 	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	l := lock.FindFrom(cwd)
+	if l == nil {
+		return
+	}
+	if rel, err := filepath.Rel(workspaceRoot, l.WorkerDir()); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return // Lock outside this workspace, or the town root itself
+	}
+	_ = l.Renew(session)
+}
 
-	state := State{
+// TouchInWorkspace updates the keepalive file in a specific workspace.
+// It silently ignores errors (best-effort signaling).
+func TouchInWorkspace(workspaceRoot, command string) {
+	writeState(workspaceRoot, State{
 		LastCommand: command,
 		Timestamp:   time.Now().UTC(),
-	}
+	})
+}
 
-	data, err := json.Marshal(state)
-	if err != nil {
-		return
-	}
+// stateDoc returns the keepalive document in the workspace's runtime state
+// store. A missing keepalive loads as nil.
+func stateDoc(workspaceRoot string) *runstate.Doc[State] {
+	return runstate.NewDoc(runstate.Open(workspaceRoot), "keepalive.json", func() *State { return nil })
+}
+
+// sessionDoc returns the keepalive document of one agent session.
+// A missing keepalive loads as the zero State.
+func sessionDoc(workspaceRoot, session string) *runstate.Doc[State] {
+	return runstate.NewDoc[State](runstate.Open(workspaceRoot), "keepalive/"+sessionFileName(session)+".json", nil)
+}
+
+// sessionFileName makes a session identity ("gt-rig-nux", "rig/nux", "%3")
+// safe to use as a file name.
+func sessionFileName(session string) string {
+	return strings.NewReplacer("/", "_", string(filepath.Separator), "_", "..", "_").Replace(session)
+}
 
-	keepalivePath := filepath.Join(runtimeDir, "keepalive.json")
-	_ = os.WriteFile(keepalivePath, data, 0644) // non-fatal: status file for debugging
+// writeState writes the keepalive file, ignoring errors.
+func writeState(workspaceRoot string, state State) {
+	// Atomic so readers never see a half-written file while a Heartbeat runs
+	_ = stateDoc(workspaceRoot).Save(&state) // non-fatal: status file for debugging
+}
+
+// touchSession refreshes a session's keepalive for command. The phase of a
+// Heartbeat running in the same session is kept; the Heartbeat clears it
+// when it stops.
+func touchSession(workspaceRoot, session, command string) {
+	_, _ = sessionDoc(workspaceRoot, session).Update(func(s *State) error {
+		s.LastCommand, s.Timestamp = command, time.Now().UTC()
+		return nil
+	})
 }
 
 // Read returns the current keepalive state for the workspace.
@@ -101,19 +170,24 @@ func TouchInWorkspace(workspaceRoot, command string) {
 // when the keepalive file doesn't exist, can't be read, or contains invalid JSON.
 // Callers can safely pass the result to [State.Age] without nil checks.
 func Read(workspaceRoot string) *State {
-	keepalivePath := filepath.Join(workspaceRoot, ".runtime", "keepalive.json")
-
-	data, err := os.ReadFile(keepalivePath)
+	state, err := stateDoc(workspaceRoot).Load()
 	if err != nil {
 		return nil
 	}
+	return state
+}
 
-	var state State
-	if err := json.Unmarshal(data, &state); err != nil {
+// ReadSession returns the keepalive of an agent session (a tmux session name
+// such as "gt-gastown-nux"), with the same nil sentinel as [Read].
+func ReadSession(workspaceRoot, session string) *State {
+	if session == "" {
 		return nil
 	}
-
-	return &state
+	state, err := sessionDoc(workspaceRoot, session).Load()
+	if err != nil || state.Timestamp.IsZero() {
+		return nil
+	}
+	return state
 }
 
 // Age returns how old the keepalive signal is.
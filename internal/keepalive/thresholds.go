@@ -0,0 +1,71 @@
+package keepalive
+
+import (
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// Freshness classes for agent activity.
+const (
+	Fresh     = "fresh"      // actively working
+	Stale     = "stale"      // quiet; possibly in a long operation
+	VeryStale = "very-stale" // idle or stuck; worth a nudge
+)
+
+// Thresholds are the activity ages at which an agent stops being fresh and
+// becomes very stale.
+type Thresholds struct {
+	Fresh time.Duration // younger than this is fresh
+	Stale time.Duration // this old or older is very stale
+}
+
+// DefaultThresholds are used when a rig doesn't configure keepalive.
+var DefaultThresholds = Thresholds{Fresh: 2 * time.Minute, Stale: 5 * time.Minute}
+
+// ThresholdsFromConfig returns thresholds from a keepalive config, using
+// defaults for unset or invalid values.
+func ThresholdsFromConfig(cfg *config.KeepaliveConfig) Thresholds {
+	th := DefaultThresholds
+	if cfg == nil {
+		return th
+	}
+	if d, err := time.ParseDuration(cfg.Fresh); err == nil && d > 0 {
+		th.Fresh = d
+	}
+	if d, err := time.ParseDuration(cfg.Stale); err == nil && d > 0 {
+		th.Stale = d
+	}
+	if th.Stale < th.Fresh {
+		// Only one was raised past the other's default
+		th.Stale = th.Fresh
+	}
+	return th
+}
+
+// RigThresholds returns the keepalive thresholds configured for a rig.
+func RigThresholds(rigPath string) Thresholds {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil {
+		return DefaultThresholds
+	}
+	return ThresholdsFromConfig(settings.Keepalive)
+}
+
+// Classify returns Fresh, Stale, or VeryStale for an activity age.
+func (th Thresholds) Classify(age time.Duration) string {
+	switch {
+	case age < th.Fresh:
+		return Fresh
+	case age < th.Stale:
+		return Stale
+	default:
+		return VeryStale
+	}
+}
+
+// Freshness classifies the keepalive signal. A nil State (no keepalive)
+// is VeryStale, following the sentinel pattern of Age.
+func (s *State) Freshness(th Thresholds) string {
+	return th.Classify(s.Age())
+}
@@ -0,0 +1,70 @@
+package keepalive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestThresholdsClassify(t *testing.T) {
+	th := DefaultThresholds
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{30 * time.Second, Fresh},
+		{3 * time.Minute, Stale},
+		{5 * time.Minute, VeryStale},
+	}
+	for _, tt := range tests {
+		if got := th.Classify(tt.age); got != tt.want {
+			t.Errorf("Classify(%v) = %q, want %q", tt.age, got, tt.want)
+		}
+	}
+
+	var missing *State
+	if got := missing.Freshness(th); got != VeryStale {
+		t.Errorf("nil State freshness = %q, want %q", got, VeryStale)
+	}
+}
+
+func TestThresholdsFromConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.KeepaliveConfig
+		want Thresholds
+	}{
+		{"nil", nil, DefaultThresholds},
+		{"both", &config.KeepaliveConfig{Fresh: "10m", Stale: "45m"}, Thresholds{10 * time.Minute, 45 * time.Minute}},
+		{"invalid falls back", &config.KeepaliveConfig{Fresh: "soon"}, DefaultThresholds},
+		{"fresh past default stale", &config.KeepaliveConfig{Fresh: "20m"}, Thresholds{20 * time.Minute, 20 * time.Minute}},
+	}
+	for _, tt := range tests {
+		if got := ThresholdsFromConfig(tt.cfg); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRigThresholds(t *testing.T) {
+	rigPath := t.TempDir()
+	if got := RigThresholds(rigPath); got != DefaultThresholds {
+		t.Errorf("no settings: got %+v, want defaults", got)
+	}
+
+	settings := config.NewRigSettings()
+	settings.Keepalive = &config.KeepaliveConfig{Fresh: "15m", Stale: "40m"}
+	if err := os.MkdirAll(filepath.Join(rigPath, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatal(err)
+	}
+	// A 30-minute test run is stale, not very stale, in this rig
+	if got := RigThresholds(rigPath).Classify(30 * time.Minute); got != Stale {
+		t.Errorf("Classify(30m) = %q, want %q", got, Stale)
+	}
+}
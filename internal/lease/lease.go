@@ -0,0 +1,388 @@
+// Package lease serializes access to shared resources with named leases.
+//
+// A lease is a named slot with a capacity: up to Capacity holders may hold
+// it at once. Agents that find it full can queue as waiters, and free
+// capacity goes to waiters in order, so a release hands the slot to the
+// next one in line. Holds can carry a TTL, after which the hold lapses
+// unless the holder renews it, so a crashed holder can't keep a resource
+// forever. Operators can force a release.
+//
+// The refinery's merge slot, which serializes conflict resolution in a rig,
+// is the lease "<rig>/merge-slot". Other serialized resources, such as
+// deploy locks, use their own names.
+//
+// Leases are stored as JSON files under the town's .runtime/leases
+// directory, one per lease; changes are serialized with a file lock, so
+// agents on the same host see a consistent view.
+package lease
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Errors returned by Store methods.
+var (
+	ErrHeld     = errors.New("lease is held")
+	ErrNotHeld  = errors.New("lease not held")
+	ErrNotFound = errors.New("lease not found")
+)
+
+// MergeSlot returns the name of a rig's merge slot lease.
+func MergeSlot(rig string) string {
+	return rig + "/merge-slot"
+}
+
+// Lease is the state of a named lease.
+type Lease struct {
+	Name     string        `json:"name"`
+	Capacity int           `json:"capacity"`
+	TTL      time.Duration `json:"ttl,omitempty"` // Length of holds; 0 = holds don't lapse
+	Holders  []Hold        `json:"holders,omitempty"`
+	Waiters  []string      `json:"waiters,omitempty"`
+}
+
+// Hold is one holder's claim on a lease.
+type Hold struct {
+	Holder   string    `json:"holder"`
+	Acquired time.Time `json:"acquired"`
+	Expires  time.Time `json:"expires,omitempty"` // Zero = never
+}
+
+// Available reports whether the lease has free capacity.
+func (l *Lease) Available() bool {
+	return len(l.Holders) < l.Capacity
+}
+
+// Hold returns holder's hold on the lease, or nil.
+func (l *Lease) Hold(holder string) *Hold {
+	for i := range l.Holders {
+		if l.Holders[i].Holder == holder {
+			return &l.Holders[i]
+		}
+	}
+	return nil
+}
+
+// Waiting returns holder's position in the waiters queue (1 = next), or 0.
+func (l *Lease) Waiting(holder string) int {
+	for i, w := range l.Waiters {
+		if w == holder {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// settle drops lapsed holds and grants free capacity to waiters. It
+// reports whether anything changed.
+func (l *Lease) settle(now time.Time) bool {
+	changed := false
+	kept := l.Holders[:0]
+	for _, h := range l.Holders {
+		if !h.Expires.IsZero() && !now.Before(h.Expires) {
+			changed = true
+			continue
+		}
+		kept = append(kept, h)
+	}
+	l.Holders = kept
+
+	for l.Available() && len(l.Waiters) > 0 {
+		next := l.Waiters[0]
+		l.Waiters = l.Waiters[1:]
+		if l.Hold(next) == nil {
+			l.grant(next, now)
+		}
+		changed = true
+	}
+	return changed
+}
+
+func (l *Lease) grant(holder string, now time.Time) {
+	h := Hold{Holder: holder, Acquired: now}
+	if l.TTL > 0 {
+		h.Expires = now.Add(l.TTL)
+	}
+	l.Holders = append(l.Holders, h)
+}
+
+func (l *Lease) removeWaiter(holder string) {
+	for i, w := range l.Waiters {
+		if w == holder {
+			l.Waiters = append(l.Waiters[:i], l.Waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// AcquireOptions configures Acquire.
+type AcquireOptions struct {
+	// Capacity and TTL configure the lease when set; leases are created
+	// with capacity 1 and no TTL.
+	Capacity int
+	TTL      time.Duration
+
+	// Wait queues the holder as a waiter if the lease is full. The hold is
+	// granted, without the waiter doing anything, when capacity frees up.
+	Wait bool
+}
+
+// Store reads and changes the leases of a town.
+type Store struct {
+	dir string
+	now func() time.Time
+}
+
+// NewStore returns the lease store of the town at townRoot.
+func NewStore(townRoot string) *Store {
+	return &Store{
+		dir: filepath.Join(constants.TownRuntimePath(townRoot), "leases"),
+		now: time.Now,
+	}
+}
+
+// Acquire takes a hold on the named lease for holder, creating the lease
+// if needed. Acquiring a lease already held renews the hold. If the lease
+// is full, Acquire returns the lease with ErrHeld (after queuing holder,
+// with opts.Wait).
+func (s *Store) Acquire(name, holder string, opts AcquireOptions) (*Lease, error) {
+	if holder == "" {
+		return nil, fmt.Errorf("acquiring lease %s: no holder", name)
+	}
+	var full bool
+	l, err := s.update(name, true, func(l *Lease, now time.Time) error {
+		if opts.Capacity > 0 {
+			l.Capacity = opts.Capacity
+		}
+		if opts.TTL > 0 {
+			l.TTL = opts.TTL
+		}
+		l.settle(now)
+
+		if h := l.Hold(holder); h != nil {
+			renew(l, h, now)
+			return nil
+		}
+		if l.Available() {
+			l.removeWaiter(holder)
+			l.grant(holder, now)
+			return nil
+		}
+		full = true
+		if opts.Wait && l.Waiting(holder) == 0 {
+			l.Waiters = append(l.Waiters, holder)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if full {
+		return l, fmt.Errorf("%w: %s", ErrHeld, name)
+	}
+	return l, nil
+}
+
+// Renew extends holder's hold on the named lease by the lease's TTL.
+func (s *Store) Renew(name, holder string) (*Lease, error) {
+	return s.update(name, false, func(l *Lease, now time.Time) error {
+		l.settle(now)
+		h := l.Hold(holder)
+		if h == nil {
+			return fmt.Errorf("%w: %s by %s", ErrNotHeld, name, holder)
+		}
+		renew(l, h, now)
+		return nil
+	})
+}
+
+func renew(l *Lease, h *Hold, now time.Time) {
+	if l.TTL > 0 {
+		h.Expires = now.Add(l.TTL)
+	}
+}
+
+// Release gives up holder's hold on the named lease, or its place among the
+// waiters. The freed capacity goes to the next waiter.
+func (s *Store) Release(name, holder string) (*Lease, error) {
+	return s.update(name, false, func(l *Lease, now time.Time) error {
+		l.settle(now)
+		if l.Waiting(holder) > 0 {
+			l.removeWaiter(holder)
+			return nil
+		}
+		if l.Hold(holder) == nil {
+			return fmt.Errorf("%w: %s by %s", ErrNotHeld, name, holder)
+		}
+		dropHolder(l, holder)
+		l.settle(now)
+		return nil
+	})
+}
+
+// ForceRelease drops holds on the named lease regardless of who holds it:
+// holder's, or every hold if holder is empty. Waiters keep their places
+// and get the freed capacity. It returns the holders that were dropped.
+func (s *Store) ForceRelease(name, holder string) ([]string, error) {
+	var dropped []string
+	_, err := s.update(name, false, func(l *Lease, now time.Time) error {
+		l.settle(now)
+		for _, h := range l.Holders {
+			if holder == "" || h.Holder == holder {
+				dropped = append(dropped, h.Holder)
+			}
+		}
+		if len(dropped) == 0 {
+			if holder == "" {
+				return nil
+			}
+			return fmt.Errorf("%w: %s by %s", ErrNotHeld, name, holder)
+		}
+		for _, d := range dropped {
+			dropHolder(l, d)
+		}
+		l.settle(now)
+		return nil
+	})
+	return dropped, err
+}
+
+func dropHolder(l *Lease, holder string) {
+	for i, h := range l.Holders {
+		if h.Holder == holder {
+			l.Holders = append(l.Holders[:i], l.Holders[i+1:]...)
+			return
+		}
+	}
+}
+
+// Get returns the named lease.
+func (s *Store) Get(name string) (*Lease, error) {
+	return s.update(name, false, func(l *Lease, now time.Time) error {
+		l.settle(now)
+		return nil
+	})
+}
+
+// List returns every lease in the town, sorted by name.
+func (s *Store) List() ([]*Lease, error) {
+	var names []string
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(strings.TrimSuffix(rel, ".json")))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing leases: %w", err)
+	}
+	sort.Strings(names)
+
+	var leases []*Lease
+	for _, name := range names {
+		l, err := s.Get(name)
+		if errors.Is(err, ErrNotFound) {
+			continue // Removed since the walk
+		}
+		if err != nil {
+			return nil, err
+		}
+		leases = append(leases, l)
+	}
+	return leases, nil
+}
+
+// ValidName checks a lease name: slash-separated segments of letters,
+// digits, '.', '_', and '-', such as "gastown/merge-slot" or "deploy".
+func ValidName(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty lease name")
+	}
+	for _, seg := range strings.Split(name, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return fmt.Errorf("invalid lease name %q", name)
+		}
+		for _, r := range seg {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			case r == '.' || r == '_' || r == '-':
+			default:
+				return fmt.Errorf("invalid lease name %q", name)
+			}
+		}
+	}
+	return nil
+}
+
+// update loads the named lease under its file lock, applies fn, and saves
+// the result if it changed. With create, a missing lease starts empty;
+// otherwise it is ErrNotFound. Errors from fn are returned as they are,
+// with nothing saved.
+func (s *Store) update(name string, create bool, fn func(l *Lease, now time.Time) error) (*Lease, error) {
+	if err := ValidName(name); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(s.dir, filepath.FromSlash(name)+".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating lease directory: %w", err)
+	}
+
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return nil, fmt.Errorf("locking lease %s: %w", name, err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	l := &Lease{Name: name, Capacity: 1}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, l); err != nil {
+			return nil, fmt.Errorf("reading lease %s: %w", name, err)
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		if !create {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+	default:
+		return nil, fmt.Errorf("reading lease %s: %w", name, err)
+	}
+
+	before, _ := json.Marshal(l)
+	if err := fn(l, s.now()); err != nil {
+		return nil, err
+	}
+	after, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	if string(before) != string(after) || data == nil {
+		if err := util.AtomicWriteJSON(path, l); err != nil {
+			return nil, fmt.Errorf("writing lease %s: %w", name, err)
+		}
+	}
+	return l, nil
+}
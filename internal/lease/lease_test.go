@@ -0,0 +1,224 @@
+package lease
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testStore(t *testing.T) (*Store, *time.Time) {
+	t.Helper()
+	s := NewStore(t.TempDir())
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func holders(l *Lease) []string {
+	var hs []string
+	for _, h := range l.Holders {
+		hs = append(hs, h.Holder)
+	}
+	return hs
+}
+
+func TestAcquireRelease(t *testing.T) {
+	s, _ := testStore(t)
+
+	l, err := s.Acquire("gastown/merge-slot", "gastown/refinery", AcquireOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Capacity != 1 || !reflect.DeepEqual(holders(l), []string{"gastown/refinery"}) {
+		t.Fatalf("after acquire: %+v", l)
+	}
+
+	// Acquiring again is a renewal, not a second hold.
+	if l, err = s.Acquire("gastown/merge-slot", "gastown/refinery", AcquireOptions{}); err != nil || len(l.Holders) != 1 {
+		t.Fatalf("reacquire = %+v, %v", l, err)
+	}
+
+	l, err = s.Acquire("gastown/merge-slot", "gastown/polecats/Toast", AcquireOptions{})
+	if !errors.Is(err, ErrHeld) {
+		t.Fatalf("acquire of full lease: err = %v, want ErrHeld", err)
+	}
+	if l == nil || l.Waiting("gastown/polecats/Toast") != 0 {
+		t.Fatalf("acquire without Wait queued the holder: %+v", l)
+	}
+
+	if _, err := s.Release("gastown/merge-slot", "gastown/polecats/Toast"); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("release by non-holder: err = %v, want ErrNotHeld", err)
+	}
+	if l, err = s.Release("gastown/merge-slot", "gastown/refinery"); err != nil || !l.Available() {
+		t.Fatalf("release = %+v, %v", l, err)
+	}
+	if _, err := s.Release("missing", "x"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("release of missing lease: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestWaitersGetFreedCapacity(t *testing.T) {
+	s, _ := testStore(t)
+
+	if _, err := s.Acquire("deploy", "a", AcquireOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range []string{"b", "c", "b"} {
+		if _, err := s.Acquire("deploy", w, AcquireOptions{Wait: true}); !errors.Is(err, ErrHeld) {
+			t.Fatalf("acquire by %s: err = %v, want ErrHeld", w, err)
+		}
+	}
+	l, _ := s.Get("deploy")
+	if !reflect.DeepEqual(l.Waiters, []string{"b", "c"}) {
+		t.Fatalf("waiters = %v, want [b c]", l.Waiters)
+	}
+
+	l, err := s.Release("deploy", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(holders(l), []string{"b"}) || !reflect.DeepEqual(l.Waiters, []string{"c"}) {
+		t.Fatalf("after release: holders %v, waiters %v", holders(l), l.Waiters)
+	}
+
+	// A waiter can give up its place.
+	if l, err = s.Release("deploy", "c"); err != nil || len(l.Waiters) != 0 {
+		t.Fatalf("waiter release = %+v, %v", l, err)
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	s, _ := testStore(t)
+
+	for _, h := range []string{"a", "b"} {
+		if _, err := s.Acquire("builders", h, AcquireOptions{Capacity: 2}); err != nil {
+			t.Fatalf("acquire by %s: %v", h, err)
+		}
+	}
+	if _, err := s.Acquire("builders", "c", AcquireOptions{}); !errors.Is(err, ErrHeld) {
+		t.Fatalf("third holder: err = %v, want ErrHeld", err)
+	}
+	// Raising the capacity lets the next holder in.
+	if _, err := s.Acquire("builders", "c", AcquireOptions{Capacity: 3}); err != nil {
+		t.Fatalf("acquire after raising capacity: %v", err)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	s, now := testStore(t)
+
+	if _, err := s.Acquire("deploy", "a", AcquireOptions{TTL: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Acquire("deploy", "b", AcquireOptions{Wait: true}); !errors.Is(err, ErrHeld) {
+		t.Fatalf("err = %v, want ErrHeld", err)
+	}
+
+	*now = now.Add(50 * time.Minute)
+	if _, err := s.Renew("deploy", "a"); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(50 * time.Minute)
+	l, _ := s.Get("deploy")
+	if !reflect.DeepEqual(holders(l), []string{"a"}) {
+		t.Fatalf("renewed hold lapsed: holders %v", holders(l))
+	}
+
+	// Without renewal the hold lapses and passes to the waiter.
+	*now = now.Add(time.Hour)
+	l, _ = s.Get("deploy")
+	if !reflect.DeepEqual(holders(l), []string{"b"}) {
+		t.Fatalf("after lapse: holders %v, want [b]", holders(l))
+	}
+	if _, err := s.Renew("deploy", "a"); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("renewing lapsed hold: err = %v, want ErrNotHeld", err)
+	}
+}
+
+func TestForceRelease(t *testing.T) {
+	s, _ := testStore(t)
+
+	_, _ = s.Acquire("builders", "a", AcquireOptions{Capacity: 2})
+	_, _ = s.Acquire("builders", "b", AcquireOptions{})
+	_, _ = s.Acquire("builders", "c", AcquireOptions{Wait: true})
+
+	dropped, err := s.ForceRelease("builders", "b")
+	if err != nil || !reflect.DeepEqual(dropped, []string{"b"}) {
+		t.Fatalf("ForceRelease(b) = %v, %v", dropped, err)
+	}
+	l, _ := s.Get("builders")
+	if !reflect.DeepEqual(holders(l), []string{"a", "c"}) {
+		t.Fatalf("after forced release: holders %v, want [a c]", holders(l))
+	}
+
+	dropped, err = s.ForceRelease("builders", "")
+	if err != nil || !reflect.DeepEqual(dropped, []string{"a", "c"}) {
+		t.Fatalf("ForceRelease(all) = %v, %v", dropped, err)
+	}
+	if _, err := s.ForceRelease("builders", "z"); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("ForceRelease(z): err = %v, want ErrNotHeld", err)
+	}
+}
+
+func TestConcurrentAcquire(t *testing.T) {
+	s := NewStore(t.TempDir())
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	won := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := s.Acquire("deploy", string(rune('a'+i)), AcquireOptions{})
+			if err == nil {
+				mu.Lock()
+				won++
+				mu.Unlock()
+			} else if !errors.Is(err, ErrHeld) {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if won != 1 {
+		t.Errorf("%d holders acquired a capacity-1 lease", won)
+	}
+}
+
+func TestList(t *testing.T) {
+	s, _ := testStore(t)
+
+	for _, name := range []string{"gastown/merge-slot", "deploy", "beads/merge-slot"} {
+		if _, err := s.Acquire(name, "x", AcquireOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	leases, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, l := range leases {
+		names = append(names, l.Name)
+	}
+	want := []string{"beads/merge-slot", "deploy", "gastown/merge-slot"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("List = %v, want %v", names, want)
+	}
+}
+
+func TestValidName(t *testing.T) {
+	for _, name := range []string{"deploy", "gastown/merge-slot", "a.b_c-d"} {
+		if err := ValidName(name); err != nil {
+			t.Errorf("ValidName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "/deploy", "a//b", "../x", "a/./b", "dep loy", "a:b"} {
+		if err := ValidName(name); err == nil {
+			t.Errorf("ValidName(%q) = nil, want error", name)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package lock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/townlog"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// logEvent records a lock lifecycle event in the town log of the workspace
+// containing the worker, naming the lock holder so identity problems can be
+// investigated after the fact. Best-effort: locks outside a workspace are
+// not logged and errors are ignored.
+func (l *Lock) logEvent(eventType townlog.EventType, info *LockInfo, detail string) {
+	townRoot, err := workspace.Find(l.workerDir)
+	if err != nil || townRoot == "" {
+		return
+	}
+
+	msg := "holder " + info.describeHolder()
+	if detail != "" {
+		msg += "; " + detail
+	}
+	_ = townlog.NewLogger(townRoot).Log(eventType, workerAgent(townRoot, l.workerDir), msg)
+}
+
+// workerAgent names a worker directory for the town log: the first three
+// path segments under the town root (e.g. "gastown/polecats/Toast").
+func workerAgent(townRoot, workerDir string) string {
+	rel, err := filepath.Rel(townRoot, workerDir)
+	if err != nil {
+		return workerDir
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) > 3 {
+		parts = parts[:3]
+	}
+	return strings.Join(parts, "/")
+}
+
+// describeHolder formats the holder identity as key=value pairs.
+func (l *LockInfo) describeHolder() string {
+	fields := []string{
+		"session=" + l.SessionID,
+		fmt.Sprintf("pid=%d", l.PID),
+		"host=" + l.Hostname,
+	}
+	if l.Agent != "" {
+		fields = append(fields, "agent="+l.Agent)
+	}
+	if !l.ExpiresAt.IsZero() {
+		fields = append(fields, "expires="+l.ExpiresAt.Format(time.RFC3339))
+	}
+	return strings.Join(fields, " ")
+}
+
+// describeClaimant formats the identity of the current process trying to
+// claim a lock, in the same form as describeHolder.
+func describeClaimant(sessionID string) string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("session=%s pid=%d host=%s", sessionID, os.Getpid(), hostname)
+}
@@ -2,29 +2,55 @@
 // from claiming the same worker identity.
 //
 // Lock files are stored at <worker>/.runtime/agent.lock and contain:
-// - PID of the owning process
+// - PID of the process that last wrote the lock
+// - PID of the holder's tmux pane, the root of the agent's process tree
 // - Timestamp when lock was acquired
-// - Session ID (tmux session name)
+// - Session ID (tmux session name) identifying the holder
+// - Lease expiry, extended each time the holder renews
+// - Holder role, rig, agent preset, and start command
 //
-// Stale locks (where the PID is dead) are automatically cleaned up.
+// Locks are leases: the holder renews them (every gt command run in the
+// worker renews via keepalive), and a lease that has expired can be claimed
+// by another agent without anyone deleting files. Lease expiry works across
+// containers and hosts, where PID checks don't.
+//
+// On the holder's own host, a lock taken from tmux is also tied to the
+// agent's pane: the gt process that wrote the lock is short-lived, but the
+// pane process (and the agent running under it) lives exactly as long as
+// the agent does, so a dead pane frees the lock before its lease runs out.
 package lock
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"syscall"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/townlog"
+	"github.com/steveyegge/gastown/internal/util"
 )
 
+// DefaultLeaseTTL is how long a lease lasts without renewal.
+const DefaultLeaseTTL = 30 * time.Minute
+
+// guardTimeout bounds how long lock operations wait for the guard flock.
+// Holders only keep it for a read and a write, so contention is brief.
+const guardTimeout = 5 * time.Second
+
 // Common errors
 var (
 	ErrLocked      = errors.New("worker is locked by another agent")
 	ErrNotLocked   = errors.New("worker is not locked")
 	ErrInvalidLock = errors.New("invalid lock file")
+	ErrGuardBusy   = errors.New("timed out waiting for lock guard")
 )
 
 // LockInfo contains information about who holds a lock.
@@ -32,59 +58,284 @@ type LockInfo struct {
 	PID       int       `json:"pid"`
 	AcquiredAt time.Time `json:"acquired_at"`
 	SessionID string    `json:"session_id,omitempty"`
+	PanePID   int       `json:"pane_pid,omitempty"` // tmux pane process of the holder, if any
 	Hostname  string    `json:"hostname,omitempty"`
+	RenewedAt time.Time `json:"renewed_at"`
+	ExpiresAt time.Time `json:"expires_at"` // zero for locks written before leases
+	Holder
+}
+
+// Holder describes the agent holding a lock, for humans inspecting locks.
+type Holder struct {
+	Role    string `json:"role,omitempty"`
+	Rig     string `json:"rig,omitempty"`
+	Agent   string `json:"agent,omitempty"`   // agent preset (claude, codex, ...)
+	Command string `json:"command,omitempty"` // command the agent was started with
+}
+
+// Lock states reported by LockInfo.State.
+const (
+	StateActive  = "active"  // agent pane alive, lease valid, or legacy lock with a live PID
+	StateExpired = "expired" // lease ran out; claimable
+	StateDead    = "dead"    // local agent process is gone; claimable
+)
+
+// State returns StateActive, StateExpired, or StateDead.
+func (l *LockInfo) State() string {
+	switch {
+	case !l.IsStale():
+		return StateActive
+	case l.IsLocal() && l.PanePID > 0 && !processExists(l.PanePID):
+		return StateDead
+	case l.IsLocal() && l.PanePID == 0 && l.ExpiresAt.IsZero():
+		return StateDead
+	default:
+		return StateExpired
+	}
 }
 
-// IsStale checks if the lock is stale (owning process is dead).
+// IsStale reports whether the lock can be claimed by another agent.
+//
+// An expired lease is always stale: a hung agent whose pane is still alive
+// stops renewing and loses its identity like any other. Before expiry, on
+// this host, a lock that records the holder's tmux pane is also stale once
+// that pane process is gone, since the agent died without releasing it.
+// Locks written before leases existed carry no expiry: on this host they
+// fall back to checking whether the writing process is alive; from another
+// host (shared filesystem), where the PID means nothing here, they are
+// treated as a lease of DefaultLeaseTTL from acquisition.
 func (l *LockInfo) IsStale() bool {
+	if !l.ExpiresAt.IsZero() && time.Now().After(l.ExpiresAt) {
+		return true
+	}
+	if l.PanePID > 0 && l.IsLocal() {
+		return !processExists(l.PanePID)
+	}
+	if !l.ExpiresAt.IsZero() {
+		return false
+	}
+	if !l.IsLocal() {
+		return time.Now().After(l.AcquiredAt.Add(DefaultLeaseTTL))
+	}
 	return !processExists(l.PID)
 }
 
+// IsLocal reports whether the lock was taken on this host.
+// Locks without a hostname are assumed local.
+func (l *LockInfo) IsLocal() bool {
+	if l.Hostname == "" {
+		return true
+	}
+	hostname, _ := os.Hostname()
+	return l.Hostname == hostname
+}
+
+// agentPID returns the PID that best represents the holding agent:
+// its pane process if known, else the process that wrote the lock.
+func (l *LockInfo) agentPID() int {
+	if l.PanePID > 0 {
+		return l.PanePID
+	}
+	return l.PID
+}
+
+// ownedByProcess reports whether the current process wrote the lock.
+// PIDs are only comparable on the same host.
+func (l *LockInfo) ownedByProcess() bool {
+	return l.PID == os.Getpid() && l.IsLocal()
+}
+
+// HeldBy reports whether the lock belongs to the given session on this
+// host, or was written by the current process.
+func (l *LockInfo) HeldBy(sessionID string) bool {
+	if l.ownedByProcess() {
+		return true
+	}
+	return sessionID != "" && l.SessionID == sessionID && l.IsLocal()
+}
+
 // Lock represents an agent identity lock for a worker directory.
+//
+// Acquire, Renew, and stale cleanup decide and write while holding an OS
+// file lock (flock on Unix, LockFileEx on Windows) on a sibling guard file,
+// so concurrent gt prime runs can't both see the lock as free and both
+// claim the identity.
 type Lock struct {
 	workerDir string
 	lockPath  string
+	guardPath string
+	ttl       time.Duration
+	holder    Holder
 }
 
 // New creates a Lock for the given worker directory.
 func New(workerDir string) *Lock {
+	lockPath := filepath.Join(workerDir, ".runtime", "agent.lock")
 	return &Lock{
 		workerDir: workerDir,
-		lockPath:  filepath.Join(workerDir, ".runtime", "agent.lock"),
+		lockPath:  lockPath,
+		guardPath: lockPath + ".guard",
+		ttl:       DefaultLeaseTTL,
+	}
+}
+
+// withGuard runs fn while holding the guard flock.
+// The guard file is never removed: deleting a flock file lets a waiter lock
+// the old inode while a newcomer locks a new one.
+func (l *Lock) withGuard(fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(l.guardPath), 0755); err != nil {
+		return fmt.Errorf("creating lock directory: %w", err)
 	}
+
+	guard := flock.New(l.guardPath)
+	ctx, cancel := context.WithTimeout(context.Background(), guardTimeout)
+	defer cancel()
+
+	locked, err := guard.TryLockContext(ctx, 20*time.Millisecond)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("locking %s: %w", l.guardPath, err)
+	}
+	if !locked {
+		return fmt.Errorf("%w: %s", ErrGuardBusy, l.guardPath)
+	}
+	defer func() { _ = guard.Unlock() }()
+
+	return fn()
+}
+
+// WorkerDir returns the worker directory this lock guards.
+func (l *Lock) WorkerDir() string {
+	return l.workerDir
+}
+
+// WithHolder sets the holder metadata recorded when acquiring.
+// Renewals keep the metadata already in the lock unless one is set.
+func (l *Lock) WithHolder(h Holder) *Lock {
+	l.holder = h
+	return l
+}
+
+// WithTTL sets the lease duration used when acquiring or renewing.
+func (l *Lock) WithTTL(ttl time.Duration) *Lock {
+	l.ttl = ttl
+	return l
 }
 
 // Acquire attempts to acquire the lock for this worker.
-// Returns ErrLocked if another live process holds the lock.
-// Automatically cleans up stale locks.
+// Returns ErrLocked if another holder's lease is still valid.
+// A lease we already hold is renewed; an expired lease is taken over.
 func (l *Lock) Acquire(sessionID string) error {
-	// Check for existing lock
-	info, err := l.Read()
-	if err == nil {
-		// Lock exists - check if stale
-		if info.IsStale() {
-			// Stale lock - remove it
-			if err := l.Release(); err != nil {
-				return fmt.Errorf("removing stale lock: %w", err)
+	return l.withGuard(func() error {
+		info, err := l.Read()
+		if err == nil {
+			if info.HeldBy(sessionID) {
+				// We already hold it - renew
+				return l.write(sessionID, info)
 			}
-		} else {
-			// Active lock - check if it's us
-			if info.PID == os.Getpid() {
-				// We already hold it - refresh
-				return l.write(sessionID)
+			if !info.IsStale() {
+				l.logEvent(townlog.EventLockCollision, info, "claimed by "+describeClaimant(sessionID))
+				return fmt.Errorf("%w: %s", ErrLocked, info.describe())
 			}
-			// Another process holds it
-			return fmt.Errorf("%w: PID %d (session: %s, acquired: %s)",
-				ErrLocked, info.PID, info.SessionID, info.AcquiredAt.Format(time.RFC3339))
+			// Expired lease: claim it by overwriting, no cleanup needed
+			l.logEvent(townlog.EventLockStale, info, info.State()+", taken over by "+describeClaimant(sessionID))
+		} else if !errors.Is(err, ErrNotLocked) && !errors.Is(err, ErrInvalidLock) {
+			return err
+		}
+		if err := l.write(sessionID, nil); err != nil {
+			return err
+		}
+		if info, err := l.Read(); err == nil {
+			l.logEvent(townlog.EventLockAcquired, info, "")
+		}
+		return nil
+	})
+}
+
+// Renew extends the lease if sessionID holds it.
+// Returns ErrNotLocked if there is no lock, ErrLocked if someone else holds it.
+func (l *Lock) Renew(sessionID string) error {
+	return l.withGuard(func() error {
+		info, err := l.Read()
+		if err != nil {
+			return err
+		}
+		if !info.HeldBy(sessionID) {
+			return fmt.Errorf("%w: %s", ErrLocked, info.describe())
+		}
+		return l.write(sessionID, info)
+	})
+}
+
+// ReleaseIfStale removes the lock if it is stale, reporting whether it did.
+func (l *Lock) ReleaseIfStale() (bool, error) {
+	info, err := l.Read()
+	if err != nil {
+		if errors.Is(err, ErrNotLocked) {
+			return false, nil
 		}
+		return false, err
 	}
+	return l.releaseIfUnchanged(info)
+}
 
-	// No lock or stale lock removed - acquire it
-	return l.write(sessionID)
+// releaseIfUnchanged removes the lock only if it still matches seen and is
+// still stale, so a cleanup racing with a fresh Acquire can't delete the
+// new holder's lock.
+func (l *Lock) releaseIfUnchanged(seen *LockInfo) (bool, error) {
+	released := false
+	err := l.withGuard(func() error {
+		info, err := l.Read()
+		if err != nil {
+			if errors.Is(err, ErrNotLocked) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsStale() || info.PID != seen.PID || info.SessionID != seen.SessionID ||
+			!info.AcquiredAt.Equal(seen.AcquiredAt) || !info.ExpiresAt.Equal(seen.ExpiresAt) {
+			return nil
+		}
+		if err := l.remove(); err != nil {
+			return err
+		}
+		l.logEvent(townlog.EventLockStale, info, info.State()+", removed")
+		released = true
+		return nil
+	})
+	return released, err
+}
+
+// describe summarizes the lock holder for error messages.
+func (l *LockInfo) describe() string {
+	desc := fmt.Sprintf("PID %d (session: %s, acquired: %s", l.PID, l.SessionID, l.AcquiredAt.Format(time.RFC3339))
+	if l.PanePID > 0 {
+		desc += fmt.Sprintf(", pane PID: %d", l.PanePID)
+	}
+	if !l.ExpiresAt.IsZero() {
+		desc += ", lease expires: " + l.ExpiresAt.Format(time.RFC3339)
+	}
+	return desc + ")"
 }
 
 // Release releases the lock if we hold it.
 func (l *Lock) Release() error {
+	return l.release("")
+}
+
+// release removes the lock, logging who held it.
+func (l *Lock) release(detail string) error {
+	info, _ := l.Read()
+	if err := l.remove(); err != nil {
+		return err
+	}
+	if info != nil {
+		l.logEvent(townlog.EventLockReleased, info, detail)
+	}
+	return nil
+}
+
+// remove deletes the lock file.
+func (l *Lock) remove() error {
 	if err := os.Remove(l.lockPath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("removing lock file: %w", err)
 	}
@@ -125,17 +376,17 @@ func (l *Lock) Check() error {
 	// Check if stale
 	if info.IsStale() {
 		// Clean up stale lock (best-effort cleanup)
-		_ = l.Release()
+		_, _ = l.releaseIfUnchanged(info)
 		return nil
 	}
 
 	// Check if it's us
-	if info.PID == os.Getpid() {
+	if info.ownedByProcess() {
 		return nil
 	}
 
-	// Locked by another process
-	return fmt.Errorf("%w: PID %d (session: %s)", ErrLocked, info.PID, info.SessionID)
+	// Locked by another agent
+	return fmt.Errorf("%w: %s", ErrLocked, info.describe())
 }
 
 // Status returns a human-readable status of the lock.
@@ -149,10 +400,16 @@ func (l *Lock) Status() string {
 	}
 
 	if info.IsStale() {
-		return fmt.Sprintf("stale (dead PID %d)", info.PID)
+		if info.State() == StateDead {
+			return fmt.Sprintf("stale (dead PID %d)", info.agentPID())
+		}
+		if info.ExpiresAt.IsZero() {
+			return fmt.Sprintf("stale (taken on %s at %s, no lease)", info.Hostname, info.AcquiredAt.Format(time.RFC3339))
+		}
+		return fmt.Sprintf("stale (lease expired %s)", info.ExpiresAt.Format(time.RFC3339))
 	}
 
-	if info.PID == os.Getpid() {
+	if info.ownedByProcess() {
 		return "locked (by us)"
 	}
 
@@ -162,23 +419,41 @@ func (l *Lock) Status() string {
 // ForceRelease removes the lock regardless of who holds it.
 // Use with caution - only for doctor --fix scenarios.
 func (l *Lock) ForceRelease() error {
-	return l.Release()
+	return l.release("forced")
 }
 
-// write creates or updates the lock file.
-func (l *Lock) write(sessionID string) error {
+// write creates or updates the lock file with a fresh lease.
+// A nil prev starts a new acquisition; otherwise its acquisition time,
+// holder metadata, and pane PID (when not running in tmux) carry over.
+// The file is replaced atomically so readers never see a partial lock.
+func (l *Lock) write(sessionID string, prev *LockInfo) error {
 	// Ensure .runtime directory exists
 	dir := filepath.Dir(l.lockPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("creating lock directory: %w", err)
 	}
 
+	now := time.Now()
+	acquiredAt, holder, panePID := now, l.holder, currentPanePID()
+	if prev != nil {
+		acquiredAt = prev.AcquiredAt
+		if holder == (Holder{}) {
+			holder = prev.Holder
+		}
+		if panePID == 0 {
+			panePID = prev.PanePID
+		}
+	}
 	hostname, _ := os.Hostname()
 	info := LockInfo{
 		PID:        os.Getpid(),
-		AcquiredAt: time.Now(),
+		AcquiredAt: acquiredAt,
 		SessionID:  sessionID,
+		PanePID:    panePID,
 		Hostname:   hostname,
+		RenewedAt:  now,
+		ExpiresAt:  now.Add(l.ttl),
+		Holder:     holder,
 	}
 
 	data, err := json.MarshalIndent(info, "", "  ")
@@ -186,28 +461,82 @@ func (l *Lock) write(sessionID string) error {
 		return fmt.Errorf("marshaling lock info: %w", err)
 	}
 
-	if err := os.WriteFile(l.lockPath, data, 0644); err != nil { //nolint:gosec // G306: lock files are non-sensitive operational data
+	if err := util.AtomicWriteFile(l.lockPath, data, 0644); err != nil {
 		return fmt.Errorf("writing lock file: %w", err)
 	}
 
 	return nil
 }
 
-// processExists checks if a process with the given PID exists and is alive.
-func processExists(pid int) bool {
-	if pid <= 0 {
-		return false
+// FindFrom returns the lock for the nearest worker directory at or above
+// dir that has a lock file, or nil if there is none.
+func FindFrom(dir string) *Lock {
+	for {
+		l := New(dir)
+		if _, err := os.Stat(l.lockPath); err == nil {
+			return l
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
 	}
+}
 
-	// On Unix, sending signal 0 checks if process exists without affecting it
-	process, err := os.FindProcess(pid)
+// CurrentSessionID returns the identity an agent uses as lock holder: the
+// tmux session name when running in tmux (stable across restarts of the
+// same agent, unlike pane IDs), else $TMUX_PANE, else fallback.
+func CurrentSessionID(fallback string) string {
+	pane := os.Getenv("TMUX_PANE")
+	if pane == "" {
+		return fallback
+	}
+	if name := paneValue(pane, "#{session_name}"); name != "" {
+		return name
+	}
+	return pane
+}
+
+// currentPanePID returns the PID of the tmux pane this process runs in:
+// the pane's root process, under which the agent and every gt command it
+// runs are descendants. Returns 0 outside tmux.
+func currentPanePID() int {
+	pane := os.Getenv("TMUX_PANE")
+	if pane == "" {
+		return 0
+	}
+	pid, err := strconv.Atoi(paneValue(pane, "#{pane_pid}"))
 	if err != nil {
-		return false
+		return 0
 	}
+	return pid
+}
 
-	// Try to send signal 0 - this will fail if process doesn't exist
-	err = process.Signal(syscall.Signal(0))
-	return err == nil
+// paneValues caches what tmux reports about a pane, keyed by pane and
+// format. Neither the pane's session nor its PID changes while this process
+// runs, and the lease is renewed on every gt command.
+var paneValues = struct {
+	sync.Mutex
+	m map[string]string
+}{m: make(map[string]string)}
+
+// paneValue returns tmux's expansion of format for pane, or "" if tmux
+// can't say. Only answers are cached, so a failed query is retried.
+func paneValue(pane, format string) string {
+	key := pane + "\x00" + format
+	paneValues.Lock()
+	defer paneValues.Unlock()
+	if v, ok := paneValues.m[key]; ok {
+		return v
+	}
+	out, err := execCommand("tmux", "display-message", "-p", "-t", pane, format).Output()
+	v := strings.TrimSpace(string(out))
+	if err != nil || v == "" {
+		return ""
+	}
+	paneValues.m[key] = v
+	return v
 }
 
 // FindAllLocks scans a directory tree for agent.lock files.
@@ -267,7 +596,7 @@ func CleanStaleLocks(root string) (int, error) {
 			}
 			// Both PID dead AND no session = truly stale
 			lock := New(workerDir)
-			if err := lock.Release(); err == nil {
+			if released, err := lock.releaseIfUnchanged(info); err == nil && released {
 				cleaned++
 			}
 		}
@@ -385,8 +714,13 @@ func DetectCollisions(root string, activeSessions []string) []string {
 	for workerDir, info := range locks {
 		if info.IsStale() {
 			collisions = append(collisions,
-				fmt.Sprintf("stale lock in %s (dead PID %d, session: %s)",
-					workerDir, info.PID, info.SessionID))
+				fmt.Sprintf("stale lock in %s (%s, PID %d, session: %s)",
+					workerDir, info.State(), info.PID, info.SessionID))
+			continue
+		}
+
+		// Sessions on other hosts aren't visible to the local tmux
+		if !info.IsLocal() {
 			continue
 		}
 
@@ -394,7 +728,7 @@ func DetectCollisions(root string, activeSessions []string) []string {
 		if info.SessionID != "" && !activeSet[info.SessionID] {
 			collisions = append(collisions,
 				fmt.Sprintf("orphaned lock in %s (session %s not found, PID %d still alive)",
-					workerDir, info.SessionID, info.PID))
+					workerDir, info.SessionID, info.agentPID()))
 		}
 	}
 
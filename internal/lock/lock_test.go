@@ -2,10 +2,15 @@ package lock
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/gofrs/flock"
 )
 
 func TestNew(t *testing.T) {
@@ -663,3 +668,413 @@ func TestLock_CheckCleansUpStaleLock(t *testing.T) {
 		t.Error("Check() should have removed stale lock file")
 	}
 }
+
+// writeLockInfo writes a lock file for workerDir directly, as another agent would.
+func writeLockInfo(t *testing.T, workerDir string, info LockInfo) {
+	t.Helper()
+	runtimeDir := filepath.Join(workerDir, ".runtime")
+	if err := os.MkdirAll(runtimeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(info)
+	if err := os.WriteFile(filepath.Join(runtimeDir, "agent.lock"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLockInfo_IsStaleLease(t *testing.T) {
+	// A live lease is not stale even if the writing PID is unknown here
+	// (e.g. it lives in another container)
+	live := LockInfo{PID: 999999999, ExpiresAt: time.Now().Add(time.Minute)}
+	if live.IsStale() {
+		t.Error("unexpired lease reported stale")
+	}
+	// An expired lease is stale even if the PID happens to be alive
+	expired := LockInfo{PID: os.Getpid(), ExpiresAt: time.Now().Add(-time.Minute)}
+	if !expired.IsStale() {
+		t.Error("expired lease not reported stale")
+	}
+}
+
+func TestLock_AcquireRespectsLiveLease(t *testing.T) {
+	workerDir := filepath.Join(t.TempDir(), "worker")
+	writeLockInfo(t, workerDir, LockInfo{
+		PID:        999999999,
+		AcquiredAt: time.Now(),
+		SessionID:  "other-session",
+		ExpiresAt:  time.Now().Add(time.Minute),
+	})
+
+	err := New(workerDir).Acquire("new-session")
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("Acquire() error = %v, want ErrLocked", err)
+	}
+	if !contains(err.Error(), "lease expires") {
+		t.Errorf("error %q does not mention lease expiry", err)
+	}
+}
+
+func TestLock_AcquireExpiredLease(t *testing.T) {
+	workerDir := filepath.Join(t.TempDir(), "worker")
+	writeLockInfo(t, workerDir, LockInfo{
+		PID:        1, // alive, but the lease has run out
+		AcquiredAt: time.Now().Add(-time.Hour),
+		SessionID:  "other-session",
+		ExpiresAt:  time.Now().Add(-time.Minute),
+	})
+
+	l := New(workerDir).WithTTL(time.Minute)
+	if err := l.Acquire("new-session"); err != nil {
+		t.Fatalf("Acquire() of expired lease error = %v", err)
+	}
+	info, err := l.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.SessionID != "new-session" {
+		t.Errorf("SessionID = %q, want new-session", info.SessionID)
+	}
+	if time.Until(info.ExpiresAt) <= 0 || time.Until(info.ExpiresAt) > time.Minute {
+		t.Errorf("ExpiresAt = %v, want within the next minute", info.ExpiresAt)
+	}
+}
+
+func TestLock_RenewBySession(t *testing.T) {
+	workerDir := filepath.Join(t.TempDir(), "worker")
+	acquired := time.Now().Add(-time.Hour).Truncate(time.Second)
+	hostname, _ := os.Hostname()
+	// Lock written by an earlier process of the same session (e.g. before a restart)
+	writeLockInfo(t, workerDir, LockInfo{
+		PID:        999999999,
+		AcquiredAt: acquired,
+		SessionID:  "gt-rig-toast",
+		Hostname:   hostname,
+		ExpiresAt:  time.Now().Add(time.Second),
+	})
+
+	l := New(workerDir)
+	if err := l.Renew("gt-rig-other"); !errors.Is(err, ErrLocked) {
+		t.Errorf("Renew() by other session error = %v, want ErrLocked", err)
+	}
+	if err := l.Renew("gt-rig-toast"); err != nil {
+		t.Fatalf("Renew() error = %v", err)
+	}
+	info, err := l.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.AcquiredAt.Equal(acquired) {
+		t.Errorf("AcquiredAt = %v, want preserved %v", info.AcquiredAt, acquired)
+	}
+	if time.Until(info.ExpiresAt) < DefaultLeaseTTL-time.Minute {
+		t.Errorf("ExpiresAt = %v, lease not extended", info.ExpiresAt)
+	}
+
+	if err := New(t.TempDir()).Renew("gt-rig-toast"); !errors.Is(err, ErrNotLocked) {
+		t.Errorf("Renew() without lock error = %v, want ErrNotLocked", err)
+	}
+}
+
+func TestFindFrom(t *testing.T) {
+	workerDir := filepath.Join(t.TempDir(), "worker")
+	writeLockInfo(t, workerDir, LockInfo{PID: os.Getpid()})
+	sub := filepath.Join(workerDir, "src", "pkg")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	l := FindFrom(sub)
+	if l == nil || l.WorkerDir() != workerDir {
+		t.Fatalf("FindFrom() = %v, want lock for %s", l, workerDir)
+	}
+	if FindFrom(t.TempDir()) != nil {
+		t.Error("FindFrom() found a lock where there is none")
+	}
+}
+
+func TestCurrentSessionID(t *testing.T) {
+	origExecCommand := execCommand
+	defer func() { execCommand = origExecCommand }()
+	execCommand = func(name string, args ...string) interface{ Output() ([]byte, error) } {
+		return &mockCmd{output: []byte("gt-rig-toast\n")}
+	}
+
+	t.Setenv("TMUX_PANE", "")
+	if got := CurrentSessionID("rig/toast"); got != "rig/toast" {
+		t.Errorf("outside tmux: got %q, want fallback", got)
+	}
+	t.Setenv("TMUX_PANE", "%3")
+	if got := CurrentSessionID("rig/toast"); got != "gt-rig-toast" {
+		t.Errorf("in tmux: got %q, want session name", got)
+	}
+}
+
+func TestPaneValueCached(t *testing.T) {
+	origExecCommand := execCommand
+	defer func() { execCommand = origExecCommand }()
+	calls := 0
+	execCommand = func(name string, args ...string) interface{ Output() ([]byte, error) } {
+		calls++
+		return &mockCmd{output: []byte("gt-rig-cached\n")}
+	}
+
+	// Renewals ask for the session on every gt command; tmux runs once
+	t.Setenv("TMUX_PANE", "%cached")
+	for i := 0; i < 3; i++ {
+		if got := CurrentSessionID(""); got != "gt-rig-cached" {
+			t.Fatalf("CurrentSessionID() = %q", got)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("tmux ran %d times, want 1", calls)
+	}
+}
+
+func TestLock_AcquireWaitsForGuard(t *testing.T) {
+	workerDir := filepath.Join(t.TempDir(), "worker")
+	l := New(workerDir)
+
+	// Another process is mid-acquisition: it holds the guard and has not
+	// written its lock yet. Acquire must wait for it and then see its lease
+	// instead of concluding the worker is free.
+	guard := flock.New(l.guardPath)
+	if err := os.MkdirAll(filepath.Dir(l.guardPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		data, _ := json.Marshal(LockInfo{
+			PID:        999999999,
+			AcquiredAt: time.Now(),
+			SessionID:  "other-session",
+			ExpiresAt:  time.Now().Add(time.Minute),
+		})
+		if err := os.WriteFile(l.lockPath, data, 0644); err != nil {
+			t.Error(err)
+		}
+		_ = guard.Unlock()
+	}()
+
+	if err := l.Acquire("new-session"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Acquire() error = %v, want ErrLocked", err)
+	}
+}
+
+func TestLock_ReleaseIfUnchanged(t *testing.T) {
+	workerDir := filepath.Join(t.TempDir(), "worker")
+	stale := LockInfo{PID: 999999999, SessionID: "old", ExpiresAt: time.Now().Add(-time.Minute)}
+	writeLockInfo(t, workerDir, LockInfo{
+		PID:       999999999,
+		SessionID: "new",
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+
+	// A cleanup that saw the old stale lock must not remove the new one
+	if released, err := New(workerDir).releaseIfUnchanged(&stale); err != nil || released {
+		t.Fatalf("releaseIfUnchanged() = %v, %v; want false, nil", released, err)
+	}
+	if _, err := New(workerDir).Read(); err != nil {
+		t.Errorf("lock was removed: %v", err)
+	}
+}
+
+func TestLock_HolderMetadata(t *testing.T) {
+	workerDir := filepath.Join(t.TempDir(), "worker")
+	holder := Holder{Role: "polecat", Rig: "gastown", Agent: "claude", Command: "claude --dangerously-skip-permissions"}
+
+	if err := New(workerDir).WithHolder(holder).Acquire("gt-gastown-toast"); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	// Renewal without metadata (as keepalive does) keeps the recorded holder
+	l := New(workerDir)
+	if err := l.Renew("gt-gastown-toast"); err != nil {
+		t.Fatalf("Renew() error = %v", err)
+	}
+	info, err := l.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Holder != holder {
+		t.Errorf("Holder = %+v, want %+v", info.Holder, holder)
+	}
+	if info.State() != StateActive {
+		t.Errorf("State() = %q, want %q", info.State(), StateActive)
+	}
+
+	// Holder fields are stored flat in the lock file
+	data, _ := os.ReadFile(filepath.Join(workerDir, ".runtime", "agent.lock"))
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["agent"] != "claude" || raw["role"] != "polecat" {
+		t.Errorf("lock file missing holder fields: %s", data)
+	}
+}
+
+func TestLock_LogsLifecycleEvents(t *testing.T) {
+	town := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(town, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(town, "mayor", "town.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	workerDir := filepath.Join(town, "gastown", "polecats", "toast")
+
+	// Another agent holds a live lease: our claim is a collision
+	writeLockInfo(t, workerDir, LockInfo{
+		PID:       999999999,
+		SessionID: "gt-gastown-toast",
+		Hostname:  "other-host",
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	l := New(workerDir)
+	if err := l.Acquire("gt-gastown-imposter"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Acquire() error = %v, want ErrLocked", err)
+	}
+
+	// The lease expires: our claim takes it over
+	writeLockInfo(t, workerDir, LockInfo{
+		PID:       999999999,
+		SessionID: "gt-gastown-toast",
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	if err := l.Acquire("gt-gastown-imposter"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Release(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(town, "logs", "town.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	for _, want := range []string{
+		"[lock_collision] gastown/polecats/toast",
+		"session=gt-gastown-toast pid=999999999 host=other-host",
+		"claimed by session=gt-gastown-imposter",
+		"[lock_stale] gastown/polecats/toast",
+		"taken over by session=gt-gastown-imposter",
+		"[lock_acquired] gastown/polecats/toast",
+		"[lock_released] gastown/polecats/toast",
+	} {
+		if !contains(log, want) {
+			t.Errorf("town log missing %q:\n%s", want, log)
+		}
+	}
+}
+
+func TestLockInfo_CrossHost(t *testing.T) {
+	hostname, _ := os.Hostname()
+	remote := hostname + "-elsewhere"
+
+	// A legacy lock from another host can't be judged by PID: our PID
+	// namespace says nothing about it. It is live until an implicit lease
+	// from acquisition runs out.
+	recent := LockInfo{PID: 999999999, Hostname: remote, AcquiredAt: time.Now().Add(-time.Minute)}
+	if recent.IsStale() {
+		t.Error("recent remote legacy lock reported stale")
+	}
+	old := LockInfo{PID: os.Getpid(), Hostname: remote, AcquiredAt: time.Now().Add(-DefaultLeaseTTL - time.Minute)}
+	if !old.IsStale() || old.State() != StateExpired {
+		t.Errorf("old remote legacy lock: IsStale() = %v, State() = %q", old.IsStale(), old.State())
+	}
+
+	// A matching PID or session on another host is not us
+	if old.HeldBy("") {
+		t.Error("remote lock with our PID reported as held by us")
+	}
+	sameSession := LockInfo{PID: 1, SessionID: "gt-gastown-toast", Hostname: remote}
+	if sameSession.HeldBy("gt-gastown-toast") {
+		t.Error("remote lock with our session name reported as held by us")
+	}
+
+	// Leased locks expire the same way on every host
+	leased := LockInfo{PID: 999999999, Hostname: remote, ExpiresAt: time.Now().Add(time.Minute)}
+	if leased.IsStale() {
+		t.Error("live remote lease reported stale")
+	}
+}
+
+func TestDetectCollisions_RemoteLock(t *testing.T) {
+	tmpDir := t.TempDir()
+	hostname, _ := os.Hostname()
+	writeLockInfo(t, filepath.Join(tmpDir, "worker"), LockInfo{
+		PID:       1,
+		SessionID: "remote-session",
+		Hostname:  hostname + "-elsewhere",
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+
+	// The session lives in the other host's tmux; not an orphan here
+	if collisions := DetectCollisions(tmpDir, nil); len(collisions) != 0 {
+		t.Errorf("DetectCollisions() = %v, want none", collisions)
+	}
+}
+
+func TestLockInfo_PaneLiveness(t *testing.T) {
+	hostname, _ := os.Hostname()
+
+	// The agent outlives the gt process that wrote the lock
+	alive := LockInfo{PID: 999999999, PanePID: os.Getpid(), Hostname: hostname, ExpiresAt: time.Now().Add(time.Hour)}
+	if alive.IsStale() || alive.State() != StateActive {
+		t.Errorf("live pane: IsStale() = %v, State() = %q", alive.IsStale(), alive.State())
+	}
+
+	// A hung agent with a live pane stops renewing and loses the lease
+	hung := LockInfo{PID: 999999999, PanePID: os.Getpid(), Hostname: hostname, ExpiresAt: time.Now().Add(-time.Minute)}
+	if !hung.IsStale() || hung.State() != StateExpired {
+		t.Errorf("live pane, expired lease: IsStale() = %v, State() = %q", hung.IsStale(), hung.State())
+	}
+
+	// The agent died before its lease ran out
+	dead := LockInfo{PID: os.Getpid(), PanePID: 999999999, Hostname: hostname, ExpiresAt: time.Now().Add(time.Hour)}
+	if !dead.IsStale() || dead.State() != StateDead {
+		t.Errorf("dead pane: IsStale() = %v, State() = %q", dead.IsStale(), dead.State())
+	}
+	if got := (&dead).describe(); !strings.Contains(got, "pane PID: 999999999") {
+		t.Errorf("describe() = %q, want pane PID", got)
+	}
+
+	// A pane PID from another host means nothing here; the lease decides
+	remote := LockInfo{PID: 1, PanePID: 999999999, Hostname: hostname + "-elsewhere", ExpiresAt: time.Now().Add(time.Hour)}
+	if remote.IsStale() {
+		t.Error("remote lock with live lease reported stale")
+	}
+}
+
+func TestLock_RecordsPanePID(t *testing.T) {
+	origExecCommand := execCommand
+	defer func() { execCommand = origExecCommand }()
+	execCommand = func(name string, args ...string) interface{ Output() ([]byte, error) } {
+		return &mockCmd{output: []byte(fmt.Sprintf("%d\n", os.Getpid()))}
+	}
+
+	l := New(t.TempDir())
+	t.Setenv("TMUX_PANE", "%3")
+	if err := l.Acquire("gt-rig-toast"); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	info, err := l.Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if info.PanePID != os.Getpid() {
+		t.Errorf("PanePID = %d, want %d", info.PanePID, os.Getpid())
+	}
+
+	// Renewing from outside tmux keeps the recorded pane
+	t.Setenv("TMUX_PANE", "")
+	if err := l.Renew("gt-rig-toast"); err != nil {
+		t.Fatalf("Renew() error = %v", err)
+	}
+	if info, _ := l.Read(); info.PanePID != os.Getpid() {
+		t.Errorf("after renew PanePID = %d, want %d", info.PanePID, os.Getpid())
+	}
+}
@@ -0,0 +1,370 @@
+// Package logrotate rotates the town's append-only logs by size and age,
+// compresses rotated segments, prunes segments past a retention window, and
+// reads a log transparently across its rotated segments.
+//
+// A log at <path> is rotated by renaming it to <path>.<UTC stamp>, which is
+// then gzipped to <path>.<UTC stamp>.gz. Writers open the log per write, so
+// the next write after a rotation simply creates a fresh <path>.
+package logrotate
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stampLayout names segments by rotation time; it sorts lexically.
+const stampLayout = "20060102T150405Z"
+
+// Policy controls when a log is rotated and how long segments are kept.
+type Policy struct {
+	// MaxSize rotates the log once it reaches this many bytes (0 = no limit).
+	MaxSize int64
+
+	// MaxAge rotates the log once its first entry is older than this
+	// (0 = no limit). Requires LineTime.
+	MaxAge time.Duration
+
+	// Retention removes segments rotated longer ago than this (0 = keep all).
+	Retention time.Duration
+
+	// Compress gzips rotated segments.
+	Compress bool
+
+	// LineTime extracts the timestamp of a log line, for MaxAge.
+	LineTime func(line []byte) (time.Time, bool)
+}
+
+// Segment is a rotated piece of a log.
+type Segment struct {
+	Path      string
+	RotatedAt time.Time
+	seq       int
+}
+
+// Segments returns the rotated segments of the log at path, oldest first.
+func Segments(path string) ([]Segment, error) {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var segments []Segment
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, base+".") {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, base+"."), ".gz")
+		seq := 0
+		if s, n, ok := strings.Cut(stamp, "-"); ok {
+			if seq, err = strconv.Atoi(n); err != nil {
+				continue
+			}
+			stamp = s
+		}
+		ts, err := time.Parse(stampLayout, stamp)
+		if err != nil {
+			continue // Not a segment (e.g. a temp file)
+		}
+		segments = append(segments, Segment{Path: filepath.Join(dir, name), RotatedAt: ts, seq: seq})
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		if !segments[i].RotatedAt.Equal(segments[j].RotatedAt) {
+			return segments[i].RotatedAt.Before(segments[j].RotatedAt)
+		}
+		return segments[i].seq < segments[j].seq
+	})
+	return segments, nil
+}
+
+// Rotate rotates the log at path if it has reached p.MaxSize or p.MaxAge,
+// then prunes segments older than p.Retention. It also finishes compressing
+// any segment left uncompressed by an interrupted rotation.
+// Returns whether the log was rotated.
+func Rotate(path string, p Policy, now time.Time) (bool, error) {
+	due, err := rotationDue(path, p, now)
+	if err != nil {
+		return false, err
+	}
+	rotated := false
+	if due {
+		if rotated, err = Cut(path, p, now); err != nil {
+			return false, err
+		}
+	}
+	if err := finish(path, p, now); err != nil {
+		return rotated, err
+	}
+	return rotated, nil
+}
+
+// Cut rotates the log at path now, regardless of size or age.
+// An empty or missing log is left alone. Returns whether it was rotated.
+func Cut(path string, p Policy, now time.Time) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if info.Size() == 0 {
+		return false, nil
+	}
+
+	segment := segmentPath(path, now)
+	if err := os.Rename(path, segment); err != nil {
+		return false, fmt.Errorf("rotating %s: %w", filepath.Base(path), err)
+	}
+	if p.Compress {
+		// The rotation stands; Rotate retries compression later
+		if err := compress(segment); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// rotationDue reports whether the log has outgrown the policy.
+func rotationDue(path string, p Policy, now time.Time) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if info.Size() == 0 {
+		return false, nil
+	}
+	if p.MaxSize > 0 && info.Size() >= p.MaxSize {
+		return true, nil
+	}
+	if p.MaxAge > 0 && p.LineTime != nil {
+		if first, ok := firstLineTime(path, p.LineTime); ok && now.Sub(first) >= p.MaxAge {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// finish compresses leftover uncompressed segments and prunes expired ones.
+func finish(path string, p Policy, now time.Time) error {
+	segments, err := Segments(path)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, s := range segments {
+		if p.Retention > 0 && now.Sub(s.RotatedAt) > p.Retention {
+			if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if p.Compress && !strings.HasSuffix(s.Path, ".gz") {
+			if err := compress(s.Path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// segmentPath returns an unused segment name for a rotation at now.
+func segmentPath(path string, now time.Time) string {
+	base := path + "." + now.UTC().Format(stampLayout)
+	candidate := base
+	for i := 1; ; i++ {
+		_, errPlain := os.Stat(candidate)
+		_, errGz := os.Stat(candidate + ".gz")
+		if os.IsNotExist(errPlain) && os.IsNotExist(errGz) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// compress gzips segment to segment.gz and removes the original.
+func compress(segment string) error {
+	in, err := os.Open(segment) //nolint:gosec // G304: segment of a town log
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := segment + ".gz.tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600) //nolint:gosec // G304: segment of a town log
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	_, err = io.Copy(zw, in)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, segment+".gz")
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("compressing %s: %w", filepath.Base(segment), err)
+	}
+	return os.Remove(segment)
+}
+
+// firstLineTime returns the timestamp of the first line of the log.
+func firstLineTime(path string, lineTime func([]byte) (time.Time, bool)) (time.Time, bool) {
+	f, err := os.Open(path) //nolint:gosec // G304: town log path
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	if !scanner.Scan() {
+		return time.Time{}, false
+	}
+	return lineTime(scanner.Bytes())
+}
+
+// Open returns a reader over the whole log at path: its rotated segments,
+// oldest first, followed by the live log. It returns an error satisfying
+// os.IsNotExist only if neither the log nor any segment exists.
+func Open(path string) (io.ReadCloser, error) {
+	segments, err := Segments(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &multiReadCloser{}
+	for _, s := range segments {
+		sr, err := s.Open()
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // Pruned since listing
+			}
+			_ = r.Close()
+			return nil, err
+		}
+		r.closers = append(r.closers, sr)
+		r.readers = append(r.readers, sr)
+	}
+
+	f, err := os.Open(path) //nolint:gosec // G304: town log path
+	switch {
+	case err == nil:
+		r.closers = append(r.closers, f)
+		r.readers = append(r.readers, f)
+	case !os.IsNotExist(err) || len(r.readers) == 0:
+		_ = r.Close()
+		return nil, err
+	}
+
+	r.Reader = io.MultiReader(r.readers...)
+	return r, nil
+}
+
+// Open returns a reader over the segment's contents, decompressing it if
+// needed.
+func (s Segment) Open() (io.ReadCloser, error) {
+	f, err := os.Open(s.Path) //nolint:gosec // G304: segment of a town log
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(s.Path, ".gz") {
+		return f, nil
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("reading %s: %w", filepath.Base(s.Path), err)
+	}
+	return &gzipReadCloser{Reader: zr, file: f}, nil
+}
+
+type gzipReadCloser struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g *gzipReadCloser) Close() error {
+	return errors.Join(g.Reader.Close(), g.file.Close())
+}
+
+// ReadFile reads the whole log at path across its segments, like os.ReadFile.
+func ReadFile(path string) ([]byte, error) {
+	r, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+type multiReadCloser struct {
+	io.Reader
+	readers []io.Reader
+	closers []io.Closer
+}
+
+func (m *multiReadCloser) Close() error {
+	var errs []error
+	for _, c := range m.closers {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Replaced reports whether path no longer refers to the open file f, as
+// after a rotation and a subsequent write. Tailers use it to reopen the
+// live log once it has been recreated.
+func Replaced(f *os.File, path string) bool {
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	open, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return !os.SameFile(open, current)
+}
+
+// ParseDuration parses a duration with an optional day suffix (e.g. "7d",
+// "36h"). An empty string is zero.
+func ParseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,146 @@
+package logrotate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func appendLine(t *testing.T, path, line string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRotate_SizeCompressAndReadAcrossSegments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "town.log")
+	p := Policy{MaxSize: 10, Compress: true}
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	// Missing and small logs are left alone
+	if rotated, err := Rotate(path, p, now); err != nil || rotated {
+		t.Fatalf("Rotate(missing) = %v, %v", rotated, err)
+	}
+	appendLine(t, path, "one")
+	if rotated, _ := Rotate(path, p, now); rotated {
+		t.Fatal("rotated below MaxSize")
+	}
+
+	appendLine(t, path, "two two")
+	if rotated, err := Rotate(path, p, now); err != nil || !rotated {
+		t.Fatalf("Rotate = %v, %v; want rotation", rotated, err)
+	}
+	appendLine(t, path, "three three")
+	// Same second: the second segment gets a sequence suffix and sorts after
+	if rotated, err := Rotate(path, p, now); err != nil || !rotated {
+		t.Fatalf("second Rotate = %v, %v", rotated, err)
+	}
+	appendLine(t, path, "four")
+
+	segments, err := Segments(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 2 {
+		t.Fatalf("got %d segments, want 2", len(segments))
+	}
+	for _, s := range segments {
+		if !strings.HasSuffix(s.Path, ".gz") {
+			t.Errorf("segment %s not compressed", s.Path)
+		}
+	}
+
+	data, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "one\ntwo two\nthree three\nfour\n"; got != want {
+		t.Errorf("ReadFile = %q, want %q", got, want)
+	}
+}
+
+func TestRotate_AgeAndRetention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "town.log")
+	lineTime := func(line []byte) (time.Time, bool) {
+		ts, err := time.Parse(time.RFC3339, strings.Fields(string(line))[0])
+		return ts, err == nil
+	}
+	p := Policy{MaxAge: 24 * time.Hour, Retention: 48 * time.Hour, LineTime: lineTime}
+	day := func(n int) time.Time { return time.Date(2026, 1, n, 12, 0, 0, 0, time.UTC) }
+
+	appendLine(t, path, day(1).Format(time.RFC3339)+" first")
+	if rotated, _ := Rotate(path, p, day(1).Add(time.Hour)); rotated {
+		t.Fatal("rotated before MaxAge")
+	}
+	if rotated, err := Rotate(path, p, day(2)); err != nil || !rotated {
+		t.Fatalf("Rotate at MaxAge = %v, %v", rotated, err)
+	}
+	appendLine(t, path, day(2).Format(time.RFC3339)+" second")
+	if _, err := Rotate(path, p, day(3)); err != nil {
+		t.Fatal(err)
+	}
+
+	// On day 5 the day-2 segment is past retention; the day-3 one is not
+	if _, err := Rotate(path, p, day(5)); err != nil {
+		t.Fatal(err)
+	}
+	segments, _ := Segments(path)
+	if len(segments) != 1 || !segments[0].RotatedAt.Equal(day(3)) {
+		t.Fatalf("segments after retention = %+v", segments)
+	}
+	if _, err := os.Stat(segments[0].Path); err != nil || strings.HasSuffix(segments[0].Path, ".gz") {
+		t.Errorf("uncompressed segment expected: %v", err)
+	}
+
+	// A log with only segments is still readable; nothing at all is not-exist
+	data, err := ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "second") {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+	if _, err := Open(filepath.Join(t.TempDir(), "none.log")); !os.IsNotExist(err) {
+		t.Errorf("Open(missing) err = %v, want not-exist", err)
+	}
+}
+
+func TestReplaced(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".events.jsonl")
+	appendLine(t, path, "a")
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if Replaced(f, path) {
+		t.Fatal("fresh file reported replaced")
+	}
+	if rotated, err := Cut(path, Policy{}, time.Now()); err != nil || !rotated {
+		t.Fatalf("Cut = %v, %v", rotated, err)
+	}
+	if Replaced(f, path) {
+		t.Error("replaced before the log was recreated")
+	}
+	appendLine(t, path, "b")
+	if !Replaced(f, path) {
+		t.Error("recreated log not reported replaced")
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for in, want := range map[string]time.Duration{"": 0, "7d": 7 * 24 * time.Hour, "36h": 36 * time.Hour} {
+		if got, err := ParseDuration(in); err != nil || got != want {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseDuration("xd"); err == nil {
+		t.Error("expected error for xd")
+	}
+}
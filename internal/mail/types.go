@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/identity"
 )
 
 // Priority levels for messages.
@@ -505,33 +507,11 @@ func ParseMessageType(s string) MessageType {
 //   - "gastown/refinery" → "gastown/refinery"
 //   - "gastown/" → "gastown" (rig broadcast)
 func addressToIdentity(address string) string {
-	// Overseer (human operator) - no trailing slash, distinct from agents
-	if address == "overseer" {
-		return "overseer"
-	}
-
-	// Town-level agents: mayor and deacon keep trailing slash
-	if address == "mayor" || address == "mayor/" {
-		return "mayor/"
-	}
-	if address == "deacon" || address == "deacon/" {
-		return "deacon/"
-	}
-
-	// Trim trailing slash for rig-level addresses
-	if len(address) > 0 && address[len(address)-1] == '/' {
-		address = address[:len(address)-1]
-	}
-
-	// Normalize crew/ and polecats/ to canonical form:
-	// "rig/crew/name" → "rig/name"
-	// "rig/polecats/name" → "rig/name"
-	parts := strings.Split(address, "/")
-	if len(parts) == 3 && (parts[1] == "crew" || parts[1] == "polecats") {
-		return parts[0] + "/" + parts[2]
+	if id, err := identity.Parse(address); err == nil {
+		return id.Address()
 	}
-
-	return address
+	// Rig broadcasts ("gastown/") and anything else that isn't an agent
+	return strings.TrimSuffix(address, "/")
 }
 
 // identityToAddress converts a beads identity back to a GGT address.
@@ -544,25 +524,9 @@ func addressToIdentity(address string) string {
 //   - "gastown/crew/max" → "gastown/max" (normalized)
 //   - "gastown/Toast" → "gastown/Toast" (already canonical)
 //   - "gastown/refinery" → "gastown/refinery"
-func identityToAddress(identity string) string {
-	// Overseer (human operator) - no trailing slash
-	if identity == "overseer" {
-		return "overseer"
-	}
-
-	// Town-level agents ensure trailing slash
-	if identity == "mayor" || identity == "mayor/" {
-		return "mayor/"
-	}
-	if identity == "deacon" || identity == "deacon/" {
-		return "deacon/"
+func identityToAddress(ident string) string {
+	if id, err := identity.Parse(ident); err == nil {
+		return id.Address()
 	}
-
-	// Normalize crew/ and polecats/ to canonical form
-	parts := strings.Split(identity, "/")
-	if len(parts) == 3 && (parts[1] == "crew" || parts[1] == "polecats") {
-		return parts[0] + "/" + parts[2]
-	}
-
-	return identity
+	return ident
 }
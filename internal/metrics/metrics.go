@@ -0,0 +1,95 @@
+// Package metrics renders Gas Town metrics in the Prometheus text
+// exposition format, so towns can be scraped by standard Prometheus and
+// Grafana stacks without pulling in a client library.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the HTTP content type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Type is a Prometheus metric type.
+type Type string
+
+// Metric types.
+const (
+	Gauge   Type = "gauge"
+	Counter Type = "counter"
+)
+
+// Labels are the label names and values of a sample.
+type Labels map[string]string
+
+// Sample is one labeled value of a metric family.
+type Sample struct {
+	Labels Labels
+	Value  float64
+}
+
+// Family is a named metric with its help text, type, and samples.
+type Family struct {
+	Name    string
+	Help    string
+	Type    Type
+	Samples []Sample
+}
+
+// NewFamily creates an empty metric family.
+func NewFamily(name string, typ Type, help string) *Family {
+	return &Family{Name: name, Type: typ, Help: help}
+}
+
+// Add appends a sample to the family.
+func (f *Family) Add(value float64, labels Labels) {
+	f.Samples = append(f.Samples, Sample{Labels: labels, Value: value})
+}
+
+// Write renders families in the text exposition format.
+// Families with no samples still get their HELP and TYPE lines, so
+// dashboards can tell "zero" from "not exported".
+func Write(w io.Writer, families []*Family) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		fmt.Fprintf(bw, "# HELP %s %s\n", f.Name, escapeHelp(f.Help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.Name, f.Type)
+		for _, s := range f.Samples {
+			fmt.Fprintf(bw, "%s%s %s\n", f.Name, formatLabels(s.Labels),
+				strconv.FormatFloat(s.Value, 'g', -1, 64))
+		}
+	}
+	return bw.Flush()
+}
+
+// formatLabels renders labels as {k="v",...}, sorted by name.
+func formatLabels(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + `="` + labelEscaper.Replace(labels[name]) + `"`
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	queue := NewFamily("gastown_merge_queue_depth", Gauge, "Merge requests in the queue.")
+	queue.Add(3, Labels{"state": "pending", "rig": "gastown"})
+	queue.Add(0.5, nil)
+	failures := NewFamily("gastown_merge_failures_total", Counter, "Failed merges.\nBy type.")
+	failures.Add(2, Labels{"type": `say "hi"\`})
+	empty := NewFamily("gastown_agent_restarts_total", Counter, "Agent restarts.")
+
+	var buf bytes.Buffer
+	if err := Write(&buf, []*Family{queue, failures, empty}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := `# HELP gastown_merge_queue_depth Merge requests in the queue.
+# TYPE gastown_merge_queue_depth gauge
+gastown_merge_queue_depth{rig="gastown",state="pending"} 3
+gastown_merge_queue_depth 0.5
+# HELP gastown_merge_failures_total Failed merges.\nBy type.
+# TYPE gastown_merge_failures_total counter
+gastown_merge_failures_total{type="say \"hi\"\\"} 2
+# HELP gastown_agent_restarts_total Agent restarts.
+# TYPE gastown_agent_restarts_total counter
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,414 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/eventsink"
+	"github.com/steveyegge/gastown/internal/runstate"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// ChannelState is the saved delivery state of a channel.
+type ChannelState struct {
+	// Pending are notifications waiting for the next batch, quiet hours to
+	// end, or a failed delivery to be retried.
+	Pending []Notification `json:"pending,omitempty"`
+
+	// Seen is when each notification key was last routed to the channel.
+	Seen map[string]time.Time `json:"seen,omitempty"`
+
+	// Sent and Suppressed count delivered and deduplicated notifications.
+	Sent       int `json:"sent"`
+	Suppressed int `json:"suppressed"`
+
+	// SentAt is the last successful delivery; Error the last failure.
+	SentAt time.Time `json:"sent_at,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// StateFile returns the path of the bridge's saved channel states.
+func StateFile(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "notifications.json")
+}
+
+// stateDoc returns the document holding the channel states.
+func stateDoc(townRoot string) *runstate.Doc[map[string]*ChannelState] {
+	return runstate.NewDocAt(StateFile(townRoot), func() *map[string]*ChannelState {
+		return &map[string]*ChannelState{}
+	})
+}
+
+// LoadState reads the saved channel states, keyed by channel name.
+func LoadState(townRoot string) (map[string]*ChannelState, error) {
+	state, err := stateDoc(townRoot).Load()
+	if err != nil {
+		return nil, err
+	}
+	if *state == nil {
+		return map[string]*ChannelState{}, nil
+	}
+	return *state, nil
+}
+
+// Bridge routes notifications to channels and delivers them in batches.
+// It is an eventsink.Sink: attach it to the daemon's event forwarder to
+// receive the events listed in EventTypes.
+type Bridge struct {
+	townRoot string
+	town     string
+	cfg      Config
+	channels []Channel
+	batch    time.Duration
+	dedup    time.Duration
+	client   *http.Client
+	logger   func(format string, args ...interface{})
+
+	// sendMail sends an email (smtp.SendMail; replaced in tests).
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+	mu    sync.Mutex
+	state map[string]*ChannelState
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ eventsink.Sink = (*Bridge)(nil)
+
+// NewBridge validates the config and creates a bridge for its enabled channels.
+func NewBridge(townRoot string, cfg Config, logger func(format string, args ...interface{})) (*Bridge, error) {
+	batch, dedup, err := cfg.Durations()
+	if err != nil {
+		return nil, err
+	}
+	town, _ := workspace.GetTownName(townRoot)
+	b := &Bridge{
+		townRoot: townRoot,
+		town:     town,
+		cfg:      cfg,
+		batch:    batch,
+		dedup:    dedup,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		logger:   logger,
+		sendMail: smtp.SendMail,
+	}
+	for _, ch := range cfg.Channels {
+		if !ch.Disabled {
+			b.channels = append(b.channels, ch)
+		}
+	}
+	if b.state, err = LoadState(townRoot); err != nil {
+		logger("notifications: %v; starting with fresh state", err)
+		b.state = map[string]*ChannelState{}
+	}
+	return b, nil
+}
+
+// Channels returns the number of enabled channels.
+func (b *Bridge) Channels() int {
+	return len(b.channels)
+}
+
+// Start begins delivering batches every batch interval.
+func (b *Bridge) Start() error {
+	b.ctx, b.cancel = context.WithCancel(context.Background())
+	b.wg.Add(1)
+	go b.run()
+	return nil
+}
+
+// Stop stops delivery. Pending notifications stay saved for the next start.
+func (b *Bridge) Stop() {
+	if b.cancel != nil {
+		b.cancel()
+	}
+	b.wg.Wait()
+}
+
+func (b *Bridge) run() {
+	defer b.wg.Done()
+	ticker := time.NewTicker(b.batch)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+			b.Flush(b.ctx, time.Now())
+		}
+	}
+}
+
+// Send routes the notifications among records to their channels. Delivery
+// happens on the next Flush.
+func (b *Bridge) Send(_ context.Context, records []eventsink.Record) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	for _, r := range records {
+		if n, ok := FromEvent(r.Event); ok {
+			b.route(n, now)
+		}
+	}
+	return b.save()
+}
+
+// Notify routes a notification to its channels directly, bypassing the
+// events log.
+func (b *Bridge) Notify(n Notification, now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.route(n, now)
+	return b.save()
+}
+
+// route queues n for every channel that wants it, unless the channel saw
+// the same key within the dedup window. A repeat of a still-pending
+// notification is counted on it instead.
+func (b *Bridge) route(n Notification, now time.Time) {
+	if n.Time.IsZero() {
+		n.Time = now
+	}
+	for _, ch := range b.channels {
+		if !ch.Wants(n) {
+			continue
+		}
+		cs := b.channelState(ch.ChannelName())
+		if seen, ok := cs.Seen[n.Key]; ok && now.Sub(seen) < b.dedup {
+			folded := false
+			for i := range cs.Pending {
+				if cs.Pending[i].Key == n.Key {
+					cs.Pending[i].Repeats++
+					folded = true
+					break
+				}
+			}
+			if !folded {
+				cs.Suppressed++
+			}
+			continue
+		}
+		cs.Seen[n.Key] = now
+		cs.Pending = append(cs.Pending, n)
+	}
+}
+
+func (b *Bridge) channelState(name string) *ChannelState {
+	cs := b.state[name]
+	if cs == nil {
+		cs = &ChannelState{}
+		b.state[name] = cs
+	}
+	if cs.Seen == nil {
+		cs.Seen = make(map[string]time.Time)
+	}
+	return cs
+}
+
+// Flush delivers each channel's pending notifications as one message.
+// During a channel's quiet hours only critical notifications are sent; the
+// rest are held. Failed deliveries are retried on the next flush until
+// they are MaxPendingAge old.
+func (b *Bridge) Flush(ctx context.Context, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, ch := range b.channels {
+		name := ch.ChannelName()
+		cs := b.channelState(name)
+		for key, seen := range cs.Seen {
+			if now.Sub(seen) >= b.dedup {
+				delete(cs.Seen, key)
+			}
+		}
+
+		var ready, held []Notification
+		quiet := ch.Quiet(now)
+		for _, n := range cs.Pending {
+			switch {
+			case now.Sub(n.Time) > MaxPendingAge:
+				b.logger("Warning: notifications: %s: dropping undelivered %q from %s", name, n.Title, n.Time.Format(time.RFC3339))
+			case quiet && n.Severity != config.SeverityCritical:
+				held = append(held, n)
+			default:
+				ready = append(ready, n)
+			}
+		}
+		if len(ready) == 0 {
+			cs.Pending = held
+			continue
+		}
+		if err := b.deliver(ctx, ch, ready); err != nil {
+			cs.Error = err.Error()
+			cs.Pending = append(held, ready...)
+			b.logger("Warning: notifications: %s: %v", name, err)
+			continue
+		}
+		cs.Pending = held
+		cs.Sent += len(ready)
+		cs.SentAt = now
+		cs.Error = ""
+	}
+
+	// Forget channels that were removed from the config
+	for name := range b.state {
+		if !b.hasChannel(name) {
+			delete(b.state, name)
+		}
+	}
+	if err := b.save(); err != nil {
+		b.logger("notifications: saving state: %v", err)
+	}
+}
+
+func (b *Bridge) hasChannel(name string) bool {
+	for _, ch := range b.channels {
+		if ch.ChannelName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Bridge) save() error {
+	return stateDoc(b.townRoot).Save(&b.state)
+}
+
+// Test delivers a test notification to the named channel (all enabled
+// channels if empty) immediately, ignoring routing, dedup, and quiet hours.
+func (b *Bridge) Test(ctx context.Context, channel string) error {
+	n := Notification{
+		Kind:     KindAlert,
+		Severity: config.SeverityLow,
+		Title:    "Test notification",
+		Body:     "Notifications from this town reach this channel.",
+		Time:     time.Now(),
+		Key:      "test",
+	}
+	found := false
+	for _, ch := range b.channels {
+		if channel != "" && ch.ChannelName() != channel {
+			continue
+		}
+		found = true
+		if err := b.deliver(ctx, ch, []Notification{n}); err != nil {
+			return fmt.Errorf("%s: %w", ch.ChannelName(), err)
+		}
+	}
+	if !found {
+		return fmt.Errorf("no enabled notification channel %q", channel)
+	}
+	return nil
+}
+
+// deliver sends a batch to a channel as one message.
+func (b *Bridge) deliver(ctx context.Context, ch Channel, batch []Notification) error {
+	subject, text := Format(b.town, batch)
+	switch ch.Type {
+	case TypeSlack:
+		return b.post(ctx, ch.URL, map[string]string{"text": text})
+	case TypeDiscord:
+		if len(text) > 2000 { // Discord's message limit
+			text = text[:1990] + "\n…"
+		}
+		return b.post(ctx, ch.URL, map[string]string{"content": text})
+	case TypeWebhook:
+		return b.post(ctx, ch.URL, map[string]interface{}{"town": b.town, "notifications": batch})
+	case TypeEmail:
+		return b.email(ch.To, subject, text)
+	}
+	return fmt.Errorf("unknown channel type %q", ch.Type)
+}
+
+// post sends body as JSON to a webhook URL.
+func (b *Bridge) post(ctx context.Context, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, os.ExpandEnv(url), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (b *Bridge) email(to []string, subject, text string) error {
+	s := b.cfg.SMTP
+	port := s.Port
+	if port == 0 {
+		port = 587
+	}
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, os.ExpandEnv(s.Password), s.Host)
+	}
+	msg := "From: " + s.From + "\r\n" +
+		"To: " + strings.Join(to, ", ") + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n\r\n" +
+		strings.ReplaceAll(text, "\n", "\r\n") + "\r\n"
+	return b.sendMail(s.Host+":"+strconv.Itoa(port), auth, s.From, to, []byte(msg))
+}
+
+// Format renders a batch as a subject line and a plain-text message.
+func Format(town string, batch []Notification) (subject, text string) {
+	prefix := "[Gas Town] "
+	if town != "" {
+		prefix = "[" + town + "] "
+	}
+	line := func(n Notification) string {
+		s := fmt.Sprintf("[%s] %s", strings.ToUpper(n.Severity), n.Title)
+		if n.Rig != "" {
+			s += " (" + n.Rig + ")"
+		}
+		if n.Repeats > 0 {
+			s += fmt.Sprintf(" ×%d", n.Repeats+1)
+		}
+		return s
+	}
+
+	if len(batch) == 1 {
+		n := batch[0]
+		text = line(n)
+		if n.Body != "" {
+			text += "\n" + n.Body
+		}
+		return prefix + n.Title, text
+	}
+
+	var lines []string
+	for _, n := range batch {
+		l := "• " + line(n)
+		if n.Body != "" {
+			l += "\n  " + strings.ReplaceAll(n.Body, "\n", "\n  ")
+		}
+		lines = append(lines, l)
+	}
+	return fmt.Sprintf("%s%d notifications", prefix, len(batch)),
+		fmt.Sprintf("%d notifications:\n%s", len(batch), strings.Join(lines, "\n"))
+}
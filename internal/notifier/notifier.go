@@ -0,0 +1,351 @@
+// Package notifier delivers the town's important events to people outside
+// it: Slack, Discord, email, or any webhook.
+//
+// The daemon runs a Bridge that receives escalations (including protocol
+// messages never acknowledged), firing alerts, and exhausted SLO error
+// budgets from the events log, routes each to the channels that want it,
+// and sends each channel's notifications in batches:
+//   - deduplication: a notification repeated within the dedup window (an
+//     alert re-firing, the same escalation logged twice) is folded into the
+//     pending one or dropped, instead of paging again
+//   - batching: notifications arriving together go out as one digest
+//   - quiet hours: outside critical severity, a channel's notifications
+//     are held during its quiet hours and sent as a digest when they end
+//
+// Channels are configured under "notifications" in mayor/daemon.json. New
+// producers should emit events this package understands rather than post
+// to webhooks of their own.
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/logrotate"
+)
+
+// Notification kinds.
+const (
+	KindEscalation = "escalation"
+	KindAlert      = "alert"
+	KindSLO        = "slo"
+)
+
+// Channel types.
+const (
+	TypeSlack   = "slack"
+	TypeDiscord = "discord"
+	TypeWebhook = "webhook"
+	TypeEmail   = "email"
+)
+
+// Defaults for Config.
+const (
+	DefaultBatch = time.Minute
+	DefaultDedup = time.Hour
+)
+
+// MaxPendingAge is how long an undeliverable notification is retried
+// before it is dropped.
+const MaxPendingAge = 24 * time.Hour
+
+// EventTypes are the event types the bridge turns into notifications.
+var EventTypes = []string{events.TypeEscalationSent, events.TypeAlertFired, events.TypeProtocolUnacked}
+
+// Config is the "notifications" section of mayor/daemon.json.
+type Config struct {
+	// Batch is how often pending notifications are sent, e.g. "30s"
+	// (default "1m"). Notifications arriving within it share a message.
+	Batch string `json:"batch,omitempty"`
+
+	// Dedup is how long a repeated notification is suppressed (default "1h").
+	Dedup string `json:"dedup,omitempty"`
+
+	// SMTP is the mail server for email channels.
+	SMTP *SMTPConfig `json:"smtp,omitempty"`
+
+	// Channels are the destinations.
+	Channels []Channel `json:"channels"`
+}
+
+// SMTPConfig is the mail server used by email channels.
+type SMTPConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"` // default 587
+	Username string `json:"username,omitempty"`
+
+	// Password may reference environment variables, e.g. "${GT_SMTP_PASSWORD}".
+	Password string `json:"password,omitempty"`
+
+	From string `json:"from"`
+}
+
+// Channel is one destination and the notifications routed to it.
+type Channel struct {
+	// Name identifies the channel in logs and saved state (default: Type).
+	Name string `json:"name,omitempty"`
+
+	// Type is "slack", "discord", "webhook", or "email".
+	Type string `json:"type"`
+
+	// Disabled stops delivery to the channel without removing it.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// URL is the Slack or Discord incoming webhook, or the webhook URL. It
+	// may reference environment variables, e.g. "${GT_SLACK_WEBHOOK}".
+	URL string `json:"url,omitempty"`
+
+	// To are the recipients of an email channel.
+	To []string `json:"to,omitempty"`
+
+	// Kinds limits the channel to these kinds: escalation, alert, slo
+	// (default: all).
+	Kinds []string `json:"kinds,omitempty"`
+
+	// MinSeverity drops notifications below it: low, medium, high, or
+	// critical (default: low).
+	MinSeverity string `json:"min_severity,omitempty"`
+
+	// Rigs limits the channel to notifications about these rigs. Town-wide
+	// notifications are always routed.
+	Rigs []string `json:"rigs,omitempty"`
+
+	// QuietHours holds non-critical notifications during a daily window.
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+}
+
+// QuietHours is a daily window, e.g. 22:00 to 07:00, in which only critical
+// notifications are delivered.
+type QuietHours struct {
+	Start string `json:"start"` // "22:00"
+	End   string `json:"end"`   // "07:00"
+
+	// Timezone is an IANA zone such as "America/Los_Angeles" (default: local).
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Notification is a message for people outside the town.
+type Notification struct {
+	Kind     string    `json:"kind"`
+	Severity string    `json:"severity"`
+	Title    string    `json:"title"`
+	Body     string    `json:"body,omitempty"`
+	Rig      string    `json:"rig,omitempty"`
+	Time     time.Time `json:"time"`
+
+	// Key identifies repeats of the same notification for deduplication.
+	Key string `json:"key"`
+
+	// Repeats counts duplicates folded into this notification while pending.
+	Repeats int `json:"repeats,omitempty"`
+}
+
+// ChannelName returns the name the channel is known by.
+func (c Channel) ChannelName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Type
+}
+
+// Wants reports whether a notification is routed to the channel.
+func (c Channel) Wants(n Notification) bool {
+	if len(c.Kinds) > 0 && !contains(c.Kinds, n.Kind) {
+		return false
+	}
+	if severityRank(n.Severity) < severityRank(c.MinSeverity) {
+		return false
+	}
+	if len(c.Rigs) > 0 && n.Rig != "" && !contains(c.Rigs, n.Rig) {
+		return false
+	}
+	return true
+}
+
+// Quiet reports whether the channel is in its quiet hours at t.
+func (c Channel) Quiet(t time.Time) bool {
+	if c.QuietHours == nil {
+		return false
+	}
+	active, _ := c.QuietHours.Active(t)
+	return active
+}
+
+// Active reports whether t falls within the quiet hours. A window whose
+// end is before its start spans midnight.
+func (q QuietHours) Active(t time.Time) (bool, error) {
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return false, fmt.Errorf("quiet_hours start: %w", err)
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return false, fmt.Errorf("quiet_hours end: %w", err)
+	}
+	if q.Timezone != "" {
+		loc, err := time.LoadLocation(q.Timezone)
+		if err != nil {
+			return false, fmt.Errorf("quiet_hours timezone: %w", err)
+		}
+		t = t.In(loc)
+	}
+	now := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if start <= end {
+		return now >= start && now < end, nil
+	}
+	return now >= start || now < end, nil
+}
+
+// parseClock parses "HH:MM" into the time since midnight.
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (want HH:MM)", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Durations validates the config and returns its batch and dedup intervals.
+func (c Config) Durations() (batch, dedup time.Duration, err error) {
+	if batch, err = logrotate.ParseDuration(c.Batch); err != nil {
+		return 0, 0, fmt.Errorf("notifications batch: %w", err)
+	}
+	if batch <= 0 {
+		batch = DefaultBatch
+	}
+	if dedup, err = logrotate.ParseDuration(c.Dedup); err != nil {
+		return 0, 0, fmt.Errorf("notifications dedup: %w", err)
+	}
+	if dedup <= 0 {
+		dedup = DefaultDedup
+	}
+
+	seen := make(map[string]bool)
+	for _, ch := range c.Channels {
+		name := ch.ChannelName()
+		if name == "" {
+			return 0, 0, errors.New("notification channel has no type")
+		}
+		if seen[name] {
+			return 0, 0, fmt.Errorf("duplicate notification channel %q", name)
+		}
+		seen[name] = true
+		if err := ch.validate(c.SMTP); err != nil {
+			return 0, 0, fmt.Errorf("notification channel %s: %w", name, err)
+		}
+	}
+	return batch, dedup, nil
+}
+
+func (c Channel) validate(smtp *SMTPConfig) error {
+	switch c.Type {
+	case TypeSlack, TypeDiscord, TypeWebhook:
+		if c.URL == "" {
+			return fmt.Errorf("%s channel needs a url", c.Type)
+		}
+	case TypeEmail:
+		if len(c.To) == 0 {
+			return errors.New("email channel needs recipients (to)")
+		}
+		if smtp == nil || smtp.Host == "" || smtp.From == "" {
+			return errors.New("email channel needs smtp.host and smtp.from")
+		}
+	default:
+		return fmt.Errorf("unknown type %q (want slack, discord, webhook, or email)", c.Type)
+	}
+	for _, k := range c.Kinds {
+		if k != KindEscalation && k != KindAlert && k != KindSLO {
+			return fmt.Errorf("unknown kind %q (want escalation, alert, or slo)", k)
+		}
+	}
+	if c.MinSeverity != "" && !config.IsValidSeverity(c.MinSeverity) {
+		return fmt.Errorf("unknown min_severity %q", c.MinSeverity)
+	}
+	if c.QuietHours != nil {
+		if _, err := c.QuietHours.Active(time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FromEvent converts an event into a notification, if it is one the bridge
+// delivers.
+func FromEvent(e events.Event) (Notification, bool) {
+	str := func(key string) string {
+		s, _ := e.Payload[key].(string)
+		return s
+	}
+	n := Notification{Rig: e.Rig, Time: e.Time()}
+	switch e.Type {
+	case events.TypeEscalationSent:
+		n.Kind = KindEscalation
+		id := str("escalation_id")
+		if id == "" {
+			id = str("rig") // gt escalate records the escalation bead here
+		}
+		n.Rig = ""
+		if e.Payload["reescalated"] == true {
+			n.Severity = str("new_severity")
+			n.Title = fmt.Sprintf("Escalation %s re-escalated (%s → %s)", id, str("old_severity"), n.Severity)
+		} else {
+			n.Severity = str("severity")
+			n.Title = fmt.Sprintf("Escalation %s: %s", id, str("reason"))
+			n.Body = "From " + e.Actor
+			if src := str("source"); src != "" {
+				n.Body += " (" + src + ")"
+			}
+		}
+		if !config.IsValidSeverity(n.Severity) {
+			n.Severity = config.SeverityMedium
+		}
+		n.Body = strings.TrimSpace(n.Body + "\nAcknowledge with: gt escalate ack " + id)
+		n.Key = "escalation:" + id + ":" + n.Severity
+
+	case events.TypeAlertFired:
+		rule := str("rule")
+		n.Kind = KindAlert
+		n.Severity = config.SeverityHigh
+		n.Title = "Alert " + rule
+		if s := str("slo"); s != "" {
+			n.Kind = KindSLO
+			n.Title = "SLO " + s + " error budget exhausted"
+		}
+		n.Body = str("summary")
+		n.Key = "alert:" + rule
+
+	case events.TypeProtocolUnacked:
+		n.Kind = KindEscalation
+		n.Severity = config.SeverityHigh
+		n.Title = fmt.Sprintf("%s to %s not processed", str("subject"), str("to"))
+		n.Body = str("reason")
+		n.Key = "protocol:" + str("to") + ":" + str("subject")
+
+	default:
+		return Notification{}, false
+	}
+	return n, true
+}
+
+// severityRank orders severities; unknown ones rank as low.
+func severityRank(s string) int {
+	for i, v := range config.ValidSeverities() {
+		if v == s {
+			return i
+		}
+	}
+	return 0
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
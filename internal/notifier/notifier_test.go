@@ -0,0 +1,284 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/eventsink"
+)
+
+func TestFromEvent(t *testing.T) {
+	esc := events.Event{Type: events.TypeEscalationSent, Actor: "gastown/polecats/toast", Payload: map[string]interface{}{
+		"rig": "hq-esc1", "reason": "Build is broken", "severity": "critical", "source": "ci",
+	}}
+	n, ok := FromEvent(esc)
+	if !ok || n.Kind != KindEscalation || n.Severity != "critical" || n.Key != "escalation:hq-esc1:critical" {
+		t.Fatalf("escalation = %+v", n)
+	}
+	if n.Title != "Escalation hq-esc1: Build is broken" || !strings.Contains(n.Body, "gastown/polecats/toast (ci)") {
+		t.Errorf("escalation text = %q / %q", n.Title, n.Body)
+	}
+
+	re := events.Event{Type: events.TypeEscalationSent, Payload: map[string]interface{}{
+		"escalation_id": "hq-esc1", "reescalated": true, "old_severity": "high", "new_severity": "critical",
+	}}
+	if n, _ := FromEvent(re); n.Severity != "critical" || !strings.Contains(n.Title, "high → critical") {
+		t.Errorf("re-escalation = %+v", n)
+	}
+
+	slo := events.Event{Type: events.TypeAlertFired, Rig: "gastown", Payload: events.AlertPayload("slo-merge-2h", -1, "50% met")}
+	slo.Payload["slo"] = "merge-2h"
+	if n, _ := FromEvent(slo); n.Kind != KindSLO || n.Severity != "high" || n.Rig != "gastown" || n.Key != "alert:slo-merge-2h" {
+		t.Errorf("slo alert = %+v", n)
+	}
+
+	unacked := events.Event{Type: events.TypeProtocolUnacked, Payload: events.ProtocolUnackedPayload("MERGE_FAILED", "gastown/witness", "MERGE_FAILED nux", "not acknowledged after 3 deliveries", 3)}
+	if n, _ := FromEvent(unacked); n.Kind != KindEscalation || n.Severity != "high" || n.Key != "protocol:gastown/witness:MERGE_FAILED nux" {
+		t.Errorf("protocol unacked = %+v", n)
+	}
+
+	if _, ok := FromEvent(events.Event{Type: events.TypeMerged}); ok {
+		t.Error("merged event became a notification")
+	}
+}
+
+func TestChannelWants(t *testing.T) {
+	ch := Channel{Type: TypeSlack, Kinds: []string{KindAlert, KindSLO}, MinSeverity: "high", Rigs: []string{"gastown"}}
+	for _, tt := range []struct {
+		n    Notification
+		want bool
+	}{
+		{Notification{Kind: KindAlert, Severity: "high", Rig: "gastown"}, true},
+		{Notification{Kind: KindAlert, Severity: "high"}, true}, // Town-wide
+		{Notification{Kind: KindAlert, Severity: "high", Rig: "beads"}, false},
+		{Notification{Kind: KindAlert, Severity: "medium", Rig: "gastown"}, false},
+		{Notification{Kind: KindEscalation, Severity: "critical"}, false},
+	} {
+		if got := ch.Wants(tt.n); got != tt.want {
+			t.Errorf("Wants(%+v) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestQuietHours(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2026, 1, 15, h, m, 0, 0, time.UTC) }
+	night := QuietHours{Start: "22:00", End: "07:00", Timezone: "UTC"}
+	for _, tt := range []struct {
+		t    time.Time
+		want bool
+	}{
+		{at(23, 0), true}, {at(3, 0), true}, {at(7, 0), false}, {at(12, 0), false}, {at(22, 0), true},
+	} {
+		if got, err := night.Active(tt.t); err != nil || got != tt.want {
+			t.Errorf("night.Active(%s) = %v, %v", tt.t.Format("15:04"), got, err)
+		}
+	}
+	lunch := QuietHours{Start: "12:00", End: "13:00", Timezone: "UTC"}
+	if got, _ := lunch.Active(at(12, 30)); !got {
+		t.Error("lunch window not active at 12:30")
+	}
+	if got, _ := lunch.Active(at(13, 30)); got {
+		t.Error("lunch window active at 13:30")
+	}
+	if _, err := (QuietHours{Start: "10pm", End: "07:00"}).Active(at(0, 0)); err == nil {
+		t.Error("invalid start accepted")
+	}
+}
+
+func TestConfigDurations(t *testing.T) {
+	for _, tt := range []struct {
+		cfg Config
+		err string
+	}{
+		{Config{Channels: []Channel{{Type: TypeSlack, URL: "u"}}}, ""},
+		{Config{Batch: "soon"}, "batch"},
+		{Config{Channels: []Channel{{Type: TypeSlack}}}, "needs a url"},
+		{Config{Channels: []Channel{{Type: TypeEmail, To: []string{"a@b"}}}}, "smtp"},
+		{Config{Channels: []Channel{{Type: "pager", URL: "u"}}}, "unknown type"},
+		{Config{Channels: []Channel{{Type: TypeSlack, URL: "u", Kinds: []string{"merge"}}}}, "unknown kind"},
+		{Config{Channels: []Channel{{Type: TypeSlack, URL: "u", MinSeverity: "urgent"}}}, "min_severity"},
+		{Config{Channels: []Channel{{Type: TypeSlack, URL: "a"}, {Type: TypeSlack, URL: "b"}}}, "duplicate"},
+		{Config{Channels: []Channel{{Type: TypeSlack, URL: "u", QuietHours: &QuietHours{Start: "22:00", End: "7"}}}}, "quiet_hours end"},
+	} {
+		batch, dedup, err := tt.cfg.Durations()
+		switch {
+		case tt.err == "" && (err != nil || batch != DefaultBatch || dedup != DefaultDedup):
+			t.Errorf("%+v: %v, %v, %v", tt.cfg, batch, dedup, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%+v: error = %v, want %q", tt.cfg, err, tt.err)
+		}
+	}
+}
+
+// recorder is a webhook endpoint that records posted bodies.
+type recorder struct {
+	mu     sync.Mutex
+	bodies []map[string]interface{}
+	fail   bool
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var body map[string]interface{}
+	_ = json.NewDecoder(req.Body).Decode(&body)
+	r.bodies = append(r.bodies, body)
+}
+
+func (r *recorder) posts() []map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]map[string]interface{}(nil), r.bodies...)
+}
+
+func TestBridge_DedupBatchAndRetry(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	townRoot := t.TempDir()
+	b, err := NewBridge(townRoot, Config{Channels: []Channel{{Name: "ops", Type: TypeSlack, URL: srv.URL}}}, t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	now := time.Now()
+	alert := Notification{Kind: KindAlert, Severity: "high", Title: "Alert merge-failures", Body: "4 merge_failed", Key: "alert:merge-failures"}
+
+	// Two distinct notifications and a repeat share one message
+	_ = b.Notify(alert, now)
+	_ = b.Notify(Notification{Kind: KindEscalation, Severity: "medium", Title: "Escalation hq-1: stuck", Key: "escalation:hq-1:medium"}, now)
+	_ = b.Notify(alert, now.Add(time.Second))
+	b.Flush(ctx, now.Add(time.Minute))
+	posts := rec.posts()
+	if len(posts) != 1 {
+		t.Fatalf("got %d posts, want 1 batch", len(posts))
+	}
+	text, _ := posts[0]["text"].(string)
+	if !strings.HasPrefix(text, "2 notifications:") || !strings.Contains(text, "Alert merge-failures ×2") {
+		t.Errorf("batch text = %q", text)
+	}
+
+	// A repeat within the dedup window after delivery is suppressed
+	_ = b.Notify(alert, now.Add(10*time.Minute))
+	b.Flush(ctx, now.Add(11*time.Minute))
+	if len(rec.posts()) != 1 {
+		t.Error("duplicate delivered within the dedup window")
+	}
+	// ...but delivered again once the window has passed
+	_ = b.Notify(alert, now.Add(2*time.Hour))
+	b.Flush(ctx, now.Add(2*time.Hour+time.Minute))
+	if len(rec.posts()) != 2 {
+		t.Errorf("got %d posts after the dedup window, want 2", len(rec.posts()))
+	}
+
+	// Failed deliveries stay pending and are retried
+	rec.mu.Lock()
+	rec.fail = true
+	rec.mu.Unlock()
+	_ = b.Notify(Notification{Kind: KindAlert, Severity: "high", Title: "Alert other", Key: "alert:other"}, now.Add(3*time.Hour))
+	b.Flush(ctx, now.Add(3*time.Hour+time.Minute))
+	state, err := LoadState(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs := state["ops"]; cs == nil || len(cs.Pending) != 1 || cs.Error == "" || cs.Sent != 3 || cs.Suppressed != 1 {
+		t.Fatalf("state after failure = %+v", state["ops"])
+	}
+	rec.mu.Lock()
+	rec.fail = false
+	rec.mu.Unlock()
+	b.Flush(ctx, now.Add(3*time.Hour+2*time.Minute))
+	state, _ = LoadState(townRoot)
+	if cs := state["ops"]; len(cs.Pending) != 0 || cs.Error != "" || len(rec.posts()) != 3 {
+		t.Errorf("state after retry = %+v, %d posts", cs, len(rec.posts()))
+	}
+}
+
+func TestBridge_QuietHoursHoldAllButCritical(t *testing.T) {
+	var sent []string
+	townRoot := t.TempDir()
+	cfg := Config{
+		SMTP: &SMTPConfig{Host: "smtp.example.com", From: "gt@example.com"},
+		Channels: []Channel{{Type: TypeEmail, To: []string{"me@example.com"},
+			QuietHours: &QuietHours{Start: "22:00", End: "07:00", Timezone: "UTC"}}},
+	}
+	b, err := NewBridge(townRoot, cfg, t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.sendMail = func(addr string, _ smtp.Auth, from string, to []string, msg []byte) error {
+		if addr != "smtp.example.com:587" || from != "gt@example.com" || to[0] != "me@example.com" {
+			return errors.New("bad envelope")
+		}
+		sent = append(sent, string(msg))
+		return nil
+	}
+
+	ctx := context.Background()
+	night := time.Date(2026, 1, 15, 23, 0, 0, 0, time.UTC)
+	_ = b.Notify(Notification{Kind: KindAlert, Severity: "high", Title: "Alert a", Key: "a"}, night)
+	_ = b.Notify(Notification{Kind: KindEscalation, Severity: "critical", Title: "Escalation hq-2: outage", Key: "b"}, night)
+	b.Flush(ctx, night.Add(time.Minute))
+	if len(sent) != 1 || !strings.Contains(sent[0], "Subject: [Gas Town] Escalation hq-2: outage") {
+		t.Fatalf("during quiet hours sent %q", sent)
+	}
+
+	b.Flush(ctx, night.Add(8*time.Hour)) // 07:00
+	if len(sent) != 2 || !strings.Contains(sent[1], "Alert a") {
+		t.Errorf("held notification not sent after quiet hours: %q", sent)
+	}
+}
+
+func TestBridge_ReceivesEventsFromForwarder(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	townRoot := t.TempDir()
+	b, err := NewBridge(townRoot, Config{Channels: []Channel{{Type: TypeWebhook, URL: srv.URL, Kinds: []string{KindSLO}}}}, t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := eventsink.NewForwarder(townRoot, nil, t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Attach("notifications", EventTypes, b)
+	ctx := context.Background()
+	f.Poll(ctx, time.Now()) // Starts the cursor at the end of the log
+
+	emit := func(rule, slo string) {
+		p := events.AlertPayload(rule, -1, "budget gone")
+		if slo != "" {
+			p["slo"] = slo
+		}
+		if err := events.EmitTo(townRoot, events.Event{Type: events.TypeAlertFired, Actor: "daemon", Rig: "gastown", Payload: p}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	emit("merge-failures", "") // Not an SLO: not routed to this channel
+	emit("slo-merge-2h", "merge-2h")
+	f.Poll(ctx, time.Now())
+	b.Flush(ctx, time.Now())
+
+	posts := rec.posts()
+	if len(posts) != 1 {
+		t.Fatalf("got %d posts, want 1", len(posts))
+	}
+	ns, _ := posts[0]["notifications"].([]interface{})
+	if len(ns) != 1 || !strings.Contains(ns[0].(map[string]interface{})["title"].(string), "SLO merge-2h") {
+		t.Errorf("webhook body = %v", posts[0])
+	}
+}
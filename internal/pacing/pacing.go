@@ -0,0 +1,205 @@
+// Package pacing paces a rig's autonomous work to the capacity of the people
+// reviewing it, so work doesn't flood the merge queue overnight. Polecats are
+// only spawned during the rig's working hours, and at most
+// max_slings_per_hour beads are slung to (or claimed in) the rig in any
+// rolling hour.
+//
+// Pacing is configured under "pacing" in a rig's settings/config.json. The
+// rig's recent slings live in <rig>/.runtime/pacing.json.
+package pacing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/runstate"
+	"github.com/steveyegge/gastown/internal/schedule"
+)
+
+var (
+	// ErrOutsideHours is returned when a polecat would spawn outside the
+	// rig's working hours.
+	ErrOutsideHours = errors.New("outside working hours")
+
+	// ErrRateLimited is returned when the rig has had its hourly quota of
+	// slings.
+	ErrRateLimited = errors.New("sling rate limit reached")
+)
+
+// stateFile is the pacing document in the rig's runtime store.
+const stateFile = "pacing.json"
+
+// Sling is one bead slung to or claimed in the rig.
+type Sling struct {
+	At   time.Time `json:"at"`
+	Bead string    `json:"bead,omitempty"`
+}
+
+// State is a rig's pacing state.
+type State struct {
+	Recent []Sling `json:"recent,omitempty"` // slings in the last hour
+}
+
+// Status describes a rig's pacing at a moment.
+type Status struct {
+	WorkingHours []string
+	InHours      bool
+	NextOpen     time.Time // When working hours next begin (zero while open)
+
+	Limit    int       // Max slings per hour (0: unlimited)
+	Slung    int       // Slings in the last hour
+	NextSlot time.Time // When a sling is next allowed (zero if allowed now)
+}
+
+// Pacer applies a rig's PacingConfig.
+type Pacer struct {
+	rig   string
+	cfg   config.PacingConfig
+	hours schedule.Windows
+	loc   *time.Location
+	doc   *runstate.Doc[State]
+	now   func() time.Time
+}
+
+// New creates a Pacer for the rig at rigPath. A nil cfg paces nothing.
+func New(rigName, rigPath string, cfg *config.PacingConfig) (*Pacer, error) {
+	p := &Pacer{
+		rig: rigName,
+		loc: time.Local,
+		doc: runstate.NewDoc[State](runstate.Open(rigPath), stateFile, nil),
+		now: time.Now,
+	}
+	if cfg == nil {
+		return p, nil
+	}
+	p.cfg = *cfg
+	hours, err := schedule.ParseWindows(cfg.WorkingHours)
+	if err != nil {
+		return nil, fmt.Errorf("pacing working_hours: %w", err)
+	}
+	p.hours = hours
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("pacing timezone: %w", err)
+		}
+		p.loc = loc
+	}
+	if cfg.MaxSlingsPerHour < 0 {
+		return nil, fmt.Errorf("pacing max_slings_per_hour must not be negative")
+	}
+	return p, nil
+}
+
+// ForRig creates a Pacer from the rig's settings. A rig without settings
+// is not paced.
+func ForRig(rigName, rigPath string) (*Pacer, error) {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil {
+		if errors.Is(err, config.ErrNotFound) {
+			return New(rigName, rigPath, nil)
+		}
+		return nil, err
+	}
+	return New(rigName, rigPath, settings.Pacing)
+}
+
+// Paced reports whether the rig has any pacing configured.
+func (p *Pacer) Paced() bool {
+	return len(p.hours) > 0 || p.cfg.MaxSlingsPerHour > 0
+}
+
+// CheckSpawn returns an error wrapping ErrOutsideHours if a polecat may not
+// be spawned now.
+func (p *Pacer) CheckSpawn() error {
+	if len(p.hours) == 0 {
+		return nil
+	}
+	now := p.now().In(p.loc)
+	if p.hours.Contains(now) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s spawns polecats during %s; next window opens %s",
+		ErrOutsideHours, p.rig, strings.Join(p.cfg.WorkingHours, ", "), formatTime(p.hours.NextOpen(now)))
+}
+
+// Take records a sling of bead in the rig, or returns an error wrapping
+// ErrRateLimited if the rig has had its hourly quota. The returned undo
+// function forgets the sling again, for a sling that then failed.
+func (p *Pacer) Take(bead string) (undo func(), err error) {
+	limit := p.cfg.MaxSlingsPerHour
+	if limit <= 0 {
+		return func() {}, nil
+	}
+	now := p.now()
+	_, err = p.doc.Update(func(s *State) error {
+		s.Recent = recent(s.Recent, now)
+		if len(s.Recent) >= limit {
+			return fmt.Errorf("%w: %d bead(s) slung to %s in the last hour (max %d); next slot %s",
+				ErrRateLimited, len(s.Recent), p.rig, limit, formatTime(s.Recent[0].At.Add(time.Hour).In(p.loc)))
+		}
+		s.Recent = append(s.Recent, Sling{At: now, Bead: bead})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		_, _ = p.doc.Update(func(s *State) error {
+			for i, sl := range s.Recent {
+				if sl.At.Equal(now) && sl.Bead == bead {
+					s.Recent = append(s.Recent[:i], s.Recent[i+1:]...)
+					return nil
+				}
+			}
+			return runstate.ErrSkip
+		})
+	}, nil
+}
+
+// Status returns the rig's pacing now.
+func (p *Pacer) Status() (*Status, error) {
+	now := p.now().In(p.loc)
+	st := &Status{
+		WorkingHours: p.cfg.WorkingHours,
+		InHours:      len(p.hours) == 0 || p.hours.Contains(now),
+		Limit:        p.cfg.MaxSlingsPerHour,
+	}
+	if !st.InHours {
+		st.NextOpen = p.hours.NextOpen(now)
+	}
+	if st.Limit > 0 {
+		s, err := p.doc.Load()
+		if err != nil {
+			return nil, err
+		}
+		slings := recent(s.Recent, now)
+		st.Slung = len(slings)
+		if st.Slung >= st.Limit {
+			st.NextSlot = slings[0].At.Add(time.Hour).In(p.loc)
+		}
+	}
+	return st, nil
+}
+
+// recent returns the slings within the hour before now, oldest first.
+func recent(slings []Sling, now time.Time) []Sling {
+	cutoff := now.Add(-time.Hour)
+	var kept []Sling
+	for _, s := range slings {
+		if s.At.After(cutoff) {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format("Mon 15:04 MST")
+}
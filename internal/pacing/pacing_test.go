@@ -0,0 +1,132 @@
+package pacing
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func newTestPacer(t *testing.T, cfg *config.PacingConfig, now *time.Time) *Pacer {
+	t.Helper()
+	p, err := New("gastown", t.TempDir(), cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	p.now = func() time.Time { return *now }
+	return p
+}
+
+func TestCheckSpawn(t *testing.T) {
+	// 2026-03-02 is a Monday
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	p := newTestPacer(t, &config.PacingConfig{
+		WorkingHours: []string{"weekday 09:00-18:00"},
+		Timezone:     "UTC",
+	}, &now)
+
+	if err := p.CheckSpawn(); err != nil {
+		t.Errorf("CheckSpawn in hours: %v", err)
+	}
+	now = time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC)
+	err := p.CheckSpawn()
+	if !errors.Is(err, ErrOutsideHours) {
+		t.Fatalf("CheckSpawn at night = %v, want ErrOutsideHours", err)
+	}
+	st, err := p.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.InHours || !st.NextOpen.Equal(time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Status = %+v", st)
+	}
+
+	// Timezone applies to the windows: 23:00 UTC is 08:00 in Tokyo
+	p = newTestPacer(t, &config.PacingConfig{
+		WorkingHours: []string{"weekday 07:00-18:00"},
+		Timezone:     "Asia/Tokyo",
+	}, &now)
+	if err := p.CheckSpawn(); err != nil {
+		t.Errorf("CheckSpawn in Tokyo hours: %v", err)
+	}
+}
+
+func TestTake(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	p := newTestPacer(t, &config.PacingConfig{MaxSlingsPerHour: 2}, &now)
+
+	if _, err := p.Take("gt-1"); err != nil {
+		t.Fatalf("Take 1: %v", err)
+	}
+	now = now.Add(20 * time.Minute)
+	undo, err := p.Take("gt-2")
+	if err != nil {
+		t.Fatalf("Take 2: %v", err)
+	}
+	if _, err := p.Take("gt-3"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Take 3 = %v, want ErrRateLimited", err)
+	}
+
+	// A failed sling gives its slot back
+	undo()
+	if _, err := p.Take("gt-3"); err != nil {
+		t.Fatalf("Take after undo: %v", err)
+	}
+
+	st, err := p.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Slung != 2 || !st.NextSlot.Equal(time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("Status = %+v", st)
+	}
+
+	// The oldest sling leaves the window after an hour
+	now = time.Date(2026, 3, 2, 11, 0, 1, 0, time.UTC)
+	if _, err := p.Take("gt-4"); err != nil {
+		t.Errorf("Take after an hour: %v", err)
+	}
+}
+
+func TestUnpaced(t *testing.T) {
+	now := time.Date(2026, 3, 2, 3, 0, 0, 0, time.UTC)
+	p := newTestPacer(t, nil, &now)
+	if p.Paced() {
+		t.Error("Paced() with no config")
+	}
+	if err := p.CheckSpawn(); err != nil {
+		t.Errorf("CheckSpawn: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := p.Take("gt-1"); err != nil {
+			t.Fatalf("Take: %v", err)
+		}
+	}
+	if _, err := os.Stat(p.doc.Path()); !os.IsNotExist(err) {
+		t.Error("unlimited rig should not record slings")
+	}
+}
+
+func TestForRig(t *testing.T) {
+	rigPath := t.TempDir()
+	p, err := ForRig("gastown", rigPath)
+	if err != nil || p.Paced() {
+		t.Fatalf("ForRig without settings = %v, %v", p, err)
+	}
+
+	settings := config.NewRigSettings()
+	settings.Pacing = &config.PacingConfig{WorkingHours: []string{"weekday 25:00-26:00"}}
+	path := config.RigSettingsPath(rigPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveRigSettings(path, settings); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ForRig("gastown", rigPath); err == nil {
+		t.Error("ForRig with invalid working_hours should fail")
+	}
+}
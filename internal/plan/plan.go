@@ -0,0 +1,191 @@
+// Package plan turns a large bead into a swarm plan: child steps with
+// dependencies and a model tier each, drafted by an agent for the Mayor to
+// approve before anything is spawned.
+//
+// A plan is written in the molecule step format (see
+// beads.ParseMoleculeSteps), so an approved plan is instantiated like any
+// molecule: one child bead per step under the planned bead, wired by their
+// Needs. Drafts are plan beads whose description holds the fields below
+// followed by the steps.
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Label marks plan beads.
+const Label = "gt:plan"
+
+// Plan states, recorded in the plan bead's status field.
+const (
+	StatusDraft    = "draft"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+// Fields holds the structured fields of a plan bead.
+// They are stored as key: value lines at the top of its description.
+type Fields struct {
+	For       string // the bead being planned
+	Rig       string // where the steps are slung
+	Agent     string // agent that drafted the plan
+	Status    string // draft, approved, or rejected
+	Convoy    string // convoy tracking the steps, once approved
+	DecidedBy string
+	Note      string
+}
+
+// ParseFields extracts plan fields from an issue's description.
+// Returns nil if the issue has no plan fields.
+func ParseFields(issue *beads.Issue) *Fields {
+	if issue == nil {
+		return nil
+	}
+	kv := beads.ParseFieldHeader(issue.Description)
+	f := &Fields{
+		For:       kv["plan_for"],
+		Rig:       kv["rig"],
+		Agent:     kv["planned_by"],
+		Status:    kv["plan_status"],
+		Convoy:    kv["convoy"],
+		DecidedBy: kv["decided_by"],
+		Note:      kv["decision_note"],
+	}
+	if *f == (Fields{}) {
+		return nil
+	}
+	return f
+}
+
+// Format renders the fields as description lines. Empty fields are omitted.
+func (f *Fields) Format() string {
+	return beads.FormatFieldHeader(
+		beads.Field{Key: "plan_for", Value: f.For},
+		beads.Field{Key: "rig", Value: f.Rig},
+		beads.Field{Key: "planned_by", Value: f.Agent},
+		beads.Field{Key: "plan_status", Value: f.Status},
+		beads.Field{Key: "convoy", Value: f.Convoy},
+		beads.Field{Key: "decided_by", Value: f.DecidedBy},
+		beads.Field{Key: "decision_note", Value: f.Note},
+	)
+}
+
+// Plan is a decomposition of a bead into steps.
+type Plan struct {
+	Steps []beads.MoleculeStep
+}
+
+// Parse extracts a plan from agent output. Anything before the first step
+// is ignored, so the agent may think out loud first. The steps must form a
+// valid molecule: unique refs, known Needs, no cycles.
+func Parse(output string) (*Plan, error) {
+	start := strings.Index(output, "## Step:")
+	if start < 0 {
+		return nil, fmt.Errorf("no steps in plan (expected \"## Step: <ref>\" sections)")
+	}
+	text := strings.TrimSpace(output[start:])
+	if err := beads.ValidateMolecule(&beads.Issue{Type: "molecule", Description: text}); err != nil {
+		return nil, fmt.Errorf("invalid plan: %w", err)
+	}
+	steps, err := beads.ParseMoleculeSteps(text)
+	if err != nil {
+		return nil, err
+	}
+	return &Plan{Steps: steps}, nil
+}
+
+// Markdown renders the plan in the molecule step format.
+func (p *Plan) Markdown() string {
+	var b strings.Builder
+	for i, s := range p.Steps {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## Step: %s\n", s.Ref)
+		if s.Instructions != "" {
+			b.WriteString(s.Instructions)
+			b.WriteString("\n")
+		} else {
+			b.WriteString(s.Title)
+			b.WriteString("\n")
+		}
+		if len(s.Needs) > 0 {
+			fmt.Fprintf(&b, "Needs: %s\n", strings.Join(s.Needs, ", "))
+		}
+		if s.Tier != "" {
+			fmt.Fprintf(&b, "Tier: %s\n", s.Tier)
+		}
+	}
+	return b.String()
+}
+
+// Roots returns the steps that need no other step: the ones that can start
+// as soon as the plan is approved.
+func (p *Plan) Roots() []beads.MoleculeStep {
+	var roots []beads.MoleculeStep
+	for _, s := range p.Steps {
+		if len(s.Needs) == 0 {
+			roots = append(roots, s)
+		}
+	}
+	return roots
+}
+
+// Tiers counts the steps per tier; steps without a tier count as "".
+func (p *Plan) Tiers() map[string]int {
+	counts := make(map[string]int)
+	for _, s := range p.Steps {
+		counts[s.Tier]++
+	}
+	return counts
+}
+
+// Describe builds a plan bead description: the fields, then the steps.
+func Describe(f *Fields, p *Plan) string {
+	return f.Format() + "\n\n" + p.Markdown()
+}
+
+// FromIssue reads the plan back out of a plan bead.
+func FromIssue(issue *beads.Issue) (*Plan, error) {
+	return Parse(issue.Description)
+}
+
+// withFields replaces the field lines of a plan description, keeping the
+// steps that follow them.
+func withFields(description string, f *Fields) string {
+	return beads.SetFieldHeader(description, f.Format())
+}
+
+// Prompt builds the instructions for the agent that drafts a plan.
+func Prompt(issue *beads.Issue) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Decompose this work item into a plan for a swarm of parallel workers.\n\n")
+	fmt.Fprintf(&b, "# %s: %s\n\n", issue.ID, issue.Title)
+	if desc := strings.TrimSpace(issue.Description); desc != "" {
+		b.WriteString(desc)
+		b.WriteString("\n\n")
+	}
+	b.WriteString(`Read the repository in the current directory as needed. Do not change
+any files. Split the work into steps that one worker can finish and merge
+on its own, each small enough for one session. Prefer steps that can run
+in parallel; add a dependency only where a step really needs another's
+merged result.
+
+Answer with the steps only, in exactly this format:
+
+## Step: <short-ref>
+<one-line title>
+<instructions for the worker: what to change, where, how to verify>
+Needs: <ref>, <ref>
+Tier: haiku|sonnet|opus
+
+Refs are lowercase words joined by hyphens. Omit the Needs line for steps
+with no dependencies. Tier estimates the model a step needs: haiku for
+mechanical edits, sonnet for ordinary feature work, opus for design-heavy or
+risky changes.
+`)
+	return b.String()
+}
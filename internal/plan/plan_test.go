@@ -0,0 +1,96 @@
+package plan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+const agentOutput = `Looking at the repo, the migration splits three ways.
+
+## Step: schema
+Add the accounts table
+Write migrations/002_accounts.sql and run the migration tests.
+Tier: sonnet
+
+## Step: api
+Expose accounts over the API
+Needs: schema
+Tier: sonnet
+
+## Step: docs
+Document the accounts endpoints
+Needs: api
+Tier: haiku
+`
+
+func TestParse(t *testing.T) {
+	p, err := Parse(agentOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Steps) != 3 {
+		t.Fatalf("got %d steps, want 3", len(p.Steps))
+	}
+	if s := p.Steps[1]; s.Ref != "api" || s.Title != "Expose accounts over the API" || s.Tier != "sonnet" ||
+		len(s.Needs) != 1 || s.Needs[0] != "schema" {
+		t.Errorf("step api = %+v", s)
+	}
+	if roots := p.Roots(); len(roots) != 1 || roots[0].Ref != "schema" {
+		t.Errorf("Roots() = %+v, want [schema]", roots)
+	}
+	if tiers := p.Tiers(); tiers["sonnet"] != 2 || tiers["haiku"] != 1 {
+		t.Errorf("Tiers() = %v", tiers)
+	}
+}
+
+func TestParseRejectsBadPlans(t *testing.T) {
+	for name, output := range map[string]string{
+		"no steps":     "I could not work out a plan.",
+		"unknown need": "## Step: a\nDo a\nNeeds: b\n",
+		"cycle":        "## Step: a\nDo a\nNeeds: b\n\n## Step: b\nDo b\nNeeds: a\n",
+	} {
+		if _, err := Parse(output); err == nil {
+			t.Errorf("%s: Parse accepted %q", name, output)
+		}
+	}
+}
+
+func TestDescribeRoundTrip(t *testing.T) {
+	p, err := Parse(agentOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &Fields{For: "gt-big", Rig: "gastown", Agent: "claude", Status: StatusDraft}
+	issue := &beads.Issue{Description: Describe(f, p)}
+
+	if got := ParseFields(issue); got == nil || *got != *f {
+		t.Errorf("ParseFields = %+v, want %+v", got, f)
+	}
+	back, err := FromIssue(issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Markdown() != p.Markdown() {
+		t.Errorf("steps changed:\n%s\nwant:\n%s", back.Markdown(), p.Markdown())
+	}
+
+	f.Status = StatusApproved
+	f.Convoy = "hq-cv-1"
+	updated := withFields(issue.Description, f)
+	if got := ParseFields(&beads.Issue{Description: updated}); got.Convoy != "hq-cv-1" {
+		t.Errorf("convoy not recorded: %+v", got)
+	}
+	if !strings.HasSuffix(updated, p.Markdown()) {
+		t.Errorf("steps lost from updated description:\n%s", updated)
+	}
+}
+
+func TestParseFieldsIgnoresSteps(t *testing.T) {
+	f := &Fields{For: "gt-big", Rig: "gastown", Status: StatusDraft}
+	desc := f.Format() + "\n\nrig: other\n\n## Step: a\nDo a\nplan_status: approved\n"
+	if got := ParseFields(&beads.Issue{Description: desc}); got == nil || *got != *f {
+		t.Errorf("ParseFields = %+v, want %+v", got, f)
+	}
+}
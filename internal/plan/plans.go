@@ -0,0 +1,138 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// ErrNotFound is returned when a bead has no draft plan.
+var ErrNotFound = errors.New("no draft plan")
+
+// Plans manages plan beads in one beads database.
+type Plans struct {
+	bd *beads.Beads
+}
+
+// New returns a Plans backed by bd.
+func New(bd *beads.Beads) *Plans {
+	return &Plans{bd: bd}
+}
+
+// Draft files a plan for f.For as a draft awaiting approval.
+func (ps *Plans) Draft(f *Fields, p *Plan, title string) (*beads.Issue, error) {
+	f.Status = StatusDraft
+	issue, err := ps.bd.Create(beads.CreateOptions{
+		Title:       "Plan: " + title,
+		Type:        "plan",
+		Priority:    2,
+		Description: Describe(f, p),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating plan bead: %w", err)
+	}
+	return issue, nil
+}
+
+// Drafts returns the plans awaiting approval.
+func (ps *Plans) Drafts() ([]*beads.Issue, error) {
+	issues, err := ps.bd.List(beads.ListOptions{
+		Status:   "open",
+		Label:    Label,
+		Priority: -1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// For returns the draft plan for a bead, or ErrNotFound.
+func (ps *Plans) For(beadID string) (*beads.Issue, *Fields, error) {
+	issues, err := ps.Drafts()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, issue := range issues {
+		if f := ParseFields(issue); f != nil && f.For == beadID {
+			return issue, f, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w for %s", ErrNotFound, beadID)
+}
+
+// Get returns a draft plan by the plan bead's ID or the planned bead's.
+func (ps *Plans) Get(id string) (*beads.Issue, *Fields, error) {
+	issue, err := ps.bd.Show(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !beads.HasLabel(issue, Label) {
+		return ps.For(id)
+	}
+	f := ParseFields(issue)
+	if f == nil {
+		return nil, nil, fmt.Errorf("%s is not a plan", id)
+	}
+	if issue.Status == "closed" {
+		return nil, nil, fmt.Errorf("%w: %s was already %s", ErrNotFound, id, f.Status)
+	}
+	return issue, f, nil
+}
+
+// Approve instantiates a draft plan: one child of the planned bead per
+// step, with dependencies wired by Needs. The plan bead is closed.
+func (ps *Plans) Approve(issue *beads.Issue, f *Fields, by, note string) ([]*beads.Issue, error) {
+	parent, err := ps.bd.Show(f.For)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", f.For, err)
+	}
+	// Instantiate from the steps alone; the field lines aren't instructions.
+	p, err := FromIssue(issue)
+	if err != nil {
+		return nil, err
+	}
+	mol := &beads.Issue{ID: issue.ID, Type: "molecule", Description: p.Markdown()}
+	children, err := ps.bd.InstantiateMolecule(mol, parent, beads.InstantiateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("instantiating plan %s: %w", issue.ID, err)
+	}
+
+	f.Status = StatusApproved
+	f.DecidedBy = by
+	f.Note = note
+	if err := ps.close(issue, f); err != nil {
+		return children, err
+	}
+	return children, nil
+}
+
+// Reject closes a draft plan without instantiating it.
+func (ps *Plans) Reject(issue *beads.Issue, f *Fields, by, note string) error {
+	f.Status = StatusRejected
+	f.DecidedBy = by
+	f.Note = note
+	return ps.close(issue, f)
+}
+
+// Record updates a plan bead's fields, e.g. to note its convoy.
+func (ps *Plans) Record(issue *beads.Issue, f *Fields) error {
+	description := withFields(issue.Description, f)
+	if err := ps.bd.Update(issue.ID, beads.UpdateOptions{Description: &description}); err != nil {
+		return err
+	}
+	issue.Description = description
+	return nil
+}
+
+func (ps *Plans) close(issue *beads.Issue, f *Fields) error {
+	if err := ps.Record(issue, f); err != nil {
+		return err
+	}
+	reason := f.Status
+	if f.Note != "" {
+		reason += ": " + f.Note
+	}
+	return ps.bd.CloseWithReason(reason, issue.ID)
+}
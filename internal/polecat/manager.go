@@ -172,7 +172,7 @@ func (m *Manager) repoBase() (*git.Git, error) {
 	bareRepoPath := filepath.Join(m.rig.Path, ".repo.git")
 	if info, err := os.Stat(bareRepoPath); err == nil && info.IsDir() {
 		// Bare repo exists - use it
-		return git.NewGitWithDir(bareRepoPath, ""), nil
+		return rig.WithGitAuth(m.rig.Path, git.NewGitWithDir(bareRepoPath, "")), nil
 	}
 
 	// Fall back to mayor/rig (legacy architecture)
@@ -180,7 +180,7 @@ func (m *Manager) repoBase() (*git.Git, error) {
 	if _, err := os.Stat(mayorPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("no repo base found (neither .repo.git nor mayor/rig exists)")
 	}
-	return git.NewGit(mayorPath), nil
+	return rig.WithGitAuth(m.rig.Path, git.NewGit(mayorPath)), nil
 }
 
 // polecatDir returns the parent directory for a polecat.
@@ -262,6 +262,11 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 		branchName = fmt.Sprintf("polecat/%s-%s", name, timestamp)
 	}
 
+	// Reject branch names outside the rig's branch namespace policy
+	if err := rig.CheckBranchPolicy(m.rig.Path, branchName); err != nil {
+		return nil, err
+	}
+
 	// Create polecat directory (polecats/<name>/)
 	if err := os.MkdirAll(polecatDir, 0755); err != nil {
 		return nil, fmt.Errorf("creating polecat dir: %w", err)
@@ -273,7 +278,11 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 		return nil, fmt.Errorf("finding repo base: %w", err)
 	}
 
-	// Fetch latest from origin to ensure worktree starts from up-to-date code
+	// Fetch latest from origin to ensure worktree starts from up-to-date code.
+	// Refresh the shared mirror first (if any) so the fetch reuses its objects.
+	if err := rig.RefreshMirror(m.rig.Path); err != nil {
+		fmt.Printf("Warning: could not refresh shared mirror: %v\n", err)
+	}
 	if err := repoGit.Fetch("origin"); err != nil {
 		// Non-fatal - proceed with potentially stale code
 		fmt.Printf("Warning: could not fetch origin: %v\n", err)
@@ -290,10 +299,23 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 	// Always create fresh branch - unique name guarantees no collision
 	// git worktree add -b polecat/<name>-<timestamp> <path> <startpoint>
 	// Worktree goes in polecats/<name>/<rigname>/ for LLM ergonomics
-	if err := repoGit.WorktreeAddFromRef(clonePath, branchName, startPoint); err != nil {
+	// LFS repos: configure filters, or skip smudge if the rig opts out of binary assets
+	wtGit := rig.WorktreeGit(m.rig.Path, repoGit, startPoint)
+	if err := wtGit.WorktreeAddFromRef(clonePath, branchName, startPoint); err != nil {
 		return nil, fmt.Errorf("creating worktree from %s: %w", startPoint, err)
 	}
 
+	// Populate submodules (fresh worktrees don't have them)
+	if err := rig.UpdateSubmodules(m.rig.Path, clonePath); err != nil {
+		// Non-fatal - polecat can run "git submodule update --init" itself
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	// Large-repo performance settings (fsmonitor, untracked cache)
+	if err := rig.ApplyLargeRepoMode(m.rig.Path, clonePath); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
 	// Ensure AGENTS.md exists - critical for polecats to "land the plane"
 	// Fall back to copy from mayor/rig if not in git (e.g., stale fetch, local-only file)
 	agentsMDPath := filepath.Join(clonePath, "AGENTS.md")
@@ -341,6 +363,13 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 		fmt.Printf("Warning: could not run setup hooks: %v\n", err)
 	}
 
+	// Install rig git hooks from .runtime/git-hooks/ so commit conventions
+	// are enforced in the worktree before work reaches the merge queue.
+	if err := rig.InstallGitHooks(m.rig.Path, clonePath); err != nil {
+		// Non-fatal - log warning but continue
+		fmt.Printf("Warning: could not install git hooks: %v\n", err)
+	}
+
 	// NOTE: Slash commands (.claude/commands/) are provisioned at town level by gt install.
 	// All agents inherit them via Claude's directory traversal - no per-workspace copies needed.
 
@@ -562,6 +591,21 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 		}
 	}
 
+	// Branch naming: include issue ID when available for better traceability.
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 36)
+	var branchName string
+	if opts.HookBead != "" {
+		branchName = fmt.Sprintf("polecat/%s/%s@%s", name, opts.HookBead, timestamp)
+	} else {
+		branchName = fmt.Sprintf("polecat/%s-%s", name, timestamp)
+	}
+
+	// Reject branch names outside the rig's branch namespace policy
+	// before tearing down the old worktree
+	if err := rig.CheckBranchPolicy(m.rig.Path, branchName); err != nil {
+		return nil, err
+	}
+
 	// Close old agent bead before recreation (non-fatal)
 	// NOTE: We use CloseAndClearAgentBead instead of DeleteAgentBead because bd delete --hard
 	// creates tombstones that cannot be reopened.
@@ -584,6 +628,9 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 	_ = repoGit.WorktreePrune()
 
 	// Fetch latest from origin to ensure we have fresh commits (non-fatal: may be offline)
+	if err := rig.RefreshMirror(m.rig.Path); err != nil {
+		fmt.Printf("Warning: could not refresh shared mirror: %v\n", err)
+	}
 	_ = repoGit.Fetch("origin")
 
 	// Ensure polecat directory exists for new structure
@@ -602,18 +649,19 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 	// Create fresh worktree with unique branch name, starting from origin's default branch
 	// Old branches are left behind - they're ephemeral (never pushed to origin)
 	// and will be cleaned up by garbage collection
-	// Branch naming: include issue ID when available for better traceability.
-	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 36)
-	var branchName string
-	if opts.HookBead != "" {
-		branchName = fmt.Sprintf("polecat/%s/%s@%s", name, opts.HookBead, timestamp)
-	} else {
-		branchName = fmt.Sprintf("polecat/%s-%s", name, timestamp)
-	}
-	if err := repoGit.WorktreeAddFromRef(newClonePath, branchName, startPoint); err != nil {
+	wtGit := rig.WorktreeGit(m.rig.Path, repoGit, startPoint)
+	if err := wtGit.WorktreeAddFromRef(newClonePath, branchName, startPoint); err != nil {
 		return nil, fmt.Errorf("creating fresh worktree from %s: %w", startPoint, err)
 	}
 
+	// Populate submodules (fresh worktrees don't have them)
+	if err := rig.UpdateSubmodules(m.rig.Path, newClonePath); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	if err := rig.ApplyLargeRepoMode(m.rig.Path, newClonePath); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
 	// Ensure AGENTS.md exists - critical for polecats to "land the plane"
 	// Fall back to copy from mayor/rig if not in git (e.g., stale fetch, local-only file)
 	agentsMDPath := filepath.Join(newClonePath, "AGENTS.md")
@@ -639,6 +687,11 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
 	}
 
+	// Install rig git hooks from .runtime/git-hooks/.
+	if err := rig.InstallGitHooks(m.rig.Path, newClonePath); err != nil {
+		fmt.Printf("Warning: could not install git hooks: %v\n", err)
+	}
+
 	// NOTE: Slash commands inherited from town level - no per-workspace copies needed.
 
 	// Create or reopen agent bead for ZFC compliance
@@ -1,14 +1,11 @@
 package polecat
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"sort"
 	"sync"
 
-	"github.com/steveyegge/gastown/internal/util"
+	"github.com/steveyegge/gastown/internal/runstate"
 )
 
 const (
@@ -95,8 +92,8 @@ type NamePool struct {
 	// MaxSize is the maximum number of themed names before overflow.
 	MaxSize int `json:"max_size"`
 
-	// stateFile is the path to persist pool state.
-	stateFile string
+	// state is the persisted pool state in the rig's runtime state store.
+	state *runstate.Doc[namePoolState]
 }
 
 // NewNamePool creates a new name pool for a rig.
@@ -107,7 +104,7 @@ func NewNamePool(rigPath, rigName string) *NamePool {
 		InUse:        make(map[string]bool),
 		OverflowNext: DefaultPoolSize + 1,
 		MaxSize:      DefaultPoolSize,
-		stateFile:    filepath.Join(rigPath, ".runtime", "namepool-state.json"),
+		state:        runstate.NewDoc[namePoolState](runstate.Open(rigPath), "namepool-state.json", nil),
 	}
 }
 
@@ -127,7 +124,7 @@ func NewNamePoolWithConfig(rigPath, rigName, theme string, customNames []string,
 		InUse:        make(map[string]bool),
 		OverflowNext: maxSize + 1,
 		MaxSize:      maxSize,
-		stateFile:    filepath.Join(rigPath, ".runtime", "namepool-state.json"),
+		state:        runstate.NewDoc[namePoolState](runstate.Open(rigPath), "namepool-state.json", nil),
 	}
 }
 
@@ -152,22 +149,12 @@ func (p *NamePool) Load() error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	data, err := os.ReadFile(p.stateFile)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// Initialize with empty state
-			p.InUse = make(map[string]bool)
-			p.OverflowNext = p.MaxSize + 1
-			return nil
-		}
-		return err
-	}
-
 	// Load only runtime state - Theme and CustomNames come from settings/config.json.
 	// ZFC: InUse is NEVER loaded from disk - it's transient state derived
 	// from filesystem via Reconcile(). Always start with empty map.
-	var loaded namePoolState
-	if err := json.Unmarshal(data, &loaded); err != nil {
+	// A missing state file loads as the zero state.
+	loaded, err := p.state.Load()
+	if err != nil {
 		return err
 	}
 
@@ -199,19 +186,12 @@ func (p *NamePool) Save() error {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
-	dir := filepath.Dir(p.stateFile)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-
 	// Only save runtime state, not configuration
-	state := namePoolState{
+	return p.state.Save(&namePoolState{
 		RigName:      p.RigName,
 		OverflowNext: p.OverflowNext,
 		MaxSize:      p.MaxSize,
-	}
-
-	return util.AtomicWriteJSON(p.stateFile, state)
+	})
 }
 
 // Allocate returns a name from the pool.
@@ -0,0 +1,235 @@
+package protocol
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/runstate"
+)
+
+// AckTimeout is how long a protocol message may go unacknowledged before
+// it is delivered again. Each retry doubles the wait.
+const AckTimeout = 15 * time.Minute
+
+// MaxDeliveries is how many times a protocol message is delivered before
+// it is escalated to the Mayor as unacknowledged.
+const MaxDeliveries = 3
+
+// PendingAck is a protocol message awaiting acknowledgment.
+type PendingAck struct {
+	Thread   string           `json:"thread"`
+	Type     MessageType      `json:"type"`
+	From     string           `json:"from"`
+	To       string           `json:"to"`
+	Subject  string           `json:"subject"`
+	Body     string           `json:"body"`
+	Priority mail.Priority    `json:"priority,omitempty"`
+	MsgType  mail.MessageType `json:"msg_type,omitempty"`
+
+	SentAt     time.Time `json:"sent_at"`
+	Deliveries int       `json:"deliveries"`
+	NextCheck  time.Time `json:"next_check"`
+}
+
+// AckLedgerFile returns the path of the town's pending acknowledgments.
+func AckLedgerFile(townRoot string) string {
+	return ackLedger(townRoot).Path()
+}
+
+// ackLedger returns the ack ledger in the town's runtime state store.
+func ackLedger(townRoot string) *runstate.Doc[map[string]*PendingAck] {
+	return runstate.NewDoc(runstate.Open(townRoot), "protocol-acks.json", func() *map[string]*PendingAck {
+		return &map[string]*PendingAck{}
+	})
+}
+
+// LoadPendingAcks reads the pending acknowledgments, keyed by thread ID.
+func LoadPendingAcks(townRoot string) (map[string]*PendingAck, error) {
+	pending, err := ackLedger(townRoot).Load()
+	if err != nil {
+		return nil, err
+	}
+	if *pending == nil {
+		return map[string]*PendingAck{}, nil
+	}
+	return *pending, nil
+}
+
+// RequiresAck reports whether messages of the type must be acknowledged.
+func RequiresAck(t MessageType) bool {
+	switch t {
+	case TypeMergeReady, TypeMerged, TypeMergeFailed, TypeReworkRequest:
+		return true
+	}
+	return false
+}
+
+// AckTracker makes protocol delivery reliable. Sent protocol messages are
+// recorded in a ledger until acknowledged, either explicitly (an ACK in the
+// same thread) or implicitly (the recipient marks the message read or
+// archives it). Check delivers unacknowledged messages again with growing
+// delays and, after MaxDeliveries, escalates them to the Mayor. A NACK is
+// escalated at once.
+type AckTracker struct {
+	townRoot string
+
+	// send delivers a message (mail.Router.Send; replaced in tests).
+	send func(msg *mail.Message) error
+
+	// processed reports whether the recipient has read, archived, or
+	// deleted the pending message (replaced in tests).
+	processed func(p *PendingAck) (bool, error)
+}
+
+// NewAckTracker creates an ack tracker for the town.
+func NewAckTracker(townRoot string) *AckTracker {
+	router := mail.NewRouterWithTownRoot(townRoot, townRoot)
+	return &AckTracker{
+		townRoot: townRoot,
+		send:     router.Send,
+		processed: func(p *PendingAck) (bool, error) {
+			mailbox, err := router.GetMailbox(p.To)
+			if err != nil {
+				return false, err
+			}
+			msgs, err := mailbox.ListByThread(p.Thread)
+			if err != nil {
+				return false, err
+			}
+			for _, m := range msgs {
+				if m.Subject == p.Subject && !m.Read {
+					return false, nil // A delivery is still unread
+				}
+			}
+			return true, nil
+		},
+	}
+}
+
+// Record updates the ledger for a protocol message that was just sent:
+// messages that require acknowledgment start being tracked, an ACK
+// settles its thread, and a NACK escalates it. Other messages are ignored.
+func (t *AckTracker) Record(msg *mail.Message, now time.Time) error {
+	msgType := ParseMessageType(msg.Subject)
+	if msgType == "" || msg.ThreadID == "" {
+		return nil
+	}
+	if msgType != TypeAck && msgType != TypeNack && !RequiresAck(msgType) {
+		return nil
+	}
+	return t.update(func(pending map[string]*PendingAck) {
+		switch msgType {
+		case TypeAck:
+			delete(pending, msg.ThreadID)
+		case TypeNack:
+			if p := pending[msg.ThreadID]; p != nil {
+				reason := ParseAckReason(msg.Body)
+				if reason == "" {
+					reason = "no reason given"
+				}
+				t.escalate(p, msg.From, fmt.Sprintf("rejected by %s: %s", msg.From, reason))
+				delete(pending, msg.ThreadID)
+			}
+		default:
+			if _, ok := pending[msg.ThreadID]; ok {
+				return // Already tracked (a manual resend)
+			}
+			pending[msg.ThreadID] = &PendingAck{
+				Thread:     msg.ThreadID,
+				Type:       msgType,
+				From:       msg.From,
+				To:         msg.To,
+				Subject:    strings.TrimSpace(msg.Subject),
+				Body:       msg.Body,
+				Priority:   msg.Priority,
+				MsgType:    msg.Type,
+				SentAt:     now,
+				Deliveries: 1,
+				NextCheck:  now.Add(AckTimeout),
+			}
+		}
+	})
+}
+
+// AckCheckResult counts what a Check did.
+type AckCheckResult struct {
+	Acked     int `json:"acked"`
+	Retried   int `json:"retried"`
+	Escalated int `json:"escalated"`
+	Pending   int `json:"pending"`
+}
+
+// Check settles messages whose recipient has processed them, delivers
+// overdue ones again, and escalates those delivered MaxDeliveries times.
+func (t *AckTracker) Check(now time.Time) (AckCheckResult, error) {
+	var res AckCheckResult
+	err := t.update(func(pending map[string]*PendingAck) {
+		for thread, p := range pending {
+			if now.Before(p.NextCheck) {
+				res.Pending++
+				continue
+			}
+			if done, err := t.processed(p); err == nil && done {
+				delete(pending, thread)
+				res.Acked++
+				continue
+			}
+			if p.Deliveries >= MaxDeliveries {
+				t.escalate(p, "daemon", fmt.Sprintf("not acknowledged after %d deliveries since %s", p.Deliveries, p.SentAt.Format(time.RFC3339)))
+				delete(pending, thread)
+				res.Escalated++
+				continue
+			}
+
+			retry := mail.NewMessage(p.From, p.To, p.Subject, p.Body)
+			retry.ThreadID = p.Thread
+			retry.Priority = p.Priority
+			retry.Type = p.MsgType
+			retry.Body = strings.TrimRight(p.Body, "\n") + fmt.Sprintf("\n\nRedelivery: %d of %d (unacknowledged since %s; archive this message or reply ACK when processed)\n",
+				p.Deliveries+1, MaxDeliveries, p.SentAt.Format(time.RFC3339))
+			if err := t.send(retry); err != nil {
+				p.NextCheck = now.Add(AckTimeout) // Try again later without counting it
+				res.Pending++
+				continue
+			}
+			p.NextCheck = now.Add(AckTimeout << p.Deliveries)
+			p.Deliveries++
+			res.Retried++
+			res.Pending++
+		}
+	})
+	return res, err
+}
+
+// escalate tells the Mayor about a message that was never acknowledged or
+// was rejected, and records it on the activity feed.
+func (t *AckTracker) escalate(p *PendingAck, from, reason string) {
+	body := fmt.Sprintf("A protocol message was not processed by its recipient.\n\nSubject: %s\nFrom: %s\nTo: %s\nSent-At: %s\nDeliveries: %d\nThread: %s\nProblem: %s\n\nOriginal message:\n\n%s",
+		p.Subject, p.From, p.To, p.SentAt.Format(time.RFC3339), p.Deliveries, p.Thread, reason, p.Body)
+	msg := mail.NewMessage(from, "mayor/", "PROTOCOL_UNACKED "+p.Subject, body)
+	msg.Priority = mail.PriorityHigh
+	msg.Type = mail.TypeTask
+	_ = t.send(msg)
+	_ = events.EmitTo(t.townRoot, events.Event{
+		Type:       events.TypeProtocolUnacked,
+		Actor:      from,
+		Subject:    p.Subject,
+		Payload:    events.ProtocolUnackedPayload(string(p.Type), p.To, p.Subject, reason, p.Deliveries),
+		Visibility: events.VisibilityBoth,
+	})
+}
+
+// update applies fn to the ledger under a lock and saves it.
+func (t *AckTracker) update(fn func(pending map[string]*PendingAck)) error {
+	_, err := ackLedger(t.townRoot).Update(func(pending *map[string]*PendingAck) error {
+		if *pending == nil {
+			*pending = map[string]*PendingAck{}
+		}
+		fn(*pending)
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,145 @@
+package protocol
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+// newTestTracker returns a tracker that records sends instead of delivering
+// them, and whose recipients process nothing unless done is set.
+func newTestTracker(t *testing.T) (*AckTracker, *[]*mail.Message, *bool) {
+	t.Helper()
+	var sent []*mail.Message
+	done := false
+	tr := &AckTracker{
+		townRoot: t.TempDir(),
+		send: func(msg *mail.Message) error {
+			sent = append(sent, msg)
+			return nil
+		},
+		processed: func(*PendingAck) (bool, error) { return done, nil },
+	}
+	return tr, &sent, &done
+}
+
+func pendingAcks(t *testing.T, tr *AckTracker) map[string]*PendingAck {
+	t.Helper()
+	pending, err := LoadPendingAcks(tr.townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pending
+}
+
+func TestAckTrackerRetriesThenEscalates(t *testing.T) {
+	tr, sent, _ := newTestTracker(t)
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	msg := NewMergeFailedMessage("gastown", "nux", "polecat/nux/gt-abc", "gt-abc", "main", "tests", "Test failed")
+	if err := tr.Record(msg, now); err != nil {
+		t.Fatal(err)
+	}
+	if p := pendingAcks(t, tr)[msg.ThreadID]; p == nil || p.Deliveries != 1 || p.To != "gastown/witness" {
+		t.Fatalf("pending = %+v", p)
+	}
+
+	// Not yet due
+	if res, _ := tr.Check(now.Add(AckTimeout - time.Second)); res.Retried != 0 || res.Pending != 1 {
+		t.Fatalf("early check = %+v", res)
+	}
+
+	// First retry, then the second after a doubled timeout
+	now = now.Add(AckTimeout)
+	if res, _ := tr.Check(now); res.Retried != 1 {
+		t.Fatalf("first retry = %+v", res)
+	}
+	if len(*sent) != 1 || (*sent)[0].ThreadID != msg.ThreadID || !strings.Contains((*sent)[0].Body, "Redelivery: 2 of 3") {
+		t.Fatalf("retry = %+v", *sent)
+	}
+	if res, _ := tr.Check(now.Add(AckTimeout)); res.Retried != 0 {
+		t.Fatalf("retry did not back off: %+v", res)
+	}
+	now = now.Add(2 * AckTimeout)
+	if res, _ := tr.Check(now); res.Retried != 1 {
+		t.Fatalf("second retry = %+v", res)
+	}
+
+	// Delivered MaxDeliveries times: escalate to the Mayor
+	now = now.Add(4 * AckTimeout)
+	res, err := tr.Check(now)
+	if err != nil || res.Escalated != 1 || res.Pending != 0 {
+		t.Fatalf("escalation = %+v, %v", res, err)
+	}
+	last := (*sent)[len(*sent)-1]
+	if last.To != "mayor/" || last.Subject != "PROTOCOL_UNACKED MERGE_FAILED nux" || !strings.Contains(last.Body, "Deliveries: 3") {
+		t.Errorf("escalation mail = %+v", last)
+	}
+	if len(pendingAcks(t, tr)) != 0 {
+		t.Error("escalated message still pending")
+	}
+}
+
+func TestAckTrackerProcessedSettles(t *testing.T) {
+	tr, sent, done := newTestTracker(t)
+	now := time.Now()
+
+	msg := NewMergedMessage("gastown", "nux", "polecat/nux/gt-abc", "gt-abc", "main", "abc123")
+	if err := tr.Record(msg, now); err != nil {
+		t.Fatal(err)
+	}
+	*done = true
+	res, err := tr.Check(now.Add(AckTimeout))
+	if err != nil || res.Acked != 1 || len(*sent) != 0 {
+		t.Fatalf("check = %+v, %v (sent %d)", res, err, len(*sent))
+	}
+}
+
+func TestAckTrackerAckAndNack(t *testing.T) {
+	tr, sent, _ := newTestTracker(t)
+	now := time.Now()
+
+	merged := NewMergedMessage("gastown", "nux", "polecat/nux/gt-abc", "gt-abc", "main", "abc123")
+	failed := NewMergeFailedMessage("gastown", "ace", "polecat/ace/gt-def", "gt-def", "main", "build", "broken")
+	for _, m := range []*mail.Message{merged, failed} {
+		if err := tr.Record(m, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tr.Record(NewAckMessage(merged, "gastown/witness"), now); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Record(NewNackMessage(failed, "gastown/witness", "polecat ace is gone"), now); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(pendingAcks(t, tr)); n != 0 {
+		t.Errorf("pending after ACK/NACK = %d", n)
+	}
+	if len(*sent) != 1 || (*sent)[0].To != "mayor/" || !strings.Contains((*sent)[0].Body, "polecat ace is gone") {
+		t.Errorf("NACK escalation = %+v", *sent)
+	}
+}
+
+func TestAckTrackerIgnoresOtherMail(t *testing.T) {
+	tr, _, _ := newTestTracker(t)
+	if err := tr.Record(mail.NewMessage("mayor/", "gastown/witness", "Hello", "hi"), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(pendingAcks(t, tr)); n != 0 {
+		t.Errorf("pending = %d, want 0", n)
+	}
+}
+
+func TestParseAckReason(t *testing.T) {
+	orig := NewReworkRequestMessage("gastown", "nux", "polecat/nux/gt-abc", "gt-abc", "main", []string{"a.go"})
+	nack := NewNackMessage(orig, "gastown/polecats/nux", "worktree deleted")
+	if nack.To != orig.From || nack.ThreadID != orig.ThreadID || nack.Subject != "NACK "+orig.Subject {
+		t.Errorf("nack = %+v", nack)
+	}
+	if got := ParseAckReason(nack.Body); got != "worktree deleted" {
+		t.Errorf("ParseAckReason = %q", got)
+	}
+}
@@ -0,0 +1,42 @@
+package protocol
+
+import (
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+// LogMergeEvent records MERGED and MERGE_FAILED messages in the town events
+// log as merged and merge_failed events, with the failure type as reason.
+// The Refinery reports merge outcomes by mail, so this is where merge
+// throughput and failure metrics get their data. Other messages are ignored.
+func LogMergeEvent(msg *mail.Message) {
+	if e := MergeEvent(msg); e != nil {
+		_ = events.Emit(*e)
+	}
+}
+
+// MergeEvent returns the merged or merge_failed event envelope for a MERGED
+// or MERGE_FAILED message, or nil for other messages. Callers may add a
+// trace ID before emitting it.
+func MergeEvent(msg *mail.Message) *events.Event {
+	worker := ExtractPolecat(msg.Subject)
+	e := &events.Event{Actor: msg.From, Visibility: events.VisibilityFeed}
+	switch ParseMessageType(msg.Subject) {
+	case TypeMerged:
+		p := ParseMergedPayload(msg.Body)
+		e.Type = events.TypeMerged
+		e.Payload = events.MergePayload("", worker, p.Branch, "")
+	case TypeMergeFailed:
+		p := ParseMergeFailedPayload(msg.Body)
+		reason := p.FailureType
+		if reason == "" {
+			reason = "unknown"
+		}
+		e.Type = events.TypeMergeFailed
+		e.Payload = events.MergePayload("", worker, p.Branch, reason)
+	default:
+		return nil
+	}
+	e.Source = "refinery"
+	return e
+}
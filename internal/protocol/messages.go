@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -186,11 +187,140 @@ func formatRebaseInstructions(targetBranch string) string {
   git fetch origin
   git rebase origin/%s
   # Resolve any conflicts
-  git push -f
+  git push --force-with-lease
 
 The Refinery will retry the merge after rebase is complete.`, targetBranch, targetBranch)
 }
 
+// NewWorkClaimedMessage creates a WORK_CLAIMED protocol message.
+// Sent by a polecat to the Mayor when it claims an issue.
+func NewWorkClaimedMessage(rig, polecat, issue, branch string) *mail.Message {
+	payload := WorkClaimedPayload{
+		Issue:     issue,
+		Polecat:   polecat,
+		Rig:       rig,
+		Branch:    branch,
+		ClaimedAt: time.Now(),
+	}
+
+	body := formatWorkClaimedBody(payload)
+
+	msg := mail.NewMessage(
+		fmt.Sprintf("%s/%s", rig, polecat),
+		"mayor/",
+		fmt.Sprintf("WORK_CLAIMED %s", polecat),
+		body,
+	)
+	msg.Type = mail.TypeNotification
+
+	return msg
+}
+
+// formatWorkClaimedBody formats the body of a WORK_CLAIMED message.
+func formatWorkClaimedBody(p WorkClaimedPayload) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Issue: %s\n", p.Issue))
+	sb.WriteString(fmt.Sprintf("Polecat: %s\n", p.Polecat))
+	sb.WriteString(fmt.Sprintf("Rig: %s\n", p.Rig))
+	if p.Branch != "" {
+		sb.WriteString(fmt.Sprintf("Branch: %s\n", p.Branch))
+	}
+	sb.WriteString(fmt.Sprintf("Claimed-At: %s\n", p.ClaimedAt.Format(time.RFC3339)))
+	return sb.String()
+}
+
+// NewTestResultsMessage creates a TEST_RESULTS protocol message from a
+// filled-in payload (RanAt defaults to now).
+// Sent by Refinery to the Mayor after running tests on a branch.
+func NewTestResultsMessage(p TestResultsPayload) *mail.Message {
+	if p.RanAt.IsZero() {
+		p.RanAt = time.Now()
+	}
+
+	body := formatTestResultsBody(p)
+
+	msg := mail.NewMessage(
+		fmt.Sprintf("%s/refinery", p.Rig),
+		"mayor/",
+		fmt.Sprintf("TEST_RESULTS %s", p.Polecat),
+		body,
+	)
+	msg.Type = mail.TypeNotification
+	if !p.Success {
+		msg.Priority = mail.PriorityHigh
+	}
+
+	return msg
+}
+
+// formatTestResultsBody formats the body of a TEST_RESULTS message.
+func formatTestResultsBody(p TestResultsPayload) string {
+	result := "pass"
+	if !p.Success {
+		result = "fail"
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Branch: %s\n", p.Branch))
+	sb.WriteString(fmt.Sprintf("Issue: %s\n", p.Issue))
+	sb.WriteString(fmt.Sprintf("Polecat: %s\n", p.Polecat))
+	sb.WriteString(fmt.Sprintf("Rig: %s\n", p.Rig))
+	sb.WriteString(fmt.Sprintf("Result: %s\n", result))
+	sb.WriteString(fmt.Sprintf("Passed: %d\n", p.Passed))
+	sb.WriteString(fmt.Sprintf("Failed: %d\n", p.Failed))
+	sb.WriteString(fmt.Sprintf("Skipped: %d\n", p.Skipped))
+	sb.WriteString(fmt.Sprintf("Duration: %s\n", p.Duration))
+	if p.Command != "" {
+		sb.WriteString(fmt.Sprintf("Command: %s\n", p.Command))
+	}
+	sb.WriteString(fmt.Sprintf("Ran-At: %s\n", p.RanAt.Format(time.RFC3339)))
+	if len(p.Failures) > 0 {
+		sb.WriteString(fmt.Sprintf("Failures: %s\n", strings.Join(p.Failures, ", ")))
+	}
+	return sb.String()
+}
+
+// NewBudgetAlertMessage creates a BUDGET_ALERT protocol message.
+// Sent to the Mayor when spending for scope crosses threshold percent of
+// limit (both in USD) for the period.
+func NewBudgetAlertMessage(from, scope, period string, spent, limit float64, threshold int) *mail.Message {
+	payload := BudgetAlertPayload{
+		Scope:     scope,
+		Period:    period,
+		Spent:     spent,
+		Limit:     limit,
+		Threshold: threshold,
+		AlertedAt: time.Now(),
+	}
+
+	body := formatBudgetAlertBody(payload)
+
+	msg := mail.NewMessage(
+		from,
+		"mayor/",
+		fmt.Sprintf("BUDGET_ALERT %s", scope),
+		body,
+	)
+	msg.Type = mail.TypeNotification
+	msg.Priority = mail.PriorityHigh
+	if payload.Exceeded() {
+		msg.Priority = mail.PriorityUrgent
+	}
+
+	return msg
+}
+
+// formatBudgetAlertBody formats the body of a BUDGET_ALERT message.
+func formatBudgetAlertBody(p BudgetAlertPayload) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Scope: %s\n", p.Scope))
+	sb.WriteString(fmt.Sprintf("Period: %s\n", p.Period))
+	sb.WriteString(fmt.Sprintf("Spent: %.2f\n", p.Spent))
+	sb.WriteString(fmt.Sprintf("Limit: %.2f\n", p.Limit))
+	sb.WriteString(fmt.Sprintf("Threshold: %d%%\n", p.Threshold))
+	sb.WriteString(fmt.Sprintf("Alerted-At: %s\n", p.AlertedAt.Format(time.RFC3339)))
+	return sb.String()
+}
+
 // ParseMergeReadyPayload parses a MERGE_READY message body into a payload.
 func ParseMergeReadyPayload(body string) *MergeReadyPayload {
 	return &MergeReadyPayload{
@@ -271,6 +401,75 @@ func ParseReworkRequestPayload(body string) *ReworkRequestPayload {
 	return payload
 }
 
+// ParseWorkClaimedPayload parses a WORK_CLAIMED message body into a payload.
+func ParseWorkClaimedPayload(body string) *WorkClaimedPayload {
+	payload := &WorkClaimedPayload{
+		Issue:   parseField(body, "Issue"),
+		Polecat: parseField(body, "Polecat"),
+		Rig:     parseField(body, "Rig"),
+		Branch:  parseField(body, "Branch"),
+	}
+
+	// Parse timestamp
+	if ts := parseField(body, "Claimed-At"); ts != "" {
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			payload.ClaimedAt = t
+		}
+	}
+
+	return payload
+}
+
+// ParseTestResultsPayload parses a TEST_RESULTS message body into a payload.
+func ParseTestResultsPayload(body string) *TestResultsPayload {
+	payload := &TestResultsPayload{
+		Branch:  parseField(body, "Branch"),
+		Issue:   parseField(body, "Issue"),
+		Polecat: parseField(body, "Polecat"),
+		Rig:     parseField(body, "Rig"),
+		Command: parseField(body, "Command"),
+		Success: parseField(body, "Result") == "pass",
+	}
+	payload.Passed, _ = strconv.Atoi(parseField(body, "Passed"))
+	payload.Failed, _ = strconv.Atoi(parseField(body, "Failed"))
+	payload.Skipped, _ = strconv.Atoi(parseField(body, "Skipped"))
+	payload.Duration, _ = time.ParseDuration(parseField(body, "Duration"))
+
+	// Parse timestamp
+	if ts := parseField(body, "Ran-At"); ts != "" {
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			payload.RanAt = t
+		}
+	}
+
+	// Parse failing tests
+	if failures := parseField(body, "Failures"); failures != "" {
+		payload.Failures = strings.Split(failures, ", ")
+	}
+
+	return payload
+}
+
+// ParseBudgetAlertPayload parses a BUDGET_ALERT message body into a payload.
+func ParseBudgetAlertPayload(body string) *BudgetAlertPayload {
+	payload := &BudgetAlertPayload{
+		Scope:  parseField(body, "Scope"),
+		Period: parseField(body, "Period"),
+	}
+	payload.Spent, _ = strconv.ParseFloat(parseField(body, "Spent"), 64)
+	payload.Limit, _ = strconv.ParseFloat(parseField(body, "Limit"), 64)
+	payload.Threshold, _ = strconv.Atoi(strings.TrimSuffix(parseField(body, "Threshold"), "%"))
+
+	// Parse timestamp
+	if ts := parseField(body, "Alerted-At"); ts != "" {
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			payload.AlertedAt = t
+		}
+	}
+
+	return payload
+}
+
 // parseField extracts a field value from a key-value body format.
 // Format: "Key: value"
 func parseField(body, key string) string {
@@ -286,3 +485,40 @@ func parseField(body, key string) string {
 
 	return ""
 }
+
+// NewAckMessage creates an ACK for a processed protocol message. It is sent
+// back to the original sender in the same thread.
+func NewAckMessage(original *mail.Message, from string) *mail.Message {
+	msg := mail.NewMessage(from, original.From, fmt.Sprintf("ACK %s", strings.TrimSpace(original.Subject)), formatAckBody(original, ""))
+	msg.Type = mail.TypeReply
+	msg.ThreadID = original.ThreadID
+	msg.ReplyTo = original.ID
+	return msg
+}
+
+// NewNackMessage creates a NACK for a protocol message that could not be
+// processed. The sender's ack tracker escalates it to the Mayor.
+func NewNackMessage(original *mail.Message, from, reason string) *mail.Message {
+	msg := mail.NewMessage(from, original.From, fmt.Sprintf("NACK %s", strings.TrimSpace(original.Subject)), formatAckBody(original, reason))
+	msg.Type = mail.TypeReply
+	msg.Priority = mail.PriorityHigh
+	msg.ThreadID = original.ThreadID
+	msg.ReplyTo = original.ID
+	return msg
+}
+
+// formatAckBody formats the body of an ACK or NACK message.
+func formatAckBody(original *mail.Message, reason string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Message: %s\n", original.ID))
+	sb.WriteString(fmt.Sprintf("Thread: %s\n", original.ThreadID))
+	if reason != "" {
+		sb.WriteString(fmt.Sprintf("Reason: %s\n", reason))
+	}
+	return sb.String()
+}
+
+// ParseAckReason returns the reason given in a NACK message body.
+func ParseAckReason(body string) string {
+	return parseField(body, "Reason")
+}
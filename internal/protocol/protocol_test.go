@@ -22,6 +22,12 @@ func TestParseMessageType(t *testing.T) {
 		{"Unknown subject", ""},
 		{"", ""},
 		{"  MERGE_READY nux  ", TypeMergeReady}, // with whitespace
+		{"WORK_CLAIMED nux", TypeWorkClaimed},
+		{"TEST_RESULTS nux", TypeTestResults},
+		{"BUDGET_ALERT gastown", TypeBudgetAlert},
+		{"ACK MERGE_FAILED nux", TypeAck},
+		{"NACK MERGED nux", TypeNack},
+		{"ACKNOWLEDGED all of it", ""},
 	}
 
 	for _, tt := range tests {
@@ -376,3 +382,63 @@ func (m *mockRefineryHandler) HandleMergeReady(payload *MergeReadyPayload) error
 	m.readyCalled = true
 	return nil
 }
+
+func TestWorkClaimedRoundTrip(t *testing.T) {
+	msg := NewWorkClaimedMessage("gastown", "nux", "gt-abc", "polecat/nux/gt-abc")
+
+	if msg.Subject != "WORK_CLAIMED nux" || msg.From != "gastown/nux" || msg.To != "mayor/" {
+		t.Errorf("message = %s -> %s %q", msg.From, msg.To, msg.Subject)
+	}
+	p := ParseWorkClaimedPayload(msg.Body)
+	if p.Issue != "gt-abc" || p.Polecat != "nux" || p.Rig != "gastown" || p.Branch != "polecat/nux/gt-abc" || p.ClaimedAt.IsZero() {
+		t.Errorf("payload = %+v", p)
+	}
+}
+
+func TestTestResultsRoundTrip(t *testing.T) {
+	msg := NewTestResultsMessage(TestResultsPayload{
+		Branch:   "polecat/nux/gt-abc",
+		Issue:    "gt-abc",
+		Polecat:  "nux",
+		Rig:      "gastown",
+		Command:  "go test ./...",
+		Passed:   41,
+		Failed:   2,
+		Skipped:  1,
+		Duration: 90 * time.Second,
+		Failures: []string{"TestA", "TestB"},
+	})
+
+	if msg.Subject != "TEST_RESULTS nux" || msg.From != "gastown/refinery" || msg.Priority != mail.PriorityHigh {
+		t.Errorf("message = %s %q priority %s", msg.From, msg.Subject, msg.Priority)
+	}
+	p := ParseTestResultsPayload(msg.Body)
+	if p.Success || p.Passed != 41 || p.Failed != 2 || p.Skipped != 1 || p.Duration != 90*time.Second {
+		t.Errorf("payload = %+v", p)
+	}
+	if p.Command != "go test ./..." || len(p.Failures) != 2 || p.Failures[1] != "TestB" || p.RanAt.IsZero() {
+		t.Errorf("payload = %+v", p)
+	}
+
+	pass := NewTestResultsMessage(TestResultsPayload{Rig: "gastown", Polecat: "ace", Success: true})
+	if !ParseTestResultsPayload(pass.Body).Success || pass.Priority == mail.PriorityHigh {
+		t.Errorf("passing run = %+v", pass)
+	}
+}
+
+func TestBudgetAlertRoundTrip(t *testing.T) {
+	msg := NewBudgetAlertMessage("deacon/", "gastown", "daily", 82.5, 100, 80)
+
+	if msg.Subject != "BUDGET_ALERT gastown" || msg.To != "mayor/" || msg.Priority != mail.PriorityHigh {
+		t.Errorf("message = %s %q priority %s", msg.To, msg.Subject, msg.Priority)
+	}
+	p := ParseBudgetAlertPayload(msg.Body)
+	if p.Scope != "gastown" || p.Period != "daily" || p.Spent != 82.5 || p.Limit != 100 || p.Threshold != 80 || p.Exceeded() {
+		t.Errorf("payload = %+v", p)
+	}
+
+	over := NewBudgetAlertMessage("deacon/", "town", "monthly", 510, 500, 100)
+	if over.Priority != mail.PriorityUrgent || !ParseBudgetAlertPayload(over.Body).Exceeded() {
+		t.Errorf("exceeded budget = %+v", over)
+	}
+}
@@ -8,6 +8,12 @@
 //   - MERGED: Refinery → Witness (merge succeeded, cleanup ok)
 //   - MERGE_FAILED: Refinery → Witness (merge failed, needs rework)
 //   - REWORK_REQUEST: Refinery → Witness (rebase needed)
+//   - WORK_CLAIMED: Polecat → Mayor (polecat started work on an issue)
+//   - TEST_RESULTS: Refinery → Mayor (test run summary for a branch)
+//   - BUDGET_ALERT: any → Mayor (spending crossed a budget threshold)
+//   - ACK / NACK: recipient → sender (message processed / rejected)
+//
+// The merge messages must be acknowledged: see AckTracker.
 package protocol
 
 import (
@@ -38,6 +44,32 @@ const (
 	// branch needs rebasing due to conflicts with the target branch.
 	// Subject format: "REWORK_REQUEST <polecat-name>"
 	TypeReworkRequest MessageType = "REWORK_REQUEST"
+
+	// TypeWorkClaimed is sent from a polecat to the Mayor when it claims an
+	// issue and starts working on it.
+	// Subject format: "WORK_CLAIMED <polecat-name>"
+	TypeWorkClaimed MessageType = "WORK_CLAIMED"
+
+	// TypeTestResults is sent from Refinery to the Mayor with a summary of
+	// the test run for a branch in the merge queue.
+	// Subject format: "TEST_RESULTS <polecat-name>"
+	TypeTestResults MessageType = "TEST_RESULTS"
+
+	// TypeBudgetAlert is sent to the Mayor when spending for a scope (the
+	// town, a rig, or an agent) crosses a threshold of its budget.
+	// Subject format: "BUDGET_ALERT <scope>"
+	TypeBudgetAlert MessageType = "BUDGET_ALERT"
+
+	// TypeAck is sent back to the sender of a protocol message once it has
+	// been processed, in the same thread.
+	// Subject format: "ACK <original-subject>"
+	TypeAck MessageType = "ACK"
+
+	// TypeNack is sent back to the sender of a protocol message that could
+	// not be processed, with the reason in the body. The sender's ack
+	// tracker escalates it to the Mayor.
+	// Subject format: "NACK <original-subject>"
+	TypeNack MessageType = "NACK"
 )
 
 // ParseMessageType extracts the protocol message type from a mail subject.
@@ -45,12 +77,23 @@ const (
 func ParseMessageType(subject string) MessageType {
 	subject = strings.TrimSpace(subject)
 
+	// ACK and NACK are short words: require a separator, so that subjects
+	// like "ACKNOWLEDGED" are not mistaken for them
+	for _, t := range []MessageType{TypeAck, TypeNack} {
+		if subject == string(t) || strings.HasPrefix(subject, string(t)+" ") {
+			return t
+		}
+	}
+
 	// Check each known prefix
 	prefixes := []MessageType{
 		TypeMergeReady,
 		TypeMerged,
 		TypeMergeFailed,
 		TypeReworkRequest,
+		TypeWorkClaimed,
+		TypeTestResults,
+		TypeBudgetAlert,
 	}
 
 	for _, prefix := range prefixes {
@@ -165,6 +208,90 @@ type ReworkRequestPayload struct {
 	Instructions string `json:"instructions,omitempty"`
 }
 
+// WorkClaimedPayload contains the data for a WORK_CLAIMED message.
+// Sent by a polecat when it claims an issue.
+type WorkClaimedPayload struct {
+	// Issue is the beads issue ID claimed.
+	Issue string `json:"issue"`
+
+	// Polecat is the worker name.
+	Polecat string `json:"polecat"`
+
+	// Rig is the rig name.
+	Rig string `json:"rig"`
+
+	// Branch is the polecat's work branch, if already created.
+	Branch string `json:"branch,omitempty"`
+
+	// ClaimedAt is when the work was claimed.
+	ClaimedAt time.Time `json:"claimed_at"`
+}
+
+// TestResultsPayload contains the data for a TEST_RESULTS message.
+// Sent by Refinery after running tests on a branch.
+type TestResultsPayload struct {
+	// Branch is the branch that was tested.
+	Branch string `json:"branch"`
+
+	// Issue is the beads issue ID.
+	Issue string `json:"issue"`
+
+	// Polecat is the worker name.
+	Polecat string `json:"polecat"`
+
+	// Rig is the rig name.
+	Rig string `json:"rig"`
+
+	// Command is the test command that was run.
+	Command string `json:"command,omitempty"`
+
+	// Passed, Failed, and Skipped count test cases (zero if unknown).
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+
+	// Success is whether the test command succeeded.
+	Success bool `json:"success"`
+
+	// Duration is how long the test run took.
+	Duration time.Duration `json:"duration"`
+
+	// Failures names the failing tests (if known).
+	Failures []string `json:"failures,omitempty"`
+
+	// RanAt is when the tests finished.
+	RanAt time.Time `json:"ran_at"`
+}
+
+// BudgetAlertPayload contains the data for a BUDGET_ALERT message.
+// Sent when spending for a scope crosses a threshold of its budget.
+type BudgetAlertPayload struct {
+	// Scope is what the budget covers: "town", a rig name, or an agent
+	// address (e.g., "gastown/nux").
+	Scope string `json:"scope"`
+
+	// Period is the budget period (e.g., "daily", "monthly").
+	Period string `json:"period"`
+
+	// Spent is the spending so far in the period, in USD.
+	Spent float64 `json:"spent"`
+
+	// Limit is the budget for the period, in USD.
+	Limit float64 `json:"limit"`
+
+	// Threshold is the percentage of the budget that was crossed
+	// (e.g., 80 or 100).
+	Threshold int `json:"threshold"`
+
+	// AlertedAt is when the threshold was crossed.
+	AlertedAt time.Time `json:"alerted_at"`
+}
+
+// Exceeded reports whether the budget is used up.
+func (p *BudgetAlertPayload) Exceeded() bool {
+	return p.Limit > 0 && p.Spent >= p.Limit
+}
+
 // IsProtocolMessage returns true if the subject matches a known protocol type.
 func IsProtocolMessage(subject string) bool {
 	return ParseMessageType(subject) != ""
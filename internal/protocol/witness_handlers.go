@@ -198,7 +198,7 @@ Please rebase your changes:
   git fetch origin
   git rebase origin/%s
   # Resolve any conflicts
-  git push -f
+  git push --force-with-lease
 
 Then run 'gt done' to resubmit for merge.`,
 			payload.TargetBranch,
@@ -0,0 +1,147 @@
+package ratelimit
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// gitOptionsWithValue are git's global options that take a separate argument.
+var gitOptionsWithValue = map[string]bool{
+	"-C": true, "-c": true, "--git-dir": true, "--work-tree": true,
+	"--namespace": true, "--exec-path": true, "--config-env": true,
+}
+
+// GitInvocation is one git command found in a shell command line.
+type GitInvocation struct {
+	Subcommand string   // e.g. "push"
+	Args       []string // arguments after the subcommand
+	Text       string   // the invocation as written
+}
+
+// ParseGit finds the git invocations in a shell command line, as an agent
+// would pass it to its shell tool: several commands may be chained with
+// &&, ||, ;, | or newlines, and each may be prefixed with environment
+// assignments. Quoting is handled only as far as stripping quotes from
+// words; the aim is to recognise git commands, not to parse shell.
+func ParseGit(command string) []GitInvocation {
+	var found []GitInvocation
+	for _, segment := range splitCommands(command) {
+		words := strings.Fields(segment)
+		for i := range words {
+			words[i] = strings.Trim(words[i], `"'`)
+		}
+		// Skip environment assignments and simple wrappers.
+		for len(words) > 0 && (isAssignment(words[0]) || words[0] == "command" || words[0] == "exec" || words[0] == "sudo") {
+			words = words[1:]
+		}
+		if len(words) == 0 || filepath.Base(words[0]) != "git" {
+			continue
+		}
+		words = words[1:]
+		for len(words) > 0 && strings.HasPrefix(words[0], "-") {
+			if gitOptionsWithValue[words[0]] && len(words) > 1 {
+				words = words[1:]
+			}
+			words = words[1:]
+		}
+		inv := GitInvocation{Text: strings.TrimSpace(segment)}
+		if len(words) > 0 {
+			inv.Subcommand = words[0]
+			inv.Args = words[1:]
+		}
+		found = append(found, inv)
+	}
+	return found
+}
+
+// Classify returns the kinds of destructive git operations in a shell
+// command line, one entry per destructive invocation:
+//   - force-push: git push --force / -f / --force-with-lease / +refspec
+//   - remote-delete: git push --delete / -d / :refspec
+//   - branch-delete: git branch -D, or -d with --force
+func Classify(command string) []string {
+	var kinds []string
+	for _, inv := range ParseGit(command) {
+		if kind := inv.Kind(); kind != "" {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// Kind returns the destructive kind of a git invocation, or "" if it is
+// not destructive.
+func (inv GitInvocation) Kind() string {
+	switch inv.Subcommand {
+	case "push":
+		force, del := false, false
+		for _, arg := range inv.Args {
+			switch {
+			case arg == "--force" || strings.HasPrefix(arg, "--force-with-lease") || arg == "--mirror":
+				force = true
+			case arg == "--delete" || arg == "--prune":
+				del = true
+			case isShortFlags(arg):
+				force = force || strings.Contains(arg, "f")
+				del = del || strings.Contains(arg, "d")
+			case strings.HasPrefix(arg, "+"):
+				force = true
+			case strings.HasPrefix(arg, ":") && len(arg) > 1:
+				del = true
+			}
+		}
+		if del {
+			return config.RateLimitKindRemoteDelete
+		}
+		if force {
+			return config.RateLimitKindForcePush
+		}
+	case "branch":
+		force, del := false, false
+		for _, arg := range inv.Args {
+			switch {
+			case arg == "--force":
+				force = true
+			case arg == "--delete":
+				del = true
+			case isShortFlags(arg):
+				if strings.Contains(arg, "D") {
+					return config.RateLimitKindBranchDelete
+				}
+				force = force || strings.Contains(arg, "f")
+				del = del || strings.Contains(arg, "d")
+			}
+		}
+		if del && force {
+			return config.RateLimitKindBranchDelete
+		}
+	}
+	return ""
+}
+
+// splitCommands splits a command line on shell command separators.
+func splitCommands(command string) []string {
+	replacer := strings.NewReplacer("&&", "\n", "||", "\n", ";", "\n", "|", "\n")
+	return strings.Split(replacer.Replace(command), "\n")
+}
+
+// isAssignment reports whether word is an environment assignment (FOO=bar).
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	if !ok || name == "" || strings.HasPrefix(name, "-") {
+		return false
+	}
+	for _, r := range name {
+		if r != '_' && (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// isShortFlags reports whether arg is a cluster of short flags like -fu.
+func isShortFlags(arg string) bool {
+	return len(arg) > 1 && arg[0] == '-' && arg[1] != '-'
+}
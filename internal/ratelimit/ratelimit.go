@@ -0,0 +1,224 @@
+// Package ratelimit contains runaway agents by rate limiting the destructive
+// git commands they issue: force pushes and branch deletions.
+//
+// Each agent has a sliding window of recent destructive commands. When the
+// count exceeds the configured limit, the agent's circuit breaker trips: from
+// then on every git command it issues is blocked until an overseer resets the
+// breaker. Agents' shell commands reach the limiter through a PreToolUse hook
+// (gt ratelimit check), so a tripped agent is stopped before the command runs.
+//
+// State lives in <town>/.runtime/ratelimit/, one file per agent.
+package ratelimit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/runstate"
+)
+
+// Event is one destructive command an agent issued.
+type Event struct {
+	At      time.Time `json:"at"`
+	Kind    string    `json:"kind"`
+	Command string    `json:"command"`
+}
+
+// State is an agent's rate limit state.
+type State struct {
+	Agent  string  `json:"agent"`
+	Recent []Event `json:"recent,omitempty"` // destructive commands in the window
+
+	// Set when the breaker trips; cleared by Reset.
+	Tripped    bool      `json:"tripped,omitempty"`
+	TrippedAt  time.Time `json:"tripped_at,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	Escalation string    `json:"escalation,omitempty"` // escalation bead ID
+	Blocked    int       `json:"blocked,omitempty"`    // commands blocked since tripping
+}
+
+// Decision is the limiter's verdict on one command.
+type Decision struct {
+	Kinds   []string // destructive operations in the command
+	Count   int      // destructive commands in the window, including this one
+	Block   bool     // the command must not run
+	Tripped bool     // this command tripped the breaker
+	State   *State
+}
+
+// Limiter applies a RateLimitConfig to agents' commands.
+type Limiter struct {
+	store *runstate.Store
+	cfg   *config.RateLimitConfig
+	now   func() time.Time
+}
+
+// New creates a Limiter for a town.
+func New(townRoot string, cfg *config.RateLimitConfig) *Limiter {
+	return &Limiter{
+		store: runstate.Open(townRoot),
+		cfg:   cfg,
+		now:   time.Now,
+	}
+}
+
+// Check records a command issued by agent and decides whether it may run.
+// Commands without git in them are always allowed and not recorded.
+func (l *Limiter) Check(agent, command string) (*Decision, error) {
+	invocations := ParseGit(command)
+	if len(invocations) == 0 || l.cfg.Mode == config.GateModeOff {
+		return &Decision{}, nil
+	}
+	block := l.cfg.Mode != config.GateActionWarn
+
+	// Ordinary git commands only matter once the breaker has tripped;
+	// don't take the lock and rewrite state for each of them.
+	counted := false
+	for _, inv := range invocations {
+		if kind := inv.Kind(); kind != "" && l.cfg.Counts(kind) {
+			counted = true
+		}
+	}
+	if !counted {
+		s, err := l.Get(agent)
+		if err != nil {
+			return nil, err
+		}
+		if !s.Tripped {
+			return &Decision{State: s}, nil
+		}
+	}
+
+	var d *Decision
+	err := l.update(agent, func(s *State) {
+		now := l.now()
+		d = &Decision{State: s}
+
+		if s.Tripped {
+			d.Block = block
+			if block {
+				s.Blocked++
+			}
+			return
+		}
+
+		cutoff := now.Add(-l.cfg.GetWindow())
+		recent := s.Recent[:0]
+		for _, e := range s.Recent {
+			if e.At.After(cutoff) {
+				recent = append(recent, e)
+			}
+		}
+		s.Recent = recent
+
+		for _, inv := range invocations {
+			kind := inv.Kind()
+			if kind == "" || !l.cfg.Counts(kind) {
+				continue
+			}
+			d.Kinds = append(d.Kinds, kind)
+			s.Recent = append(s.Recent, Event{At: now, Kind: kind, Command: inv.Text})
+		}
+		d.Count = len(s.Recent)
+
+		if len(d.Kinds) > 0 && d.Count > l.cfg.GetLimit() {
+			s.Tripped = true
+			s.TrippedAt = now
+			s.Reason = fmt.Sprintf("%d destructive git commands within %s (limit %d)",
+				d.Count, l.cfg.GetWindow(), l.cfg.GetLimit())
+			d.Tripped = true
+			d.Block = block
+			if block {
+				s.Blocked++
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// SetEscalation records the escalation filed for a tripped breaker.
+func (l *Limiter) SetEscalation(agent, beadID string) error {
+	return l.update(agent, func(s *State) {
+		s.Escalation = beadID
+	})
+}
+
+// Reset closes an agent's breaker and forgets its recent commands.
+func (l *Limiter) Reset(agent string) error {
+	if err := l.doc(agent).Delete(); err != nil {
+		return fmt.Errorf("resetting %s: %w", agent, err)
+	}
+	return nil
+}
+
+// Get returns an agent's state; an agent with no state gets an empty one.
+func (l *Limiter) Get(agent string) (*State, error) {
+	s, err := l.doc(agent).Load()
+	if err != nil {
+		return nil, fmt.Errorf("rate limit state for %s: %w", agent, err)
+	}
+	return s, nil
+}
+
+// List returns the state of every agent the limiter has seen, tripped
+// breakers first.
+func (l *Limiter) List() ([]*State, error) {
+	dir := l.store.Path(stateDir)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var states []*State
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var s State
+		if json.Unmarshal(data, &s) == nil && s.Agent != "" {
+			states = append(states, &s)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Tripped != states[j].Tripped {
+			return states[i].Tripped
+		}
+		return states[i].Agent < states[j].Agent
+	})
+	return states, nil
+}
+
+// update applies fn to an agent's state under a lock and saves it.
+func (l *Limiter) update(agent string, fn func(s *State)) error {
+	_, err := l.doc(agent).Update(func(s *State) error {
+		fn(s)
+		return nil
+	})
+	return err
+}
+
+// stateDir is the directory, in the town's runtime state store, holding
+// one document per agent.
+const stateDir = "ratelimit"
+
+// doc returns an agent's state document.
+func (l *Limiter) doc(agent string) *runstate.Doc[State] {
+	name := stateDir + "/" + strings.ReplaceAll(agent, "/", "--") + ".json"
+	return runstate.NewDoc(l.store, name, func() *State { return &State{Agent: agent} })
+}
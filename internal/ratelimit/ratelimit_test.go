@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"git status", nil},
+		{"git push origin polecat/nux", nil},
+		{"git push -u origin polecat/nux", nil},
+		{"git push --force origin main", []string{config.RateLimitKindForcePush}},
+		{"git push -f", []string{config.RateLimitKindForcePush}},
+		{"git push -uf origin HEAD", []string{config.RateLimitKindForcePush}},
+		{"git push --force-with-lease=main:abc origin main", []string{config.RateLimitKindForcePush}},
+		{"git push origin +HEAD:main", []string{config.RateLimitKindForcePush}},
+		{"git push origin --delete old", []string{config.RateLimitKindRemoteDelete}},
+		{"git push origin :old", []string{config.RateLimitKindRemoteDelete}},
+		{"git branch -d merged", nil},
+		{"git branch -D feature", []string{config.RateLimitKindBranchDelete}},
+		{"git branch --delete --force feature", []string{config.RateLimitKindBranchDelete}},
+		{"git branch -df feature", []string{config.RateLimitKindBranchDelete}},
+		{"git -C /tmp/repo -c core.pager=cat push -f", []string{config.RateLimitKindForcePush}},
+		{"GIT_TRACE=1 /usr/bin/git push --force", []string{config.RateLimitKindForcePush}},
+		{"cd repo && git branch -D a; git branch -D b", []string{config.RateLimitKindBranchDelete, config.RateLimitKindBranchDelete}},
+		{"echo 'git push --force'", nil},
+		{"gt done", nil},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.command); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Classify(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestLimiterTrips(t *testing.T) {
+	cfg := config.NewRateLimitConfig()
+	cfg.Limit = 2
+	cfg.Window = "10m"
+	l := New(t.TempDir(), cfg)
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	agent := "gastown/polecats/nux"
+	check := func(command string) *Decision {
+		t.Helper()
+		d, err := l.Check(agent, command)
+		if err != nil {
+			t.Fatalf("Check(%q): %v", command, err)
+		}
+		return d
+	}
+
+	if d := check("git push --force"); d.Block || d.Count != 1 {
+		t.Fatalf("first force push: %+v", d)
+	}
+	// Outside the window, the first push no longer counts.
+	now = now.Add(11 * time.Minute)
+	if d := check("git branch -D a"); d.Block || d.Count != 1 {
+		t.Fatalf("after window: %+v", d)
+	}
+	if d := check("git status"); d.Block {
+		t.Fatalf("ordinary git blocked before tripping: %+v", d)
+	}
+	if d := check("git branch -D b"); d.Block || d.Count != 2 {
+		t.Fatalf("at limit: %+v", d)
+	}
+	d := check("git branch -D c")
+	if !d.Block || !d.Tripped || d.Count != 3 {
+		t.Fatalf("over limit should trip: %+v", d)
+	}
+
+	// Once tripped, every git command is blocked, but not other commands.
+	if d := check("git status"); !d.Block || d.Tripped {
+		t.Errorf("git after tripping: %+v", d)
+	}
+	if d := check("go test ./..."); d.Block {
+		t.Errorf("non-git command blocked: %+v", d)
+	}
+
+	if err := l.SetEscalation(agent, "hq-esc-1"); err != nil {
+		t.Fatal(err)
+	}
+	states, err := l.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 1 || !states[0].Tripped || states[0].Escalation != "hq-esc-1" || states[0].Blocked != 2 {
+		t.Errorf("List() = %+v", states)
+	}
+
+	if err := l.Reset(agent); err != nil {
+		t.Fatal(err)
+	}
+	if d := check("git push --force"); d.Block || d.Count != 1 {
+		t.Errorf("after reset: %+v", d)
+	}
+}
+
+func TestLimiterWarnMode(t *testing.T) {
+	cfg := config.NewRateLimitConfig()
+	cfg.Mode = config.GateActionWarn
+	cfg.Limit = 1
+	l := New(t.TempDir(), cfg)
+
+	if _, err := l.Check("mayor", "git push -f"); err != nil {
+		t.Fatal(err)
+	}
+	d, err := l.Check("mayor", "git push -f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Tripped || d.Block {
+		t.Errorf("warn mode should trip without blocking: %+v", d)
+	}
+}
@@ -0,0 +1,37 @@
+package refinery
+
+import (
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/auditchain"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// AuditedTarget reports whether merges into target are recorded in the
+// town's audit chain: the rig's default branch and any branch under one of
+// its protected prefixes.
+func AuditedTarget(rigPath, target string) bool {
+	if target == (&rig.Rig{Path: rigPath}).DefaultBranch() {
+		return true
+	}
+	policy, err := rig.LoadBranchPolicy(rigPath)
+	return err == nil && policy.Protected(target)
+}
+
+// RecordMerge records a merge into target in the town's audit chain if
+// target is audited (see AuditedTarget). An empty target means the rig's
+// default branch.
+func RecordMerge(townRoot, rigName, actor, target string, details map[string]string) error {
+	rigPath := filepath.Join(townRoot, rigName)
+	if target == "" {
+		target = (&rig.Rig{Path: rigPath}).DefaultBranch()
+	}
+	if !AuditedTarget(rigPath, target) {
+		return nil
+	}
+	if details == nil {
+		details = make(map[string]string)
+	}
+	details["rig"] = rigName
+	return auditchain.Record(townRoot, auditchain.ActionMerge, actor, target, details)
+}
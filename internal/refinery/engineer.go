@@ -5,19 +5,28 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/changelog"
+	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/github"
+	"github.com/steveyegge/gastown/internal/keepalive"
+	"github.com/steveyegge/gastown/internal/lease"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/protocol"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/tracing"
+	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 // MergeQueueConfig holds configuration for the merge queue processor.
@@ -51,6 +60,58 @@ type MergeQueueConfig struct {
 
 	// MaxConcurrent is the maximum number of MRs to process concurrently.
 	MaxConcurrent int `json:"max_concurrent"`
+
+	// WorktreePool runs each merge and test run in its own worktree from a
+	// pool of MaxConcurrent checkouts instead of the refinery/rig checkout.
+	WorktreePool bool `json:"worktree_pool"`
+
+	// TestSelection picks the tests to run by the files an MR changes: the
+	// commands of the rules whose paths it touches run instead of
+	// TestCommand. TestCommand still runs when any changed file matches no
+	// rule, or when the changed files can't be determined.
+	TestSelection []TestRule `json:"test_selection,omitempty"`
+}
+
+// TestRule runs Command for MRs that change files matching any of Paths
+// (see git.MatchPath, e.g. "web/**" or "*.proto").
+type TestRule struct {
+	Paths   []string `json:"paths"`
+	Command string   `json:"command"`
+}
+
+// HasTests reports whether any test command is configured.
+func (c *MergeQueueConfig) HasTests() bool {
+	return c.TestCommand != "" || len(c.TestSelection) > 0
+}
+
+// TestCommands returns the test commands for an MR changing the files in
+// changes (nil if unknown), in configuration order, without duplicates.
+// TestCommand comes last, for files no rule covers.
+func (c *MergeQueueConfig) TestCommands(changes *git.ChangeSet) []string {
+	var commands []string
+	seen := make(map[string]bool)
+	uncovered := true
+	if changes != nil {
+		covered := make(map[string]bool)
+		for _, rule := range c.TestSelection {
+			if rule.Command == "" {
+				continue
+			}
+			matched := changes.Match(rule.Paths...)
+			for _, f := range matched {
+				covered[f] = true
+			}
+			if len(matched) > 0 && !seen[rule.Command] {
+				seen[rule.Command] = true
+				commands = append(commands, rule.Command)
+			}
+		}
+		uncovered = len(commands) == 0 || len(covered) < len(changes.Files)
+	}
+	if uncovered && c.TestCommand != "" && !seen[c.TestCommand] {
+		commands = append(commands, c.TestCommand)
+	}
+	return commands
 }
 
 // DefaultMergeQueueConfig returns sensible defaults for merge queue configuration.
@@ -69,6 +130,15 @@ func DefaultMergeQueueConfig() *MergeQueueConfig {
 	}
 }
 
+// Concurrency returns how many MRs are processed at once: MaxConcurrent
+// with the worktree pool, one otherwise.
+func (c *MergeQueueConfig) Concurrency() int {
+	if c.WorktreePool && c.MaxConcurrent > 1 {
+		return c.MaxConcurrent
+	}
+	return 1
+}
+
 // MRInfo holds merge request information for display and processing.
 // This replaces mrqueue.MR after the mrqueue package removal.
 type MRInfo struct {
@@ -86,6 +156,7 @@ type MRInfo struct {
 	ConvoyCreatedAt *time.Time // Convoy creation time
 	CreatedAt       time.Time  // MR creation time
 	BlockedBy       string     // Task ID blocking this MR
+	Commits         []string   // Commits to cherry-pick, for a partial MR
 }
 
 // Engineer is the merge queue processor that polls for ready merge-requests
@@ -98,11 +169,31 @@ type Engineer struct {
 	workDir string
 	output  io.Writer    // Output destination for user-facing messages
 	router  *mail.Router // Mail router for sending protocol messages
+	leases  *lease.Store // Town leases, for the rig's merge slot
 
 	// stopCh is used for graceful shutdown
 	stopCh chan struct{}
+
+	// pool is the worktree pool used when config.WorktreePool is set (lazily created)
+	pool   *WorktreePool
+	poolMu sync.Mutex
+
+	// pushMu serializes the check-and-push step of pooled merges
+	pushMu sync.Mutex
+
+	// github posts commit statuses to githubRepo (nil if not configured)
+	github     *github.Client
+	githubRepo string
+
+	// tracer exports merge pipeline spans over OTLP (nil if not configured)
+	tracer *tracing.Tracer
 }
 
+// MergeSlotTTL is how long the Refinery holds a rig's merge slot for a
+// conflict resolution. A resolution abandoned for longer lets the next one
+// through.
+const MergeSlotTTL = 4 * time.Hour
+
 // NewEngineer creates a new Engineer for the given rig.
 func NewEngineer(r *rig.Rig) *Engineer {
 	cfg := DefaultMergeQueueConfig()
@@ -117,16 +208,20 @@ func NewEngineer(r *rig.Rig) *Engineer {
 		gitDir = filepath.Join(r.Path, "mayor", "rig")
 	}
 
-	return &Engineer{
+	e := &Engineer{
 		rig:     r,
 		beads:   beads.New(r.Path),
-		git:     git.NewGit(gitDir),
+		git:     rig.WithGitAuth(r.Path, git.NewGit(gitDir)),
 		config:  cfg,
 		workDir: gitDir,
 		output:  os.Stdout,
 		router:  mail.NewRouter(r.Path),
+		leases:  lease.NewStore(filepath.Dir(r.Path)),
 		stopCh:  make(chan struct{}),
 	}
+	e.github, e.githubRepo = newStatusClient(r)
+	e.tracer = newTracer(r, e)
+	return e
 }
 
 // SetOutput sets the output writer for user-facing messages.
@@ -160,59 +255,73 @@ func (e *Engineer) LoadConfig() error {
 		return nil
 	}
 
+	return ApplyMergeQueueConfig(e.config, rawConfig.MergeQueue)
+}
+
+// ApplyMergeQueueConfig applies a merge_queue config section (as found in a
+// rig's config.json) to cfg. Fields missing from raw keep their values.
+func ApplyMergeQueueConfig(cfg *MergeQueueConfig, raw json.RawMessage) error {
 	// Parse merge_queue section into our config struct
 	// We need special handling for poll_interval (string -> Duration)
 	var mqRaw struct {
-		Enabled              *bool   `json:"enabled"`
-		TargetBranch         *string `json:"target_branch"`
-		IntegrationBranches  *bool   `json:"integration_branches"`
-		OnConflict           *string `json:"on_conflict"`
-		RunTests             *bool   `json:"run_tests"`
-		TestCommand          *string `json:"test_command"`
-		DeleteMergedBranches *bool   `json:"delete_merged_branches"`
-		RetryFlakyTests      *int    `json:"retry_flaky_tests"`
-		PollInterval         *string `json:"poll_interval"`
-		MaxConcurrent        *int    `json:"max_concurrent"`
-	}
-
-	if err := json.Unmarshal(rawConfig.MergeQueue, &mqRaw); err != nil {
+		Enabled              *bool      `json:"enabled"`
+		TargetBranch         *string    `json:"target_branch"`
+		IntegrationBranches  *bool      `json:"integration_branches"`
+		OnConflict           *string    `json:"on_conflict"`
+		RunTests             *bool      `json:"run_tests"`
+		TestCommand          *string    `json:"test_command"`
+		DeleteMergedBranches *bool      `json:"delete_merged_branches"`
+		RetryFlakyTests      *int       `json:"retry_flaky_tests"`
+		PollInterval         *string    `json:"poll_interval"`
+		MaxConcurrent        *int       `json:"max_concurrent"`
+		WorktreePool         *bool      `json:"worktree_pool"`
+		TestSelection        []TestRule `json:"test_selection"`
+	}
+
+	if err := json.Unmarshal(raw, &mqRaw); err != nil {
 		return fmt.Errorf("parsing merge_queue config: %w", err)
 	}
 
 	// Apply non-nil values to config (preserving defaults for missing fields)
 	if mqRaw.Enabled != nil {
-		e.config.Enabled = *mqRaw.Enabled
+		cfg.Enabled = *mqRaw.Enabled
 	}
 	if mqRaw.TargetBranch != nil {
-		e.config.TargetBranch = *mqRaw.TargetBranch
+		cfg.TargetBranch = *mqRaw.TargetBranch
 	}
 	if mqRaw.IntegrationBranches != nil {
-		e.config.IntegrationBranches = *mqRaw.IntegrationBranches
+		cfg.IntegrationBranches = *mqRaw.IntegrationBranches
 	}
 	if mqRaw.OnConflict != nil {
-		e.config.OnConflict = *mqRaw.OnConflict
+		cfg.OnConflict = *mqRaw.OnConflict
 	}
 	if mqRaw.RunTests != nil {
-		e.config.RunTests = *mqRaw.RunTests
+		cfg.RunTests = *mqRaw.RunTests
 	}
 	if mqRaw.TestCommand != nil {
-		e.config.TestCommand = *mqRaw.TestCommand
+		cfg.TestCommand = *mqRaw.TestCommand
 	}
 	if mqRaw.DeleteMergedBranches != nil {
-		e.config.DeleteMergedBranches = *mqRaw.DeleteMergedBranches
+		cfg.DeleteMergedBranches = *mqRaw.DeleteMergedBranches
 	}
 	if mqRaw.RetryFlakyTests != nil {
-		e.config.RetryFlakyTests = *mqRaw.RetryFlakyTests
+		cfg.RetryFlakyTests = *mqRaw.RetryFlakyTests
 	}
 	if mqRaw.MaxConcurrent != nil {
-		e.config.MaxConcurrent = *mqRaw.MaxConcurrent
+		cfg.MaxConcurrent = *mqRaw.MaxConcurrent
+	}
+	if mqRaw.WorktreePool != nil {
+		cfg.WorktreePool = *mqRaw.WorktreePool
+	}
+	if mqRaw.TestSelection != nil {
+		cfg.TestSelection = mqRaw.TestSelection
 	}
 	if mqRaw.PollInterval != nil {
 		dur, err := time.ParseDuration(*mqRaw.PollInterval)
 		if err != nil {
 			return fmt.Errorf("invalid poll_interval %q: %w", *mqRaw.PollInterval, err)
 		}
-		e.config.PollInterval = dur
+		cfg.PollInterval = dur
 	}
 
 	return nil
@@ -230,6 +339,8 @@ type ProcessResult struct {
 	Error       string
 	Conflict    bool
 	TestsFailed bool
+	Requeue     bool   // Target kept moving; retry later, the MR is not at fault
+	TraceID     string // Trace of the merge, when tracing is on
 }
 
 // ProcessMR processes a single merge request from a beads issue.
@@ -248,13 +359,22 @@ func (e *Engineer) ProcessMR(ctx context.Context, mr *beads.Issue) ProcessResult
 	_, _ = fmt.Fprintf(e.output, "  Branch: %s\n", mrFields.Branch)
 	_, _ = fmt.Fprintf(e.output, "  Target: %s\n", mrFields.Target)
 	_, _ = fmt.Fprintf(e.output, "  Worker: %s\n", mrFields.Worker)
+	if mrFields.IsPartial() {
+		_, _ = fmt.Fprintf(e.output, "  Partial: %d commit(s)\n", len(mrFields.Commits))
+	}
 
-	return e.doMerge(ctx, mrFields.Branch, mrFields.Target, mrFields.SourceIssue)
+	return e.mergeWithStatus(ctx, mrFields.Branch, mrFields.Target, mrFields.SourceIssue, mrFields.Commits)
 }
 
 // doMerge performs the actual git merge operation.
 // This is the core merge logic shared by ProcessMR and ProcessMRFromQueue.
-func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue string) ProcessResult {
+// With commits (a partial MR), only those commits are cherry-picked onto
+// target instead of merging the whole branch.
+func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue string, commits []string) ProcessResult {
+	if e.config.WorktreePool {
+		return e.doMergePooled(ctx, branch, target, sourceIssue, commits)
+	}
+
 	// Step 1: Verify source branch exists locally (shared .repo.git with polecats)
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Checking local branch %s...\n", branch)
 	exists, err := e.git.BranchExists(branch)
@@ -272,8 +392,11 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 	}
 
 	// Step 2: Checkout the target branch
+	_, span := e.tracer.Start(ctx, "fetch")
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Checking out target branch %s...\n", target)
 	if err := e.git.Checkout(target); err != nil {
+		span.SetError(err.Error())
+		span.End()
 		return ProcessResult{
 			Success: false,
 			Error:   fmt.Sprintf("failed to checkout target %s: %v", target, err),
@@ -285,29 +408,39 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		// Pull might fail if nothing to pull, that's ok
 		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: pull from origin/%s: %v (continuing)\n", target, err)
 	}
-
-	// Step 3: Check for merge conflicts (using local branch)
-	_, _ = fmt.Fprintf(e.output, "[Engineer] Checking for conflicts...\n")
-	conflicts, err := e.git.CheckConflicts(branch, target)
-	if err != nil {
-		return ProcessResult{
-			Success:  false,
-			Conflict: true,
-			Error:    fmt.Sprintf("conflict check failed: %v", err),
+	span.End()
+
+	// Step 3: Check for merge conflicts (using local branch). A partial
+	// MR's cherry-pick doubles as its conflict check.
+	if len(commits) == 0 {
+		_, span = e.tracer.Start(ctx, "conflict_check")
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Checking for conflicts...\n")
+		conflicts, err := e.git.CheckConflicts(branch, target)
+		span.SetAttribute("conflicts", len(conflicts))
+		span.End()
+		if err != nil {
+			return ProcessResult{
+				Success:  false,
+				Conflict: true,
+				Error:    fmt.Sprintf("conflict check failed: %v", err),
+			}
 		}
-	}
-	if len(conflicts) > 0 {
-		return ProcessResult{
-			Success:  false,
-			Conflict: true,
-			Error:    fmt.Sprintf("merge conflicts in: %v", conflicts),
+		if len(conflicts) > 0 {
+			return ProcessResult{
+				Success:  false,
+				Conflict: true,
+				Error:    fmt.Sprintf("merge conflicts in: %v", conflicts),
+			}
 		}
 	}
 
 	// Step 4: Run tests if configured
-	if e.config.RunTests && e.config.TestCommand != "" {
-		_, _ = fmt.Fprintf(e.output, "[Engineer] Running tests: %s\n", e.config.TestCommand)
-		result := e.runTests(ctx)
+	if e.config.RunTests && e.config.HasTests() {
+		commands := e.testCommands(e.git, target, branch)
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Running tests: %s\n", strings.Join(commands, "; "))
+		e.postStatus(branch, StatusContextTests, github.StatePending, "Running tests")
+		result := e.runTests(ctx, e.workDir, commands)
+		e.reportTests(branch, result)
 		if !result.Success {
 			return ProcessResult{
 				Success:     false,
@@ -323,13 +456,16 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 	if sourceIssue != "" {
 		mergeMsg = fmt.Sprintf("Merge %s into %s (%s)", branch, target, sourceIssue)
 	}
-	_, _ = fmt.Fprintf(e.output, "[Engineer] Merging with message: %s\n", mergeMsg)
-	if err := e.git.MergeNoFF(branch, mergeMsg); err != nil {
-		// ZFC: Use git's porcelain output to detect conflicts instead of parsing stderr.
-		// GetConflictingFiles() uses `git diff --diff-filter=U` which is proper.
-		conflicts, conflictErr := e.git.GetConflictingFiles()
-		if conflictErr == nil && len(conflicts) > 0 {
-			_ = e.git.AbortMerge()
+	if len(commits) > 0 {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Cherry-picking %d commit(s) from %s\n", len(commits), branch)
+	} else {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Merging with message: %s\n", mergeMsg)
+	}
+	_, span = e.tracer.Start(ctx, "merge")
+	conflicts, err := applyMR(e.git, branch, mergeMsg, commits)
+	span.End()
+	if err != nil {
+		if len(conflicts) > 0 {
 			return ProcessResult{
 				Success:  false,
 				Conflict: true,
@@ -353,7 +489,10 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 
 	// Step 7: Push to origin
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Pushing to origin/%s...\n", target)
-	if err := e.git.Push("origin", target, false); err != nil {
+	_, span = e.tracer.Start(ctx, "push")
+	err = e.git.Push("origin", target, false)
+	span.End()
+	if err != nil {
 		return ProcessResult{
 			Success: false,
 			Error:   fmt.Sprintf("failed to push to origin: %v", err),
@@ -367,13 +506,347 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 	}
 }
 
-// runTests runs the configured test command and returns the result.
-func (e *Engineer) runTests(ctx context.Context) ProcessResult {
-	if e.config.TestCommand == "" {
+// applyMR applies an MR onto the target checked out in g: a no-ff merge of
+// branch or, for a partial MR, a cherry-pick of its commits. A failed merge
+// is aborted; conflicts lists the files that conflicted, if any.
+func applyMR(g *git.Git, branch, message string, commits []string) (conflicts []string, err error) {
+	if len(commits) > 0 {
+		err = g.CherryPick(commits...)
+	} else {
+		err = g.MergeNoFF(branch, message)
+	}
+	if err == nil {
+		return nil, nil
+	}
+	// ZFC: Use git's porcelain output to detect conflicts instead of parsing stderr.
+	conflicts, _ = g.GetConflictingFiles()
+	if len(commits) > 0 {
+		_ = g.AbortCherryPick()
+	} else {
+		_ = g.AbortMerge()
+	}
+	return conflicts, err
+}
+
+// PooledMergeAttempts is the minimum number of times a pooled merge is
+// rebuilt because the target branch moved while its tests ran.
+const PooledMergeAttempts = 3
+
+// pooledMergeAttempts returns the rebuild budget for a pooled merge. Each
+// concurrent worker can land at most once ahead of an MR in the same batch,
+// so the budget grows with the number of workers.
+func (e *Engineer) pooledMergeAttempts() int {
+	return max(PooledMergeAttempts, e.config.Concurrency())
+}
+
+// worktreePool returns the Engineer's worktree pool, creating it on first use.
+func (e *Engineer) worktreePool() (*WorktreePool, error) {
+	e.poolMu.Lock()
+	defer e.poolMu.Unlock()
+	if e.pool == nil {
+		pool, err := NewWorktreePool(e.rig.Path, e.config.MaxConcurrent)
+		if err != nil {
+			return nil, err
+		}
+		e.pool = pool
+	}
+	return e.pool, nil
+}
+
+// doMergePooled merges branch into target in a worktree from the pool.
+// Merges and tests run concurrently in separate checkouts; only the final
+// check-and-push is serialized. If target moved while tests ran, the merge
+// is rebuilt on the new tip and re-tested, so nothing untested is pushed.
+// If target still moves after every attempt, the MR is requeued.
+func (e *Engineer) doMergePooled(ctx context.Context, branch, target, sourceIssue string, commits []string) ProcessResult {
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Checking local branch %s...\n", branch)
+	exists, err := e.git.BranchExists(branch)
+	if err != nil {
+		return ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("failed to check branch %s: %v", branch, err),
+		}
+	}
+	if !exists {
+		return ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("branch %s not found locally", branch),
+		}
+	}
+
+	pool, err := e.worktreePool()
+	if err != nil {
+		return ProcessResult{Success: false, Error: fmt.Sprintf("worktree pool: %v", err)}
+	}
+	wt, err := pool.Acquire(ctx, "")
+	if err != nil {
+		return ProcessResult{Success: false, Error: fmt.Sprintf("acquiring pool worktree: %v", err)}
+	}
+	defer wt.Release()
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Using pool worktree %s\n", wt.Path)
+
+	mergeMsg := fmt.Sprintf("Merge %s into %s", branch, target)
+	if sourceIssue != "" {
+		mergeMsg = fmt.Sprintf("Merge %s into %s (%s)", branch, target, sourceIssue)
+	}
+	remoteTarget := "origin/" + target
+
+	attempts := e.pooledMergeAttempts()
+	for attempt := 1; attempt <= attempts; attempt++ {
+		_, span := e.tracer.Start(ctx, "fetch")
+		span.SetAttribute("attempt", attempt)
+		if err := wt.Git.FetchBranch("origin", target); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: fetch origin/%s: %v (continuing)\n", target, err)
+		}
+		base, err := wt.Git.Rev(remoteTarget)
+		if err != nil {
+			span.End()
+			return ProcessResult{Success: false, Error: fmt.Sprintf("failed to resolve %s: %v", remoteTarget, err)}
+		}
+		if err := resetPoolWorktree(wt.Git, base); err != nil {
+			span.End()
+			return ProcessResult{Success: false, Error: fmt.Sprintf("failed to checkout %s: %v", remoteTarget, err)}
+		}
+		span.End()
+
+		// The pooled merge doubles as the conflict check
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Merging onto %s@%s...\n", remoteTarget, base[:8])
+		_, span = e.tracer.Start(ctx, "merge")
+		span.SetAttribute("attempt", attempt)
+		conflicts, err := applyMR(wt.Git, branch, mergeMsg, commits)
+		span.End()
+		if err != nil {
+			if len(conflicts) > 0 {
+				return ProcessResult{
+					Success:  false,
+					Conflict: true,
+					Error:    fmt.Sprintf("merge conflicts in: %v", conflicts),
+				}
+			}
+			return ProcessResult{Success: false, Error: fmt.Sprintf("merge failed: %v", err)}
+		}
+
+		if e.config.RunTests && e.config.HasTests() {
+			commands := e.testCommands(wt.Git, target, branch)
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Running tests: %s\n", strings.Join(commands, "; "))
+			e.postStatus(branch, StatusContextTests, github.StatePending, "Running tests")
+			result := e.runTests(ctx, wt.Path, commands)
+			e.reportTests(branch, result)
+			if !result.Success {
+				return ProcessResult{
+					Success:     false,
+					TestsFailed: true,
+					Error:       result.Error,
+				}
+			}
+			_, _ = fmt.Fprintln(e.output, "[Engineer] Tests passed")
+		}
+
+		mergeCommit, err := wt.Git.Rev("HEAD")
+		if err != nil {
+			return ProcessResult{Success: false, Error: fmt.Sprintf("failed to get merge commit SHA: %v", err)}
+		}
+
+		_, span = e.tracer.Start(ctx, "push")
+		span.SetAttribute("attempt", attempt)
+		moved, err := e.pushIfTargetUnchanged(wt.Git, target, base)
+		span.SetAttribute("target_moved", moved)
+		span.End()
+		if err != nil {
+			return ProcessResult{Success: false, Error: fmt.Sprintf("failed to push to origin: %v", err)}
+		}
+		if moved {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] origin/%s moved during test run, rebuilding merge (attempt %d/%d)\n",
+				target, attempt, attempts)
+			continue
+		}
+
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Successfully merged: %s\n", mergeCommit[:8])
+		return ProcessResult{
+			Success:     true,
+			MergeCommit: mergeCommit,
+		}
+	}
+
+	return ProcessResult{
+		Success: false,
+		Requeue: true,
+		Error:   fmt.Sprintf("origin/%s kept moving; requeued after %d attempts", target, attempts),
+	}
+}
+
+// pushIfTargetUnchanged pushes HEAD to target if origin/target is still at
+// base. Returns moved=true (and pushes nothing) if another merge landed first.
+func (e *Engineer) pushIfTargetUnchanged(g *git.Git, target, base string) (moved bool, err error) {
+	e.pushMu.Lock()
+	defer e.pushMu.Unlock()
+
+	if err := g.FetchBranch("origin", target); err != nil {
+		return false, err
+	}
+	tip, err := g.Rev("origin/" + target)
+	if err != nil {
+		return false, err
+	}
+	if tip != base {
+		return true, nil
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Pushing to origin/%s...\n", target)
+	if err := g.Push("origin", "HEAD:"+target, false); err != nil {
+		// Another refinery process may have pushed between fetch and push
+		if fetchErr := g.FetchBranch("origin", target); fetchErr == nil {
+			if tip, revErr := g.Rev("origin/" + target); revErr == nil && tip != base {
+				return true, nil
+			}
+		}
+		return false, err
+	}
+	return false, nil
+}
+
+// ProcessMRInfos processes MRs concurrently, up to MaxConcurrent at a time,
+// when the worktree pool is enabled (sequentially otherwise). Results are
+// returned in the order of mrs.
+func (e *Engineer) ProcessMRInfos(ctx context.Context, mrs []*MRInfo) []ProcessResult {
+	results := make([]ProcessResult, len(mrs))
+	sem := make(chan struct{}, e.config.Concurrency())
+	var wg sync.WaitGroup
+	for i, mr := range mrs {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, mr *MRInfo) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = e.ProcessMRInfo(ctx, mr)
+		}(i, mr)
+	}
+	wg.Wait()
+	return results
+}
+
+// ProcessReady claims the next batch of ready MRs (as many as are processed
+// at once) for workerID and processes them with ProcessMRInfos. Merged MRs
+// are closed; failed ones are recorded and released back to the queue.
+// Returns the MRs processed and their results, in the same order.
+func (e *Engineer) ProcessReady(ctx context.Context, workerID string) ([]*MRInfo, []ProcessResult, error) {
+	ready, err := e.ListReadyMRs()
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing ready MRs: %w", err)
+	}
+
+	batch := claimBatch(ready, e.config.Concurrency(), time.Now(), func(mr *MRInfo) bool {
+		if err := e.ClaimMR(mr.ID, workerID); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to claim %s: %v\n", mr.ID, err)
+			return false
+		}
+		return true
+	})
+
+	results := e.ProcessMRInfos(ctx, batch)
+	for i, mr := range batch {
+		switch {
+		case results[i].Success:
+			e.HandleMRInfoSuccess(mr, results[i])
+			continue
+		case results[i].Requeue:
+			// Busy target, not a merge failure: no MERGE_FAILED to the witness
+			_, _ = fmt.Fprintf(e.output, "[Engineer] ↻ Requeued: %s - %s\n", mr.ID, results[i].Error)
+		default:
+			e.HandleMRInfoFailure(mr, results[i])
+		}
+		if err := e.ReleaseMR(mr.ID); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to release %s: %v\n", mr.ID, err)
+		}
+	}
+	return batch, results, nil
+}
+
+// claimBatch ranks ready MRs into processing order (RankMRs at now) and
+// claims up to n of them, highest score first. MRs claim fails for are
+// skipped.
+func claimBatch(ready []*MRInfo, n int, now time.Time, claim func(*MRInfo) bool) []*MRInfo {
+	RankMRs(ready, now, DefaultScoreConfig())
+	var batch []*MRInfo
+	for _, mr := range ready {
+		if len(batch) == n {
+			break
+		}
+		if claim(mr) {
+			batch = append(batch, mr)
+		}
+	}
+	return batch
+}
+
+// ProvisionPool checks out the worktree pool ahead of the first MRs, so
+// they don't pay the checkout cost. A no-op unless the pool is enabled.
+func (e *Engineer) ProvisionPool() error {
+	if !e.config.WorktreePool {
+		return nil
+	}
+	pool, err := e.worktreePool()
+	if err != nil {
+		return err
+	}
+	return pool.Provision("")
+}
+
+// testCommands returns the test commands for merging branch into target,
+// selected by the files it changes (see MergeQueueConfig.TestSelection).
+func (e *Engineer) testCommands(g *git.Git, target, branch string) []string {
+	if len(e.config.TestSelection) == 0 {
+		return e.config.TestCommands(nil)
+	}
+	changes, err := g.ChangedFiles("origin/"+target, branch)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not list changed files for test selection: %v\n", err)
+		return e.config.TestCommands(nil)
+	}
+	return e.config.TestCommands(changes)
+}
+
+// runTests runs the test commands in workDir, in order, and returns the
+// result of the first to fail, or success.
+func (e *Engineer) runTests(ctx context.Context, workDir string, commands []string) ProcessResult {
+	if len(commands) == 0 {
 		return ProcessResult{Success: true}
 	}
 
-	// Run the test command with retries for flaky tests
+	ctx, span := e.tracer.Start(ctx, "tests")
+	defer span.End()
+
+	// Test suites can run for many minutes; keep the refinery's keepalive
+	// fresh meanwhile so it doesn't look stale to the witness and daemon.
+	var hb *keepalive.Heartbeat
+	if townRoot, err := workspace.Find(e.rig.Path); err == nil && townRoot != "" {
+		hb = keepalive.StartInWorkspace(townRoot, "gt refinery", "updating submodules")
+	}
+	defer hb.Stop()
+
+	// Bring submodules to the commits recorded by the merged tree; a stale
+	// or missing submodule fails tests with confusing errors.
+	_, subSpan := e.tracer.Start(ctx, "submodules")
+	err := rig.UpdateSubmodules(e.rig.Path, workDir)
+	subSpan.End()
+	if err != nil {
+		span.SetError(err.Error())
+		return ProcessResult{
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+
+	for _, command := range commands {
+		if result := e.runTestCommand(ctx, span, hb, workDir, command); !result.Success {
+			return result
+		}
+	}
+	return ProcessResult{Success: true}
+}
+
+// runTestCommand runs one test command in workDir, retrying flaky failures.
+func (e *Engineer) runTestCommand(ctx context.Context, span *tracing.Span, hb *keepalive.Heartbeat, workDir, command string) ProcessResult {
 	maxRetries := e.config.RetryFlakyTests
 	if maxRetries < 1 {
 		maxRetries = 1
@@ -383,17 +856,27 @@ func (e *Engineer) runTests(ctx context.Context) ProcessResult {
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 		if attempt > 1 {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Retrying tests (attempt %d/%d)...\n", attempt, maxRetries)
+			hb.SetPhase(fmt.Sprintf("running tests (attempt %d/%d)", attempt, maxRetries))
+		} else {
+			hb.SetPhase("running tests")
 		}
 
-		// Note: TestCommand comes from rig's config.json (trusted infrastructure config),
+		// Note: test commands come from rig's config.json (trusted infrastructure config),
 		// not from PR branches. Shell execution is intentional for flexibility (pipes, etc).
-		cmd := exec.CommandContext(ctx, "sh", "-c", e.config.TestCommand) //nolint:gosec // G204: TestCommand is from trusted rig config
-		cmd.Dir = e.workDir
+		cmd := exec.CommandContext(ctx, "sh", "-c", command) //nolint:gosec // G204: command is from trusted rig config
+		cmd.Dir = workDir
 		var stdout, stderr bytes.Buffer
 		cmd.Stdout = &stdout
 		cmd.Stderr = &stderr
 
+		_, attemptSpan := e.tracer.Start(ctx, "tests.attempt")
+		attemptSpan.SetAttribute("attempt", attempt)
 		err := cmd.Run()
+		if err != nil {
+			attemptSpan.SetError(err.Error())
+		}
+		attemptSpan.End()
+		span.SetAttribute("attempts", attempt)
 		if err == nil {
 			return ProcessResult{Success: true}
 		}
@@ -401,6 +884,7 @@ func (e *Engineer) runTests(ctx context.Context) ProcessResult {
 
 		// Check if context was canceled
 		if ctx.Err() != nil {
+			span.SetError("test run canceled")
 			return ProcessResult{
 				Success: false,
 				Error:   "test run canceled",
@@ -408,10 +892,11 @@ func (e *Engineer) runTests(ctx context.Context) ProcessResult {
 		}
 	}
 
+	span.SetError(fmt.Sprintf("tests failed after %d attempts", maxRetries))
 	return ProcessResult{
 		Success:     false,
 		TestsFailed: true,
-		Error:       fmt.Sprintf("tests failed after %d attempts: %v", maxRetries, lastErr),
+		Error:       fmt.Sprintf("%s: tests failed after %d attempts: %v", command, maxRetries, lastErr),
 	}
 }
 
@@ -419,9 +904,10 @@ func (e *Engineer) runTests(ctx context.Context) ProcessResult {
 // Steps:
 // 1. Update MR with merge_commit SHA
 // 2. Close MR with reason 'merged'
-// 3. Close source issue with reference to MR
-// 4. Delete source branch if configured
-// 5. Log success
+// 3. Close source issue with reference to MR (full MRs only)
+// 4. Delete source branch if configured (full MRs only)
+// 5. Record the merge in the audit chain and changelog
+// 6. Log success
 func (e *Engineer) handleSuccess(mr *beads.Issue, result ProcessResult) {
 	// Parse MR fields from description
 	mrFields := beads.ParseMRFields(mr)
@@ -442,8 +928,9 @@ func (e *Engineer) handleSuccess(mr *beads.Issue, result ProcessResult) {
 		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to close MR %s: %v\n", mr.ID, err)
 	}
 
-	// 3. Close source issue with reference to MR
-	if mrFields.SourceIssue != "" {
+	// 3. Close source issue with reference to MR. A partial MR lands only
+	// part of the work; the worker is still on it.
+	if mrFields.SourceIssue != "" && !mrFields.IsPartial() {
 		closeReason := fmt.Sprintf("Merged in %s", mr.ID)
 		if err := e.beads.CloseWithReason(closeReason, mrFields.SourceIssue); err != nil {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to close source issue %s: %v\n", mrFields.SourceIssue, err)
@@ -462,7 +949,7 @@ func (e *Engineer) handleSuccess(mr *beads.Issue, result ProcessResult) {
 	// 4. Delete source branch if configured (local and remote)
 	// Since the self-cleaning model (Jan 10), polecats push to origin before gt done,
 	// so we need to clean up both local and remote branches after merge.
-	if e.config.DeleteMergedBranches && mrFields.Branch != "" {
+	if e.config.DeleteMergedBranches && mrFields.Branch != "" && !mrFields.IsPartial() {
 		if err := e.git.DeleteBranch(mrFields.Branch, true); err != nil {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to delete local branch %s: %v\n", mrFields.Branch, err)
 		} else {
@@ -476,7 +963,20 @@ func (e *Engineer) handleSuccess(mr *beads.Issue, result ProcessResult) {
 		}
 	}
 
-	// 5. Log success
+	// 5. Record merges to the default or protected branches in the audit chain
+	target := mrFields.Target
+	if target == "" {
+		target = e.config.TargetBranch
+	}
+	e.recordMerge(target, mrFields.Branch, result.MergeCommit, mr.ID, mrFields.SourceIssue)
+
+	// 6. Append to the changelog, for release notes
+	e.recordChangelog(changelog.Entry{
+		Issue: mrFields.SourceIssue, Title: mr.Title, Worker: mrFields.Worker,
+		MR: mr.ID, Branch: mrFields.Branch, Target: target, Commit: result.MergeCommit,
+	})
+
+	// 7. Log success
 	_, _ = fmt.Fprintf(e.output, "[Engineer] ✓ Merged: %s (commit: %s)\n", mr.ID, result.MergeCommit)
 }
 
@@ -501,9 +1001,12 @@ func (e *Engineer) ProcessMRInfo(ctx context.Context, mr *MRInfo) ProcessResult
 	_, _ = fmt.Fprintf(e.output, "  Target: %s\n", mr.Target)
 	_, _ = fmt.Fprintf(e.output, "  Worker: %s\n", mr.Worker)
 	_, _ = fmt.Fprintf(e.output, "  Source: %s\n", mr.SourceIssue)
+	if len(mr.Commits) > 0 {
+		_, _ = fmt.Fprintf(e.output, "  Partial: %d commit(s)\n", len(mr.Commits))
+	}
 
 	// Use the shared merge logic
-	return e.doMerge(ctx, mr.Branch, mr.Target, mr.SourceIssue)
+	return e.mergeWithStatus(ctx, mr.Branch, mr.Target, mr.SourceIssue, mr.Commits)
 }
 
 // HandleMRInfoSuccess handles a successful merge from MRInfo.
@@ -511,11 +1014,10 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 	// Release merge slot if this was a conflict resolution
 	// The slot is held while conflict resolution is in progress
 	holder := e.rig.Name + "/refinery"
-	if err := e.beads.MergeSlotRelease(holder); err != nil {
+	slot := lease.MergeSlot(e.rig.Name)
+	if _, err := e.leases.Release(slot, holder); err != nil {
 		// Not an error if slot wasn't held - it's optional
-		// Only log if it seems like an actual issue
-		errStr := err.Error()
-		if !strings.Contains(errStr, "not held") && !strings.Contains(errStr, "not found") {
+		if !errors.Is(err, lease.ErrNotHeld) && !errors.Is(err, lease.ErrNotFound) {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to release merge slot: %v\n", err)
 		}
 	} else {
@@ -550,8 +1052,9 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 		}
 	}
 
-	// 1. Close source issue with reference to MR
-	if mr.SourceIssue != "" {
+	// 1. Close source issue with reference to MR, unless the MR is partial
+	// and the worker is still on it
+	if mr.SourceIssue != "" && len(mr.Commits) == 0 {
 		closeReason := fmt.Sprintf("Merged in %s", mr.ID)
 		if err := e.beads.CloseWithReason(closeReason, mr.SourceIssue); err != nil {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to close source issue %s: %v\n", mr.SourceIssue, err)
@@ -568,7 +1071,7 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 	}
 
 	// 2. Delete source branch if configured (local only)
-	if e.config.DeleteMergedBranches && mr.Branch != "" {
+	if e.config.DeleteMergedBranches && mr.Branch != "" && len(mr.Commits) == 0 {
 		if err := e.git.DeleteBranch(mr.Branch, true); err != nil {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to delete branch %s: %v\n", mr.Branch, err)
 		} else {
@@ -576,10 +1079,51 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 		}
 	}
 
-	// 3. Log success
+	// 3. Record merges to the default or protected branches in the audit chain
+	e.recordMerge(mr.Target, mr.Branch, result.MergeCommit, mr.ID, mr.SourceIssue)
+
+	// 4. Append to the changelog, for release notes
+	e.recordChangelog(changelog.Entry{
+		Issue: mr.SourceIssue, Title: mr.Title, Worker: mr.Worker,
+		MR: mr.ID, Branch: mr.Branch, Target: mr.Target, Commit: result.MergeCommit,
+	})
+
+	// 5. Log success
 	_, _ = fmt.Fprintf(e.output, "[Engineer] ✓ Merged: %s (commit: %s)\n", mr.ID, result.MergeCommit)
 }
 
+// recordMerge records a completed merge in the town's audit chain.
+func (e *Engineer) recordMerge(target, branch, commit, mrID, sourceIssue string) {
+	details := map[string]string{"branch": branch, "commit": commit}
+	if mrID != "" {
+		details["mr"] = mrID
+	}
+	if sourceIssue != "" {
+		details["source_issue"] = sourceIssue
+	}
+	townRoot := filepath.Dir(e.rig.Path)
+	if err := RecordMerge(townRoot, e.rig.Name, e.rig.Name+"/refinery", target, details); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to record merge in audit chain: %v\n", err)
+	}
+}
+
+// recordChangelog appends a merge to the town's changelog, taking its title
+// and type from the source issue when there is one.
+func (e *Engineer) recordChangelog(entry changelog.Entry) {
+	if entry.Commit == "" {
+		return
+	}
+	entry.Rig = e.rig.Name
+	if entry.Issue != "" {
+		if issue, err := e.beads.Show(entry.Issue); err == nil && issue != nil {
+			entry.Title, entry.Type = issue.Title, issue.Type
+		}
+	}
+	if err := changelog.Append(filepath.Dir(e.rig.Path), entry); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to append to changelog: %v\n", err)
+	}
+}
+
 // HandleMRInfoFailure handles a failed merge from MRInfo.
 // For conflicts, creates a resolution task and blocks the MR until resolved.
 // This enables non-blocking delegation: the queue continues to the next MR.
@@ -597,6 +1141,15 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 		fmt.Fprintf(e.output, "[Engineer] Warning: failed to send MERGE_FAILED to witness: %v\n", err)
 	} else {
 		fmt.Fprintf(e.output, "[Engineer] Notified witness of merge failure for %s\n", mr.Worker)
+		if err := protocol.NewAckTracker(filepath.Dir(e.rig.Path)).Record(msg, time.Now()); err != nil {
+			fmt.Fprintf(e.output, "[Engineer] Warning: could not track MERGE_FAILED acknowledgment: %v\n", err)
+		}
+		if ev := protocol.MergeEvent(msg); ev != nil {
+			ev.Subject = mr.ID
+			ev.TraceID = result.TraceID
+			ev.Payload["mr"] = mr.ID
+			_ = events.Emit(*ev)
+		}
 	}
 
 	// If this was a conflict, create a conflict-resolution task for dispatch
@@ -638,33 +1191,31 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 //	Description: metadata including branch, conflict SHA, etc.
 //
 // Merge Slot Integration:
-// Before creating a conflict resolution task, we acquire the merge-slot for this rig.
+// Before creating a conflict resolution task, we acquire the rig's merge-slot lease.
 // This serializes conflict resolution - only one polecat can resolve conflicts at a time.
 // If the slot is already held, we skip creating the task and let the MR stay in queue.
 // When the current resolution completes and merges, the slot is released.
 func (e *Engineer) createConflictResolutionTaskForMR(mr *MRInfo, _ ProcessResult) (string, error) { // result unused but kept for future merge diagnostics
 	// === MERGE SLOT GATE: Serialize conflict resolution ===
-	// Ensure merge slot exists (idempotent)
-	slotID, err := e.beads.MergeSlotEnsureExists()
-	if err != nil {
-		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not ensure merge slot: %v\n", err)
-		// Continue anyway - slot is optional for now
-	} else {
-		// Try to acquire the merge slot
-		holder := e.rig.Name + "/refinery"
-		status, err := e.beads.MergeSlotAcquire(holder, false)
-		if err != nil {
-			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not acquire merge slot: %v\n", err)
-			// Continue anyway - slot is optional
-		} else if !status.Available && status.Holder != "" && status.Holder != holder {
-			// Slot is held by someone else - skip creating the task
-			// The MR stays in queue and will retry when slot is released
-			_, _ = fmt.Fprintf(e.output, "[Engineer] Merge slot held by %s - deferring conflict resolution\n", status.Holder)
-			_, _ = fmt.Fprintf(e.output, "[Engineer] MR %s will retry after current resolution completes\n", mr.ID)
-			return "", nil // Not an error - just deferred
+	holder := e.rig.Name + "/refinery"
+	slot := lease.MergeSlot(e.rig.Name)
+	l, err := e.leases.Acquire(slot, holder, lease.AcquireOptions{TTL: MergeSlotTTL})
+	switch {
+	case errors.Is(err, lease.ErrHeld):
+		// Slot is held by someone else - skip creating the task
+		// The MR stays in queue and will retry when slot is released
+		heldBy := "another worker"
+		if l != nil && len(l.Holders) > 0 {
+			heldBy = l.Holders[0].Holder
 		}
-		// Either we acquired the slot, or status indicates we already hold it
-		_, _ = fmt.Fprintf(e.output, "[Engineer] Acquired merge slot: %s\n", slotID)
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Merge slot held by %s - deferring conflict resolution\n", heldBy)
+		_, _ = fmt.Fprintf(e.output, "[Engineer] MR %s will retry after current resolution completes\n", mr.ID)
+		return "", nil // Not an error - just deferred
+	case err != nil:
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not acquire merge slot: %v\n", err)
+		// Continue anyway - slot is optional
+	default:
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Acquired merge slot: %s\n", slot)
 	}
 
 	// Get the current main SHA for conflict tracking
@@ -706,7 +1257,7 @@ func (e *Engineer) createConflictResolutionTaskForMR(mr *MRInfo, _ ProcessResult
 2. Rebase onto target: git rebase origin/%s
 3. Resolve conflicts in your editor
 4. Complete the rebase: git add . && git rebase --continue
-5. Force-push the resolved branch: git push -f
+5. Force-push the resolved branch: git push --force-with-lease
 6. Close this task: bd close <this-task-id>
 
 The Refinery will automatically retry the merge after you force-push.`,
@@ -757,9 +1308,14 @@ func (e *Engineer) IsBeadOpen(beadID string) (bool, error) {
 // - Not claimed by another worker (checked via assignee field)
 // - Not blocked by an open task (handled by bd ready)
 // Sorted by priority (highest first).
+// Returns none while the rig's merge queue is paused (see PauseQueue).
 //
 // This queries beads for merge-request wisps.
 func (e *Engineer) ListReadyMRs() ([]*MRInfo, error) {
+	if _, paused := QueuePaused(filepath.Dir(e.rig.Path), e.rig.Name); paused {
+		return nil, nil
+	}
+
 	// Query beads for ready merge-request issues
 	issues, err := e.beads.ReadyWithType("merge-request")
 	if err != nil {
@@ -815,6 +1371,7 @@ func (e *Engineer) ListReadyMRs() ([]*MRInfo, error) {
 			ConvoyID:        fields.ConvoyID,
 			ConvoyCreatedAt: convoyCreatedAt,
 			CreatedAt:       createdAt,
+			Commits:         fields.Commits,
 		}
 		mrs = append(mrs, mr)
 	}
@@ -903,6 +1460,7 @@ func (e *Engineer) ListBlockedMRs() ([]*MRInfo, error) {
 			ConvoyID:        fields.ConvoyID,
 			ConvoyCreatedAt: convoyCreatedAt,
 			CreatedAt:       createdAt,
+			Commits:         fields.Commits,
 			BlockedBy:       blockedBy,
 		}
 		mrs = append(mrs, mr)
@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/rig"
 )
 
@@ -215,3 +217,58 @@ func TestEngineer_DeleteMergedBranchesConfig(t *testing.T) {
 		t.Error("expected DeleteMergedBranches to be true by default")
 	}
 }
+
+func TestClaimBatch_HigherPriorityFirst(t *testing.T) {
+	now := time.Now()
+	ready := []*MRInfo{
+		{ID: "gt-low", Priority: 4, CreatedAt: now.Add(-time.Hour)},
+		{ID: "gt-high", Priority: 0, CreatedAt: now},
+		{ID: "gt-mid", Priority: 2, CreatedAt: now},
+	}
+
+	var claimed []string
+	batch := claimBatch(ready, 2, now, func(mr *MRInfo) bool {
+		claimed = append(claimed, mr.ID)
+		return mr.ID != "gt-mid" // someone else got it first
+	})
+
+	if got := strings.Join(claimed, ","); got != "gt-high,gt-mid,gt-low" {
+		t.Errorf("claim order = %s, want gt-high,gt-mid,gt-low", got)
+	}
+	if len(batch) != 2 || batch[0].ID != "gt-high" || batch[1].ID != "gt-low" {
+		t.Errorf("batch = %v, want [gt-high gt-low]", batch)
+	}
+}
+
+func TestMergeQueueConfig_TestCommands(t *testing.T) {
+	cfg := DefaultMergeQueueConfig()
+	raw := []byte(`{"test_command": "make test", "test_selection": [
+		{"paths": ["web/"], "command": "npm test"},
+		{"paths": ["*.go"], "command": "go test ./..."},
+		{"paths": ["internal/**"], "command": "go test ./..."}
+	]}`)
+	if err := ApplyMergeQueueConfig(cfg, raw); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{"web only", []string{"web/app.js"}, "npm test"},
+		{"go and web", []string{"internal/x/x.go", "web/app.js"}, "npm test; go test ./..."},
+		{"no rule matches", []string{"README.md"}, "make test"},
+		{"some files uncovered", []string{"web/app.js", "README.md"}, "npm test; make test"},
+		{"all files covered", []string{"web/app.js", "cmd/main.go"}, "npm test; go test ./..."},
+	}
+	for _, tt := range tests {
+		got := strings.Join(cfg.TestCommands(&git.ChangeSet{Files: tt.files}), "; ")
+		if got != tt.want {
+			t.Errorf("%s: TestCommands = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := strings.Join(cfg.TestCommands(nil), "; "); got != "make test" {
+		t.Errorf("unknown changes: TestCommands = %q, want make test", got)
+	}
+}
@@ -1,7 +1,6 @@
 package refinery
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -11,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/agent"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
@@ -32,9 +32,10 @@ var (
 
 // Manager handles refinery lifecycle and queue operations.
 type Manager struct {
-	rig     *rig.Rig
-	workDir string
-	output  io.Writer // Output destination for user-facing messages
+	rig          *rig.Rig
+	workDir      string
+	output       io.Writer // Output destination for user-facing messages
+	stateManager *agent.StateManager[Refinery]
 }
 
 // NewManager creates a new refinery manager for a rig.
@@ -43,6 +44,12 @@ func NewManager(r *rig.Rig) *Manager {
 		rig:     r,
 		workDir: r.Path,
 		output:  os.Stdout,
+		stateManager: agent.NewStateManager[Refinery](r.Path, "refinery.json", func() *Refinery {
+			return &Refinery{
+				RigName: r.Name,
+				State:   StateStopped,
+			}
+		}),
 	}
 }
 
@@ -54,7 +61,7 @@ func (m *Manager) SetOutput(w io.Writer) {
 
 // stateFile returns the path to the refinery state file.
 func (m *Manager) stateFile() string {
-	return filepath.Join(m.rig.Path, ".runtime", "refinery.json")
+	return m.stateManager.StateFile()
 }
 
 // SessionName returns the tmux session name for this refinery.
@@ -64,33 +71,12 @@ func (m *Manager) SessionName() string {
 
 // loadState loads refinery state from disk.
 func (m *Manager) loadState() (*Refinery, error) {
-	data, err := os.ReadFile(m.stateFile())
-	if err != nil {
-		if os.IsNotExist(err) {
-			return &Refinery{
-				RigName: m.rig.Name,
-				State:   StateStopped,
-			}, nil
-		}
-		return nil, err
-	}
-
-	var ref Refinery
-	if err := json.Unmarshal(data, &ref); err != nil {
-		return nil, err
-	}
-
-	return &ref, nil
+	return m.stateManager.Load()
 }
 
 // saveState persists refinery state to disk using atomic write.
 func (m *Manager) saveState(ref *Refinery) error {
-	dir := filepath.Dir(m.stateFile())
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-
-	return util.AtomicWriteJSON(m.stateFile(), ref)
+	return m.stateManager.Save(ref)
 }
 
 // Status returns the current refinery status.
@@ -129,6 +115,7 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 		if err := m.saveState(ref); err != nil {
 			return err
 		}
+		m.provisionPool()
 
 		// Run the processing loop (blocking)
 		return m.run(ref)
@@ -173,6 +160,8 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 		return fmt.Errorf("ensuring runtime settings: %w", err)
 	}
 
+	m.provisionPool()
+
 	// Build startup command first
 	townRoot := filepath.Dir(m.rig.Path)
 	var command string
@@ -255,6 +244,20 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 	return nil
 }
 
+// provisionPool checks out the merge queue's worktree pool, if enabled, so
+// the first MRs the refinery processes don't wait on checkouts.
+func (m *Manager) provisionPool() {
+	eng := NewEngineer(m.rig)
+	eng.SetOutput(m.output)
+	if err := eng.LoadConfig(); err != nil {
+		_, _ = fmt.Fprintf(m.output, "Warning: loading merge queue config: %v\n", err)
+		return
+	}
+	if err := eng.ProvisionPool(); err != nil {
+		_, _ = fmt.Fprintf(m.output, "Warning: provisioning worktree pool: %v\n", err)
+	}
+}
+
 // Stop stops the refinery.
 func (m *Manager) Stop() error {
 	ref, err := m.loadState()
@@ -603,7 +606,7 @@ func (m *Manager) notifyWorkerConflict(mr *MergeRequest) {
 Please rebase your changes:
   git fetch origin
   git rebase origin/%s
-  git push -f
+  git push --force-with-lease
 
 Then the Refinery will retry the merge.`,
 			mr.Branch, mr.TargetBranch, mr.TargetBranch),
@@ -688,30 +691,26 @@ func (m *Manager) FindMR(idOrBranch string) (*MergeRequest, error) {
 // The processNow parameter is deprecated - the Refinery agent handles processing.
 // Clearing the error is sufficient; the agent will pick up the MR in its next patrol cycle.
 func (m *Manager) Retry(id string, processNow bool) error {
-	ref, err := m.loadState()
-	if err != nil {
-		return err
-	}
-
-	// Find the MR
-	var mr *MergeRequest
-	if ref.PendingMRs != nil {
-		mr = ref.PendingMRs[id]
-	}
-	if mr == nil {
-		return ErrMRNotFound
-	}
-
-	// Verify it's in a failed state (open with an error)
-	if mr.Status != MROpen || mr.Error == "" {
-		return ErrMRNotFailed
-	}
+	_, err := m.stateManager.Update(func(ref *Refinery) error {
+		// Find the MR
+		var mr *MergeRequest
+		if ref.PendingMRs != nil {
+			mr = ref.PendingMRs[id]
+		}
+		if mr == nil {
+			return ErrMRNotFound
+		}
 
-	// Clear the error to mark as ready for retry
-	mr.Error = ""
+		// Verify it's in a failed state (open with an error)
+		if mr.Status != MROpen || mr.Error == "" {
+			return ErrMRNotFailed
+		}
 
-	// Save the state
-	if err := m.saveState(ref); err != nil {
+		// Clear the error to mark as ready for retry
+		mr.Error = ""
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
@@ -727,17 +726,14 @@ func (m *Manager) Retry(id string, processNow bool) error {
 
 // RegisterMR adds a merge request to the pending queue.
 func (m *Manager) RegisterMR(mr *MergeRequest) error {
-	ref, err := m.loadState()
-	if err != nil {
-		return err
-	}
-
-	if ref.PendingMRs == nil {
-		ref.PendingMRs = make(map[string]*MergeRequest)
-	}
-
-	ref.PendingMRs[mr.ID] = mr
-	return m.saveState(ref)
+	_, err := m.stateManager.Update(func(ref *Refinery) error {
+		if ref.PendingMRs == nil {
+			ref.PendingMRs = make(map[string]*MergeRequest)
+		}
+		ref.PendingMRs[mr.ID] = mr
+		return nil
+	})
+	return err
 }
 
 // RejectMR manually rejects a merge request.
@@ -0,0 +1,30 @@
+package refinery
+
+import (
+	"github.com/steveyegge/gastown/internal/wisp"
+)
+
+// QueuePausedKey is the rig wisp config key that pauses a rig's merge queue.
+// Its value is the reason the queue was paused.
+const QueuePausedKey = "merge_queue_paused"
+
+// PauseQueue pauses a rig's merge queue: no MRs are reported ready until
+// ResumeQueue is called. The refinery keeps running and finishes any merge
+// already in progress. The pause is local to this town (wisp layer).
+func PauseQueue(townRoot, rigName, reason string) error {
+	if reason == "" {
+		reason = "paused"
+	}
+	return wisp.NewConfig(townRoot, rigName).Set(QueuePausedKey, reason)
+}
+
+// ResumeQueue resumes a rig's merge queue paused with PauseQueue.
+func ResumeQueue(townRoot, rigName string) error {
+	return wisp.NewConfig(townRoot, rigName).Unset(QueuePausedKey)
+}
+
+// QueuePaused reports whether a rig's merge queue is paused, and why.
+func QueuePaused(townRoot, rigName string) (reason string, paused bool) {
+	reason = wisp.NewConfig(townRoot, rigName).GetString(QueuePausedKey)
+	return reason, reason != ""
+}
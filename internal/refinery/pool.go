@@ -0,0 +1,193 @@
+package refinery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// WorktreePool is a fixed set of detached worktrees the Engineer checks out
+// for merges and test runs. Each MR gets its own checkout, so test runs can
+// proceed concurrently and a checkout left broken by one MR (stuck merge,
+// corrupted index) is rebuilt instead of wedging the whole queue.
+//
+// Pool worktrees live at <rig>/refinery/pool/<n> and share the rig's
+// .repo.git (or mayor/rig) objects and refs, so polecat branches are visible
+// without fetching.
+type WorktreePool struct {
+	rigPath string
+	repo    *git.Git
+	dir     string
+	slots   chan int
+
+	// mu serializes changes to the repo's worktree list: a prune run while
+	// another slot's worktree is being added can delete its half-written entry.
+	mu sync.Mutex
+}
+
+// PoolWorktree is a worktree checked out from a WorktreePool.
+// Call Release when done with it.
+type PoolWorktree struct {
+	Path string
+	Git  *git.Git
+
+	pool *WorktreePool
+	slot int
+}
+
+// NewWorktreePool creates a pool of size worktrees for the rig.
+// Worktrees are provisioned lazily on first Acquire, or eagerly via Provision.
+func NewWorktreePool(rigPath string, size int) (*WorktreePool, error) {
+	if size < 1 {
+		size = 1
+	}
+	repo, err := poolRepoBase(rigPath)
+	if err != nil {
+		return nil, err
+	}
+	p := &WorktreePool{
+		rigPath: rigPath,
+		repo:    repo,
+		dir:     filepath.Join(rigPath, "refinery", "pool"),
+		slots:   make(chan int, size),
+	}
+	for i := 0; i < size; i++ {
+		p.slots <- i
+	}
+	return p, nil
+}
+
+// poolRepoBase returns the repo that pool worktrees are created from:
+// the shared bare repo, or mayor/rig for legacy rigs.
+func poolRepoBase(rigPath string) (*git.Git, error) {
+	bareRepoPath := filepath.Join(rigPath, ".repo.git")
+	if info, err := os.Stat(bareRepoPath); err == nil && info.IsDir() {
+		return git.NewGitWithDir(bareRepoPath, ""), nil
+	}
+	mayorPath := filepath.Join(rigPath, "mayor", "rig")
+	if _, err := os.Stat(mayorPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("no repo base found (neither .repo.git nor mayor/rig exists)")
+	}
+	return git.NewGit(mayorPath), nil
+}
+
+// Size returns the number of worktrees in the pool.
+func (p *WorktreePool) Size() int {
+	return cap(p.slots)
+}
+
+// Dir returns the directory holding the pool's worktrees.
+func (p *WorktreePool) Dir() string {
+	return p.dir
+}
+
+func (p *WorktreePool) slotPath(slot int) string {
+	return filepath.Join(p.dir, strconv.Itoa(slot))
+}
+
+// Provision creates any missing pool worktrees at ref so the first MRs
+// don't pay the checkout cost. Worktrees currently in use are skipped.
+func (p *WorktreePool) Provision(ref string) error {
+	var taken []int
+	defer func() {
+		for _, slot := range taken {
+			p.slots <- slot
+		}
+	}()
+	for i := 0; i < p.Size(); i++ {
+		select {
+		case slot := <-p.slots:
+			taken = append(taken, slot)
+			if _, err := p.prepare(slot, ref); err != nil {
+				return fmt.Errorf("preparing pool worktree %d: %w", slot, err)
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// Acquire blocks until a worktree is free (or ctx is done) and returns it
+// reset to a clean detached checkout of ref. A worktree that can't be reset
+// is removed and re-created.
+func (p *WorktreePool) Acquire(ctx context.Context, ref string) (*PoolWorktree, error) {
+	var slot int
+	select {
+	case slot = <-p.slots:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	g, err := p.prepare(slot, ref)
+	if err != nil {
+		p.slots <- slot
+		return nil, fmt.Errorf("preparing pool worktree %d: %w", slot, err)
+	}
+	return &PoolWorktree{
+		Path: p.slotPath(slot),
+		Git:  rig.WithGitAuth(p.rigPath, g),
+		pool: p,
+		slot: slot,
+	}, nil
+}
+
+// Release returns the worktree to the pool. The worktree is reset on the
+// next Acquire, so whatever state the merge left behind doesn't matter.
+func (wt *PoolWorktree) Release() {
+	wt.pool.slots <- wt.slot
+}
+
+// prepare returns a clean detached checkout of ref in the slot's worktree,
+// creating or rebuilding the worktree as needed.
+func (p *WorktreePool) prepare(slot int, ref string) (*git.Git, error) {
+	path := p.slotPath(slot)
+	g := git.NewGit(path)
+
+	if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+		if err := resetPoolWorktree(g, ref); err == nil {
+			return g, nil
+		}
+		// Broken checkout: rebuild it rather than fail every MR that lands here
+		p.mu.Lock()
+		_ = p.repo.WorktreeRemove(path, true)
+		p.mu.Unlock()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := os.RemoveAll(path); err != nil {
+		return nil, fmt.Errorf("removing stale worktree: %w", err)
+	}
+	_ = p.repo.WorktreePrune() // non-fatal: clears the entry of a removed checkout
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating pool dir: %w", err)
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if err := p.repo.WorktreeAddDetached(path, ref); err != nil {
+		return nil, fmt.Errorf("creating worktree: %w", err)
+	}
+	return g, nil
+}
+
+// resetPoolWorktree discards any merge, modification, or untracked file left
+// in a pool worktree and checks out ref detached. An empty ref keeps HEAD.
+func resetPoolWorktree(g *git.Git, ref string) error {
+	_ = g.AbortMerge()      // non-fatal: usually no merge in progress
+	_ = g.AbortCherryPick() // nor a partial MR's cherry-pick
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if err := g.CheckoutDetached(ref); err != nil {
+		return err
+	}
+	return g.Clean()
+}
@@ -0,0 +1,309 @@
+package refinery
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// runGit runs git in dir and fails the test on error.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// setupPoolRig creates a rig with an origin repo, a .repo.git bare clone,
+// and a refinery/rig worktree on main. Returns the rig path and origin path.
+func setupPoolRig(t *testing.T) (string, string) {
+	t.Helper()
+	tmp := t.TempDir()
+
+	origin := filepath.Join(tmp, "origin")
+	runGit(t, tmp, "init", "--bare", "-b", "main", origin)
+
+	seed := filepath.Join(tmp, "seed")
+	runGit(t, tmp, "clone", origin, seed)
+	runGit(t, seed, "config", "user.email", "test@test.com")
+	runGit(t, seed, "config", "user.name", "Test User")
+	if err := os.WriteFile(filepath.Join(seed, "README.md"), []byte("# Test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, seed, "add", ".")
+	runGit(t, seed, "commit", "-m", "initial")
+	runGit(t, seed, "push", "origin", "HEAD:main")
+
+	rigPath := filepath.Join(tmp, "rig")
+	bare := filepath.Join(rigPath, ".repo.git")
+	runGit(t, tmp, "clone", "--bare", origin, bare)
+	runGit(t, bare, "config", "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*")
+	runGit(t, bare, "config", "user.email", "test@test.com")
+	runGit(t, bare, "config", "user.name", "Test User")
+	runGit(t, bare, "fetch", "origin")
+	runGit(t, bare, "worktree", "add", filepath.Join(rigPath, "refinery", "rig"), "main")
+
+	return rigPath, origin
+}
+
+// addPolecatBranch commits file on a new branch in the rig's bare repo.
+// Returns the branch's worktree.
+func addPolecatBranch(t *testing.T, rigPath, branch, file, content string) string {
+	t.Helper()
+	wt := filepath.Join(t.TempDir(), "polecat")
+	runGit(t, filepath.Join(rigPath, ".repo.git"), "worktree", "add", "-b", branch, wt, "origin/main")
+	if err := os.WriteFile(filepath.Join(wt, file), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, wt, "add", ".")
+	runGit(t, wt, "commit", "-m", "work on "+file)
+	return wt
+}
+
+func TestWorktreePool_AcquireResetsCheckout(t *testing.T) {
+	rigPath, _ := setupPoolRig(t)
+	pool, err := NewWorktreePool(rigPath, 1)
+	if err != nil {
+		t.Fatalf("NewWorktreePool: %v", err)
+	}
+
+	wt, err := pool.Acquire(context.Background(), "origin/main")
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if wt.Path != filepath.Join(rigPath, "refinery", "pool", "0") {
+		t.Errorf("Path = %s", wt.Path)
+	}
+
+	// Leave the checkout dirty: modified tracked file and an untracked file
+	if err := os.WriteFile(filepath.Join(wt.Path, "README.md"), []byte("dirty"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wt.Path, "junk.txt"), []byte("junk"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wt.Release()
+
+	wt, err = pool.Acquire(context.Background(), "origin/main")
+	if err != nil {
+		t.Fatalf("second Acquire: %v", err)
+	}
+	defer wt.Release()
+	data, _ := os.ReadFile(filepath.Join(wt.Path, "README.md"))
+	if string(data) != "# Test\n" {
+		t.Errorf("README.md = %q, want reset content", data)
+	}
+	if _, err := os.Stat(filepath.Join(wt.Path, "junk.txt")); !os.IsNotExist(err) {
+		t.Error("untracked file should be cleaned")
+	}
+}
+
+func TestWorktreePool_RebuildsBrokenWorktree(t *testing.T) {
+	rigPath, _ := setupPoolRig(t)
+	pool, err := NewWorktreePool(rigPath, 1)
+	if err != nil {
+		t.Fatalf("NewWorktreePool: %v", err)
+	}
+	if err := pool.Provision("origin/main"); err != nil {
+		t.Fatalf("Provision: %v", err)
+	}
+
+	// Corrupt the worktree's link to the repo
+	path := filepath.Join(pool.Dir(), "0")
+	if err := os.WriteFile(filepath.Join(path, ".git"), []byte("gitdir: /nonexistent\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wt, err := pool.Acquire(context.Background(), "origin/main")
+	if err != nil {
+		t.Fatalf("Acquire should rebuild broken worktree: %v", err)
+	}
+	defer wt.Release()
+	if _, err := wt.Git.Rev("HEAD"); err != nil {
+		t.Errorf("rebuilt worktree unusable: %v", err)
+	}
+}
+
+func TestWorktreePool_AcquireBlocksWhenExhausted(t *testing.T) {
+	rigPath, _ := setupPoolRig(t)
+	pool, err := NewWorktreePool(rigPath, 2)
+	if err != nil {
+		t.Fatalf("NewWorktreePool: %v", err)
+	}
+
+	a, err := pool.Acquire(context.Background(), "origin/main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := pool.Acquire(context.Background(), "origin/main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Path == b.Path {
+		t.Errorf("both acquisitions got %s", a.Path)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Acquire(ctx, "origin/main"); err == nil {
+		t.Error("Acquire should fail when the pool is exhausted and ctx expires")
+	}
+
+	a.Release()
+	c, err := pool.Acquire(context.Background(), "origin/main")
+	if err != nil {
+		t.Fatalf("Acquire after release: %v", err)
+	}
+	c.Release()
+	b.Release()
+}
+
+func TestEngineer_ProcessMRInfosPooled(t *testing.T) {
+	rigPath, origin := setupPoolRig(t)
+	addPolecatBranch(t, rigPath, "polecat/a", "a.txt", "a\n")
+	addPolecatBranch(t, rigPath, "polecat/b", "b.txt", "b\n")
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: rigPath})
+	e.SetOutput(io.Discard)
+	e.config.WorktreePool = true
+	e.config.MaxConcurrent = 2
+	e.config.TestCommand = "test -f README.md"
+
+	results := e.ProcessMRInfos(context.Background(), []*MRInfo{
+		{ID: "mr-a", Branch: "polecat/a", Target: "main"},
+		{ID: "mr-b", Branch: "polecat/b", Target: "main"},
+	})
+	for i, r := range results {
+		if !r.Success {
+			t.Errorf("result %d failed: %s", i, r.Error)
+		}
+	}
+
+	// Both branches landed on origin/main
+	files := runGit(t, origin, "ls-tree", "--name-only", "main")
+	for _, f := range []string{"a.txt", "b.txt"} {
+		if !strings.Contains(files, f) {
+			t.Errorf("origin/main missing %s; tree: %s", f, files)
+		}
+	}
+}
+
+func TestEngineer_PooledMergeConflict(t *testing.T) {
+	rigPath, _ := setupPoolRig(t)
+	addPolecatBranch(t, rigPath, "polecat/a", "same.txt", "a\n")
+	addPolecatBranch(t, rigPath, "polecat/b", "same.txt", "b\n")
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: rigPath})
+	e.SetOutput(io.Discard)
+	e.config.WorktreePool = true
+	e.config.RunTests = false
+
+	if r := e.ProcessMRInfo(context.Background(), &MRInfo{Branch: "polecat/a", Target: "main"}); !r.Success {
+		t.Fatalf("first merge failed: %s", r.Error)
+	}
+	r := e.ProcessMRInfo(context.Background(), &MRInfo{Branch: "polecat/b", Target: "main"})
+	if r.Success || !r.Conflict {
+		t.Errorf("second merge = %+v, want conflict", r)
+	}
+
+	// The pool worktree is usable again after the conflict
+	if r := e.ProcessMRInfo(context.Background(), &MRInfo{Branch: "polecat/a", Target: "main"}); !r.Success {
+		t.Errorf("merge after conflict failed: %s", r.Error)
+	}
+}
+
+func TestEngineer_PooledPartialMR(t *testing.T) {
+	rigPath, origin := setupPoolRig(t)
+	wt := addPolecatBranch(t, rigPath, "polecat/a", "a.txt", "a\n")
+	landed := runGit(t, wt, "rev-parse", "HEAD")
+	if err := os.WriteFile(filepath.Join(wt, "later.txt"), []byte("later\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, wt, "add", ".")
+	runGit(t, wt, "commit", "-m", "work in progress")
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: rigPath})
+	e.SetOutput(io.Discard)
+	e.config.WorktreePool = true
+	e.config.RunTests = false
+
+	r := e.ProcessMRInfo(context.Background(), &MRInfo{Branch: "polecat/a", Target: "main", Commits: []string{landed}})
+	if !r.Success {
+		t.Fatalf("partial merge failed: %s", r.Error)
+	}
+
+	// Only the selected commit landed
+	files := runGit(t, origin, "ls-tree", "--name-only", "main")
+	if !strings.Contains(files, "a.txt") || strings.Contains(files, "later.txt") {
+		t.Errorf("origin/main tree = %q, want a.txt without later.txt", files)
+	}
+	if msg := runGit(t, origin, "log", "-1", "--format=%B", "main"); !strings.Contains(msg, "cherry picked from commit "+landed) {
+		t.Errorf("landed commit message missing cherry-pick trailer:\n%s", msg)
+	}
+}
+
+func TestEngineer_ProvisionPool(t *testing.T) {
+	rigPath, _ := setupPoolRig(t)
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: rigPath})
+	e.SetOutput(io.Discard)
+	poolDir := filepath.Join(rigPath, "refinery", "pool")
+
+	// Disabled: nothing is checked out
+	if err := e.ProvisionPool(); err != nil {
+		t.Fatalf("ProvisionPool (disabled): %v", err)
+	}
+	if _, err := os.Stat(poolDir); !os.IsNotExist(err) {
+		t.Errorf("pool dir exists with the pool disabled: %v", err)
+	}
+
+	e.config.WorktreePool = true
+	e.config.MaxConcurrent = 2
+	if err := e.ProvisionPool(); err != nil {
+		t.Fatalf("ProvisionPool: %v", err)
+	}
+	for _, slot := range []string{"0", "1"} {
+		if _, err := os.Stat(filepath.Join(poolDir, slot, "README.md")); err != nil {
+			t.Errorf("pool worktree %s not provisioned: %v", slot, err)
+		}
+	}
+}
+
+func TestEngineer_PooledMergeBusyTarget(t *testing.T) {
+	// Four workers merge at once; each push moves origin/main under the
+	// others, and every MR must still land rather than fail
+	rigPath, origin := setupPoolRig(t)
+	var mrs []*MRInfo
+	for _, name := range []string{"a", "b", "c", "d"} {
+		addPolecatBranch(t, rigPath, "polecat/"+name, name+".txt", name+"\n")
+		mrs = append(mrs, &MRInfo{ID: "mr-" + name, Branch: "polecat/" + name, Target: "main"})
+	}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: rigPath})
+	e.SetOutput(io.Discard)
+	e.config.WorktreePool = true
+	e.config.MaxConcurrent = 4
+	e.config.TestCommand = "sleep 0.2"
+
+	for i, r := range e.ProcessMRInfos(context.Background(), mrs) {
+		if !r.Success {
+			t.Errorf("%s = %+v, want merged", mrs[i].ID, r)
+		}
+	}
+	files := runGit(t, origin, "ls-tree", "--name-only", "main")
+	for _, f := range []string{"a.txt", "b.txt", "c.txt", "d.txt"} {
+		if !strings.Contains(files, f) {
+			t.Errorf("origin/main missing %s; tree: %s", f, files)
+		}
+	}
+}
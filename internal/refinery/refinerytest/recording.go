@@ -0,0 +1,128 @@
+package refinerytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Test outcomes for ScriptedMR.Tests.
+const (
+	TestPass = "pass"
+	TestFail = "fail"
+)
+
+// Duration is a time.Duration that reads and writes JSON as a duration
+// string ("30m", "1h30m").
+type Duration time.Duration
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30m\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Recording is a queue to replay: the MRs waiting at Start and those that
+// arrive later, with scripted test and conflict outcomes.
+type Recording struct {
+	Rig    string    `json:"rig,omitempty"`
+	Target string    `json:"target,omitempty"`
+	Start  time.Time `json:"start"` // Simulated clock at the start of the run
+
+	// TestDuration is how long one test run takes, unless an MR overrides it.
+	TestDuration Duration `json:"test_duration"`
+
+	// ResolveAfter is how long a conflict-resolution task stays open before
+	// the MR is rebased and back in the queue, unless an MR overrides it.
+	ResolveAfter Duration `json:"resolve_after"`
+
+	MRs []ScriptedMR `json:"mrs"`
+}
+
+// ScriptedMR is one MR in a recording.
+type ScriptedMR struct {
+	ID       string `json:"id"`
+	Branch   string `json:"branch,omitempty"`
+	Priority int    `json:"priority"`
+
+	// SubmittedAt is when the MR entered the queue, relative to the
+	// recording's Start; negative for MRs already waiting.
+	SubmittedAt Duration `json:"submitted_at"`
+
+	// ConvoyAge is the age of the MR's convoy at Start (nil: no convoy).
+	ConvoyAge *Duration `json:"convoy_age,omitempty"`
+
+	RetryCount int `json:"retry_count,omitempty"`
+
+	// Files are the files the branch changes. The branch conflicts with any
+	// MR landed after it forked that touched one of them.
+	Files []string `json:"files,omitempty"`
+
+	// Conflicts forces the first Conflicts merge attempts to conflict
+	// regardless of Files (e.g. a branch already stale when recorded).
+	Conflicts int `json:"conflicts,omitempty"`
+
+	// Tests scripts the outcome of each test run, TestPass or TestFail, in
+	// order across all attempts; the last entry repeats. Empty: always pass.
+	Tests []string `json:"tests,omitempty"`
+
+	TestDuration *Duration `json:"test_duration,omitempty"`
+	ResolveAfter *Duration `json:"resolve_after,omitempty"`
+}
+
+// Validate checks the recording for duplicate IDs and unknown test outcomes.
+func (r *Recording) Validate() error {
+	seen := make(map[string]bool, len(r.MRs))
+	for _, mr := range r.MRs {
+		if mr.ID == "" {
+			return fmt.Errorf("MR with no id")
+		}
+		if seen[mr.ID] {
+			return fmt.Errorf("duplicate MR %s", mr.ID)
+		}
+		seen[mr.ID] = true
+		for _, t := range mr.Tests {
+			if t != TestPass && t != TestFail {
+				return fmt.Errorf("MR %s: test outcome %q (want %q or %q)", mr.ID, t, TestPass, TestFail)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadRecording reads a recording from path.
+func LoadRecording(path string) (*Recording, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is from the user
+	if err != nil {
+		return nil, err
+	}
+	var rec Recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := rec.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &rec, nil
+}
+
+// Save writes the recording to path.
+func (r *Recording) Save(path string) error {
+	return util.AtomicWriteJSON(path, r)
+}
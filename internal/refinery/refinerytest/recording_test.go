@@ -0,0 +1,67 @@
+package refinerytest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecording_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	rec := &Recording{
+		Rig:          "gastown",
+		Target:       "main",
+		Start:        time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		TestDuration: Duration(12 * time.Minute),
+		ResolveAfter: Duration(time.Hour),
+		MRs: []ScriptedMR{{
+			ID:          "gt-mr1",
+			Branch:      "polecat/nux",
+			Priority:    1,
+			SubmittedAt: Duration(-90 * time.Minute),
+			ConvoyAge:   dur(26 * time.Hour),
+			Files:       []string{"a.go"},
+			Tests:       []string{TestFail, TestPass},
+		}},
+	}
+	if err := rec.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"submitted_at": "-1h30m0s"`) {
+		t.Errorf("durations not written as strings:\n%s", data)
+	}
+
+	got, err := LoadRecording(path)
+	if err != nil {
+		t.Fatalf("LoadRecording: %v", err)
+	}
+	if !reflect.DeepEqual(got, rec) {
+		t.Errorf("round trip:\n got %+v\nwant %+v", got, rec)
+	}
+}
+
+func TestLoadRecording_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"bad duration": `{"test_duration": 600}`,
+		"duplicate":    `{"mrs": [{"id": "a"}, {"id": "a"}]}`,
+		"bad outcome":  `{"mrs": [{"id": "a", "tests": ["flaky"]}]}`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "queue.json")
+			if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadRecording(path); err == nil {
+				t.Error("LoadRecording should fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Package refinerytest simulates a rig's merge queue without real
+// repositories, so queue behaviors (ordering and priority aging, conflict
+// handling, merge-slot contention, pooled "merge trains" that rebuild when
+// the target moves) can be exercised deterministically and proposed
+// merge_queue config changes can be tried against a recorded queue before
+// they are rolled out.
+//
+// The simulator reuses the Refinery's own policy (refinery.RankMRs,
+// MergeQueueConfig.Concurrency, MergeSlotTTL, PooledMergeAttempts); git and
+// test runs are replaced by a Repo and scripted outcomes.
+package refinerytest
+
+import "sort"
+
+// Change is one commit landed on the target branch.
+type Change struct {
+	MR    string   // MR that landed it ("" for a direct push)
+	Files []string // Files it touched
+}
+
+// Repo is a fake git remote holding a single target branch. Its history is
+// the list of landed changes; a version is a position in that history, so
+// version n is the tip after n changes.
+//
+// Conflicts are modelled by file: a branch forked at version v conflicts with
+// the tip if a change landed after v touched one of the branch's files.
+type Repo struct {
+	history []Change
+}
+
+// NewRepo returns an empty repo at version 0.
+func NewRepo() *Repo {
+	return &Repo{}
+}
+
+// Tip returns the current version of the target branch.
+func (r *Repo) Tip() int {
+	return len(r.history)
+}
+
+// Push lands a change on the target branch and returns the new tip.
+func (r *Repo) Push(mr string, files ...string) int {
+	r.history = append(r.history, Change{MR: mr, Files: files})
+	return r.Tip()
+}
+
+// Landed returns the MRs landed so far, in order.
+func (r *Repo) Landed() []string {
+	var ids []string
+	for _, c := range r.history {
+		if c.MR != "" {
+			ids = append(ids, c.MR)
+		}
+	}
+	return ids
+}
+
+// Conflicts returns the files a branch forked at base and touching files
+// conflicts on when merged onto the tip, sorted; nil means it merges cleanly.
+func (r *Repo) Conflicts(base int, files []string) []string {
+	if base < 0 {
+		base = 0
+	}
+	touched := make(map[string]bool)
+	for _, c := range r.history[min(base, len(r.history)):] {
+		for _, f := range c.Files {
+			touched[f] = true
+		}
+	}
+	var conflicts []string
+	seen := make(map[string]bool)
+	for _, f := range files {
+		if touched[f] && !seen[f] {
+			conflicts = append(conflicts, f)
+			seen[f] = true
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
@@ -0,0 +1,516 @@
+package refinerytest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/refinery"
+)
+
+// DefaultHorizon bounds a simulation that never drains (e.g. an MR whose
+// target keeps moving forever).
+const DefaultHorizon = 7 * 24 * time.Hour
+
+// Outcome is where an MR ended up when the simulation stopped.
+type Outcome string
+
+const (
+	OutcomeMerged  Outcome = "merged"
+	OutcomeFailed  Outcome = "failed"  // Tests failed; sent back to the polecat
+	OutcomePending Outcome = "pending" // Still queued or blocked at the horizon
+)
+
+// Event kinds.
+const (
+	EventStart    = "start"    // A worker picked the MR
+	EventConflict = "conflict" // Merge conflicted; blocked on a resolution task
+	EventDeferred = "deferred" // Merge conflicted while the merge slot was held
+	EventResolved = "resolved" // Resolution task closed; branch rebased
+	EventTests    = "tests"    // Tests passed after retrying a flaky failure
+	EventRebuild  = "rebuild"  // Target moved during the test run; rebuilding
+	EventRequeued = "requeued" // Target kept moving; back in the queue
+	EventMerged   = "merged"
+	EventFailed   = "failed"
+)
+
+// Options tune a simulation.
+type Options struct {
+	// Score weights MR ordering. Zero value: refinery.DefaultScoreConfig().
+	Score refinery.ScoreConfig
+
+	// Horizon stops the simulation this long after the recording's Start.
+	// Zero: DefaultHorizon.
+	Horizon time.Duration
+}
+
+// Event is one step of a simulation.
+type Event struct {
+	At     Duration `json:"at"` // Since the recording's Start
+	MR     string   `json:"mr"`
+	Kind   string   `json:"kind"`
+	Detail string   `json:"detail,omitempty"`
+}
+
+// String formats the event as a log line.
+func (e Event) String() string {
+	s := fmt.Sprintf("+%-8s %-10s %s", time.Duration(e.At), e.Kind, e.MR)
+	if e.Detail != "" {
+		s += "  " + e.Detail
+	}
+	return s
+}
+
+// Result is one MR's fate in a simulation.
+type Result struct {
+	ID        string   `json:"id"`
+	Outcome   Outcome  `json:"outcome"`
+	Position  int      `json:"position,omitempty"` // 1-based merge order, for merged MRs
+	Wait      Duration `json:"wait"`               // From submission until merged, failed, or the end
+	Attempts  int      `json:"attempts"`           // Times a worker picked the MR
+	Conflicts int      `json:"conflicts"`
+	Deferrals int      `json:"deferrals"` // Conflicts deferred because the merge slot was held
+	TestRuns  int      `json:"test_runs"`
+	Rebuilds  int      `json:"rebuilds"`
+}
+
+// Report summarizes a simulation.
+type Report struct {
+	Workers    int      `json:"workers"`
+	Results    []Result `json:"results"` // In recording order
+	MergeOrder []string `json:"merge_order"`
+
+	Merged        int `json:"merged"`
+	Failed        int `json:"failed"`
+	Pending       int `json:"pending"`
+	Conflicts     int `json:"conflicts"`
+	SlotDeferrals int `json:"slot_deferrals"`
+	TestRuns      int `json:"test_runs"`
+	Rebuilds      int `json:"rebuilds"`
+
+	Makespan Duration `json:"makespan"`  // From Start until the last MR merged or failed
+	MeanWait Duration `json:"mean_wait"` // Over merged MRs
+	MaxWait  Duration `json:"max_wait"`  // Over merged MRs
+
+	Events []Event `json:"events"`
+}
+
+// Result returns the result for the MR with the given ID, or nil.
+func (r *Report) Result(id string) *Result {
+	for i := range r.Results {
+		if r.Results[i].ID == id {
+			return &r.Results[i]
+		}
+	}
+	return nil
+}
+
+// Simulate replays rec through a merge queue configured by cfg.
+//
+// The model follows the Refinery:
+//   - Ready MRs are taken highest score first (refinery.RankMRs at the
+//     simulated time, so waiting MRs age), by cfg.Concurrency() workers.
+//   - A merge conflicts if a change landed since the branch forked touched
+//     one of its files (or a scripted conflict remains). The MR then takes
+//     the merge slot and is blocked on a resolution task for ResolveAfter,
+//     after which it is rebased onto the tip and retried. If another MR
+//     holds the slot (until a merge lands or MergeSlotTTL passes), the
+//     conflict is deferred and the MR retried after cfg.PollInterval.
+//   - With cfg.RunTests and a TestCommand, each attempt runs tests up to
+//     max(RetryFlakyTests, 1) times; an attempt whose runs all fail sends
+//     the MR back to its polecat (failed).
+//   - If the target moved while a pooled merge was tested, the merge is
+//     rebuilt on the new tip and re-tested, up to PooledMergeAttempts times,
+//     then requeued.
+//
+// Simulate is deterministic: the same inputs give the same report.
+func Simulate(rec *Recording, cfg *refinery.MergeQueueConfig, opts Options) *Report {
+	s := newSim(rec, cfg, opts)
+	s.run()
+	return s.report()
+}
+
+type simMR struct {
+	spec   *ScriptedMR
+	info   *refinery.MRInfo
+	result *Result
+
+	submitAt time.Time
+	arrived  bool
+	base     int // Target version the branch forked from
+
+	readyAt      time.Time // Deferred until
+	blocked      bool      // Waiting on a conflict-resolution task
+	blockedUntil time.Time
+	running      bool
+	done         bool
+	finishedAt   time.Time
+
+	conflictsLeft int // Scripted conflicts still to come
+	testIdx       int // Next entry of spec.Tests
+}
+
+type job struct {
+	mr     *simMR
+	base   int // Target version the merge was built on
+	doneAt time.Time
+	round  int // Build attempt, 1..PooledMergeAttempts
+	runs   int
+	passed bool
+}
+
+type sim struct {
+	rec     *Recording
+	cfg     *refinery.MergeQueueConfig
+	score   refinery.ScoreConfig
+	workers int
+	poll    time.Duration
+
+	repo    *Repo
+	start   time.Time
+	end     time.Time
+	now     time.Time
+	mrs     []*simMR
+	running []*job
+
+	slotHolder  string
+	slotExpires time.Time
+
+	events     []Event
+	mergeOrder []string
+}
+
+func newSim(rec *Recording, cfg *refinery.MergeQueueConfig, opts Options) *sim {
+	s := &sim{
+		rec:     rec,
+		cfg:     cfg,
+		score:   opts.Score,
+		workers: cfg.Concurrency(),
+		poll:    cfg.PollInterval,
+		repo:    NewRepo(),
+		start:   rec.Start,
+	}
+	if s.score == (refinery.ScoreConfig{}) {
+		s.score = refinery.DefaultScoreConfig()
+	}
+	if s.poll <= 0 {
+		s.poll = refinery.DefaultMergeQueueConfig().PollInterval
+	}
+	if s.start.IsZero() {
+		s.start = time.Unix(0, 0).UTC()
+	}
+	horizon := opts.Horizon
+	if horizon <= 0 {
+		horizon = DefaultHorizon
+	}
+	s.end = s.start.Add(horizon)
+	s.now = s.start
+
+	for i := range rec.MRs {
+		spec := &rec.MRs[i]
+		submitAt := s.start.Add(time.Duration(spec.SubmittedAt))
+		info := &refinery.MRInfo{
+			ID:         spec.ID,
+			Branch:     spec.Branch,
+			Target:     rec.Target,
+			Rig:        rec.Rig,
+			Priority:   spec.Priority,
+			RetryCount: spec.RetryCount,
+			CreatedAt:  submitAt,
+		}
+		if spec.ConvoyAge != nil {
+			convoyAt := s.start.Add(-time.Duration(*spec.ConvoyAge))
+			info.ConvoyCreatedAt = &convoyAt
+		}
+		s.mrs = append(s.mrs, &simMR{
+			spec:          spec,
+			info:          info,
+			result:        &Result{ID: spec.ID, Outcome: OutcomePending},
+			submitAt:      submitAt,
+			conflictsLeft: spec.Conflicts,
+		})
+	}
+	return s
+}
+
+func (s *sim) run() {
+	for {
+		s.arrive()
+		s.dispatch()
+		next, ok := s.next()
+		if !ok {
+			return
+		}
+		if next.After(s.end) {
+			s.now = s.end
+			return
+		}
+		s.now = next
+		s.finish()
+	}
+}
+
+// arrive admits MRs submitted by now and unblocks MRs whose resolution
+// task is done.
+func (s *sim) arrive() {
+	for _, mr := range s.mrs {
+		if !mr.arrived && !mr.submitAt.After(s.now) {
+			// Branches waiting at Start forked from the initial tip;
+			// later ones from the tip when they were submitted
+			mr.arrived = true
+			mr.base = s.repo.Tip()
+		}
+		if mr.blocked && !mr.blockedUntil.After(s.now) {
+			mr.blocked = false
+			mr.base = s.repo.Tip()
+			mr.info.RetryCount++
+			if mr.conflictsLeft > 0 {
+				mr.conflictsLeft--
+			}
+			s.event(mr, EventResolved, fmt.Sprintf("rebased onto v%d", mr.base))
+		}
+	}
+}
+
+// dispatch hands ready MRs to free workers, highest score first.
+func (s *sim) dispatch() {
+	for len(s.running) < s.workers {
+		var ready []*refinery.MRInfo
+		byInfo := make(map[*refinery.MRInfo]*simMR)
+		for _, mr := range s.mrs {
+			if mr.arrived && !mr.done && !mr.running && !mr.blocked && !mr.readyAt.After(s.now) {
+				ready = append(ready, mr.info)
+				byInfo[mr.info] = mr
+			}
+		}
+		if len(ready) == 0 {
+			return
+		}
+		refinery.RankMRs(ready, s.now, s.score)
+		s.startMR(byInfo[ready[0]])
+	}
+}
+
+func (s *sim) startMR(mr *simMR) {
+	mr.result.Attempts++
+	s.event(mr, EventStart, fmt.Sprintf("onto v%d (score %.0f)", s.repo.Tip(), refinery.ScoreMR(refinery.ScoreInput{
+		Priority:        mr.info.Priority,
+		MRCreatedAt:     mr.info.CreatedAt,
+		ConvoyCreatedAt: mr.info.ConvoyCreatedAt,
+		RetryCount:      mr.info.RetryCount,
+		Now:             s.now,
+	}, s.score)))
+	if files := s.conflicts(mr); len(files) > 0 {
+		s.conflict(mr, files)
+		return
+	}
+	s.build(mr, 1)
+}
+
+// build merges mr onto the tip and runs its tests on a worker.
+func (s *sim) build(mr *simMR, round int) {
+	j := &job{mr: mr, base: s.repo.Tip(), round: round, passed: true, doneAt: s.now}
+	if s.cfg.RunTests && s.cfg.TestCommand != "" {
+		maxRuns := s.cfg.RetryFlakyTests
+		if maxRuns < 1 {
+			maxRuns = 1
+		}
+		testDuration := time.Duration(s.rec.TestDuration)
+		if mr.spec.TestDuration != nil {
+			testDuration = time.Duration(*mr.spec.TestDuration)
+		}
+		j.passed = false
+		for j.runs < maxRuns && !j.passed {
+			j.runs++
+			j.doneAt = j.doneAt.Add(testDuration)
+			j.passed = mr.nextTest() == TestPass
+		}
+		mr.result.TestRuns += j.runs
+	}
+	mr.running = true
+	s.running = append(s.running, j)
+}
+
+// next returns the time of the next event, if any.
+func (s *sim) next() (time.Time, bool) {
+	var next time.Time
+	consider := func(t time.Time) {
+		if next.IsZero() || t.Before(next) {
+			next = t
+		}
+	}
+	for _, j := range s.running {
+		consider(j.doneAt)
+	}
+	for _, mr := range s.mrs {
+		switch {
+		case mr.done || mr.running:
+		case !mr.arrived:
+			consider(mr.submitAt)
+		case mr.blocked:
+			consider(mr.blockedUntil)
+		case mr.readyAt.After(s.now):
+			consider(mr.readyAt)
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// finish completes the jobs due by now, in the order they started.
+func (s *sim) finish() {
+	var still []*job
+	var due []*job
+	for _, j := range s.running {
+		if j.doneAt.After(s.now) {
+			still = append(still, j)
+		} else {
+			due = append(due, j)
+		}
+	}
+	s.running = still
+
+	for _, j := range due {
+		mr := j.mr
+		mr.running = false
+
+		if !j.passed {
+			s.done(mr, OutcomeFailed)
+			s.event(mr, EventFailed, fmt.Sprintf("tests failed (%d run(s))", j.runs))
+			continue
+		}
+		if j.runs > 1 {
+			s.event(mr, EventTests, fmt.Sprintf("passed on run %d", j.runs))
+		}
+
+		if tip := s.repo.Tip(); tip != j.base {
+			// Another merge landed while this one was tested
+			if files := s.conflicts(mr); len(files) > 0 {
+				s.conflict(mr, files)
+				continue
+			}
+			if j.round < refinery.PooledMergeAttempts {
+				mr.result.Rebuilds++
+				s.event(mr, EventRebuild, fmt.Sprintf("target moved v%d→v%d (attempt %d/%d)",
+					j.base, tip, j.round+1, refinery.PooledMergeAttempts))
+				s.build(mr, j.round+1)
+				continue
+			}
+			mr.readyAt = s.now.Add(s.poll)
+			s.event(mr, EventRequeued, fmt.Sprintf("target kept moving; gave up after %d attempts", refinery.PooledMergeAttempts))
+			continue
+		}
+
+		version := s.repo.Push(mr.spec.ID, mr.spec.Files...)
+		s.mergeOrder = append(s.mergeOrder, mr.spec.ID)
+		mr.result.Position = len(s.mergeOrder)
+		// Any successful merge releases the merge slot
+		s.slotHolder = ""
+		s.done(mr, OutcomeMerged)
+		s.event(mr, EventMerged, fmt.Sprintf("as v%d", version))
+	}
+}
+
+// conflicts returns the files mr conflicts on if merged now.
+func (s *sim) conflicts(mr *simMR) []string {
+	if mr.conflictsLeft > 0 {
+		return []string{"(scripted)"}
+	}
+	return s.repo.Conflicts(mr.base, mr.spec.Files)
+}
+
+// conflict handles a conflicted merge: take the merge slot and block the MR
+// on a resolution task, or defer if the slot is held.
+func (s *sim) conflict(mr *simMR, files []string) {
+	mr.result.Conflicts++
+	id := mr.spec.ID
+	if s.slotHolder != "" && s.slotHolder != id && s.now.Before(s.slotExpires) {
+		mr.result.Deferrals++
+		mr.readyAt = s.now.Add(s.poll)
+		s.event(mr, EventDeferred, fmt.Sprintf("merge slot held by %s", s.slotHolder))
+		return
+	}
+	s.slotHolder = id
+	s.slotExpires = s.now.Add(refinery.MergeSlotTTL)
+
+	resolveAfter := time.Duration(s.rec.ResolveAfter)
+	if mr.spec.ResolveAfter != nil {
+		resolveAfter = time.Duration(*mr.spec.ResolveAfter)
+	}
+	mr.blocked = true
+	mr.blockedUntil = s.now.Add(resolveAfter)
+	s.event(mr, EventConflict, fmt.Sprintf("in %s; resolving for %s", strings.Join(files, ", "), resolveAfter))
+}
+
+func (s *sim) done(mr *simMR, outcome Outcome) {
+	mr.done = true
+	mr.finishedAt = s.now
+	mr.result.Outcome = outcome
+}
+
+func (s *sim) event(mr *simMR, kind, detail string) {
+	s.events = append(s.events, Event{
+		At:     Duration(s.now.Sub(s.start)),
+		MR:     mr.spec.ID,
+		Kind:   kind,
+		Detail: detail,
+	})
+}
+
+// nextTest returns the MR's next scripted test outcome.
+func (mr *simMR) nextTest() string {
+	tests := mr.spec.Tests
+	if len(tests) == 0 {
+		return TestPass
+	}
+	i := min(mr.testIdx, len(tests)-1)
+	mr.testIdx++
+	return tests[i]
+}
+
+func (s *sim) report() *Report {
+	rep := &Report{
+		Workers:    s.workers,
+		MergeOrder: s.mergeOrder,
+		Events:     s.events,
+	}
+	var last time.Time
+	var totalWait time.Duration
+	for _, mr := range s.mrs {
+		res := mr.result
+		end := s.now
+		if mr.done {
+			end = mr.finishedAt
+			if end.After(last) {
+				last = end
+			}
+		}
+		if mr.arrived {
+			res.Wait = Duration(end.Sub(mr.submitAt))
+		}
+
+		switch res.Outcome {
+		case OutcomeMerged:
+			rep.Merged++
+			totalWait += time.Duration(res.Wait)
+			if res.Wait > rep.MaxWait {
+				rep.MaxWait = res.Wait
+			}
+		case OutcomeFailed:
+			rep.Failed++
+		default:
+			rep.Pending++
+		}
+		rep.Conflicts += res.Conflicts
+		rep.SlotDeferrals += res.Deferrals
+		rep.TestRuns += res.TestRuns
+		rep.Rebuilds += res.Rebuilds
+		rep.Results = append(rep.Results, *res)
+	}
+	if !last.IsZero() {
+		rep.Makespan = Duration(last.Sub(s.start))
+	}
+	if rep.Merged > 0 {
+		rep.MeanWait = Duration(totalWait / time.Duration(rep.Merged))
+	}
+	return rep
+}
@@ -0,0 +1,286 @@
+package refinerytest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/refinery"
+)
+
+func dur(d time.Duration) *Duration {
+	v := Duration(d)
+	return &v
+}
+
+// testConfig returns a sequential queue that runs tests.
+func testConfig() *refinery.MergeQueueConfig {
+	cfg := refinery.DefaultMergeQueueConfig()
+	cfg.TestCommand = "make test"
+	return cfg
+}
+
+func pooled(workers int) *refinery.MergeQueueConfig {
+	cfg := testConfig()
+	cfg.WorktreePool = true
+	cfg.MaxConcurrent = workers
+	return cfg
+}
+
+func TestRepo_Conflicts(t *testing.T) {
+	r := NewRepo()
+	r.Push("a", "x.go", "y.go")
+	base := r.Tip()
+	r.Push("b", "z.go")
+
+	if got := r.Conflicts(0, []string{"y.go", "w.go", "x.go"}); !reflect.DeepEqual(got, []string{"x.go", "y.go"}) {
+		t.Errorf("Conflicts(0) = %v", got)
+	}
+	if got := r.Conflicts(base, []string{"x.go"}); got != nil {
+		t.Errorf("Conflicts after a = %v, want none", got)
+	}
+	if got := r.Landed(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Landed = %v", got)
+	}
+}
+
+func TestSimulate_PriorityOrder(t *testing.T) {
+	rec := &Recording{
+		TestDuration: Duration(10 * time.Minute),
+		MRs: []ScriptedMR{
+			{ID: "p2", Priority: 2, Files: []string{"a"}},
+			{ID: "p0", Priority: 0, Files: []string{"b"}},
+			{ID: "p1", Priority: 1, Files: []string{"c"}},
+		},
+	}
+	rep := Simulate(rec, testConfig(), Options{})
+
+	if !reflect.DeepEqual(rep.MergeOrder, []string{"p0", "p1", "p2"}) {
+		t.Errorf("MergeOrder = %v", rep.MergeOrder)
+	}
+	if rep.Merged != 3 || rep.TestRuns != 3 || rep.Conflicts != 0 {
+		t.Errorf("Merged=%d TestRuns=%d Conflicts=%d", rep.Merged, rep.TestRuns, rep.Conflicts)
+	}
+	if time.Duration(rep.Makespan) != 30*time.Minute {
+		t.Errorf("Makespan = %v, want 30m", time.Duration(rep.Makespan))
+	}
+	if time.Duration(rep.Result("p2").Wait) != 30*time.Minute {
+		t.Errorf("p2 waited %v, want 30m", time.Duration(rep.Result("p2").Wait))
+	}
+}
+
+func TestSimulate_PriorityAging(t *testing.T) {
+	// A P3 waits while a P1 arrives every hour; each takes an hour to test
+	rec := &Recording{TestDuration: Duration(time.Hour)}
+	rec.MRs = append(rec.MRs, ScriptedMR{ID: "old", Priority: 3, Files: []string{"old"}})
+	for i, id := range []string{"n1", "n2", "n3", "n4", "n5"} {
+		rec.MRs = append(rec.MRs, ScriptedMR{
+			ID: id, Priority: 1, Files: []string{id},
+			SubmittedAt: Duration(time.Duration(i) * time.Hour),
+		})
+	}
+
+	rep := Simulate(rec, testConfig(), Options{})
+	if got := rep.Result("old").Position; got != 6 {
+		t.Errorf("default weights: old merged at %d, want last (6)", got)
+	}
+
+	// Weighting MR age heavily lets the old MR overtake the newer P1s
+	score := refinery.DefaultScoreConfig()
+	score.MRAgeWeight = 100
+	rep = Simulate(rec, testConfig(), Options{Score: score})
+	if got := rep.Result("old").Position; got >= 6 || got == 0 {
+		t.Errorf("aged: old merged at %d, want before the last P1", got)
+	}
+
+	// Convoy age counts from before the recording started
+	rec.MRs[0].ConvoyAge = dur(48 * time.Hour)
+	rep = Simulate(rec, testConfig(), Options{})
+	if got := rep.Result("old").Position; got != 1 {
+		t.Errorf("old convoy: merged at %d, want 1", got)
+	}
+}
+
+func TestSimulate_ConflictHandling(t *testing.T) {
+	rec := &Recording{
+		TestDuration: Duration(10 * time.Minute),
+		ResolveAfter: Duration(30 * time.Minute),
+		MRs: []ScriptedMR{
+			{ID: "a", Priority: 0, Files: []string{"x.go"}},
+			{ID: "b", Priority: 1, Files: []string{"x.go", "y.go"}},
+			{ID: "c", Priority: 2, Files: []string{"z.go"}},
+		},
+	}
+	rep := Simulate(rec, testConfig(), Options{})
+
+	// b conflicts on x.go after a lands; c proceeds while b is blocked
+	if !reflect.DeepEqual(rep.MergeOrder, []string{"a", "c", "b"}) {
+		t.Errorf("MergeOrder = %v", rep.MergeOrder)
+	}
+	b := rep.Result("b")
+	if b.Conflicts != 1 || b.Attempts != 2 || b.TestRuns != 1 {
+		t.Errorf("b = %+v", b)
+	}
+	// Conflict at 10m, resolved at 40m, tested until 50m
+	if time.Duration(b.Wait) != 50*time.Minute {
+		t.Errorf("b waited %v, want 50m", time.Duration(b.Wait))
+	}
+	if !hasEvent(rep, "b", EventConflict) || !hasEvent(rep, "b", EventResolved) {
+		t.Errorf("missing conflict/resolved events: %v", rep.Events)
+	}
+}
+
+func TestSimulate_ScriptedConflicts(t *testing.T) {
+	rec := &Recording{
+		ResolveAfter: Duration(time.Hour),
+		MRs:          []ScriptedMR{{ID: "stale", Conflicts: 2}},
+	}
+	rep := Simulate(rec, testConfig(), Options{})
+	res := rep.Result("stale")
+	if res.Outcome != OutcomeMerged || res.Conflicts != 2 || time.Duration(res.Wait) != 2*time.Hour {
+		t.Errorf("stale = %+v", res)
+	}
+}
+
+func TestSimulate_SlotContention(t *testing.T) {
+	rec := &Recording{
+		TestDuration: Duration(10 * time.Minute),
+		ResolveAfter: Duration(time.Hour),
+		MRs: []ScriptedMR{
+			{ID: "a", Priority: 0, Files: []string{"x.go", "y.go"}},
+			{ID: "b", Priority: 1, Files: []string{"x.go"}},
+			{ID: "c", Priority: 2, Files: []string{"y.go"}},
+		},
+	}
+	rep := Simulate(rec, testConfig(), Options{})
+
+	// b holds the merge slot while resolving; c's conflict waits for it
+	c := rep.Result("c")
+	if c.Deferrals == 0 || rep.SlotDeferrals != c.Deferrals {
+		t.Errorf("c deferrals = %d, report = %d", c.Deferrals, rep.SlotDeferrals)
+	}
+	if !reflect.DeepEqual(rep.MergeOrder, []string{"a", "b", "c"}) {
+		t.Errorf("MergeOrder = %v", rep.MergeOrder)
+	}
+	if c.Outcome != OutcomeMerged || c.Conflicts < 2 {
+		t.Errorf("c = %+v", c)
+	}
+	// c only starts resolving once b's merge frees the slot at 1h20m
+	if time.Duration(c.Wait) < 2*time.Hour+20*time.Minute {
+		t.Errorf("c waited %v, want at least 2h20m", time.Duration(c.Wait))
+	}
+}
+
+func TestSimulate_MergeTrain(t *testing.T) {
+	rec := &Recording{
+		TestDuration: Duration(10 * time.Minute),
+		MRs: []ScriptedMR{
+			{ID: "a", Priority: 0, Files: []string{"a"}},
+			{ID: "b", Priority: 1, Files: []string{"b"}},
+			{ID: "c", Priority: 2, Files: []string{"c"}},
+			{ID: "d", Priority: 3, Files: []string{"d"}},
+		},
+	}
+	rep := Simulate(rec, pooled(4), Options{})
+
+	if rep.Workers != 4 || rep.Merged != 4 {
+		t.Fatalf("Workers=%d Merged=%d", rep.Workers, rep.Merged)
+	}
+	if !reflect.DeepEqual(rep.MergeOrder, []string{"a", "b", "c", "d"}) {
+		t.Errorf("MergeOrder = %v", rep.MergeOrder)
+	}
+	// Each landing invalidates the merges tested alongside it: b rebuilds
+	// once, c twice, and d gives up after PooledMergeAttempts and requeues
+	if got := rep.Result("c").Rebuilds; got != 2 {
+		t.Errorf("c rebuilds = %d, want 2", got)
+	}
+	if !hasEvent(rep, "d", EventRequeued) {
+		t.Errorf("d was not requeued: %v", rep.Events)
+	}
+
+	// A rebuilt merge that now conflicts goes to conflict resolution
+	rec.MRs[1].Files = []string{"a"}
+	rep = Simulate(rec, pooled(2), Options{})
+	if res := rep.Result("b"); res.Conflicts != 1 || res.Rebuilds != 0 {
+		t.Errorf("b = %+v", res)
+	}
+}
+
+func TestSimulate_FlakyTests(t *testing.T) {
+	rec := &Recording{
+		TestDuration: Duration(10 * time.Minute),
+		MRs:          []ScriptedMR{{ID: "flaky", Tests: []string{TestFail, TestPass}}},
+	}
+
+	cfg := testConfig()
+	cfg.RetryFlakyTests = 2
+	rep := Simulate(rec, cfg, Options{})
+	if res := rep.Result("flaky"); res.Outcome != OutcomeMerged || res.TestRuns != 2 {
+		t.Errorf("with retry: %+v", res)
+	}
+
+	cfg.RetryFlakyTests = 1
+	rep = Simulate(rec, cfg, Options{})
+	if res := rep.Result("flaky"); res.Outcome != OutcomeFailed || rep.Failed != 1 {
+		t.Errorf("without retry: %+v", res)
+	}
+
+	// No test command: tests never run
+	cfg.TestCommand = ""
+	rep = Simulate(rec, cfg, Options{})
+	if rep.Merged != 1 || rep.TestRuns != 0 {
+		t.Errorf("no tests: Merged=%d TestRuns=%d", rep.Merged, rep.TestRuns)
+	}
+}
+
+func TestSimulate_Horizon(t *testing.T) {
+	rec := &Recording{
+		TestDuration: Duration(time.Hour),
+		MRs: []ScriptedMR{
+			{ID: "a", Files: []string{"a"}},
+			{ID: "b", Files: []string{"b"}},
+		},
+	}
+	rep := Simulate(rec, testConfig(), Options{Horizon: 90 * time.Minute})
+	if rep.Merged != 1 || rep.Pending != 1 || rep.Result("b").Outcome != OutcomePending {
+		t.Errorf("Merged=%d Pending=%d", rep.Merged, rep.Pending)
+	}
+}
+
+func TestSimulate_Deterministic(t *testing.T) {
+	rec := &Recording{
+		TestDuration: Duration(7 * time.Minute),
+		ResolveAfter: Duration(20 * time.Minute),
+	}
+	files := []string{"x", "y", "z"}
+	for i, id := range []string{"m1", "m2", "m3", "m4", "m5", "m6"} {
+		rec.MRs = append(rec.MRs, ScriptedMR{
+			ID:          id,
+			Priority:    2, // Equal scores: ties break by age, then ID
+			Files:       []string{files[i%len(files)]},
+			SubmittedAt: Duration(time.Duration(i%2) * time.Minute),
+			Tests:       []string{TestFail, TestPass},
+		})
+	}
+	cfg := pooled(3)
+	cfg.RetryFlakyTests = 2
+
+	first := Simulate(rec, cfg, Options{})
+	for i := 0; i < 5; i++ {
+		if again := Simulate(rec, cfg, Options{}); !reflect.DeepEqual(first, again) {
+			t.Fatalf("run %d differs:\n%v\n%v", i, first.Events, again.Events)
+		}
+	}
+	if first.Merged != len(rec.MRs) {
+		t.Errorf("Merged = %d, want %d", first.Merged, len(rec.MRs))
+	}
+}
+
+func hasEvent(rep *Report, mr, kind string) bool {
+	for _, e := range rep.Events {
+		if e.MR == mr && e.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
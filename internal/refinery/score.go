@@ -4,6 +4,7 @@
 package refinery
 
 import (
+	"sort"
 	"time"
 )
 
@@ -141,12 +142,36 @@ func (mr *MRInfo) Score() float64 {
 
 // ScoreAt calculates the priority score at a specific time (for deterministic testing).
 func (mr *MRInfo) ScoreAt(now time.Time) float64 {
-	input := ScoreInput{
+	return ScoreMRWithDefaults(mr.scoreInput(now))
+}
+
+func (mr *MRInfo) scoreInput(now time.Time) ScoreInput {
+	return ScoreInput{
 		Priority:        mr.Priority,
 		MRCreatedAt:     mr.CreatedAt,
 		ConvoyCreatedAt: mr.ConvoyCreatedAt,
 		RetryCount:      mr.RetryCount,
 		Now:             now,
 	}
-	return ScoreMRWithDefaults(input)
+}
+
+// RankMRs sorts mrs into processing order: highest score at now first,
+// then oldest, then by ID, so equal scores always rank the same way. The
+// Refinery claims ready MRs in this order (see ProcessReady), and the
+// queue simulator (package refinerytest) models it the same way.
+func RankMRs(mrs []*MRInfo, now time.Time, config ScoreConfig) {
+	scores := make(map[*MRInfo]float64, len(mrs))
+	for _, mr := range mrs {
+		scores[mr] = ScoreMR(mr.scoreInput(now), config)
+	}
+	sort.SliceStable(mrs, func(i, j int) bool {
+		a, b := mrs[i], mrs[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
 }
@@ -0,0 +1,136 @@
+package refinery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/github"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// Commit status contexts posted to the source branch head on GitHub.
+const (
+	StatusContextTests = "queue:tests"
+	StatusContextMerge = "queue:merge"
+)
+
+// statusTimeout bounds each status post so GitHub outages can't stall the queue.
+const statusTimeout = 10 * time.Second
+
+// newStatusClient returns a GitHub client and "owner/name" repo for posting
+// commit statuses, or nil if the rig has no github settings or no token.
+func newStatusClient(r *rig.Rig) (*github.Client, string) {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(r.Path))
+	if err != nil || settings.GitHub == nil {
+		return nil, ""
+	}
+	cfg := settings.GitHub
+
+	tokenEnv := cfg.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = "GITHUB_TOKEN"
+	}
+	token := os.Getenv(tokenEnv)
+	if token == "" {
+		return nil, ""
+	}
+
+	repo := cfg.Repo
+	if repo == "" {
+		var ok bool
+		if repo, ok = github.RepoFromURL(r.GitURL); !ok {
+			return nil, ""
+		}
+	}
+	return github.NewClient(cfg.APIURL, token), repo
+}
+
+// postStatus posts a commit status to the head of branch (best-effort).
+// A no-op unless GitHub reporting is configured for the rig.
+func (e *Engineer) postStatus(branch, statusContext, state, description string) {
+	if e.github == nil {
+		return
+	}
+	sha, err := e.git.Rev(branch)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: resolving %s for GitHub status: %v\n", branch, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+	defer cancel()
+	err = e.github.CreateStatus(ctx, e.githubRepo, sha, github.CommitStatus{
+		State:       state,
+		Context:     statusContext,
+		Description: description,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: posting %s status: %v\n", statusContext, err)
+	}
+}
+
+// mergeWithStatus runs doMerge, reporting queue:merge progress to GitHub.
+// queue:tests is reported by the merge itself, around the test run.
+// The whole merge is traced as a merge_request span when tracing is on,
+// and a merge_started event carrying the trace ID marks its start.
+func (e *Engineer) mergeWithStatus(ctx context.Context, branch, target, sourceIssue string, commits []string) ProcessResult {
+	ctx, span := e.tracer.Start(ctx, "merge_request")
+	defer span.End()
+	span.SetAttribute("gastown.rig", e.rig.Name)
+	span.SetAttribute("gastown.branch", branch)
+	span.SetAttribute("gastown.target", target)
+	span.SetAttribute("gastown.source_issue", sourceIssue)
+	span.SetAttribute("gastown.worktree_pool", e.config.WorktreePool)
+	span.SetAttribute("gastown.partial_commits", len(commits))
+
+	_ = events.Emit(events.Event{
+		Source:  "refinery",
+		Type:    events.TypeMergeStarted,
+		Actor:   e.rig.Name + "/refinery",
+		Rig:     e.rig.Name,
+		Subject: branch,
+		TraceID: span.TraceID(),
+		Payload: map[string]interface{}{"branch": branch, "target": target, "issue": sourceIssue},
+	})
+	e.postStatus(branch, StatusContextMerge, github.StatePending, fmt.Sprintf("Merging into %s", target))
+
+	result := e.doMerge(ctx, branch, target, sourceIssue, commits)
+	result.TraceID = span.TraceID()
+
+	span.SetAttribute("gastown.conflict", result.Conflict)
+	span.SetAttribute("gastown.tests_failed", result.TestsFailed)
+	span.SetAttribute("gastown.requeued", result.Requeue)
+	if result.Success {
+		span.SetAttribute("gastown.merge_commit", result.MergeCommit)
+	} else {
+		span.SetError(result.Error)
+	}
+
+	switch {
+	case result.Success:
+		e.postStatus(branch, StatusContextMerge, github.StateSuccess,
+			fmt.Sprintf("Merged into %s as %s", target, git.ShortSHA(result.MergeCommit)))
+	case result.Conflict:
+		e.postStatus(branch, StatusContextMerge, github.StateFailure, fmt.Sprintf("Conflicts with %s; rebase required", target))
+	case result.TestsFailed:
+		e.postStatus(branch, StatusContextMerge, github.StateFailure, "Not merged: tests failed")
+	case result.Requeue:
+		e.postStatus(branch, StatusContextMerge, github.StatePending, fmt.Sprintf("Requeued: %s is busy", target))
+	default:
+		e.postStatus(branch, StatusContextMerge, github.StateError, result.Error)
+	}
+	return result
+}
+
+// reportTests posts the queue:tests status for a finished test run.
+func (e *Engineer) reportTests(branch string, result ProcessResult) {
+	if result.Success {
+		e.postStatus(branch, StatusContextTests, github.StateSuccess, "Tests passed")
+		return
+	}
+	e.postStatus(branch, StatusContextTests, github.StateFailure, result.Error)
+}
@@ -0,0 +1,77 @@
+package refinery
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/github"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestNewStatusClient(t *testing.T) {
+	rigPath := t.TempDir()
+	r := &rig.Rig{Name: "test-rig", Path: rigPath, GitURL: "git@github.com:acme/widgets.git"}
+	if c, _ := newStatusClient(r); c != nil {
+		t.Error("expected no client without github settings")
+	}
+
+	settings := config.NewRigSettings()
+	settings.GitHub = &config.GitHubConfig{TokenEnv: "GT_TEST_GITHUB_TOKEN"}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GT_TEST_GITHUB_TOKEN", "")
+	if c, _ := newStatusClient(r); c != nil {
+		t.Error("expected no client without a token")
+	}
+
+	t.Setenv("GT_TEST_GITHUB_TOKEN", "tok")
+	c, repo := newStatusClient(r)
+	if c == nil || repo != "acme/widgets" {
+		t.Errorf("newStatusClient = %v, %q; want client for acme/widgets", c, repo)
+	}
+}
+
+func TestEngineer_PostsCommitStatuses(t *testing.T) {
+	var mu sync.Mutex
+	var posted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var s github.CommitStatus
+		_ = json.NewDecoder(r.Body).Decode(&s)
+		mu.Lock()
+		posted = append(posted, s.Context+"="+s.State)
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	rigPath, _ := setupPoolRig(t)
+	addPolecatBranch(t, rigPath, "polecat/a", "a.txt", "a\n")
+
+	settings := config.NewRigSettings()
+	settings.GitHub = &config.GitHubConfig{Repo: "acme/widgets", TokenEnv: "GT_TEST_GITHUB_TOKEN", APIURL: srv.URL}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GT_TEST_GITHUB_TOKEN", "tok")
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: rigPath})
+	e.SetOutput(io.Discard)
+	e.config.TestCommand = "true"
+
+	if r := e.ProcessMRInfo(context.Background(), &MRInfo{Branch: "polecat/a", Target: "main"}); !r.Success {
+		t.Fatalf("merge failed: %s", r.Error)
+	}
+
+	want := "queue:merge=pending,queue:tests=pending,queue:tests=success,queue:merge=success"
+	if got := strings.Join(posted, ","); got != want {
+		t.Errorf("posted statuses = %s, want %s", got, want)
+	}
+}
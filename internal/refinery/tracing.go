@@ -0,0 +1,46 @@
+package refinery
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/tracing"
+)
+
+// defaultServiceName names the Refinery's traces when neither the rig's
+// tracing settings nor OTEL_SERVICE_NAME do.
+const defaultServiceName = "gastown-refinery"
+
+// newTracer returns a tracer for the merge pipeline, or nil if the rig has
+// no tracing settings and no OTEL_EXPORTER_OTLP_ENDPOINT is set.
+// Export failures are reported through e's output.
+func newTracer(r *rig.Rig, e *Engineer) *tracing.Tracer {
+	cfg := tracerConfig(r)
+	cfg.OnError = func(err error) {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: exporting merge trace: %v\n", err)
+	}
+	return tracing.New(cfg)
+}
+
+// tracerConfig builds the merge pipeline's tracing config: the rig's
+// tracing settings first, then the OTEL_* environment, then defaults.
+func tracerConfig(r *rig.Rig) tracing.Config {
+	cfg := tracing.Config{
+		Scope: "github.com/steveyegge/gastown/internal/refinery",
+	}
+	if settings, err := config.LoadRigSettings(config.RigSettingsPath(r.Path)); err == nil && settings.Tracing != nil {
+		tc := settings.Tracing
+		cfg.Endpoint = tc.Endpoint
+		cfg.ServiceName = tc.ServiceName
+		if tc.HeadersEnv != "" {
+			cfg.Headers = tracing.ParseHeaders(os.Getenv(tc.HeadersEnv))
+		}
+	}
+	cfg = tracing.ConfigFromEnv(cfg)
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = defaultServiceName
+	}
+	return cfg
+}
@@ -0,0 +1,95 @@
+package refinery
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/tracing"
+)
+
+func TestEngineer_ExportsMergeTrace(t *testing.T) {
+	t.Setenv(tracing.EnvEndpoint, "")
+	t.Setenv(tracing.EnvTracesEndpoint, "")
+
+	var mu sync.Mutex
+	var names []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []struct {
+						Name string `json:"name"`
+					} `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					names = append(names, s.Name)
+				}
+			}
+		}
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	rigPath, _ := setupPoolRig(t)
+	addPolecatBranch(t, rigPath, "polecat/a", "a.txt", "a\n")
+
+	settings := config.NewRigSettings()
+	settings.Tracing = &config.TracingConfig{Endpoint: srv.URL}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: rigPath})
+	e.SetOutput(io.Discard)
+	e.config.WorktreePool = true
+	e.config.TestCommand = "true"
+
+	if r := e.ProcessMRInfo(context.Background(), &MRInfo{Branch: "polecat/a", Target: "main"}); !r.Success {
+		t.Fatalf("merge failed: %s", r.Error)
+	}
+
+	sort.Strings(names)
+	want := "fetch,merge,merge_request,push,submodules,tests,tests.attempt"
+	if got := strings.Join(names, ","); got != want {
+		t.Errorf("exported spans = %s, want %s", got, want)
+	}
+}
+
+func TestTracerConfig_ServiceName(t *testing.T) {
+	rigPath := t.TempDir()
+	r := &rig.Rig{Name: "test-rig", Path: rigPath}
+
+	t.Setenv(tracing.EnvServiceName, "")
+	if got := tracerConfig(r).ServiceName; got != defaultServiceName {
+		t.Errorf("default ServiceName = %q, want %q", got, defaultServiceName)
+	}
+
+	t.Setenv(tracing.EnvServiceName, "from-env")
+	if got := tracerConfig(r).ServiceName; got != "from-env" {
+		t.Errorf("ServiceName with %s set = %q, want from-env", tracing.EnvServiceName, got)
+	}
+
+	settings := config.NewRigSettings()
+	settings.Tracing = &config.TracingConfig{ServiceName: "from-rig"}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatal(err)
+	}
+	if got := tracerConfig(r).ServiceName; got != "from-rig" {
+		t.Errorf("ServiceName with rig settings = %q, want from-rig", got)
+	}
+}
@@ -0,0 +1,164 @@
+// Package review runs a review pass on merge requests before they enter the
+// merge queue.
+//
+// When a rig enables review (settings review.enabled), each new MR gets a
+// review bead and the MR is made to depend on it. The Refinery only
+// processes ready MRs, so it leaves the MR alone until the review closes.
+// The review bead carries what a reviewer needs - a diff summary, the
+// changed files, and a checklist - and is slung to the rig's reviewer.
+//
+// Approving closes the review, releasing the MR into the queue. Requesting
+// changes puts the review back to open with the reviewer's note; the worker
+// is told what to fix, and when they resubmit the review goes back to the
+// reviewer for another round.
+package review
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// Label marks review beads.
+const Label = "gt:review"
+
+// Review states, recorded in the review bead's status field.
+const (
+	StatusPending          = "pending"
+	StatusApproved         = "approved"
+	StatusChangesRequested = "changes-requested"
+)
+
+// maxListedFiles caps the changed files listed in a review task.
+const maxListedFiles = 50
+
+// DefaultChecklist is used when the rig doesn't configure one.
+var DefaultChecklist = []string{
+	"Change does what the source issue asks, and nothing unrelated",
+	"Tests cover the new behavior and edge cases",
+	"Errors are handled and surfaced, not swallowed",
+	"No secrets, debug output, or leftover TODOs",
+	"Docs and help text updated for user-visible changes",
+}
+
+// Fields holds the structured fields of a review bead.
+// They are stored as key: value lines at the top of its description.
+type Fields struct {
+	MR          string // Merge-request bead under review
+	Branch      string
+	Target      string
+	SourceIssue string
+	Worker      string
+	Rig         string
+	Reviewer    string // Sling target the review was sent to
+	Status      string // pending, approved, or changes-requested
+	Round       int    // 1 for the first review, incremented on resubmission
+	Note        string // Reviewer's latest note
+}
+
+// ParseFields extracts review fields from an issue's description.
+// Returns nil if the issue has no review fields.
+func ParseFields(issue *beads.Issue) *Fields {
+	if issue == nil {
+		return nil
+	}
+	kv := beads.ParseFieldHeader(issue.Description)
+	f := &Fields{
+		MR:          kv["review_of"],
+		Branch:      kv["branch"],
+		Target:      kv["target"],
+		SourceIssue: kv["source_issue"],
+		Worker:      kv["worker"],
+		Rig:         kv["rig"],
+		Reviewer:    kv["reviewer"],
+		Status:      kv["review_status"],
+		Note:        kv["review_note"],
+	}
+	f.Round, _ = strconv.Atoi(kv["round"])
+	if *f == (Fields{}) {
+		return nil
+	}
+	return f
+}
+
+// Format renders the fields as description lines. Empty fields are omitted.
+func (f *Fields) Format() string {
+	round := ""
+	if f.Round > 0 {
+		round = strconv.Itoa(f.Round)
+	}
+	return beads.FormatFieldHeader(
+		beads.Field{Key: "review_of", Value: f.MR},
+		beads.Field{Key: "branch", Value: f.Branch},
+		beads.Field{Key: "target", Value: f.Target},
+		beads.Field{Key: "source_issue", Value: f.SourceIssue},
+		beads.Field{Key: "worker", Value: f.Worker},
+		beads.Field{Key: "rig", Value: f.Rig},
+		beads.Field{Key: "reviewer", Value: f.Reviewer},
+		beads.Field{Key: "review_status", Value: f.Status},
+		beads.Field{Key: "round", Value: round},
+		beads.Field{Key: "review_note", Value: f.Note},
+	)
+}
+
+// Summary is what the reviewer is shown about the change.
+type Summary struct {
+	Title     string        // Source issue title
+	Commits   int           // Commits on the branch since the merge base
+	Stat      *git.DiffStat // Per-file line counts; nil if unavailable
+	Checklist []string      // Items to check; DefaultChecklist if empty
+}
+
+// Describe builds a review bead description: the fields, then the task for
+// the reviewer.
+func Describe(f *Fields, s Summary) string {
+	var b strings.Builder
+	b.WriteString(f.Format())
+	b.WriteString("\n\n")
+
+	fmt.Fprintf(&b, "Review %s before it enters the merge queue.\n", f.MR)
+	if s.Title != "" {
+		fmt.Fprintf(&b, "Source issue %s: %s\n", f.SourceIssue, s.Title)
+	}
+	fmt.Fprintf(&b, "Inspect with: git diff origin/%s...origin/%s\n", f.Target, f.Branch)
+
+	b.WriteString("\n## Diff summary\n\n")
+	if s.Stat == nil {
+		b.WriteString("(unavailable)\n")
+	} else {
+		fmt.Fprintf(&b, "%d commit(s), %d file(s) changed, +%d -%d\n",
+			s.Commits, len(s.Stat.Files), s.Stat.Insertions, s.Stat.Deletions)
+
+		b.WriteString("\n## Changed files\n\n")
+		for i, file := range s.Stat.Files {
+			if i == maxListedFiles {
+				fmt.Fprintf(&b, "- ... and %d more\n", len(s.Stat.Files)-maxListedFiles)
+				break
+			}
+			fmt.Fprintf(&b, "- %s (+%d -%d)\n", file.Path, file.Insertions, file.Deletions)
+		}
+	}
+
+	checklist := s.Checklist
+	if len(checklist) == 0 {
+		checklist = DefaultChecklist
+	}
+	b.WriteString("\n## Checklist\n\n")
+	for _, item := range checklist {
+		fmt.Fprintf(&b, "- [ ] %s\n", item)
+	}
+
+	b.WriteString("\n## Outcome\n\n")
+	fmt.Fprintf(&b, "Approve:          gt review approve %s\n", f.MR)
+	fmt.Fprintf(&b, "Request changes:  gt review request-changes %s -m \"<what to fix>\"\n", f.MR)
+	return b.String()
+}
+
+// withFields replaces the field lines of a review description, keeping the
+// task text that follows them.
+func withFields(description string, f *Fields) string {
+	return beads.SetFieldHeader(description, f.Format())
+}
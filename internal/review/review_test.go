@@ -0,0 +1,114 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+func testFields() *Fields {
+	return &Fields{
+		MR:          "gt-mr1",
+		Branch:      "polecat/Toast/gt-abc",
+		Target:      "main",
+		SourceIssue: "gt-abc",
+		Worker:      "Toast",
+		Rig:         "gastown",
+		Reviewer:    "gastown/crew/max",
+		Status:      StatusPending,
+		Round:       2,
+		Note:        "fix the\nempty case",
+	}
+}
+
+func TestFieldsRoundTrip(t *testing.T) {
+	f := testFields()
+	got := ParseFields(&beads.Issue{Description: Describe(f, Summary{})})
+	if got == nil {
+		t.Fatal("ParseFields returned nil")
+	}
+	want := *f
+	want.Note = "fix the empty case" // newlines are folded so the note parses back
+	if *got != want {
+		t.Errorf("round trip = %+v, want %+v", *got, want)
+	}
+}
+
+func TestParseFieldsNone(t *testing.T) {
+	if f := ParseFields(&beads.Issue{Description: "just prose"}); f != nil {
+		t.Errorf("ParseFields = %+v, want nil", f)
+	}
+	if f := ParseFields(nil); f != nil {
+		t.Errorf("ParseFields(nil) = %+v, want nil", f)
+	}
+}
+
+func TestParseFieldsIgnoresTask(t *testing.T) {
+	f := &Fields{MR: "gt-mr1", Branch: "polecat/Toast/gt-abc"}
+	desc := Describe(f, Summary{Title: "branch: elsewhere"}) + "\nreview_status: approved\n"
+	got := ParseFields(&beads.Issue{Description: desc})
+	if got == nil || got.Branch != f.Branch || got.Status != "" {
+		t.Errorf("ParseFields = %+v, want fields from the header only", got)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	stat := &git.DiffStat{Insertions: 12, Deletions: 3}
+	for i := 0; i < maxListedFiles+5; i++ {
+		stat.Files = append(stat.Files, git.FileStat{Path: fmt.Sprintf("f%d.go", i), Insertions: 1})
+	}
+	desc := Describe(testFields(), Summary{Title: "Add widgets", Commits: 4, Stat: stat})
+
+	for _, want := range []string{
+		"Source issue gt-abc: Add widgets",
+		"git diff origin/main...origin/polecat/Toast/gt-abc",
+		"4 commit(s), 55 file(s) changed, +12 -3",
+		"- f0.go (+1 -0)",
+		"- ... and 5 more",
+		"- [ ] " + DefaultChecklist[0],
+		"gt review approve gt-mr1",
+		"gt review request-changes gt-mr1",
+	} {
+		if !strings.Contains(desc, want) {
+			t.Errorf("description missing %q:\n%s", want, desc)
+		}
+	}
+	if strings.Contains(desc, fmt.Sprintf("f%d.go", maxListedFiles)) {
+		t.Errorf("description lists more than %d files", maxListedFiles)
+	}
+}
+
+func TestDescribeCustomChecklistNoDiff(t *testing.T) {
+	desc := Describe(testFields(), Summary{Checklist: []string{"Migration is reversible"}})
+	if !strings.Contains(desc, "- [ ] Migration is reversible") {
+		t.Errorf("custom checklist missing:\n%s", desc)
+	}
+	if strings.Contains(desc, DefaultChecklist[0]) {
+		t.Errorf("default checklist used despite custom one:\n%s", desc)
+	}
+	if !strings.Contains(desc, "(unavailable)") {
+		t.Errorf("missing diff not reported:\n%s", desc)
+	}
+}
+
+func TestWithFieldsKeepsTask(t *testing.T) {
+	f := testFields()
+	desc := Describe(f, Summary{Title: "Add widgets"})
+
+	f.Status = StatusChangesRequested
+	f.Note = "handle nil"
+	updated := withFields(desc, f)
+
+	got := ParseFields(&beads.Issue{Description: updated})
+	if got.Status != StatusChangesRequested || got.Note != "handle nil" {
+		t.Errorf("fields not updated: %+v", got)
+	}
+	_, wantBody, _ := strings.Cut(desc, "\n\n")
+	_, gotBody, _ := strings.Cut(updated, "\n\n")
+	if gotBody != wantBody {
+		t.Errorf("task body changed:\n%s\nwant:\n%s", gotBody, wantBody)
+	}
+}
@@ -0,0 +1,139 @@
+package review
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// ErrNotFound is returned when there is no open review for an ID.
+var ErrNotFound = errors.New("no open review")
+
+// Reviews manages review beads in a rig's beads database, the one holding
+// its merge requests (an MR can only depend on beads in its own database).
+type Reviews struct {
+	bd *beads.Beads
+}
+
+// New returns a Reviews backed by bd.
+func New(bd *beads.Beads) *Reviews {
+	return &Reviews{bd: bd}
+}
+
+// Open creates a pending review of f.MR and blocks the MR on it.
+func (r *Reviews) Open(f *Fields, s Summary, priority int) (*beads.Issue, error) {
+	f.Status = StatusPending
+	if f.Round == 0 {
+		f.Round = 1
+	}
+	title := "Review: " + f.MR
+	if s.Title != "" {
+		title += " (" + s.Title + ")"
+	}
+	issue, err := r.bd.Create(beads.CreateOptions{
+		Title:       title,
+		Type:        "review",
+		Priority:    priority,
+		Description: Describe(f, s),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating review bead: %w", err)
+	}
+	if err := r.bd.AddDependency(f.MR, issue.ID); err != nil {
+		// An unlinked review wouldn't gate anything; don't leave it around.
+		_ = r.bd.CloseWithReason("failed to block MR on review", issue.ID)
+		return nil, fmt.Errorf("blocking %s on review %s: %w", f.MR, issue.ID, err)
+	}
+	return issue, nil
+}
+
+// List returns the open reviews, including those with changes requested.
+func (r *Reviews) List() ([]*beads.Issue, error) {
+	issues, err := r.bd.List(beads.ListOptions{
+		Status:   "all",
+		Label:    Label,
+		Priority: -1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var open []*beads.Issue
+	for _, issue := range issues {
+		if issue.Status != "closed" {
+			open = append(open, issue)
+		}
+	}
+	return open, nil
+}
+
+// ForMR returns the open review of an MR, or ErrNotFound.
+func (r *Reviews) ForMR(mrID string) (*beads.Issue, *Fields, error) {
+	issues, err := r.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, issue := range issues {
+		if f := ParseFields(issue); f != nil && f.MR == mrID {
+			return issue, f, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w of %s", ErrNotFound, mrID)
+}
+
+// Resolve returns the open review named by id, which may be the review
+// bead itself or the MR under review.
+func (r *Reviews) Resolve(id string) (*beads.Issue, *Fields, error) {
+	issue, err := r.bd.Show(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !beads.HasLabel(issue, Label) {
+		return r.ForMR(id)
+	}
+	f := ParseFields(issue)
+	if f == nil || issue.Status == "closed" {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return issue, f, nil
+}
+
+// Approve closes the review, releasing the MR into the merge queue.
+func (r *Reviews) Approve(issue *beads.Issue, f *Fields, note string) error {
+	f.Status = StatusApproved
+	f.Note = note
+	if err := r.setFields(issue, f); err != nil {
+		return err
+	}
+	reason := "approved"
+	if note != "" {
+		reason += ": " + note
+	}
+	return r.bd.CloseWithReason(reason, issue.ID)
+}
+
+// RequestChanges records the reviewer's note and takes the review off the
+// reviewer's hook. The review stays open, so the MR stays out of the queue
+// until the worker resubmits and a later round approves it.
+func (r *Reviews) RequestChanges(issue *beads.Issue, f *Fields, note string) error {
+	f.Status = StatusChangesRequested
+	f.Note = note
+	if err := r.setFields(issue, f); err != nil {
+		return err
+	}
+	return r.bd.ReleaseWithReason(issue.ID, "changes requested: "+note)
+}
+
+// Resubmit starts the next round of a review whose changes were addressed,
+// refreshing the task with the branch's current diff.
+func (r *Reviews) Resubmit(issue *beads.Issue, f *Fields, s Summary) error {
+	f.Status = StatusPending
+	f.Round++
+	description := Describe(f, s)
+	return r.bd.Update(issue.ID, beads.UpdateOptions{Description: &description})
+}
+
+func (r *Reviews) setFields(issue *beads.Issue, f *Fields) error {
+	description := withFields(issue.Description, f)
+	return r.bd.Update(issue.ID, beads.UpdateOptions{Description: &description})
+}
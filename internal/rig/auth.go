@@ -0,0 +1,73 @@
+package rig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// GitAuth resolves the rig's configured git credentials.
+// Returns nil, nil when the rig has no git.auth settings.
+func GitAuth(rigPath string) (*git.Auth, error) {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil || settings.Git == nil || settings.Git.Auth == nil {
+		return nil, nil
+	}
+	cfg := settings.Git.Auth
+
+	auth := &git.Auth{
+		SSHKey:           expandHome(cfg.SSHKey),
+		Username:         cfg.Username,
+		CredentialHelper: cfg.CredentialHelper,
+	}
+	if rigCfg, err := LoadRigConfig(rigPath); err == nil {
+		auth.URL = rigCfg.GitURL
+	}
+	if auth.SSHKey != "" {
+		if _, err := os.Stat(auth.SSHKey); err != nil {
+			return nil, fmt.Errorf("ssh key: %w", err)
+		}
+	}
+
+	if cfg.TokenEnv != "" {
+		auth.Token = os.Getenv(cfg.TokenEnv)
+	}
+	if auth.Token == "" && cfg.TokenCommand != "" {
+		// Run by git's credential helper on every request, not here: a
+		// token read once would go stale in a long-running refinery
+		auth.TokenCommand, auth.TokenDir = cfg.TokenCommand, rigPath
+	}
+	if auth.Token == "" && auth.TokenCommand == "" && cfg.TokenEnv != "" {
+		return nil, fmt.Errorf("no token found (token_env %q is empty and no token_command is set)", cfg.TokenEnv)
+	}
+
+	return auth, nil
+}
+
+// WithGitAuth returns g configured with the rig's git credentials.
+// Credential errors are warnings: the command then runs with whatever
+// global git config exists, as it did before auth was configured.
+func WithGitAuth(rigPath string, g *git.Git) *git.Git {
+	auth, err := GitAuth(rigPath)
+	if err != nil {
+		fmt.Printf("Warning: could not load git credentials for rig: %v\n", err)
+		return g
+	}
+	return g.WithAuth(auth)
+}
+
+// expandHome expands a leading "~/" to the user's home directory.
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
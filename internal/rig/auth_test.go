@@ -0,0 +1,64 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func saveAuthSettings(t *testing.T, rigPath string, auth *config.GitAuthConfig) {
+	t.Helper()
+	settings := config.NewRigSettings()
+	settings.Git = &config.RigGitConfig{Auth: auth}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+}
+
+func TestGitAuth(t *testing.T) {
+	rigPath := t.TempDir()
+	if auth, err := GitAuth(rigPath); auth != nil || err != nil {
+		t.Fatalf("GitAuth without settings = %v, %v; want nil, nil", auth, err)
+	}
+
+	t.Run("token env", func(t *testing.T) {
+		t.Setenv("GT_TEST_TOKEN", "secret")
+		saveAuthSettings(t, rigPath, &config.GitAuthConfig{TokenEnv: "GT_TEST_TOKEN", TokenCommand: "echo unused"})
+		auth, err := GitAuth(rigPath)
+		if err != nil || auth.Token != "secret" {
+			t.Errorf("GitAuth = %+v, %v; want token from env", auth, err)
+		}
+	})
+
+	t.Run("token command", func(t *testing.T) {
+		saveAuthSettings(t, rigPath, &config.GitAuthConfig{TokenEnv: "GT_TEST_UNSET_TOKEN", TokenCommand: "echo from-vault"})
+		auth, err := GitAuth(rigPath)
+		if err != nil || auth.Token != "" || auth.TokenCommand != "echo from-vault" || auth.TokenDir != rigPath {
+			t.Errorf("GitAuth = %+v, %v; want token command run at use time in the rig", auth, err)
+		}
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		saveAuthSettings(t, rigPath, &config.GitAuthConfig{TokenEnv: "GT_TEST_UNSET_TOKEN"})
+		if _, err := GitAuth(rigPath); err == nil {
+			t.Error("GitAuth should fail when the configured token is empty")
+		}
+	})
+
+	t.Run("ssh key", func(t *testing.T) {
+		key := filepath.Join(t.TempDir(), "id_ed25519")
+		saveAuthSettings(t, rigPath, &config.GitAuthConfig{SSHKey: key})
+		if _, err := GitAuth(rigPath); err == nil {
+			t.Error("GitAuth should fail when the ssh key is missing")
+		}
+		if err := os.WriteFile(key, []byte("key"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		auth, err := GitAuth(rigPath)
+		if err != nil || auth.SSHKey != key {
+			t.Errorf("GitAuth = %+v, %v; want ssh key %s", auth, err, key)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package rig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// Placeholder expansions for branch policy patterns.
+var branchPatternTokens = map[string]string{
+	"<name>": `[A-Za-z0-9_.-]+`,
+	"<bead>": `[a-z0-9]+-[a-z0-9]+(?:\.[0-9]+)*(?:@[a-z0-9]+)?`,
+	"**":     `.*`,
+	"*":      `[^/]*`,
+}
+
+// branchTokenPattern matches the placeholders, longest first.
+var branchTokenPattern = regexp.MustCompile(`<name>|<bead>|\*\*|\*`)
+
+// BranchPolicy enforces a rig's branch namespace policy (git.branches).
+// The zero value allows every branch.
+type BranchPolicy struct {
+	patterns  []string
+	compiled  []*regexp.Regexp
+	protected []string
+}
+
+// BranchPolicyError reports a branch name rejected by the rig's policy.
+type BranchPolicyError struct {
+	Branch string
+	Reason string
+}
+
+func (e *BranchPolicyError) Error() string {
+	return fmt.Sprintf("branch %q violates rig branch policy: %s", e.Branch, e.Reason)
+}
+
+// NewBranchPolicy compiles a branch policy config. A nil config allows every branch.
+func NewBranchPolicy(cfg *config.BranchPolicyConfig) (*BranchPolicy, error) {
+	p := &BranchPolicy{}
+	if cfg == nil {
+		return p, nil
+	}
+	for _, pattern := range cfg.Patterns {
+		re, err := compileBranchPattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid branch pattern %q: %w", pattern, err)
+		}
+		p.patterns = append(p.patterns, pattern)
+		p.compiled = append(p.compiled, re)
+	}
+	for _, prefix := range cfg.ProtectedPrefixes {
+		if prefix != "" {
+			p.protected = append(p.protected, prefix)
+		}
+	}
+	return p, nil
+}
+
+// compileBranchPattern turns a pattern with placeholders into an anchored regexp.
+func compileBranchPattern(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range branchTokenPattern.FindAllStringIndex(pattern, -1) {
+		b.WriteString(regexp.QuoteMeta(pattern[last:loc[0]]))
+		b.WriteString(branchPatternTokens[pattern[loc[0]:loc[1]]])
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(pattern[last:]))
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// LoadBranchPolicy loads the rig's branch policy from its settings.
+// Rigs without settings or without git.branches get a policy allowing every branch.
+func LoadBranchPolicy(rigPath string) (*BranchPolicy, error) {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil || settings.Git == nil {
+		return &BranchPolicy{}, nil
+	}
+	return NewBranchPolicy(settings.Git.Branches)
+}
+
+// Check returns a *BranchPolicyError if branch is protected or matches
+// none of the allowed patterns.
+func (p *BranchPolicy) Check(branch string) error {
+	for _, prefix := range p.protected {
+		if strings.HasPrefix(branch, prefix) {
+			return &BranchPolicyError{Branch: branch, Reason: fmt.Sprintf("prefix %q is protected", prefix)}
+		}
+	}
+	if len(p.compiled) == 0 {
+		return nil
+	}
+	for _, re := range p.compiled {
+		if re.MatchString(branch) {
+			return nil
+		}
+	}
+	return &BranchPolicyError{
+		Branch: branch,
+		Reason: "must match one of " + strings.Join(p.patterns, ", "),
+	}
+}
+
+// Protected reports whether branch falls under one of the policy's
+// protected prefixes.
+func (p *BranchPolicy) Protected(branch string) bool {
+	for _, prefix := range p.protected {
+		if strings.HasPrefix(branch, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckBranchPolicy checks branch against the rig's branch policy.
+func CheckBranchPolicy(rigPath, branch string) error {
+	policy, err := LoadBranchPolicy(rigPath)
+	if err != nil {
+		return err
+	}
+	return policy.Check(branch)
+}
@@ -0,0 +1,102 @@
+package rig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestBranchPolicyCheck(t *testing.T) {
+	policy, err := NewBranchPolicy(&config.BranchPolicyConfig{
+		Patterns:          []string{"polecat/<name>/<bead>", "integration/*"},
+		ProtectedPrefixes: []string{"release/", "polecat/admin/"},
+	})
+	if err != nil {
+		t.Fatalf("NewBranchPolicy: %v", err)
+	}
+
+	tests := []struct {
+		branch string
+		ok     bool
+	}{
+		{"polecat/toast/gt-abc", true},
+		{"polecat/toast/gt-abc.1", true},
+		{"polecat/toast/gt-abc@mk12xyz", true},
+		{"polecat/toast-mk12xyz", false},      // no bead
+		{"polecat/toast/gt-abc/extra", false}, // too deep
+		{"polecat/admin/gt-abc", false},       // protected
+		{"integration/gt-epic", true},
+		{"integration/nested/branch", false}, // * is one segment
+		{"release/1.0", false},               // protected
+		{"feature/whatever", false},
+	}
+	for _, tt := range tests {
+		err := policy.Check(tt.branch)
+		if tt.ok && err != nil {
+			t.Errorf("Check(%q) = %v, want allowed", tt.branch, err)
+		}
+		if !tt.ok {
+			var policyErr *BranchPolicyError
+			if !errors.As(err, &policyErr) {
+				t.Errorf("Check(%q) = %v, want *BranchPolicyError", tt.branch, err)
+			}
+		}
+	}
+
+	for branch, want := range map[string]bool{"release/1.0": true, "polecat/admin/x": true, "integration/gt-epic": false} {
+		if got := policy.Protected(branch); got != want {
+			t.Errorf("Protected(%q) = %v, want %v", branch, got, want)
+		}
+	}
+}
+
+func TestCompileBranchPattern(t *testing.T) {
+	tests := []struct {
+		pattern, branch string
+		want            bool
+	}{
+		{"fix.<bead>", "fix.gt-abc", true},
+		{"fix.<bead>", "fixXgt-abc", false}, // '.' is literal
+		{"**", "anything/at/all", true},
+		{"team/*/<name>", "team/infra/toast", true},
+		{"team/*/<name>", "team/toast", false},
+	}
+	for _, tt := range tests {
+		re, err := compileBranchPattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("compileBranchPattern(%q): %v", tt.pattern, err)
+		}
+		if got := re.MatchString(tt.branch); got != tt.want {
+			t.Errorf("%q matching %q = %v, want %v", tt.pattern, tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestLoadBranchPolicy(t *testing.T) {
+	rigPath := t.TempDir()
+
+	// No settings: everything allowed
+	if err := CheckBranchPolicy(rigPath, "whatever"); err != nil {
+		t.Errorf("no settings: %v", err)
+	}
+
+	settings := config.NewRigSettings()
+	settings.Git = &config.RigGitConfig{Branches: &config.BranchPolicyConfig{
+		Patterns: []string{"polecat/<name>/<bead>"},
+	}}
+	if err := os.MkdirAll(filepath.Join(rigPath, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckBranchPolicy(rigPath, "polecat/toast-mk12"); err == nil {
+		t.Error("expected policy violation for branch without bead")
+	}
+	if err := CheckBranchPolicy(rigPath, "polecat/toast/gt-abc@mk12"); err != nil {
+		t.Errorf("conforming branch rejected: %v", err)
+	}
+}
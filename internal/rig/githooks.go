@@ -0,0 +1,111 @@
+package rig
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// InstallGitHooks installs the rig's git hooks from <rigPath>/.runtime/git-hooks/
+// into a polecat worktree, so commit conventions (pre-commit, commit-msg, ...)
+// are enforced before work ever reaches the merge queue.
+//
+// Hooks are installed into a per-worktree directory (<gitdir>/gt-hooks) and
+// enabled with a worktree-scoped core.hooksPath, so the refinery and other
+// worktrees sharing the repo are unaffected. Hooks the worktree already had
+// (e.g. a repo-provided pre-push) are carried over; rig hooks of the same
+// name take precedence.
+//
+// Returns nil if the rig defines no git hooks.
+func InstallGitHooks(rigPath, worktreePath string) error {
+	srcDir := filepath.Join(rigPath, ".runtime", "git-hooks")
+	entries, err := os.ReadDir(srcDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading git hooks directory: %w", err)
+	}
+
+	g := git.NewGit(worktreePath)
+	gitDir, err := g.GitDir()
+	if err != nil {
+		return fmt.Errorf("finding worktree git dir: %w", err)
+	}
+	destDir := filepath.Join(gitDir, "gt-hooks")
+
+	// Carry over the hooks currently in effect, unless we installed them
+	if current, err := g.HooksDir(); err == nil && current != destDir {
+		if err := copyHooks(current, destDir); err != nil {
+			return fmt.Errorf("copying existing hooks: %w", err)
+		}
+	}
+
+	installed := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if err := copyHook(filepath.Join(srcDir, entry.Name()), filepath.Join(destDir, entry.Name())); err != nil {
+			return fmt.Errorf("installing hook %s: %w", entry.Name(), err)
+		}
+		installed++
+	}
+	if installed == 0 {
+		return nil
+	}
+
+	if err := g.SetWorktreeConfig("core.hooksPath", destDir); err != nil {
+		return fmt.Errorf("setting core.hooksPath: %w", err)
+	}
+	return nil
+}
+
+// copyHooks copies every active hook in src (skipping git's *.sample files) to dest.
+func copyHooks(src, dest string) error {
+	entries, err := os.ReadDir(src)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".sample") {
+			continue
+		}
+		if err := copyHook(filepath.Join(src, entry.Name()), filepath.Join(dest, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyHook copies a single hook script and makes it executable.
+func copyHook(src, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755) //nolint:gosec // G302: hooks must be executable
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chmod(dest, 0755) //nolint:gosec // G302: hooks must be executable
+}
@@ -0,0 +1,85 @@
+package rig
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func gitOut(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	out, err := exec.Command("git", args...).CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+func mustGit(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := gitOut(t, args...)
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return out
+}
+
+func TestInstallGitHooks(t *testing.T) {
+	tmp := t.TempDir()
+	rigPath := filepath.Join(tmp, "rig")
+	bare := filepath.Join(rigPath, ".repo.git")
+
+	seed := filepath.Join(tmp, "seed")
+	mustGit(t, "init", "-b", "main", seed)
+	mustGit(t, "-C", seed, "-c", "user.email=t@t", "-c", "user.name=T", "commit", "--allow-empty", "-m", "initial")
+	mustGit(t, "clone", "--bare", seed, bare)
+	mustGit(t, "--git-dir", bare, "config", "user.email", "t@t")
+	mustGit(t, "--git-dir", bare, "config", "user.name", "T")
+
+	polecat := filepath.Join(rigPath, "polecats", "toast")
+	refinery := filepath.Join(rigPath, "refinery", "rig")
+	mustGit(t, "--git-dir", bare, "worktree", "add", "-b", "polecat/toast", polecat, "main")
+	mustGit(t, "--git-dir", bare, "worktree", "add", refinery, "main")
+
+	// No hooks defined: nothing to do
+	if err := InstallGitHooks(rigPath, polecat); err != nil {
+		t.Fatalf("InstallGitHooks without hooks dir: %v", err)
+	}
+
+	// Rig hook rejects commit messages without an issue ID
+	hooksDir := filepath.Join(rigPath, ".runtime", "git-hooks")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	hook := "#!/bin/sh\ngrep -q '(gt-' \"$1\" || { echo 'missing issue ID' >&2; exit 1; }\n"
+	if err := os.WriteFile(filepath.Join(hooksDir, "commit-msg"), []byte(hook), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := InstallGitHooks(rigPath, polecat); err != nil {
+		t.Fatalf("InstallGitHooks: %v", err)
+	}
+	// Installing again is idempotent
+	if err := InstallGitHooks(rigPath, polecat); err != nil {
+		t.Fatalf("second InstallGitHooks: %v", err)
+	}
+
+	if out, err := gitOut(t, "-C", polecat, "commit", "--allow-empty", "-m", "no id"); err == nil {
+		t.Error("commit without issue ID should be rejected in polecat worktree")
+	} else if !strings.Contains(out, "missing issue ID") {
+		t.Errorf("unexpected rejection output: %s", out)
+	}
+	mustGit(t, "-C", polecat, "commit", "--allow-empty", "-m", "fix thing (gt-abc)")
+
+	// Other worktrees sharing the repo are unaffected
+	mustGit(t, "-C", refinery, "commit", "--allow-empty", "-m", "merge without id")
+
+	// The shared repo is still bare and the worktrees still have work trees
+	if got := mustGit(t, "--git-dir", bare, "rev-parse", "--is-bare-repository"); got != "true" {
+		t.Errorf("bare repo is-bare = %s, want true", got)
+	}
+	for _, wt := range []string{polecat, refinery} {
+		if got := mustGit(t, "-C", wt, "rev-parse", "--is-inside-work-tree"); got != "true" {
+			t.Errorf("%s is-inside-work-tree = %s, want true", wt, got)
+		}
+	}
+}
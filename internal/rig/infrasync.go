@@ -0,0 +1,182 @@
+package rig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// CloneSyncState classifies an infrastructure clone relative to origin.
+type CloneSyncState string
+
+const (
+	// CloneInSync means the clone's branch matches origin.
+	CloneInSync CloneSyncState = "in-sync"
+
+	// CloneBehind means origin has new commits and the clone has none of
+	// its own: safe to fast-forward.
+	CloneBehind CloneSyncState = "behind"
+
+	// CloneAhead means the clone has local commits that aren't on origin.
+	// Infrastructure clones never commit, so these are accidental.
+	CloneAhead CloneSyncState = "ahead"
+
+	// CloneDiverged means both the clone and origin have commits the other
+	// lacks. Needs a human (or the Mayor) to decide what to keep.
+	CloneDiverged CloneSyncState = "diverged"
+)
+
+// CloneSync describes how an infrastructure clone relates to origin.
+type CloneSync struct {
+	Path   string
+	Branch string
+	Head   string // HEAD commit SHA
+	Ahead  int    // local commits not on origin
+	Behind int    // origin commits not in the clone
+	Dirty  bool   // uncommitted changes to tracked files
+	State  CloneSyncState
+
+	// ReportOnly marks refinery/rig: the Refinery checks out, tests, and
+	// merges there, and brings it up to date itself. Syncing it from outside
+	// could move the tree in the middle of a test run or a merge.
+	ReportOnly bool
+}
+
+// NeedsEscalation reports whether the clone has local commits, which a
+// fast-forward can't resolve and an automatic reset would destroy.
+func (s *CloneSync) NeedsEscalation() bool {
+	return s.State == CloneAhead || s.State == CloneDiverged
+}
+
+// CanFastForward reports whether the clone can be safely fast-forwarded.
+func (s *CloneSync) CanFastForward() bool {
+	return s.State == CloneBehind && !s.Dirty && !s.ReportOnly
+}
+
+// String returns a one-line summary, e.g. "behind origin/main by 3".
+func (s *CloneSync) String() string {
+	upstream := "origin/" + s.Branch
+	var desc string
+	switch s.State {
+	case CloneBehind:
+		desc = fmt.Sprintf("behind %s by %d", upstream, s.Behind)
+	case CloneAhead:
+		desc = fmt.Sprintf("%d local commit(s) not on %s", s.Ahead, upstream)
+	case CloneDiverged:
+		desc = fmt.Sprintf("diverged from %s (%d local, %d upstream)", upstream, s.Ahead, s.Behind)
+	default:
+		desc = "in sync with " + upstream
+	}
+	if s.Dirty {
+		desc += ", uncommitted changes"
+	}
+	return desc
+}
+
+// InfraClonePaths returns the rig's infrastructure checkouts that exist:
+// mayor/rig and refinery/rig. These should always track origin exactly.
+func InfraClonePaths(rigPath string) []string {
+	var paths []string
+	for _, rel := range []string{"mayor/rig", "refinery/rig"} {
+		path := filepath.Join(rigPath, rel)
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// InspectClone compares a clone's current branch with its origin branch.
+// With fetch set, origin is fetched first (using the rig's credentials);
+// otherwise the existing remote-tracking refs are used and may be stale.
+func InspectClone(rigPath, path string, fetch bool) (*CloneSync, error) {
+	g := WithGitAuth(rigPath, git.NewGit(path))
+
+	branch, err := g.CurrentBranch()
+	if err != nil {
+		return nil, fmt.Errorf("reading branch: %w", err)
+	}
+	if branch == "HEAD" {
+		return nil, fmt.Errorf("detached HEAD")
+	}
+
+	if fetch {
+		if err := g.Fetch("origin"); err != nil {
+			return nil, fmt.Errorf("fetching origin: %w", err)
+		}
+	}
+
+	ahead, behind, err := g.AheadBehind("HEAD", "refs/remotes/origin/"+branch)
+	if err != nil {
+		return nil, fmt.Errorf("comparing with origin/%s: %w", branch, err)
+	}
+	// Untracked files (runtime state, agent scratch) don't block a fast-forward
+	status, err := g.Status()
+	if err != nil {
+		return nil, fmt.Errorf("checking status: %w", err)
+	}
+	dirty := len(status.Modified)+len(status.Added)+len(status.Deleted) > 0
+
+	head, err := g.Rev("HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("reading HEAD: %w", err)
+	}
+
+	s := &CloneSync{Path: path, Branch: branch, Head: head, Ahead: ahead, Behind: behind, Dirty: dirty,
+		ReportOnly: path == filepath.Join(rigPath, "refinery", "rig")}
+	switch {
+	case ahead > 0 && behind > 0:
+		s.State = CloneDiverged
+	case ahead > 0:
+		s.State = CloneAhead
+	case behind > 0:
+		s.State = CloneBehind
+	default:
+		s.State = CloneInSync
+	}
+	return s, nil
+}
+
+// FastForwardClone brings a clone that is behind origin up to date.
+// Refuses clones with local commits or uncommitted changes, and refinery/rig.
+func FastForwardClone(s *CloneSync) error {
+	if !s.CanFastForward() {
+		return fmt.Errorf("%s: not safe to fast-forward (%s)", s.Path, s)
+	}
+	if err := git.NewGit(s.Path).MergeFFOnly("refs/remotes/origin/" + s.Branch); err != nil {
+		return fmt.Errorf("%s: fast-forward failed: %w", s.Path, err)
+	}
+	if head, err := git.NewGit(s.Path).Rev("HEAD"); err == nil {
+		s.Head = head
+	}
+	s.Behind = 0
+	s.State = CloneInSync
+	return nil
+}
+
+// SyncInfraClones fetches origin for each of the rig's infrastructure
+// clones and fast-forwards those that are safely behind. refinery/rig is
+// only inspected, never moved (see CloneSync.ReportOnly). Returns the state
+// of every clone after syncing; callers escalate those that NeedsEscalation.
+// Clones that can't be inspected or fast-forwarded are reported in the error.
+func SyncInfraClones(rigPath string) ([]*CloneSync, error) {
+	var results []*CloneSync
+	var errs []error
+	for _, path := range InfraClonePaths(rigPath) {
+		s, err := InspectClone(rigPath, path, true)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		if s.CanFastForward() {
+			if err := FastForwardClone(s); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		results = append(results, s)
+	}
+	return results, errors.Join(errs...)
+}
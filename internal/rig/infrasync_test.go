@@ -0,0 +1,131 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupInfraRig creates an origin repo and a rig whose mayor/rig clones it.
+// Returns the rig path and a separate clone for pushing upstream changes.
+func setupInfraRig(t *testing.T) (string, string) {
+	t.Helper()
+	tmp := t.TempDir()
+	origin := filepath.Join(tmp, "origin.git")
+	mustGit(t, "init", "--bare", "-b", "main", origin)
+
+	upstream := filepath.Join(tmp, "upstream")
+	mustGit(t, "clone", origin, upstream)
+	mustGit(t, "-C", upstream, "-c", "user.email=t@t", "-c", "user.name=T", "commit", "--allow-empty", "-m", "initial")
+	mustGit(t, "-C", upstream, "push", "origin", "HEAD:main")
+
+	rigPath := filepath.Join(tmp, "rig")
+	mustGit(t, "clone", origin, filepath.Join(rigPath, "mayor", "rig"))
+	return rigPath, upstream
+}
+
+func commitIn(t *testing.T, dir, msg string) {
+	t.Helper()
+	mustGit(t, "-C", dir, "-c", "user.email=t@t", "-c", "user.name=T", "commit", "--allow-empty", "-m", msg)
+}
+
+func TestSyncInfraClones_FastForwardsBehind(t *testing.T) {
+	rigPath, upstream := setupInfraRig(t)
+	mayor := filepath.Join(rigPath, "mayor", "rig")
+	commitIn(t, upstream, "upstream work")
+	mustGit(t, "-C", upstream, "push", "origin", "HEAD:main")
+
+	// Untracked files don't block the sync
+	if err := os.WriteFile(filepath.Join(mayor, "scratch.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := SyncInfraClones(rigPath)
+	if err != nil {
+		t.Fatalf("SyncInfraClones: %v", err)
+	}
+	if len(results) != 1 || results[0].State != CloneInSync {
+		t.Fatalf("results = %+v, want one in-sync clone", results)
+	}
+	if got, want := mustGit(t, "-C", mayor, "rev-parse", "HEAD"), mustGit(t, "-C", upstream, "rev-parse", "HEAD"); got != want {
+		t.Errorf("mayor HEAD = %s, want %s", got, want)
+	}
+}
+
+func TestSyncInfraClones_LeavesLocalCommits(t *testing.T) {
+	rigPath, upstream := setupInfraRig(t)
+	mayor := filepath.Join(rigPath, "mayor", "rig")
+	commitIn(t, mayor, "accidental")
+	local := mustGit(t, "-C", mayor, "rev-parse", "HEAD")
+
+	results, err := SyncInfraClones(rigPath)
+	if err != nil {
+		t.Fatalf("SyncInfraClones: %v", err)
+	}
+	if results[0].State != CloneAhead || !results[0].NeedsEscalation() {
+		t.Errorf("state = %s, want ahead needing escalation", results[0].State)
+	}
+
+	commitIn(t, upstream, "upstream work")
+	mustGit(t, "-C", upstream, "push", "origin", "HEAD:main")
+	results, _ = SyncInfraClones(rigPath)
+	if s := results[0]; s.State != CloneDiverged || s.Ahead != 1 || s.Behind != 1 {
+		t.Errorf("after upstream push: %+v, want diverged 1/1", s)
+	}
+	if got := mustGit(t, "-C", mayor, "rev-parse", "HEAD"); got != local {
+		t.Errorf("diverged clone was modified: HEAD %s, want %s", got, local)
+	}
+}
+
+func TestSyncInfraClones_SkipsDirtyClone(t *testing.T) {
+	rigPath, upstream := setupInfraRig(t)
+	mayor := filepath.Join(rigPath, "mayor", "rig")
+	if err := os.WriteFile(filepath.Join(mayor, "tracked.txt"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mustGit(t, "-C", mayor, "add", "tracked.txt")
+	commitIn(t, upstream, "upstream work")
+	mustGit(t, "-C", upstream, "push", "origin", "HEAD:main")
+
+	results, err := SyncInfraClones(rigPath)
+	if err != nil {
+		t.Fatalf("SyncInfraClones: %v", err)
+	}
+	if s := results[0]; s.State != CloneBehind || !s.Dirty || s.CanFastForward() {
+		t.Errorf("dirty clone = %+v, want behind and not fast-forwardable", s)
+	}
+	if err := FastForwardClone(results[0]); err == nil {
+		t.Error("FastForwardClone should refuse a dirty clone")
+	}
+}
+
+func TestSyncInfraClones_LeavesRefineryCheckout(t *testing.T) {
+	// The Refinery tests and merges in refinery/rig; syncing must not move
+	// its tree underneath it
+	rigPath, upstream := setupInfraRig(t)
+	refinery := filepath.Join(rigPath, "refinery", "rig")
+	mustGit(t, "clone", filepath.Join(filepath.Dir(rigPath), "origin.git"), refinery)
+	before := mustGit(t, "-C", refinery, "rev-parse", "HEAD")
+	commitIn(t, upstream, "upstream work")
+	mustGit(t, "-C", upstream, "push", "origin", "HEAD:main")
+
+	results, err := SyncInfraClones(rigPath)
+	if err != nil {
+		t.Fatalf("SyncInfraClones: %v", err)
+	}
+	for _, s := range results {
+		if s.Path != refinery {
+			continue
+		}
+		if s.State != CloneBehind || !s.ReportOnly || s.CanFastForward() {
+			t.Errorf("refinery clone = %+v, want behind and report-only", s)
+		}
+	}
+	if got := mustGit(t, "-C", refinery, "rev-parse", "HEAD"); got != before {
+		t.Errorf("refinery/rig HEAD moved to %s, want %s", got, before)
+	}
+	if got, want := mustGit(t, "-C", filepath.Join(rigPath, "mayor", "rig"), "rev-parse", "HEAD"),
+		mustGit(t, "-C", upstream, "rev-parse", "HEAD"); got != want {
+		t.Errorf("mayor/rig HEAD = %s, want fast-forwarded to %s", got, want)
+	}
+}
@@ -0,0 +1,30 @@
+package rig
+
+import (
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// largeRepoEnabled reports whether the rig opts into git.large_repo mode.
+func largeRepoEnabled(rigPath string) bool {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil || settings.Git == nil {
+		return false
+	}
+	return settings.Git.LargeRepo
+}
+
+// ApplyLargeRepoMode enables git's large-repo performance settings in a
+// checkout of the rig's repo when the rig's git.large_repo option is set.
+// Returns nil when the option is off.
+func ApplyLargeRepoMode(rigPath, checkoutPath string) error {
+	if !largeRepoEnabled(rigPath) {
+		return nil
+	}
+	if err := git.NewGit(checkoutPath).EnableLargeRepoMode(); err != nil {
+		return fmt.Errorf("enabling large-repo mode: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package rig
+
+import (
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// lfsSkipSmudge returns whether the rig is configured to create polecat
+// worktrees without downloading LFS objects.
+func lfsSkipSmudge(rigPath string) bool {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil || settings.Git == nil || settings.Git.LFS == nil {
+		return false
+	}
+	return settings.Git.LFS.SkipSmudge
+}
+
+// SetupLFS configures Git LFS for a rig's repositories if the repo uses it.
+// Without this, worktrees of an LFS repo silently contain pointer files
+// (or fail to check out when filter.lfs.required is set globally).
+// repos are configured in order; the first is used for detection.
+// Returns whether the repo uses LFS.
+func SetupLFS(repos ...*git.Git) bool {
+	if len(repos) == 0 || !repos[0].UsesLFS("") {
+		return false
+	}
+	if !git.LFSAvailable() {
+		fmt.Printf("  Warning: repo uses Git LFS but git-lfs is not installed; worktrees will contain pointer files\n")
+		return true
+	}
+	for _, g := range repos {
+		if err := g.InstallLFS(); err != nil {
+			fmt.Printf("  Warning: could not configure Git LFS: %v\n", err)
+		}
+	}
+	return true
+}
+
+// WorktreeGit returns the git wrapper to use when creating a polecat (or dog)
+// worktree from ref. For LFS repos with lfs.skip_smudge set, checkout leaves
+// pointer files instead of downloading binary assets.
+func WorktreeGit(rigPath string, repoGit *git.Git, ref string) *git.Git {
+	if !repoGit.UsesLFS(ref) {
+		return repoGit
+	}
+	if lfsSkipSmudge(rigPath) {
+		return repoGit.WithEnv(git.EnvLFSSkipSmudge)
+	}
+	if !git.LFSAvailable() {
+		fmt.Printf("Warning: repo uses Git LFS but git-lfs is not installed; worktree will contain pointer files\n")
+	} else if !repoGit.LFSConfigured() {
+		if err := repoGit.InstallLFS(); err != nil {
+			fmt.Printf("Warning: could not configure Git LFS: %v\n", err)
+		}
+	}
+	return repoGit
+}
@@ -0,0 +1,23 @@
+package rig
+
+import (
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestLFSSkipSmudge(t *testing.T) {
+	rigPath := t.TempDir()
+	if lfsSkipSmudge(rigPath) {
+		t.Error("lfsSkipSmudge = true without settings")
+	}
+
+	settings := config.NewRigSettings()
+	settings.Git = &config.RigGitConfig{LFS: &config.LFSConfig{SkipSmudge: true}}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+	if !lfsSkipSmudge(rigPath) {
+		t.Error("lfsSkipSmudge = false with lfs.skip_smudge set")
+	}
+}
@@ -213,6 +213,7 @@ type AddRigOptions struct {
 	GitURL        string // Repository URL
 	BeadsPrefix   string // Beads issue prefix (defaults to derived from name)
 	LocalRepo     string // Optional local repo for reference clones
+	SharedMirror  bool   // Borrow objects from the town's shared mirror of GitURL (ignored with LocalRepo)
 	DefaultBranch string // Default branch (defaults to auto-detected from remote)
 }
 
@@ -271,6 +272,11 @@ func (m *Manager) AddRig(opts AddRigOptions) (*Rig, error) {
 		return nil, fmt.Errorf("rig name %q contains invalid characters; hyphens, dots, and spaces are reserved for agent ID parsing. Try %q instead (underscores are allowed)", opts.Name, sanitized)
 	}
 
+	// observers/ holds the town's read-only observers, not a rig.
+	if opts.Name == "observers" {
+		return nil, fmt.Errorf("rig name %q is reserved", opts.Name)
+	}
+
 	rigPath := filepath.Join(m.townRoot, opts.Name)
 
 	// Check if directory already exists
@@ -281,15 +287,31 @@ func (m *Manager) AddRig(opts AddRigOptions) (*Rig, error) {
 	// Track whether user explicitly provided --prefix (before deriving)
 	userProvidedPrefix := opts.BeadsPrefix != ""
 
-	// Derive defaults
+	// Derive defaults. Each rig owns its prefix: a derived one is made unique
+	// against the registry, an explicit one must already be.
 	if opts.BeadsPrefix == "" {
-		opts.BeadsPrefix = deriveBeadsPrefix(opts.Name)
+		opts.BeadsPrefix = m.uniqueBeadsPrefix(deriveBeadsPrefix(opts.Name))
+	} else if owner := m.prefixOwner(opts.BeadsPrefix); owner != "" {
+		return nil, fmt.Errorf("beads prefix %q is already used by %s; choose another with --prefix", opts.BeadsPrefix, owner)
 	}
 
 	localRepo, warn := resolveLocalRepo(opts.LocalRepo, opts.GitURL)
 	if warn != "" {
 		fmt.Printf("  Warning: %s\n", warn)
 	}
+	if localRepo == "" && (opts.SharedMirror || mirrorExists(m.townRoot, opts.GitURL)) {
+		fmt.Printf("  Preparing shared object mirror...\n")
+		// The rig's settings (and so any git.auth) don't exist until it's
+		// created; a mirror shared with an existing rig is refreshed with
+		// that rig's credentials on its next spawn
+		mirror, err := EnsureMirror(m.townRoot, opts.GitURL, nil)
+		if err != nil {
+			fmt.Printf("  Warning: %v (cloning without shared objects)\n", err)
+		} else {
+			localRepo = mirror
+			fmt.Printf("   ✓ Using shared mirror %s\n", mirror)
+		}
+	}
 
 	// Create container directory
 	if err := os.MkdirAll(rigPath, 0755); err != nil {
@@ -388,6 +410,22 @@ func (m *Manager) AddRig(opts AddRigOptions) (*Rig, error) {
 	}
 	fmt.Printf("   ✓ Created mayor clone\n")
 
+	// Configure Git LFS before any worktree is created from the bare repo.
+	// The mayor clone was checked out before LFS was configured, so pull
+	// its LFS objects now (no-op if the global config already smudged them).
+	if SetupLFS(bareGit, mayorGit) && git.LFSAvailable() {
+		if err := mayorGit.PullLFS(); err != nil {
+			fmt.Printf("  Warning: could not pull LFS objects for mayor: %v\n", err)
+		}
+		fmt.Printf("   ✓ Configured Git LFS\n")
+	}
+	if err := UpdateSubmodules(rigPath, mayorRigPath); err != nil {
+		fmt.Printf("  Warning: %v\n", err)
+	}
+	if err := ApplyLargeRepoMode(rigPath, mayorRigPath); err != nil {
+		fmt.Printf("  Warning: %v\n", err)
+	}
+
 	// Check if source repo has tracked .beads/ directory.
 	// If so, we need to initialize the database (beads.db is gitignored so it doesn't exist after clone).
 	sourceBeadsDir := filepath.Join(mayorRigPath, ".beads")
@@ -401,6 +439,9 @@ func (m *Manager) AddRig(opts AddRigOptions) (*Rig, error) {
 			if userProvidedPrefix && opts.BeadsPrefix != sourcePrefix {
 				return nil, fmt.Errorf("prefix mismatch: source repo uses '%s' but --prefix '%s' was provided; use --prefix %s to match existing issues", sourcePrefix, opts.BeadsPrefix, sourcePrefix)
 			}
+			if owner := m.prefixOwner(sourcePrefix); owner != "" {
+				return nil, fmt.Errorf("source repo's beads prefix %q is already used by %s; IDs would collide", sourcePrefix, owner)
+			}
 			// Use detected prefix (overrides derived prefix)
 			opts.BeadsPrefix = sourcePrefix
 			rigConfig.Beads.Prefix = sourcePrefix
@@ -462,6 +503,9 @@ func (m *Manager) AddRig(opts AddRigOptions) (*Rig, error) {
 	if err := bareGit.WorktreeAddExisting(refineryRigPath, defaultBranch); err != nil {
 		return nil, fmt.Errorf("creating refinery worktree: %w", err)
 	}
+	if err := UpdateSubmodules(rigPath, refineryRigPath); err != nil {
+		fmt.Printf("  Warning: %v\n", err)
+	}
 	fmt.Printf("   ✓ Created refinery worktree\n")
 	// Set up beads redirect for refinery (points to rig-level .beads)
 	if err := beads.SetupRedirect(m.townRoot, refineryRigPath); err != nil {
@@ -837,6 +881,30 @@ func deriveBeadsPrefix(name string) string {
 	return strings.ToLower(name[:2])
 }
 
+// prefixOwner returns who already uses a beads prefix: "rig <name>" for a
+// registered rig, "town beads" for the reserved hq prefix, or "" if it is free.
+func (m *Manager) prefixOwner(prefix string) string {
+	if prefix == beads.TownBeadsPrefix {
+		return "town beads"
+	}
+	for name, entry := range m.config.Rigs {
+		if entry.BeadsConfig != nil && entry.BeadsConfig.Prefix == prefix {
+			return "rig " + name
+		}
+	}
+	return ""
+}
+
+// uniqueBeadsPrefix returns prefix, or prefix with the smallest numeric
+// suffix that no other rig uses: "gt" -> "gt2" when gastown already has "gt".
+func (m *Manager) uniqueBeadsPrefix(prefix string) string {
+	candidate := prefix
+	for n := 2; m.prefixOwner(candidate) != ""; n++ {
+		candidate = fmt.Sprintf("%s%d", prefix, n)
+	}
+	return candidate
+}
+
 // splitCompoundWord attempts to split a compound word into its components.
 // Common suffixes like "town", "ville", "port" are detected to split
 // compound names (e.g., "gastown" -> ["gas", "town"]).
@@ -217,6 +217,41 @@ func TestAddRig_RejectsInvalidNames(t *testing.T) {
 	}
 }
 
+func TestAddRig_RejectsTakenPrefix(t *testing.T) {
+	root, rigsConfig := setupTestTown(t)
+	rigsConfig.Rigs["gastown"] = config.RigEntry{BeadsConfig: &config.BeadsConfig{Prefix: "gt"}}
+	manager := NewManager(root, rigsConfig, git.NewGit(root))
+
+	for prefix, owner := range map[string]string{"gt": "rig gastown", "hq": "town beads"} {
+		_, err := manager.AddRig(AddRigOptions{
+			Name:        "greentree",
+			GitURL:      "git@github.com:test/test.git",
+			BeadsPrefix: prefix,
+		})
+		if err == nil || !strings.Contains(err.Error(), "already used by "+owner) {
+			t.Errorf("AddRig(--prefix %s) error = %v, want it to name %s", prefix, err, owner)
+		}
+	}
+}
+
+func TestUniqueBeadsPrefix(t *testing.T) {
+	root, rigsConfig := setupTestTown(t)
+	rigsConfig.Rigs["gastown"] = config.RigEntry{BeadsConfig: &config.BeadsConfig{Prefix: "gt"}}
+	rigsConfig.Rigs["greentree"] = config.RigEntry{BeadsConfig: &config.BeadsConfig{Prefix: "gt2"}}
+	manager := NewManager(root, rigsConfig, git.NewGit(root))
+
+	tests := map[string]string{
+		"bd": "bd",
+		"gt": "gt3",
+		"hq": "hq2",
+	}
+	for prefix, want := range tests {
+		if got := manager.uniqueBeadsPrefix(prefix); got != want {
+			t.Errorf("uniqueBeadsPrefix(%q) = %q, want %q", prefix, got, want)
+		}
+	}
+}
+
 func TestListRigNames(t *testing.T) {
 	root, rigsConfig := setupTestTown(t)
 	rigsConfig.Rigs["rig1"] = config.RigEntry{}
@@ -0,0 +1,173 @@
+package rig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// EnvMirrorDir overrides where shared object mirrors live. Point several
+// towns at the same directory to share objects across towns.
+const EnvMirrorDir = "GT_MIRROR_DIR"
+
+// mirrorMarkerKey is set in a mirror's git config so gt only ever refreshes
+// mirrors it created (never a user's --local-repo).
+const mirrorMarkerKey = "gastown.mirror"
+
+// mirrorRefreshInterval skips refreshing a mirror fetched this recently, so
+// bursts of polecat spawns don't each pay for a network round trip.
+const mirrorRefreshInterval = 30 * time.Second
+
+// MirrorDir returns the directory holding shared object mirrors:
+// $GT_MIRROR_DIR, or <town>/.repo-mirrors.
+func MirrorDir(townRoot string) string {
+	if dir := os.Getenv(EnvMirrorDir); dir != "" {
+		return dir
+	}
+	return filepath.Join(townRoot, ".repo-mirrors")
+}
+
+var mirrorNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// MirrorPath returns the mirror location for gitURL. Names are readable
+// (host and path) with a short hash so distinct URLs never collide.
+func MirrorPath(townRoot, gitURL string) string {
+	name := gitURL
+	if i := strings.Index(name, "://"); i >= 0 {
+		name = name[i+3:]
+	}
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSuffix(name, ".git")
+	name = strings.Trim(mirrorNameUnsafe.ReplaceAllString(name, "_"), "_")
+
+	sum := sha256.Sum256([]byte(gitURL))
+	return filepath.Join(MirrorDir(townRoot), fmt.Sprintf("%s-%s.git", name, hex.EncodeToString(sum[:4])))
+}
+
+// EnsureMirror returns the shared mirror for gitURL, creating it if needed
+// or refreshing it if it exists, with auth applied to the clone and fetch
+// (nil for the global git config). Rigs cloned with the mirror as
+// --reference share its objects instead of each downloading and storing the
+// full history.
+func EnsureMirror(townRoot, gitURL string, auth *git.Auth) (string, error) {
+	path := MirrorPath(townRoot, gitURL)
+	if _, err := os.Stat(path); err == nil {
+		if err := refreshMirror(path, 0, auth); err != nil {
+			fmt.Printf("  Warning: could not refresh mirror: %v\n", err)
+		}
+		return path, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("creating mirror dir: %w", err)
+	}
+	// Clone to a temp path and rename, so a concurrent rig add never sees
+	// (or references) a half-written mirror.
+	tmp, err := os.MkdirTemp(filepath.Dir(path), ".tmp-mirror-")
+	if err != nil {
+		return "", fmt.Errorf("creating mirror dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	tmpRepo := filepath.Join(tmp, "repo.git")
+	g := git.NewGit("").WithAuth(auth)
+	if err := g.CloneMirror(gitURL, tmpRepo); err != nil {
+		return "", fmt.Errorf("creating mirror: %w", err)
+	}
+	if err := git.NewGitWithDir(tmpRepo, "").SetConfig(mirrorMarkerKey, "true"); err != nil {
+		return "", fmt.Errorf("marking mirror: %w", err)
+	}
+	if err := protectMirror(tmpRepo); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpRepo, path); err != nil {
+		if _, statErr := os.Stat(path); statErr == nil {
+			return path, nil // another process won the race
+		}
+		return "", fmt.Errorf("installing mirror: %w", err)
+	}
+	return path, nil
+}
+
+// RefreshMirror fetches the shared mirror that the rig's repo borrows objects
+// from, if any, so a following fetch in the rig transfers (and stores) only
+// what the mirror doesn't already have. The fetch uses the rig's git
+// credentials. A no-op for rigs without a mirror.
+func RefreshMirror(rigPath string) error {
+	gitDir := filepath.Join(rigPath, ".repo.git")
+	if _, err := os.Stat(gitDir); err != nil {
+		gitDir = filepath.Join(rigPath, "mayor", "rig", ".git")
+	}
+	alternates, err := git.Alternates(gitDir)
+	if err != nil {
+		return err
+	}
+	for _, objects := range alternates {
+		mirror := filepath.Dir(objects)
+		if isMirror(mirror) {
+			auth, err := GitAuth(rigPath)
+			if err != nil {
+				return fmt.Errorf("loading git credentials: %w", err)
+			}
+			return refreshMirror(mirror, mirrorRefreshInterval, auth)
+		}
+	}
+	return nil
+}
+
+// mirrorExists reports whether a shared mirror of gitURL has been created.
+func mirrorExists(townRoot, gitURL string) bool {
+	_, err := os.Stat(MirrorPath(townRoot, gitURL))
+	return err == nil
+}
+
+// isMirror reports whether path is a mirror created by EnsureMirror.
+func isMirror(path string) bool {
+	val, err := git.NewGitWithDir(path, "").GetConfig(mirrorMarkerKey)
+	return err == nil && val == "true"
+}
+
+// mirrorGCConfig keeps git from ever deleting a mirror's objects. Rig repos
+// borrow them through alternates without a copy of their own, so an object
+// pruned from the mirror (by gc, auto-gc, or after a ref deleted upstream
+// leaves it unreachable) corrupts every rig still using it.
+var mirrorGCConfig = [][2]string{
+	{"gc.auto", "0"},
+	{"gc.pruneExpire", "never"},
+}
+
+// protectMirror disables garbage collection and pruning in the mirror.
+func protectMirror(path string) error {
+	g := git.NewGitWithDir(path, "")
+	for _, kv := range mirrorGCConfig {
+		if err := g.SetConfig(kv[0], kv[1]); err != nil {
+			return fmt.Errorf("setting %s on mirror: %w", kv[0], err)
+		}
+	}
+	return nil
+}
+
+// refreshMirror fetches all refs into the mirror unless it was fetched
+// within minAge, with auth applied to the fetch. Refs deleted upstream are
+// kept: the mirror only ever grows.
+func refreshMirror(path string, minAge time.Duration, auth *git.Auth) error {
+	if minAge > 0 {
+		if info, err := os.Stat(filepath.Join(path, "FETCH_HEAD")); err == nil && time.Since(info.ModTime()) < minAge {
+			return nil
+		}
+	}
+	// Mirrors created before gc was disabled get protected on their next refresh
+	if err := protectMirror(path); err != nil {
+		return err
+	}
+	return git.NewGitWithDir(path, "").WithAuth(auth).FetchNoPrune("origin")
+}
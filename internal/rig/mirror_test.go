@@ -0,0 +1,120 @@
+package rig
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+func TestMirrorPath(t *testing.T) {
+	t.Setenv(EnvMirrorDir, "")
+	town := "/town"
+
+	ssh := MirrorPath(town, "git@github.com:acme/widgets.git")
+	https := MirrorPath(town, "https://github.com/acme/widgets.git")
+	if filepath.Dir(ssh) != filepath.Join(town, ".repo-mirrors") {
+		t.Errorf("mirror dir = %s", filepath.Dir(ssh))
+	}
+	if !strings.HasPrefix(filepath.Base(ssh), "github.com_acme_widgets-") || !strings.HasSuffix(ssh, ".git") {
+		t.Errorf("mirror name = %s", filepath.Base(ssh))
+	}
+	if ssh == https {
+		t.Error("distinct URLs must map to distinct mirrors")
+	}
+
+	t.Setenv(EnvMirrorDir, "/shared/mirrors")
+	if got := filepath.Dir(MirrorPath(town, "git@github.com:acme/widgets.git")); got != "/shared/mirrors" {
+		t.Errorf("mirror dir with %s = %s", EnvMirrorDir, got)
+	}
+}
+
+func TestEnsureMirrorAndRefresh(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv(EnvMirrorDir, "")
+
+	// Origin with one commit
+	origin := filepath.Join(tmp, "origin")
+	for _, args := range [][]string{
+		{"init", origin},
+		{"-C", origin, "-c", "user.email=t@t", "-c", "user.name=T", "commit", "--allow-empty", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	town := filepath.Join(tmp, "town")
+	mirror, err := EnsureMirror(town, origin, nil)
+	if err != nil {
+		t.Fatalf("EnsureMirror: %v", err)
+	}
+	if !isMirror(mirror) {
+		t.Fatal("created mirror is not marked as a gt mirror")
+	}
+	for _, kv := range mirrorGCConfig {
+		if got, _ := git.NewGitWithDir(mirror, "").GetConfig(kv[0]); got != kv[1] {
+			t.Errorf("mirror %s = %q, want %q", kv[0], got, kv[1])
+		}
+	}
+	if again, err := EnsureMirror(town, origin, nil); err != nil || again != mirror {
+		t.Errorf("second EnsureMirror = %s, %v; want existing %s", again, err, mirror)
+	}
+	if !mirrorExists(town, origin) {
+		t.Error("mirrorExists = false after EnsureMirror")
+	}
+
+	// A rig repo borrowing objects from the mirror
+	rigPath := filepath.Join(town, "myrig")
+	if err := git.NewGit("").CloneBareWithReference(origin, filepath.Join(rigPath, ".repo.git"), mirror); err != nil {
+		t.Fatalf("CloneBareWithReference: %v", err)
+	}
+	alternates, err := git.Alternates(filepath.Join(rigPath, ".repo.git"))
+	if err != nil || len(alternates) != 1 || filepath.Dir(alternates[0]) != mirror {
+		t.Fatalf("Alternates = %v, %v; want the mirror's objects dir", alternates, err)
+	}
+
+	_ = os.Remove(filepath.Join(mirror, "FETCH_HEAD"))
+	if err := RefreshMirror(rigPath); err != nil {
+		t.Fatalf("RefreshMirror: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(mirror, "FETCH_HEAD")); err != nil {
+		t.Error("RefreshMirror did not fetch into the mirror")
+	}
+
+	// Branches deleted upstream stay in the mirror: rigs may still need
+	// their objects
+	for _, args := range [][]string{
+		{"-C", origin, "branch", "doomed"},
+		{"-C", mirror, "fetch", "origin"},
+		{"-C", origin, "branch", "-D", "doomed"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	_ = os.Remove(filepath.Join(mirror, "FETCH_HEAD"))
+	if err := RefreshMirror(rigPath); err != nil {
+		t.Fatalf("RefreshMirror: %v", err)
+	}
+	if _, err := git.NewGitWithDir(mirror, "").Rev("refs/heads/doomed"); err != nil {
+		t.Error("RefreshMirror pruned a branch deleted upstream")
+	}
+
+	// The refresh fetches with the rig's credentials, and says so when
+	// they can't be loaded instead of fetching without them
+	saveAuthSettings(t, rigPath, &config.GitAuthConfig{TokenEnv: "GT_TEST_UNSET_TOKEN"})
+	_ = os.Remove(filepath.Join(mirror, "FETCH_HEAD"))
+	if err := RefreshMirror(rigPath); err == nil || !strings.Contains(err.Error(), "credentials") {
+		t.Errorf("RefreshMirror with broken git.auth = %v, want credentials error", err)
+	}
+
+	// Rigs without a mirror are left alone
+	if err := RefreshMirror(t.TempDir()); err != nil {
+		t.Errorf("RefreshMirror without mirror: %v", err)
+	}
+}
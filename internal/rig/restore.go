@@ -0,0 +1,123 @@
+package rig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// RestoreClones recreates a rig's infrastructure checkouts after its state
+// was restored from a backup (see gt town migrate): the shared bare repo,
+// the Mayor's clone, and the refinery worktree. Checkouts that already
+// exist are left alone; gt state restored inside the others, such as the
+// canonical beads in the Mayor's clone, is kept.
+func (m *Manager) RestoreClones(name string) error {
+	r, err := m.GetRig(name)
+	if err != nil {
+		return err
+	}
+	if r.GitURL == "" {
+		return fmt.Errorf("rig %s has no git URL", name)
+	}
+	branch := r.DefaultBranch()
+
+	bareRepoPath := filepath.Join(r.Path, ".repo.git")
+	if _, err := os.Stat(bareRepoPath); os.IsNotExist(err) {
+		if err := m.git.CloneBare(r.GitURL, bareRepoPath); err != nil {
+			return wrapCloneError(err, r.GitURL)
+		}
+	}
+	bareGit := git.NewGitWithDir(bareRepoPath, "")
+
+	mayorRigPath := filepath.Join(r.Path, "mayor", "rig")
+	if !isCheckout(mayorRigPath) {
+		if err := CloneInto(mayorRigPath, func(dest string) error {
+			if err := m.git.Clone(r.GitURL, dest); err != nil {
+				return err
+			}
+			return git.NewGitWithDir("", dest).Checkout(branch)
+		}); err != nil {
+			return fmt.Errorf("cloning for mayor: %w", err)
+		}
+		if err := m.createRoleCLAUDEmd(mayorRigPath, "mayor", name, ""); err != nil {
+			return fmt.Errorf("creating mayor CLAUDE.md: %w", err)
+		}
+	}
+
+	refineryRigPath := filepath.Join(r.Path, "refinery", "rig")
+	if !isCheckout(refineryRigPath) {
+		if err := CloneInto(refineryRigPath, func(dest string) error {
+			return bareGit.WorktreeAddExisting(dest, branch)
+		}); err != nil {
+			return fmt.Errorf("creating refinery worktree: %w", err)
+		}
+		if err := beads.SetupRedirect(m.townRoot, refineryRigPath); err != nil {
+			fmt.Printf("  Warning: Could not set up refinery beads redirect: %v\n", err)
+		}
+		if err := m.createRoleCLAUDEmd(refineryRigPath, "refinery", name, ""); err != nil {
+			return fmt.Errorf("creating refinery CLAUDE.md: %w", err)
+		}
+	}
+	return nil
+}
+
+// CloneInto runs clone to create a checkout at dir. If dir already holds
+// restored gt state, the state is moved aside for the clone and laid back
+// over the new checkout, replacing any tracked files it collides with.
+func CloneInto(dir string, clone func(dest string) error) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			return err
+		}
+		return clone(dir)
+	}
+
+	aside := dir + ".restoring"
+	if err := os.Rename(dir, aside); err != nil {
+		return err
+	}
+	if err := clone(dir); err != nil {
+		_ = os.RemoveAll(dir)
+		_ = os.Rename(aside, dir)
+		return err
+	}
+	if err := moveOver(aside, dir); err != nil {
+		return fmt.Errorf("restoring state into %s (kept in %s): %w", dir, aside, err)
+	}
+	return os.RemoveAll(aside)
+}
+
+// moveOver moves everything in src into dst, merging directories.
+func moveOver(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		from := filepath.Join(src, e.Name())
+		to := filepath.Join(dst, e.Name())
+		if e.IsDir() {
+			if fi, err := os.Lstat(to); err == nil && fi.IsDir() {
+				if err := moveOver(from, to); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if err := os.RemoveAll(to); err != nil {
+			return err
+		}
+		if err := os.Rename(from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isCheckout(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
@@ -0,0 +1,58 @@
+package rig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCloneInto(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mayor", "rig")
+	// Restored state: beads that differ from the tracked ones
+	if err := os.MkdirAll(filepath.Join(dir, ".beads"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".beads", "beads.db"), []byte("restored"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	clone := func(dest string) error {
+		if _, err := os.Stat(dest); err == nil {
+			t.Fatalf("clone into existing %s", dest)
+		}
+		for name, content := range map[string]string{".git/HEAD": "ref", "main.go": "package main", ".beads/config.yaml": "prefix: gt", ".beads/beads.db": "tracked"} {
+			p := filepath.Join(dest, name)
+			if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := CloneInto(dir, clone); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{"main.go": "package main", ".beads/config.yaml": "prefix: gt", ".beads/beads.db": "restored"} {
+		if got, err := os.ReadFile(filepath.Join(dir, name)); err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := os.Stat(dir + ".restoring"); !os.IsNotExist(err) {
+		t.Errorf("aside dir left behind: %v", err)
+	}
+
+	// A failed clone puts the restored state back
+	if err := os.RemoveAll(filepath.Join(dir, ".git")); err != nil {
+		t.Fatal(err)
+	}
+	if err := CloneInto(dir, func(string) error { return errors.New("offline") }); err == nil {
+		t.Fatal("expected clone error")
+	}
+	if got, err := os.ReadFile(filepath.Join(dir, ".beads", "beads.db")); err != nil || string(got) != "restored" {
+		t.Errorf("state after failed clone = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,37 @@
+package rig
+
+import (
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// submodulesSetting returns the rig's git.submodules toggle (nil = auto).
+func submodulesSetting(rigPath string) *bool {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil || settings.Git == nil {
+		return nil
+	}
+	return settings.Git.Submodules
+}
+
+// UpdateSubmodules initializes and updates submodules in a checkout of the
+// rig's repo, honoring the rig's git.submodules toggle. Fresh worktrees don't
+// populate submodules, so without this tests fail with confusing missing-file
+// errors. Returns nil when there is nothing to do.
+func UpdateSubmodules(rigPath, checkoutPath string) error {
+	g := git.NewGit(checkoutPath)
+	setting := submodulesSetting(rigPath)
+	if setting != nil && !*setting {
+		return nil
+	}
+	if setting == nil && !g.HasSubmodules() {
+		return nil
+	}
+	// Submodules may be private repos on the same host as the rig's repo
+	if err := WithGitAuth(rigPath, g).UpdateSubmodules(); err != nil {
+		return fmt.Errorf("updating submodules: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestUpdateSubmodules_Toggle(t *testing.T) {
+	rigPath := t.TempDir()
+	checkout := t.TempDir() // not a git repo: any git invocation fails
+
+	// Auto mode without .gitmodules: nothing to do
+	if err := UpdateSubmodules(rigPath, checkout); err != nil {
+		t.Fatalf("auto without .gitmodules: %v", err)
+	}
+
+	// Auto mode with .gitmodules: runs git (and fails here, proving it ran)
+	if err := os.WriteFile(filepath.Join(checkout, ".gitmodules"), []byte("[submodule \"x\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateSubmodules(rigPath, checkout); err == nil {
+		t.Error("auto with .gitmodules: expected git to run and fail outside a repo")
+	}
+
+	// Disabled: skipped even with .gitmodules
+	disabled := false
+	settings := config.NewRigSettings()
+	settings.Git = &config.RigGitConfig{Submodules: &disabled}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+	if err := UpdateSubmodules(rigPath, checkout); err != nil {
+		t.Errorf("disabled: expected skip, got %v", err)
+	}
+}
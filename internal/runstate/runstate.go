@@ -0,0 +1,179 @@
+// Package runstate is the store for runtime state: the small JSON documents
+// agents, the daemon, and gt commands keep under a town's or rig's .runtime/
+// directory (refinery.json, namepool-state.json, keepalive.json, ...).
+//
+// Many processes read and write these documents at once, so every write goes
+// through the store:
+//
+//   - Writes are atomic (temp file + rename): a reader never sees a partial
+//     document, and reads need no lock.
+//   - Writes hold an exclusive file lock (<document>.lock) for the document,
+//     so concurrent writers never interleave.
+//   - Update runs a read-modify-write under that lock, so concurrent updates
+//     from different processes are never lost.
+//
+// Documents are namespaced by their path under .runtime/, e.g. "refinery.json"
+// or "deacon/paused.json". A Doc is the typed accessor for one document:
+//
+//	doc := runstate.NewDoc(runstate.Open(rigPath), "refinery.json", func() *Refinery {
+//	    return &Refinery{State: StateStopped}
+//	})
+//	ref, err := doc.Update(func(r *Refinery) error {
+//	    r.State = StateRunning
+//	    return nil
+//	})
+//
+// State kept outside .runtime/ since before the store existed (daemon/,
+// deacon/, a crew member's state.json, ...) stays where readers expect it
+// and is opened by path with NewDocAt.
+package runstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// ErrSkip, returned by an Update function, leaves the document unchanged.
+var ErrSkip = errors.New("runstate: skip save")
+
+// Store is the .runtime/ directory of a town, rig, or worker.
+type Store struct {
+	dir string
+}
+
+// Open returns the store under root's .runtime/ directory.
+// The directory is created on first write.
+func Open(root string) *Store {
+	return &Store{dir: filepath.Join(root, constants.DirRuntime)}
+}
+
+// Dir returns the store's directory.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Path returns the path of the document named name.
+func (s *Store) Path(name string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(name))
+}
+
+// Doc is a typed accessor for one document in a store.
+type Doc[T any] struct {
+	path       string
+	newDefault func() *T
+}
+
+// NewDoc returns the document named name in s. newDefault builds the value
+// Load returns while the document doesn't exist; nil means the zero value.
+func NewDoc[T any](s *Store, name string, newDefault func() *T) *Doc[T] {
+	return &Doc[T]{path: s.Path(name), newDefault: newDefault}
+}
+
+// NewDocAt returns the document at path, for state that lives outside a
+// .runtime/ directory. It is locked and written like any other document.
+func NewDocAt[T any](path string, newDefault func() *T) *Doc[T] {
+	return &Doc[T]{path: path, newDefault: newDefault}
+}
+
+// Path returns the document's file path.
+func (d *Doc[T]) Path() string {
+	return d.path
+}
+
+// Exists reports whether the document has been written.
+func (d *Doc[T]) Exists() bool {
+	_, err := os.Stat(d.path)
+	return err == nil
+}
+
+// Load reads the document, or returns the default if it doesn't exist.
+func (d *Doc[T]) Load() (*T, error) {
+	data, err := os.ReadFile(d.path) //nolint:gosec // G304: path is built from a trusted root
+	if err != nil {
+		if os.IsNotExist(err) {
+			return d.defaultValue(), nil
+		}
+		return nil, err
+	}
+	v := new(T)
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", d.path, err)
+	}
+	return v, nil
+}
+
+// Save replaces the document with v.
+func (d *Doc[T]) Save(v *T) error {
+	unlock, err := d.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return util.AtomicWriteJSON(d.path, v)
+}
+
+// Update loads the document, applies fn, and saves the result, holding the
+// document's lock throughout. If fn returns an error the document is left
+// unchanged; ErrSkip does so without Update failing. Returns the value as
+// fn left it.
+func (d *Doc[T]) Update(fn func(v *T) error) (*T, error) {
+	unlock, err := d.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	v, err := d.Load()
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(v); err != nil {
+		if errors.Is(err, ErrSkip) {
+			return v, nil
+		}
+		return nil, err
+	}
+	if err := util.AtomicWriteJSON(d.path, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Delete removes the document. Deleting a missing document is not an error.
+func (d *Doc[T]) Delete() error {
+	unlock, err := d.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := os.Remove(d.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// lock takes the document's exclusive lock, creating its directory.
+func (d *Doc[T]) lock() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(d.path), 0755); err != nil {
+		return nil, err
+	}
+	l := flock.New(d.path + ".lock")
+	if err := l.Lock(); err != nil {
+		return nil, fmt.Errorf("locking %s: %w", filepath.Base(d.path), err)
+	}
+	return func() { _ = l.Unlock() }, nil
+}
+
+func (d *Doc[T]) defaultValue() *T {
+	if d.newDefault != nil {
+		return d.newDefault()
+	}
+	return new(T)
+}
@@ -0,0 +1,152 @@
+package runstate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+type counter struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestDoc_LoadDefaultAndSave(t *testing.T) {
+	root := t.TempDir()
+	doc := NewDoc(Open(root), "sub/counter.json", func() *counter { return &counter{Name: "default"} })
+
+	if doc.Path() != filepath.Join(root, ".runtime", "sub", "counter.json") {
+		t.Errorf("Path() = %q", doc.Path())
+	}
+	if doc.Exists() {
+		t.Error("Exists() before any write")
+	}
+	got, err := doc.Load()
+	if err != nil {
+		t.Fatalf("Load missing: %v", err)
+	}
+	if got.Name != "default" {
+		t.Errorf("Load missing = %+v, want default", got)
+	}
+
+	if err := doc.Save(&counter{Name: "saved", Count: 3}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err = doc.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got.Name != "saved" || got.Count != 3 {
+		t.Errorf("Load = %+v, want saved/3", got)
+	}
+
+	if err := doc.Delete(); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if doc.Exists() {
+		t.Error("Exists() after Delete")
+	}
+	if err := doc.Delete(); err != nil {
+		t.Errorf("Delete missing: %v", err)
+	}
+}
+
+func TestDoc_ZeroDefault(t *testing.T) {
+	doc := NewDoc[counter](Open(t.TempDir()), "counter.json", nil)
+	got, err := doc.Load()
+	if err != nil || got == nil || got.Count != 0 {
+		t.Errorf("Load = %+v, %v; want zero value", got, err)
+	}
+}
+
+func TestNewDocAt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon", "state.json")
+	doc := NewDocAt[counter](path, nil)
+	if _, err := doc.Update(func(c *counter) error {
+		c.Count++
+		return nil
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("document not written at %s: %v", path, err)
+	}
+	if _, err := os.Stat(path + ".lock"); err != nil {
+		t.Errorf("document not locked beside itself: %v", err)
+	}
+}
+
+func TestDoc_UpdateConcurrent(t *testing.T) {
+	root := t.TempDir()
+	const writers, perWriter = 8, 25
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// A separate Doc per writer, as separate processes would have
+			doc := NewDoc[counter](Open(root), "counter.json", nil)
+			for j := 0; j < perWriter; j++ {
+				if _, err := doc.Update(func(c *counter) error {
+					c.Count++
+					return nil
+				}); err != nil {
+					t.Errorf("Update: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := NewDoc[counter](Open(root), "counter.json", nil).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Count != writers*perWriter {
+		t.Errorf("Count = %d, want %d (lost updates)", got.Count, writers*perWriter)
+	}
+}
+
+func TestDoc_UpdateErrorLeavesDocument(t *testing.T) {
+	doc := NewDoc[counter](Open(t.TempDir()), "counter.json", nil)
+	if err := doc.Save(&counter{Count: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	boom := errors.New("boom")
+	if _, err := doc.Update(func(c *counter) error {
+		c.Count = 99
+		return boom
+	}); !errors.Is(err, boom) {
+		t.Errorf("Update error = %v, want boom", err)
+	}
+	v, err := doc.Update(func(c *counter) error {
+		c.Count = 99
+		return ErrSkip
+	})
+	if err != nil || v.Count != 99 {
+		t.Errorf("Update ErrSkip = %+v, %v", v, err)
+	}
+
+	got, _ := doc.Load()
+	if got.Count != 1 {
+		t.Errorf("Count = %d after failed updates, want 1", got.Count)
+	}
+}
+
+func TestDoc_LoadCorrupt(t *testing.T) {
+	doc := NewDoc[counter](Open(t.TempDir()), "counter.json", nil)
+	if err := os.MkdirAll(filepath.Dir(doc.Path()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(doc.Path(), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := doc.Load(); err == nil {
+		t.Error("Load of corrupt document should fail")
+	}
+}
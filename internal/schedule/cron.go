@@ -0,0 +1,236 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spec is a parsed schedule: the times, to the minute, at which a trigger
+// fires.
+type Spec struct {
+	expr   string
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	// domStar and dowStar record an unrestricted day field. As in cron, a
+	// time matches when either day field does if both are restricted.
+	domStar bool
+	dowStar bool
+}
+
+type field struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{min: 0, max: 59}
+	hourField   = field{min: 0, max: 23}
+	domField    = field{min: 1, max: 31}
+	monthField  = field{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Day of week 7 is Sunday too.
+	dowField = field{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// macros are the cron @-shorthands.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// dayWords are the day forms of "<days> HH:MM" schedules, as cron
+// day-of-week fields.
+var dayWords = map[string]string{
+	"daily":    "*",
+	"everyday": "*",
+	"weekday":  "1-5",
+	"weekdays": "1-5",
+	"weekend":  "0,6",
+	"weekends": "0,6",
+}
+
+// Parse parses a schedule. It accepts a five-field cron expression
+// ("minute hour day-of-month month day-of-week", e.g. "0 9 * * 1-5"), a
+// cron @-shorthand ("@daily", "@hourly", ...), or "<days> HH:MM", where
+// days is daily, weekday, weekend, or a list or range of day names
+// ("weekday 09:00", "mon,thu 14:30", "mon-fri 17:00").
+func Parse(expr string) (*Spec, error) {
+	expr = strings.TrimSpace(expr)
+	cron := strings.ToLower(expr)
+	if m, ok := macros[cron]; ok {
+		cron = m
+	} else if fields := strings.Fields(cron); len(fields) == 2 {
+		c, err := parseDaysAt(fields[0], fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", expr, err)
+		}
+		cron = c
+	}
+
+	fields := strings.Fields(cron)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule %q: want 5 cron fields, \"<days> HH:MM\", or an @-shorthand", expr)
+	}
+	s := &Spec{expr: expr}
+	var err error
+	for i, p := range []struct {
+		f    field
+		bits *uint64
+	}{{minuteField, &s.minute}, {hourField, &s.hour}, {domField, &s.dom}, {monthField, &s.month}, {dowField, &s.dow}} {
+		if *p.bits, err = parseField(fields[i], p.f); err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", expr, err)
+		}
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = fields[2] == "*" || strings.HasPrefix(fields[2], "*/")
+	s.dowStar = fields[4] == "*" || strings.HasPrefix(fields[4], "*/")
+	return s, nil
+}
+
+// parseDaysAt converts "<days> HH:MM" to a cron expression.
+func parseDaysAt(days, at string) (string, error) {
+	hour, minute, err := parseClock(at)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d %d * * %s", minute, hour, dayField(days)), nil
+}
+
+// dayField converts the days of a "<days> ..." form to a cron day-of-week
+// field.
+func dayField(days string) string {
+	if dow, ok := dayWords[days]; ok {
+		return dow
+	}
+	// A list or range of day names; parseField validates it
+	return days
+}
+
+// parseClock parses an "HH:MM" time of day.
+func parseClock(at string) (hour, minute int, err error) {
+	hh, mm, ok := strings.Cut(at, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("time %q is not HH:MM", at)
+	}
+	hour, err = strconv.Atoi(hh)
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, 0, fmt.Errorf("invalid hour in %q", at)
+	}
+	minute, err = strconv.Atoi(mm)
+	if err != nil || len(mm) != 2 || minute < 0 || minute > 59 {
+		return 0, 0, fmt.Errorf("invalid minute in %q", at)
+	}
+	return hour, minute, nil
+}
+
+// parseField parses one cron field into a bit set of its allowed values.
+func parseField(s string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			step = n
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*":
+			lo, hi = f.min, f.max
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(a); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(b); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			v, err := f.value(rng)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+			if hasStep {
+				hi = f.max
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[s]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value %q (want %d-%d)", s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// String returns the schedule as written.
+func (s *Spec) String() string {
+	return s.expr
+}
+
+// Next returns the first time after t at which the schedule fires, in t's
+// location, or the zero time if it never does (e.g. "0 0 31 2 *").
+func (s *Spec) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + 5
+	for t.Year() <= limit {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Spec) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,85 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"0 9 * *",
+		"60 9 * * *",
+		"0 24 * * *",
+		"0 9 0 * *",
+		"0 9 * 13 *",
+		"0 9 * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"weekday 9am",
+		"weekday 25:00",
+		"weekday 09:5",
+		"someday 09:00",
+		"@fortnightly",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded", expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// Wednesday
+	base := time.Date(2026, 3, 4, 10, 30, 15, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"weekday 09:00", "2026-03-05 09:00"},
+		{"WEEKDAY 11:00", "2026-03-04 11:00"},
+		{"weekend 09:00", "2026-03-07 09:00"},
+		{"mon,fri 08:15", "2026-03-06 08:15"},
+		{"mon-tue 10:00", "2026-03-09 10:00"},
+		{"daily 10:31", "2026-03-04 10:31"},
+		{"@hourly", "2026-03-04 11:00"},
+		{"@daily", "2026-03-05 00:00"},
+		{"@weekly", "2026-03-08 00:00"},
+		{"@monthly", "2026-04-01 00:00"},
+		{"*/20 * * * *", "2026-03-04 10:40"},
+		{"5/20 * * * *", "2026-03-04 10:45"},
+		{"0 9-17/4 * * *", "2026-03-04 13:00"},
+		{"0 9 * * 7", "2026-03-08 09:00"},
+		{"0 9 * jun *", "2026-06-01 09:00"},
+		{"0 0 29 2 *", "2028-02-29 00:00"},
+		// Both day fields restricted: either matches
+		{"0 9 1 * fri", "2026-03-06 09:00"},
+		// Day of week restricted only
+		{"0 9 * * sun", "2026-03-08 09:00"},
+	}
+	for _, tt := range tests {
+		spec, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := spec.Next(base).Format("2006-01-02 15:04"); got != tt.want {
+			t.Errorf("%q.Next = %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+
+	never, err := Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := never.Next(base); !got.IsZero() {
+		t.Errorf("Feb 31 fires at %v", got)
+	}
+}
+
+func TestNextIsAfter(t *testing.T) {
+	spec, _ := Parse("* * * * *")
+	at := time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)
+	if got := spec.Next(at); !got.Equal(at.Add(time.Minute)) {
+		t.Errorf("Next(%v) = %v", at, got)
+	}
+}
@@ -0,0 +1,410 @@
+// Package schedule delivers callbacks to the Mayor on a timetable.
+//
+// Triggers pair a schedule, such as "weekday 09:00" or "0 9 * * 1-5", with
+// a callback subject, such as TRIAGE_REMINDER. The daemon runs a Scheduler
+// that mails each trigger's callback to the Mayor when its schedule comes
+// due, and gt callbacks process forwards it to the agent the trigger names.
+// Recurring rituals (a standup summary, backlog triage) are then driven by
+// the same machinery as callbacks from agents.
+//
+// Triggers are configured under "schedules" in mayor/daemon.json.
+//
+// The package also parses windows, such as "weekday 09:00-18:00", for
+// settings that hold during recurring hours (a rig's working hours).
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/runstate"
+)
+
+// Sender is the From address of scheduled callbacks. gt callbacks process
+// recognizes scheduled callbacks by it.
+const Sender = "scheduler"
+
+// DefaultRoute is where a scheduled callback is forwarded unless its
+// trigger sets a route: back to the Mayor's inbox, as an instruction for
+// the Mayor agent.
+const DefaultRoute = "mayor/"
+
+// MaxLate is how late a trigger may still fire, e.g. after the daemon was
+// down at the scheduled time. Later runs are skipped, not caught up.
+const MaxLate = time.Hour
+
+// checkInterval is how often the scheduler looks for due triggers.
+const checkInterval = 30 * time.Second
+
+// Config is the "schedules" section of mayor/daemon.json.
+type Config struct {
+	// Timezone is the IANA time zone schedules are in, e.g.
+	// "America/New_York" (default: the daemon's local time).
+	Timezone string `json:"timezone,omitempty"`
+
+	// Triggers are the scheduled callbacks.
+	Triggers []Trigger `json:"triggers"`
+}
+
+// Trigger is a callback delivered on a schedule.
+type Trigger struct {
+	// Name identifies the trigger in logs and saved state.
+	Name string `json:"name"`
+
+	// Schedule is when the trigger fires (see Parse).
+	Schedule string `json:"schedule"`
+
+	// Subject is the callback subject, e.g. "TRIAGE_REMINDER".
+	Subject string `json:"subject"`
+
+	// Body is the instruction passed on with the callback.
+	Body string `json:"body,omitempty"`
+
+	// Route is the agent the callback is forwarded to, e.g.
+	// "gastown/witness" (default DefaultRoute).
+	Route string `json:"route,omitempty"`
+
+	// Priority is the mail priority: low, normal, high, or urgent
+	// (default normal).
+	Priority string `json:"priority,omitempty"`
+
+	// Disabled skips the trigger without removing it.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// TriggerState is the saved state of a trigger between checks.
+type TriggerState struct {
+	// ArmedAt is when the trigger was first seen. It does not fire for
+	// times before it.
+	ArmedAt time.Time `json:"armed_at"`
+
+	// FiredAt is when the trigger last fired, and Scheduled the time it
+	// was due then.
+	FiredAt   time.Time `json:"fired_at,omitempty"`
+	Scheduled time.Time `json:"scheduled,omitempty"`
+
+	// MissedAt is the time up to which runs were skipped for being too
+	// late.
+	MissedAt time.Time `json:"missed_at,omitempty"`
+
+	// Fired and Missed count runs.
+	Fired  int `json:"fired"`
+	Missed int `json:"missed"`
+
+	// Error is the last delivery error, if the last attempt failed.
+	Error string `json:"error,omitempty"`
+}
+
+// since is the time after which the trigger's next run is looked for.
+func (st *TriggerState) since() time.Time {
+	t := st.ArmedAt
+	for _, u := range []time.Time{st.Scheduled, st.MissedAt} {
+		if u.After(t) {
+			t = u
+		}
+	}
+	return t
+}
+
+// StateFile returns the path of the saved trigger states.
+func StateFile(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "schedules.json")
+}
+
+// LoadState reads the saved trigger states, keyed by trigger name.
+func LoadState(townRoot string) (map[string]*TriggerState, error) {
+	state, err := stateDoc(townRoot).Load()
+	if err != nil {
+		return nil, err
+	}
+	if *state == nil {
+		return map[string]*TriggerState{}, nil
+	}
+	return *state, nil
+}
+
+// stateDoc returns the document holding the trigger states.
+func stateDoc(townRoot string) *runstate.Doc[map[string]*TriggerState] {
+	return runstate.NewDocAt(StateFile(townRoot), func() *map[string]*TriggerState {
+		return &map[string]*TriggerState{}
+	})
+}
+
+type trigger struct {
+	Trigger
+	spec *Spec
+}
+
+// Scheduler fires triggers when they come due.
+type Scheduler struct {
+	townRoot string
+	loc      *time.Location
+	triggers []*trigger
+	logger   func(format string, args ...interface{})
+
+	// sendMail delivers a callback to the Mayor (replaced in tests).
+	sendMail func(msg *mail.Message) error
+
+	mu    sync.Mutex
+	state map[string]*TriggerState
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New validates the configured triggers and creates a scheduler for them.
+// Disabled triggers are skipped.
+func New(townRoot string, cfg Config, logger func(format string, args ...interface{})) (*Scheduler, error) {
+	loc := time.Local
+	if cfg.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(cfg.Timezone); err != nil {
+			return nil, fmt.Errorf("schedules timezone: %w", err)
+		}
+	}
+	state, err := LoadState(townRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Scheduler{
+		townRoot: townRoot,
+		loc:      loc,
+		logger:   logger,
+		sendMail: mail.NewRouterWithTownRoot(townRoot, townRoot).Send,
+		state:    state,
+	}
+	seen := make(map[string]bool)
+	for i, t := range cfg.Triggers {
+		if t.Name == "" {
+			return nil, fmt.Errorf("schedule trigger %d has no name", i+1)
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("duplicate schedule trigger %q", t.Name)
+		}
+		seen[t.Name] = true
+		ct, err := compile(t)
+		if err != nil {
+			return nil, fmt.Errorf("schedule trigger %s: %w", t.Name, err)
+		}
+		if !t.Disabled {
+			s.triggers = append(s.triggers, ct)
+		}
+	}
+	return s, nil
+}
+
+func compile(t Trigger) (*trigger, error) {
+	if strings.TrimSpace(t.Subject) == "" {
+		return nil, errors.New("subject is required")
+	}
+	switch mail.Priority(t.Priority) {
+	case "", mail.PriorityLow, mail.PriorityNormal, mail.PriorityHigh, mail.PriorityUrgent:
+	default:
+		return nil, fmt.Errorf("invalid priority %q", t.Priority)
+	}
+	spec, err := Parse(t.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	return &trigger{Trigger: t, spec: spec}, nil
+}
+
+// Triggers returns the number of enabled triggers.
+func (s *Scheduler) Triggers() int {
+	return len(s.triggers)
+}
+
+// Status describes a trigger for display.
+type Status struct {
+	Trigger
+	Next  time.Time     `json:"next"`
+	State *TriggerState `json:"state,omitempty"`
+}
+
+// Status returns the enabled triggers with their next run after now.
+func (s *Scheduler) Status(now time.Time) []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Status, 0, len(s.triggers))
+	for _, t := range s.triggers {
+		st := Status{Trigger: t.Trigger, State: s.state[t.Name]}
+		st.Next = t.spec.Next(now.In(s.loc))
+		out = append(out, st)
+	}
+	return out
+}
+
+// Start begins checking triggers in the background.
+func (s *Scheduler) Start() error {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.wg.Add(1)
+	go s.run()
+	return nil
+}
+
+// Stop stops checking triggers.
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		s.Check(time.Now())
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check fires the triggers due at now and saves the trigger states. A
+// trigger fires at most once per check, for its latest due time; runs
+// more than MaxLate overdue are skipped. It returns the names of the
+// triggers fired.
+func (s *Scheduler) Check(now time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now = now.In(s.loc)
+	var fired []string
+	changed := false
+	for _, t := range s.triggers {
+		st := s.state[t.Name]
+		if st == nil {
+			st = &TriggerState{ArmedAt: now}
+			s.state[t.Name] = st
+			changed = true
+			continue
+		}
+
+		since := st.since().In(s.loc)
+		first := t.spec.Next(since)
+		if first.IsZero() || first.After(now) {
+			continue
+		}
+		changed = true
+
+		// Fire for the latest scheduled time not after now, if one is
+		// recent enough; otherwise the runs since the last check were missed
+		from := since
+		if earliest := now.Add(-MaxLate); earliest.After(from) {
+			from = earliest
+		}
+		due := time.Time{}
+		for next := t.spec.Next(from); !next.IsZero() && !next.After(now); next = t.spec.Next(next) {
+			due = next
+		}
+		if due.IsZero() {
+			st.MissedAt = from
+			st.Missed++
+			s.logf("schedules: %s missed its %s run", t.Name, first.Format("2006-01-02 15:04"))
+			continue
+		}
+		if err := s.deliver(t, due); err != nil {
+			// Retried at the next check, until it is too late
+			st.Error = err.Error()
+			s.logf("schedules: %s: %v", t.Name, err)
+			continue
+		}
+		st.FiredAt, st.Scheduled, st.Error = now, due, ""
+		st.Fired++
+		fired = append(fired, t.Name)
+		s.logf("schedules: fired %s (%s)", t.Name, t.Subject)
+	}
+
+	// Forget triggers that were removed from the config
+	for name := range s.state {
+		if !s.hasTrigger(name) {
+			delete(s.state, name)
+			changed = true
+		}
+	}
+	if changed {
+		if err := stateDoc(s.townRoot).Save(&s.state); err != nil {
+			s.logf("schedules: saving state: %v", err)
+		}
+	}
+	return fired
+}
+
+// Fire delivers a trigger's callback now, regardless of its schedule,
+// without changing its saved state.
+func (s *Scheduler) Fire(name string, now time.Time) error {
+	for _, t := range s.triggers {
+		if t.Name == name {
+			return s.deliver(t, now.In(s.loc))
+		}
+	}
+	return fmt.Errorf("no enabled schedule trigger %q", name)
+}
+
+func (s *Scheduler) hasTrigger(name string) bool {
+	for _, t := range s.triggers {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scheduler) deliver(t *trigger, scheduled time.Time) error {
+	msg := NewCallback(t.Trigger, scheduled)
+	if err := s.sendMail(msg); err != nil {
+		return fmt.Errorf("sending %s to mayor: %w", t.Subject, err)
+	}
+	return nil
+}
+
+func (s *Scheduler) logf(format string, args ...interface{}) {
+	if s.logger != nil {
+		s.logger(format, args...)
+	}
+}
+
+// NewCallback builds the callback mail for a trigger run at scheduled.
+// Its body starts with Schedule, Scheduled-At, and Route header lines,
+// followed by a blank line and the trigger's body.
+func NewCallback(t Trigger, scheduled time.Time) *mail.Message {
+	route := t.Route
+	if route == "" {
+		route = DefaultRoute
+	}
+	body := fmt.Sprintf("Schedule: %s (%s)\nScheduled-At: %s\nRoute: %s\n",
+		t.Name, t.Schedule, scheduled.Format(time.RFC3339), route)
+	if t.Body != "" {
+		body += "\n" + t.Body
+	}
+	msg := mail.NewMessage(Sender, "mayor/", strings.TrimSpace(t.Subject), body)
+	if t.Priority != "" {
+		msg.Priority = mail.Priority(t.Priority)
+	}
+	return msg
+}
+
+// CallbackRoute returns the agent a scheduled callback's body says to
+// forward it to.
+func CallbackRoute(body string) string {
+	header, _, _ := strings.Cut(body, "\n\n")
+	for _, line := range strings.Split(header, "\n") {
+		if v, ok := strings.CutPrefix(line, "Route:"); ok && strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return DefaultRoute
+}
@@ -0,0 +1,157 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+func newTestScheduler(t *testing.T, triggers ...Trigger) (*Scheduler, *[]*mail.Message) {
+	t.Helper()
+	s, err := New(t.TempDir(), Config{Timezone: "UTC", Triggers: triggers}, t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent []*mail.Message
+	s.sendMail = func(msg *mail.Message) error {
+		sent = append(sent, msg)
+		return nil
+	}
+	return s, &sent
+}
+
+var triage = Trigger{
+	Name:     "triage",
+	Schedule: "weekday 09:00",
+	Subject:  "TRIAGE_REMINDER",
+	Body:     "Triage new beads.",
+	Route:    "gastown/witness",
+	Priority: "high",
+}
+
+func TestCheckFiresWhenDue(t *testing.T) {
+	s, sent := newTestScheduler(t, triage, Trigger{Name: "off", Schedule: "@hourly", Subject: "X", Disabled: true})
+	if s.Triggers() != 1 {
+		t.Fatalf("triggers = %d", s.Triggers())
+	}
+
+	// Wednesday; the first check arms the trigger
+	armed := time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC)
+	if fired := s.Check(armed); len(fired) != 0 {
+		t.Fatalf("fired on arming: %v", fired)
+	}
+	if fired := s.Check(armed.Add(59 * time.Minute)); len(fired) != 0 {
+		t.Fatalf("fired early: %v", fired)
+	}
+	if fired := s.Check(armed.Add(61 * time.Minute)); len(fired) != 1 {
+		t.Fatalf("not fired at 09:01")
+	}
+	if fired := s.Check(armed.Add(90 * time.Minute)); len(fired) != 0 {
+		t.Fatalf("fired twice: %v", fired)
+	}
+
+	if len(*sent) != 1 {
+		t.Fatalf("sent %d", len(*sent))
+	}
+	msg := (*sent)[0]
+	if msg.From != Sender || msg.To != "mayor/" || msg.Subject != "TRIAGE_REMINDER" || msg.Priority != mail.PriorityHigh {
+		t.Errorf("msg = %+v", msg)
+	}
+	if !strings.Contains(msg.Body, "Scheduled-At: 2026-03-04T09:00:00Z") || !strings.HasSuffix(msg.Body, "\n\nTriage new beads.") {
+		t.Errorf("body = %q", msg.Body)
+	}
+	if got := CallbackRoute(msg.Body); got != "gastown/witness" {
+		t.Errorf("route = %q", got)
+	}
+
+	state, err := LoadState(s.townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := state["triage"]; st == nil || st.Fired != 1 || !st.Scheduled.Equal(armed.Add(time.Hour)) {
+		t.Errorf("state = %+v", st)
+	}
+}
+
+func TestCheckSkipsLongMissedRuns(t *testing.T) {
+	s, sent := newTestScheduler(t, triage)
+	armed := time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC)
+	s.Check(armed)
+
+	// Down from before 09:00 Wednesday until Friday 08:00: two runs missed
+	s.Check(time.Date(2026, 3, 6, 8, 0, 0, 0, time.UTC))
+	if len(*sent) != 0 {
+		t.Fatalf("caught up %d runs", len(*sent))
+	}
+	if st := s.state["triage"]; st.Missed != 1 || st.Fired != 0 {
+		t.Errorf("state = %+v", st)
+	}
+
+	// Late within MaxLate still fires, once
+	s.Check(time.Date(2026, 3, 6, 9, 30, 0, 0, time.UTC))
+	if len(*sent) != 1 {
+		t.Fatalf("sent %d", len(*sent))
+	}
+}
+
+func TestCheckRetriesFailedDelivery(t *testing.T) {
+	s, sent := newTestScheduler(t, triage)
+	armed := time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC)
+	s.Check(armed)
+
+	send := s.sendMail
+	s.sendMail = func(*mail.Message) error { return errTest }
+	s.Check(armed.Add(61 * time.Minute))
+	if st := s.state["triage"]; st.Error == "" || st.Fired != 0 {
+		t.Errorf("state after failure = %+v", st)
+	}
+
+	s.sendMail = send
+	if fired := s.Check(armed.Add(62 * time.Minute)); len(fired) != 1 || len(*sent) != 1 {
+		t.Errorf("not retried: %v", fired)
+	}
+	if st := s.state["triage"]; st.Error != "" {
+		t.Errorf("error not cleared: %+v", st)
+	}
+}
+
+type testError string
+
+func (e testError) Error() string { return string(e) }
+
+const errTest = testError("mail down")
+
+func TestNewErrors(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"no name":      {Triggers: []Trigger{{Schedule: "@daily", Subject: "X"}}},
+		"no subject":   {Triggers: []Trigger{{Name: "a", Schedule: "@daily"}}},
+		"bad schedule": {Triggers: []Trigger{{Name: "a", Schedule: "sometimes", Subject: "X"}}},
+		"bad priority": {Triggers: []Trigger{{Name: "a", Schedule: "@daily", Subject: "X", Priority: "asap"}}},
+		"duplicate":    {Triggers: []Trigger{{Name: "a", Schedule: "@daily", Subject: "X"}, {Name: "a", Schedule: "@daily", Subject: "Y"}}},
+		"bad timezone": {Timezone: "Mars/Olympus", Triggers: []Trigger{{Name: "a", Schedule: "@daily", Subject: "X"}}},
+	} {
+		if _, err := New(t.TempDir(), cfg, nil); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestFireAndStatus(t *testing.T) {
+	s, sent := newTestScheduler(t, triage)
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	if err := s.Fire("triage", now); err != nil || len(*sent) != 1 {
+		t.Fatalf("Fire = %v, sent %d", err, len(*sent))
+	}
+	if err := s.Fire("nope", now); err == nil {
+		t.Error("fired an unknown trigger")
+	}
+	st := s.Status(now)
+	if len(st) != 1 || st[0].Next.Format("2006-01-02 15:04") != "2026-03-05 09:00" {
+		t.Errorf("status = %+v", st)
+	}
+	if CallbackRoute("Schedule: x\n\nRoute: elsewhere") != DefaultRoute {
+		t.Error("route read from the instruction")
+	}
+}
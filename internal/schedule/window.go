@@ -0,0 +1,139 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Window is a recurring daily time span on some days of the week, such as
+// "weekday 09:00-18:00". A span whose end is before its start runs past
+// midnight: "mon-thu 22:00-02:00" starts Monday to Thursday evenings.
+type Window struct {
+	expr       string
+	dow        uint64
+	start, end time.Duration // Since midnight
+}
+
+// ParseWindow parses "[<days>] HH:MM-HH:MM", where days is daily (the
+// default), weekday, weekend, or a list or range of day names, as in Parse.
+func ParseWindow(expr string) (*Window, error) {
+	fields := strings.Fields(strings.ToLower(expr))
+	days, span := "daily", ""
+	switch len(fields) {
+	case 1:
+		span = fields[0]
+	case 2:
+		days, span = fields[0], fields[1]
+	default:
+		return nil, fmt.Errorf("window %q: want \"[<days>] HH:MM-HH:MM\"", expr)
+	}
+
+	from, to, ok := strings.Cut(span, "-")
+	if !ok {
+		return nil, fmt.Errorf("window %q: want \"[<days>] HH:MM-HH:MM\"", expr)
+	}
+	w := &Window{expr: strings.TrimSpace(expr)}
+	for _, p := range []struct {
+		at string
+		d  *time.Duration
+	}{{from, &w.start}, {to, &w.end}} {
+		hour, minute, err := parseClock(p.at)
+		if err != nil {
+			return nil, fmt.Errorf("window %q: %w", expr, err)
+		}
+		*p.d = time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute
+	}
+	if w.start == w.end {
+		return nil, fmt.Errorf("window %q is empty", expr)
+	}
+
+	dow, err := parseField(dayField(days), dowField)
+	if err != nil {
+		return nil, fmt.Errorf("window %q: %w", expr, err)
+	}
+	if dow&(1<<7) != 0 {
+		dow |= 1
+	}
+	w.dow = dow
+	return w, nil
+}
+
+// String returns the window as written.
+func (w *Window) String() string {
+	return w.expr
+}
+
+// Contains reports whether t, in its location, falls within the window.
+func (w *Window) Contains(t time.Time) bool {
+	tod := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+	if w.start < w.end {
+		return w.on(t.Weekday()) && tod >= w.start && tod < w.end
+	}
+	if tod >= w.start {
+		return w.on(t.Weekday())
+	}
+	// Past midnight: the span started the day before
+	return tod < w.end && w.on((t.Weekday()+6)%7)
+}
+
+// NextStart returns the first time at or after t at which the window opens.
+func (w *Window) NextStart(t time.Time) time.Time {
+	for d := 0; d <= 7; d++ {
+		day := time.Date(t.Year(), t.Month(), t.Day()+d, 0, 0, 0, 0, t.Location())
+		if !w.on(day.Weekday()) {
+			continue
+		}
+		start := day.Add(w.start)
+		if !start.Before(t) {
+			return start
+		}
+	}
+	return time.Time{}
+}
+
+func (w *Window) on(day time.Weekday) bool {
+	return w.dow&(1<<uint(day)) != 0
+}
+
+// Windows is a set of windows, open whenever any of them is.
+type Windows []*Window
+
+// ParseWindows parses each of exprs with ParseWindow.
+func ParseWindows(exprs []string) (Windows, error) {
+	ws := make(Windows, 0, len(exprs))
+	for _, expr := range exprs {
+		w, err := ParseWindow(expr)
+		if err != nil {
+			return nil, err
+		}
+		ws = append(ws, w)
+	}
+	return ws, nil
+}
+
+// Contains reports whether t falls within any of the windows.
+func (ws Windows) Contains(t time.Time) bool {
+	for _, w := range ws {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns t if the windows are open at t, otherwise the first time
+// after t at which one opens (zero if there are no windows).
+func (ws Windows) NextOpen(t time.Time) time.Time {
+	if ws.Contains(t) {
+		return t
+	}
+	var next time.Time
+	for _, w := range ws {
+		if s := w.NextStart(t); !s.IsZero() && (next.IsZero() || s.Before(next)) {
+			next = s
+		}
+	}
+	return next
+}
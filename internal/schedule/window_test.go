@@ -0,0 +1,83 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWindowErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"weekday",
+		"weekday 09:00",
+		"weekday 9am-5pm",
+		"weekday 09:00-25:00",
+		"someday 09:00-17:00",
+		"daily 09:00-09:00",
+		"mon 09:00-17:00 extra",
+	} {
+		if _, err := ParseWindow(expr); err == nil {
+			t.Errorf("ParseWindow(%q) succeeded", expr)
+		}
+	}
+}
+
+func TestWindowContains(t *testing.T) {
+	// 2026-03-02 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		expr string
+		t    time.Time
+		want bool
+	}{
+		{"weekday 09:00-18:00", at(2, 9, 0), true},
+		{"weekday 09:00-18:00", at(2, 17, 59), true},
+		{"weekday 09:00-18:00", at(2, 18, 0), false},
+		{"weekday 09:00-18:00", at(2, 8, 59), false},
+		{"weekday 09:00-18:00", at(7, 12, 0), false}, // Saturday
+		{"09:00-17:00", at(8, 12, 0), true},          // Daily by default
+		{"mon-thu 22:00-02:00", at(2, 23, 0), true},
+		{"mon-thu 22:00-02:00", at(3, 1, 30), true},  // Monday night
+		{"mon-thu 22:00-02:00", at(2, 1, 30), false}, // Sunday night
+		{"mon-thu 22:00-02:00", at(6, 1, 30), true},  // Thursday night
+		{"sat,sun 10:00-14:00", at(8, 10, 0), true},
+	}
+	for _, tt := range tests {
+		w, err := ParseWindow(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseWindow(%q): %v", tt.expr, err)
+		}
+		if got := w.Contains(tt.t); got != tt.want {
+			t.Errorf("%q.Contains(%s) = %v, want %v", tt.expr, tt.t.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestWindowsNextOpen(t *testing.T) {
+	ws, err := ParseWindows([]string{"weekday 09:00-18:00", "sat 10:00-12:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		t, want time.Time
+	}{
+		// Open now
+		{time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)},
+		// Monday evening: Tuesday morning
+		{time.Date(2026, 3, 2, 19, 0, 0, 0, time.UTC), time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC)},
+		// Friday evening: Saturday's window
+		{time.Date(2026, 3, 6, 19, 0, 0, 0, time.UTC), time.Date(2026, 3, 7, 10, 0, 0, 0, time.UTC)},
+		// Saturday afternoon: Monday
+		{time.Date(2026, 3, 7, 13, 0, 0, 0, time.UTC), time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := ws.NextOpen(tt.t); !got.Equal(tt.want) {
+			t.Errorf("NextOpen(%s) = %s, want %s", tt.t.Format("Mon 15:04"), got.Format("Mon 15:04"), tt.want.Format("Mon 15:04"))
+		}
+	}
+	if got := (Windows{}).NextOpen(time.Now()); !got.IsZero() {
+		t.Errorf("empty NextOpen = %v, want zero", got)
+	}
+}
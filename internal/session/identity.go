@@ -4,6 +4,8 @@ package session
 import (
 	"fmt"
 	"strings"
+
+	"github.com/steveyegge/gastown/internal/identity"
 )
 
 // Role represents the type of Gas Town agent.
@@ -115,7 +117,12 @@ func (a *AgentIdentity) SessionName() string {
 	}
 }
 
-// Address returns the mail-style address for this identity.
+// Identity returns the agent's identity.
+func (a *AgentIdentity) Identity() identity.Identity {
+	return identity.New(identity.Role(a.Role), a.Rig, a.Name)
+}
+
+// Address returns the agent's BD_ACTOR-style address.
 // Examples:
 //   - mayor → "mayor"
 //   - deacon → "deacon"
@@ -124,22 +131,7 @@ func (a *AgentIdentity) SessionName() string {
 //   - crew → "gastown/crew/max"
 //   - polecat → "gastown/polecats/Toast"
 func (a *AgentIdentity) Address() string {
-	switch a.Role {
-	case RoleMayor:
-		return "mayor"
-	case RoleDeacon:
-		return "deacon"
-	case RoleWitness:
-		return fmt.Sprintf("%s/witness", a.Rig)
-	case RoleRefinery:
-		return fmt.Sprintf("%s/refinery", a.Rig)
-	case RoleCrew:
-		return fmt.Sprintf("%s/crew/%s", a.Rig, a.Name)
-	case RolePolecat:
-		return fmt.Sprintf("%s/polecats/%s", a.Rig, a.Name)
-	default:
-		return ""
-	}
+	return a.Identity().Actor()
 }
 
 // GTRole returns the GT_ROLE environment variable format.
@@ -0,0 +1,249 @@
+// Package slo measures service level objectives for the merge queue.
+//
+// An objective says what share of merge requests must merge within a time
+// of being submitted (gt done), over a rolling window, e.g. 90% of MRs
+// merged within 2h over 7d. The allowed misses (10% of MRs here) are the
+// error budget. A request counts as missed when it merges late, or when it
+// is still unmerged after the target time, so a stalled queue burns budget
+// without waiting for its MRs to land.
+//
+// Objectives are configured under "slos" in mayor/daemon.json. gt stats
+// reports their attainment, and the daemon alerts when a budget is
+// exhausted (see package alert).
+package slo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/logrotate"
+)
+
+// DefaultWindow is the window of an objective that sets none.
+const DefaultWindow = 7 * 24 * time.Hour
+
+// Objective is one service level objective for the merge queue.
+type Objective struct {
+	// Name identifies the objective in reports and alerts.
+	Name string `json:"name"`
+
+	// Disabled stops measuring and alerting on the objective.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Rig restricts the objective to one rig's merge queue.
+	Rig string `json:"rig,omitempty"`
+
+	// Target is the share of MRs that must meet the objective, e.g. 0.9.
+	Target float64 `json:"target"`
+
+	// Within is how soon after submission an MR must merge, e.g. "2h".
+	Within string `json:"within"`
+
+	// Window is the rolling span MRs are counted over, e.g. "7d" (default "7d").
+	Window string `json:"window,omitempty"`
+}
+
+// Durations validates the objective and returns its parsed Within and Window.
+func (o Objective) Durations() (within, window time.Duration, err error) {
+	if o.Name == "" {
+		return 0, 0, errors.New("slo has no name")
+	}
+	if o.Target <= 0 || o.Target > 1 {
+		return 0, 0, fmt.Errorf("slo %s: target must be between 0 and 1 (e.g. 0.9), got %g", o.Name, o.Target)
+	}
+	if within, err = logrotate.ParseDuration(o.Within); err != nil {
+		return 0, 0, fmt.Errorf("slo %s: within: %w", o.Name, err)
+	}
+	if within <= 0 {
+		return 0, 0, fmt.Errorf("slo %s: needs within", o.Name)
+	}
+	if window, err = logrotate.ParseDuration(o.Window); err != nil {
+		return 0, 0, fmt.Errorf("slo %s: window: %w", o.Name, err)
+	}
+	if window == 0 {
+		window = DefaultWindow
+	}
+	return within, window, nil
+}
+
+// Validate checks a set of objectives: each must be well formed and names
+// must be unique.
+func Validate(objectives []Objective) error {
+	seen := make(map[string]bool)
+	for _, o := range objectives {
+		if _, _, err := o.Durations(); err != nil {
+			return err
+		}
+		if seen[o.Name] {
+			return fmt.Errorf("duplicate slo %q", o.Name)
+		}
+		seen[o.Name] = true
+	}
+	return nil
+}
+
+// Find returns the enabled objective with the given name.
+func Find(objectives []Objective, name string) (Objective, bool) {
+	for _, o := range objectives {
+		if o.Name == name && !o.Disabled {
+			return o, true
+		}
+	}
+	return Objective{}, false
+}
+
+// Describe summarizes an objective, e.g. "90% of MRs in gastown merged
+// within 2h over 7d". It does not validate the objective.
+func Describe(o Objective) string {
+	window := o.Window
+	if window == "" {
+		window = "7d"
+	}
+	scope := ""
+	if o.Rig != "" {
+		scope = " in " + o.Rig
+	}
+	return fmt.Sprintf("%s of MRs%s merged within %s over %s", formatPercent(o.Target), scope, o.Within, window)
+}
+
+// Status is an objective's attainment over its window.
+type Status struct {
+	Name   string  `json:"name"`
+	Rig    string  `json:"rig,omitempty"`
+	Target float64 `json:"target"`
+	Within string  `json:"within"`
+	Window string  `json:"window"`
+
+	// Met and Missed count MRs submitted in the window that merged in
+	// time, and that merged late or are overdue.
+	Met    int `json:"met"`
+	Missed int `json:"missed"`
+
+	// Pending counts MRs submitted in the window, unmerged but not yet due.
+	Pending int `json:"pending"`
+
+	// Attainment is Met / (Met + Missed); 1 with nothing measured.
+	Attainment float64 `json:"attainment"`
+
+	// BudgetRemaining is the share of the error budget left: 1 untouched,
+	// 0 exhausted, negative when overspent.
+	BudgetRemaining float64 `json:"budget_remaining"`
+
+	// BurnRate is the miss rate relative to the budget: 1 spends the
+	// budget exactly over the window, 2 twice as fast.
+	BurnRate float64 `json:"burn_rate"`
+
+	// Exhausted reports whether the error budget is used up.
+	Exhausted bool `json:"exhausted"`
+}
+
+// Summary describes the status in one line for alerts.
+func (s Status) Summary() string {
+	return fmt.Sprintf("%s of %d MRs merged within %s over %s (target %s), error budget %s",
+		formatPercent(s.Attainment), s.Met+s.Missed, s.Within, s.Window, formatPercent(s.Target), s.BudgetString())
+}
+
+// BudgetString formats the remaining error budget, e.g. "40% left" or
+// "exhausted (-25%)".
+func (s Status) BudgetString() string {
+	if s.Exhausted {
+		if s.BudgetRemaining < 0 {
+			return fmt.Sprintf("exhausted (%s)", formatPercent(s.BudgetRemaining))
+		}
+		return "exhausted"
+	}
+	return formatPercent(s.BudgetRemaining) + " left"
+}
+
+// Measure computes an objective's status at now from the events log.
+// evts must be oldest first and cover the objective's window.
+func Measure(o Objective, evts []events.Event, now time.Time) (Status, error) {
+	within, window, err := o.Durations()
+	if err != nil {
+		return Status{}, err
+	}
+	s := Status{Name: o.Name, Rig: o.Rig, Target: o.Target, Within: o.Within, Window: o.Window}
+	if s.Window == "" {
+		s.Window = "7d"
+	}
+	start := now.Add(-window)
+
+	// An MR is submitted by its first gt done and settled by its merge;
+	// resubmitting after a failed merge does not restart the clock.
+	submitted := make(map[string]time.Time)
+	for _, e := range evts {
+		if o.Rig != "" && e.Rig != o.Rig {
+			continue
+		}
+		branch, _ := e.Payload["branch"].(string)
+		ts := e.Time()
+		if branch == "" || ts.IsZero() || ts.After(now) {
+			continue
+		}
+		key := e.Rig + "\x00" + branch
+		switch e.Type {
+		case events.TypeDone:
+			if exit, _ := e.Payload["exit"].(string); exit != "" && exit != "COMPLETED" {
+				continue // Escalated or deferred: nothing was submitted
+			}
+			if _, ok := submitted[key]; !ok {
+				submitted[key] = ts
+			}
+		case events.TypeMerged:
+			at, ok := submitted[key]
+			if !ok {
+				continue
+			}
+			delete(submitted, key)
+			if at.Before(start) {
+				continue
+			}
+			if ts.Sub(at) <= within {
+				s.Met++
+			} else {
+				s.Missed++
+			}
+		}
+	}
+	for _, at := range submitted {
+		switch {
+		case at.Before(start):
+		case now.Sub(at) > within:
+			s.Missed++
+		default:
+			s.Pending++
+		}
+	}
+
+	s.finish()
+	return s, nil
+}
+
+// finish derives the attainment and budget figures from the counts.
+func (s *Status) finish() {
+	measured := s.Met + s.Missed
+	s.Attainment, s.BudgetRemaining = 1, 1
+	if measured == 0 {
+		return
+	}
+	s.Attainment = float64(s.Met) / float64(measured)
+	missRate := float64(s.Missed) / float64(measured)
+	budget := 1 - s.Target
+	if budget <= 0 {
+		// A 100% target has no budget: any miss exhausts it
+		if s.Missed > 0 {
+			s.BudgetRemaining, s.Exhausted = 0, true
+		}
+		return
+	}
+	s.BurnRate = missRate / budget
+	s.BudgetRemaining = 1 - s.BurnRate
+	s.Exhausted = s.Missed > 0 && s.BudgetRemaining <= 0
+}
+
+// formatPercent formats a fraction as a percentage, e.g. 0.9 → "90%".
+func formatPercent(f float64) string {
+	return fmt.Sprintf("%.4g%%", f*100)
+}
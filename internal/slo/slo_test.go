@@ -0,0 +1,119 @@
+package slo
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+func TestDurations(t *testing.T) {
+	for _, tt := range []struct {
+		o   Objective
+		err string
+	}{
+		{Objective{Name: "ok", Target: 0.9, Within: "2h"}, ""},
+		{Objective{Name: "all", Target: 1, Within: "30m", Window: "1d"}, ""},
+		{Objective{Target: 0.9, Within: "2h"}, "no name"},
+		{Objective{Name: "x", Target: 90, Within: "2h"}, "between 0 and 1"},
+		{Objective{Name: "x", Target: 0.9}, "needs within"},
+		{Objective{Name: "x", Target: 0.9, Within: "2h", Window: "soon"}, "window"},
+	} {
+		_, window, err := tt.o.Durations()
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.o.Name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%+v: error = %v, want %q", tt.o, err, tt.err)
+		case tt.err == "" && tt.o.Window == "" && window != DefaultWindow:
+			t.Errorf("%s: window = %v, want default", tt.o.Name, window)
+		}
+	}
+
+	dup := []Objective{{Name: "a", Target: 0.9, Within: "1h"}, {Name: "a", Target: 0.5, Within: "1h"}}
+	if err := Validate(dup); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("Validate(duplicates) = %v", err)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	ev := func(ago time.Duration, typ, rig, branch string) events.Event {
+		return events.Event{Timestamp: now.Add(-ago).Format(time.RFC3339), Type: typ, Rig: rig, Payload: map[string]interface{}{"branch": branch}}
+	}
+	done := func(ago time.Duration, rig, branch, exit string) events.Event {
+		return events.Event{Timestamp: now.Add(-ago).Format(time.RFC3339), Type: events.TypeDone, Rig: rig, Payload: events.DonePayload("gt-x", branch, exit)}
+	}
+	h := time.Hour
+	evts := []events.Event{
+		// Submitted before the 1d window: ignored even though merged inside it
+		done(30*h, "gastown", "polecat/old", "COMPLETED"),
+		ev(20*h, events.TypeMerged, "gastown", "polecat/old"),
+
+		// Met: merged 1h after submission
+		done(10*h, "gastown", "polecat/a", "COMPLETED"),
+		ev(9*h, events.TypeMerged, "gastown", "polecat/a"),
+
+		// Met: failed, resubmitted, merged 90m after the first gt done
+		done(8*h, "gastown", "polecat/b", "COMPLETED"),
+		ev(7*h+30*time.Minute, events.TypeMergeFailed, "gastown", "polecat/b"),
+		done(7*h, "gastown", "polecat/b", "COMPLETED"),
+		ev(6*h+30*time.Minute, events.TypeMerged, "gastown", "polecat/b"),
+
+		// Missed: merged 3h after submission
+		done(6*h, "gastown", "polecat/c", "COMPLETED"),
+		ev(3*h, events.TypeMerged, "gastown", "polecat/c"),
+
+		// Missed: still unmerged after 5h
+		done(5*h, "gastown", "polecat/d", "COMPLETED"),
+
+		// Pending: submitted 30m ago
+		done(30*time.Minute, "gastown", "polecat/e", "COMPLETED"),
+
+		// Escalated: never submitted
+		done(4*h, "gastown", "polecat/f", "ESCALATED"),
+
+		// Other rig
+		done(4*h, "beads", "polecat/g", "COMPLETED"),
+		ev(3*h, events.TypeMerged, "beads", "polecat/g"),
+	}
+
+	st, err := Measure(Objective{Name: "merge-2h", Rig: "gastown", Target: 0.75, Within: "2h", Window: "1d"}, evts, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Met != 2 || st.Missed != 2 || st.Pending != 1 {
+		t.Fatalf("met/missed/pending = %d/%d/%d, want 2/2/1", st.Met, st.Missed, st.Pending)
+	}
+	// 50% attained against a 75% target: twice the budget spent
+	if st.Attainment != 0.5 || st.BurnRate != 2 || st.BudgetRemaining != -1 || !st.Exhausted {
+		t.Errorf("status = %+v, want attainment 0.5, burn 2, budget -1, exhausted", st)
+	}
+	if got := st.BudgetString(); got != "exhausted (-100%)" {
+		t.Errorf("BudgetString = %q", got)
+	}
+
+	// Town-wide with a looser target: within budget
+	st, _ = Measure(Objective{Name: "town", Target: 0.5, Within: "4h", Window: "1d"}, evts, now)
+	if st.Met != 4 || st.Missed != 1 || st.Exhausted {
+		t.Errorf("town status = %+v, want 4 met, 1 missed, not exhausted", st)
+	}
+	if math.Abs(st.BudgetRemaining-0.6) > 1e-9 {
+		t.Errorf("budget remaining = %v, want 0.6", st.BudgetRemaining)
+	}
+
+	// Nothing measured: full budget
+	st, _ = Measure(Objective{Name: "empty", Rig: "greenplace", Target: 0.9, Within: "1h"}, evts, now)
+	if st.Attainment != 1 || st.BudgetRemaining != 1 || st.Exhausted {
+		t.Errorf("empty status = %+v", st)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	got := Describe(Objective{Name: "m", Rig: "gastown", Target: 0.9, Within: "2h"})
+	if want := "90% of MRs in gastown merged within 2h over 7d"; got != want {
+		t.Errorf("Describe = %q, want %q", got, want)
+	}
+}
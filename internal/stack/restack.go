@@ -0,0 +1,299 @@
+package stack
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// Action is what a restack did with one branch.
+type Action string
+
+const (
+	ActionUpToDate  Action = "up-to-date" // Already on the branch below
+	ActionRestacked Action = "restacked"  // Rebased and force-pushed
+	ActionLanded    Action = "landed"     // Merged into the target since the last restack
+	ActionConflict  Action = "conflict"   // Rebase conflicted; a task was filed
+	ActionBlocked   Action = "blocked"    // Waiting on a conflict task or a branch below
+	ActionMissing   Action = "missing"    // Branch is gone from the remote but never landed
+	ActionFailed    Action = "failed"
+)
+
+// Result is the outcome of restacking one branch.
+type Result struct {
+	Branch     string   `json:"branch"`
+	Worker     string   `json:"worker,omitempty"`
+	Action     Action   `json:"action"`
+	Onto       string   `json:"onto,omitempty"`    // Branch below
+	OldTip     string   `json:"old_tip,omitempty"` // Before the restack
+	NewTip     string   `json:"new_tip,omitempty"` // After the restack
+	Conflicts  []string `json:"conflicts,omitempty"`
+	Task       string   `json:"task,omitempty"`        // Conflict task
+	CheckedOut bool     `json:"checked_out,omitempty"` // Branch is checked out in a worktree
+	Error      string   `json:"error,omitempty"`
+}
+
+// Restacker rebases a stack's branches onto the branches below them.
+type Restacker struct {
+	// Repo is the rig's shared repository, with push credentials.
+	// Branches are rebased in a temporary detached worktree of it.
+	Repo *git.Git
+
+	// Remote holds the stacked branches (default "origin").
+	Remote string
+
+	// DryRun reports what would be restacked without rebasing or pushing.
+	DryRun bool
+
+	// From limits the restack to the branches above this one, for a branch
+	// that just changed: it and the branches below are left as they are.
+	From string
+
+	// TaskOpen reports whether a conflict task is still open. If nil,
+	// conflict tasks never close.
+	TaskOpen func(id string) bool
+
+	// FileConflict files a conflict-resolution task for a restack of e that
+	// conflicted and returns its ID. If nil, no task is filed and the
+	// conflict is retried on every restack.
+	FileConflict func(s *Stack, e *Entry, res *Result) (string, error)
+}
+
+// NewRestacker returns a Restacker for the rig at rigPath. It works in the
+// rig's shared repository: the bare repo polecat worktrees come from, or
+// mayor/rig in rigs without one.
+func NewRestacker(rigPath string) (*Restacker, error) {
+	bare := filepath.Join(rigPath, ".repo.git")
+	if info, err := os.Stat(bare); err == nil && info.IsDir() {
+		return &Restacker{Repo: rig.WithGitAuth(rigPath, git.NewGitWithDir(bare, ""))}, nil
+	}
+	mayor := filepath.Join(rigPath, "mayor", "rig")
+	if _, err := os.Stat(mayor); err != nil {
+		return nil, fmt.Errorf("no repo base found (neither .repo.git nor mayor/rig exists)")
+	}
+	return &Restacker{Repo: rig.WithGitAuth(rigPath, git.NewGit(mayor))}, nil
+}
+
+func (r *Restacker) remote() string {
+	if r.Remote == "" {
+		return "origin"
+	}
+	return r.Remote
+}
+
+// Restack fetches the remote and restacks s bottom to top, recording each
+// branch's new base and tip in s. A branch that lands drops out of the stack
+// and the branches above it are restacked onto the branch below it. A
+// branch that can't be restacked blocks the branches above it.
+func (r *Restacker) Restack(s *Stack) ([]Result, error) {
+	remote := r.remote()
+	if err := r.Repo.Fetch(remote); err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", remote, err)
+	}
+	targetRef := remote + "/" + s.Target
+	if _, err := r.Repo.Rev(targetRef); err != nil {
+		return nil, fmt.Errorf("stack target %s not found on %s", s.Target, remote)
+	}
+	checkedOut := r.checkedOut()
+
+	var results []Result
+	blocker := ""         // Branch holding up the rest of the stack
+	moved := false        // A branch below was (or in a dry run would be) restacked
+	below := r.From != "" // Still at or below From
+	for i, e := range s.Entries {
+		if below {
+			below = e.Branch != r.From
+			continue
+		}
+		if e.Landed {
+			continue
+		}
+		res := Result{Branch: e.Branch, Worker: e.Worker, Onto: s.Parent(i), CheckedOut: checkedOut[e.Branch]}
+		if blocker != "" {
+			res.Action, res.Error = ActionBlocked, "waiting on "+blocker
+			results = append(results, res)
+			continue
+		}
+
+		tip, _ := r.Repo.Rev(remote + "/" + e.Branch)
+		if tip != "" && !r.DryRun {
+			e.Tip = tip
+		}
+		if r.landed(targetRef, e, tip) {
+			res.Action = ActionLanded
+			if !r.DryRun {
+				e.Landed, e.ConflictTask = true, ""
+			}
+			results = append(results, res)
+			continue
+		}
+		if tip == "" {
+			res.Action, res.Error = ActionMissing, fmt.Sprintf("%s/%s not found", remote, e.Branch)
+			results = append(results, res)
+			blocker = e.Branch
+			continue
+		}
+		if e.ConflictTask != "" {
+			if r.TaskOpen == nil || r.TaskOpen(e.ConflictTask) {
+				res.Action, res.Task = ActionBlocked, e.ConflictTask
+				res.Error = "conflict task " + e.ConflictTask + " is open"
+				results = append(results, res)
+				blocker = e.Branch
+				continue
+			}
+			if !r.DryRun {
+				e.ConflictTask = "" // Resolved: check the branch again
+			}
+		}
+
+		onto, err := r.Repo.Rev(remote + "/" + res.Onto)
+		if err != nil {
+			res.Action, res.Error = ActionFailed, fmt.Sprintf("%s/%s not found", remote, res.Onto)
+			results = append(results, res)
+			blocker = e.Branch
+			continue
+		}
+		if !moved && r.onParent(e, tip, onto) {
+			res.Action = ActionUpToDate
+			if !r.DryRun {
+				e.Base = onto
+			}
+			results = append(results, res)
+			continue
+		}
+		res.OldTip = tip
+		if r.DryRun {
+			res.Action = ActionRestacked
+			results = append(results, res)
+			moved = true
+			continue
+		}
+
+		r.restack(s, e, &res, tip, onto)
+		results = append(results, res)
+		if res.Action != ActionRestacked {
+			blocker = e.Branch
+		}
+	}
+	return results, nil
+}
+
+// restack rebases e onto onto and pushes it, filling in res.
+func (r *Restacker) restack(s *Stack, e *Entry, res *Result, tip, onto string) {
+	upstream := e.Base
+	if upstream == "" {
+		upstream, _ = r.Repo.MergeBase(onto, tip)
+	}
+	newTip, conflicts, err := r.rebase(tip, onto, upstream)
+	switch {
+	case len(conflicts) > 0:
+		res.Action, res.Conflicts = ActionConflict, conflicts
+		if r.FileConflict != nil {
+			task, err := r.FileConflict(s, e, res)
+			if err != nil {
+				res.Error = fmt.Sprintf("filing conflict task: %v", err)
+			}
+			res.Task, e.ConflictTask = task, task
+		}
+		return
+	case err != nil:
+		res.Action, res.Error = ActionFailed, err.Error()
+		return
+	}
+
+	if err := r.Repo.PushRefWithLease(r.remote(), newTip, e.Branch, tip); err != nil {
+		var leaseErr *git.LeaseError
+		if errors.As(err, &leaseErr) {
+			// Pushed to while we rebased: the next restack picks it up
+			res.Action, res.Error = ActionFailed, leaseErr.Error()
+			return
+		}
+		res.Action, res.Error = ActionFailed, fmt.Sprintf("pushing %s: %v", e.Branch, err)
+		return
+	}
+	// Keep the local branch in step unless someone has it checked out; they
+	// are told to rebase instead.
+	if local, err := r.Repo.Rev(e.Branch); err == nil && local == tip && !res.CheckedOut {
+		_ = r.Repo.ResetBranch(e.Branch, newTip)
+	}
+	e.Base, e.Tip = onto, newTip
+	res.Action, res.NewTip = ActionRestacked, newTip
+}
+
+// rebase replays the commits after upstream on tip onto onto, in a
+// temporary detached worktree, and returns the new tip. On conflict the
+// rebase is abandoned and the conflicting files are returned.
+func (r *Restacker) rebase(tip, onto, upstream string) (newTip string, conflicts []string, err error) {
+	dir, err := os.MkdirTemp("", "gt-restack-")
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	if err := r.Repo.WorktreeAddDetached(dir, tip); err != nil {
+		return "", nil, fmt.Errorf("creating restack worktree: %w", err)
+	}
+	defer func() {
+		_ = r.Repo.WorktreeRemove(dir, true)
+		_ = r.Repo.WorktreePrune()
+	}()
+
+	wt := git.NewGit(dir)
+	if err := wt.RebaseOnto(onto, upstream); err != nil {
+		// ZFC: Use git's porcelain output to detect conflicts instead of parsing stderr.
+		conflicts, _ = wt.GetConflictingFiles()
+		_ = wt.AbortRebase()
+		return "", conflicts, err
+	}
+	newTip, err = wt.Rev("HEAD")
+	return newTip, nil, err
+}
+
+// onParent reports whether the branch at tip already sits on onto, the tip
+// of the branch below. A branch whose base is still in its history but no
+// longer below it (the branch below was rewritten or dropped from the stack)
+// carries stale commits and needs a restack even if it contains onto.
+func (r *Restacker) onParent(e *Entry, tip, onto string) bool {
+	if ok, _ := r.Repo.IsAncestor(onto, tip); !ok {
+		return false
+	}
+	if e.Base == "" || e.Base == onto {
+		return true
+	}
+	if ok, _ := r.Repo.IsAncestor(e.Base, onto); ok {
+		return true
+	}
+	stale, _ := r.Repo.IsAncestor(e.Base, tip)
+	return !stale
+}
+
+// landed reports whether the branch's commits are all on the target. A
+// branch with no commits of its own hasn't landed; it hasn't started.
+func (r *Restacker) landed(targetRef string, e *Entry, tip string) bool {
+	if tip == "" {
+		tip = e.Tip
+	}
+	if tip == "" || tip == e.Base {
+		return false
+	}
+	unlanded, err := r.Repo.UnlandedCommits(targetRef, tip)
+	return err == nil && len(unlanded) == 0
+}
+
+// checkedOut returns the branches checked out in the repo's worktrees.
+func (r *Restacker) checkedOut() map[string]bool {
+	out := make(map[string]bool)
+	worktrees, err := r.Repo.WorktreeList()
+	if err != nil {
+		return out
+	}
+	for _, wt := range worktrees {
+		if wt.Branch != "" {
+			out[wt.Branch] = true
+		}
+	}
+	return out
+}
@@ -0,0 +1,269 @@
+package stack
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// repo is a clone of a bare origin, standing in for a rig's shared repo.
+type repo struct {
+	t   *testing.T
+	dir string
+	g   *git.Git
+}
+
+func newRepo(t *testing.T) *repo {
+	t.Helper()
+	origin := t.TempDir()
+	r := &repo{t: t, dir: t.TempDir()}
+	r.git(origin, "init", "--bare")
+	r.git(r.dir, "init")
+	r.git(r.dir, "config", "user.email", "test@test.com")
+	r.git(r.dir, "config", "user.name", "Test User")
+	r.git(r.dir, "remote", "add", "origin", origin)
+	r.git(r.dir, "checkout", "-b", "main")
+	r.commit("README.md", "# Test\n")
+	r.git(r.dir, "push", "origin", "main")
+	r.g = git.NewGit(r.dir)
+	return r
+}
+
+func (r *repo) git(dir string, args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commit commits file on the checked-out branch and returns the commit.
+func (r *repo) commit(file, content string) string {
+	r.t.Helper()
+	if err := os.WriteFile(filepath.Join(r.dir, file), []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+	r.git(r.dir, "add", file)
+	r.git(r.dir, "commit", "-m", "edit "+file)
+	return r.git(r.dir, "rev-parse", "HEAD")
+}
+
+// branch starts branch at from, commits file, and pushes the branch.
+func (r *repo) branch(branch, from, file, content string) string {
+	r.t.Helper()
+	r.git(r.dir, "checkout", "-B", branch, from)
+	sha := r.commit(file, content)
+	r.git(r.dir, "push", "-f", "origin", branch)
+	r.git(r.dir, "checkout", "--detach")
+	return sha
+}
+
+func (r *repo) remoteTip(branch string) string {
+	r.t.Helper()
+	return r.git(r.dir, "rev-parse", "origin/"+branch)
+}
+
+func (r *repo) contains(ancestor, branch string) bool {
+	ok, err := r.g.IsAncestor(ancestor, "origin/"+branch)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return ok
+}
+
+// newStack stacks a on main and b on a.
+func newStack(r *repo) *Stack {
+	main := r.remoteTip("main")
+	a := r.branch("a", "main", "a.txt", "a\n")
+	b := r.branch("b", "a", "b.txt", "b\n")
+	return &Stack{Epic: "gt-epic", Target: "main", Entries: []*Entry{
+		{Branch: "a", Base: main, Tip: a},
+		{Branch: "b", Base: a, Tip: b},
+	}}
+}
+
+func actions(results []Result) string {
+	var out []string
+	for _, res := range results {
+		out = append(out, res.Branch+"="+string(res.Action))
+	}
+	return strings.Join(out, " ")
+}
+
+func TestRestackAncestorChanged(t *testing.T) {
+	r := newRepo(t)
+	s := newStack(r)
+	rs := &Restacker{Repo: r.g}
+
+	results, err := rs.Restack(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := actions(results); got != "a=up-to-date b=up-to-date" {
+		t.Fatalf("fresh stack: %s", got)
+	}
+
+	// a gets a new commit: b is rebased onto it
+	r.git(r.dir, "checkout", "a")
+	newA := r.commit("a2.txt", "a2\n")
+	r.git(r.dir, "push", "origin", "a")
+	r.git(r.dir, "checkout", "--detach")
+
+	dry := &Restacker{Repo: r.g, DryRun: true}
+	if results, _ := dry.Restack(s); actions(results) != "a=up-to-date b=restacked" {
+		t.Errorf("dry run: %s", actions(results))
+	}
+	if r.contains(newA, "b") {
+		t.Fatal("dry run pushed b")
+	}
+
+	// Restacking from a, the branch that changed, leaves a alone
+	results, err = (&Restacker{Repo: r.g, From: "a"}).Restack(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := actions(results); got != "b=restacked" {
+		t.Fatalf("after a changed: %s", got)
+	}
+	if !r.contains(newA, "b") {
+		t.Error("b not restacked onto a's new tip")
+	}
+	if s.Entries[1].Base != newA || s.Entries[1].Tip != r.remoteTip("b") {
+		t.Errorf("b entry = %+v", s.Entries[1])
+	}
+	if results[0].OldTip == "" || results[0].NewTip != r.remoteTip("b") {
+		t.Errorf("b result = %+v", results[0])
+	}
+}
+
+func TestRestackAncestorLanded(t *testing.T) {
+	r := newRepo(t)
+	s := newStack(r)
+	oldA := r.remoteTip("a")
+
+	// main moves on, then a lands on it and its branch is deleted
+	r.git(r.dir, "checkout", "main")
+	r.commit("main.txt", "main\n")
+	r.git(r.dir, "merge", "--no-ff", "-m", "Merge a", "origin/a")
+	r.git(r.dir, "push", "origin", "main")
+	r.git(r.dir, "push", "origin", "--delete", "a")
+	r.git(r.dir, "checkout", "--detach")
+
+	rs := &Restacker{Repo: r.g}
+	results, err := rs.Restack(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := actions(results); got != "a=landed b=restacked" {
+		t.Fatalf("after a landed: %s", got)
+	}
+	if !s.Entries[0].Landed || results[1].Onto != "main" {
+		t.Errorf("a = %+v, b onto %s", s.Entries[0], results[1].Onto)
+	}
+	if !r.contains(r.remoteTip("main"), "b") {
+		t.Error("b not restacked onto main")
+	}
+	if n, _ := r.g.CommitsAhead("origin/main", "origin/b"); n != 1 {
+		t.Errorf("b has %d commits ahead of main, want just its own", n)
+	}
+	if ok, _ := r.g.IsAncestor(oldA, "origin/b"); !ok {
+		// a's commit reaches b through main's merge of it
+		t.Error("b lost a's landed commit")
+	}
+
+	// Landed branches drop out of later restacks
+	results, _ = rs.Restack(s)
+	if got := actions(results); got != "b=up-to-date" {
+		t.Errorf("second restack: %s", got)
+	}
+}
+
+func TestRestackConflict(t *testing.T) {
+	r := newRepo(t)
+	s := newStack(r)
+	c := r.branch("c", "b", "c.txt", "c\n")
+	s.Entries = append(s.Entries, &Entry{Branch: "c", Base: r.remoteTip("b"), Tip: c})
+
+	// a is rewritten to touch the file b changes
+	r.git(r.dir, "checkout", "a")
+	r.commit("b.txt", "from a\n")
+	r.git(r.dir, "push", "origin", "a")
+	r.git(r.dir, "checkout", "--detach")
+
+	open := map[string]bool{}
+	var filed []string
+	rs := &Restacker{
+		Repo:     r.g,
+		TaskOpen: func(id string) bool { return open[id] },
+		FileConflict: func(s *Stack, e *Entry, res *Result) (string, error) {
+			filed = append(filed, e.Branch+":"+strings.Join(res.Conflicts, ","))
+			open["gt-task"] = true
+			return "gt-task", nil
+		},
+	}
+	results, err := rs.Restack(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := actions(results); got != "a=up-to-date b=conflict c=blocked" {
+		t.Fatalf("conflicting restack: %s", got)
+	}
+	if strings.Join(filed, " ") != "b:b.txt" || s.Entries[1].ConflictTask != "gt-task" {
+		t.Errorf("filed = %v, b = %+v", filed, s.Entries[1])
+	}
+	if r.remoteTip("c") != c {
+		t.Error("c moved while b was in conflict")
+	}
+
+	// While the task is open, b waits
+	results, _ = rs.Restack(s)
+	if got := actions(results); got != "a=up-to-date b=blocked c=blocked" {
+		t.Errorf("with task open: %s", got)
+	}
+
+	// Someone resolves it by hand and closes the task: c follows b
+	r.git(r.dir, "checkout", "b")
+	r.git(r.dir, "reset", "--hard", "origin/a")
+	r.commit("b.txt", "b on top of a\n")
+	r.git(r.dir, "push", "-f", "origin", "b")
+	r.git(r.dir, "checkout", "--detach")
+	open["gt-task"] = false
+
+	results, err = rs.Restack(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := actions(results); got != "a=up-to-date b=up-to-date c=restacked" {
+		t.Fatalf("after resolution: %s", got)
+	}
+	if s.Entries[1].ConflictTask != "" || !r.contains(r.remoteTip("b"), "c") {
+		t.Errorf("b = %+v; c restacked onto b: %v", s.Entries[1], r.contains(r.remoteTip("b"), "c"))
+	}
+}
+
+func TestRestackDroppedBranch(t *testing.T) {
+	r := newRepo(t)
+	s := newStack(r)
+	a := r.remoteTip("a")
+
+	// Drop a from the stack: b is rebased onto main without a's commit
+	s.Entries = s.Entries[1:]
+	rs := &Restacker{Repo: r.g}
+	results, err := rs.Restack(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := actions(results); got != "b=restacked" {
+		t.Fatalf("after dropping a: %s", got)
+	}
+	if r.contains(a, "b") {
+		t.Error("b still carries the dropped branch's commit")
+	}
+}
@@ -0,0 +1,225 @@
+// Package stack tracks epic branch stacks: polecat branches for an epic that
+// build on each other. Each branch in a stack is based on the branch below
+// it, and the bottom branch on the stack's target (the epic's integration
+// branch or the rig's default branch).
+//
+// When a branch changes or lands, the branches above it are restacked:
+// rebased onto the new tip of the branch below with git rebase --onto, so
+// dependent polecats keep building on their ancestors' current work instead
+// of each rebasing by hand. A restack that conflicts stops there. The
+// conflict becomes a conflict-resolution task, and the branches above it
+// wait until the task is closed.
+//
+// A rig's stacks live in <rig>/.runtime/stacks.json.
+package stack
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/runstate"
+)
+
+// ErrNotFound is returned when an epic has no stack.
+var ErrNotFound = errors.New("stack not found")
+
+// stateFile is the stacks document in the rig's runtime store.
+const stateFile = "stacks.json"
+
+// Entry is one branch in a stack.
+type Entry struct {
+	Branch string `json:"branch"`
+	Issue  string `json:"issue,omitempty"`  // Bead the branch implements
+	Worker string `json:"worker,omitempty"` // Polecat working on the branch
+
+	// Base is the commit of the branch below that this branch was last
+	// stacked on. Commits after Base are the branch's own; a restack
+	// replays them onto the new tip below.
+	Base string `json:"base,omitempty"`
+
+	// Tip is the branch's last known commit, so a landed branch can be
+	// recognized after its ref has been deleted.
+	Tip string `json:"tip,omitempty"`
+
+	Landed       bool   `json:"landed,omitempty"`
+	ConflictTask string `json:"conflict_task,omitempty"` // Open restack conflict task
+}
+
+// Stack is an epic's branch stack.
+type Stack struct {
+	Epic      string    `json:"epic"`
+	Target    string    `json:"target"`  // Branch the bottom of the stack is based on
+	Entries   []*Entry  `json:"entries"` // Bottom first
+	Created   time.Time `json:"created"`
+	Restacked time.Time `json:"restacked,omitempty"`
+}
+
+// Find returns the entry for branch and its index, or -1 and nil.
+func (s *Stack) Find(branch string) (int, *Entry) {
+	for i, e := range s.Entries {
+		if e.Branch == branch {
+			return i, e
+		}
+	}
+	return -1, nil
+}
+
+// Parent returns the branch the entry at index i is stacked on: the nearest
+// unlanded branch below it, or the stack's target.
+func (s *Stack) Parent(i int) string {
+	for j := i - 1; j >= 0; j-- {
+		if !s.Entries[j].Landed {
+			return s.Entries[j].Branch
+		}
+	}
+	return s.Target
+}
+
+// Top returns the branch a new entry would be stacked on.
+func (s *Stack) Top() string {
+	return s.Parent(len(s.Entries))
+}
+
+// Done reports whether every branch in the stack has landed.
+func (s *Stack) Done() bool {
+	for _, e := range s.Entries {
+		if !e.Landed {
+			return false
+		}
+	}
+	return true
+}
+
+// State is a rig's stacks.
+type State struct {
+	Stacks []*Stack `json:"stacks,omitempty"`
+}
+
+func (st *State) find(epic string) *Stack {
+	for _, s := range st.Stacks {
+		if s.Epic == epic {
+			return s
+		}
+	}
+	return nil
+}
+
+// Store is a rig's stacks.
+type Store struct {
+	doc *runstate.Doc[State]
+	now func() time.Time
+}
+
+// Open returns the stack store of the rig at rigPath.
+func Open(rigPath string) *Store {
+	return &Store{
+		doc: runstate.NewDoc[State](runstate.Open(rigPath), stateFile, nil),
+		now: time.Now,
+	}
+}
+
+// List returns the rig's stacks.
+func (st *Store) List() ([]*Stack, error) {
+	s, err := st.doc.Load()
+	if err != nil {
+		return nil, err
+	}
+	return s.Stacks, nil
+}
+
+// Get returns the epic's stack, or ErrNotFound.
+func (st *Store) Get(epic string) (*Stack, error) {
+	s, err := st.doc.Load()
+	if err != nil {
+		return nil, err
+	}
+	if stk := s.find(epic); stk != nil {
+		return stk, nil
+	}
+	return nil, fmt.Errorf("%w for %s", ErrNotFound, epic)
+}
+
+// Of returns the stack holding branch (landed or not), or nil.
+func (st *Store) Of(branch string) (*Stack, error) {
+	stacks, err := st.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range stacks {
+		if _, e := s.Find(branch); e != nil {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+// Add puts e on top of the epic's stack, creating the stack on target if
+// the epic has none. An empty target keeps an existing stack's target.
+// A branch can be in only one stack.
+func (st *Store) Add(epic, target string, e Entry) (*Stack, error) {
+	var added *Stack
+	_, err := st.doc.Update(func(s *State) error {
+		for _, other := range s.Stacks {
+			if _, dup := other.Find(e.Branch); dup != nil {
+				return fmt.Errorf("branch %s is already stacked on %s", e.Branch, other.Epic)
+			}
+		}
+		stk := s.find(epic)
+		switch {
+		case stk == nil && target == "":
+			return fmt.Errorf("new stack for %s needs a target branch", epic)
+		case stk == nil:
+			stk = &Stack{Epic: epic, Target: target, Created: st.now()}
+			s.Stacks = append(s.Stacks, stk)
+		case target != "" && target != stk.Target:
+			return fmt.Errorf("stack for %s targets %s, not %s", epic, stk.Target, target)
+		}
+		if e.Branch == stk.Target {
+			return fmt.Errorf("cannot stack %s on itself", e.Branch)
+		}
+		stk.Entries = append(stk.Entries, &e)
+		added = stk
+		return nil
+	})
+	return added, err
+}
+
+// Remove drops branch from the epic's stack, or the whole stack if branch
+// is empty. The next restack rebases the branches above a dropped branch
+// onto the branch below it, leaving out the dropped branch's commits.
+func (st *Store) Remove(epic, branch string) error {
+	_, err := st.doc.Update(func(s *State) error {
+		for i, stk := range s.Stacks {
+			if stk.Epic != epic {
+				continue
+			}
+			if branch == "" {
+				s.Stacks = append(s.Stacks[:i], s.Stacks[i+1:]...)
+				return nil
+			}
+			j, e := stk.Find(branch)
+			if e == nil {
+				return fmt.Errorf("branch %s is not in the stack for %s", branch, epic)
+			}
+			stk.Entries = append(stk.Entries[:j], stk.Entries[j+1:]...)
+			return nil
+		}
+		return fmt.Errorf("%w for %s", ErrNotFound, epic)
+	})
+	return err
+}
+
+// Update applies fn to the epic's stack under the store's lock, so
+// concurrent restacks of a rig are serialized. If fn returns an error the
+// stack is left unchanged; runstate.ErrSkip does so without Update failing.
+func (st *Store) Update(epic string, fn func(s *Stack) error) error {
+	_, err := st.doc.Update(func(s *State) error {
+		stk := s.find(epic)
+		if stk == nil {
+			return fmt.Errorf("%w for %s", ErrNotFound, epic)
+		}
+		return fn(stk)
+	})
+	return err
+}
@@ -0,0 +1,71 @@
+package stack
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStoreAddAndRemove(t *testing.T) {
+	st := Open(t.TempDir())
+
+	if _, err := st.Add("gt-epic", "", Entry{Branch: "polecat/a"}); err == nil {
+		t.Error("Add to a new stack without a target should fail")
+	}
+	if _, err := st.Add("gt-epic", "integration/gt-epic", Entry{Branch: "polecat/a", Base: "111"}); err != nil {
+		t.Fatalf("Add a: %v", err)
+	}
+	s, err := st.Add("gt-epic", "", Entry{Branch: "polecat/b"})
+	if err != nil {
+		t.Fatalf("Add b: %v", err)
+	}
+	if s.Target != "integration/gt-epic" || len(s.Entries) != 2 || s.Top() != "polecat/b" {
+		t.Errorf("stack = %+v", s)
+	}
+	if _, err := st.Add("gt-other", "main", Entry{Branch: "polecat/a"}); err == nil {
+		t.Error("a branch should be in only one stack")
+	}
+	if _, err := st.Add("gt-epic", "main", Entry{Branch: "polecat/c"}); err == nil {
+		t.Error("Add with a different target should fail")
+	}
+
+	if s, err := st.Of("polecat/b"); err != nil || s == nil || s.Epic != "gt-epic" {
+		t.Errorf("Of(polecat/b) = %v, %v", s, err)
+	}
+	if s, err := st.Of("polecat/z"); err != nil || s != nil {
+		t.Errorf("Of(polecat/z) = %v, %v; want nil", s, err)
+	}
+
+	if err := st.Remove("gt-epic", "polecat/a"); err != nil {
+		t.Fatalf("Remove a: %v", err)
+	}
+	s, err = st.Get("gt-epic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Entries) != 1 || s.Parent(0) != "integration/gt-epic" {
+		t.Errorf("after Remove, stack = %+v", s)
+	}
+	if err := st.Remove("gt-epic", ""); err != nil {
+		t.Fatalf("Remove stack: %v", err)
+	}
+	if _, err := st.Get("gt-epic"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get removed stack = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStackParent(t *testing.T) {
+	s := &Stack{Target: "main", Entries: []*Entry{
+		{Branch: "a", Landed: true},
+		{Branch: "b"},
+		{Branch: "c", Landed: true},
+		{Branch: "d"},
+	}}
+	for i, want := range []string{"main", "main", "b", "b"} {
+		if got := s.Parent(i); got != want {
+			t.Errorf("Parent(%d) = %s, want %s", i, got, want)
+		}
+	}
+	if s.Top() != "d" || s.Done() {
+		t.Errorf("Top = %s, Done = %v", s.Top(), s.Done())
+	}
+}
@@ -24,7 +24,7 @@ type Templates struct {
 
 // RoleData contains information for rendering role contexts.
 type RoleData struct {
-	Role           string   // mayor, witness, refinery, polecat, crew, deacon
+	Role           string   // mayor, witness, refinery, polecat, crew, deacon, observer
 	RigName        string   // e.g., "greenplace"
 	TownRoot       string   // e.g., "/Users/steve/ai"
 	TownName       string   // e.g., "ai" - the town identifier for session names
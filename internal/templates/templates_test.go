@@ -83,6 +83,36 @@ func TestRenderRole_Polecat(t *testing.T) {
 	}
 }
 
+func TestRenderRole_Observer(t *testing.T) {
+	tmpl, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	data := RoleData{
+		Role:     "observer",
+		TownRoot: "/test/town",
+		TownName: "town",
+		WorkDir:  "/test/town/observers/alice",
+		Polecat:  "alice",
+	}
+
+	output, err := tmpl.RenderRole("observer", data)
+	if err != nil {
+		t.Fatalf("RenderRole() error = %v", err)
+	}
+
+	if !strings.Contains(output, "Observer Context") {
+		t.Error("output missing 'Observer Context'")
+	}
+	if !strings.Contains(output, "/test/town/observers/alice/") {
+		t.Error("output missing observer home")
+	}
+	if !strings.Contains(output, "read-only") {
+		t.Error("output missing read-only notice")
+	}
+}
+
 func TestRenderRole_Deacon(t *testing.T) {
 	tmpl, err := New()
 	if err != nil {
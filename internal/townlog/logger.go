@@ -7,6 +7,9 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/logrotate"
 )
 
 // EventType represents the type of agent lifecycle event.
@@ -40,6 +43,12 @@ const (
 	// Session death events (for crash investigation)
 	EventSessionDeath EventType = "session_death" // Session terminated (with reason)
 	EventMassDeath    EventType = "mass_death"    // Multiple sessions died in short window
+
+	// Identity lock events (for investigating identity collisions)
+	EventLockAcquired  EventType = "lock_acquired"  // Worker identity lock acquired
+	EventLockReleased  EventType = "lock_released"  // Lock released (or force-released)
+	EventLockStale     EventType = "lock_stale"     // Expired/dead lock removed or taken over
+	EventLockCollision EventType = "lock_collision" // Claim refused: another agent holds the lease
 )
 
 // Event represents a single agent lifecycle event.
@@ -48,12 +57,17 @@ type Event struct {
 	Type      EventType `json:"type"`
 	Agent     string    `json:"agent"`            // e.g., "gastown/crew/max" or "gastown/polecats/Toast"
 	Context   string    `json:"context,omitempty"` // Additional context (issue ID, error message, etc.)
+	Detail    string    `json:"detail,omitempty"`  // Formatted detail text, set when parsed from the log
 }
 
+// Source identifies town log events in the unified events log.
+const Source = "townlog"
+
 // Logger handles writing events to the town log file.
 type Logger struct {
-	logPath string
-	mu      sync.Mutex
+	townRoot string
+	logPath  string
+	mu       sync.Mutex
 }
 
 // logDir returns the directory for town logs.
@@ -66,14 +80,22 @@ func logPath(townRoot string) string {
 	return filepath.Join(logDir(townRoot), "town.log")
 }
 
+// LogPath returns the path to the town log file.
+func LogPath(townRoot string) string {
+	return logPath(townRoot)
+}
+
 // NewLogger creates a new Logger for the given town root.
 func NewLogger(townRoot string) *Logger {
 	return &Logger{
-		logPath: logPath(townRoot),
+		townRoot: townRoot,
+		logPath:  logPath(townRoot),
 	}
 }
 
-// LogEvent logs a single event to the town log.
+// LogEvent logs a single event to the town log, and records it in the town
+// events log as an audit-only event envelope (source "townlog") so
+// structured consumers read one format.
 func (l *Logger) LogEvent(event Event) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -96,9 +118,33 @@ func (l *Logger) LogEvent(event Event) error {
 		return fmt.Errorf("writing log line: %w", err)
 	}
 
+	// Best-effort: the town log above is the record of truth
+	_ = events.EmitTo(l.townRoot, Envelope(event))
+
 	return nil
 }
 
+// Envelope converts a town log event to the unified event envelope.
+// The context is kept in the payload, along with the human-readable detail;
+// for spawn and done it is the issue worked on, so it is also the subject.
+func Envelope(e Event) events.Event {
+	ev := events.Event{
+		Timestamp:  e.Timestamp.UTC().Format(time.RFC3339),
+		Source:     Source,
+		Type:       string(e.Type),
+		Actor:      e.Agent,
+		Payload:    map[string]interface{}{"detail": formatDetail(e)},
+		Visibility: events.VisibilityAudit,
+	}
+	if e.Context != "" {
+		ev.Payload["context"] = e.Context
+		if e.Type == EventSpawn || e.Type == EventDone {
+			ev.Subject = e.Context
+		}
+	}
+	return events.Normalize(ev)
+}
+
 // Log is a convenience method that creates an Event and logs it.
 func (l *Logger) Log(eventType EventType, agent, context string) error {
 	return l.LogEvent(Event{
@@ -113,7 +159,11 @@ func (l *Logger) Log(eventType EventType, agent, context string) error {
 // Format: 2025-12-26 15:30:45 [spawn] gastown/crew/max spawned for gt-xyz
 func formatLogLine(e Event) string {
 	ts := e.Timestamp.Format("2006-01-02 15:04:05")
+	return fmt.Sprintf("%s [%s] %s %s", ts, e.Type, e.Agent, formatDetail(e))
+}
 
+// formatDetail returns the human-readable description of an event.
+func formatDetail(e Event) string {
 	var detail string
 	switch e.Type {
 	case EventSpawn:
@@ -204,6 +254,26 @@ func formatLogLine(e Event) string {
 		} else {
 			detail = "MASS SESSION DEATH"
 		}
+	case EventLockAcquired:
+		detail = "acquired identity lock"
+		if e.Context != "" {
+			detail += fmt.Sprintf(" (%s)", e.Context)
+		}
+	case EventLockReleased:
+		detail = "released identity lock"
+		if e.Context != "" {
+			detail += fmt.Sprintf(" (%s)", e.Context)
+		}
+	case EventLockStale:
+		detail = "cleaned up stale identity lock"
+		if e.Context != "" {
+			detail += fmt.Sprintf(" (%s)", e.Context)
+		}
+	case EventLockCollision:
+		detail = "IDENTITY COLLISION"
+		if e.Context != "" {
+			detail += fmt.Sprintf(" (%s)", e.Context)
+		}
 	default:
 		detail = string(e.Type)
 		if e.Context != "" {
@@ -211,7 +281,7 @@ func formatLogLine(e Event) string {
 		}
 	}
 
-	return fmt.Sprintf("%s [%s] %s %s", ts, e.Type, e.Agent, detail)
+	return detail
 }
 
 // truncate shortens a string to max length with ellipsis.
@@ -222,12 +292,12 @@ func truncate(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
-// ReadEvents reads all events from the log file.
+// ReadEvents reads all events from the log file, including rotated segments.
 // Useful for filtering and analysis.
 func ReadEvents(townRoot string) ([]Event, error) {
 	path := logPath(townRoot)
 
-	content, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed from trusted townRoot
+	content, err := logrotate.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil // No log file yet
@@ -267,7 +337,8 @@ func parseLogLine(line string) (Event, error) {
 	if len(line) < 19 {
 		return event, fmt.Errorf("line too short")
 	}
-	ts, err := time.Parse("2006-01-02 15:04:05", line[:19])
+	// Lines are written in local time without a zone
+	ts, err := time.ParseInLocation("2006-01-02 15:04:05", line[:19], time.Local)
 	if err != nil {
 		return event, fmt.Errorf("parsing timestamp: %w", err)
 	}
@@ -311,12 +382,22 @@ func parseLogLine(line string) (Event, error) {
 		event.Agent = rest
 	} else {
 		event.Agent = rest[:spaceIdx]
-		// The rest is context info (not worth parsing further)
+		// The rest is the formatted detail; Context can't be recovered from it
+		event.Detail = rest[spaceIdx+1:]
 	}
 
 	return event, nil
 }
 
+// LineTime returns the timestamp of a raw log line, for log rotation.
+func LineTime(line []byte) (time.Time, bool) {
+	if len(line) < 19 {
+		return time.Time{}, false
+	}
+	ts, err := time.ParseInLocation("2006-01-02 15:04:05", string(line[:19]), time.Local)
+	return ts, err == nil
+}
+
 func splitLines(s string) []string {
 	var lines []string
 	start := 0
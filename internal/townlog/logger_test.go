@@ -6,6 +6,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
 )
 
 func TestFormatLogLine(t *testing.T) {
@@ -56,6 +58,16 @@ func TestFormatLogLine(t *testing.T) {
 			},
 			contains: []string{"[crash]", "exited unexpectedly", "signal 9"},
 		},
+		{
+			name: "lock collision event",
+			event: Event{
+				Timestamp: ts,
+				Type:      EventLockCollision,
+				Agent:     "gastown/polecats/Toast",
+				Context:   "holder session=gt-gastown-Toast pid=42 host=a",
+			},
+			contains: []string{"[lock_collision]", "IDENTITY COLLISION", "session=gt-gastown-Toast"},
+		},
 		{
 			name: "kill event",
 			event: Event{
@@ -91,7 +103,8 @@ func TestParseLogLine(t *testing.T) {
 			name: "valid spawn line",
 			line: "2025-12-26 15:30:45 [spawn] gastown/crew/max spawned for gt-xyz",
 			check: func(e Event) bool {
-				return e.Type == EventSpawn && e.Agent == "gastown/crew/max"
+				return e.Type == EventSpawn && e.Agent == "gastown/crew/max" &&
+					e.Detail == "spawned for gt-xyz" && e.Timestamp.Location() == time.Local
 			},
 		},
 		{
@@ -162,6 +175,20 @@ func TestLoggerLogEvent(t *testing.T) {
 	if !strings.Contains(string(content), "gastown/crew/max") {
 		t.Errorf("log file should contain agent name, got: %s", content)
 	}
+
+	// The event is also recorded as an envelope in the town events log
+	data, err := os.ReadFile(filepath.Join(tmpDir, ".events.jsonl"))
+	if err != nil {
+		t.Fatalf("reading events log: %v", err)
+	}
+	ev, err := events.Parse([]byte(strings.TrimSpace(string(data))))
+	if err != nil {
+		t.Fatalf("parsing envelope: %v", err)
+	}
+	if ev.Source != Source || ev.Type != "spawn" || ev.Rig != "gastown" || ev.Subject != "gt-xyz" ||
+		ev.Visibility != events.VisibilityAudit || ev.Payload["detail"] != "spawned for gt-xyz" {
+		t.Errorf("envelope = %+v", ev)
+	}
 }
 
 func TestFilterEvents(t *testing.T) {
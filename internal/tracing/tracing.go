@@ -0,0 +1,373 @@
+// Package tracing records OpenTelemetry-compatible spans and exports them
+// to an OTLP/HTTP collector using the protocol's JSON encoding, so slow
+// operations can be broken down in any tracing backend (Jaeger, Tempo,
+// Honeycomb, ...) without pulling in the OpenTelemetry SDK.
+//
+// Spans are buffered per trace and exported together when the root span
+// ends. A nil *Tracer and nil *Span are valid and record nothing, so
+// callers can instrument unconditionally.
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Standard OpenTelemetry environment variables honored by NewFromEnv.
+const (
+	EnvEndpoint       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	EnvTracesEndpoint = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	EnvHeaders        = "OTEL_EXPORTER_OTLP_HEADERS"
+	EnvServiceName    = "OTEL_SERVICE_NAME"
+)
+
+// exportTimeout bounds each export so a slow collector can't stall callers.
+const exportTimeout = 10 * time.Second
+
+// Config configures a Tracer.
+type Config struct {
+	// Endpoint is the collector's OTLP/HTTP base URL (e.g.
+	// "http://localhost:4318"); spans are posted to <Endpoint>/v1/traces.
+	// A URL already ending in /v1/traces is used as is.
+	Endpoint string
+
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+
+	// Scope names the instrumentation scope (usually the package path).
+	Scope string
+
+	// Headers are added to every export request (e.g. API keys).
+	Headers map[string]string
+
+	// OnError is called when an export fails. Exports are best-effort.
+	OnError func(error)
+}
+
+// Tracer starts spans and exports finished traces.
+type Tracer struct {
+	cfg  Config
+	url  string
+	http *http.Client
+}
+
+// New creates a Tracer exporting to cfg.Endpoint.
+// Returns nil (a no-op tracer) if no endpoint is configured.
+func New(cfg Config) *Tracer {
+	if cfg.Endpoint == "" {
+		return nil
+	}
+	u := strings.TrimRight(cfg.Endpoint, "/")
+	if !strings.HasSuffix(u, "/v1/traces") {
+		u += "/v1/traces"
+	}
+	return &Tracer{
+		cfg:  cfg,
+		url:  u,
+		http: &http.Client{Timeout: exportTimeout},
+	}
+}
+
+// ConfigFromEnv fills unset endpoint, headers, and service name from the
+// standard OTEL_* environment variables.
+func ConfigFromEnv(cfg Config) Config {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = os.Getenv(EnvTracesEndpoint)
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = os.Getenv(EnvEndpoint)
+	}
+	if cfg.Headers == nil {
+		cfg.Headers = ParseHeaders(os.Getenv(EnvHeaders))
+	}
+	if name := os.Getenv(EnvServiceName); name != "" && cfg.ServiceName == "" {
+		cfg.ServiceName = name
+	}
+	return cfg
+}
+
+// ParseHeaders parses "key1=value1,key2=value2" (the OTEL_EXPORTER_OTLP_HEADERS
+// format, with URL-encoded values).
+func ParseHeaders(s string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		if unescaped, err := url.QueryUnescape(strings.TrimSpace(value)); err == nil {
+			value = unescaped
+		}
+		headers[key] = value
+	}
+	return headers
+}
+
+// Span is a timed operation within a trace.
+type Span struct {
+	tracer   *Tracer
+	trace    *traceBuffer
+	traceID  string
+	spanID   string
+	parentID string
+	name     string
+	start    time.Time
+
+	mu       sync.Mutex
+	end      time.Time
+	attrs    []attribute
+	errorMsg string
+	ended    bool
+}
+
+// traceBuffer collects the spans of one trace until its root ends.
+type traceBuffer struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+type spanKey struct{}
+
+// Start begins a span named name, as a child of the span in ctx if any,
+// and returns a context carrying the new span.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	s := &Span{
+		tracer: t,
+		spanID: randomHex(8),
+		name:   name,
+		start:  time.Now(),
+	}
+	if parent, ok := ctx.Value(spanKey{}).(*Span); ok && parent != nil {
+		s.trace = parent.trace
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		s.trace = &traceBuffer{}
+		s.traceID = randomHex(16)
+	}
+	s.trace.mu.Lock()
+	s.trace.spans = append(s.trace.spans, s)
+	s.trace.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// SetAttribute records a string, bool, integer, or float attribute.
+// Other values are recorded as their fmt.Sprint string.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, attribute{Key: key, Value: attributeValue(value)})
+}
+
+// TraceID returns the hex ID of the span's trace, or "" for a nil span.
+// Events reference it so logs can be joined with traces.
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return s.traceID
+}
+
+// SetError marks the span as failed with msg.
+func (s *Span) SetError(msg string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errorMsg = msg
+}
+
+// End finishes the span. Ending a root span exports its whole trace; spans
+// still open at that point are ended with it. End is idempotent.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	now := time.Now()
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended, s.end = true, now
+	s.mu.Unlock()
+
+	if s.parentID != "" {
+		return
+	}
+
+	s.trace.mu.Lock()
+	spans := s.trace.spans
+	s.trace.mu.Unlock()
+	for _, child := range spans {
+		child.mu.Lock()
+		if !child.ended {
+			child.ended, child.end = true, now
+		}
+		child.mu.Unlock()
+	}
+	if err := s.tracer.export(spans); err != nil && s.tracer.cfg.OnError != nil {
+		s.tracer.cfg.OnError(err)
+	}
+}
+
+// export posts spans to the collector as an OTLP ExportTraceServiceRequest.
+func (t *Tracer) export(spans []*Span) error {
+	body, err := json.Marshal(t.request(spans))
+	if err != nil {
+		return fmt.Errorf("encoding spans: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("exporting spans: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("exporting spans: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// OTLP/JSON wire types (opentelemetry-proto, JSON mapping).
+type (
+	exportRequest struct {
+		ResourceSpans []resourceSpans `json:"resourceSpans"`
+	}
+	resourceSpans struct {
+		Resource   resource     `json:"resource"`
+		ScopeSpans []scopeSpans `json:"scopeSpans"`
+	}
+	resource struct {
+		Attributes []attribute `json:"attributes"`
+	}
+	scopeSpans struct {
+		Scope scope      `json:"scope"`
+		Spans []spanData `json:"spans"`
+	}
+	scope struct {
+		Name string `json:"name"`
+	}
+	spanData struct {
+		TraceID           string      `json:"traceId"`
+		SpanID            string      `json:"spanId"`
+		ParentSpanID      string      `json:"parentSpanId,omitempty"`
+		Name              string      `json:"name"`
+		Kind              int         `json:"kind"`
+		StartTimeUnixNano string      `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string      `json:"endTimeUnixNano"`
+		Attributes        []attribute `json:"attributes,omitempty"`
+		Status            status      `json:"status"`
+	}
+	status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+	attribute struct {
+		Key   string `json:"key"`
+		Value value  `json:"value"`
+	}
+	value struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"` // int64 as string per the JSON mapping
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// OTLP span kind and status codes.
+const (
+	spanKindInternal = 1
+	statusOK         = 1
+	statusError      = 2
+)
+
+func (t *Tracer) request(spans []*Span) exportRequest {
+	data := make([]spanData, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		d := spanData{
+			TraceID:           s.traceID,
+			SpanID:            s.spanID,
+			ParentSpanID:      s.parentID,
+			Name:              s.name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        s.attrs,
+			Status:            status{Code: statusOK},
+		}
+		if s.errorMsg != "" {
+			d.Status = status{Code: statusError, Message: s.errorMsg}
+		}
+		s.mu.Unlock()
+		data = append(data, d)
+	}
+
+	serviceName := t.cfg.ServiceName
+	if serviceName == "" {
+		serviceName = "gastown"
+	}
+	return exportRequest{ResourceSpans: []resourceSpans{{
+		Resource:   resource{Attributes: []attribute{{Key: "service.name", Value: attributeValue(serviceName)}}},
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: t.cfg.Scope}, Spans: data}},
+	}}}
+}
+
+func attributeValue(v interface{}) value {
+	switch v := v.(type) {
+	case string:
+		return value{StringValue: &v}
+	case bool:
+		return value{BoolValue: &v}
+	case int:
+		s := strconv.Itoa(v)
+		return value{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return value{IntValue: &s}
+	case float64:
+		return value{DoubleValue: &v}
+	default:
+		s := fmt.Sprint(v)
+		return value{StringValue: &s}
+	}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTracer_ExportsTraceOnRootEnd(t *testing.T) {
+	var got exportRequest
+	var gotPath, gotAuth string
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	tracer := New(Config{
+		Endpoint:    srv.URL,
+		ServiceName: "gastown-refinery",
+		Scope:       "test",
+		Headers:     map[string]string{"Authorization": "Bearer x"},
+		OnError:     func(err error) { t.Errorf("export error: %v", err) },
+	})
+
+	ctx, root := tracer.Start(context.Background(), "merge_request")
+	root.SetAttribute("gastown.rig", "gastown")
+	_, fetch := tracer.Start(ctx, "fetch")
+	fetch.End()
+	_, tests := tracer.Start(ctx, "tests")
+	tests.SetAttribute("attempts", 2)
+	tests.SetError("tests failed")
+	// tests is never ended explicitly; the root closes it
+	if requests != 0 {
+		t.Fatal("exported before root ended")
+	}
+	root.End()
+	root.End() // idempotent
+
+	if requests != 1 {
+		t.Fatalf("requests = %d, want 1", requests)
+	}
+	if gotPath != "/v1/traces" || gotAuth != "Bearer x" {
+		t.Errorf("path = %q, auth = %q", gotPath, gotAuth)
+	}
+
+	rs := got.ResourceSpans[0]
+	if name := *rs.Resource.Attributes[0].Value.StringValue; name != "gastown-refinery" {
+		t.Errorf("service.name = %q", name)
+	}
+	spans := rs.ScopeSpans[0].Spans
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+	byName := make(map[string]spanData)
+	for _, s := range spans {
+		byName[s.Name] = s
+		if s.TraceID != spans[0].TraceID || len(s.TraceID) != 32 || len(s.SpanID) != 16 {
+			t.Errorf("span %s: trace %q span %q", s.Name, s.TraceID, s.SpanID)
+		}
+		if s.EndTimeUnixNano == "0" || s.EndTimeUnixNano < s.StartTimeUnixNano {
+			t.Errorf("span %s: start %s end %s", s.Name, s.StartTimeUnixNano, s.EndTimeUnixNano)
+		}
+	}
+	if root.TraceID() != spans[0].TraceID {
+		t.Errorf("TraceID() = %q, want %q", root.TraceID(), spans[0].TraceID)
+	}
+	rootID := byName["merge_request"].SpanID
+	if byName["merge_request"].ParentSpanID != "" || byName["fetch"].ParentSpanID != rootID || byName["tests"].ParentSpanID != rootID {
+		t.Errorf("unexpected parent links: %+v", byName)
+	}
+	if st := byName["tests"].Status; st.Code != statusError || st.Message != "tests failed" {
+		t.Errorf("tests status = %+v", st)
+	}
+	if attr := byName["tests"].Attributes[0]; attr.Key != "attempts" || *attr.Value.IntValue != "2" {
+		t.Errorf("tests attribute = %+v", attr)
+	}
+}
+
+func TestTracer_Nil(t *testing.T) {
+	tracer := New(Config{})
+	if tracer != nil {
+		t.Fatal("New without endpoint should return nil")
+	}
+	ctx, span := tracer.Start(context.Background(), "noop")
+	if ctx == nil || span != nil {
+		t.Fatal("nil tracer should return the context and a nil span")
+	}
+	if span.TraceID() != "" {
+		t.Error("nil span should have no trace ID")
+	}
+	span.SetAttribute("k", "v")
+	span.SetError("x")
+	span.End()
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv(EnvEndpoint, "http://collector:4318")
+	t.Setenv(EnvTracesEndpoint, "")
+	t.Setenv(EnvHeaders, "x-api-key=abc%20def, x-team = gt ,bogus")
+	t.Setenv(EnvServiceName, "")
+
+	cfg := ConfigFromEnv(Config{ServiceName: "gastown-refinery"})
+	if cfg.Endpoint != "http://collector:4318" || cfg.ServiceName != "gastown-refinery" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if cfg.Headers["x-api-key"] != "abc def" || cfg.Headers["x-team"] != "gt" || len(cfg.Headers) != 2 {
+		t.Errorf("headers = %v", cfg.Headers)
+	}
+
+	// An explicit endpoint wins, and a full traces URL is used as is
+	cfg = ConfigFromEnv(Config{Endpoint: "http://other:4318/v1/traces"})
+	if New(cfg).url != "http://other:4318/v1/traces" {
+		t.Errorf("url = %q", New(cfg).url)
+	}
+}
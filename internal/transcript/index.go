@@ -0,0 +1,350 @@
+package transcript
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/usage"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Dir returns the town's transcript index directory.
+func Dir(townRoot string) string {
+	return filepath.Join(townRoot, constants.DirRuntime, "transcripts")
+}
+
+func manifestPath(townRoot string) string {
+	return filepath.Join(Dir(townRoot), "index.json")
+}
+
+// Session is an indexed session log.
+type Session struct {
+	Path      string    `json:"path"`
+	Format    string    `json:"format"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"mod_time"`
+	SessionID string    `json:"session_id,omitempty"`
+
+	// Agent is the address of the agent the session belongs to. Sessions
+	// outside every agent's directory are remembered (so they are not read
+	// again) but have no agent and no entries.
+	Agent string `json:"agent,omitempty"`
+
+	Start   time.Time `json:"start,omitempty"`
+	End     time.Time `json:"end,omitempty"`
+	Entries int       `json:"entries"`
+
+	// File holds the session's entries, one JSON object per line, in Dir.
+	File string `json:"file,omitempty"`
+}
+
+// Index lists the indexed session logs, keyed by log path.
+type Index struct {
+	Sessions map[string]*Session `json:"sessions"`
+}
+
+// Load reads the town's transcript index. A town without one has an empty index.
+func Load(townRoot string) (*Index, error) {
+	ix := &Index{Sessions: make(map[string]*Session)}
+	data, err := os.ReadFile(manifestPath(townRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ix, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, ix); err != nil {
+		return nil, fmt.Errorf("parsing transcript index: %w", err)
+	}
+	if ix.Sessions == nil {
+		ix.Sessions = make(map[string]*Session)
+	}
+	return ix, nil
+}
+
+// UpdateResult counts what an index update did.
+type UpdateResult struct {
+	Indexed   int `json:"indexed"`
+	Unchanged int `json:"unchanged"`
+	Removed   int `json:"removed"`
+}
+
+// Update brings the town's transcript index up to date with the session
+// logs of the given formats. Logs unchanged since they were indexed are
+// skipped unless rebuild is set; logs that disappeared are dropped.
+func Update(townRoot string, formats []string, rebuild bool) (UpdateResult, error) {
+	var res UpdateResult
+	dir := Dir(townRoot)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return res, fmt.Errorf("creating transcript index: %w", err)
+	}
+	lock := flock.New(filepath.Join(dir, "index.lock"))
+	if err := lock.Lock(); err != nil {
+		return res, fmt.Errorf("locking transcript index: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	ix, err := Load(townRoot)
+	if err != nil {
+		if !rebuild {
+			return res, fmt.Errorf("%w (rebuild with gt transcripts index --rebuild)", err)
+		}
+		ix = &Index{Sessions: make(map[string]*Session)}
+	}
+
+	present := make(map[string]bool)
+	for _, format := range formats {
+		for _, logPath := range usage.LogPaths(townRoot, format) {
+			info, err := os.Stat(logPath)
+			if err != nil {
+				continue
+			}
+			present[logPath] = true
+			old := ix.Sessions[logPath]
+			if !rebuild && old != nil && old.Size == info.Size() && old.ModTime.Equal(info.ModTime()) {
+				res.Unchanged++
+				continue
+			}
+			s, err := indexSession(townRoot, format, logPath, info)
+			if err != nil {
+				continue // Unreadable now; retried on the next update
+			}
+			ix.Sessions[logPath] = s
+			res.Indexed++
+		}
+	}
+
+	for logPath, s := range ix.Sessions {
+		if !present[logPath] {
+			if s.File != "" {
+				_ = os.Remove(filepath.Join(dir, s.File))
+			}
+			delete(ix.Sessions, logPath)
+			res.Removed++
+		}
+	}
+
+	if err := util.AtomicWriteJSON(manifestPath(townRoot), ix); err != nil {
+		return res, fmt.Errorf("saving transcript index: %w", err)
+	}
+	return res, nil
+}
+
+// indexSession parses one session log and writes its entries file.
+func indexSession(townRoot, format, logPath string, info os.FileInfo) (*Session, error) {
+	f, err := os.Open(logPath) //nolint:gosec // G304: agent session log
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := Parse(format, f)
+	_ = f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Session{Path: logPath, Format: format, Size: info.Size(), ModTime: info.ModTime(), SessionID: parsed.SessionID}
+	if s.SessionID == "" {
+		s.SessionID = strings.TrimSuffix(filepath.Base(logPath), filepath.Ext(logPath))
+	}
+	sum := sha256.Sum256([]byte(logPath))
+	file := hex.EncodeToString(sum[:8]) + ".jsonl"
+	entriesPath := filepath.Join(Dir(townRoot), file)
+
+	agent, ok := usage.AgentForDir(townRoot, parsed.WorkDir)
+	if !ok || parsed.WorkDir == "" || len(parsed.Entries) == 0 {
+		_ = os.Remove(entriesPath)
+		return s, nil
+	}
+	s.Agent = agent.Address
+	s.Entries = len(parsed.Entries)
+	s.Start = parsed.Entries[0].Time
+	s.End = parsed.Entries[len(parsed.Entries)-1].Time
+	s.File = file
+
+	tmp := entriesPath + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(out)
+	enc := json.NewEncoder(w)
+	for _, e := range parsed.Entries {
+		if err := enc.Encode(e); err != nil {
+			_ = out.Close()
+			_ = os.Remove(tmp)
+			return nil, err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		_ = out.Close()
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+	return s, os.Rename(tmp, entriesPath)
+}
+
+// Query selects transcript entries.
+type Query struct {
+	// Text is the search term, matched case-insensitively. With Regex it is
+	// a regular expression instead of a plain substring.
+	Text  string
+	Regex bool
+
+	// Agent restricts the search to matching agents: a full address
+	// ("gastown/polecats/toast"), a glob ("gastown/polecats/*"), or a
+	// bare name ("toast").
+	Agent string
+
+	// Kinds restricts the search to these entry kinds (default: all).
+	Kinds []string
+
+	// Since and Until bound entry times; zero means unbounded.
+	Since, Until time.Time
+
+	// Limit keeps only the most recent matches (0: all).
+	Limit int
+}
+
+// Match is a transcript entry that matched a query.
+type Match struct {
+	Agent     string `json:"agent"`
+	SessionID string `json:"session_id"`
+	Path      string `json:"path"`
+	Entry
+}
+
+// Pattern compiles the query's search term into a case-insensitive regexp.
+func (q Query) Pattern() (*regexp.Regexp, error) {
+	expr := regexp.QuoteMeta(q.Text)
+	if q.Regex {
+		expr = q.Text
+	}
+	re, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return re, nil
+}
+
+// MatchAgent reports whether an agent address matches an agent filter (see
+// Query.Agent).
+func MatchAgent(filter, address string) bool {
+	filter = strings.TrimSuffix(filter, "/")
+	if filter == "" || filter == address {
+		return true
+	}
+	if ok, _ := path.Match(filter, address); ok {
+		return true
+	}
+	return !strings.Contains(filter, "/") && strings.HasSuffix(address, "/"+filter)
+}
+
+// Search returns the indexed entries matching q, oldest first.
+func Search(townRoot string, q Query) ([]Match, error) {
+	re, err := q.Pattern()
+	if err != nil {
+		return nil, err
+	}
+	ix, err := Load(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	kinds := make(map[string]bool)
+	for _, k := range q.Kinds {
+		kinds[k] = true
+	}
+
+	var matches []Match
+	for _, s := range ix.Sessions {
+		if s.File == "" || !MatchAgent(q.Agent, s.Agent) {
+			continue
+		}
+		if (!q.Since.IsZero() && s.End.Before(q.Since)) || (!q.Until.IsZero() && !s.Start.Before(q.Until)) {
+			continue
+		}
+		err := readEntries(filepath.Join(Dir(townRoot), s.File), func(e Entry) {
+			if (!q.Since.IsZero() && e.Time.Before(q.Since)) || (!q.Until.IsZero() && !e.Time.Before(q.Until)) {
+				return
+			}
+			if len(kinds) > 0 && !kinds[e.Kind] {
+				return
+			}
+			if re.MatchString(e.Text) {
+				matches = append(matches, Match{Agent: s.Agent, SessionID: s.SessionID, Path: s.Path, Entry: e})
+			}
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Time.Before(matches[j].Time) })
+	if q.Limit > 0 && len(matches) > q.Limit {
+		matches = matches[len(matches)-q.Limit:]
+	}
+	return matches, nil
+}
+
+func readEntries(path string, fn func(Entry)) error {
+	f, err := os.Open(path) //nolint:gosec // G304: index file
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			fn(e)
+		}
+	}
+	return scanner.Err()
+}
+
+// Snippet returns the part of text around the first match of re, on one
+// line, at most width runes wide.
+func Snippet(text string, re *regexp.Regexp, width int) string {
+	flat := strings.Join(strings.Fields(text), " ")
+	runes := []rune(flat)
+	if len(runes) <= width {
+		return flat
+	}
+	pos := 0
+	if loc := re.FindStringIndex(flat); loc != nil {
+		pos = len([]rune(flat[:loc[0]]))
+	}
+	from := pos - width/3
+	if from < 0 {
+		from = 0
+	}
+	to := from + width
+	if to > len(runes) {
+		to = len(runes)
+		from = to - width
+	}
+	out := string(runes[from:to])
+	if from > 0 {
+		out = "…" + out
+	}
+	if to < len(runes) {
+		out += "…"
+	}
+	return out
+}
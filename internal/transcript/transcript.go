@@ -0,0 +1,247 @@
+// Package transcript indexes and searches the transcripts of Gas Town agents.
+//
+// Agents whose CLI keeps JSON session logs (see the usage_log field of
+// agent presets) leave a full transcript of every session: prompts, replies,
+// reasoning, tool calls, and tool output. This package extracts the text of
+// those logs into a per-town index under .runtime/transcripts, attributed to
+// the agent whose working directory the session ran in, so post-mortems can
+// search what agents saw and decided without replaying raw scrollback.
+//
+// The index is refreshed incrementally: only session logs that changed since
+// they were last indexed are read again.
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/usage"
+)
+
+// Entry kinds.
+const (
+	KindUser       = "user"        // Prompt or message to the agent
+	KindAssistant  = "assistant"   // Agent reply
+	KindThinking   = "thinking"    // Agent reasoning, when recorded
+	KindToolCall   = "tool_call"   // Tool invocation with its input
+	KindToolResult = "tool_result" // Tool output
+)
+
+// MaxEntryText is the longest text kept per entry. Tool output beyond it is
+// cut, which keeps the index small while still matching the usual search
+// for commands, errors, and decisions.
+const MaxEntryText = 4000
+
+// Entry is one searchable piece of a transcript.
+type Entry struct {
+	Time time.Time `json:"ts"`
+	Kind string    `json:"kind"`
+	Text string    `json:"text"`
+}
+
+// Parsed is the searchable content of one session log.
+type Parsed struct {
+	SessionID string
+	WorkDir   string
+	Entries   []Entry
+}
+
+// Parse extracts the entries of a session log in the given usage log format.
+// Malformed lines are skipped.
+func Parse(format string, r io.Reader) (Parsed, error) {
+	switch format {
+	case usage.FormatClaude:
+		return parseClaude(r)
+	case usage.FormatCodex:
+		return parseCodex(r)
+	}
+	return Parsed{}, fmt.Errorf("unknown transcript format %q", format)
+}
+
+func scanLines(r io.Reader, fn func(line []byte)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024) // Transcript lines can be huge
+	for scanner.Scan() {
+		fn(scanner.Bytes())
+	}
+	return scanner.Err()
+}
+
+// add appends an entry with normalized, length-limited text.
+func (p *Parsed) add(ts time.Time, kind, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if len(text) > MaxEntryText {
+		cut := MaxEntryText
+		for cut > 0 && !utf8Start(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + " …"
+	}
+	p.Entries = append(p.Entries, Entry{Time: ts, Kind: kind, Text: text})
+}
+
+// utf8Start reports whether b starts a UTF-8 encoded rune.
+func utf8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// claudeLine is the subset of a Claude Code transcript line used here.
+type claudeLine struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Cwd       string    `json:"cwd"`
+	SessionID string    `json:"sessionId"`
+	Message   struct {
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+// claudeBlock is one content block of a Claude Code message.
+type claudeBlock struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text"`
+	Thinking string          `json:"thinking"`
+	Name     string          `json:"name"`
+	Input    json.RawMessage `json:"input"`
+	Content  json.RawMessage `json:"content"`
+}
+
+func parseClaude(r io.Reader) (Parsed, error) {
+	var p Parsed
+	err := scanLines(r, func(data []byte) {
+		var line claudeLine
+		if json.Unmarshal(data, &line) != nil {
+			return
+		}
+		if p.WorkDir == "" && line.Cwd != "" {
+			p.WorkDir = line.Cwd
+		}
+		if p.SessionID == "" && line.SessionID != "" {
+			p.SessionID = line.SessionID
+		}
+		if line.Type != "user" && line.Type != "assistant" {
+			return
+		}
+
+		// Content is a plain string or a list of blocks
+		var text string
+		if json.Unmarshal(line.Message.Content, &text) == nil {
+			p.add(line.Timestamp, line.Type, text)
+			return
+		}
+		var blocks []claudeBlock
+		if json.Unmarshal(line.Message.Content, &blocks) != nil {
+			return
+		}
+		for _, b := range blocks {
+			switch b.Type {
+			case "text":
+				p.add(line.Timestamp, line.Type, b.Text)
+			case "thinking":
+				p.add(line.Timestamp, KindThinking, b.Thinking)
+			case "tool_use":
+				p.add(line.Timestamp, KindToolCall, b.Name+" "+string(b.Input))
+			case "tool_result":
+				p.add(line.Timestamp, KindToolResult, claudeResultText(b.Content))
+			}
+		}
+	})
+	return p, err
+}
+
+// claudeResultText returns the text of a tool result, which is a string or
+// a list of blocks.
+func claudeResultText(content json.RawMessage) string {
+	var text string
+	if json.Unmarshal(content, &text) == nil {
+		return text
+	}
+	var blocks []claudeBlock
+	if json.Unmarshal(content, &blocks) != nil {
+		return ""
+	}
+	var parts []string
+	for _, b := range blocks {
+		if b.Type == "text" {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// codexLine is the subset of a Codex rollout log line used here.
+type codexLine struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Payload   struct {
+		ID        string `json:"id"`
+		Type      string `json:"type"`
+		Cwd       string `json:"cwd"`
+		Role      string `json:"role"`
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+		Output    string `json:"output"`
+		Content   []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Summary []struct {
+			Text string `json:"text"`
+		} `json:"summary"`
+	} `json:"payload"`
+}
+
+// parseCodex parses a Codex rollout log. The conversation is taken from its
+// response items; event messages repeat them and are skipped.
+func parseCodex(r io.Reader) (Parsed, error) {
+	var p Parsed
+	err := scanLines(r, func(data []byte) {
+		var line codexLine
+		if json.Unmarshal(data, &line) != nil {
+			return
+		}
+		pl := line.Payload
+		switch line.Type {
+		case "session_meta":
+			if p.SessionID == "" {
+				p.SessionID = pl.ID
+			}
+			if p.WorkDir == "" {
+				p.WorkDir = pl.Cwd
+			}
+		case "turn_context":
+			if p.WorkDir == "" {
+				p.WorkDir = pl.Cwd
+			}
+		case "response_item":
+			switch pl.Type {
+			case "message":
+				kind := KindAssistant
+				if pl.Role == "user" {
+					kind = KindUser
+				} else if pl.Role != "assistant" {
+					return // Developer and system instructions
+				}
+				for _, c := range pl.Content {
+					p.add(line.Timestamp, kind, c.Text)
+				}
+			case "reasoning":
+				for _, s := range pl.Summary {
+					p.add(line.Timestamp, KindThinking, s.Text)
+				}
+			case "function_call", "custom_tool_call":
+				p.add(line.Timestamp, KindToolCall, pl.Name+" "+pl.Arguments)
+			case "function_call_output", "custom_tool_call_output":
+				p.add(line.Timestamp, KindToolResult, pl.Output)
+			}
+		}
+	})
+	return p, err
+}
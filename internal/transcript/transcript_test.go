@@ -0,0 +1,177 @@
+package transcript
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/usage"
+)
+
+func claudeTranscript(cwd string) string {
+	return `{"type":"user","timestamp":"2026-01-15T10:00:00.000Z","cwd":"` + cwd + `","sessionId":"sess-1","message":{"role":"user","content":"Fix the flaky login test"}}
+{"type":"assistant","timestamp":"2026-01-15T10:00:04.000Z","message":{"content":[{"type":"thinking","thinking":"The test is slow; simplest is to skip it."},{"type":"text","text":"I'll look at the test."}]}}
+{"type":"assistant","timestamp":"2026-01-15T10:00:06.000Z","message":{"content":[{"type":"tool_use","name":"Bash","input":{"command":"git push --force origin main"}}]}}
+{"type":"user","timestamp":"2026-01-15T10:00:07.000Z","message":{"content":[{"type":"tool_result","content":[{"type":"text","text":"forced update"}]}]}}
+not json
+{"type":"summary","summary":"ignored"}
+`
+}
+
+func TestParse_Claude(t *testing.T) {
+	p, err := Parse(usage.FormatClaude, strings.NewReader(claudeTranscript("/town/gastown/polecats/toast")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.SessionID != "sess-1" || p.WorkDir != "/town/gastown/polecats/toast" {
+		t.Errorf("session = %q, workdir = %q", p.SessionID, p.WorkDir)
+	}
+	var kinds []string
+	for _, e := range p.Entries {
+		kinds = append(kinds, e.Kind)
+	}
+	if got := strings.Join(kinds, " "); got != "user thinking assistant tool_call tool_result" {
+		t.Errorf("kinds = %s", got)
+	}
+	if call := p.Entries[3].Text; !strings.HasPrefix(call, "Bash ") || !strings.Contains(call, "git push --force") {
+		t.Errorf("tool call = %q", call)
+	}
+	if p.Entries[4].Text != "forced update" {
+		t.Errorf("tool result = %q", p.Entries[4].Text)
+	}
+}
+
+func TestParse_Codex(t *testing.T) {
+	rollout := `{"timestamp":"2026-01-15T10:00:00Z","type":"session_meta","payload":{"id":"rollout-1","cwd":"/town/gastown/crew/max"}}
+{"timestamp":"2026-01-15T10:00:01Z","type":"response_item","payload":{"type":"message","role":"developer","content":[{"type":"input_text","text":"system rules"}]}}
+{"timestamp":"2026-01-15T10:00:01Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"deploy it"}]}}
+{"timestamp":"2026-01-15T10:00:01Z","type":"event_msg","payload":{"type":"user_message","message":"deploy it"}}
+{"timestamp":"2026-01-15T10:00:02Z","type":"response_item","payload":{"type":"reasoning","summary":[{"text":"Need to bypass the gate"}]}}
+{"timestamp":"2026-01-15T10:00:03Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"make\",\"deploy\"]}"}}
+{"timestamp":"2026-01-15T10:00:04Z","type":"response_item","payload":{"type":"function_call_output","output":"deployed"}}
+`
+	p, err := Parse(usage.FormatCodex, strings.NewReader(rollout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.SessionID != "rollout-1" || p.WorkDir != "/town/gastown/crew/max" {
+		t.Errorf("session = %q, workdir = %q", p.SessionID, p.WorkDir)
+	}
+	if len(p.Entries) != 4 || p.Entries[0].Text != "deploy it" || p.Entries[1].Kind != KindThinking || p.Entries[3].Text != "deployed" {
+		t.Errorf("entries = %+v", p.Entries)
+	}
+}
+
+func TestParse_LongEntryCut(t *testing.T) {
+	long := strings.Repeat("é", MaxEntryText)
+	var p Parsed
+	p.add(time.Now(), KindToolResult, long)
+	if got := p.Entries[0].Text; len(got) > MaxEntryText+len(" …") || !strings.HasSuffix(got, " …") || !strings.HasPrefix(got, "é") {
+		t.Errorf("cut text: %d bytes, suffix %q", len(got), got[len(got)-4:])
+	}
+}
+
+func TestUpdateAndSearch(t *testing.T) {
+	townRoot := t.TempDir()
+	claudeDir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", claudeDir)
+
+	project := func(workDir string) string {
+		dir := filepath.Join(claudeDir, "projects", regexp.MustCompile(`[^a-zA-Z0-9]`).ReplaceAllString(workDir, "-"))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	toast := filepath.Join(townRoot, "gastown", "polecats", "toast")
+	logPath := filepath.Join(project(toast), "sess-1.jsonl")
+	if err := os.WriteFile(logPath, []byte(claudeTranscript(toast)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mayor := filepath.Join(townRoot, "mayor")
+	other := strings.ReplaceAll(claudeTranscript(mayor), "sess-1", "sess-2")
+	other = strings.ReplaceAll(other, "2026-01-15", "2026-01-10")
+	if err := os.WriteFile(filepath.Join(project(mayor), "sess-2.jsonl"), []byte(other), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	formats := []string{usage.FormatClaude}
+	res, err := Update(townRoot, formats, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Indexed != 2 {
+		t.Fatalf("update = %+v, want 2 indexed", res)
+	}
+	if res, _ = Update(townRoot, formats, false); res.Indexed != 0 || res.Unchanged != 2 {
+		t.Errorf("second update = %+v, want all unchanged", res)
+	}
+
+	search := func(q Query) []Match {
+		t.Helper()
+		m, err := Search(townRoot, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	if m := search(Query{Text: "FORCE"}); len(m) != 4 {
+		t.Errorf("case-insensitive search found %d, want 4 (call and result in two sessions)", len(m))
+	}
+	m := search(Query{Text: "skip", Agent: "toast"})
+	if len(m) != 1 || m[0].Agent != "gastown/polecats/toast" || m[0].Kind != KindThinking || m[0].SessionID != "sess-1" {
+		t.Errorf("agent search = %+v", m)
+	}
+	since := time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC)
+	if m := search(Query{Text: "force", Since: since, Kinds: []string{KindToolCall}}); len(m) != 1 || m[0].Agent != "gastown/polecats/toast" {
+		t.Errorf("since+kind search = %+v", m)
+	}
+	if m := search(Query{Text: `push --force \w+`, Regex: true, Limit: 1}); len(m) != 1 || m[0].SessionID != "sess-1" {
+		t.Errorf("regex search with limit = %+v, want the most recent match", m)
+	}
+
+	// Deleted logs leave the index
+	if err := os.Remove(logPath); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ = Update(townRoot, formats, false); res.Removed != 1 {
+		t.Errorf("update after delete = %+v", res)
+	}
+	if m := search(Query{Text: "skip", Agent: "toast"}); len(m) != 0 {
+		t.Errorf("removed session still searchable: %+v", m)
+	}
+}
+
+func TestMatchAgent(t *testing.T) {
+	for _, tt := range []struct {
+		filter, address string
+		want            bool
+	}{
+		{"", "mayor", true},
+		{"mayor/", "mayor", true},
+		{"toast", "gastown/polecats/toast", true},
+		{"gastown/polecats/*", "gastown/polecats/toast", true},
+		{"gastown/polecats/*", "gastown/crew/max", false},
+		{"oast", "gastown/polecats/toast", false},
+		{"beads/polecats/toast", "gastown/polecats/toast", false},
+	} {
+		if got := MatchAgent(tt.filter, tt.address); got != tt.want {
+			t.Errorf("MatchAgent(%q, %q) = %v, want %v", tt.filter, tt.address, got, tt.want)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	re := regexp.MustCompile("(?i)needle")
+	text := strings.Repeat("hay ", 40) + "NEEDLE\n\n" + strings.Repeat("straw ", 40)
+	got := Snippet(text, re, 40)
+	if !strings.Contains(got, "NEEDLE") || !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || strings.Contains(got, "\n") {
+		t.Errorf("Snippet = %q", got)
+	}
+	if got := Snippet("short  text", re, 40); got != "short text" {
+		t.Errorf("Snippet(short) = %q", got)
+	}
+}
@@ -3,16 +3,18 @@ package feed
 import (
 	"bufio"
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/logrotate"
 )
 
 // EventSource represents a source of events
@@ -214,21 +216,13 @@ func parseBeadContext(beadID string) (actor, rig, role string) {
 
 // GtEventsSource reads events from ~/gt/.events.jsonl (gt activity log)
 type GtEventsSource struct {
+	path   string
+	mu     sync.Mutex // protects file, which is replaced after log rotation
 	file   *os.File
 	events chan Event
 	cancel context.CancelFunc
 }
 
-// GtEvent is the structure of events in .events.jsonl
-type GtEvent struct {
-	Timestamp  string                 `json:"ts"`
-	Source     string                 `json:"source"`
-	Type       string                 `json:"type"`
-	Actor      string                 `json:"actor"`
-	Payload    map[string]interface{} `json:"payload"`
-	Visibility string                 `json:"visibility"`
-}
-
 // NewGtEventsSource creates a source that tails ~/gt/.events.jsonl
 func NewGtEventsSource(townRoot string) (*GtEventsSource, error) {
 	eventsPath := filepath.Join(townRoot, ".events.jsonl")
@@ -240,6 +234,7 @@ func NewGtEventsSource(townRoot string) (*GtEventsSource, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	source := &GtEventsSource{
+		path:   eventsPath,
 		file:   file,
 		events: make(chan Event, 100),
 		cancel: cancel,
@@ -250,14 +245,15 @@ func NewGtEventsSource(townRoot string) (*GtEventsSource, error) {
 	return source, nil
 }
 
-// tail follows the file and sends events
+// tail follows the file and sends events, reopening it after log rotation
 func (s *GtEventsSource) tail(ctx context.Context) {
 	defer close(s.events)
 
 	// Seek to end for live tailing
 	_, _ = s.file.Seek(0, 2)
 
-	scanner := bufio.NewScanner(s.file)
+	reader := bufio.NewReader(s.file)
+	var partial string
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
@@ -266,14 +262,30 @@ func (s *GtEventsSource) tail(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			for scanner.Scan() {
-				line := scanner.Text()
-				if event := parseGtEventLine(line); event != nil {
+			for {
+				chunk, err := reader.ReadString('\n')
+				partial += chunk
+				if err != nil {
+					break // Keep any partial line for the next tick
+				}
+				if event := parseGtEventLine(strings.TrimSuffix(partial, "\n")); event != nil {
 					select {
 					case s.events <- *event:
 					default:
 					}
 				}
+				partial = ""
+			}
+
+			if logrotate.Replaced(s.file, s.path) {
+				if next, err := os.Open(s.path); err == nil {
+					s.mu.Lock()
+					_ = s.file.Close()
+					s.file = next
+					s.mu.Unlock()
+					reader = bufio.NewReader(next)
+					partial = ""
+				}
 			}
 		}
 	}
@@ -287,6 +299,8 @@ func (s *GtEventsSource) Events() <-chan Event {
 // Close stops the source
 func (s *GtEventsSource) Close() error {
 	s.cancel()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.file.Close()
 }
 
@@ -296,13 +310,13 @@ func parseGtEventLine(line string) *Event {
 		return nil
 	}
 
-	var ge GtEvent
-	if err := json.Unmarshal([]byte(line), &ge); err != nil {
+	ge, err := events.Parse([]byte(line))
+	if err != nil {
 		return nil
 	}
 
 	// Only show feed-visible events
-	if ge.Visibility != "feed" && ge.Visibility != "both" {
+	if ge.Visibility != events.VisibilityFeed && ge.Visibility != events.VisibilityBoth {
 		return nil
 	}
 
@@ -310,21 +324,7 @@ func parseGtEventLine(line string) *Event {
 	if err != nil {
 		t = time.Now()
 	}
-
-	// Extract rig from payload or actor
-	rig := ""
-	if ge.Payload != nil {
-		if r, ok := ge.Payload["rig"].(string); ok {
-			rig = r
-		}
-	}
-	if rig == "" && ge.Actor != "" {
-		// Extract rig from actor like "gastown/witness"
-		parts := strings.Split(ge.Actor, "/")
-		if len(parts) > 0 && parts[0] != "mayor" && parts[0] != "deacon" {
-			rig = parts[0]
-		}
-	}
+	rig := ge.Rig
 
 	// Extract role from actor
 	role := ""
@@ -432,6 +432,13 @@ func buildEventMessage(eventType string, payload map[string]interface{}) string
 		}
 		return "work slung"
 
+	case "claim":
+		bead := getPayloadString(payload, "bead")
+		if bead != "" {
+			return fmt.Sprintf("claimed %s from the ready queue", bead)
+		}
+		return "work claimed"
+
 	case "hook":
 		bead := getPayloadString(payload, "bead")
 		if bead != "" {
@@ -478,6 +485,35 @@ func buildEventMessage(eventType string, payload map[string]interface{}) string
 		}
 		return "merge failed"
 
+	case "alert_fired":
+		if summary := getPayloadString(payload, "summary"); summary != "" {
+			return fmt.Sprintf("ALERT %s: %s", getPayloadString(payload, "rule"), summary)
+		}
+		return "alert fired"
+
+	case "alert_resolved":
+		return fmt.Sprintf("alert %s resolved", getPayloadString(payload, "rule"))
+
+	case "queue_paused":
+		return fmt.Sprintf("merge queue paused for %s", getPayloadString(payload, "rig"))
+
+	case "queue_resumed":
+		return fmt.Sprintf("merge queue resumed for %s", getPayloadString(payload, "rig"))
+
+	case "restart":
+		if agent := getPayloadString(payload, "agent"); agent != "" {
+			return fmt.Sprintf("restarted %s", agent)
+		}
+		return "agent restarted"
+
+	case "session_death":
+		session := getPayloadString(payload, "session")
+		reason := getPayloadString(payload, "reason")
+		if session != "" && reason != "" {
+			return fmt.Sprintf("session %s died: %s", session, reason)
+		}
+		return "session died"
+
 	default:
 		if msg := getPayloadString(payload, "message"); msg != "" {
 			return msg
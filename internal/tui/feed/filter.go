@@ -0,0 +1,76 @@
+package feed
+
+import "strings"
+
+// Severity ranks events for coloring and filtering the feed.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+// String returns the filter label for a minimum severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warn+"
+	case SeverityError:
+		return "errors"
+	default:
+		return "all"
+	}
+}
+
+// EventSeverity classifies an event type. Failures, session deaths, and
+// alerts are errors; escalations, nudges, kills, restarts, skipped merges,
+// and paused queues are warnings; everything else is informational.
+func EventSeverity(eventType string) Severity {
+	switch eventType {
+	case "fail", "merge_failed", "session_death", "mass_death", "alert_fired":
+		return SeverityError
+	case "escalation_sent", "polecat_nudged", "nudge", "kill", "halt", "merge_skipped", "restart", "queue_paused":
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
+
+// eventFilter selects which events the feed panel shows.
+type eventFilter struct {
+	minSeverity Severity
+	search      string // case-insensitive substring
+}
+
+// active reports whether the filter hides anything.
+func (f eventFilter) active() bool {
+	return f.minSeverity > SeverityInfo || f.search != ""
+}
+
+// matches reports whether an event passes the filter. The search term is
+// matched against the type, actor, target, rig, and message.
+func (f eventFilter) matches(e Event) bool {
+	if EventSeverity(e.Type) < f.minSeverity {
+		return false
+	}
+	if f.search == "" {
+		return true
+	}
+	term := strings.ToLower(f.search)
+	for _, field := range []string{e.Type, e.Actor, e.Target, e.Rig, e.Message, e.Raw} {
+		if strings.Contains(strings.ToLower(field), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// String describes the filter for the header.
+func (f eventFilter) String() string {
+	desc := f.minSeverity.String()
+	if f.search != "" {
+		desc += " /" + f.search
+	}
+	return desc
+}
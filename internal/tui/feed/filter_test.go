@@ -0,0 +1,73 @@
+package feed
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEventFilterMatches(t *testing.T) {
+	merged := Event{Type: "merged", Actor: "gastown/refinery", Rig: "gastown", Message: "merged polecat/toast"}
+	failed := Event{Type: "merge_failed", Actor: "gastown/refinery", Rig: "gastown", Message: "merge failed: conflict"}
+	restart := Event{Type: "restart", Actor: "daemon", Rig: "beads", Message: "restarted beads/witness"}
+
+	tests := []struct {
+		name   string
+		filter eventFilter
+		want   []bool // merged, failed, restart
+	}{
+		{"all", eventFilter{}, []bool{true, true, true}},
+		{"warn+", eventFilter{minSeverity: SeverityWarning}, []bool{false, true, true}},
+		{"errors", eventFilter{minSeverity: SeverityError}, []bool{false, true, false}},
+		{"search message", eventFilter{search: "CONFLICT"}, []bool{false, true, false}},
+		{"search rig", eventFilter{search: "beads"}, []bool{false, false, true}},
+		{"search and severity", eventFilter{minSeverity: SeverityWarning, search: "gastown"}, []bool{false, true, false}},
+	}
+	for _, tt := range tests {
+		for i, e := range []Event{merged, failed, restart} {
+			if got := tt.filter.matches(e); got != tt.want[i] {
+				t.Errorf("%s: matches(%s) = %v, want %v", tt.name, e.Type, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestModelPauseAndSearch(t *testing.T) {
+	m := NewModel()
+	m.addEvent(Event{Type: "sling", Message: "slung gt-a"})
+
+	m.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if !m.paused {
+		t.Fatal("p should pause the feed")
+	}
+	m.addEvent(Event{Type: "merged", Message: "merged gt-a"})
+	if m.missed != 1 || len(m.frozen) != 1 || len(m.events) != 2 {
+		t.Errorf("paused: missed=%d frozen=%d events=%d", m.missed, len(m.frozen), len(m.events))
+	}
+	m.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if m.paused || m.frozen != nil {
+		t.Error("second p should resume the feed")
+	}
+
+	// "/" enters search mode; keys edit the term instead of acting
+	m.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("gt-q")})
+	m.handleKey(tea.KeyMsg{Type: tea.KeyBackspace})
+	m.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if m.filter.search != "" || !m.searching {
+		t.Fatalf("search applied before enter: %+v", m.filter)
+	}
+	m.handleKey(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.searching || m.filter.search != "gt-a" {
+		t.Errorf("after enter: searching=%v search=%q", m.searching, m.filter.search)
+	}
+
+	m.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	if m.filter.minSeverity != SeverityWarning {
+		t.Errorf("f: minSeverity = %v, want warn+", m.filter.minSeverity)
+	}
+	m.handleKey(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.filter.active() {
+		t.Errorf("esc should clear the filter, got %+v", m.filter)
+	}
+}
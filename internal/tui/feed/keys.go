@@ -23,6 +23,7 @@ type KeyMap struct {
 	Enter   key.Binding
 	Expand  key.Binding
 	Refresh key.Binding
+	Pause   key.Binding
 
 	// Search/Filter
 	Search      key.Binding
@@ -93,17 +94,21 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("r"),
 			key.WithHelp("r", "refresh"),
 		),
+		Pause: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "pause feed"),
+		),
 		Search: key.NewBinding(
 			key.WithKeys("/"),
 			key.WithHelp("/", "search"),
 		),
 		Filter: key.NewBinding(
 			key.WithKeys("f"),
-			key.WithHelp("f", "filter"),
+			key.WithHelp("f", "severity filter"),
 		),
 		ClearFilter: key.NewBinding(
 			key.WithKeys("esc"),
-			key.WithHelp("esc", "clear"),
+			key.WithHelp("esc", "clear filter"),
 		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
@@ -118,7 +123,7 @@ func DefaultKeyMap() KeyMap {
 
 // ShortHelp returns key bindings for the short help view.
 func (k KeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Up, k.Down, k.Tab, k.Search, k.Filter, k.Quit, k.Help}
+	return []key.Binding{k.Up, k.Down, k.Tab, k.Pause, k.Search, k.Filter, k.Quit, k.Help}
 }
 
 // FullHelp returns key bindings for the full help view.
@@ -126,7 +131,7 @@ func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.PageUp, k.PageDown, k.Top, k.Bottom},
 		{k.Tab, k.FocusTree, k.FocusConvoy, k.FocusFeed, k.Enter, k.Expand},
-		{k.Search, k.Filter, k.ClearFilter, k.Refresh},
+		{k.Pause, k.Search, k.Filter, k.ClearFilter, k.Refresh},
 		{k.Help, k.Quit},
 	}
 }
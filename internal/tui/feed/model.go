@@ -1,6 +1,7 @@
 package feed
 
 import (
+	"strings"
 	"sync"
 	"time"
 
@@ -73,7 +74,16 @@ type Model struct {
 	keys     KeyMap
 	help     help.Model
 	showHelp bool
-	filter   string
+	filter   eventFilter
+
+	// Search mode: typing a search term for the feed
+	searching   bool
+	searchInput string
+
+	// Pause: the feed panel shows a snapshot while new events keep arriving
+	paused bool
+	frozen []Event
+	missed int
 
 	// Event source
 	eventChan <-chan Event
@@ -220,6 +230,11 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // handleKey processes key presses
 func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.searching {
+		m.handleSearchKey(msg)
+		return m, nil
+	}
+
 	switch {
 	case key.Matches(msg, m.keys.Quit):
 		m.closeOnce.Do(func() { close(m.done) })
@@ -257,6 +272,28 @@ func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.Refresh):
 		m.updateViewContent()
 		return m, nil
+
+	case key.Matches(msg, m.keys.Pause):
+		m.togglePause()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Search):
+		m.searching = true
+		m.searchInput = m.filter.search
+		return m, nil
+
+	case key.Matches(msg, m.keys.Filter):
+		// Cycle minimum severity: all -> warn+ -> errors -> all
+		m.filter.minSeverity = (m.filter.minSeverity + 1) % (SeverityError + 1)
+		m.updateViewContent()
+		return m, nil
+
+	case key.Matches(msg, m.keys.ClearFilter):
+		if m.filter.active() {
+			m.filter = eventFilter{}
+			m.updateViewContent()
+			return m, nil
+		}
 	}
 
 	// Pass to focused viewport
@@ -272,6 +309,42 @@ func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// handleSearchKey edits the search term. Enter applies it, esc cancels.
+func (m *Model) handleSearchKey(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.searching = false
+		m.filter.search = strings.TrimSpace(m.searchInput)
+		m.updateViewContent()
+	case tea.KeyEsc:
+		m.searching = false
+	case tea.KeyBackspace:
+		if r := []rune(m.searchInput); len(r) > 0 {
+			m.searchInput = string(r[:len(r)-1])
+		}
+	case tea.KeyCtrlU:
+		m.searchInput = ""
+	case tea.KeyCtrlC:
+		m.searching = false
+	case tea.KeyRunes, tea.KeySpace:
+		m.searchInput += string(msg.Runes)
+	}
+}
+
+// togglePause freezes or resumes the feed panel. While paused, events are
+// still collected (and the agent tree still updates) so nothing is lost.
+func (m *Model) togglePause() {
+	m.paused = !m.paused
+	if m.paused {
+		m.frozen = append([]Event(nil), m.events...)
+		m.missed = 0
+	} else {
+		m.frozen = nil
+		m.missed = 0
+	}
+	m.updateViewContent()
+}
+
 // updateViewportSizes recalculates viewport dimensions
 func (m *Model) updateViewportSizes() {
 	// Reserve space: header (1) + borders (6 for 3 panels) + status bar (1) + help (1-2)
@@ -387,6 +460,9 @@ func (m *Model) addEvent(e Event) {
 
 	// Add to event feed
 	m.events = append(m.events, e)
+	if m.paused {
+		m.missed++
+	}
 
 	// Keep max 1000 events
 	if len(m.events) > 1000 {
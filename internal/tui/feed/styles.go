@@ -33,6 +33,14 @@ var (
 	FilterStyle = lipgloss.NewStyle().
 			Foreground(colorDim)
 
+	FilterActiveStyle = lipgloss.NewStyle().
+				Foreground(colorWarning).
+				Bold(true)
+
+	PausedStyle = lipgloss.NewStyle().
+			Foreground(colorWarning).
+			Bold(true)
+
 	// Agent tree styles
 	TreePanelStyle = lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
@@ -81,6 +89,13 @@ var (
 	EventDeleteStyle = lipgloss.NewStyle().
 				Foreground(colorWarning)
 
+	// Severity styles color the message of warning and error events
+	SeverityWarningStyle = lipgloss.NewStyle().
+				Foreground(colorWarning)
+
+	SeverityErrorStyle = lipgloss.NewStyle().
+				Foreground(colorError)
+
 	// Status bar styles
 	StatusBarStyle = lipgloss.NewStyle().
 			Background(lipgloss.Color("236")).
@@ -146,6 +161,7 @@ var (
 		"merge_skipped": "⊘",
 		// General gt events
 		"sling":   "🎯",
+		"claim":   "✋",
 		"hook":    "🪝",
 		"unhook":  "↩",
 		"handoff": "🤝",
@@ -156,5 +172,14 @@ var (
 		"nudge":   "⚡",
 		"boot":    "🔌",
 		"halt":    "⏹",
+		// Daemon lifecycle events
+		"restart":       "♻",
+		"session_death": "☠",
+		"mass_death":    "☠",
+		// Alerts and queue control
+		"alert_fired":    "🚨",
+		"alert_resolved": "✓",
+		"queue_paused":   "⏸",
+		"queue_resumed":  "▶",
 	}
 )
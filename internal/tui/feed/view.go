@@ -47,11 +47,9 @@ func (m *Model) render() string {
 func (m *Model) renderHeader() string {
 	title := TitleStyle.Render("GT Feed")
 
-	filter := ""
-	if m.filter != "" {
-		filter = FilterStyle.Render(fmt.Sprintf("Filter: %s", m.filter))
-	} else {
-		filter = FilterStyle.Render("Filter: all")
+	filter := FilterStyle.Render(fmt.Sprintf("Filter: %s", m.filter))
+	if m.filter.active() {
+		filter = FilterActiveStyle.Render(fmt.Sprintf("Filter: %s", m.filter))
 	}
 
 	// Right-align filter
@@ -215,21 +213,23 @@ func (m *Model) renderAgent(icon string, agent *Agent, indent int) string {
 
 // renderFeed renders the event feed content
 func (m *Model) renderFeed() string {
-	if len(m.events) == 0 {
+	events := m.events
+	if m.paused {
+		events = m.frozen
+	}
+	if len(events) == 0 {
 		return AgentIdleStyle.Render("No events yet")
 	}
 
+	// Show the 100 most recent matching events first (reversed)
 	var lines []string
-
-	// Show most recent events first (reversed)
-	start := 0
-	if len(m.events) > 100 {
-		start = len(m.events) - 100
+	for i := len(events) - 1; i >= 0 && len(lines) < 100; i-- {
+		if m.filter.matches(events[i]) {
+			lines = append(lines, m.renderEvent(events[i]))
+		}
 	}
-
-	for i := len(m.events) - 1; i >= start; i-- {
-		event := m.events[i]
-		lines = append(lines, m.renderEvent(event))
+	if len(lines) == 0 {
+		return AgentIdleStyle.Render(fmt.Sprintf("No events match filter: %s", m.filter))
 	}
 
 	return strings.Join(lines, "\n")
@@ -255,19 +255,19 @@ func (m *Model) renderEvent(e Event) string {
 		symbolStyle = EventUpdateStyle
 	case "complete", "patrol_complete", "merged", "done":
 		symbolStyle = EventCompleteStyle
-	case "fail", "merge_failed":
+	case "fail", "merge_failed", "session_death", "mass_death":
 		symbolStyle = EventFailStyle
 	case "delete":
 		symbolStyle = EventDeleteStyle
 	case "merge_started":
 		symbolStyle = EventMergeStartedStyle
-	case "merge_skipped":
+	case "merge_skipped", "restart", "kill", "halt":
 		symbolStyle = EventMergeSkippedStyle
 	case "patrol_started", "polecat_checked":
 		symbolStyle = EventUpdateStyle
 	case "polecat_nudged", "escalation_sent", "nudge":
 		symbolStyle = EventFailStyle // Use red/warning style for nudges and escalations
-	case "sling", "hook", "spawn", "boot":
+	case "sling", "claim", "hook", "spawn", "boot":
 		symbolStyle = EventCreateStyle
 	case "handoff", "mail":
 		symbolStyle = EventUpdateStyle
@@ -290,11 +290,17 @@ func (m *Model) renderEvent(e Event) string {
 		actor = RoleStyle.Render(actor) + ": "
 	}
 
-	// Message
+	// Message, colored by severity
 	msg := e.Message
 	if msg == "" && e.Raw != "" {
 		msg = e.Raw
 	}
+	switch EventSeverity(e.Type) {
+	case SeverityError:
+		msg = SeverityErrorStyle.Render(msg)
+	case SeverityWarning:
+		msg = SeverityWarningStyle.Render(msg)
+	}
 
 	return fmt.Sprintf("%s %s %s%s", ts, styledSymbol, actor, msg)
 }
@@ -321,6 +327,14 @@ func (m *Model) renderStatusBar() string {
 
 	// Combine
 	left := panel + " " + count
+	if m.paused {
+		left += " " + PausedStyle.Render(fmt.Sprintf("PAUSED +%d", m.missed))
+	}
+	if m.searching {
+		left = panel + " search: " + m.searchInput + "█"
+		help = HelpKeyStyle.Render("enter") + HelpDescStyle.Render(":apply  ") +
+			HelpKeyStyle.Render("esc") + HelpDescStyle.Render(":cancel")
+	}
 	gap := m.width - lipgloss.Width(left) - lipgloss.Width(help) - 4
 	if gap < 1 {
 		gap = 1
@@ -334,7 +348,9 @@ func (m *Model) renderShortHelp() string {
 	hints := []string{
 		HelpKeyStyle.Render("j/k") + HelpDescStyle.Render(":scroll"),
 		HelpKeyStyle.Render("tab") + HelpDescStyle.Render(":switch"),
+		HelpKeyStyle.Render("p") + HelpDescStyle.Render(":pause"),
 		HelpKeyStyle.Render("/") + HelpDescStyle.Render(":search"),
+		HelpKeyStyle.Render("f") + HelpDescStyle.Render(":filter"),
 		HelpKeyStyle.Render("q") + HelpDescStyle.Render(":quit"),
 		HelpKeyStyle.Render("?") + HelpDescStyle.Render(":help"),
 	}
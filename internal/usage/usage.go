@@ -0,0 +1,418 @@
+// Package usage reports token usage and response latency of Gas Town agents.
+//
+// Agents whose CLI records usage metadata in JSON session logs (see the
+// usage_log field of agent presets) are measured by parsing those logs:
+// each model response yields a Sample, which is attributed to the agent
+// whose working directory the session ran in. Nothing is cached; reports
+// are derived from the session logs each time.
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Usage log formats.
+const (
+	FormatClaude = "claude" // Claude Code session transcripts
+	FormatCodex  = "codex"  // Codex rollout logs
+)
+
+// Tokens counts the tokens of one or more model responses.
+type Tokens struct {
+	Input      int64 `json:"input"`
+	Output     int64 `json:"output"`
+	CacheRead  int64 `json:"cache_read"`
+	CacheWrite int64 `json:"cache_write"`
+}
+
+// Add adds o to t.
+func (t *Tokens) Add(o Tokens) {
+	t.Input += o.Input
+	t.Output += o.Output
+	t.CacheRead += o.CacheRead
+	t.CacheWrite += o.CacheWrite
+}
+
+// Total returns all tokens processed, including cache reads and writes.
+func (t Tokens) Total() int64 {
+	return t.Input + t.Output + t.CacheRead + t.CacheWrite
+}
+
+// Sample is the usage of one model response.
+type Sample struct {
+	Time    time.Time
+	Model   string
+	Tokens  Tokens
+	Latency time.Duration // From the prompt or tool result to the response; 0 if unknown
+}
+
+// Session is a parsed agent session log.
+type Session struct {
+	Path    string
+	Format  string
+	WorkDir string
+	Samples []Sample
+}
+
+// Parse parses a session log in the given format, returning the session's
+// working directory and its samples. Malformed lines are skipped.
+func Parse(format string, r io.Reader) (string, []Sample, error) {
+	switch format {
+	case FormatClaude:
+		return parseClaude(r)
+	case FormatCodex:
+		return parseCodex(r)
+	}
+	return "", nil, nil
+}
+
+func scanLines(r io.Reader, fn func(line []byte)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024) // Transcript lines can be huge
+	for scanner.Scan() {
+		fn(scanner.Bytes())
+	}
+	return scanner.Err()
+}
+
+// claudeLine is the subset of a Claude Code transcript line used here.
+type claudeLine struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Cwd       string    `json:"cwd"`
+	Message   struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Usage *struct {
+			InputTokens              int64 `json:"input_tokens"`
+			OutputTokens             int64 `json:"output_tokens"`
+			CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+			CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+}
+
+// parseClaude parses a Claude Code transcript. A response streamed over
+// several lines shares a message ID; its usage is taken from the last one.
+func parseClaude(r io.Reader) (string, []Sample, error) {
+	var workDir string
+	var samples []Sample
+	index := make(map[string]int) // message ID -> samples index
+	var prompted time.Time
+
+	err := scanLines(r, func(data []byte) {
+		var line claudeLine
+		if json.Unmarshal(data, &line) != nil {
+			return
+		}
+		if workDir == "" && line.Cwd != "" {
+			workDir = line.Cwd
+		}
+		switch line.Type {
+		case "user":
+			prompted = line.Timestamp
+		case "assistant":
+			u := line.Message.Usage
+			if u == nil || line.Message.Model == "<synthetic>" {
+				return
+			}
+			tokens := Tokens{
+				Input:      u.InputTokens,
+				Output:     u.OutputTokens,
+				CacheRead:  u.CacheReadInputTokens,
+				CacheWrite: u.CacheCreationInputTokens,
+			}
+			if i, ok := index[line.Message.ID]; ok && line.Message.ID != "" {
+				samples[i].Tokens = tokens
+				return
+			}
+			s := Sample{Time: line.Timestamp, Model: line.Message.Model, Tokens: tokens}
+			if !prompted.IsZero() && line.Timestamp.After(prompted) {
+				s.Latency = line.Timestamp.Sub(prompted)
+			}
+			prompted = time.Time{}
+			index[line.Message.ID] = len(samples)
+			samples = append(samples, s)
+		}
+	})
+	return workDir, samples, err
+}
+
+// codexLine is the subset of a Codex rollout log line used here.
+type codexLine struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Payload   struct {
+		Type  string `json:"type"`
+		Cwd   string `json:"cwd"`
+		Model string `json:"model"`
+		Info  *struct {
+			Last struct {
+				InputTokens       int64 `json:"input_tokens"`
+				CachedInputTokens int64 `json:"cached_input_tokens"`
+				OutputTokens      int64 `json:"output_tokens"`
+			} `json:"last_token_usage"`
+		} `json:"info"`
+	} `json:"payload"`
+}
+
+// parseCodex parses a Codex rollout log, where each model response is
+// followed by a token_count event.
+func parseCodex(r io.Reader) (string, []Sample, error) {
+	var workDir, model string
+	var samples []Sample
+	var prompted time.Time
+
+	err := scanLines(r, func(data []byte) {
+		var line codexLine
+		if json.Unmarshal(data, &line) != nil {
+			return
+		}
+		p := line.Payload
+		switch {
+		case line.Type == "session_meta" || line.Type == "turn_context":
+			if workDir == "" && p.Cwd != "" {
+				workDir = p.Cwd
+			}
+			if p.Model != "" {
+				model = p.Model
+			}
+		case p.Type == "user_message" || p.Type == "function_call_output":
+			prompted = line.Timestamp
+		case p.Type == "token_count" && p.Info != nil:
+			s := Sample{
+				Time:  line.Timestamp,
+				Model: model,
+				Tokens: Tokens{
+					Input:     p.Info.Last.InputTokens - p.Info.Last.CachedInputTokens,
+					Output:    p.Info.Last.OutputTokens,
+					CacheRead: p.Info.Last.CachedInputTokens,
+				},
+			}
+			if !prompted.IsZero() && line.Timestamp.After(prompted) {
+				s.Latency = line.Timestamp.Sub(prompted)
+			}
+			prompted = time.Time{}
+			samples = append(samples, s)
+		}
+	})
+	return workDir, samples, err
+}
+
+// LogDir returns the directory holding session logs of the given format.
+func LogDir(format string) string {
+	home, _ := os.UserHomeDir()
+	switch format {
+	case FormatClaude:
+		if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+			return filepath.Join(dir, "projects")
+		}
+		return filepath.Join(home, ".claude", "projects")
+	case FormatCodex:
+		if dir := os.Getenv("CODEX_HOME"); dir != "" {
+			return filepath.Join(dir, "sessions")
+		}
+		return filepath.Join(home, ".codex", "sessions")
+	}
+	return ""
+}
+
+// claudeProjectName matches how Claude Code names a project's transcript
+// directory after its working directory.
+var claudeProjectName = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// LogPaths returns the session logs of the given format that may belong to
+// agents working under townRoot. Claude Code logs are narrowed to the town's
+// project directories; Codex logs cannot be, so all are returned and the
+// caller checks each session's working directory.
+func LogPaths(townRoot, format string) []string {
+	root := LogDir(format)
+	if root == "" {
+		return nil
+	}
+	var paths []string
+	switch format {
+	case FormatClaude:
+		// Transcripts of a working directory live in one directory
+		// named after it, so only the town's directories are read
+		prefix := claudeProjectName.ReplaceAllString(townRoot, "-")
+		paths, _ = filepath.Glob(filepath.Join(root, prefix+"*", "*.jsonl"))
+	case FormatCodex:
+		_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && strings.HasSuffix(path, ".jsonl") {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+	}
+	return paths
+}
+
+// Sessions finds and parses the session logs, in the given formats, of
+// agents working under townRoot that were active since the given time.
+func Sessions(townRoot string, formats []string, since time.Time) ([]Session, error) {
+	var sessions []Session
+	for _, format := range formats {
+		for _, path := range LogPaths(townRoot, format) {
+			if info, err := os.Stat(path); err != nil || info.ModTime().Before(since) {
+				continue
+			}
+			f, err := os.Open(path) //nolint:gosec // G304: agent session log
+			if err != nil {
+				continue
+			}
+			workDir, samples, err := Parse(format, f)
+			_ = f.Close()
+			if err != nil || !within(townRoot, workDir) {
+				continue
+			}
+			sessions = append(sessions, Session{Path: path, Format: format, WorkDir: workDir, Samples: samples})
+		}
+	}
+	return sessions, nil
+}
+
+func within(root, dir string) bool {
+	rel, err := filepath.Rel(root, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Agent identifies who a session belongs to.
+type Agent struct {
+	Address string `json:"agent"` // e.g. "gastown/polecats/toast"
+	Role    string `json:"role"`  // mayor, deacon, boot, dog, witness, refinery, polecat, crew
+	Rig     string `json:"rig,omitempty"`
+}
+
+// AgentForDir returns the agent whose working directory contains dir, by
+// the town's directory layout.
+func AgentForDir(townRoot, dir string) (Agent, bool) {
+	rel, err := filepath.Rel(townRoot, dir)
+	if err != nil || !within(townRoot, dir) {
+		return Agent{}, false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	switch {
+	case parts[0] == "." || parts[0] == "mayor":
+		return Agent{Address: "mayor", Role: "mayor"}, true
+	case parts[0] == "deacon" && len(parts) >= 3 && parts[1] == "dogs":
+		if parts[2] == "boot" {
+			return Agent{Address: "deacon/dogs/boot", Role: "boot"}, true
+		}
+		return Agent{Address: "deacon/dogs/" + parts[2], Role: "dog"}, true
+	case parts[0] == "deacon":
+		return Agent{Address: "deacon", Role: "deacon"}, true
+	case len(parts) < 2:
+		return Agent{}, false
+	}
+
+	rig := parts[0]
+	switch parts[1] {
+	case "witness", "refinery":
+		return Agent{Address: rig + "/" + parts[1], Role: parts[1], Rig: rig}, true
+	case "polecats", "crew":
+		if len(parts) < 3 {
+			return Agent{}, false
+		}
+		role := "polecat"
+		if parts[1] == "crew" {
+			role = "crew"
+		}
+		return Agent{Address: rig + "/" + parts[1] + "/" + parts[2], Role: role, Rig: rig}, true
+	}
+	return Agent{}, false
+}
+
+// Entry is the usage of one agent on one day (local time).
+type Entry struct {
+	Agent
+	Day     string  `json:"day"` // YYYY-MM-DD
+	Turns   int     `json:"turns"`
+	Tokens  Tokens  `json:"tokens"`
+	Latency Latency `json:"latency"`
+}
+
+// Latency accumulates response latencies.
+type Latency struct {
+	Total time.Duration `json:"total_ns"`
+	Count int           `json:"count"`
+	Max   time.Duration `json:"max_ns"`
+}
+
+// Add records one response latency.
+func (l *Latency) Add(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	l.Total += d
+	l.Count++
+	if d > l.Max {
+		l.Max = d
+	}
+}
+
+// Merge adds the latencies recorded in o.
+func (l *Latency) Merge(o Latency) {
+	l.Total += o.Total
+	l.Count += o.Count
+	if o.Max > l.Max {
+		l.Max = o.Max
+	}
+}
+
+// Mean returns the average latency, or 0 if none were recorded.
+func (l Latency) Mean() time.Duration {
+	if l.Count == 0 {
+		return 0
+	}
+	return l.Total / time.Duration(l.Count)
+}
+
+// Accumulate totals the samples of sessions in [since, until) per agent and
+// day. Sessions outside any agent's directory are ignored. A zero until
+// means no upper bound. Entries are sorted by day, then agent.
+func Accumulate(townRoot string, sessions []Session, since, until time.Time) []Entry {
+	type key struct{ agent, day string }
+	entries := make(map[key]*Entry)
+	for _, s := range sessions {
+		agent, ok := AgentForDir(townRoot, s.WorkDir)
+		if !ok {
+			continue
+		}
+		for _, sample := range s.Samples {
+			if sample.Time.Before(since) || (!until.IsZero() && !sample.Time.Before(until)) {
+				continue
+			}
+			day := sample.Time.Local().Format("2006-01-02")
+			k := key{agent.Address, day}
+			e := entries[k]
+			if e == nil {
+				e = &Entry{Agent: agent, Day: day}
+				entries[k] = e
+			}
+			e.Turns++
+			e.Tokens.Add(sample.Tokens)
+			e.Latency.Add(sample.Latency)
+		}
+	}
+
+	result := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, *e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Day != result[j].Day {
+			return result[i].Day < result[j].Day
+		}
+		return result[i].Address < result[j].Address
+	})
+	return result
+}
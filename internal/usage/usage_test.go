@@ -0,0 +1,121 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const claudeTranscript = `{"type":"user","timestamp":"2026-01-15T10:00:00.000Z","cwd":"/town/gastown/polecats/toast/gastown","message":{"role":"user","content":"go"}}
+{"type":"assistant","timestamp":"2026-01-15T10:00:04.000Z","message":{"id":"msg_1","model":"claude-sonnet","usage":{"input_tokens":10,"output_tokens":1,"cache_read_input_tokens":1000,"cache_creation_input_tokens":200}}}
+{"type":"assistant","timestamp":"2026-01-15T10:00:06.000Z","message":{"id":"msg_1","model":"claude-sonnet","usage":{"input_tokens":10,"output_tokens":50,"cache_read_input_tokens":1000,"cache_creation_input_tokens":200}}}
+{"type":"user","timestamp":"2026-01-15T10:00:07.000Z","message":{"role":"user","content":[{"type":"tool_result"}]}}
+not json
+{"type":"assistant","timestamp":"2026-01-15T10:00:09.000Z","message":{"id":"msg_2","model":"claude-sonnet","usage":{"input_tokens":5,"output_tokens":20}}}
+{"type":"assistant","timestamp":"2026-01-15T10:00:10.000Z","message":{"id":"msg_3","model":"<synthetic>","usage":{"input_tokens":0,"output_tokens":0}}}
+`
+
+func TestParse_Claude(t *testing.T) {
+	workDir, samples, err := Parse(FormatClaude, strings.NewReader(claudeTranscript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if workDir != "/town/gastown/polecats/toast/gastown" {
+		t.Errorf("workDir = %q", workDir)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("got %d samples, want 2 (streamed lines merged, synthetic skipped)", len(samples))
+	}
+	if got := samples[0].Tokens; got != (Tokens{Input: 10, Output: 50, CacheRead: 1000, CacheWrite: 200}) {
+		t.Errorf("first sample tokens = %+v, want the last streamed usage", got)
+	}
+	if samples[0].Latency != 4*time.Second || samples[1].Latency != 2*time.Second {
+		t.Errorf("latencies = %v, %v; want 4s, 2s", samples[0].Latency, samples[1].Latency)
+	}
+}
+
+func TestParse_Codex(t *testing.T) {
+	rollout := `{"timestamp":"2026-01-15T10:00:00Z","type":"session_meta","payload":{"id":"s1","cwd":"/town/gastown/crew/max"}}
+{"timestamp":"2026-01-15T10:00:00Z","type":"turn_context","payload":{"cwd":"/town/gastown/crew/max","model":"gpt-5-codex"}}
+{"timestamp":"2026-01-15T10:00:01Z","type":"event_msg","payload":{"type":"user_message","message":"go"}}
+{"timestamp":"2026-01-15T10:00:04Z","type":"event_msg","payload":{"type":"token_count","info":null}}
+{"timestamp":"2026-01-15T10:00:04Z","type":"event_msg","payload":{"type":"token_count","info":{"last_token_usage":{"input_tokens":1200,"cached_input_tokens":1000,"output_tokens":80}}}}
+`
+	workDir, samples, err := Parse(FormatCodex, strings.NewReader(rollout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if workDir != "/town/gastown/crew/max" || len(samples) != 1 {
+		t.Fatalf("workDir = %q, samples = %+v", workDir, samples)
+	}
+	s := samples[0]
+	if s.Model != "gpt-5-codex" || s.Tokens != (Tokens{Input: 200, Output: 80, CacheRead: 1000}) || s.Latency != 3*time.Second {
+		t.Errorf("sample = %+v", s)
+	}
+}
+
+func TestAgentForDir(t *testing.T) {
+	townRoot := filepath.FromSlash("/town")
+	for dir, want := range map[string]Agent{
+		"/town":                          {Address: "mayor", Role: "mayor"},
+		"/town/mayor/rig":                {Address: "mayor", Role: "mayor"},
+		"/town/deacon":                   {Address: "deacon", Role: "deacon"},
+		"/town/deacon/dogs/boot":         {Address: "deacon/dogs/boot", Role: "boot"},
+		"/town/deacon/dogs/alpha/x":      {Address: "deacon/dogs/alpha", Role: "dog"},
+		"/town/gastown/refinery/rig":     {Address: "gastown/refinery", Role: "refinery", Rig: "gastown"},
+		"/town/gastown/polecats/toast/g": {Address: "gastown/polecats/toast", Role: "polecat", Rig: "gastown"},
+		"/town/gastown/crew/max":         {Address: "gastown/crew/max", Role: "crew", Rig: "gastown"},
+	} {
+		got, ok := AgentForDir(townRoot, filepath.FromSlash(dir))
+		if !ok || got != want {
+			t.Errorf("AgentForDir(%s) = %+v, %v; want %+v", dir, got, ok, want)
+		}
+	}
+	for _, dir := range []string{"/elsewhere", "/town/gastown", "/town/gastown/polecats"} {
+		if got, ok := AgentForDir(townRoot, filepath.FromSlash(dir)); ok {
+			t.Errorf("AgentForDir(%s) = %+v, want no agent", dir, got)
+		}
+	}
+}
+
+func TestSessionsAndAccumulate(t *testing.T) {
+	claudeDir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", claudeDir)
+	townRoot := "/town"
+
+	// One transcript in the town, one in an unrelated project
+	write := func(project, content string) {
+		dir := filepath.Join(claudeDir, "projects", project)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "session.jsonl"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("-town-gastown-polecats-toast-gastown", claudeTranscript)
+	write("-other", strings.ReplaceAll(claudeTranscript, "/town/", "/other/"))
+
+	sessions, err := Sessions(townRoot, []string{FormatClaude}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(sessions))
+	}
+
+	since := time.Date(2026, 1, 15, 10, 0, 5, 0, time.UTC)
+	entries := Accumulate(townRoot, sessions, time.Time{}, time.Time{})
+	if len(entries) != 1 {
+		t.Fatalf("entries = %+v", entries)
+	}
+	e := entries[0]
+	if e.Address != "gastown/polecats/toast" || e.Turns != 2 || e.Tokens.Total() != 1285 || e.Latency.Mean() != 3*time.Second || e.Latency.Max != 4*time.Second {
+		t.Errorf("entry = %+v", e)
+	}
+	if entries := Accumulate(townRoot, sessions, since, time.Time{}); len(entries) != 1 || entries[0].Turns != 1 {
+		t.Errorf("since filter: entries = %+v", entries)
+	}
+}
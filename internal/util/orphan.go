@@ -200,13 +200,6 @@ func parseEtime(etime string) (int, error) {
 	return days*86400 + hours*3600 + minutes*60 + seconds, nil
 }
 
-// OrphanedProcess represents a claude process running without a controlling terminal.
-type OrphanedProcess struct {
-	PID int
-	Cmd string
-	Age int // Age in seconds
-}
-
 // FindOrphanedClaudeProcesses finds claude/codex processes without a controlling terminal.
 // These are typically subagent processes spawned by Claude Code's Task tool that didn't
 // clean up properly after completion.
@@ -287,13 +280,6 @@ func FindOrphanedClaudeProcesses() ([]OrphanedProcess, error) {
 	return orphans, nil
 }
 
-// CleanupResult describes what happened to an orphaned process.
-type CleanupResult struct {
-	Process OrphanedProcess
-	Signal  string // "SIGTERM", "SIGKILL", or "UNKILLABLE"
-	Error   error
-}
-
 // CleanupOrphanedClaudeProcesses finds and kills orphaned claude/codex processes.
 //
 // Uses a state machine to escalate signals:
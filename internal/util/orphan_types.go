@@ -0,0 +1,15 @@
+package util
+
+// OrphanedProcess represents a claude process running without a controlling terminal.
+type OrphanedProcess struct {
+	PID int
+	Cmd string
+	Age int // Age in seconds
+}
+
+// CleanupResult describes what happened to an orphaned process.
+type CleanupResult struct {
+	Process OrphanedProcess
+	Signal  string // "SIGTERM", "SIGKILL", or "UNKILLABLE"
+	Error   error
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package util
+
+// FindOrphanedClaudeProcesses is not supported on Windows: orphan detection
+// relies on ps TTY columns and tmux panes. It reports no orphans.
+func FindOrphanedClaudeProcesses() ([]OrphanedProcess, error) {
+	return nil, nil
+}
+
+// CleanupOrphanedClaudeProcesses is a no-op on Windows.
+func CleanupOrphanedClaudeProcesses() ([]CleanupResult, error) {
+	return nil, nil
+}
@@ -10,11 +10,13 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/activity"
+	"github.com/steveyegge/gastown/internal/keepalive"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 // LiveConvoyFetcher fetches convoy data from beads.
 type LiveConvoyFetcher struct {
+	townRoot  string
 	townBeads string
 }
 
@@ -26,10 +28,19 @@ func NewLiveConvoyFetcher() (*LiveConvoyFetcher, error) {
 	}
 
 	return &LiveConvoyFetcher{
+		townRoot:  townRoot,
 		townBeads: filepath.Join(townRoot, ".beads"),
 	}, nil
 }
 
+// rigThresholds returns the keepalive thresholds that color a rig's
+// activity, or the defaults when the rig is unknown.
+func (f *LiveConvoyFetcher) rigThresholds(rig string) keepalive.Thresholds {
+	if f.townRoot == "" || rig == "" {
+		return keepalive.DefaultThresholds
+	}
+	return keepalive.RigThresholds(filepath.Join(f.townRoot, rig))
+}
 
 // FetchConvoys fetches all open convoys with their activity data.
 func (f *LiveConvoyFetcher) FetchConvoys() ([]ConvoyRow, error) {
@@ -69,6 +80,7 @@ func (f *LiveConvoyFetcher) FetchConvoys() ([]ConvoyRow, error) {
 		row.Total = len(tracked)
 
 		var mostRecentActivity time.Time
+		var mostRecentRig string
 		var mostRecentUpdated time.Time
 		var hasAssignee bool
 		for _, t := range tracked {
@@ -78,6 +90,7 @@ func (f *LiveConvoyFetcher) FetchConvoys() ([]ConvoyRow, error) {
 			// Track most recent activity from workers
 			if t.LastActivity.After(mostRecentActivity) {
 				mostRecentActivity = t.LastActivity
+				mostRecentRig, _, _ = strings.Cut(t.Assignee, "/")
 			}
 			// Track most recent updated_at as fallback
 			if t.UpdatedAt.After(mostRecentUpdated) {
@@ -93,7 +106,7 @@ func (f *LiveConvoyFetcher) FetchConvoys() ([]ConvoyRow, error) {
 		// Calculate activity info from most recent worker activity
 		if !mostRecentActivity.IsZero() {
 			// Have active tmux session activity from assigned workers
-			row.LastActivity = activity.Calculate(mostRecentActivity)
+			row.LastActivity = activity.CalculateFor(mostRecentActivity, f.rigThresholds(mostRecentRig))
 		} else if !hasAssignee {
 			// No assignees found in beads - try fallback to any running polecat activity
 			// This handles cases where bd update --assignee didn't persist or wasn't returned
@@ -319,7 +332,8 @@ func (f *LiveConvoyFetcher) getWorkersFromAssignees(details map[string]*issueDet
 	return result
 }
 
-// getSessionActivityForAssignee looks up tmux session activity for an assignee.
+// getSessionActivityForAssignee looks up tmux session activity for an assignee,
+// or the session's keepalive if that is more recent.
 // Assignee format: "rigname/polecats/polecatname" -> session "gt-rigname-polecatname"
 func (f *LiveConvoyFetcher) getSessionActivityForAssignee(assignee string) *time.Time {
 	// Parse assignee: "roxas/polecats/dag" -> rig="roxas", polecat="dag"
@@ -360,6 +374,10 @@ func (f *LiveConvoyFetcher) getSessionActivityForAssignee(assignee string) *time
 	}
 
 	activity := time.Unix(activityUnix, 0)
+	// A long command's heartbeat is activity even while the pane is quiet
+	if ka := keepalive.ReadSession(f.townRoot, sessionName); ka != nil && ka.Timestamp.After(activity) {
+		activity = ka.Timestamp
+	}
 	return &activity
 }
 
@@ -407,6 +425,9 @@ func (f *LiveConvoyFetcher) getAllPolecatActivity() *time.Time {
 		}
 
 		activityTime := time.Unix(activityUnix, 0)
+		if ka := keepalive.ReadSession(f.townRoot, sessionName); ka != nil && ka.Timestamp.After(activityTime) {
+			activityTime = ka.Timestamp
+		}
 		if activityTime.After(mostRecent) {
 			mostRecent = activityTime
 		}
@@ -659,7 +680,7 @@ func (f *LiveConvoyFetcher) FetchPolecats() ([]PolecatRow, error) {
 			Name:         polecat,
 			Rig:          rig,
 			SessionID:    sessionName,
-			LastActivity: activity.Calculate(activityTime),
+			LastActivity: activity.CalculateFor(activityTime, f.rigThresholds(rig)),
 			StatusHint:   statusHint,
 		})
 	}
@@ -0,0 +1,417 @@
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	gh "github.com/steveyegge/gastown/internal/github"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/runstate"
+)
+
+// maxRecent is how many deliveries the saved state keeps for gt webhooks.
+const maxRecent = 20
+
+// Delivery records what the receiver did with one event.
+type Delivery struct {
+	Time      time.Time `json:"time"`
+	Source    string    `json:"source"`
+	Rule      string    `json:"rule,omitempty"`
+	Key       string    `json:"key,omitempty"`
+	Bead      string    `json:"bead,omitempty"`
+	Rig       string    `json:"rig,omitempty"`
+	Duplicate bool      `json:"duplicate,omitempty"`
+	Slung     bool      `json:"slung,omitempty"`
+	Callback  string    `json:"callback,omitempty"` // Subject of the mayor callback sent
+	Error     string    `json:"error,omitempty"`
+}
+
+// State is the receiver's saved state.
+type State struct {
+	// Seen maps dedup keys to the delivery that created their bead.
+	Seen map[string]Delivery `json:"seen,omitempty"`
+
+	// Recent are the latest deliveries, newest last.
+	Recent []Delivery `json:"recent,omitempty"`
+
+	// Counts of requests by outcome.
+	Created    int `json:"created"`
+	Duplicates int `json:"duplicates"`
+	Unmatched  int `json:"unmatched"`
+	Rejected   int `json:"rejected"`
+	Failed     int `json:"failed"`
+	Callbacks  int `json:"callbacks"`
+}
+
+// StateFile returns the path of the receiver's saved state.
+func StateFile(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "webhooks.json")
+}
+
+// stateDoc returns the receiver's state document.
+func stateDoc(townRoot string) *runstate.Doc[State] {
+	return runstate.NewDocAt(StateFile(townRoot), func() *State { return &State{} })
+}
+
+// LoadState reads the receiver's saved state.
+func LoadState(townRoot string) (*State, error) {
+	state, err := stateDoc(townRoot).Load()
+	if err != nil {
+		return nil, err
+	}
+	if state.Seen == nil {
+		state.Seen = map[string]Delivery{}
+	}
+	return state, nil
+}
+
+// Result is the response to a webhook request.
+type Result struct {
+	Matched   bool   `json:"matched"`
+	Rule      string `json:"rule,omitempty"`
+	Bead      string `json:"bead,omitempty"`
+	Rig       string `json:"rig,omitempty"`
+	Duplicate bool   `json:"duplicate,omitempty"`
+	Sling     bool   `json:"sling,omitempty"`
+	Callback  string `json:"callback,omitempty"`
+}
+
+// Receiver serves POST /hooks/<source>, turning matching events into beads.
+type Receiver struct {
+	townRoot string
+	listen   string
+	token    string
+	router   *Router
+	github   *GitHubConfig
+	secret   string
+	logger   func(format string, args ...interface{})
+	server   *http.Server
+
+	// create creates the bead for a match and returns its ID (replaced in
+	// tests).
+	create func(m *Match) (string, error)
+
+	// sling slings a bead to a rig (replaced in tests).
+	sling func(ctx context.Context, beadID, rig string) error
+
+	// sendMail delivers a callback to the Mayor (replaced in tests).
+	sendMail func(msg *mail.Message) error
+
+	handleMu sync.Mutex // serializes Handle, so repeats find the bead
+	mu       sync.Mutex // protects state
+	state    *State
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewReceiver validates the config and creates a receiver for the town.
+func NewReceiver(townRoot string, cfg Config, logger func(format string, args ...interface{})) (*Receiver, error) {
+	router, err := cfg.Compile()
+	if err != nil {
+		return nil, err
+	}
+	token := os.ExpandEnv(cfg.Token)
+	if token == "" {
+		return nil, errors.New("webhooks: token is required")
+	}
+	listen := cfg.Listen
+	if listen == "" {
+		listen = DefaultListen
+	}
+	r := &Receiver{
+		townRoot: townRoot,
+		listen:   listen,
+		token:    token,
+		router:   router,
+		github:   cfg.GitHub,
+		logger:   logger,
+	}
+	if cfg.GitHub != nil {
+		r.secret = os.ExpandEnv(cfg.GitHub.Secret)
+	}
+	r.create = r.createBead
+	r.sling = r.slingBead
+	r.sendMail = mail.NewRouterWithTownRoot(townRoot, townRoot).Send
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	if r.state, err = LoadState(townRoot); err != nil {
+		logger("webhooks: %v; starting with fresh state", err)
+		r.state = &State{Seen: map[string]Delivery{}}
+	}
+	return r, nil
+}
+
+// Start begins serving /hooks/.
+func (r *Receiver) Start() error {
+	ln, err := net.Listen("tcp", r.listen)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", r.listen, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/hooks/", r)
+	r.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := r.server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			r.logger("webhook server error: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop shuts the server down and waits for slings in progress.
+func (r *Receiver) Stop() {
+	if r.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = r.server.Shutdown(ctx)
+	}
+	r.cancel()
+	r.wg.Wait()
+}
+
+// Addr returns the address the server listens on.
+func (r *Receiver) Addr() string {
+	return r.listen
+}
+
+// ServeHTTP handles POST /hooks/<source>.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	source := strings.TrimPrefix(req.URL.Path, "/hooks/")
+	if !sourcePattern.MatchString(source) {
+		http.NotFound(w, req)
+		return
+	}
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, MaxBodyBytes))
+	if err != nil {
+		http.Error(w, "reading body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	github := source == GitHubSource && r.github != nil
+	signed := github && gh.VerifySignature(r.secret, body, req.Header.Get("X-Hub-Signature-256"))
+	if !signed && !r.authorized(req) {
+		r.mu.Lock()
+		r.state.Rejected++
+		r.mu.Unlock()
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var res Result
+	if github {
+		res, err = r.HandleGitHub(req.Header.Get("X-GitHub-Event"), body, time.Now())
+	} else {
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			http.Error(w, "body must be a JSON object: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		res, err = r.Handle(source, payload, time.Now())
+	}
+	if err != nil {
+		r.logger("webhook %s: %v", source, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// authorized checks the request's token, given as a bearer token, in the
+// X-Gastown-Token header, or (for senders that cannot set headers) in the
+// token query parameter.
+func (r *Receiver) authorized(req *http.Request) bool {
+	got := req.Header.Get("X-Gastown-Token")
+	if auth := req.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		got = strings.TrimPrefix(auth, "Bearer ")
+	}
+	if got == "" {
+		got = req.URL.Query().Get("token")
+	}
+	return got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(r.token)) == 1
+}
+
+// Handle routes an event and creates, or finds the existing, bead for it.
+// Matches that sling are slung in the background.
+func (r *Receiver) Handle(source string, payload map[string]interface{}, now time.Time) (Result, error) {
+	r.handleMu.Lock()
+	defer r.handleMu.Unlock()
+
+	m, err := r.router.Route(source, payload)
+	if err != nil {
+		r.record(Delivery{Time: now, Source: source, Error: err.Error()}, func(s *State) { s.Failed++ })
+		return Result{}, err
+	}
+	if m == nil {
+		r.record(Delivery{Time: now, Source: source}, func(s *State) { s.Unmatched++ })
+		return Result{}, nil
+	}
+
+	res := Result{Matched: true, Rule: m.Rule, Rig: m.Rig}
+	d := Delivery{Time: now, Source: source, Rule: m.Rule, Key: m.Key, Rig: m.Rig}
+
+	r.mu.Lock()
+	prev, seen := r.state.Seen[m.Key]
+	r.mu.Unlock()
+	if seen && now.Sub(prev.Time) < r.router.Dedup() {
+		res.Bead, res.Duplicate = prev.Bead, true
+		d.Bead, d.Duplicate = prev.Bead, true
+		r.record(d, func(s *State) { s.Duplicates++ })
+		r.emit(source, d)
+		return res, nil
+	}
+
+	beadID, err := r.create(m)
+	if err != nil {
+		d.Error = err.Error()
+		r.record(d, func(s *State) { s.Failed++ })
+		return Result{}, fmt.Errorf("creating bead for rule %s: %w", m.Rule, err)
+	}
+	res.Bead, d.Bead = beadID, beadID
+	res.Sling, d.Slung = m.Sling, m.Sling
+	r.record(d, func(s *State) {
+		s.Created++
+		s.Seen[m.Key] = d
+	})
+	r.emit(source, d)
+	r.logger("webhook %s: rule %s created %s", source, m.Rule, beadID)
+
+	if m.Sling {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			ctx, cancel := context.WithTimeout(r.ctx, 5*time.Minute)
+			defer cancel()
+			if err := r.sling(ctx, beadID, m.Rig); err != nil {
+				r.logger("Warning: webhook rule %s: slinging %s to %s: %v", m.Rule, beadID, m.Rig, err)
+				r.record(Delivery{Time: time.Now(), Source: source, Rule: m.Rule, Bead: beadID, Rig: m.Rig, Error: "sling: " + err.Error()}, func(s *State) { s.Failed++ })
+			}
+		}()
+	}
+	return res, nil
+}
+
+// HandleGitHub turns a GitHub webhook into a mayor callback, if it is an
+// event the bridge forwards for a repository it covers.
+func (r *Receiver) HandleGitHub(event string, body []byte, now time.Time) (Result, error) {
+	cb, err := gh.ParseWebhook(event, body)
+	if err != nil {
+		r.record(Delivery{Time: now, Source: GitHubSource, Error: err.Error()}, func(s *State) { s.Failed++ })
+		return Result{}, err
+	}
+	if cb == nil || (len(r.github.Repos) > 0 && !containsString(r.github.Repos, cb.Repo)) {
+		r.record(Delivery{Time: now, Source: GitHubSource}, func(s *State) { s.Unmatched++ })
+		return Result{}, nil
+	}
+
+	msg := mail.NewMessage("github", "mayor/", cb.Subject, cb.Body)
+	msg.Type = mail.TypeNotification
+	if cb.Urgent {
+		msg.Priority = mail.PriorityUrgent
+	}
+	d := Delivery{Time: now, Source: GitHubSource, Callback: cb.Subject}
+	if err := r.sendMail(msg); err != nil {
+		d.Error = err.Error()
+		r.record(d, func(s *State) { s.Failed++ })
+		return Result{}, fmt.Errorf("sending callback %q: %w", cb.Subject, err)
+	}
+	r.record(d, func(s *State) { s.Callbacks++ })
+	r.logger("webhook github: sent callback %s", cb.Subject)
+	return Result{Matched: true, Callback: cb.Subject}, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// record saves a delivery and applies fn to the state.
+func (r *Receiver) record(d Delivery, fn func(s *State)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(r.state)
+	r.state.Recent = append(r.state.Recent, d)
+	if len(r.state.Recent) > maxRecent {
+		r.state.Recent = r.state.Recent[len(r.state.Recent)-maxRecent:]
+	}
+	for key, seen := range r.state.Seen {
+		if d.Time.Sub(seen.Time) >= r.router.Dedup() {
+			delete(r.state.Seen, key)
+		}
+	}
+	if err := stateDoc(r.townRoot).Save(r.state); err != nil {
+		r.logger("Warning: saving webhook state: %v", err)
+	}
+}
+
+func (r *Receiver) emit(source string, d Delivery) {
+	_ = events.EmitTo(r.townRoot, events.Event{
+		Type:       events.TypeWebhookReceived,
+		Actor:      "daemon",
+		Rig:        d.Rig,
+		Subject:    d.Bead,
+		Payload:    events.WebhookPayload(source, d.Rule, d.Bead, d.Duplicate, d.Slung),
+		Visibility: events.VisibilityBoth,
+	})
+}
+
+// createBead creates the bead for a match in its rig, or in town beads.
+func (r *Receiver) createBead(m *Match) (string, error) {
+	dir := r.townRoot
+	if m.Rig != "" {
+		dir = filepath.Join(r.townRoot, m.Rig)
+	}
+	b := beads.New(dir)
+	issue, err := b.Create(beads.CreateOptions{
+		Title:       m.Title,
+		Type:        m.Type,
+		Priority:    m.Priority,
+		Description: m.Description,
+		Actor:       "daemon",
+	})
+	if err != nil {
+		return "", err
+	}
+	labels := append([]string{"webhook:" + m.Rule}, m.Labels...)
+	if err := b.Update(issue.ID, beads.UpdateOptions{AddLabels: labels}); err != nil {
+		r.logger("Warning: labeling %s: %v", issue.ID, err)
+	}
+	return issue.ID, nil
+}
+
+// slingBead runs gt sling for the bead.
+func (r *Receiver) slingBead(ctx context.Context, beadID, rig string) error {
+	cmd := exec.CommandContext(ctx, "gt", "sling", beadID, rig) //nolint:gosec // G204: bead ID from bd, rig from config
+	cmd.Dir = r.townRoot
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+// Package webhook turns events from outside the town into work.
+//
+// The daemon hosts a Receiver that accepts JSON webhooks from issue
+// trackers, monitoring systems, and the like at POST /hooks/<source>. Each
+// request must carry the configured token. Rules, tried in order, match the
+// payload's fields and render a bead from it; the first matching rule
+// creates the bead in its rig and, if asked, slings it there so that a
+// polecat starts on it. Repeats of the same event (an alert that keeps
+// firing) are deduplicated by key.
+//
+// GitHub webhooks posted to /hooks/github, if enabled, bypass the rules:
+// they are authenticated by signature and become mayor callbacks (see
+// github.ParseWebhook) for gt callbacks process to route.
+//
+// Receivers are configured under "webhooks" in mayor/daemon.json.
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/logrotate"
+)
+
+// DefaultListen is the receiver address unless webhooks.listen is set.
+// Loopback only by default: put a reverse proxy in front to expose it.
+const DefaultListen = "127.0.0.1:9465"
+
+// DefaultDedup is how long a repeated event maps to the bead it created.
+const DefaultDedup = 6 * time.Hour
+
+// DefaultPriority is the priority of beads created by rules that set none.
+const DefaultPriority = 2
+
+// MaxBodyBytes bounds the size of a webhook request body.
+const MaxBodyBytes = 1 << 20
+
+// sourcePattern is the allowed form of a source name (the path segment
+// after /hooks/).
+var sourcePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Config is the "webhooks" section of mayor/daemon.json.
+type Config struct {
+	// Enabled starts the receiver with the daemon.
+	Enabled bool `json:"enabled"`
+
+	// Listen is the address to serve on (default DefaultListen).
+	Listen string `json:"listen,omitempty"`
+
+	// Token authenticates requests (required). It may reference
+	// environment variables, e.g. "${GT_WEBHOOK_TOKEN}".
+	Token string `json:"token"`
+
+	// Dedup is how long a repeated event is folded into the bead it
+	// created, e.g. "1h" (default "6h").
+	Dedup string `json:"dedup,omitempty"`
+
+	// GitHub turns webhooks posted to /hooks/github into mayor callbacks.
+	GitHub *GitHubConfig `json:"github,omitempty"`
+
+	// Rules map events to beads, tried in order.
+	Rules []Rule `json:"rules"`
+}
+
+// GitHubConfig is the GitHub webhook bridge.
+type GitHubConfig struct {
+	// Secret is the webhook secret requests are signed with. It may
+	// reference environment variables, e.g. "${GT_GITHUB_WEBHOOK_SECRET}".
+	// Unsigned requests must carry the receiver token instead.
+	Secret string `json:"secret,omitempty"`
+
+	// Repos limits the bridge to these "owner/name" repositories
+	// (default: any).
+	Repos []string `json:"repos,omitempty"`
+}
+
+// GitHubSource is the source GitHub webhooks are posted to.
+const GitHubSource = "github"
+
+// Rule maps matching events to a bead.
+type Rule struct {
+	// Name identifies the rule in logs and on created beads.
+	Name string `json:"name"`
+
+	// Source limits the rule to requests to /hooks/<source> (default: any).
+	Source string `json:"source,omitempty"`
+
+	// Disabled skips the rule without removing it.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Match maps payload fields to regular expressions their values must
+	// match. Fields are dotted paths into the JSON payload, with numbers
+	// indexing arrays, e.g. "alerts.0.labels.severity". A missing field
+	// has the value "".
+	Match map[string]string `json:"match,omitempty"`
+
+	// Rig is where the bead is created (default: town beads).
+	Rig string `json:"rig,omitempty"`
+
+	// Title and Description are Go templates over the payload, e.g.
+	// "{{.issue.title}}" or `{{field . "alerts.0.labels.alertname"}}`.
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+
+	// Type is the bead type (default "task").
+	Type string `json:"type,omitempty"`
+
+	// Priority is the bead priority, 0-4 (default DefaultPriority).
+	Priority *int `json:"priority,omitempty"`
+
+	// Labels are added to the bead.
+	Labels []string `json:"labels,omitempty"`
+
+	// Key is a template identifying repeats of an event for deduplication
+	// (default: the rendered title).
+	Key string `json:"key,omitempty"`
+
+	// Sling slings the bead to Rig, starting a polecat on it.
+	Sling bool `json:"sling,omitempty"`
+}
+
+// Match is what a rule made of an event: the bead to create.
+type Match struct {
+	Rule        string   `json:"rule"`
+	Rig         string   `json:"rig,omitempty"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Type        string   `json:"type"`
+	Priority    int      `json:"priority"`
+	Labels      []string `json:"labels,omitempty"`
+	Key         string   `json:"key"`
+	Sling       bool     `json:"sling,omitempty"`
+}
+
+// Router matches events against compiled rules.
+type Router struct {
+	rules []*compiledRule
+	dedup time.Duration
+}
+
+type compiledRule struct {
+	Rule
+	match       map[string]*regexp.Regexp
+	title       *template.Template
+	description *template.Template
+	key         *template.Template
+}
+
+// Compile validates the config and compiles its enabled rules.
+func (c Config) Compile() (*Router, error) {
+	dedup, err := logrotate.ParseDuration(c.Dedup)
+	if err != nil {
+		return nil, fmt.Errorf("webhooks dedup: %w", err)
+	}
+	if dedup <= 0 {
+		dedup = DefaultDedup
+	}
+
+	r := &Router{dedup: dedup}
+	seen := make(map[string]bool)
+	for i, rule := range c.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("webhook rule %d has no name", i+1)
+		}
+		if seen[rule.Name] {
+			return nil, fmt.Errorf("duplicate webhook rule %q", rule.Name)
+		}
+		seen[rule.Name] = true
+		cr, err := compileRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("webhook rule %s: %w", rule.Name, err)
+		}
+		if !rule.Disabled {
+			r.rules = append(r.rules, cr)
+		}
+	}
+	return r, nil
+}
+
+func compileRule(rule Rule) (*compiledRule, error) {
+	if rule.Source != "" && !sourcePattern.MatchString(rule.Source) {
+		return nil, fmt.Errorf("invalid source %q (letters, digits, - and _ only)", rule.Source)
+	}
+	if rule.Title == "" {
+		return nil, errors.New("title is required")
+	}
+	if rule.Priority != nil && (*rule.Priority < 0 || *rule.Priority > 4) {
+		return nil, fmt.Errorf("priority %d out of range 0-4", *rule.Priority)
+	}
+	if rule.Sling && rule.Rig == "" {
+		return nil, errors.New("sling needs a rig")
+	}
+
+	cr := &compiledRule{Rule: rule, match: make(map[string]*regexp.Regexp, len(rule.Match))}
+	for path, expr := range rule.Match {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("match %s: %w", path, err)
+		}
+		cr.match[path] = re
+	}
+	var err error
+	if cr.title, err = parseTemplate("title", rule.Title); err != nil {
+		return nil, err
+	}
+	if cr.description, err = parseTemplate("description", rule.Description); err != nil {
+		return nil, err
+	}
+	if cr.key, err = parseTemplate("key", rule.Key); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(template.FuncMap{"field": Field}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s template: %w", name, err)
+	}
+	return t, nil
+}
+
+// Dedup returns the deduplication window.
+func (r *Router) Dedup() time.Duration {
+	return r.dedup
+}
+
+// Rules returns the number of enabled rules.
+func (r *Router) Rules() int {
+	return len(r.rules)
+}
+
+// Route returns what the first rule matching the event makes of it, or nil
+// if no rule matches.
+func (r *Router) Route(source string, payload map[string]interface{}) (*Match, error) {
+	for _, rule := range r.rules {
+		if rule.Source != "" && rule.Source != source {
+			continue
+		}
+		if !rule.matches(payload) {
+			continue
+		}
+		return rule.render(payload)
+	}
+	return nil, nil
+}
+
+func (r *compiledRule) matches(payload map[string]interface{}) bool {
+	for path, re := range r.match {
+		if !re.MatchString(Field(payload, path)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *compiledRule) render(payload map[string]interface{}) (*Match, error) {
+	m := &Match{
+		Rule:     r.Name,
+		Rig:      r.Rig,
+		Type:     r.Type,
+		Priority: DefaultPriority,
+		Labels:   r.Labels,
+		Sling:    r.Sling,
+	}
+	if m.Type == "" {
+		m.Type = "task"
+	}
+	if r.Priority != nil {
+		m.Priority = *r.Priority
+	}
+	var err error
+	if m.Title, err = execute(r.title, payload); err != nil {
+		return nil, err
+	}
+	if m.Title == "" {
+		return nil, fmt.Errorf("rule %s rendered an empty title", r.Name)
+	}
+	if m.Description, err = execute(r.description, payload); err != nil {
+		return nil, err
+	}
+	if m.Key, err = execute(r.key, payload); err != nil {
+		return nil, err
+	}
+	if m.Key == "" {
+		m.Key = m.Title
+	}
+	m.Key = r.Name + ":" + m.Key
+	return m, nil
+}
+
+// execute renders a template, treating missing fields as empty.
+func execute(t *template.Template, payload map[string]interface{}) (string, error) {
+	var sb strings.Builder
+	if err := t.Execute(&sb, payload); err != nil {
+		return "", fmt.Errorf("%s template: %w", t.Name(), err)
+	}
+	return strings.TrimSpace(strings.ReplaceAll(sb.String(), "<no value>", "")), nil
+}
+
+// Field returns the value at a dotted path in a JSON payload as a string,
+// or "" if there is none. Numeric path segments index arrays.
+func Field(payload map[string]interface{}, path string) string {
+	var v interface{} = payload
+	for _, part := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			v = node[part]
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return ""
+			}
+			v = node[i]
+		default:
+			return ""
+		}
+	}
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
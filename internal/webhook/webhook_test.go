@@ -0,0 +1,314 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+const alertPayload = `{
+  "status": "firing",
+  "groupKey": "{}:{alertname=\"HighErrorRate\"}",
+  "commonLabels": {"alertname": "HighErrorRate", "severity": "critical"},
+  "commonAnnotations": {"summary": "5xx rate above 5%"},
+  "alerts": [{"labels": {"instance": "web-1"}, "value": 7}]
+}`
+
+func parsePayload(t *testing.T, s string) map[string]interface{} {
+	t.Helper()
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &payload); err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+func testConfig() Config {
+	p0 := 0
+	return Config{
+		Enabled: true,
+		Token:   "s3cret",
+		Rules: []Rule{
+			{Name: "off", Disabled: true, Title: "never"},
+			{
+				Name:        "prod-pages",
+				Source:      "alertmanager",
+				Match:       map[string]string{"status": "^firing$", "commonLabels.severity": "^(critical|page)$"},
+				Rig:         "gastown",
+				Title:       "[alert] {{.commonLabels.alertname}} on {{field . \"alerts.0.labels.instance\"}}",
+				Description: "{{.commonAnnotations.summary}}{{.missing}}",
+				Type:        "bug",
+				Priority:    &p0,
+				Labels:      []string{"alert"},
+				Key:         "{{.groupKey}}",
+				Sling:       true,
+			},
+			{Name: "catch-all", Title: "Event from {{.sender}}"},
+		},
+	}
+}
+
+func TestField(t *testing.T) {
+	payload := parsePayload(t, alertPayload)
+	tests := map[string]string{
+		"status":                   "firing",
+		"commonLabels.alertname":   "HighErrorRate",
+		"alerts.0.labels.instance": "web-1",
+		"alerts.0.value":           "7",
+		"alerts.1.labels":          "",
+		"alerts.x":                 "",
+		"status.deeper":            "",
+		"nope":                     "",
+	}
+	for path, want := range tests {
+		if got := Field(payload, path); got != want {
+			t.Errorf("Field(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRoute(t *testing.T) {
+	router, err := testConfig().Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if router.Rules() != 2 || router.Dedup() != DefaultDedup {
+		t.Errorf("rules = %d, dedup = %v", router.Rules(), router.Dedup())
+	}
+
+	m, err := router.Route("alertmanager", parsePayload(t, alertPayload))
+	if err != nil || m == nil {
+		t.Fatalf("Route = %+v, %v", m, err)
+	}
+	if m.Rule != "prod-pages" || m.Rig != "gastown" || !m.Sling || m.Type != "bug" || m.Priority != 0 {
+		t.Errorf("match = %+v", m)
+	}
+	if m.Title != "[alert] HighErrorRate on web-1" || m.Description != "5xx rate above 5%" {
+		t.Errorf("rendered = %q / %q", m.Title, m.Description)
+	}
+	if m.Key != `prod-pages:{}:{alertname="HighErrorRate"}` {
+		t.Errorf("key = %q", m.Key)
+	}
+
+	// Same payload on another source, or resolved: falls through to catch-all
+	resolved := parsePayload(t, strings.Replace(alertPayload, `"firing"`, `"resolved"`, 1))
+	for _, tc := range []struct {
+		source  string
+		payload map[string]interface{}
+	}{{"other", parsePayload(t, alertPayload)}, {"alertmanager", resolved}} {
+		m, err := router.Route(tc.source, tc.payload)
+		if err != nil || m == nil || m.Rule != "catch-all" || m.Type != "task" || m.Priority != DefaultPriority {
+			t.Errorf("Route(%s) = %+v, %v", tc.source, m, err)
+		}
+		if m != nil && m.Key != "catch-all:Event from" {
+			t.Errorf("default key = %q", m.Key)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	p9 := 9
+	for name, rule := range map[string]Rule{
+		"no title":       {Name: "a"},
+		"bad regexp":     {Name: "a", Title: "t", Match: map[string]string{"x": "("}},
+		"bad template":   {Name: "a", Title: "{{.x"},
+		"bad priority":   {Name: "a", Title: "t", Priority: &p9},
+		"sling, no rig":  {Name: "a", Title: "t", Sling: true},
+		"invalid source": {Name: "a", Title: "t", Source: "a/b"},
+		"no name":        {Title: "t"},
+	} {
+		if _, err := (Config{Rules: []Rule{rule}}).Compile(); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+	dup := Config{Rules: []Rule{{Name: "a", Title: "t"}, {Name: "a", Title: "t"}}}
+	if _, err := dup.Compile(); err == nil {
+		t.Error("duplicate rule names accepted")
+	}
+}
+
+// newTestReceiver returns a receiver whose bead creation and slings are
+// recorded instead of run.
+func newTestReceiver(t *testing.T) (*Receiver, *[]*Match, chan string) {
+	t.Helper()
+	r, err := NewReceiver(t.TempDir(), testConfig(), t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var created []*Match
+	r.create = func(m *Match) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		created = append(created, m)
+		return "gt-" + string(rune('a'+len(created)-1)), nil
+	}
+	slung := make(chan string, 4)
+	r.sling = func(_ context.Context, beadID, rig string) error {
+		slung <- beadID + "@" + rig
+		return nil
+	}
+	t.Cleanup(r.Stop)
+	return r, &created, slung
+}
+
+func post(r *Receiver, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestReceiverCreatesAndSlings(t *testing.T) {
+	r, created, slung := newTestReceiver(t)
+
+	w := post(r, "/hooks/alertmanager", "s3cret", alertPayload)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var res Result
+	_ = json.Unmarshal(w.Body.Bytes(), &res)
+	if !res.Matched || res.Bead != "gt-a" || !res.Sling || res.Rule != "prod-pages" {
+		t.Errorf("result = %+v", res)
+	}
+	select {
+	case got := <-slung:
+		if got != "gt-a@gastown" {
+			t.Errorf("slung %s", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("bead was not slung")
+	}
+
+	// The alert firing again maps to the same bead
+	w = post(r, "/hooks/alertmanager", "s3cret", alertPayload)
+	_ = json.Unmarshal(w.Body.Bytes(), &res)
+	if !res.Duplicate || res.Bead != "gt-a" || len(*created) != 1 {
+		t.Errorf("repeat = %+v, created %d", res, len(*created))
+	}
+
+	state, err := LoadState(r.townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Created != 1 || state.Duplicates != 1 || len(state.Recent) != 2 {
+		t.Errorf("state = %+v", state)
+	}
+}
+
+func TestReceiverDedupExpires(t *testing.T) {
+	r, created, _ := newTestReceiver(t)
+	r.router.rules[0].Sling = false
+	now := time.Now()
+	payload := parsePayload(t, alertPayload)
+
+	if _, err := r.Handle("alertmanager", payload, now); err != nil {
+		t.Fatal(err)
+	}
+	res, err := r.Handle("alertmanager", payload, now.Add(DefaultDedup+time.Minute))
+	if err != nil || res.Duplicate || res.Bead != "gt-b" || len(*created) != 2 {
+		t.Errorf("after dedup window = %+v, %v", res, err)
+	}
+}
+
+func TestReceiverRejects(t *testing.T) {
+	r, created, _ := newTestReceiver(t)
+
+	if w := post(r, "/hooks/alertmanager", "", alertPayload); w.Code != http.StatusUnauthorized {
+		t.Errorf("no token: %d", w.Code)
+	}
+	if w := post(r, "/hooks/alertmanager", "wrong", alertPayload); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token: %d", w.Code)
+	}
+	if w := post(r, "/hooks/alertmanager?token=s3cret", "", "[1,2]"); w.Code != http.StatusBadRequest {
+		t.Errorf("non-object body: %d", w.Code)
+	}
+	if w := post(r, "/hooks/a/b", "s3cret", alertPayload); w.Code != http.StatusNotFound {
+		t.Errorf("bad source: %d", w.Code)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/hooks/alertmanager", nil)
+	req.Header.Set("X-Gastown-Token", "s3cret")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: %d", w.Code)
+	}
+	if len(*created) != 0 {
+		t.Errorf("created %d beads", len(*created))
+	}
+}
+
+func TestNewReceiverRequiresToken(t *testing.T) {
+	t.Setenv("GT_TEST_WEBHOOK_TOKEN", "")
+	cfg := testConfig()
+	cfg.Token = "${GT_TEST_WEBHOOK_TOKEN}"
+	if _, err := NewReceiver(t.TempDir(), cfg, t.Logf); err == nil {
+		t.Error("receiver without a token was created")
+	}
+}
+
+func TestReceiverGitHubBridge(t *testing.T) {
+	cfg := testConfig()
+	cfg.GitHub = &GitHubConfig{Secret: "gh-secret", Repos: []string{"acme/widgets"}}
+	r, err := NewReceiver(t.TempDir(), cfg, t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent []*mail.Message
+	r.sendMail = func(msg *mail.Message) error {
+		sent = append(sent, msg)
+		return nil
+	}
+
+	body := `{"action": "completed",
+		"repository": {"full_name": "acme/widgets", "default_branch": "main"},
+		"workflow_run": {"name": "CI", "head_branch": "main", "head_sha": "abc", "conclusion": "failure"}}`
+	send := func(body, signature string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/hooks/github", strings.NewReader(body))
+		req.Header.Set("X-GitHub-Event", "workflow_run")
+		if signature != "" {
+			req.Header.Set("X-Hub-Signature-256", signature)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	mac := hmac.New(sha256.New, []byte("gh-secret"))
+	mac.Write([]byte(body))
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if w := send(body, "sha256=00"); w.Code != http.StatusUnauthorized {
+		t.Errorf("bad signature: %d", w.Code)
+	}
+	w := send(body, signature)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var res Result
+	_ = json.Unmarshal(w.Body.Bytes(), &res)
+	if !res.Matched || res.Callback != "GH_CI_FAILED acme/widgets@main" {
+		t.Errorf("result = %+v", res)
+	}
+	if len(sent) != 1 || sent[0].To != "mayor/" || sent[0].Priority != mail.PriorityUrgent {
+		t.Fatalf("sent = %+v", sent)
+	}
+
+	// Repositories outside the bridge's list are ignored
+	other := strings.Replace(body, "acme/widgets", "acme/other", 1)
+	if res, err := r.HandleGitHub("workflow_run", []byte(other), time.Now()); err != nil || res.Matched || len(sent) != 1 {
+		t.Errorf("other repo = %+v, %v", res, err)
+	}
+}